@@ -1,8 +1,18 @@
 #version 330 core
 uniform vec4 debugColor;
 
+#ifdef SKINNED
+in vec4 vJointColor;
+in vec4 vWeightColor;
+#endif
+
 out vec4 FragColor;
 
 void main() {
+#ifdef SKINNED
+    // show joints in RGB and weights in alpha (or swap)
+    FragColor = vec4(vJointColor.rgb, clamp(vWeightColor.x + vWeightColor.y + vWeightColor.z + vWeightColor.w, 0.0, 1.0));
+#else
     FragColor = debugColor;
+#endif
 }
@@ -1,10 +1,29 @@
 #version 330 core
 layout(location = 0) in vec3 position;
 
+#ifdef SKINNED
+#include "include/skinning.glsl"
+
+out vec4 vJointColor;
+out vec4 vWeightColor;
+#endif
+
 uniform mat4 model;
 uniform mat4 view;
 uniform mat4 projection;
 
 void main() {
-    gl_Position = projection * view * model * vec4(position, 1.0);
+    vec3 pos = position;
+#ifdef SKINNED
+    // encode joint indices into color channels (scaled)
+    vJointColor = vec4(aJoints) / 255.0;
+
+    // encode weights directly
+    vWeightColor = aWeights;
+
+    vec3 nrm = vec3(0.0, 0.0, 1.0);
+    vec3 tan = vec3(1.0, 0.0, 0.0);
+    skinVertex(pos, nrm, tan);
+#endif
+    gl_Position = projection * view * model * vec4(pos, 1.0);
 }
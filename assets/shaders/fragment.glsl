@@ -1,5 +1,4 @@
-#version 330 core
-#define MAX_LIGHTS 8
+#version 410 core
 
 in vec3 FragPos;
 in vec3 Normal;
@@ -25,19 +24,9 @@ layout(std140) uniform MaterialBlock {
 
 uniform vec3 viewPos;
 
-uniform int   lightCount;
-uniform vec3  lightDir[MAX_LIGHTS];
-uniform vec3  lightColor[MAX_LIGHTS];
-uniform float lightIntensity[MAX_LIGHTS];
-uniform vec3  lightPos[MAX_LIGHTS];
-uniform float lightRange[MAX_LIGHTS];
-uniform float lightAngle[MAX_LIGHTS];
-uniform int   lightType[MAX_LIGHTS];
+#include "include/lights.glsl"
 
-uniform sampler2D shadowMap;
-uniform mat4 lightSpaceMatrix;
-uniform vec2 uShadowMapSize;
-uniform int  shadowLightIndex;
+#include "include/shadows.glsl"
 
 // textures
 uniform sampler2D diffuseTex;
@@ -55,41 +44,28 @@ uniform bool      useMetallicRoughnessMap;
 uniform vec2 uvScaleBase;
 uniform vec2 uvOffsetBase;
 
-float computeShadowPCF(vec4 lightSpacePos, vec3 normal, vec3 lightDirWS)
-{
-    vec3 projCoords = lightSpacePos.xyz / lightSpacePos.w;
-    vec2 uv = projCoords.xy * 0.5 + 0.5;
-    float currentDepth = projCoords.z * 0.5 + 0.5;
+// alpha mode (0 opaque, 1 mask, 2 blend) and two-sided lighting
+uniform int   alphaMode;
+uniform float alphaCutoff;
+uniform bool  doubleSided;
 
-    if (uv.x < 0.0 || uv.x > 1.0 ||
-        uv.y < 0.0 || uv.y > 1.0 ||
-        currentDepth < 0.0 || currentDepth > 1.0) {
-        return 0.0;
-    }
-
-    float ndotl = max(dot(normalize(normal), normalize(lightDirWS)), 0.0);
-    float bias = mix(0.0005, 0.00005, ndotl);
+// instanced draws take the base colour per instance
+uniform bool useInstancing;
+in vec4 InstanceColor;
 
-    vec2 texelSize = 0.7 / uShadowMapSize;
+// meshes with vertex colours tint the base colour with them
+uniform bool useVertexColor;
+in vec4 VertexColor;
 
-    float shadow = 0.0;
-    int samples = 0;
-    for (int y = -1; y <= 1; ++y) {
-        for (int x = -1; x <= 1; ++x) {
-            vec2 offset = vec2(x, y) * texelSize;
-            float closestDepth = texture(shadowMap, uv + offset).r;
-            shadow += currentDepth - bias > closestDepth ? 1.0 : 0.0;
-            samples++;
-        }
+void main() {
+    vec4 baseColor = useInstancing ? InstanceColor : BaseColor;
+    if (useVertexColor) {
+        baseColor *= VertexColor;
     }
 
-    return shadow / float(samples);
-}
-
-void main() {
     // This shader is Blinn/Phong only
     if (materialType != 0) {
-        FragColor = BaseColor;
+        FragColor = baseColor;
         return;
     }
 
@@ -105,6 +81,9 @@ void main() {
         n = n * 2.0 - 1.0;
         finalNormal = normalize(TBN * n);
     }
+    if (doubleSided && !gl_FrontFacing) {
+        finalNormal = -finalNormal;
+    }
 
     vec3 viewDir = normalize(viewPos - FragPos);
 
@@ -112,10 +91,13 @@ void main() {
     vec2 baseUV = TexCoord * uvScaleBase + uvOffsetBase;
 
     // Base color
-    vec4 base = BaseColor;
+    vec4 base = baseColor;
     if (useTexture) {
         base = texture(diffuseTex, baseUV);
     }
+    if (alphaMode == 1 && base.a < alphaCutoff) {
+        discard;
+    }
 
     // Occlusion
     float ao = 1.0;
@@ -136,32 +118,29 @@ void main() {
 
     vec3 lighting = ambient;
 
-    // Shadow-space position
-    vec4 lightSpacePos = lightSpaceMatrix * vec4(FragPos, 1.0);
+    float viewDepth = -(view * vec4(FragPos, 1.0)).z;
 
-    for (int i = 0; i < lightCount; i++) {
+    uvec2 cell = clusterCell(FragPos);
+    int numLights = dirLightCount + int(cell.y);
+    for (int k = 0; k < numLights; ++k) {
+        int i = k < dirLightCount ? k : int(texelFetch(clusterLights, int(cell.x) + k - dirLightCount).r);
+        Light light = fetchLight(i);
         vec3 L;
         float attenuation = 1.0;
-        float shadow = 1.0;
 
-        if (shadowLightIndex >= 0 &&
-            i == shadowLightIndex &&
-            (lightType[i] == 0 || lightType[i] == 2)) {
-            shadow = computeShadowPCF(lightSpacePos, finalNormal, -lightDir[i]);
-        }
-
-        if (lightType[i] == 0) {
-            L = normalize(-lightDir[i]);
+        if (light.type == 0) {
+            L = normalize(-light.dir);
         } else {
-            vec3 toLight = lightPos[i] - FragPos;
+            vec3 toLight = light.pos - FragPos;
             float dist = length(toLight);
             L = normalize(toLight);
-            attenuation = 1.0 / (1.0 + (dist / lightRange[i]) * (dist / lightRange[i]));
+            attenuation = 1.0 / (1.0 + (dist / light.range) * (dist / light.range));
+            attenuation *= rangeWindow(dist, light.range);
         }
 
-        if (lightType[i] == 2) {
-            float cutoff = cos(radians(lightAngle[i]));
-            float spotFactor = dot(L, normalize(-lightDir[i]));
+        if (light.type == 2) {
+            float cutoff = cos(radians(light.angle));
+            float spotFactor = dot(L, normalize(-light.dir));
             if (spotFactor < cutoff) {
                 continue;
             }
@@ -170,19 +149,16 @@ void main() {
         }
 
         float diff = max(dot(finalNormal, L), 0.0);
-        vec3 diffuse = matDiffuse * diff * lightColor[i] * lightIntensity[i];
+        vec3 diffuse = matDiffuse * diff * light.color * light.intensity;
 
         vec3 H = normalize(L + viewDir);
         float spec = pow(max(dot(finalNormal, H), 0.0), matShininess);
-        vec3 specular = matSpecular * spec * lightColor[i] * lightIntensity[i];
+        vec3 specular = matSpecular * spec * light.color * light.intensity;
 
-        float shadowFactor = 1.0;
-        if (i == shadowLightIndex && (lightType[i] == 0 || lightType[i] == 2)) {
-            shadowFactor = 1.0 - shadow;
-        }
+        float shadowFactor = 1.0 - lightShadow(light, FragPos, viewDepth, finalNormal, L);
 
         lighting += (diffuse + specular) * attenuation * shadowFactor;
     }
 
-    FragColor = vec4(base.rgb * lighting, base.a);
+    FragColor = vec4(base.rgb * lighting, alphaMode == 2 ? base.a : 1.0);
 }
@@ -0,0 +1,57 @@
+// Clustered lights, shared by the lit fragment shaders.
+
+// Lights live in buffer textures, four texels each (engine.PackLights), so
+// their number is not bounded by uniform space. The first dirLightCount are
+// directional and reach every fragment; point and spot lights are found
+// through the fragment's cluster: clusterGrid holds an (offset, count) pair
+// per cluster into the clusterLights index list.
+struct Light {
+    vec3  pos;
+    int   type;
+    vec3  dir;
+    float range;
+    vec3  color;
+    float intensity;
+    float angle;
+    int   shadow; // first shadow view, -1 for none
+};
+
+uniform samplerBuffer  lightBuffer;
+uniform usamplerBuffer clusterGrid;
+uniform usamplerBuffer clusterLights;
+uniform int  dirLightCount;
+uniform vec3 clusterDims;   // tiles x, tiles y, depth slices
+uniform vec2 clusterDepth;  // near plane, slices / log(far / near)
+uniform mat4 view;
+uniform mat4 projection;
+
+Light fetchLight(int i)
+{
+    vec4 t0 = texelFetch(lightBuffer, 4 * i);
+    vec4 t1 = texelFetch(lightBuffer, 4 * i + 1);
+    vec4 t2 = texelFetch(lightBuffer, 4 * i + 2);
+    vec4 t3 = texelFetch(lightBuffer, 4 * i + 3);
+    return Light(t0.xyz, int(t0.w), t1.xyz, t1.w, t2.rgb, t2.a, t3.x, int(t3.y));
+}
+
+// clusterCell returns the (offset, count) of the cluster containing worldPos.
+uvec2 clusterCell(vec3 worldPos)
+{
+    vec4 clip = projection * view * vec4(worldPos, 1.0);
+    ivec3 dims = ivec3(clusterDims);
+    ivec2 tile = ivec2((clip.xy / clip.w * 0.5 + 0.5) * vec2(dims.xy));
+    tile = clamp(tile, ivec2(0), dims.xy - 1);
+    int slice = 0;
+    if (clip.w > clusterDepth.x) {
+        slice = min(int(log(clip.w / clusterDepth.x) * clusterDepth.y), dims.z - 1);
+    }
+    return texelFetch(clusterGrid, (slice * dims.y + tile.y) * dims.x + tile.x).xy;
+}
+
+// rangeWindow fades a light to zero at its range, where clustering cuts it off.
+float rangeWindow(float dist, float range)
+{
+    float x = dist / range;
+    float w = clamp(1.0 - x * x * x * x, 0.0, 1.0);
+    return w * w;
+}
@@ -0,0 +1,142 @@
+// Shadow atlas sampling, shared by the lit fragment shaders.
+#include "lights.glsl"
+
+// Shadow atlas. Each shadow view is five texels of shadowViews: the four
+// columns of its light matrix, then its tile (offset, size) in atlas UVs.
+// Light.shadow is a light's first view; directional lights own one per
+// cascade. Point lights own one per cube face (+X, -X, +Y, -Y, +Z, -Z),
+// rendered into a cube of pointShadowMaps rather than the atlas; their
+// fifth texel holds the faces' near and far planes and the cube layer.
+uniform sampler2D        shadowMap;
+uniform vec2             uShadowMapSize;
+uniform samplerBuffer    shadowViews;
+uniform samplerCubeArray pointShadowMaps;
+uniform vec4  cascadeSplits;      // far view depth of each cascade
+uniform int   cascadeCount;
+uniform int   shadowFilter;       // 0 hard, 1 PCF, 2 PCSS
+uniform float shadowFilterRadius; // PCF radius in texels
+uniform float shadowLightSize;    // PCSS light size, fraction of a tile
+
+float shadowCompare(vec2 uv, vec4 rect, float depth)
+{
+    // Keep filter taps inside the view's own tile.
+    vec2 texel = 1.0 / uShadowMapSize;
+    uv = clamp(uv, rect.xy + 0.5 * texel, rect.xy + rect.zw - 0.5 * texel);
+    return depth > texture(shadowMap, uv).r ? 1.0 : 0.0;
+}
+
+// sampleShadowView returns how much of worldPos shadow view v hides from
+// its light, from 0 (lit) to 1.
+float sampleShadowView(int v, vec3 worldPos, float bias)
+{
+    mat4 m = mat4(texelFetch(shadowViews, 5 * v),
+                  texelFetch(shadowViews, 5 * v + 1),
+                  texelFetch(shadowViews, 5 * v + 2),
+                  texelFetch(shadowViews, 5 * v + 3));
+    vec4 rect = texelFetch(shadowViews, 5 * v + 4);
+
+    vec4 p = m * vec4(worldPos, 1.0);
+    vec3 proj = p.xyz / p.w * 0.5 + 0.5;
+    if (any(lessThan(proj, vec3(0.0))) || any(greaterThan(proj, vec3(1.0)))) {
+        return 0.0;
+    }
+    vec2 uv = rect.xy + proj.xy * rect.zw;
+    float depth = proj.z - bias;
+    if (shadowFilter == 0) {
+        return shadowCompare(uv, rect, depth);
+    }
+
+    vec2 texel = 1.0 / uShadowMapSize;
+    float radius = shadowFilterRadius;
+    if (shadowFilter == 2) {
+        // PCSS: average the blockers under the light's footprint and widen
+        // the kernel with the receiver's distance behind them.
+        float search = shadowLightSize * rect.z * uShadowMapSize.x;
+        float blockerSum = 0.0;
+        float blockers = 0.0;
+        for (int y = -2; y <= 2; ++y) {
+            for (int x = -2; x <= 2; ++x) {
+                vec2 suv = clamp(uv + vec2(x, y) * 0.5 * search * texel, rect.xy, rect.xy + rect.zw);
+                float d = texture(shadowMap, suv).r;
+                if (d < depth) {
+                    blockerSum += d;
+                    blockers += 1.0;
+                }
+            }
+        }
+        if (blockers == 0.0) {
+            return 0.0;
+        }
+        float blocker = blockerSum / blockers;
+        radius = clamp((depth - blocker) / blocker * search, shadowFilterRadius, 16.0);
+    }
+
+    float shadow = 0.0;
+    for (int y = -2; y <= 2; ++y) {
+        for (int x = -2; x <= 2; ++x) {
+            shadow += shadowCompare(uv + vec2(x, y) * 0.5 * radius * texel, rect, depth);
+        }
+    }
+    return shadow / 25.0;
+}
+
+// samplePointShadow returns how much of worldPos the cube shadow of point
+// view v hides from the light at lightPos, from 0 (lit) to 1. PCSS filters
+// as PCF.
+float samplePointShadow(int v, vec3 lightPos, vec3 worldPos, float bias)
+{
+    vec4 cube = texelFetch(shadowViews, 5 * v + 4);
+    float n = cube.x;
+    float f = cube.y;
+    vec3 d = worldPos - lightPos;
+    // Cube faces store perspective depth along their own axis, which for
+    // the face d falls on is d's major axis.
+    float z = max(abs(d.x), max(abs(d.y), abs(d.z)));
+    float depth = ((f + n) / (f - n) - 2.0 * f * n / ((f - n) * z)) * 0.5 + 0.5;
+    if (depth >= 1.0) {
+        return 0.0;
+    }
+    depth -= bias;
+    if (shadowFilter == 0) {
+        return depth > texture(pointShadowMaps, vec4(d, cube.z)).r ? 1.0 : 0.0;
+    }
+
+    // Taps on a disc facing the light, shadowFilterRadius face texels wide.
+    vec3 dir = normalize(d);
+    vec3 t = normalize(cross(dir, abs(dir.y) < 0.99 ? vec3(0.0, 1.0, 0.0) : vec3(1.0, 0.0, 0.0)));
+    vec3 b = cross(dir, t);
+    float spread = shadowFilterRadius * z / float(textureSize(pointShadowMaps, 0).x);
+    float shadow = 0.0;
+    for (int y = -2; y <= 2; ++y) {
+        for (int x = -2; x <= 2; ++x) {
+            vec3 s = d + (float(x) * t + float(y) * b) * spread;
+            shadow += depth > texture(pointShadowMaps, vec4(s, cube.z)).r ? 1.0 : 0.0;
+        }
+    }
+    return shadow / 25.0;
+}
+
+// lightShadow picks the cascade or cube of light covering worldPos
+// and samples it. viewDepth is the fragment's distance along the camera.
+float lightShadow(Light light, vec3 worldPos, float viewDepth, vec3 N, vec3 L)
+{
+    if (light.shadow < 0) {
+        return 0.0;
+    }
+    int v = light.shadow;
+    float bias = mix(0.0005, 0.00005, max(dot(N, L), 0.0));
+    if (light.type == 1) {
+        return samplePointShadow(v, light.pos, worldPos, bias);
+    }
+    if (light.type == 0) {
+        int c = 0;
+        while (c < cascadeCount && viewDepth > cascadeSplits[c]) {
+            c++;
+        }
+        if (c == cascadeCount) {
+            return 0.0;
+        }
+        v += c;
+    }
+    return sampleShadowView(v, worldPos, bias);
+}
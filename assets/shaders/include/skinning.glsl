@@ -0,0 +1,61 @@
+// Vertex skinning for the SKINNED variants of the lit vertex shaders.
+layout(location = 4) in uvec4 aJoints;
+layout(location = 5) in vec4 aWeights;
+
+// joint * inverseBind
+uniform mat4 uJointMatrices[128];
+
+// 0 = linear blend, 1 = dual quaternion (ecs.SkinningMode)
+uniform int uSkinningMode;
+// per joint: real part, dual part
+uniform vec4 uJointDualQuats[256];
+
+vec3 dqRotate(vec4 r, vec3 v)
+{
+    return v + 2.0 * cross(r.xyz, cross(r.xyz, v) + r.w * v);
+}
+
+void blendDualQuat(out vec4 r, out vec4 d)
+{
+    vec4 r0 = uJointDualQuats[2u * aJoints.x];
+    r = aWeights.x * r0;
+    d = aWeights.x * uJointDualQuats[2u * aJoints.x + 1u];
+
+    for (int k = 1; k < 4; ++k) {
+        uint j = aJoints[k];
+        vec4 rj = uJointDualQuats[2u * j];
+        // keep every joint in r0's hemisphere so the blend takes the short way
+        float w = dot(r0, rj) < 0.0 ? -aWeights[k] : aWeights[k];
+        r += w * rj;
+        d += w * uJointDualQuats[2u * j + 1u];
+    }
+
+    float len = length(r);
+    r /= len;
+    d /= len;
+}
+
+// skinVertex moves a bind-pose position, normal and tangent into the
+// pose of the current joints, blended as uSkinningMode asks.
+void skinVertex(inout vec3 pos, inout vec3 nrm, inout vec3 tan)
+{
+    if (uSkinningMode == 1) {
+        vec4 r, d;
+        blendDualQuat(r, d);
+        vec3 t = 2.0 * (r.w * d.xyz - d.w * r.xyz + cross(r.xyz, d.xyz));
+        pos = dqRotate(r, pos) + t;
+        nrm = dqRotate(r, nrm);
+        tan = dqRotate(r, tan);
+        return;
+    }
+
+    mat4 skinMat =
+        aWeights.x * uJointMatrices[aJoints.x] +
+        aWeights.y * uJointMatrices[aJoints.y] +
+        aWeights.z * uJointMatrices[aJoints.z] +
+        aWeights.w * uJointMatrices[aJoints.w];
+    mat3 normalMatrix = mat3(transpose(inverse(skinMat)));
+    pos = (skinMat * vec4(pos, 1.0)).xyz;
+    nrm = normalMatrix * nrm;
+    tan = normalMatrix * tan;
+}
@@ -1,5 +1,4 @@
 #version 410 core
-#define MAX_LIGHTS 8
 
 layout(std140) uniform MaterialBlock {
     vec4 BaseColor;
@@ -68,20 +67,9 @@ uniform int texCoordNormal;
 uniform int texCoordOcclusion;
 uniform int texCoordMR;
 
-// Lights
-uniform int   lightCount;
-uniform vec3  lightDir[MAX_LIGHTS];
-uniform vec3  lightColor[MAX_LIGHTS];
-uniform float lightIntensity[MAX_LIGHTS];
-uniform vec3  lightPos[MAX_LIGHTS];
-uniform float lightRange[MAX_LIGHTS];
-uniform float lightAngle[MAX_LIGHTS];
-uniform int   lightType[MAX_LIGHTS];
-
-// Shadows
-uniform sampler2D shadowMap;
-uniform vec2 uShadowMapSize;
-uniform int  shadowLightIndex;
+#include "include/lights.glsl"
+
+#include "include/shadows.glsl"
 uniform float normalScale;
 // Emissive
 uniform sampler2D emissiveTex;
@@ -97,6 +85,7 @@ uniform samplerCube irradianceMap;      // diffuse
 uniform samplerCube prefilteredEnvMap;  // specular (mipmapped)
 uniform sampler2D   brdfLUT;            // 2D LUT
 uniform bool        useIBL;
+uniform float       envIntensity;       // scales both IBL terms
 uniform sampler2D clearcoatTex;
 uniform bool useClearcoatTex;
 
@@ -114,6 +103,19 @@ vec3 background = vec3(1.0); // white glass
 uniform sampler2D transmissionTex;
 uniform bool useTransmissionTex;
 
+// alpha mode (0 opaque, 1 mask, 2 blend) and two-sided lighting
+uniform int   alphaMode;
+uniform float alphaCutoff;
+uniform bool  doubleSided;
+
+// instanced draws take the base colour per instance
+uniform bool useInstancing;
+in vec4 InstanceColor;
+
+// meshes with vertex colours tint the base colour with them
+uniform bool useVertexColor;
+in vec4 VertexColor;
+
 in VS_OUT {
     vec3 WorldPos;
     vec3 Normal;
@@ -134,40 +136,6 @@ vec2 selectUV(int setIndex, vec2 uv0, vec2 uv1)
     return (setIndex == 1) ? uv1 : uv0;
 }
 
-// ------------------------------------------------------------
-// Shadow helper
-// ------------------------------------------------------------
-float computeShadowPCF(vec4 lightSpacePos, vec3 normal, vec3 lightDirWS)
-{
-    vec3 projCoords = lightSpacePos.xyz / lightSpacePos.w;
-    vec2 uv = projCoords.xy * 0.5 + 0.5;
-    float currentDepth = projCoords.z * 0.5 + 0.5;
-
-    if (uv.x < 0.0 || uv.x > 1.0 ||
-        uv.y < 0.0 || uv.y > 1.0 ||
-        currentDepth < 0.0 || currentDepth > 1.0) {
-        return 0.0;
-    }
-
-    float ndotl = max(dot(normalize(normal), normalize(lightDirWS)), 0.0);
-    float bias = mix(0.0005, 0.00005, ndotl);
-
-    vec2 texelSize = 0.7 / uShadowMapSize;
-
-    float shadow = 0.0;
-    int samples = 0;
-    for (int y = -1; y <= 1; ++y) {
-        for (int x = -1; x <= 1; ++x) {
-            vec2 offset = vec2(x, y) * texelSize;
-            float closestDepth = texture(shadowMap, uv + offset).r;
-            shadow += currentDepth - bias > closestDepth ? 1.0 : 0.0;
-            samples++;
-        }
-    }
-
-    return shadow / float(samples);
-}
-
 // ------------------------------------------------------------
 // PBR helper functions
 // ------------------------------------------------------------
@@ -208,19 +176,6 @@ vec3 FresnelSchlick(float cosTheta, vec3 F0)
 
 
 
-// --------------------------------------------------------
-// Tone mapping + gamma correction
-// --------------------------------------------------------
-vec3 ACESFilm(vec3 x)
-{
-    float a = 2.51;
-    float b = 0.03;
-    float c = 2.43;
-    float d = 0.59;
-    float e = 0.14;
-    return clamp((x*(a*x+b)) / (x*(c*x+d)+e), 0.0, 1.0);
-}
-
 
 
 
@@ -229,8 +184,13 @@ vec3 ACESFilm(vec3 x)
 // ------------------------------------------------------------
 void main()
 {
+    vec4 baseColor = useInstancing ? InstanceColor : BaseColor;
+    if (useVertexColor) {
+        baseColor *= VertexColor;
+    }
+
     if (materialType != 1) {
-        FragColor = BaseColor;
+        FragColor = baseColor;
         return;
     }
 
@@ -261,6 +221,9 @@ void main()
         n = normalize(n);
         N = normalize(TBN * n);
     }
+    if (doubleSided && !gl_FrontFacing) {
+        N = -N;
+    }
 
 
     // --------------------------------------------------------
@@ -269,9 +232,15 @@ void main()
     vec2 uvBase = selectUV(texCoordBase, fs_in.UV0, fs_in.UV1);
     uvBase = uvBase * uvScaleBase + uvOffsetBase;
 
-    vec3 albedo = BaseColor.rgb;
+    vec3 albedo = baseColor.rgb;
+    float alpha = baseColor.a;
     if (useTexture) {
-        albedo = texture(albedoTex, uvBase).rgb;
+        vec4 texel = texture(albedoTex, uvBase);
+        albedo = texel.rgb;
+        alpha = texel.a;
+    }
+    if (alphaMode == 1 && alpha < alphaCutoff) {
+        discard;
     }
 
     // --------------------------------------------------------
@@ -315,22 +284,28 @@ void main()
     // --------------------------------------------------------
     // Per-light loop with shadows
     // --------------------------------------------------------
-    for (int i = 0; i < lightCount; ++i) {
+    float viewDepth = -(view * vec4(fs_in.WorldPos, 1.0)).z;
+    uvec2 cell = clusterCell(fs_in.WorldPos);
+    int numLights = dirLightCount + int(cell.y);
+    for (int k = 0; k < numLights; ++k) {
+        int i = k < dirLightCount ? k : int(texelFetch(clusterLights, int(cell.x) + k - dirLightCount).r);
+        Light light = fetchLight(i);
         vec3 L;
         float attenuation = 1.0;
 
-        if (lightType[i] == 0) {
-            L = normalize(-lightDir[i]);
+        if (light.type == 0) {
+            L = normalize(-light.dir);
         } else {
-            vec3 toLight = lightPos[i] - fs_in.WorldPos;
+            vec3 toLight = light.pos - fs_in.WorldPos;
             float dist = length(toLight);
             L = normalize(toLight);
-            attenuation = 1.0 / (1.0 + (dist / lightRange[i]) * (dist / lightRange[i]));
+            attenuation = 1.0 / (1.0 + (dist / light.range) * (dist / light.range));
+            attenuation *= rangeWindow(dist, light.range);
         }
 
-        if (lightType[i] == 2) {
-            float cutoff = cos(radians(lightAngle[i]));
-            float spotFactor = dot(L, normalize(-lightDir[i]));
+        if (light.type == 2) {
+            float cutoff = cos(radians(light.angle));
+            float spotFactor = dot(L, normalize(-light.dir));
             if (spotFactor < cutoff) {
                 continue;
             }
@@ -354,15 +329,9 @@ void main()
         vec3 kd = (1.0 - F) * (1.0 - m);
         vec3 diffuse = kd * albedo / 3.14159;
 
-        float shadowFactor = 1.0;
-        if (shadowLightIndex >= 0 &&
-            i == shadowLightIndex &&
-            (lightType[i] == 0 || lightType[i] == 2)) {
-            float shadow = computeShadowPCF(fs_in.LightSpacePos, N, -lightDir[i]);
-            shadowFactor = 1.0 - shadow;
-        }
+        float shadowFactor = 1.0 - lightShadow(light, fs_in.WorldPos, viewDepth, N, L);
 
-        vec3 radiance = lightColor[i] * lightIntensity[i];
+        vec3 radiance = light.color * light.intensity;
      // --------------------------------------------------------
         // Clearcoat BRDF
         // --------------------------------------------------------
@@ -396,7 +365,7 @@ void main()
             float specCC = (Dcc * Gcc * Fcc) / denomCC;
 
             // Add clearcoat contribution
-            color += cc * specCC * lightColor[i] * lightIntensity[i] * NdotL * attenuation;
+            color += cc * specCC * light.color * light.intensity * NdotL * attenuation;
         }
         // --------------------------------------------------------
         // Sheen BRDF (cloth)
@@ -413,7 +382,7 @@ void main()
 
             float sheenTerm = D_sheen * NdotL * NdotV;
 
-            color += sheenTerm * F_sheen * lightColor[i] * lightIntensity[i] * attenuation;
+            color += sheenTerm * F_sheen * light.color * light.intensity * attenuation;
         }
 
         color += (diffuse + specular) * radiance * NdotL * attenuation * shadowFactor;
@@ -436,13 +405,13 @@ void main()
 
         // Specular IBL (prefiltered env + BRDF LUT)
         vec3 R = reflect(-V, N);
-        float mipCount = 5.0; // adjust to your prefiltered env mip levels
+        float mipCount = 5.0; // engine.PrefilterLevels - 1
         vec3 prefilteredColor = textureLod(prefilteredEnvMap, R, r * mipCount).rgb;
 
         vec2 brdfSample = texture(brdfLUT, vec2(max(dot(N, V), 0.0), r)).rg;
         vec3 specularIBL = prefilteredColor * (F_ibl * brdfSample.x + brdfSample.y);
 
-        color += diffuseIBL + specularIBL;
+        color += (diffuseIBL + specularIBL) * envIntensity;
     }
     
     // --------------------------------------------------------
@@ -486,12 +455,8 @@ void main()
     // Add emissive in linear space
     color += emissive;
 
-    // Tone map (ACES)
-    color = ACESFilm(color);
-
-    // Gamma correction (linear → sRGB)
-    color = pow(color, vec3(1.0/2.2));
-
-    FragColor = vec4(color, 1.0);
+    // Linear HDR radiance; exposure, tone mapping and gamma happen in
+    // post_tonemap_fragment.glsl.
+    FragColor = vec4(color, alphaMode == 2 ? alpha : 1.0);
 
 }
@@ -5,8 +5,16 @@ layout(location = 1) in vec3 aNormal;
 layout(location = 2) in vec2 aUV;
 layout(location = 3) in vec3 aTangent;
 layout(location = 4) in vec3 aBitangent;
-layout(location = 5) in uvec4 aJoints;
-layout(location = 6) in vec4 aWeights;
+
+// per-instance model matrix and colour (engine.Instance), used in place of
+// model and BaseColor for instanced draws
+layout(location = 7)  in mat4 instanceModel;
+layout(location = 11) in vec4 instanceColor;
+uniform bool useInstancing;
+
+// per-vertex colour (engine.VertexColorAttrib), multiplied into the base
+// colour when useVertexColor is set
+layout(location = 12) in vec4 aColor;
 
 
 uniform mat4 model;
@@ -23,12 +31,15 @@ out VS_OUT {
     vec3 Bitangent;
     vec4 LightSpacePos;
 } vs_out;
+out vec4 InstanceColor;
+out vec4 VertexColor;
 
 void main() {
-    vec4 world = model * vec4(aPos, 1.0);
+    mat4 M = useInstancing ? instanceModel : model;
+    vec4 world = M * vec4(aPos, 1.0);
     vs_out.WorldPos = world.xyz;
 
-    mat3 normalMatrix = mat3(model);
+    mat3 normalMatrix = mat3(M);
     vs_out.Normal    = normalize(normalMatrix * aNormal);
     vs_out.Tangent   = normalize(normalMatrix * aTangent);
     vs_out.Bitangent = normalize(normalMatrix * aBitangent);
@@ -37,6 +48,8 @@ void main() {
     vs_out.UV1 = aUV; // same for now; TexCoordMap can still switch
 
     vs_out.LightSpacePos = lightSpaceMatrix * world;
+    InstanceColor = instanceColor;
+    VertexColor = aColor;
 
     gl_Position = projection * view * world;
 }
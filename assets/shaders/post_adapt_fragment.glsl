@@ -0,0 +1,25 @@
+#version 410 core
+
+// Eye adaptation: moves last frame's luminance towards the metered
+// average (engine.AdaptLuminance).
+in vec2 vUV;
+out vec4 FragColor;
+
+uniform sampler2D lumTex;  // log2 luminance, averaged in its last mip
+uniform sampler2D prevTex; // adapted luminance of the previous frame
+uniform float lumLevel;
+uniform float dt;
+uniform float speedUp;
+uniform float speedDown;
+uniform int reset;
+
+void main()
+{
+    float target = exp2(textureLod(lumTex, vec2(0.5), lumLevel).r);
+    float current = texture(prevTex, vec2(0.5)).r;
+    if (reset == 1 || isnan(current) || current <= 0.0) {
+        current = target;
+    }
+    float speed = target > current ? speedUp : speedDown;
+    FragColor = vec4(current + (target - current) * (1.0 - exp(-dt * speed)), 0.0, 0.0, 1.0);
+}
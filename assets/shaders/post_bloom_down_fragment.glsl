@@ -0,0 +1,46 @@
+#version 410 core
+
+// Bloom downsample: 13-tap filter from Jimenez, "Next Generation Post
+// Processing in Call of Duty: Advanced Warfare". The first pass also
+// applies the soft-knee brightness threshold.
+in vec2 vUV;
+out vec4 FragColor;
+
+uniform sampler2D srcTex;
+uniform vec2 texelSize; // of srcTex
+uniform int prefilter;
+uniform float threshold;
+uniform float knee;
+
+vec3 tap(float x, float y)
+{
+    return texture(srcTex, vUV + vec2(x, y) * texelSize).rgb;
+}
+
+vec3 applyThreshold(vec3 c)
+{
+    float br = max(c.r, max(c.g, c.b));
+    float soft = clamp(br - threshold + knee, 0.0, 2.0 * knee);
+    soft = soft * soft / (4.0 * knee + 1e-4);
+    return c * max(soft, br - threshold) / max(br, 1e-4);
+}
+
+void main()
+{
+    vec3 a = tap(-2.0, -2.0), b = tap(0.0, -2.0), c = tap(2.0, -2.0);
+    vec3 d = tap(-1.0, -1.0), e = tap(1.0, -1.0);
+    vec3 f = tap(-2.0, 0.0), g = tap(0.0, 0.0), h = tap(2.0, 0.0);
+    vec3 i = tap(-1.0, 1.0), j = tap(1.0, 1.0);
+    vec3 k = tap(-2.0, 2.0), l = tap(0.0, 2.0), m = tap(2.0, 2.0);
+
+    vec3 color = (d + e + i + j) * 0.125;
+    color += (a + b + f + g) * 0.03125;
+    color += (b + c + g + h) * 0.03125;
+    color += (f + g + k + l) * 0.03125;
+    color += (g + h + l + m) * 0.03125;
+
+    if (prefilter == 1) {
+        color = applyThreshold(color);
+    }
+    FragColor = vec4(max(color, vec3(0.0)), 1.0);
+}
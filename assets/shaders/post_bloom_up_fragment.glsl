@@ -0,0 +1,22 @@
+#version 410 core
+
+// Bloom upsample: 3x3 tent over the smaller level, added to the matching
+// downsample level.
+in vec2 vUV;
+out vec4 FragColor;
+
+uniform sampler2D srcTex;  // smaller level
+uniform sampler2D baseTex; // downsample level of this size
+uniform vec2 texelSize;    // of srcTex
+uniform float radius;
+
+void main()
+{
+    vec2 o = texelSize * radius;
+    vec3 c = texture(srcTex, vUV).rgb * 4.0;
+    c += (texture(srcTex, vUV + vec2(-o.x, 0.0)).rgb + texture(srcTex, vUV + vec2(o.x, 0.0)).rgb +
+          texture(srcTex, vUV + vec2(0.0, -o.y)).rgb + texture(srcTex, vUV + vec2(0.0, o.y)).rgb) * 2.0;
+    c += texture(srcTex, vUV - o).rgb + texture(srcTex, vUV + o).rgb +
+         texture(srcTex, vUV + vec2(-o.x, o.y)).rgb + texture(srcTex, vUV + vec2(o.x, -o.y)).rgb;
+    FragColor = vec4(texture(baseTex, vUV).rgb + c / 16.0, 1.0);
+}
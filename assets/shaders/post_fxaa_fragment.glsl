@@ -0,0 +1,38 @@
+#version 410 core
+
+// FXAA on the tone-mapped image (Lottes' low-quality variant).
+in vec2 vUV;
+out vec4 FragColor;
+
+uniform sampler2D ldrTex;
+uniform vec2 texelSize;
+
+const float reduceMin = 1.0 / 128.0;
+const float reduceMul = 1.0 / 8.0;
+const float spanMax = 8.0;
+
+void main()
+{
+    const vec3 lumaW = vec3(0.299, 0.587, 0.114);
+    float lumaNW = dot(texture(ldrTex, vUV + vec2(-1.0, -1.0) * texelSize).rgb, lumaW);
+    float lumaNE = dot(texture(ldrTex, vUV + vec2(1.0, -1.0) * texelSize).rgb, lumaW);
+    float lumaSW = dot(texture(ldrTex, vUV + vec2(-1.0, 1.0) * texelSize).rgb, lumaW);
+    float lumaSE = dot(texture(ldrTex, vUV + vec2(1.0, 1.0) * texelSize).rgb, lumaW);
+    vec3 rgbM = texture(ldrTex, vUV).rgb;
+    float lumaM = dot(rgbM, lumaW);
+
+    float lumaMin = min(lumaM, min(min(lumaNW, lumaNE), min(lumaSW, lumaSE)));
+    float lumaMax = max(lumaM, max(max(lumaNW, lumaNE), max(lumaSW, lumaSE)));
+
+    vec2 dir = vec2(-((lumaNW + lumaNE) - (lumaSW + lumaSE)), (lumaNW + lumaSW) - (lumaNE + lumaSE));
+    float dirReduce = max((lumaNW + lumaNE + lumaSW + lumaSE) * 0.25 * reduceMul, reduceMin);
+    float rcpDirMin = 1.0 / (min(abs(dir.x), abs(dir.y)) + dirReduce);
+    dir = clamp(dir * rcpDirMin, vec2(-spanMax), vec2(spanMax)) * texelSize;
+
+    vec3 rgbA = 0.5 * (texture(ldrTex, vUV + dir * (1.0 / 3.0 - 0.5)).rgb +
+                       texture(ldrTex, vUV + dir * (2.0 / 3.0 - 0.5)).rgb);
+    vec3 rgbB = rgbA * 0.5 + 0.25 * (texture(ldrTex, vUV - dir * 0.5).rgb +
+                                     texture(ldrTex, vUV + dir * 0.5).rgb);
+    float lumaB = dot(rgbB, lumaW);
+    FragColor = vec4((lumaB < lumaMin || lumaB > lumaMax) ? rgbA : rgbB, 1.0);
+}
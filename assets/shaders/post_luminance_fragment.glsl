@@ -0,0 +1,23 @@
+#version 410 core
+
+// Writes log2 luminance of the HDR frame into the small metering target;
+// its mip chain then averages it down to one texel.
+in vec2 vUV;
+out vec4 FragColor;
+
+uniform sampler2D hdrTex;
+uniform vec2 texelSize; // of the metering target
+
+void main()
+{
+    // 4x4 taps per metering texel so small highlights are not skipped.
+    float sum = 0.0;
+    for (int y = 0; y < 4; y++) {
+        for (int x = 0; x < 4; x++) {
+            vec2 uv = vUV + (vec2(x, y) - 1.5) * 0.25 * texelSize;
+            vec3 c = texture(hdrTex, uv).rgb;
+            sum += log2(dot(c, vec3(0.2126, 0.7152, 0.0722)) + 1e-4);
+        }
+    }
+    FragColor = vec4(sum / 16.0, 0.0, 0.0, 1.0);
+}
@@ -0,0 +1,101 @@
+#version 410 core
+
+// Resolves the HDR frame to display colour: bloom, exposure, tone
+// mapping, vignette, gamma and an optional colour grading LUT. The maths
+// mirror engine/tonemap.go.
+in vec2 vUV;
+out vec4 FragColor;
+
+uniform sampler2D hdrTex;   // unit 0
+uniform sampler2D bloomTex; // unit 1
+uniform sampler2D adaptTex; // unit 2, adapted luminance
+uniform sampler3D lutTex;   // unit 3
+
+uniform int autoExposure;
+uniform float exposure; // manual multiplier, or compensation with autoExposure
+uniform vec2 evRange;   // EV100 clamp for autoExposure
+
+uniform int useBloom;
+uniform float bloomIntensity;
+
+uniform int tonemapper; // engine.TonemapOperator
+
+uniform float vignetteIntensity;
+uniform float vignetteSmoothness;
+
+uniform int useLUT;
+uniform float lutSize;
+uniform float lutContribution;
+uniform vec3 lutDomainMin;
+uniform vec3 lutDomainMax;
+
+const float hableWhite = 11.2;
+const float reinhardWhite = 4.0;
+
+float luminance(vec3 c)
+{
+    return dot(c, vec3(0.2126, 0.7152, 0.0722));
+}
+
+vec3 aces(vec3 x)
+{
+    return clamp((x * (2.51 * x + 0.03)) / (x * (2.43 * x + 0.59) + 0.14), 0.0, 1.0);
+}
+
+vec3 hable(vec3 x)
+{
+    const float A = 0.15, B = 0.50, C = 0.10, D = 0.20, E = 0.02, F = 0.30;
+    return (x * (A * x + C * B) + D * E) / (x * (A * x + B) + D * F) - E / F;
+}
+
+vec3 reinhard(vec3 c)
+{
+    float l = luminance(c);
+    if (l <= 0.0) {
+        return vec3(0.0);
+    }
+    return clamp(c * (1.0 + l / (reinhardWhite * reinhardWhite)) / (1.0 + l), 0.0, 1.0);
+}
+
+vec3 tonemap(vec3 c)
+{
+    if (tonemapper == 0) {
+        return aces(c);
+    } else if (tonemapper == 1) {
+        return clamp(hable(2.0 * c) / hable(vec3(hableWhite)), 0.0, 1.0);
+    } else if (tonemapper == 2) {
+        return reinhard(c);
+    }
+    return clamp(c, 0.0, 1.0);
+}
+
+void main()
+{
+    vec3 color = texture(hdrTex, vUV).rgb;
+    if (useBloom == 1) {
+        color += texture(bloomTex, vUV).rgb * bloomIntensity;
+    }
+
+    float e = exposure;
+    if (autoExposure == 1) {
+        float avg = texture(adaptTex, vec2(0.5)).r;
+        float ev100 = clamp(log2(max(avg, 1e-5) * 100.0 / 12.5), evRange.x, evRange.y);
+        e *= 1.0 / (1.2 * exp2(ev100));
+    }
+    color = tonemap(color * e);
+
+    if (vignetteIntensity > 0.0) {
+        float d = length(vUV - 0.5) * 1.41421356;
+        color *= 1.0 - vignetteIntensity * smoothstep(1.0 - vignetteSmoothness, 1.0, d);
+    }
+
+    color = pow(color, vec3(1.0 / 2.2));
+
+    if (useLUT == 1) {
+        vec3 uvw = clamp((color - lutDomainMin) / (lutDomainMax - lutDomainMin), 0.0, 1.0);
+        uvw = uvw * ((lutSize - 1.0) / lutSize) + 0.5 / lutSize;
+        color = mix(color, texture(lutTex, uvw).rgb, lutContribution);
+    }
+
+    FragColor = vec4(color, 1.0);
+}
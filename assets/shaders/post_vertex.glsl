@@ -0,0 +1,11 @@
+#version 410 core
+
+// Fullscreen triangle from gl_VertexID; draw 3 vertices with an empty VAO.
+out vec2 vUV;
+
+void main()
+{
+    vec2 p = vec2((gl_VertexID << 1) & 2, gl_VertexID & 2);
+    vUV = p;
+    gl_Position = vec4(p * 2.0 - 1.0, 0.0, 1.0);
+}
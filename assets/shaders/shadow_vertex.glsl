@@ -1,9 +1,11 @@
 #version 330 core
 layout(location = 0) in vec3 position;
+layout(location = 7) in mat4 instanceModel; // engine.Instance
 
 uniform mat4 model;
+uniform bool useInstancing;
 uniform mat4 lightSpaceMatrix;
 
 void main() {
-    gl_Position = lightSpaceMatrix * model * vec4(position, 1.0);
+    gl_Position = lightSpaceMatrix * (useInstancing ? instanceModel : model) * vec4(position, 1.0);
 }
@@ -15,6 +15,19 @@ layout(std140) uniform MaterialBlock {
 
 uniform vec3 viewPos;
 
+// alpha mode (0 opaque, 1 mask, 2 blend) and two-sided lighting
+uniform int   alphaMode;
+uniform float alphaCutoff;
+uniform bool  doubleSided;
+
+// instanced draws take the base colour per instance
+uniform bool useInstancing;
+in vec4 InstanceColor;
+
+// meshes with vertex colours tint the base colour with them
+uniform bool useVertexColor;
+in vec4 VertexColor;
+
 in vec3 Normal;
 in vec3 WorldPos;
 in vec2 UV;
@@ -22,12 +35,24 @@ in vec2 UV;
 out vec4 FragColor;
 
 void main() {
+    vec4 baseColor = useInstancing ? InstanceColor : BaseColor;
+    if (useVertexColor) {
+        baseColor *= VertexColor;
+    }
+
     if (materialType != 2) {
-        FragColor = BaseColor;
+        FragColor = baseColor;
         return;
     }
 
+    if (alphaMode == 1 && baseColor.a < alphaCutoff) {
+        discard;
+    }
+
     vec3 N = normalize(Normal);
+    if (doubleSided && !gl_FrontFacing) {
+        N = -N;
+    }
     vec3 L = normalize(vec3(0.4, -1.0, 0.3));
 
     float NdotL = dot(N, L);
@@ -36,5 +61,5 @@ void main() {
     float shade = floor(NdotL * levels) / levels;
     shade = max(shade, 0.0);
 
-    FragColor = vec4(BaseColor.rgb * shade, BaseColor.a);
+    FragColor = vec4(baseColor.rgb * shade, alphaMode == 2 ? baseColor.a : 1.0);
 }
@@ -4,6 +4,16 @@ layout(location = 0) in vec3 aPos;
 layout(location = 1) in vec3 aNormal;
 layout(location = 2) in vec2 aUV;
 
+// per-instance model matrix and colour (engine.Instance), used in place of
+// model and BaseColor for instanced draws
+layout(location = 7)  in mat4 instanceModel;
+layout(location = 11) in vec4 instanceColor;
+uniform bool useInstancing;
+
+// per-vertex colour (engine.VertexColorAttrib), multiplied into the base
+// colour when useVertexColor is set
+layout(location = 12) in vec4 aColor;
+
 uniform mat4 model;
 uniform mat4 view;
 uniform mat4 projection;
@@ -11,12 +21,17 @@ uniform mat4 projection;
 out vec3 Normal;
 out vec3 WorldPos;
 out vec2 UV;
+out vec4 InstanceColor;
+out vec4 VertexColor;
 
 void main() {
-    vec4 world = model * vec4(aPos, 1.0);
+    mat4 M = useInstancing ? instanceModel : model;
+    vec4 world = M * vec4(aPos, 1.0);
     WorldPos = world.xyz;
-    Normal = mat3(model) * aNormal;
+    Normal = mat3(M) * aNormal;
     UV = aUV;
+    InstanceColor = instanceColor;
+    VertexColor = aColor;
 
     gl_Position = projection * view * world;
 }
@@ -4,6 +4,20 @@ layout(location = 1) in vec3 normal;
 layout(location = 2) in vec2 texcoord;
 layout(location = 3) in vec4 aTangent;
 
+// per-instance model matrix and colour (engine.Instance), used in place of
+// model and BaseColor for instanced draws
+layout(location = 7)  in mat4 instanceModel;
+layout(location = 11) in vec4 instanceColor;
+uniform bool useInstancing;
+
+// per-vertex colour (engine.VertexColorAttrib), multiplied into the base
+// colour when useVertexColor is set
+layout(location = 12) in vec4 aColor;
+
+#ifdef SKINNED
+#include "include/skinning.glsl"
+#endif
+
 uniform mat4 model;
 uniform mat4 view;
 uniform mat4 projection;
@@ -15,17 +29,32 @@ out vec3 Normal;
 out vec3 Tangent;
 out float TangentW;
 out vec2 TexCoord;
+out vec4 InstanceColor;
+out vec4 VertexColor;
 
 void main() {
-    vec4 worldPos = model * vec4(position, 1.0);
+    vec3 pos = position;
+    vec3 nrm = normal;
+    vec3 tan = aTangent.xyz;
+#ifdef SKINNED
+    // skinned meshes are never instanced
+    skinVertex(pos, nrm, tan);
+    mat4 M = model;
+    InstanceColor = vec4(1.0);
+#else
+    mat4 M = useInstancing ? instanceModel : model;
+    InstanceColor = instanceColor;
+#endif
+    vec4 worldPos = M * vec4(pos, 1.0);
     FragPos       = worldPos.xyz;
     LightSpacePos = lightSpaceMatrix * worldPos;
 
-    mat3 normalMatrix = mat3(transpose(inverse(model)));
-    Normal  = normalMatrix * normal;
-    Tangent = normalMatrix * aTangent.xyz;
+    mat3 normalMatrix = mat3(transpose(inverse(M)));
+    Normal  = normalMatrix * nrm;
+    Tangent = normalMatrix * tan;
     TangentW = aTangent.w;
 
     TexCoord = texcoord;
+    VertexColor = aColor;
     gl_Position = projection * view * worldPos;
 }
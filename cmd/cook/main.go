@@ -0,0 +1,45 @@
+// Command cook pre-processes assets/ into engine-native mesh and texture
+// bundles that cmd/game loads instead of the source files.
+//
+//	go run ./cmd/cook [-src assets] [-out assets/.cooked] [-force] [-compress]
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"sort"
+
+	"go-engine/Go-Cordance/internal/cook"
+)
+
+func main() {
+	opts := cook.Options{}
+	flag.StringVar(&opts.SrcDir, "src", "assets", "asset root containing models/ and textures/")
+	flag.StringVar(&opts.OutDir, "out", cook.DefaultOutDir, "output directory for cooked bundles and the manifest")
+	flag.BoolVar(&opts.Force, "force", false, "rebuild every asset even if unchanged")
+	flag.BoolVar(&opts.Compress, "compress", true, "zlib-compress texture mip chains")
+	flag.Parse()
+
+	res, err := cook.Run(opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, src := range res.Cooked {
+		log.Printf("cooked  %s", src)
+	}
+	failed := make([]string, 0, len(res.Failed))
+	for src := range res.Failed {
+		failed = append(failed, src)
+	}
+	sort.Strings(failed)
+	for _, src := range failed {
+		log.Printf("FAILED  %s: %v", src, res.Failed[src])
+	}
+	log.Printf("%d cooked, %d up to date, %d failed", len(res.Cooked), len(res.Skipped), len(failed))
+
+	if len(failed) > 0 {
+		os.Exit(1)
+	}
+}
@@ -2,6 +2,7 @@ package loader
 
 import (
 	"go-engine/Go-Cordance/internal/assets"
+	"go-engine/Go-Cordance/internal/cook"
 	"go-engine/Go-Cordance/internal/engine"
 	"go-engine/Go-Cordance/internal/shaderlang"
 	"log"
@@ -31,15 +32,34 @@ func LoadMaterials() {
 			log.Printf("Skipping already-loaded Material: %s", full)
 			continue
 		}
-		id, err := assets.LoadMaterial(full)
-		if err != nil {
-			log.Printf("Failed to load material %s: %v", full, err)
+		assets.Async.LoadMaterialAsync(full)
+		log.Printf("Queued material %s", full)
+	}
+
+}
+
+// LoadClips registers standalone animation clips (*.clip.json, *.clip).
+// Clips recorded in the editor are written here, so the folder may not
+// exist yet.
+func LoadClips() {
+	clipDir := "assets/animations"
+	entries, err := os.ReadDir(clipDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Failed to read %s: %v", clipDir, err)
+		}
+		return
+	}
+
+	for _, e := range entries {
+		if e.IsDir() || !assets.IsClipPath(e.Name()) {
 			continue
 		}
 
-		log.Printf("Loaded material asset %d from %s", id, full)
+		full := filepath.Join(clipDir, e.Name())
+		assets.Async.LoadClipAsync(full)
+		log.Printf("Queued clip %s", full)
 	}
-
 }
 
 func LoadTextures() {
@@ -48,12 +68,16 @@ func LoadTextures() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	cooked := cook.Open(cook.DefaultOutDir)
 
 	for _, e := range entries {
 		if e.IsDir() {
 			continue
 		}
 
+		if assets.IsMetaPath(e.Name()) {
+			continue
+		}
 		ext := filepath.Ext(e.Name())
 		if ext != ".png" && ext != ".jpg" && ext != ".jpeg" {
 			log.Printf("File not allowed as Texture: %s", e.Name())
@@ -68,13 +92,10 @@ func LoadTextures() {
 			continue
 		}
 
-		id, _, err := assets.ImportTexture(full)
-		if err != nil {
-			log.Printf("Failed to load Texture %s: %v", full, err)
-			continue
-		}
-
-		log.Printf("Loaded Texture asset %d from %s", id, full)
+		// Prefer the cooked mip chain; the loader falls back to the source.
+		cookedPath, _ := cooked.Cooked(full)
+		f := assets.Async.LoadTextureAsync(full, cookedPath)
+		log.Printf("Queued Texture asset %d from %s", f.ID(), full)
 
 	}
 }
@@ -108,28 +129,28 @@ func LoadShaders() {
 			log.Printf("Failed to load shader %s: %v", full, err)
 			continue
 		}
-		// After: id, err := assets.LoadShader(full)
+		registerShaderMeta(assets.Get(id).Data.(shaderlang.ShaderSource))
 
-		a := assets.Get(id) // <-- FIX: fetch the asset struct
-
-		src := a.Data.(shaderlang.ShaderSource)
-
-		// Register metadata
-		ShaderMetaMap[src.Name] = ShaderMeta{
-			Name:     src.Name,
-			Vertex:   src.VertexPath,
-			Fragment: src.FragmentPath,
-			Defines:  src.Defines, // <-- REQUIRED
-		}
+		log.Printf("Loaded shader asset %d from %s", id, full)
 
-		// Map GLSL filenames → shader name
-		FileToShader[filepath.Base(src.VertexPath)] = src.Name
-		FileToShader[filepath.Base(src.FragmentPath)] = src.Name
+	}
 
-		log.Printf("Loaded shader asset %d from %s", id, full)
+}
 
+// registerShaderMeta records what ReloadShader needs to recompile src.
+func registerShaderMeta(src shaderlang.ShaderSource) {
+	ShaderMetaMap[src.Name] = ShaderMeta{
+		Name:     src.Name,
+		Vertex:   src.VertexPath,
+		Fragment: src.FragmentPath,
+		Defines:  src.Defines,
+		Keywords: src.Keywords,
 	}
+	registerShaderVariants(src)
 
+	// Map GLSL filenames → shader name
+	FileToShader[filepath.Base(src.VertexPath)] = src.Name
+	FileToShader[filepath.Base(src.FragmentPath)] = src.Name
 }
 
 func LoadAllShaders() error {
@@ -140,22 +161,10 @@ func LoadAllShaders() error {
 
 		src := a.Data.(shaderlang.ShaderSource)
 
-		// Load GLSL text
-		vert, err := shaderlang.LoadGLSL(src.VertexPath)
-		if err != nil {
-			return err
-		}
-		frag, err := shaderlang.LoadGLSL(src.FragmentPath)
-		if err != nil {
-			return err
-		}
-
-		// Apply defines
-		vert = shaderlang.ApplyDefines(vert, src.Defines)
-		frag = shaderlang.ApplyDefines(frag, src.Defines)
-
-		// Compile in engine
-		prog, err := engine.LoadShaderProgram(src.Name, vert, frag)
+		// Compile the base variant now; keyword variants compile on first
+		// use (engine.GetShaderVariant).
+		registerShaderVariants(src)
+		prog, err := engine.GetShaderVariant(src.Name)
 		if err != nil {
 			return err
 		}
@@ -165,20 +174,36 @@ func LoadAllShaders() error {
 
 	return nil
 }
+
+// registerShaderVariants tells the engine how to build the variants of
+// src: both stages preprocessed with the variant's keywords, compile
+// errors pointing at the GLSL file and line they come from.
+func registerShaderVariants(src shaderlang.ShaderSource) {
+	engine.RegisterShaderVariants(src.Name, src.Keywords, func(keywords []string) (engine.ShaderVariantSource, error) {
+		vert, frag, pp, err := src.Build(keywords)
+		if err != nil {
+			return engine.ShaderVariantSource{}, err
+		}
+		return engine.ShaderVariantSource{Vertex: vert, Fragment: frag, MapError: pp.MapError}, nil
+	})
+}
 func LoadMeshes(meshMgr *engine.MeshManager) {
 	meshDir := "assets/models"
 	entries, err := os.ReadDir(meshDir)
 	if err != nil {
 		log.Fatal(err)
 	}
+	cooked := cook.Open(cook.DefaultOutDir)
 
 	for _, e := range entries {
 		if e.IsDir() {
 			continue
 		}
 
-		ext := filepath.Ext(e.Name())
-		if ext != ".gltf" && ext != ".glb" && ext != ".obj" {
+		if assets.IsMetaPath(e.Name()) {
+			continue
+		}
+		if !assets.IsModelPath(e.Name()) {
 			log.Printf("Skipping non-mesh file: %s", e.Name())
 			continue
 		}
@@ -191,30 +216,9 @@ func LoadMeshes(meshMgr *engine.MeshManager) {
 			continue
 		}
 
-		// --- Load mesh asset ---
-		var assetID assets.AssetID
-		var meshIDs []string
-
-		switch ext {
-		case ".gltf", ".glb":
-			id, ids, err := assets.ImportGLTFMulti(full, meshMgr)
-			if err != nil {
-				log.Printf("Failed to load GLTF mesh %s: %v", full, err)
-				continue
-			}
-			assetID = id
-			meshIDs = ids
-
-		case ".obj":
-			id, meshID, err := assets.ImportOBJ(full, meshMgr)
-			if err != nil {
-				log.Printf("Failed to load OBJ mesh %s: %v", full, err)
-				continue
-			}
-			assetID = id
-			meshIDs = []string{meshID}
-		}
-
-		log.Printf("Loaded mesh asset %d from %s (submeshes: %v)", assetID, full, meshIDs)
+		// --- Prefer the cooked bundle, fall back to the source ---
+		cookedPath, _ := cooked.Cooked(full)
+		assets.Async.LoadMeshAsync(full, cookedPath, cooked.Deps(full), meshMgr)
+		log.Printf("Queued mesh %s", full)
 	}
 }
@@ -0,0 +1,88 @@
+package loader
+
+import (
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// AssetChangeQueue delivers batches of changed files (slash-separated) to
+// the main loop, which passes them to ReloadChangedAssets.
+var AssetChangeQueue = make(chan []string, 8)
+
+// watchDebounce groups the several events an editor save produces.
+const watchDebounce = 150 * time.Millisecond
+
+// StartAssetWatcher watches root and all its subdirectories except hidden
+// ones such as the cook output.
+func StartAssetWatcher(root string) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Println("asset watcher create error:", err)
+		return
+	}
+
+	addTree := func(dir string) {
+		filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return nil
+			}
+			if p != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if err := watcher.Add(p); err != nil {
+				log.Println("asset watcher add error:", err)
+			}
+			return nil
+		})
+	}
+	addTree(root)
+
+	go func() {
+		pending := map[string]bool{}
+		timer := time.NewTimer(watchDebounce)
+		timer.Stop()
+		for {
+			select {
+			case ev := <-watcher.Events:
+				if ev.Op&(fsnotify.Write|fsnotify.Create) == 0 || ignoredAssetFile(ev.Name) {
+					continue
+				}
+				if ev.Op&fsnotify.Create != 0 && isDir(ev.Name) {
+					addTree(ev.Name)
+					continue
+				}
+				pending[filepath.ToSlash(ev.Name)] = true
+				timer.Reset(watchDebounce)
+			case <-timer.C:
+				batch := make([]string, 0, len(pending))
+				for p := range pending {
+					batch = append(batch, p)
+				}
+				sort.Strings(batch)
+				pending = map[string]bool{}
+				AssetChangeQueue <- batch
+			case err := <-watcher.Errors:
+				log.Println("asset watcher error:", err)
+			}
+		}
+	}()
+}
+
+// ignoredAssetFile filters editor swap files and the cooker's temp outputs.
+func ignoredAssetFile(path string) bool {
+	base := filepath.Base(path)
+	return strings.HasPrefix(base, ".") || strings.HasSuffix(base, "~") ||
+		strings.HasSuffix(base, ".tmp") || strings.HasSuffix(base, ".swp")
+}
+
+func isDir(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
@@ -0,0 +1,163 @@
+package loader
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"go-engine/Go-Cordance/internal/assets"
+	"go-engine/Go-Cordance/internal/ecs"
+	"go-engine/Go-Cordance/internal/engine"
+	"go-engine/Go-Cordance/internal/scene"
+	"go-engine/Go-Cordance/internal/shaderlang"
+)
+
+// ReloadChangedAssets reloads the changed files and every asset that
+// depends on them, dependencies first, and updates live entities in place.
+// Prefab instances are rebuilt from their file instead. Shader programs are
+// queued on ReloadQueue. It must run on the GL thread and returns the asset
+// paths that were reloaded.
+func ReloadChangedAssets(changed []string, mm *engine.MeshManager, sc *scene.Scene) []string {
+	var reloaded []string
+	for _, path := range assets.Deps.ReloadOrder(changed) {
+		ents := sc.Entities()
+		a := assets.FindAssetByPath(path)
+		if a == nil {
+			if n, err := sc.ReloadPrefab(path); err != nil {
+				log.Printf("[HotReload] %s: %v", path, err)
+				continue
+			} else if n > 0 {
+				log.Printf("[HotReload] rebuilt %d instance(s) of %s", n, path)
+				reloaded = append(reloaded, path)
+				continue
+			}
+			// New files are imported; anything else is a plain input such
+			// as GLSL or a .bin buffer whose dependents follow in order.
+			if imported, err := importNewAsset(path, mm); err != nil {
+				log.Printf("[HotReload] %s: %v", path, err)
+			} else if imported {
+				log.Printf("[HotReload] imported %s", path)
+				reloaded = append(reloaded, path)
+			}
+			continue
+		}
+		if err := reloadAsset(a, mm, ents); err != nil {
+			log.Printf("[HotReload] %s: %v", path, err)
+			continue
+		}
+		log.Printf("[HotReload] reloaded %s", path)
+		reloaded = append(reloaded, path)
+	}
+	return reloaded
+}
+
+func reloadAsset(a *assets.Asset, mm *engine.MeshManager, ents []*ecs.Entity) error {
+	switch a.Type {
+	case assets.AssetTexture:
+		td, ok := a.Data.(assets.TextureData)
+		if !ok {
+			return fmt.Errorf("texture has no GL data")
+		}
+		if err := engine.ReloadTexture(td.GLID, a.Path); err != nil {
+			return err
+		}
+		s := assets.ImportSettingsFor(a.Path)
+		td.SRGB, td.NormalMap = s.SRGB, s.NormalMap
+		a.Data = td
+
+	case assets.AssetMesh:
+		return reloadMesh(a, mm)
+
+	case assets.AssetMaterial:
+		if _, err := assets.LoadMaterial(a.Path); err != nil {
+			return err
+		}
+		forEachMaterial(ents, func(m *ecs.Material) {
+			if m.MaterialAsset == a.ID {
+				m.ApplyMaterialFile(a.ID)
+			}
+		})
+
+	case assets.AssetShader:
+		if _, err := assets.LoadShader(a.Path); err != nil {
+			return err
+		}
+		src := a.Data.(shaderlang.ShaderSource)
+		registerShaderMeta(src)
+		queueShaderReload(src.Name)
+
+	case assets.AssetAnimationClip:
+		if _, err := assets.LoadClip(a.Path); err != nil {
+			return err
+		}
+		for _, e := range ents {
+			if c := e.GetComponent((*ecs.AnimationPlayer)(nil)); c != nil {
+				c.(*ecs.AnimationPlayer).ReloadClipSource(a.Path)
+			}
+		}
+	}
+	return nil
+}
+
+// importNewAsset registers a file created while running if its folder and
+// extension mark it as an asset. Shaders are left for a restart because
+// their programs are compiled at startup.
+func importNewAsset(path string, mm *engine.MeshManager) (bool, error) {
+	if assets.IsMetaPath(path) {
+		return false, nil
+	}
+	dir := filepath.Base(filepath.Dir(path))
+	ext := strings.ToLower(filepath.Ext(path))
+	var err error
+	switch {
+	case dir == "textures" && (ext == ".png" || ext == ".jpg" || ext == ".jpeg"):
+		_, _, err = assets.ImportTexture(path)
+	case dir == "models" && assets.IsModelPath(path):
+		_, err = assets.ImportModel(path, mm)
+	case dir == "materials" && ext == ".mat":
+		_, err = assets.LoadMaterial(path)
+	case assets.IsClipPath(path):
+		_, err = assets.LoadClip(path)
+	default:
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// reloadMesh re-imports a model under its existing mesh IDs, so Mesh and
+// MultiMesh components keep pointing at it.
+func reloadMesh(a *assets.Asset, mm *engine.MeshManager) error {
+	var oldIDs []string
+	switch d := a.Data.(type) {
+	case string:
+		oldIDs = []string{d}
+	case []string:
+		oldIDs = d
+	}
+	for _, id := range oldIDs {
+		mm.ReleaseMesh(id)
+	}
+
+	var err error
+	ext := strings.ToLower(filepath.Ext(a.Path))
+	if single, ok := a.Data.(string); ok && (ext == ".gltf" || ext == ".glb") {
+		_, err = assets.ImportGLTFMesh(single, a.Path, mm)
+	} else {
+		_, err = assets.ImportModel(a.Path, mm)
+	}
+	return err
+}
+
+func forEachMaterial(ents []*ecs.Entity, fn func(m *ecs.Material)) {
+	for _, e := range ents {
+		if c := e.GetComponent((*ecs.Material)(nil)); c != nil {
+			fn(c.(*ecs.Material))
+		}
+		if c := e.GetComponent((*ecs.MultiMaterial)(nil)); c != nil {
+			for _, m := range c.(*ecs.MultiMaterial).Materials {
+				fn(m)
+			}
+		}
+	}
+}
@@ -10,14 +10,15 @@ import (
 	"go-engine/Go-Cordance/internal/shaderlang"
 )
 
+// ReloadShader recompiles every variant of shaderName built so far, in
+// place, once its stages have finished being written.
 func ReloadShader(shaderName string) error {
 	meta, ok := ShaderMetaMap[shaderName]
 	if !ok {
 		return fmt.Errorf("no ShaderMeta for %s", shaderName)
 	}
 
-	var vert, frag string
-
+	ready := false
 	for i := 0; i < 5; i++ {
 		v, err1 := shaderlang.LoadGLSL(meta.Vertex)
 		f, err2 := shaderlang.LoadGLSL(meta.Fragment)
@@ -25,23 +26,20 @@ func ReloadShader(shaderName string) error {
 		if err1 == nil && err2 == nil &&
 			len(strings.TrimSpace(v)) > 0 &&
 			len(strings.TrimSpace(f)) > 0 {
-
-			vert = shaderlang.ApplyDefines(v, meta.Defines)
-			frag = shaderlang.ApplyDefines(f, meta.Defines)
+			ready = true
 			break
 		}
 
 		time.Sleep(120 * time.Millisecond)
 	}
 
-	if len(strings.TrimSpace(vert)) == 0 || len(strings.TrimSpace(frag)) == 0 {
+	if !ready {
 		return fmt.Errorf("shader %s still empty after retries", shaderName)
 	}
 
-	log.Printf("[ShaderReload] Compiling %s (vert=%d, frag=%d)", shaderName, len(vert), len(frag))
+	log.Printf("[ShaderReload] Compiling %s (variants %q)", shaderName, engine.ShaderVariantKeys(shaderName))
 
-	sp := engine.MustGetShaderProgram(shaderName)
-	if err := sp.Reload(vert, frag); err != nil {
+	if err := engine.ReloadShaderVariants(shaderName); err != nil {
 		return fmt.Errorf("reload failed for %s: %w", shaderName, err)
 	}
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
@@ -25,6 +26,10 @@ import (
 const (
 	width  = 800
 	height = 600
+
+	// uploadBudget caps the time spent per frame uploading assets that were
+	// decoded in the background.
+	uploadBudget = 4 * time.Millisecond
 )
 
 func initUndo() {
@@ -56,27 +61,31 @@ func main() {
 	if err := loader.LoadAllShaders(); err != nil {
 		log.Fatalf("Shader compile error: %v", err)
 	}
-	loader.StartShaderWatcher()
+	loader.StartAssetWatcher("assets")
+	loader.StartAssetWatcher("prefabs")
 
 	prog := engine.MustGetShaderProgram("default_shader")
 	renderer := engine.NewRendererWithProgram(prog.ID, width, height)
 	renderer.InitUniforms()
 
 	shadow_prog := engine.MustGetShaderProgram("shadow_shader")
-	// initialize shadow resources (choose resolution)
-	shadowW, shadowH := 2048, 2048
+	// initialize the shadow atlas; cascades, spot and point light faces
+	// share it (see engine.ShadowSettings)
+	shadowW, shadowH := 4096, 4096
 	renderer.InitShadowWithProgram(shadow_prog.ID, shadowW, shadowH)
 
-	// Resize callback updates viewport
+	// Resize callback updates viewport and the size the HDR target follows
 	window.SetFramebufferSizeCallback(func(_ *glfw.Window, w, h int) {
 		if h == 0 {
 			h = 1
 		}
 		gl.Viewport(0, 0, int32(w), int32(h))
+		renderer.ScreenWidth, renderer.ScreenHeight = w, h
 	})
 
 	// Mesh manager and registrations (runtime)
 	meshMgr := engine.NewMeshManager()
+	assets.Lifetime.SetBackend(engine.GLAssetBackend{Meshes: meshMgr})
 	engine.InitThumbnailRenderer(renderer, meshMgr, 256, 256)
 	engine.GlobalMeshManager = meshMgr
 	meshMgr.RegisterTriangle("triangle")
@@ -121,22 +130,18 @@ func main() {
 
 	// Load textures (runtime GPU resources)
 	// Load textures via asset pipeline (non-breaking)
-	crateAsset, crateGL, err := assets.ImportTexture("assets/textures/crate.png")
-	if err != nil {
-		log.Fatal(err)
-	}
+	// Decoded in the background; until then the GL IDs hold a placeholder
+	// that is filled in place.
+	crateAsset := assets.Async.LoadTextureAsync("assets/textures/crate.png", "").ID()
+	crateGL := assets.ResolveTextureGLID(crateAsset)
 	ecs.RegisterTexture("Crate", crateGL)
 
-	teapotAsset, teapotGL, err := assets.ImportTexture("assets/textures/teapot_diffuse.png")
-	if err != nil {
-		log.Fatal(err)
-	}
+	teapotAsset := assets.Async.LoadTextureAsync("assets/textures/teapot_diffuse.png", "").ID()
+	teapotGL := assets.ResolveTextureGLID(teapotAsset)
 	ecs.RegisterTexture("Teapot", teapotGL)
 
-	goldyAsset, goldyGL, err := assets.ImportTexture("assets/textures/goldy.jpg")
-	if err != nil {
-		log.Fatal(err)
-	}
+	goldyAsset := assets.Async.LoadTextureAsync("assets/textures/goldy.jpg", "").ID()
+	goldyGL := assets.ResolveTextureGLID(goldyAsset)
 	ecs.RegisterTexture("Goldy", goldyGL)
 
 	// Load GLTF materials info (runtime)
@@ -148,6 +153,7 @@ func main() {
 
 	loader.LoadMaterials()
 	loader.LoadTextures()
+	loader.LoadClips()
 
 	// Create runtime wrappers for textures (ecs.Texture holds GPU id)
 	crateTex := ecs.NewTexture(crateGL)
@@ -192,6 +198,7 @@ func main() {
 	sc.Systems().AddSystem(ecs.NewCollisionSystem())
 	sc.Systems().AddSystem(animSys)
 	sc.Systems().AddSystem(ecs.NewTransformSystem())
+	sc.Systems().AddSystem(ecs.NewIKSystem())
 	sc.Systems().AddSystem(billboardSys)
 	sc.Systems().AddSystem(camCtrl)
 	sc.Systems().AddSystem(camSys)
@@ -224,6 +231,12 @@ func main() {
 	t2.Scale = [3]float32{0.1, 0.1, 0.1}
 	t2.SetRotationDegrees(90, 90, 90)
 
+	violinCase, err := gltf.LoadOBJ(sc, "assets/models/violincase.obj")
+	if err != nil {
+		log.Fatal(err)
+	}
+	violinCase.GetTransform().Position = [3]float32{3, 0.5, 0}
+
 	cesiumInstance, err := gltf.LoadGLTFMultiSkinnedAttached(
 		sc,
 		"assets/models/CesiumMan/CesiumMan.glb",
@@ -294,6 +307,11 @@ func main() {
 	cesiumInstance, _ = gltf.LoadGLTFMultiSkinnedAttached(sc, "assets/models/CesiumMan/CesiumMan.glb", nil)
 	//crawlingInstance, _ := gltf.LoadGLTFMultiSkinnedAttached(sc, "assets/models/crawling-man/crawling_man.glb", nil)
 
+	if profile, err := gltf.LoadRigProfile("assets/rigs/cesium_man.rig.json"); err == nil {
+		gltf.RegisterRigProfile(profile)
+	} else {
+		log.Printf("rig profile: %v", err)
+	}
 	cesiumRig := gltf.BuildHumanoidRigFromGLTF(cesiumInstance.GltfRoot, cesiumInstance.NodeEntities)
 	crawlingRig := gltf.BuildHumanoidRigFromGLTF(crawlingInstance.GltfRoot, crawlingInstance.NodeEntities)
 
@@ -308,7 +326,8 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	_ = retargeted
+	crawlingClips[retargeted.Name] = retargeted
+	gltf.AddFootPlacement(crawlingRig)
 	fmt.Println("CrawlingMan clips:", len(crawlingClips))
 	for name := range crawlingClips {
 		fmt.Println(" -", name)
@@ -498,6 +517,7 @@ func main() {
 		}
 	}
 	bridge.SendTransformToEditorFinal = func(id int64, pos [3]float32, rot [4]float32, scale [3]float32) {
+		editorlink.RecordTransformEdit(id, pos, rot, scale)
 		if editorlink.EditorConn != nil {
 			msg := editorlink.MsgSetTransform{
 				ID:       uint64(id),
@@ -511,6 +531,7 @@ func main() {
 
 	// Main loop
 	last := glfw.GetTime()
+	nextCollect := last
 	for !window.ShouldClose() {
 		now := glfw.GetTime()
 		dt := float32(now - last)
@@ -557,15 +578,41 @@ func main() {
 			if editorlink.EditorConn != nil {
 				editorlink.SendAssetList(editorlink.EditorConn)
 			}
+		case changed := <-loader.AssetChangeQueue:
+			reloaded := loader.ReloadChangedAssets(changed, meshMgr, sc)
+			if len(reloaded) > 0 && editorlink.EditorConn != nil {
+				editorlink.SendAssetList(editorlink.EditorConn)
+				editorlink.SendFullSnapshot(sc)
+				editorlink.WriteAssetsReloaded(editorlink.EditorConn, reloaded)
+			}
 		default:
 		}
 
+		// Upload background-decoded assets within the frame budget.
+		if assets.Async.Pump(uploadBudget) > 0 && assets.Async.Pending() == 0 && editorlink.EditorConn != nil {
+			editorlink.SendAssetList(editorlink.EditorConn)
+		}
+
+		// Refresh scene references and unload assets past their grace period.
+		if now >= nextCollect {
+			nextCollect = now + 1
+			assets.Lifetime.Retain("scene", sc.AssetRefs())
+			if unloaded := assets.Lifetime.Collect(); len(unloaded) > 0 {
+				log.Printf("[Main] unloaded %d unreferenced asset(s)", len(unloaded))
+				if editorlink.EditorConn != nil {
+					editorlink.SendAssetList(editorlink.EditorConn)
+				}
+			}
+		}
+
 		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 		// determine selected entity pointer as you already do for other editor features
 
 		// ... set selected appropriately ...
 
 		sc.Update(dt)
+		// tone map the HDR frame to the screen; gizmos draw on top untouched
+		renderSys.Present()
 
 		// debug: draw gizmo on top (disable depth to rule out occlusion)
 		gl.Disable(gl.DEPTH_TEST)
@@ -9,10 +9,13 @@ const (
 	AssetMesh
 	AssetMaterial
 	AssetShader
+	AssetAnimationClip
+	AssetEnvironment
 )
 
 type Asset struct {
 	ID   AssetID
+	GUID GUID
 	Type AssetType
 	Path string
 	Data any
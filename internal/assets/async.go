@@ -0,0 +1,171 @@
+package assets
+
+import (
+	"log"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// Asynchronous loading is split in two: a decode step that runs on a worker
+// goroutine and touches only files and CPU memory, and an upload step that
+// runs on the GL thread from AsyncLoader.Pump and may call GL and mutate the
+// registry. Decoded payloads wait in a bounded queue, so a slow frame rate
+// throttles the workers instead of piling up memory.
+
+// Upload is the main-thread half of a load. It returns the asset it
+// registered or updated.
+type Upload func() (AssetID, error)
+
+// Future resolves once a load has been uploaded (or has failed).
+type Future struct {
+	done chan struct{}
+	id   AssetID
+	err  error
+}
+
+func newFuture(id AssetID) *Future {
+	return &Future{done: make(chan struct{}), id: id}
+}
+
+// Resolved returns a Future that is already complete.
+func Resolved(id AssetID, err error) *Future {
+	f := newFuture(id)
+	f.resolve(id, err)
+	return f
+}
+
+func (f *Future) resolve(id AssetID, err error) {
+	if id != 0 {
+		f.id = id
+	}
+	f.err = err
+	close(f.done)
+}
+
+// Done is closed when the load has finished.
+func (f *Future) Done() <-chan struct{} { return f.done }
+
+// Ready reports whether the load has finished.
+func (f *Future) Ready() bool {
+	select {
+	case <-f.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// ID returns the asset ID. Loads that register a placeholder (textures,
+// single-mesh models) know it from the start; others once Ready.
+func (f *Future) ID() AssetID { return f.id }
+
+// Err returns the load error once Ready.
+func (f *Future) Err() error {
+	if !f.Ready() {
+		return nil
+	}
+	return f.err
+}
+
+type pendingUpload struct {
+	key    string
+	fut    *Future
+	upload Upload
+	err    error
+}
+
+// AsyncLoader runs decode steps on a fixed number of workers and hands their
+// uploads to Pump. Submit and Pump must be called from the GL thread.
+type AsyncLoader struct {
+	sem      chan struct{}
+	uploads  chan pendingUpload
+	inflight map[string]*Future
+	pending  atomic.Int32
+}
+
+// Async is the loader used by the game's folder scans.
+var Async = NewAsyncLoader(runtime.NumCPU(), 16)
+
+// NewAsyncLoader creates a loader with the given worker count and upload
+// queue capacity.
+func NewAsyncLoader(workers, queue int) *AsyncLoader {
+	return &AsyncLoader{
+		sem:      make(chan struct{}, max(workers, 1)),
+		uploads:  make(chan pendingUpload, max(queue, 1)),
+		inflight: map[string]*Future{},
+	}
+}
+
+// Submit schedules decode on a worker. key identifies the load (usually the
+// path); submitting a key that is still in flight returns the same Future.
+// placeholder is the asset ID to report before the load completes, or 0.
+func (l *AsyncLoader) Submit(key string, placeholder AssetID, decode func() (Upload, error)) *Future {
+	if f, ok := l.inflight[key]; ok {
+		return f
+	}
+	f := newFuture(placeholder)
+	l.inflight[key] = f
+	l.pending.Add(1)
+
+	go func() {
+		l.sem <- struct{}{}
+		up, err := decode()
+		// Keep the worker slot until the payload is queued so decoded data
+		// never exceeds workers + queue capacity.
+		l.uploads <- pendingUpload{key: key, fut: f, upload: up, err: err}
+		<-l.sem
+	}()
+	return f
+}
+
+// Pump runs queued uploads until budget is spent, always at least one if
+// any is queued, and returns how many it ran.
+func (l *AsyncLoader) Pump(budget time.Duration) int {
+	start := time.Now()
+	n := 0
+	for {
+		select {
+		case p := <-l.uploads:
+			id, err := AssetID(0), p.err
+			if err == nil && p.upload != nil {
+				id, err = p.upload()
+			}
+			if err != nil {
+				log.Printf("async load %s: %v", p.key, err)
+			}
+			delete(l.inflight, p.key)
+			l.pending.Add(-1)
+			p.fut.resolve(id, err)
+			n++
+		default:
+			return n
+		}
+		if time.Since(start) >= budget {
+			return n
+		}
+	}
+}
+
+// Pending returns the number of submitted loads not yet resolved.
+func (l *AsyncLoader) Pending() int { return int(l.pending.Load()) }
+
+// Wait pumps uploads with no budget until f resolves. It is for startup
+// code that cannot proceed without an asset.
+func (l *AsyncLoader) Wait(f *Future) (AssetID, error) {
+	for !f.Ready() {
+		if l.Pump(time.Hour) == 0 {
+			runtime.Gosched()
+		}
+	}
+	return f.ID(), f.Err()
+}
+
+// WaitAll pumps until every submitted load has resolved.
+func (l *AsyncLoader) WaitAll() {
+	for l.Pending() > 0 {
+		if l.Pump(time.Hour) == 0 {
+			runtime.Gosched()
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package assets
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go-engine/Go-Cordance/internal/engine"
+)
+
+// LoadTextureAsync registers path at once with a 1x1 placeholder texture
+// and fills the same GL texture when the image has been decoded, so
+// materials can bind it immediately. cookedPath, if set, is a cooked mip
+// chain to read instead of the source.
+func (l *AsyncLoader) LoadTextureAsync(path, cookedPath string) *Future {
+	path = normalize(path)
+	if a := FindAssetByPath(path); a != nil {
+		if _, ok := a.Data.(TextureData); ok {
+			if f, ok := l.inflight[path]; ok {
+				return f
+			}
+			return Resolved(a.ID, nil)
+		}
+	}
+
+	s := ImportSettingsFor(path)
+	tex := engine.NewPlaceholderTexture()
+	id := Register(AssetTexture, path, TextureData{GLID: tex, SRGB: s.SRGB, NormalMap: s.NormalMap})
+
+	return l.Submit(path, id, func() (Upload, error) {
+		if cookedPath != "" {
+			if t, err := readCookedTexture(cookedPath); err == nil {
+				return func() (AssetID, error) {
+					engine.UploadCookedTexture(tex, t)
+					return id, nil
+				}, nil
+			}
+		}
+		rgba, err := engine.DecodeImageFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return func() (AssetID, error) {
+			engine.UploadTextureRGBA(tex, rgba)
+			return id, nil
+		}, nil
+	})
+}
+
+func readCookedTexture(path string) (engine.CookedTexture, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return engine.CookedTexture{}, err
+	}
+	defer f.Close()
+	return engine.ReadCookedTexture(f)
+}
+
+// LoadMeshAsync decodes a model on a worker and uploads it from Pump. OBJ,
+// STL and PLY files know their base mesh ID up front, so a unit cube stands
+// in under that ID until the real mesh replaces it; if an OBJ splits into
+// several parts the cube is dropped, and the MTL material of every part is
+// kept for OBJMaterials. cookedPath, if set, is a cooked bundle to read
+// instead of the source, and cookedDeps the source's dependencies as recorded
+// when it was cooked.
+func (l *AsyncLoader) LoadMeshAsync(path, cookedPath string, cookedDeps []string, mm *engine.MeshManager) *Future {
+	path = normalize(path)
+	if a := FindAssetByPath(path); a != nil {
+		if f, ok := l.inflight[path]; ok {
+			return f
+		}
+		return Resolved(a.ID, nil)
+	}
+
+	single := singleMeshModel(path)
+	var placeholder AssetID
+	var baseID string
+	if single {
+		baseID = baseMeshID(path)
+		mm.RegisterCube(baseID)
+		placeholder = Register(AssetMesh, path, baseID)
+	}
+
+	return l.Submit(path, placeholder, func() (Upload, error) {
+		dm, err := decodeMeshes(path, cookedPath, baseID)
+		if err != nil {
+			return nil, err
+		}
+		return func() (AssetID, error) {
+			if single {
+				mm.ReleaseMesh(baseID) // placeholder
+			}
+			ids := make([]string, 0, len(dm.meshes))
+			for _, md := range dm.meshes {
+				mm.ReleaseMesh(md.ID) // earlier version, with its LODs
+				mm.RegisterMeshData(md)
+				ids = append(ids, md.ID)
+			}
+			for _, md := range dm.lods {
+				mm.RegisterMeshData(md)
+			}
+			ids = withoutLODs(ids)
+			switch {
+			case dm.cooked:
+				Deps.Set(path, cookedDeps)
+			case dm.obj != nil:
+				recordOBJDeps(path, dm.obj)
+			case !single:
+				recordGLTFDeps(path)
+			}
+			var data any = ids
+			if single {
+				data = singleMeshData(ids)
+			}
+			id := Register(AssetMesh, path, data)
+			if dm.obj != nil {
+				recordOBJMaterials(id, dm.obj)
+			}
+			return id, nil
+		}, nil
+	})
+}
+
+// decodedMeshes is the worker-side result of decodeMeshes.
+type decodedMeshes struct {
+	meshes []engine.MeshData
+	lods   []engine.MeshData // generated by ProcessMeshes; a cooked bundle keeps them in meshes
+	obj    *engine.OBJModel  // set when decoded from an OBJ source
+	cooked bool              // read from the cooked bundle
+}
+
+// decodeMeshes reads a cooked bundle if one is given and readable, else the
+// source with its import settings applied. baseID names the mesh of
+// single-mesh formats.
+func decodeMeshes(path, cookedPath, baseID string) (decodedMeshes, error) {
+	if cookedPath != "" {
+		if f, err := os.Open(cookedPath); err == nil {
+			meshes, err := engine.ReadCookedMeshes(f)
+			f.Close()
+			if err == nil {
+				return decodedMeshes{meshes: meshes, cooked: true}, nil
+			}
+		}
+	}
+
+	var dm decodedMeshes
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".obj":
+		model, err := engine.DecodeOBJModel(baseID, path)
+		if err != nil {
+			return dm, err
+		}
+		dm.meshes, dm.obj = model.Meshes, model
+	case ".stl", ".ply":
+		md, err := decodeSingleMesh(baseID, path)
+		if err != nil {
+			return dm, err
+		}
+		dm.meshes = []engine.MeshData{md}
+	default:
+		src := path
+		if strings.ToLower(filepath.Ext(path)) == ".zip" {
+			var err error
+			if src, err = GLTFInZip(path); err != nil {
+				return dm, err
+			}
+		}
+		var err error
+		if dm.meshes, err = engine.DecodeGLTFMeshes("", src, true); err != nil {
+			return dm, err
+		}
+	}
+	dm.lods = ProcessMeshes(path, dm.meshes)
+	return dm, nil
+}
+
+// LoadMaterialAsync parses a .mat file on a worker.
+func (l *AsyncLoader) LoadMaterialAsync(path string) *Future {
+	return l.Submit(normalize(path), 0, func() (Upload, error) {
+		mf, err := ReadMaterialFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return func() (AssetID, error) { return registerMaterial(path, mf), nil }, nil
+	})
+}
+
+// LoadClipAsync decodes an animation clip on a worker.
+func (l *AsyncLoader) LoadClipAsync(path string) *Future {
+	return l.Submit(normalize(path), 0, func() (Upload, error) {
+		cf, err := ReadClipFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return func() (AssetID, error) { return registerClip(path, cf), nil }, nil
+	})
+}
@@ -0,0 +1,78 @@
+package assets
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAsyncLoader_UploadsOnPumpOnly(t *testing.T) {
+	l := NewAsyncLoader(2, 1)
+	var uploaded atomic.Int32
+	release := make(chan struct{})
+
+	var futs []*Future
+	for i := 0; i < 4; i++ {
+		id := AssetID(i + 1)
+		futs = append(futs, l.Submit(string(rune('a'+i)), 0, func() (Upload, error) {
+			<-release
+			return func() (AssetID, error) {
+				uploaded.Add(1)
+				return id, nil
+			}, nil
+		}))
+	}
+	if again := l.Submit("a", 0, nil); again != futs[0] {
+		t.Fatal("in-flight key was submitted twice")
+	}
+	if l.Pending() != 4 {
+		t.Fatalf("pending = %d", l.Pending())
+	}
+
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for len(l.uploads) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if uploaded.Load() != 0 || futs[0].Ready() {
+		t.Fatal("upload ran off the pumping thread")
+	}
+	// Workers block on the one-slot queue rather than running ahead.
+	if n := len(l.uploads); n != 1 {
+		t.Fatalf("queued uploads = %d, want 1", n)
+	}
+
+	// A zero budget still makes progress, one upload per call.
+	if n := l.Pump(0); n != 1 {
+		t.Fatalf("Pump(0) ran %d uploads", n)
+	}
+	l.WaitAll()
+	for i, f := range futs {
+		if !f.Ready() || f.ID() != AssetID(i+1) || f.Err() != nil {
+			t.Fatalf("future %d = ready %v id %d err %v", i, f.Ready(), f.ID(), f.Err())
+		}
+	}
+	if uploaded.Load() != 4 || l.Pending() != 0 {
+		t.Fatalf("uploaded %d, pending %d", uploaded.Load(), l.Pending())
+	}
+}
+
+func TestAsyncLoader_DecodeErrorKeepsPlaceholderID(t *testing.T) {
+	l := NewAsyncLoader(1, 4)
+	boom := errors.New("corrupt")
+	f := l.Submit("bad.png", 42, func() (Upload, error) { return nil, boom })
+	if f.ID() != 42 {
+		t.Fatalf("placeholder id = %d", f.ID())
+	}
+	id, err := l.Wait(f)
+	if id != 42 || !errors.Is(err, boom) {
+		t.Fatalf("Wait = %d, %v", id, err)
+	}
+	// The key is free again once resolved.
+	if g := l.Submit("bad.png", 0, func() (Upload, error) { return nil, boom }); g == f {
+		t.Fatal("resolved future reused")
+	}
+	l.WaitAll()
+}
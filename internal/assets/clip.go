@@ -0,0 +1,299 @@
+package assets
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Animation clips are stored standalone as JSON (*.clip.json) or in a
+// compact little-endian binary form (*.clip). Node indices address the
+// nodes of the AnimationPlayer that plays the clip; node -1 (ecs.SelfNode)
+// is the player's own entity (doors, platforms, props).
+
+type ClipFile struct {
+	Name     string      `json:"name"`
+	Duration float32     `json:"duration"`
+	Tracks   []ClipTrack `json:"tracks"`
+	Events   []ClipEvent `json:"events,omitempty"`
+}
+
+type ClipTrack struct {
+	Node int `json:"node"`
+	// Channels is the ecs.TrackChannels set the track keys; 0 means the
+	// channels are inferred from the keys, as for files written before it.
+	Channels  uint8          `json:"channels,omitempty"`
+	Keyframes []ClipKeyframe `json:"keyframes"`
+}
+
+type ClipKeyframe struct {
+	Time     float32
+	Position [3]float32
+	Rotation [4]float32
+	Scale    [3]float32
+}
+
+type ClipEvent struct {
+	Time    float32
+	Name    string
+	Payload string
+}
+
+const (
+	ClipExtJSON   = ".clip.json"
+	ClipExtBinary = ".clip"
+
+	clipMagic   = "GCLP"
+	clipVersion = uint32(2) // 2 adds the per-track channel set
+)
+
+// IsClipPath reports whether path has one of the clip asset extensions.
+func IsClipPath(path string) bool {
+	p := strings.ToLower(path)
+	return strings.HasSuffix(p, ClipExtJSON) || strings.HasSuffix(p, ClipExtBinary)
+}
+
+// LoadClip reads a clip file and registers it as an AssetAnimationClip.
+// Data is the ClipFile. Reloading a path updates the existing asset.
+func LoadClip(path string) (AssetID, error) {
+	cf, err := ReadClipFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return registerClip(path, cf), nil
+}
+
+func registerClip(path string, cf ClipFile) AssetID {
+	if a := FindAssetByPath(path); a != nil && a.Type == AssetAnimationClip {
+		a.Data = cf
+		return a.ID
+	}
+	return Register(AssetAnimationClip, path, cf)
+}
+
+// ResolveClip returns the ClipFile behind an AssetAnimationClip.
+func ResolveClip(id AssetID) (ClipFile, bool) {
+	a := Get(id)
+	if a == nil {
+		return ClipFile{}, false
+	}
+	cf, ok := a.Data.(ClipFile)
+	return cf, ok
+}
+
+// ReadClipFile decodes a clip; the format is picked from the extension.
+func ReadClipFile(path string) (ClipFile, error) {
+	var cf ClipFile
+	f, err := os.Open(path)
+	if err != nil {
+		return cf, err
+	}
+	defer f.Close()
+
+	if strings.HasSuffix(strings.ToLower(path), ClipExtBinary) {
+		err = decodeClipBinary(bufio.NewReader(f), &cf)
+	} else {
+		err = json.NewDecoder(f).Decode(&cf)
+	}
+	if err != nil {
+		return cf, fmt.Errorf("clip %s: %w", path, err)
+	}
+	return cf, nil
+}
+
+// WriteClipFile encodes a clip; *.clip is written as binary, anything else as JSON.
+func WriteClipFile(path string, cf ClipFile) error {
+	if !strings.HasSuffix(strings.ToLower(path), ClipExtBinary) {
+		data, err := json.MarshalIndent(cf, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, data, 0644)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	if err := encodeClipBinary(w, cf); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Binary layout (little-endian):
+//
+//	"GCLP" u32 version
+//	str name, f32 duration
+//	u32 trackCount, per track: i32 node, u8 channels (version 2+),
+//	u32 keyCount, keys (11 x f32 each)
+//	u32 eventCount, per event: f32 time, str name, str payload
+//
+// str is a u32 byte length followed by UTF-8 bytes.
+
+func encodeClipBinary(w io.Writer, cf ClipFile) error {
+	le := binary.LittleEndian
+	write := func(v any) error { return binary.Write(w, le, v) }
+	writeStr := func(s string) error {
+		if err := write(uint32(len(s))); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, s)
+		return err
+	}
+
+	if _, err := io.WriteString(w, clipMagic); err != nil {
+		return err
+	}
+	if err := write(clipVersion); err != nil {
+		return err
+	}
+	if err := writeStr(cf.Name); err != nil {
+		return err
+	}
+	if err := write(cf.Duration); err != nil {
+		return err
+	}
+
+	if err := write(uint32(len(cf.Tracks))); err != nil {
+		return err
+	}
+	for _, tr := range cf.Tracks {
+		if err := write(int32(tr.Node)); err != nil {
+			return err
+		}
+		if err := write(tr.Channels); err != nil {
+			return err
+		}
+		if err := write(uint32(len(tr.Keyframes))); err != nil {
+			return err
+		}
+		if err := write(tr.Keyframes); err != nil {
+			return err
+		}
+	}
+
+	if err := write(uint32(len(cf.Events))); err != nil {
+		return err
+	}
+	for _, ev := range cf.Events {
+		if err := write(ev.Time); err != nil {
+			return err
+		}
+		if err := writeStr(ev.Name); err != nil {
+			return err
+		}
+		if err := writeStr(ev.Payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maxClipCount bounds lengths read from a binary clip so a corrupt file
+// fails cleanly instead of allocating gigabytes.
+const maxClipCount = 1 << 24
+
+func decodeClipBinary(r io.Reader, cf *ClipFile) error {
+	le := binary.LittleEndian
+	read := func(v any) error { return binary.Read(r, le, v) }
+	readCount := func() (int, error) {
+		var n uint32
+		if err := read(&n); err != nil {
+			return 0, err
+		}
+		if n > maxClipCount {
+			return 0, fmt.Errorf("count %d out of range", n)
+		}
+		return int(n), nil
+	}
+	readStr := func() (string, error) {
+		n, err := readCount()
+		if err != nil {
+			return "", err
+		}
+		b := make([]byte, n)
+		_, err = io.ReadFull(r, b)
+		return string(b), err
+	}
+
+	magic := make([]byte, len(clipMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return err
+	}
+	if string(magic) != clipMagic {
+		return errors.New("not a binary clip")
+	}
+	var version uint32
+	if err := read(&version); err != nil {
+		return err
+	}
+	if version < 1 || version > clipVersion {
+		return fmt.Errorf("unsupported clip version %d", version)
+	}
+
+	var err error
+	if cf.Name, err = readStr(); err != nil {
+		return err
+	}
+	if err := read(&cf.Duration); err != nil {
+		return err
+	}
+
+	trackCount, err := readCount()
+	if err != nil {
+		return err
+	}
+	cf.Tracks = make([]ClipTrack, trackCount)
+	for i := range cf.Tracks {
+		var node int32
+		if err := read(&node); err != nil {
+			return err
+		}
+		if version >= 2 {
+			if err := read(&cf.Tracks[i].Channels); err != nil {
+				return err
+			}
+		}
+		keyCount, err := readCount()
+		if err != nil {
+			return err
+		}
+		cf.Tracks[i].Node = int(node)
+		cf.Tracks[i].Keyframes = make([]ClipKeyframe, keyCount)
+		if err := read(cf.Tracks[i].Keyframes); err != nil {
+			return err
+		}
+	}
+
+	eventCount, err := readCount()
+	if err != nil {
+		return err
+	}
+	if eventCount > 0 {
+		cf.Events = make([]ClipEvent, eventCount)
+	}
+	for i := range cf.Events {
+		if err := read(&cf.Events[i].Time); err != nil {
+			return err
+		}
+		if cf.Events[i].Name, err = readStr(); err != nil {
+			return err
+		}
+		if cf.Events[i].Payload, err = readStr(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
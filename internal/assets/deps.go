@@ -0,0 +1,177 @@
+package assets
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DepGraph records which files each asset was built from: a material
+// depends on its textures and shader, a shader on its GLSL sources, a model
+// on its buffers and images, a prefab on its models and materials. Keys are
+// slash-separated paths as used by the registry. Files that are not assets
+// themselves (GLSL, .bin buffers) appear only as dependencies.
+type DepGraph struct {
+	mu   sync.Mutex
+	deps map[string][]string        // asset -> files it reads
+	rdep map[string]map[string]bool // file -> assets that read it
+}
+
+// Deps is the process-wide graph filled in by the importers.
+var Deps = NewDepGraph()
+
+func NewDepGraph() *DepGraph {
+	return &DepGraph{
+		deps: map[string][]string{},
+		rdep: map[string]map[string]bool{},
+	}
+}
+
+// Set replaces the dependencies of asset.
+func (g *DepGraph) Set(asset string, deps []string) {
+	asset = normalize(asset)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.removeLocked(asset)
+	seen := map[string]bool{}
+	list := make([]string, 0, len(deps))
+	for _, d := range deps {
+		d = normalize(d)
+		if d == "" || d == asset || seen[d] {
+			continue
+		}
+		seen[d] = true
+		list = append(list, d)
+		if g.rdep[d] == nil {
+			g.rdep[d] = map[string]bool{}
+		}
+		g.rdep[d][asset] = true
+	}
+	if len(list) > 0 {
+		g.deps[asset] = list
+	}
+}
+
+// Remove forgets asset's own dependencies. Edges pointing at it are kept so
+// its dependents reload if it is recreated.
+func (g *DepGraph) Remove(asset string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.removeLocked(normalize(asset))
+}
+
+func (g *DepGraph) removeLocked(asset string) {
+	for _, d := range g.deps[asset] {
+		delete(g.rdep[d], asset)
+		if len(g.rdep[d]) == 0 {
+			delete(g.rdep, d)
+		}
+	}
+	delete(g.deps, asset)
+}
+
+// Dependencies returns the files asset was built from.
+func (g *DepGraph) Dependencies(asset string) []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.deps[normalize(asset)]...)
+}
+
+// Dependents returns the assets that read file directly, sorted.
+func (g *DepGraph) Dependents(file string) []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.dependentsLocked(normalize(file))
+}
+
+func (g *DepGraph) dependentsLocked(file string) []string {
+	out := make([]string, 0, len(g.rdep[file]))
+	for a := range g.rdep[file] {
+		out = append(out, a)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// ReloadOrder expands the changed files to everything that transitively
+// depends on them and orders the result so each entry comes after the files
+// it depends on. A .meta sidecar stands for the asset it describes. Members
+// of a dependency cycle are appended last, in path order.
+func (g *DepGraph) ReloadOrder(changed []string) []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	affected := map[string]bool{}
+	var queue []string
+	for _, c := range changed {
+		c = normalize(c)
+		if IsMetaPath(c) {
+			c = strings.TrimSuffix(c, MetaExt)
+		}
+		if !affected[c] {
+			affected[c] = true
+			queue = append(queue, c)
+		}
+	}
+	for len(queue) > 0 {
+		f := queue[0]
+		queue = queue[1:]
+		for _, a := range g.dependentsLocked(f) {
+			if !affected[a] {
+				affected[a] = true
+				queue = append(queue, a)
+			}
+		}
+	}
+
+	// Kahn's algorithm over the affected subgraph.
+	pending := map[string]int{}
+	for a := range affected {
+		for _, d := range g.deps[a] {
+			if affected[d] {
+				pending[a]++
+			}
+		}
+	}
+	var ready []string
+	for a := range affected {
+		if pending[a] == 0 {
+			ready = append(ready, a)
+		}
+	}
+	sort.Strings(ready)
+
+	order := make([]string, 0, len(affected))
+	for len(ready) > 0 {
+		f := ready[0]
+		ready = ready[1:]
+		order = append(order, f)
+		delete(affected, f)
+
+		var next []string
+		for _, a := range g.dependentsLocked(f) {
+			if !affected[a] {
+				continue
+			}
+			if pending[a]--; pending[a] == 0 {
+				next = append(next, a)
+			}
+		}
+		ready = append(ready, next...)
+		sort.Strings(ready)
+	}
+
+	cyclic := make([]string, 0, len(affected))
+	for a := range affected {
+		cyclic = append(cyclic, a)
+	}
+	sort.Strings(cyclic)
+	return append(order, cyclic...)
+}
+
+// relativeTo resolves a URI found inside the file at path.
+func relativeTo(path, uri string) string {
+	return filepath.ToSlash(filepath.Join(filepath.Dir(path), filepath.FromSlash(uri)))
+}
@@ -0,0 +1,64 @@
+package assets
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDepGraph_ReloadOrderFollowsDependencies(t *testing.T) {
+	g := NewDepGraph()
+	g.Set("assets/shaders/pbr.json", []string{"assets/shaders/pbr_vert.glsl", "assets/shaders/pbr_frag.glsl"})
+	g.Set("assets/materials/crate.mat", []string{"assets/textures/crate.png", "assets/shaders/pbr.json"})
+	g.Set("assets/models/crate.gltf", []string{"assets/models/crate.bin", "assets/textures/crate.png"})
+	g.Set("assets/prefabs/crate.prefab", []string{"assets/models/crate.gltf", "assets/materials/crate.mat"})
+
+	got := g.ReloadOrder([]string{"assets/textures/crate.png"})
+	want := []string{
+		"assets/textures/crate.png",
+		"assets/materials/crate.mat",
+		"assets/models/crate.gltf",
+		"assets/prefabs/crate.prefab",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("texture change order = %v\nwant %v", got, want)
+	}
+
+	// A GLSL edit reaches the prefab through shader and material; the model
+	// is untouched.
+	got = g.ReloadOrder([]string{"assets/shaders/pbr_frag.glsl"})
+	want = []string{
+		"assets/shaders/pbr_frag.glsl",
+		"assets/shaders/pbr.json",
+		"assets/materials/crate.mat",
+		"assets/prefabs/crate.prefab",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("shader change order = %v\nwant %v", got, want)
+	}
+
+	// A sidecar edit stands for its asset.
+	got = g.ReloadOrder([]string{"assets/models/crate.gltf" + MetaExt})
+	want = []string{"assets/models/crate.gltf", "assets/prefabs/crate.prefab"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("meta change order = %v\nwant %v", got, want)
+	}
+}
+
+func TestDepGraph_SetReplacesEdgesAndCyclesTerminate(t *testing.T) {
+	g := NewDepGraph()
+	g.Set("a.mat", []string{"old.png"})
+	g.Set("a.mat", []string{"new.png"})
+	if d := g.Dependents("old.png"); len(d) != 0 {
+		t.Fatalf("stale edge: %v", d)
+	}
+	if d := g.Dependents("new.png"); !reflect.DeepEqual(d, []string{"a.mat"}) {
+		t.Fatalf("dependents = %v", d)
+	}
+
+	g.Set("x", []string{"y"})
+	g.Set("y", []string{"x"})
+	got := g.ReloadOrder([]string{"x"})
+	if !reflect.DeepEqual(got, []string{"x", "y"}) {
+		t.Fatalf("cycle order = %v", got)
+	}
+}
@@ -0,0 +1,52 @@
+package assets
+
+import "go-engine/Go-Cordance/internal/engine"
+
+// ImportEnvironment bakes the image-based lighting for the .hdr
+// environment at path and registers it, or returns the one already loaded.
+func ImportEnvironment(path string) (AssetID, *engine.Environment, error) {
+	path = normalize(path)
+	if a := FindAssetByPath(path); a != nil {
+		if env, ok := a.Data.(*engine.Environment); ok {
+			return a.ID, env, nil
+		}
+	}
+	env, err := engine.LoadEnvironment(path)
+	if err != nil {
+		return 0, nil, err
+	}
+	return Register(AssetEnvironment, path, env), env, nil
+}
+
+// LoadEnvironmentAsync decodes the .hdr environment at path and bakes its
+// image-based lighting on a worker, which takes far longer than a frame,
+// then uploads the textures and registers the asset from Pump.
+func (l *AsyncLoader) LoadEnvironmentAsync(path string) *Future {
+	path = normalize(path)
+	if a := FindAssetByPath(path); a != nil {
+		if _, ok := a.Data.(*engine.Environment); ok {
+			return Resolved(a.ID, nil)
+		}
+	}
+	settings := engine.DefaultEnvironmentSettings
+	return l.Submit(path, 0, func() (Upload, error) {
+		img, err := engine.LoadHDR(path)
+		if err != nil {
+			return nil, err
+		}
+		maps := engine.BakeEnvironment(img, settings)
+		return func() (AssetID, error) {
+			return Register(AssetEnvironment, path, maps.Upload()), nil
+		}, nil
+	})
+}
+
+// EnvironmentData returns the environment registered as id, or nil.
+func EnvironmentData(id AssetID) *engine.Environment {
+	if a := Get(id); a != nil {
+		if env, ok := a.Data.(*engine.Environment); ok {
+			return env
+		}
+	}
+	return nil
+}
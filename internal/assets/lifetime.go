@@ -0,0 +1,276 @@
+package assets
+
+import (
+	"encoding/json"
+	"go-engine/Go-Cordance/internal/engine"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Backend frees and measures the GPU resources behind assets. The engine
+// provides the GL implementation (engine.GLAssetBackend); tests use a fake.
+type Backend interface {
+	DeleteTexture(tex uint32)
+	DeleteMesh(meshID string)
+	TextureBytes(tex uint32) int64
+	MeshBytes(meshID string) (cpu, gpu int64)
+}
+
+// DefaultGrace is how long an asset stays loaded after its last reference
+// goes away, so a scene reload or undo does not thrash uploads.
+const DefaultGrace = 10 * time.Second
+
+// Lifetimes counts references to assets and unloads the ones nobody has
+// referenced for the grace period. References come from Handles held by
+// code and from owner sets (see Retain) that mirror what a scene's
+// components point at. Assets that were never referenced, such as the
+// library loaded at startup for the editor's browser, stay resident.
+//
+// Shader programs are shared by name and compiled once at startup, so
+// shader assets are counted and reported but never collected.
+type Lifetimes struct {
+	Grace time.Duration
+	Now   func() time.Time
+
+	mu        sync.Mutex
+	backend   Backend
+	handles   map[AssetID]int
+	owners    map[string]map[AssetID]bool
+	zeroSince map[AssetID]time.Time
+}
+
+// Lifetime is the process-wide tracker. Its backend is set by the game once
+// GL is up; without one, unloading only drops registry entries.
+var Lifetime = NewLifetimes(nil, DefaultGrace)
+
+func NewLifetimes(b Backend, grace time.Duration) *Lifetimes {
+	return &Lifetimes{
+		Grace:     grace,
+		Now:       time.Now,
+		backend:   b,
+		handles:   map[AssetID]int{},
+		owners:    map[string]map[AssetID]bool{},
+		zeroSince: map[AssetID]time.Time{},
+	}
+}
+
+func (l *Lifetimes) SetBackend(b Backend) {
+	l.mu.Lock()
+	l.backend = b
+	l.mu.Unlock()
+}
+
+// Handle is a counted reference to an asset. The zero Handle is empty.
+type Handle struct {
+	id AssetID
+	l  *Lifetimes
+}
+
+func (h Handle) ID() AssetID { return h.id }
+
+// Release drops the reference; releasing an empty handle is a no-op.
+func (h *Handle) Release() {
+	if h.l == nil || h.id == 0 {
+		return
+	}
+	l := h.l
+	l.mu.Lock()
+	if l.handles[h.id]--; l.handles[h.id] <= 0 {
+		delete(l.handles, h.id)
+	}
+	l.markLocked(h.id)
+	l.mu.Unlock()
+	*h = Handle{}
+}
+
+// Acquire returns a handle that keeps id loaded until released.
+func (l *Lifetimes) Acquire(id AssetID) Handle {
+	if id == 0 {
+		return Handle{}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.handles[id]++
+	delete(l.zeroSince, id)
+	return Handle{id: id, l: l}
+}
+
+// Retain replaces the set of assets owner references. Assets that leave
+// the set and have no other reference start their grace period.
+func (l *Lifetimes) Retain(owner string, ids []AssetID) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	old := l.owners[owner]
+	next := make(map[AssetID]bool, len(ids))
+	for _, id := range ids {
+		if id != 0 {
+			next[id] = true
+			delete(l.zeroSince, id)
+		}
+	}
+	if len(next) > 0 {
+		l.owners[owner] = next
+	} else {
+		delete(l.owners, owner)
+	}
+	for id := range old {
+		if !next[id] {
+			l.markLocked(id)
+		}
+	}
+}
+
+// RefCount returns the number of handles plus owners referencing id.
+func (l *Lifetimes) RefCount(id AssetID) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.countLocked(id)
+}
+
+func (l *Lifetimes) countLocked(id AssetID) int {
+	n := l.handles[id]
+	for _, set := range l.owners {
+		if set[id] {
+			n++
+		}
+	}
+	return n
+}
+
+func (l *Lifetimes) markLocked(id AssetID) {
+	if l.countLocked(id) == 0 {
+		l.zeroSince[id] = l.Now()
+	}
+}
+
+// Collect unloads every asset whose references have been gone for at least
+// Grace: its GPU resources are freed and it is unregistered. It returns the
+// unloaded IDs in ascending order. Call it on the GL thread.
+func (l *Lifetimes) Collect() []AssetID {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.Now()
+	var out []AssetID
+	for id, since := range l.zeroSince {
+		if now.Sub(since) < l.Grace {
+			continue
+		}
+		a := Get(id)
+		if a != nil && a.Type == AssetShader {
+			continue
+		}
+		delete(l.zeroSince, id)
+		if a == nil {
+			continue
+		}
+		l.unloadLocked(a)
+		Unregister(id)
+		out = append(out, id)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+func (l *Lifetimes) unloadLocked(a *Asset) {
+	if l.backend == nil {
+		return
+	}
+	switch d := a.Data.(type) {
+	case TextureData:
+		l.backend.DeleteTexture(d.GLID)
+	case *engine.Environment:
+		for _, tex := range d.Textures() {
+			l.backend.DeleteTexture(tex)
+		}
+	}
+	if a.Type == AssetMesh {
+		for _, id := range meshIDs(a) {
+			l.backend.DeleteMesh(id)
+		}
+	}
+}
+
+// meshIDs returns the MeshManager IDs a mesh asset registered.
+func meshIDs(a *Asset) []string {
+	switch d := a.Data.(type) {
+	case string:
+		return []string{d}
+	case []string:
+		return d
+	}
+	return nil
+}
+
+// AssetMemory is one row of a memory report.
+type AssetMemory struct {
+	ID       AssetID
+	Path     string
+	Type     AssetType
+	Refs     int
+	CPUBytes int64
+	GPUBytes int64
+}
+
+// Report lists every registered asset with its reference count and memory
+// use, largest first.
+func (l *Lifetimes) Report() []AssetMemory {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	out := make([]AssetMemory, 0, len(registry))
+	for _, a := range registry {
+		m := AssetMemory{ID: a.ID, Path: a.Path, Type: a.Type, Refs: l.countLocked(a.ID)}
+		m.CPUBytes, m.GPUBytes = l.bytesLocked(a)
+		out = append(out, m)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		ti, tj := out[i].CPUBytes+out[i].GPUBytes, out[j].CPUBytes+out[j].GPUBytes
+		if ti != tj {
+			return ti > tj
+		}
+		return out[i].Path < out[j].Path
+	})
+	return out
+}
+
+func (l *Lifetimes) bytesLocked(a *Asset) (cpu, gpu int64) {
+	switch d := a.Data.(type) {
+	case TextureData:
+		if l.backend != nil {
+			gpu = l.backend.TextureBytes(d.GLID)
+		}
+		return 0, gpu
+	case *engine.Environment:
+		if l.backend != nil {
+			for _, tex := range d.Textures() {
+				gpu += l.backend.TextureBytes(tex)
+			}
+		}
+		return 0, gpu
+	case ClipFile:
+		for _, tr := range d.Tracks {
+			cpu += int64(len(tr.Keyframes)) * 44
+		}
+		for _, ev := range d.Events {
+			cpu += 4 + int64(len(ev.Name)+len(ev.Payload))
+		}
+		return cpu, 0
+	}
+	if a.Type == AssetMesh {
+		if l.backend != nil {
+			for _, id := range meshIDs(a) {
+				c, g := l.backend.MeshBytes(id)
+				cpu, gpu = cpu+c, gpu+g
+			}
+		}
+		return cpu, gpu
+	}
+	// Materials and shaders are small JSON-shaped records.
+	if b, err := json.Marshal(a.Data); err == nil {
+		cpu = int64(len(b))
+	}
+	return cpu, 0
+}
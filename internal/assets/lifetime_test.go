@@ -0,0 +1,90 @@
+package assets
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fakeGPU struct {
+	textures map[uint32]int64
+	meshes   map[string]int64
+}
+
+func newFakeGPU() *fakeGPU {
+	return &fakeGPU{textures: map[uint32]int64{}, meshes: map[string]int64{}}
+}
+
+func (f *fakeGPU) DeleteTexture(tex uint32)      { delete(f.textures, tex) }
+func (f *fakeGPU) DeleteMesh(id string)          { delete(f.meshes, id) }
+func (f *fakeGPU) TextureBytes(tex uint32) int64 { return f.textures[tex] }
+func (f *fakeGPU) MeshBytes(id string) (int64, int64) {
+	return f.meshes[id] / 2, f.meshes[id]
+}
+
+func TestLifetimes_UnloadsAfterGracePeriod(t *testing.T) {
+	t.Cleanup(restart)
+	restart()
+
+	gpu := newFakeGPU()
+	gpu.textures[7] = 4096
+	gpu.meshes["crate/0"] = 1000
+	gpu.meshes["crate/1"] = 500
+
+	clock := time.Unix(0, 0)
+	l := NewLifetimes(gpu, 10*time.Second)
+	l.Now = func() time.Time { return clock }
+
+	tex := register(AssetTexture, "virtual/crate.png", "g-tex", TextureData{GLID: 7})
+	mesh := register(AssetMesh, "virtual/crate.gltf", "g-mesh", []string{"crate/0", "crate/1"})
+	lib := register(AssetTexture, "virtual/library.png", "g-lib", TextureData{GLID: 9})
+
+	l.Retain("scene", []AssetID{tex, mesh})
+	h := l.Acquire(tex)
+	if n := l.RefCount(tex); n != 2 {
+		t.Fatalf("refcount = %d, want 2", n)
+	}
+
+	report := l.Report()
+	if report[0].ID != tex || report[0].GPUBytes != 4096 || report[1].ID != mesh || report[1].CPUBytes != 750 || report[1].GPUBytes != 1500 {
+		t.Fatalf("report = %+v", report)
+	}
+
+	// Loading another scene drops the mesh; the handle keeps the texture.
+	l.Retain("scene", nil)
+	clock = clock.Add(5 * time.Second)
+	if got := l.Collect(); len(got) != 0 {
+		t.Fatalf("collected %v inside the grace period", got)
+	}
+
+	// Re-referencing within the grace period cancels the unload.
+	l.Retain("scene", []AssetID{mesh})
+	clock = clock.Add(time.Minute)
+	if got := l.Collect(); len(got) != 0 {
+		t.Fatalf("collected %v while referenced", got)
+	}
+
+	l.Retain("scene", nil)
+	h.Release()
+	h.Release() // double release is a no-op
+	clock = clock.Add(10 * time.Second)
+	if got, want := l.Collect(), []AssetID{tex, mesh}; !reflect.DeepEqual(got, sortedIDs(want)) {
+		t.Fatalf("collected %v, want %v", got, want)
+	}
+	if len(gpu.textures) != 0 || len(gpu.meshes) != 0 {
+		t.Fatalf("GPU resources left: %+v %+v", gpu.textures, gpu.meshes)
+	}
+	if Get(tex) != nil || Get(mesh) != nil || FindAssetByPath("virtual/crate.gltf") != nil {
+		t.Fatal("collected assets still registered")
+	}
+	if Get(lib) == nil {
+		t.Fatal("never-referenced asset was collected")
+	}
+}
+
+func sortedIDs(ids []AssetID) []AssetID {
+	if ids[0] > ids[1] {
+		ids[0], ids[1] = ids[1], ids[0]
+	}
+	return ids
+}
@@ -3,6 +3,7 @@ package assets
 import (
 	"encoding/json"
 	"os"
+	"sort"
 )
 
 // Material assets are metadata-only for now.
@@ -26,17 +27,42 @@ type MaterialFile struct {
 }
 
 func LoadMaterial(path string) (AssetID, error) {
-	data, err := os.ReadFile(path)
+	mf, err := ReadMaterialFile(path)
 	if err != nil {
 		return 0, err
 	}
+	return registerMaterial(path, mf), nil
+}
 
+// ReadMaterialFile parses a .mat file without registering it.
+func ReadMaterialFile(path string) (MaterialFile, error) {
 	var mf MaterialFile
-	if err := json.Unmarshal(data, &mf); err != nil {
-		return 0, err
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return mf, err
 	}
+	err = json.Unmarshal(data, &mf)
+	return mf, err
+}
 
+func registerMaterial(path string, mf MaterialFile) AssetID {
 	// Store the raw struct as Data
 	id := Register(AssetMaterial, path, mf)
-	return id, nil
+	Deps.Set(path, mf.dependencies())
+	return id
+}
+
+// dependencies lists the texture files and shader asset the material uses.
+func (mf MaterialFile) dependencies() []string {
+	var deps []string
+	for _, t := range mf.Textures {
+		if t != "" {
+			deps = append(deps, t)
+		}
+	}
+	if sh := FindShaderAsset(mf.Shader); sh != nil {
+		deps = append(deps, sh.Path)
+	}
+	sort.Strings(deps)
+	return deps
 }
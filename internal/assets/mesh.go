@@ -2,39 +2,256 @@ package assets
 
 import (
 	"go-engine/Go-Cordance/internal/engine"
+	"go-engine/Go-Cordance/internal/meshproc"
 	"path/filepath"
 	"strings"
 )
 
+// lodMaxError is the simplification error, relative to the mesh's size,
+// a generated LOD level may not exceed.
+const lodMaxError = 0.01
+
+// ProcessMeshes applies path's mesh import settings in place: scale,
+// MikkTSpace tangents for meshes that have none, and vertex cache, overdraw
+// and fetch optimisation. It returns the LOD levels the settings ask for,
+// named with engine.LODMeshID; they are registered alongside the meshes but
+// are not parts of the asset. The importers and the cooker both go through
+// it, so cooked and uncooked meshes come out identical.
+func ProcessMeshes(path string, meshes []engine.MeshData) []engine.MeshData {
+	s := ImportSettingsFor(path)
+	var lods []engine.MeshData
+	for i := range meshes {
+		md := &meshes[i]
+		md.ApplyImportSettings(s.Scale, false)
+		if s.GenerateTangents && !md.HasTangents {
+			meshproc.GenerateTangents(md)
+		}
+		if s.Optimize {
+			meshproc.Optimize(md)
+		}
+		if len(s.LODs) > 0 {
+			lods = append(lods, meshproc.BuildLODChain(*md, s.LODs, lodMaxError)...)
+		}
+	}
+	return lods
+}
+
+// registerMeshes applies the path's import settings and uploads meshes and
+// their LODs. It returns the IDs of meshes only.
+func registerMeshes(path string, meshes []engine.MeshData, mm *engine.MeshManager) []string {
+	lods := ProcessMeshes(path, meshes)
+	ids := make([]string, 0, len(meshes))
+	for _, md := range meshes {
+		mm.RegisterMeshData(md)
+		ids = append(ids, md.ID)
+	}
+	for _, md := range lods {
+		mm.RegisterMeshData(md)
+	}
+	return ids
+}
+
+// withoutLODs drops LOD mesh IDs, which cooked bundles list along with the
+// meshes they simplify.
+func withoutLODs(ids []string) []string {
+	out := ids[:0:0]
+	for _, id := range ids {
+		if _, _, ok := engine.ParseLODMeshID(id); !ok {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// recordGLTFDeps notes the external buffers and images a glTF file reads so
+// editing any of them reloads the model.
+func recordGLTFDeps(path string) {
+	deps, err := GLTFDependencies(path)
+	if err != nil {
+		return
+	}
+	Deps.Set(path, deps)
+}
+
+// GLTFDependencies lists the external buffers and images a glTF file reads.
+// Binary .glb and zipped models have none.
+func GLTFDependencies(path string) ([]string, error) {
+	if strings.ToLower(filepath.Ext(path)) != ".gltf" {
+		return nil, nil
+	}
+	g, err := engine.LoadGLTFRoot(path)
+	if err != nil {
+		return nil, err
+	}
+	var deps []string
+	for _, b := range g.Buffers {
+		if b.URI != "" && !strings.HasPrefix(b.URI, "data:") {
+			deps = append(deps, relativeTo(path, b.URI))
+		}
+	}
+	for _, img := range g.Images {
+		if img.URI != "" && !strings.HasPrefix(img.URI, "data:") {
+			deps = append(deps, relativeTo(path, img.URI))
+		}
+	}
+	return deps, nil
+}
+
 // ImportGLTFMesh loads a single-mesh GLTF and registers it as an asset.
 // Data = meshID string used by MeshManager.
 func ImportGLTFMesh(meshID, path string, mm *engine.MeshManager) (AssetID, error) {
-	if _, err := mm.RegisterGLTF(meshID, path); err != nil {
+	meshes, err := engine.DecodeGLTFMeshes(meshID, path, false)
+	if err != nil {
 		return 0, err
 	}
+	registerMeshes(path, meshes, mm)
+	recordGLTFDeps(path)
 	return Register(AssetMesh, path, meshID), nil
 }
 
 // ImportGLTFMulti loads a multi-mesh GLTF and registers the root asset.
 // Later you can extend this to register each primitive separately.
 func ImportGLTFMulti(path string, mm *engine.MeshManager) (AssetID, []string, error) {
-	meshIDs, err := mm.RegisterGLTFMulti(path)
+	meshes, err := engine.DecodeGLTFMeshes("", path, true)
 	if err != nil {
 
 		return 0, nil, err
 	}
+	meshIDs := registerMeshes(path, meshes, mm)
+	recordGLTFDeps(path)
 	id := Register(AssetMesh, path, meshIDs)
 	return id, meshIDs, nil
 }
 
-func ImportOBJ(path string, mm *engine.MeshManager) (AssetID, string, error) {
-	base := filepath.Base(path)
-	meshID := strings.TrimSuffix(base, filepath.Ext(base))
+// ImportOBJ loads an OBJ and registers it as an asset. Data is the mesh ID
+// string when the file has a single part, []string when it splits into
+// several objects, groups or materials.
+func ImportOBJ(path string, mm *engine.MeshManager) (AssetID, []string, error) {
+	id, model, err := ImportOBJModel(path, mm)
+	if err != nil {
+		return 0, nil, err
+	}
+	return id, objMeshIDs(model), nil
+}
 
-	if err := mm.RegisterOBJ(meshID, path); err != nil {
-		return 0, "", err
+// ImportOBJModel is ImportOBJ returning the decoded model, whose materials
+// the caller can apply to the spawned entities.
+func ImportOBJModel(path string, mm *engine.MeshManager) (AssetID, *engine.OBJModel, error) {
+	model, err := engine.DecodeOBJModel(baseMeshID(path), path)
+	if err != nil {
+		return 0, nil, err
 	}
+	meshIDs := registerMeshes(path, model.Meshes, mm)
+	recordOBJDeps(path, model)
+	id := Register(AssetMesh, path, singleMeshData(meshIDs))
+	recordOBJMaterials(id, model)
+	return id, model, nil
+}
+
+func objMeshIDs(model *engine.OBJModel) []string {
+	ids := make([]string, len(model.Meshes))
+	for i, md := range model.Meshes {
+		ids[i] = md.ID
+	}
+	return ids
+}
+
+// objMaterials holds, per OBJ mesh asset, the MTL material each of its
+// parts used, keyed by mesh ID.
+var objMaterials = map[AssetID]map[string]engine.OBJMaterial{}
+
+// recordOBJMaterials keeps the materials of model's parts on asset id,
+// replacing those of an earlier load.
+func recordOBJMaterials(id AssetID, model *engine.OBJModel) {
+	mats := map[string]engine.OBJMaterial{}
+	for _, md := range model.Meshes {
+		if m, ok := model.Materials[model.MeshMaterials[md.ID]]; ok {
+			mats[md.ID] = m
+		}
+	}
+	objMaterials[id] = mats
+}
+
+// OBJMaterials returns the MTL material of every part of OBJ mesh asset
+// id, keyed by mesh ID. Parts without a usemtl, and models read from a
+// cooked bundle, have none.
+func OBJMaterials(id AssetID) map[string]engine.OBJMaterial {
+	return objMaterials[id]
+}
+
+// MeshIDs returns the MeshManager IDs of mesh asset id, or nil.
+func MeshIDs(id AssetID) []string {
+	if a := Get(id); a != nil && a.Type == AssetMesh {
+		return meshIDs(a)
+	}
+	return nil
+}
+
+// singleMeshData gives a model that decoded to one mesh the single-string
+// Data of the single-mesh importers.
+func singleMeshData(meshIDs []string) any {
+	if len(meshIDs) == 1 {
+		return meshIDs[0]
+	}
+	return meshIDs
+}
+
+// recordOBJDeps notes the material libraries and the textures they use.
+func recordOBJDeps(path string, model *engine.OBJModel) {
+	Deps.Set(path, OBJDependencies(model))
+}
+
+// OBJDependencies lists an OBJ model's material libraries and the textures
+// they use.
+func OBJDependencies(model *engine.OBJModel) []string {
+	deps := append([]string(nil), model.MaterialLibs...)
+	for _, m := range model.Materials {
+		for _, tex := range []string{m.DiffuseMap, m.NormalMap} {
+			if tex != "" {
+				deps = append(deps, tex)
+			}
+		}
+	}
+	return deps
+}
 
-	id := Register(AssetMesh, path, meshID)
-	return id, meshID, nil
+// ImportCookedMesh registers a mesh asset from its cooked bundle. The asset
+// keeps the source path, and its Data has the same shape the source importers
+// produce: []string for glTF, a single mesh ID for STL, PLY and one-part
+// OBJ files. Import settings were applied when cooking; deps are the
+// source's dependencies as the cook manifest recorded them.
+func ImportCookedMesh(srcPath, cookedPath string, deps []string, mm *engine.MeshManager) (AssetID, []string, error) {
+	meshIDs, err := mm.RegisterCookedMeshes(cookedPath)
+	if err != nil {
+		return 0, nil, err
+	}
+	meshIDs = withoutLODs(meshIDs)
+	var data any = meshIDs
+	if singleMeshModel(srcPath) {
+		data = singleMeshData(meshIDs)
+	}
+	Deps.Set(srcPath, deps)
+	return Register(AssetMesh, srcPath, data), meshIDs, nil
+}
+
+// FindMeshAsset returns the mesh asset that registered meshID.
+func FindMeshAsset(meshID string) *Asset {
+	for _, a := range registry {
+		if a.Type != AssetMesh {
+			continue
+		}
+		switch d := a.Data.(type) {
+		case string:
+			if d == meshID {
+				return a
+			}
+		case []string:
+			for _, id := range d {
+				if id == meshID {
+					return a
+				}
+			}
+		}
+	}
+	return nil
 }
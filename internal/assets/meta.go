@@ -0,0 +1,169 @@
+package assets
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Every asset file on disk gets a "<file>.meta" sidecar holding its GUID and
+// import settings. The GUID is what makes an AssetID stable: IDs are derived
+// from it, so references saved in scenes survive restarts, renames and moves
+// as long as the sidecar travels with the file (see Move).
+
+// GUID is a 128-bit asset identifier in lowercase hex.
+type GUID string
+
+const MetaExt = ".meta"
+
+// ImportSettings are per-asset knobs the importers honour. Texture fields are
+// ignored for meshes and vice versa.
+type ImportSettings struct {
+	SRGB      bool `json:"srgb"`      // sample as sRGB (albedo); false for data textures
+	NormalMap bool `json:"normalMap"` // tangent-space normal map
+
+	Scale            float32 `json:"scale"`            // uniform scale baked into static mesh positions
+	GenerateTangents bool    `json:"generateTangents"` // compute tangents when the source has none
+	Optimize         bool    `json:"optimize"`         // weld and reorder for vertex cache, overdraw and fetch
+
+	// LODs are the fractions of each mesh's triangles its generated LOD
+	// levels keep, finest first (e.g. [0.5, 0.25]); none by default.
+	LODs []float32 `json:"lods,omitempty"`
+}
+
+// Meta is the content of a .meta sidecar.
+type Meta struct {
+	GUID GUID `json:"guid"`
+	// File is the name of the asset the sidecar was written for, so a
+	// sidecar copied along with its asset can be told from the original.
+	File   string         `json:"file,omitempty"`
+	Import ImportSettings `json:"import"`
+}
+
+// MetaPath returns the sidecar path for an asset file.
+func MetaPath(path string) string { return path + MetaExt }
+
+// IsMetaPath reports whether path is a sidecar rather than an asset.
+func IsMetaPath(path string) bool { return strings.HasSuffix(strings.ToLower(path), MetaExt) }
+
+// NewGUID returns a random GUID.
+func NewGUID() GUID {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return GUID(hex.EncodeToString(b[:]))
+}
+
+// pathGUID is the GUID of an asset that has no file behind it (generated or
+// in-memory paths). It is derived from the path so it is still stable.
+func pathGUID(path string) GUID {
+	sum := sha1.Sum([]byte(normalize(path)))
+	return GUID(hex.EncodeToString(sum[:16]))
+}
+
+// DefaultImportSettings guesses settings from the file name: textures named
+// like normal/roughness/metallic/occlusion maps are linear, everything else
+// is sRGB; meshes import at scale 1 with tangent generation on.
+func DefaultImportSettings(path string) ImportSettings {
+	name := strings.ToLower(filepath.Base(path))
+	s := ImportSettings{SRGB: true, Scale: 1, GenerateTangents: true}
+	for _, hint := range []string{"normal", "_nrm", "_n."} {
+		if strings.Contains(name, hint) {
+			s.NormalMap = true
+			s.SRGB = false
+		}
+	}
+	for _, hint := range []string{"rough", "metal", "occlusion", "_ao", "_orm", "height", "mask"} {
+		if strings.Contains(name, hint) {
+			s.SRGB = false
+		}
+	}
+	return s
+}
+
+// ReadMeta reads the sidecar of path.
+func ReadMeta(path string) (Meta, error) {
+	var m Meta
+	data, err := os.ReadFile(MetaPath(path))
+	if err != nil {
+		return m, err
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, err
+	}
+	if m.Import.Scale == 0 {
+		m.Import.Scale = 1
+	}
+	return m, nil
+}
+
+// WriteMeta writes the sidecar of path.
+func WriteMeta(path string, m Meta) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(MetaPath(path), append(data, '\n'), 0644)
+}
+
+// MetaFor returns the meta of path, creating the sidecar with a fresh GUID
+// and default settings if the file exists but has none yet. Paths without a
+// file, and files whose sidecar cannot be written (read-only trees), get a
+// path-derived GUID.
+func MetaFor(path string) Meta {
+	if m, err := ReadMeta(path); err == nil && m.GUID != "" {
+		return ownMeta(path, m)
+	}
+	m := Meta{Import: DefaultImportSettings(path)}
+	if _, err := os.Stat(path); err != nil {
+		m.GUID = pathGUID(path)
+		return m
+	}
+	m.GUID, m.File = NewGUID(), filepath.Base(path)
+	if err := WriteMeta(path, m); err != nil {
+		log.Printf("assets: could not write %s: %v", MetaPath(path), err)
+		m.GUID = pathGUID(path)
+	}
+	return m
+}
+
+// ownMeta points a sidecar that names another file at path. If that file
+// still sits next to path under the same GUID, path is a copy and gets a
+// GUID of its own; otherwise the asset was renamed and keeps it.
+func ownMeta(path string, m Meta) Meta {
+	base := filepath.Base(path)
+	if m.File == base {
+		return m
+	}
+	copied := false
+	if m.File != "" {
+		orig := filepath.Join(filepath.Dir(path), m.File)
+		if om, err := ReadMeta(orig); err == nil && om.GUID == m.GUID {
+			if _, err := os.Stat(orig); err == nil {
+				m.GUID, copied = NewGUID(), true
+			}
+		}
+	}
+	m.File = base
+	if err := WriteMeta(path, m); err != nil {
+		log.Printf("assets: could not write %s: %v", MetaPath(path), err)
+		if copied {
+			m.GUID = pathGUID(path)
+		}
+	}
+	return m
+}
+
+// ImportSettingsFor returns the settings for path without creating a sidecar.
+func ImportSettingsFor(path string) ImportSettings {
+	if m, err := ReadMeta(path); err == nil {
+		return m.Import
+	}
+	return DefaultImportSettings(path)
+}
@@ -0,0 +1,244 @@
+package assets
+
+import (
+	"archive/zip"
+	"fmt"
+	"go-engine/Go-Cordance/internal/engine"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ModelExtensions lists the model file types the importers accept.
+var ModelExtensions = []string{".gltf", ".glb", ".obj", ".stl", ".ply", ".zip"}
+
+// IsModelPath reports whether path has a model extension.
+func IsModelPath(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range ModelExtensions {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// singleMeshModel reports whether the format always decodes to one mesh
+// named after the file (or, for OBJ, does when it has one part). Such
+// assets keep a mesh ID string as Data.
+func singleMeshModel(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".obj", ".stl", ".ply":
+		return true
+	}
+	return false
+}
+
+func baseMeshID(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// ImportSTL loads an ASCII or binary STL and registers it as an asset.
+// Data = meshID string used by MeshManager.
+func ImportSTL(path string, mm *engine.MeshManager) (AssetID, string, error) {
+	meshID := baseMeshID(path)
+	md, err := engine.DecodeSTL(meshID, path)
+	if err != nil {
+		return 0, "", err
+	}
+	registerMeshes(path, []engine.MeshData{md}, mm)
+	Deps.Set(path, nil)
+	return Register(AssetMesh, path, meshID), meshID, nil
+}
+
+// ImportPLY loads a PLY, vertex colours included, and registers it as an
+// asset. Data = meshID string used by MeshManager.
+func ImportPLY(path string, mm *engine.MeshManager) (AssetID, string, error) {
+	meshID := baseMeshID(path)
+	md, err := engine.DecodePLY(meshID, path)
+	if err != nil {
+		return 0, "", err
+	}
+	registerMeshes(path, []engine.MeshData{md}, mm)
+	Deps.Set(path, nil)
+	return Register(AssetMesh, path, meshID), meshID, nil
+}
+
+// ImportGLTFZip unpacks a zipped glTF bundle and registers its meshes under
+// the .zip path, like ImportGLTFMulti. Materials and textures can be read
+// from the unpacked copy returned by GLTFInZip.
+func ImportGLTFZip(path string, mm *engine.MeshManager) (AssetID, []string, error) {
+	gltfPath, err := GLTFInZip(path)
+	if err != nil {
+		return 0, nil, err
+	}
+	meshes, err := engine.DecodeGLTFMeshes("", gltfPath, true)
+	if err != nil {
+		return 0, nil, err
+	}
+	meshIDs := registerMeshes(path, meshes, mm)
+	Deps.Set(path, nil)
+	return Register(AssetMesh, path, meshIDs), meshIDs, nil
+}
+
+// ImportModel imports any file in ModelExtensions with the importer for
+// its type.
+func ImportModel(path string, mm *engine.MeshManager) (AssetID, error) {
+	var id AssetID
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gltf", ".glb":
+		id, _, err = ImportGLTFMulti(path, mm)
+	case ".obj":
+		id, _, err = ImportOBJ(path, mm)
+	case ".stl":
+		id, _, err = ImportSTL(path, mm)
+	case ".ply":
+		id, _, err = ImportPLY(path, mm)
+	case ".zip":
+		id, _, err = ImportGLTFZip(path, mm)
+	default:
+		err = fmt.Errorf("%s: not a model file", path)
+	}
+	return id, err
+}
+
+// decodeSingleMesh decodes the formats that yield one mesh named id.
+func decodeSingleMesh(id, path string) (engine.MeshData, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".stl":
+		return engine.DecodeSTL(id, path)
+	case ".ply":
+		return engine.DecodePLY(id, path)
+	}
+	return engine.MeshData{}, fmt.Errorf("%s: not a single-mesh format", path)
+}
+
+// GLTFInZip unpacks a .zip next to itself, into a hidden directory the
+// asset watcher and folder scans skip, and returns the glTF or GLB file
+// inside (the shallowest one if there are several). Buffers and images
+// keep their relative paths, so the glTF resolves them as usual.
+//
+// The zip is only unpacked again when its size or modification time
+// changed, and the .meta sidecars of the unpacked files survive that, so
+// their GUIDs stay stable.
+func GLTFInZip(path string) (string, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return "", err
+	}
+	defer zr.Close()
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(filepath.Dir(path), ".unzipped", filepath.Base(path))
+	stampPath := filepath.Join(dir, zipStampName)
+	stamp := fmt.Sprintf("%d %d\n", fi.Size(), fi.ModTime().UnixNano())
+	old, _ := os.ReadFile(stampPath)
+	fresh := string(old) == stamp
+	if !fresh {
+		if err := removeUnpacked(dir); err != nil {
+			return "", err
+		}
+	}
+
+	var found []string
+	for _, f := range zr.File {
+		name := filepath.FromSlash(f.Name)
+		// Reject entries that would land outside dir.
+		if !filepath.IsLocal(name) {
+			return "", fmt.Errorf("%s: unsafe entry %q", path, f.Name)
+		}
+		dst := filepath.Join(dir, name)
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(dst, 0755); err != nil {
+				return "", err
+			}
+			continue
+		}
+		if st, err := os.Stat(dst); !fresh || err != nil || st.Size() != int64(f.UncompressedSize64) {
+			if err := unzipFile(f, dst); err != nil {
+				return "", fmt.Errorf("%s: %s: %w", path, f.Name, err)
+			}
+		}
+		if ext := strings.ToLower(filepath.Ext(name)); ext == ".gltf" || ext == ".glb" {
+			found = append(found, dst)
+		}
+	}
+	if len(found) == 0 {
+		return "", fmt.Errorf("%s: no .gltf or .glb inside", path)
+	}
+	if !fresh {
+		if err := pruneSidecars(dir); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(stampPath, []byte(stamp), 0644); err != nil {
+			return "", err
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		di, dj := strings.Count(found[i], string(filepath.Separator)), strings.Count(found[j], string(filepath.Separator))
+		if di != dj {
+			return di < dj
+		}
+		return found[i] < found[j]
+	})
+	return found[0], nil
+}
+
+// zipStampName records the size and modification time of the zip a
+// directory was unpacked from.
+const zipStampName = ".zipstamp"
+
+// removeUnpacked deletes an earlier unpack of a zip except for its .meta
+// sidecars.
+func removeUnpacked(dir string) error {
+	err := filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || IsMetaPath(p) {
+			return err
+		}
+		return os.Remove(p)
+	})
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// pruneSidecars deletes the sidecars in dir whose file no longer exists.
+func pruneSidecars(dir string) error {
+	return filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !IsMetaPath(p) {
+			return err
+		}
+		if _, err := os.Stat(strings.TrimSuffix(p, MetaExt)); os.IsNotExist(err) {
+			return os.Remove(p)
+		}
+		return nil
+	})
+}
+
+func unzipFile(f *zip.File, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	in, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
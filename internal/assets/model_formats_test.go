@@ -0,0 +1,90 @@
+package assets
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeZip(t *testing.T, files map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "bundle.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for name, body := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(body))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	return path
+}
+
+func TestGLTFInZip_UnpacksNextToBundle(t *testing.T) {
+	path := writeZip(t, map[string]string{
+		"chair/extra/lod1.gltf": "{}",
+		"chair/chair.gltf":      "{}",
+		"chair/chair.bin":       "bin",
+		"chair/tex/wood.png":    "png",
+	})
+	got, err := GLTFInZip(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(filepath.Dir(path), ".unzipped", "bundle.zip")
+	if got != filepath.Join(dir, "chair", "chair.gltf") {
+		t.Fatalf("gltf = %s", got)
+	}
+	if b, err := os.ReadFile(filepath.Join(dir, "chair", "tex", "wood.png")); err != nil || string(b) != "png" {
+		t.Fatalf("texture not unpacked: %q %v", b, err)
+	}
+
+	// Loading again leaves the unpacked files and their sidecars alone.
+	wood := filepath.Join(dir, "chair", "tex", "wood.png")
+	meta := MetaFor(wood)
+	before, _ := os.Stat(wood)
+	if _, err := GLTFInZip(path); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := os.Stat(wood); !after.ModTime().Equal(before.ModTime()) {
+		t.Fatal("unchanged zip unpacked again")
+	}
+
+	// A changed zip is unpacked again; sidecars of files it still has keep
+	// their GUID, the rest are removed.
+	os.Remove(filepath.Join(dir, "chair", "chair.bin"))
+	orphan := filepath.Join(dir, "chair", "gone.png") + MetaExt
+	os.WriteFile(orphan, []byte("{}"), 0644)
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(path, later, later)
+	if _, err := GLTFInZip(path); err != nil {
+		t.Fatal(err)
+	}
+	if again := MetaFor(wood); again.GUID != meta.GUID {
+		t.Fatalf("wood.png GUID changed from %v to %v", meta.GUID, again.GUID)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "chair", "chair.bin")); err != nil {
+		t.Fatalf("chair.bin not unpacked again: %v", err)
+	}
+	if _, err := os.Stat(orphan); !os.IsNotExist(err) {
+		t.Fatalf("orphaned sidecar left behind: %v", err)
+	}
+
+	if _, err := GLTFInZip(writeZip(t, map[string]string{"../evil.gltf": "{}"})); err == nil || !strings.Contains(err.Error(), "unsafe entry") {
+		t.Fatalf("escaping entry: err = %v", err)
+	}
+	if _, err := GLTFInZip(writeZip(t, map[string]string{"readme.txt": ""})); err == nil {
+		t.Fatal("zip without a glTF imported")
+	}
+}
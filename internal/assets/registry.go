@@ -1,28 +1,79 @@
 package assets
 
 import (
+	"fmt"
+	"os"
 	"path/filepath"
-	"sync/atomic"
+	"strconv"
 )
 
 var (
-	registry    = map[AssetID]*Asset{}
-	nextAssetID uint64
+	registry = map[AssetID]*Asset{}
+	byGUID   = map[GUID]AssetID{}
+	byPath   = map[string]AssetID{}
 )
 
 func normalize(p string) string {
 	return filepath.ToSlash(p)
 }
 
+// maxAssetID keeps IDs within float64's exact integer range: they travel
+// through JSON component fields as plain numbers.
+const maxAssetID = 1<<53 - 1
+
+// idFromGUID derives an AssetID from the leading bits of a GUID, probing
+// upwards on the (unlikely) collision with a different GUID.
+func idFromGUID(g GUID) AssetID {
+	n, err := strconv.ParseUint(string(g)[:min(len(g), 14)], 16, 64)
+	if err != nil {
+		n = 0
+	}
+	id := AssetID(n & maxAssetID)
+	for {
+		if id == 0 {
+			id = 1
+		}
+		if a, ok := registry[id]; !ok || a.GUID == g {
+			return id
+		}
+		id = (id + 1) & maxAssetID
+	}
+}
+
+// Register adds an asset for path, or updates the one already registered
+// there. Its GUID (and therefore its ID) comes from the path's .meta sidecar.
 func Register(t AssetType, path string, data any) AssetID {
 	path = normalize(path)
-	id := AssetID(atomic.AddUint64(&nextAssetID, 1))
+	if id, ok := byPath[path]; ok {
+		a := registry[id]
+		a.Type, a.Data = t, data
+		return id
+	}
+	meta := MetaFor(path)
+	if id, ok := byGUID[meta.GUID]; ok && registry[id].Path != path {
+		// MetaFor tells copies by the file name their sidecar records; this
+		// catches the rest (sidecars without one, copies under the same
+		// name elsewhere), which cannot be told apart, so the asset
+		// registered first keeps the GUID.
+		meta.GUID = NewGUID()
+		if _, err := os.Stat(path); err == nil {
+			WriteMeta(path, meta)
+		}
+	}
+	return register(t, path, meta.GUID, data)
+}
+
+func register(t AssetType, path string, guid GUID, data any) AssetID {
+	id := idFromGUID(guid)
 	registry[id] = &Asset{
 		ID:   id,
+		GUID: guid,
 		Type: t,
 		Path: path,
 		Data: data,
 	}
+	byGUID[guid] = id
+	byPath[path] = id
 	return id
 }
 
@@ -30,6 +81,20 @@ func Get(id AssetID) *Asset {
 	return registry[id]
 }
 
+// Unregister drops an asset from the registry. Its .meta sidecar stays on
+// disk, so importing the path again restores the same GUID and ID.
+func Unregister(id AssetID) {
+	a := registry[id]
+	if a == nil {
+		return
+	}
+	delete(registry, id)
+	delete(byGUID, a.GUID)
+	delete(byPath, a.Path)
+	delete(objMaterials, id)
+	Deps.Remove(a.Path)
+}
+
 func All() []*Asset {
 	out := make([]*Asset, 0, len(registry))
 	for _, a := range registry {
@@ -39,14 +104,54 @@ func All() []*Asset {
 }
 
 func FindAssetByPath(path string) *Asset {
+	if id, ok := byPath[normalize(path)]; ok {
+		return registry[id]
+	}
+	return nil
+}
 
-	path = normalize(path)
+// FindAssetByGUID returns the registered asset with the given GUID.
+func FindAssetByGUID(g GUID) *Asset {
+	if id, ok := byGUID[g]; ok {
+		return registry[id]
+	}
+	return nil
+}
 
-	for _, a := range registry {
+// Move renames an asset file and its .meta sidecar and re-points the
+// registered asset, keeping its GUID and ID so existing references stay
+// valid. Files that were never registered are moved all the same.
+func Move(oldPath, newPath string) error {
+	oldPath, newPath = normalize(oldPath), normalize(newPath)
+	if oldPath == newPath {
+		return nil
+	}
+	if _, err := os.Stat(newPath); err == nil {
+		return fmt.Errorf("move %s: %s already exists", oldPath, newPath)
+	}
 
-		if a.Path == path {
-			return a
-		}
+	// Make sure the GUID is on disk before the file moves.
+	meta := MetaFor(oldPath)
+	if a := FindAssetByPath(oldPath); a != nil {
+		meta.GUID = a.GUID
+	}
+	meta.File = filepath.Base(newPath)
+
+	if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return err
+	}
+	os.Remove(MetaPath(oldPath))
+	if err := WriteMeta(newPath, meta); err != nil {
+		return err
+	}
+
+	if id, ok := byPath[oldPath]; ok {
+		delete(byPath, oldPath)
+		registry[id].Path = newPath
+		byPath[newPath] = id
 	}
 	return nil
 }
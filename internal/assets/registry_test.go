@@ -0,0 +1,140 @@
+package assets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// restart simulates a new process: the in-memory registry is gone, the
+// .meta sidecars on disk remain.
+func restart() {
+	registry = map[AssetID]*Asset{}
+	byGUID = map[GUID]AssetID{}
+	byPath = map[string]AssetID{}
+}
+
+func writeFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegister_StableIDsAcrossRestartAndMove(t *testing.T) {
+	t.Cleanup(restart)
+	restart()
+	dir := t.TempDir()
+	albedo := filepath.Join(dir, "crate.png")
+	normal := filepath.Join(dir, "crate_normal.png")
+	writeFile(t, albedo)
+	writeFile(t, normal)
+
+	id := Register(AssetTexture, albedo, nil)
+	nid := Register(AssetTexture, normal, nil)
+	if id == 0 || id == nid || id > maxAssetID {
+		t.Fatalf("ids = %d, %d", id, nid)
+	}
+	meta, err := ReadMeta(albedo)
+	if err != nil || meta.GUID != Get(id).GUID {
+		t.Fatalf("sidecar = %+v, %v", meta, err)
+	}
+	if !meta.Import.SRGB || meta.Import.NormalMap {
+		t.Fatalf("albedo defaults = %+v", meta.Import)
+	}
+	if s := ImportSettingsFor(normal); s.SRGB || !s.NormalMap {
+		t.Fatalf("normal-map defaults = %+v", s)
+	}
+	if Register(AssetTexture, albedo, "reload") != id || len(All()) != 2 {
+		t.Fatal("re-registering a path must update the existing asset")
+	}
+
+	restart()
+	if got := Register(AssetTexture, albedo, nil); got != id {
+		t.Fatalf("after restart id = %d, want %d", got, id)
+	}
+
+	moved := filepath.Join(dir, "props", "crate_albedo.png")
+	if err := Move(albedo, moved); err != nil {
+		t.Fatal(err)
+	}
+	if FindAssetByPath(albedo) != nil {
+		t.Fatal("old path still indexed")
+	}
+	a := FindAssetByPath(moved)
+	if a == nil || a.ID != id || FindAssetByGUID(meta.GUID) != a {
+		t.Fatalf("moved asset = %+v", a)
+	}
+	if _, err := os.Stat(MetaPath(albedo)); !os.IsNotExist(err) {
+		t.Fatal("old sidecar left behind")
+	}
+
+	restart()
+	if got := Register(AssetTexture, moved, nil); got != id {
+		t.Fatalf("moved asset after restart id = %d, want %d", got, id)
+	}
+}
+
+func TestRegister_CopiedSidecarGetsNewGUID(t *testing.T) {
+	t.Cleanup(restart)
+	restart()
+	dir := t.TempDir()
+	orig := filepath.Join(dir, "rock.obj")
+	writeFile(t, orig)
+	id := Register(AssetMesh, orig, nil)
+	guid := Get(id).GUID
+
+	// "rock copy.obj" sorts first, so a fresh scan registers the copy
+	// before the original; the original must still keep its identity.
+	cp := filepath.Join(dir, "rock copy.obj")
+	writeFile(t, cp)
+	data, _ := os.ReadFile(MetaPath(orig))
+	os.WriteFile(MetaPath(cp), data, 0644)
+	restart()
+
+	cid := Register(AssetMesh, cp, nil)
+	if Register(AssetMesh, orig, nil) != id || cid == id || Get(id).Path != filepath.ToSlash(orig) {
+		t.Fatalf("copy stole the original's identity: %d vs %d", cid, id)
+	}
+	if m, _ := ReadMeta(cp); m.GUID == guid || m.File != "rock copy.obj" {
+		t.Fatalf("copy's sidecar was not rewritten: %+v", m)
+	}
+
+	// Renamed outside the editor, sidecar and all: same asset.
+	renamed := filepath.Join(dir, "boulder.obj")
+	os.Rename(orig, renamed)
+	os.Rename(MetaPath(orig), MetaPath(renamed))
+	restart()
+	if got := Register(AssetMesh, renamed, nil); got != id {
+		t.Fatalf("renamed asset id = %d, want %d", got, id)
+	}
+	if m, _ := ReadMeta(renamed); m.GUID != guid || m.File != "boulder.obj" {
+		t.Fatalf("renamed sidecar = %+v", m)
+	}
+}
+
+func TestMetaFor_UnwritableSidecar(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "stone.png")
+	writeFile(t, path)
+	// A directory in the sidecar's place makes it unwritable, even as root.
+	if err := os.Mkdir(MetaPath(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if a, b := MetaFor(path), MetaFor(path); a.GUID != pathGUID(path) || b.GUID != a.GUID {
+		t.Fatalf("GUIDs %s, %s; want the path-derived one", a.GUID, b.GUID)
+	}
+}
+
+func TestRegister_VirtualPathsAreStable(t *testing.T) {
+	t.Cleanup(restart)
+	restart()
+	id := Register(AssetAnimationClip, "generated/run", nil)
+	restart()
+	if Register(AssetAnimationClip, "generated/run", nil) != id {
+		t.Fatal("path-derived GUID is not stable")
+	}
+	if _, err := os.Stat(MetaPath("generated/run")); !os.IsNotExist(err) {
+		t.Fatal("sidecar written for a path with no file")
+	}
+}
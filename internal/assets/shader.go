@@ -18,12 +18,27 @@ func LoadShader(path string) (AssetID, error) {
 	}
 
 	id := Register(AssetShader, path, src)
+	// Includes are dependencies too, so editing one reloads the shader. A
+	// broken include is reported when the shader compiles.
+	files, _ := src.Files()
+	Deps.Set(path, files)
 	return id, nil
 }
 
+// FindShaderAsset returns the shader asset whose source declares name.
+func FindShaderAsset(name string) *Asset {
+	for _, a := range registry {
+		if src, ok := a.Data.(shaderlang.ShaderSource); ok && a.Type == AssetShader && src.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
 type ShaderFile struct {
 	Name         string         `json:"name"`
 	VertexPath   string         `json:"vertex"`
 	FragmentPath string         `json:"fragment"`
 	Defines      map[string]any `json:"defines"`
+	Keywords     []string       `json:"keywords"`
 }
@@ -6,14 +6,15 @@ import (
 
 // TextureData holds runtime GPU info for a texture.
 type TextureData struct {
-	GLID uint32
-	SRGB bool
+	GLID      uint32
+	SRGB      bool
+	NormalMap bool
 }
 
 // ImportTexture loads a texture via engine.LoadTexture and registers it as an asset.
-// Default behavior: treat as sRGB (good for base color / albedo).
+// Color space and the normal-map flag come from the file's .meta import settings.
 func ImportTexture(path string) (AssetID, uint32, error) {
-	return ImportTextureWithSRGB(path, true)
+	return ImportTextureWithSRGB(path, ImportSettingsFor(path).SRGB)
 }
 
 // ImportTextureWithSRGB lets caller choose color space.
@@ -31,7 +32,25 @@ func ImportTextureWithSRGB(path string, srgb bool) (AssetID, uint32, error) {
 	if err != nil {
 		return 0, 0, err
 	}
-	data := TextureData{GLID: texGL}
+	data := TextureData{GLID: texGL, SRGB: srgb, NormalMap: ImportSettingsFor(path).NormalMap}
 	id := Register(AssetTexture, path, data)
 	return id, texGL, nil
 }
+
+// ImportCookedTexture registers a texture asset under srcPath, uploading the
+// pre-built mip chain at cookedPath instead of decoding the source image.
+func ImportCookedTexture(srcPath, cookedPath string, srgb bool) (AssetID, uint32, error) {
+	srcPath = normalize(srcPath)
+	if a := FindAssetByPath(srcPath); a != nil {
+		if td, ok := a.Data.(TextureData); ok {
+			return a.ID, td.GLID, nil
+		}
+	}
+	texGL, err := engine.LoadCookedTexture(cookedPath)
+	if err != nil {
+		return 0, 0, err
+	}
+	data := TextureData{GLID: texGL, SRGB: srgb, NormalMap: ImportSettingsFor(srcPath).NormalMap}
+	id := Register(AssetTexture, srcPath, data)
+	return id, texGL, nil
+}
@@ -0,0 +1,269 @@
+// Package cook converts source assets into engine-native bundles: meshes into
+// pre-interleaved, tangent-filled vertex data and textures into pre-built mip
+// chains. A manifest of content hashes lets it skip unchanged inputs and lets
+// the runtime loaders prefer cooked outputs.
+package cook
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	_ "image/jpeg"
+
+	"go-engine/Go-Cordance/internal/assets"
+	"go-engine/Go-Cordance/internal/engine"
+)
+
+// DefaultOutDir is where `cook` writes and the runtime looks by default.
+const DefaultOutDir = "assets/.cooked"
+
+// Options configure a cook run.
+type Options struct {
+	SrcDir   string // asset root containing models/ and textures/
+	OutDir   string
+	Force    bool // rebuild even if the hashes match
+	Compress bool // zlib-compress texture mip chains
+}
+
+// Result lists what a run did, by source path.
+type Result struct {
+	Cooked  []string
+	Skipped []string
+	Failed  map[string]error
+}
+
+var (
+	meshExts    = map[string]bool{".gltf": true, ".glb": true, ".obj": true, ".stl": true, ".ply": true, ".zip": true}
+	textureExts = map[string]bool{".png": true, ".jpg": true, ".jpeg": true}
+)
+
+// Run cooks every mesh below SrcDir/models and texture below SrcDir/textures
+// whose inputs changed since the last run, then rewrites the manifest. Per-asset
+// failures are collected in Result.Failed; the returned error is for I/O on
+// the output directory itself.
+func Run(opts Options) (Result, error) {
+	res := Result{Failed: map[string]error{}}
+	if err := os.MkdirAll(opts.OutDir, 0755); err != nil {
+		return res, err
+	}
+	m := Open(opts.OutDir)
+
+	jobs := []struct {
+		dir, kind, ext string
+		exts           map[string]bool
+		cook           func(src, out string, opts Options) ([]string, error)
+	}{
+		{"models", "mesh", ".mesh", meshExts, cookMesh},
+		{"textures", "texture", ".tex", textureExts, cookTexture},
+	}
+
+	seen := map[string]bool{}
+	for _, job := range jobs {
+		var srcs []string
+		err := filepath.WalkDir(filepath.Join(opts.SrcDir, job.dir), func(p string, d fs.DirEntry, err error) error {
+			switch {
+			case err != nil:
+				return err
+			case d.IsDir():
+				// Hidden directories hold generated files: unpacked
+				// zips (.unzipped) and cooked output (.cooked).
+				if strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+			case job.exts[strings.ToLower(filepath.Ext(d.Name()))]:
+				srcs = append(srcs, p)
+			}
+			return nil
+		})
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return res, err
+		}
+		for _, p := range srcs {
+			src := filepath.ToSlash(p)
+			seen[src] = true
+
+			inputs, err := inputsFor(src)
+			if err != nil {
+				res.Failed[src] = err
+				continue
+			}
+			hash, err := hashInputs(inputs)
+			if err != nil {
+				res.Failed[src] = err
+				continue
+			}
+
+			// Outputs mirror the source tree below SrcDir.
+			relSrc, err := filepath.Rel(opts.SrcDir, p)
+			if err != nil {
+				return res, err
+			}
+			rel := filepath.ToSlash(relSrc) + job.ext
+			out := filepath.Join(opts.OutDir, filepath.FromSlash(rel))
+			if old, ok := m.Entries[src]; ok && !opts.Force && old.Hash == hash && old.Output == rel {
+				if _, err := os.Stat(out); err == nil {
+					// Touched but identical: refresh the stat info only.
+					old.Inputs = inputs
+					m.Entries[src] = old
+					res.Skipped = append(res.Skipped, src)
+					continue
+				}
+			}
+
+			if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+				return res, err
+			}
+			deps, err := job.cook(src, out, opts)
+			if err != nil {
+				res.Failed[src] = err
+				delete(m.Entries, src)
+				continue
+			}
+			m.Entries[src] = Entry{Kind: job.kind, Hash: hash, Inputs: inputs, Output: rel, Deps: deps}
+			res.Cooked = append(res.Cooked, src)
+		}
+	}
+
+	// Drop outputs whose source was deleted.
+	for src, e := range m.Entries {
+		if !seen[src] {
+			os.Remove(filepath.Join(opts.OutDir, filepath.FromSlash(e.Output)))
+			delete(m.Entries, src)
+		}
+	}
+	return res, m.save()
+}
+
+// inputsFor lists src plus the files its cooked output depends on: the .meta
+// sidecar with its import settings and, for glTF, external buffers.
+func inputsFor(src string) ([]Input, error) {
+	paths := []string{src}
+	if _, err := os.Stat(assets.MetaPath(src)); err == nil {
+		paths = append(paths, assets.MetaPath(src))
+	}
+	if strings.ToLower(filepath.Ext(src)) == ".gltf" {
+		g, err := engine.LoadGLTFRoot(src)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range g.Buffers {
+			if b.URI != "" && !strings.HasPrefix(b.URI, "data:") {
+				paths = append(paths, filepath.ToSlash(filepath.Join(filepath.Dir(src), b.URI)))
+			}
+		}
+	}
+
+	inputs := make([]Input, 0, len(paths))
+	for _, p := range paths {
+		fi, err := os.Stat(filepath.FromSlash(p))
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, Input{Path: p, Size: fi.Size(), ModTime: fi.ModTime().UnixNano()})
+	}
+	return inputs, nil
+}
+
+func hashInputs(inputs []Input) (string, error) {
+	h := sha256.New()
+	sorted := append([]Input(nil), inputs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+	for _, in := range sorted {
+		f, err := os.Open(filepath.FromSlash(in.Path))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00", filepath.Base(in.Path))
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// cookMesh writes src's cooked bundle and returns the files the source
+// depends on.
+func cookMesh(src, out string, _ Options) ([]string, error) {
+	var meshes []engine.MeshData
+	var deps []string
+	switch strings.ToLower(filepath.Ext(src)) {
+	case ".gltf", ".glb":
+		var err error
+		if meshes, err = engine.DecodeGLTFMeshes("", src, true); err != nil {
+			return nil, err
+		}
+		if deps, err = assets.GLTFDependencies(src); err != nil {
+			return nil, err
+		}
+	case ".obj":
+		base := filepath.Base(src)
+		model, err := engine.DecodeOBJModel(strings.TrimSuffix(base, filepath.Ext(base)), src)
+		if err != nil {
+			return nil, err
+		}
+		meshes, deps = model.Meshes, assets.OBJDependencies(model)
+	case ".stl", ".ply":
+		base := filepath.Base(src)
+		id := strings.TrimSuffix(base, filepath.Ext(base))
+		decode := engine.DecodeSTL
+		if strings.ToLower(filepath.Ext(src)) == ".ply" {
+			decode = engine.DecodePLY
+		}
+		md, err := decode(id, src)
+		if err != nil {
+			return nil, err
+		}
+		meshes = []engine.MeshData{md}
+	case ".zip":
+		gltfPath, err := assets.GLTFInZip(src)
+		if err != nil {
+			return nil, err
+		}
+		if meshes, err = engine.DecodeGLTFMeshes("", gltfPath, true); err != nil {
+			return nil, err
+		}
+	}
+	meshes = append(meshes, assets.ProcessMeshes(src, meshes)...)
+	return deps, writeFile(out, func(w io.Writer) error { return engine.WriteCookedMeshes(w, meshes) })
+}
+
+func cookTexture(src, out string, opts Options) ([]string, error) {
+	img, err := engine.DecodeImageFile(src)
+	if err != nil {
+		return nil, err
+	}
+	chain := engine.BuildMipChain(img)
+	return nil, writeFile(out, func(w io.Writer) error { return engine.WriteCookedTexture(w, chain, opts.Compress) })
+}
+
+// writeFile writes via a temp file so a failed cook never leaves a truncated
+// output that the runtime would pick up.
+func writeFile(path string, write func(io.Writer) error) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,337 @@
+package cook
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"go-engine/Go-Cordance/internal/assets"
+	"go-engine/Go-Cordance/internal/engine"
+)
+
+const quadOBJ = `v -1 0 -1
+v 1 0 -1
+v 1 0 1
+v -1 0 1
+vt 0 0
+vt 1 0
+vt 1 1
+vt 0 1
+f 1/1 2/2 3/3 4/4
+`
+
+const triGLTF = `{"asset":{"version":"2.0"},
+ "buffers":[{"uri":"tri.bin","byteLength":36}],
+ "bufferViews":[{"buffer":0,"byteLength":36}],
+ "accessors":[{"bufferView":0,"componentType":5126,"count":3,"type":"VEC3"}],
+ "meshes":[{"name":"tri","primitives":[{"attributes":{"POSITION":0}}]}]}`
+
+func writeTriBin(t *testing.T, path string, scale float32) {
+	t.Helper()
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, []float32{0, 0, 0, scale, 0, 0, 0, scale, 0})
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func setupAssets(t *testing.T) string {
+	t.Helper()
+	src := t.TempDir()
+	models, textures := filepath.Join(src, "models"), filepath.Join(src, "textures")
+	os.MkdirAll(models, 0755)
+	os.MkdirAll(textures, 0755)
+
+	os.WriteFile(filepath.Join(models, "quad.obj"), []byte(quadOBJ), 0644)
+	os.WriteFile(filepath.Join(models, "tri.gltf"), []byte(triGLTF), 0644)
+	writeTriBin(t, filepath.Join(models, "tri.bin"), 1)
+	os.WriteFile(filepath.Join(models, "notes.txt"), []byte("ignored"), 0644)
+
+	// 3x2: odd width exercises the box filter's edge folding.
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	img.Set(0, 0, color.RGBA{0, 0, 0, 255})
+	f, err := os.Create(filepath.Join(textures, "checker.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	png.Encode(f, img)
+	f.Close()
+	return src
+}
+
+func sorted(s []string) []string {
+	s = append([]string(nil), s...)
+	sort.Strings(s)
+	return s
+}
+
+func TestRun_CooksAndSkipsUnchanged(t *testing.T) {
+	src := setupAssets(t)
+	out := t.TempDir()
+	opts := Options{SrcDir: src, OutDir: out, Compress: true}
+
+	res, err := Run(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Failed) != 0 || len(res.Cooked) != 3 {
+		t.Fatalf("first run: cooked=%v failed=%v", res.Cooked, res.Failed)
+	}
+
+	m := Open(out)
+	objSrc := filepath.ToSlash(filepath.Join(src, "models", "quad.obj"))
+	objOut, ok := m.Cooked(objSrc)
+	if !ok {
+		t.Fatal("quad.obj has no cooked output")
+	}
+	f, err := os.Open(objOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	meshes, err := engine.ReadCookedMeshes(f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := engine.DecodeOBJModel("quad", filepath.FromSlash(objSrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(meshes, want.Meshes) {
+		t.Fatalf("cooked OBJ differs from source decode:\n got %+v\nwant %+v", meshes, want)
+	}
+
+	texOut, ok := m.Cooked(filepath.ToSlash(filepath.Join(src, "textures", "checker.png")))
+	if !ok {
+		t.Fatal("checker.png has no cooked output")
+	}
+	f, err = os.Open(texOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tex, err := engine.ReadCookedTexture(f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tex.Width != 3 || tex.Height != 2 || len(tex.Levels) != 2 || len(tex.Levels[1]) != 4 {
+		t.Fatalf("mip chain %dx%d levels=%d", tex.Width, tex.Height, len(tex.Levels))
+	}
+	// 1x1 level averages five white texels and one black one.
+	if got := tex.Levels[1][0]; got != 213 {
+		t.Fatalf("1x1 mip red = %d, want 213", got)
+	}
+
+	// Second run: nothing changed.
+	res, err = Run(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Cooked) != 0 || len(res.Skipped) != 3 {
+		t.Fatalf("second run: cooked=%v skipped=%v", res.Cooked, res.Skipped)
+	}
+
+	// The glTF's buffer is recorded for hot reload of the cooked model.
+	gltfSrc := filepath.ToSlash(filepath.Join(src, "models", "tri.gltf"))
+	wantDeps := []string{filepath.ToSlash(filepath.Join(src, "models", "tri.bin"))}
+	if got := m.Deps(gltfSrc); !reflect.DeepEqual(got, wantDeps) {
+		t.Fatalf("glTF deps = %v, want %v", got, wantDeps)
+	}
+
+	// Changing a glTF's external buffer invalidates only that entry.
+	writeTriBin(t, filepath.Join(src, "models", "tri.bin"), 2)
+	if _, ok := Open(out).Cooked(gltfSrc); ok {
+		t.Fatal("stale glTF output still reported as cooked")
+	}
+	res, err = Run(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res.Cooked, []string{gltfSrc}) || len(res.Skipped) != 2 {
+		t.Fatalf("third run: cooked=%v skipped=%v", res.Cooked, sorted(res.Skipped))
+	}
+	gltfOut, _ := Open(out).Cooked(gltfSrc)
+	f, _ = os.Open(gltfOut)
+	meshes, err = engine.ReadCookedMeshes(f)
+	f.Close()
+	if err != nil || len(meshes) != 1 || meshes[0].ID != "tri/0" || meshes[0].Vertices[12] != 2 {
+		t.Fatalf("recooked glTF: %+v err=%v", meshes, err)
+	}
+
+	// Deleted sources drop their entry and output.
+	os.Remove(filepath.Join(src, "textures", "checker.png"))
+	if _, err := Run(opts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(texOut); !os.IsNotExist(err) {
+		t.Fatalf("orphaned texture output left behind: %v", err)
+	}
+	if len(Open(out).Entries) != 2 {
+		t.Fatalf("entries = %v", Open(out).Entries)
+	}
+}
+
+func TestRun_CooksNestedModels(t *testing.T) {
+	src := t.TempDir()
+	sofa := filepath.Join(src, "models", "sofa")
+	unzipped := filepath.Join(src, "models", ".unzipped", "chair.zip")
+	os.MkdirAll(sofa, 0755)
+	os.MkdirAll(unzipped, 0755)
+	os.WriteFile(filepath.Join(sofa, "sofa.obj"), []byte(quadOBJ), 0644)
+	os.WriteFile(filepath.Join(unzipped, "chair.obj"), []byte(quadOBJ), 0644)
+
+	out := filepath.Join(src, ".cooked")
+	res, err := Run(Options{SrcDir: src, OutDir: out})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sofaSrc := filepath.ToSlash(filepath.Join(sofa, "sofa.obj"))
+	if !reflect.DeepEqual(res.Cooked, []string{sofaSrc}) || len(res.Failed) != 0 {
+		t.Fatalf("cooked=%v failed=%v, want only the nested sofa", res.Cooked, res.Failed)
+	}
+	if e := Open(out).Entries[sofaSrc]; e.Output != "models/sofa/sofa.obj.mesh" {
+		t.Fatalf("sofa output = %q", e.Output)
+	}
+	if _, ok := Open(out).Cooked(sofaSrc); !ok {
+		t.Fatal("nested model has no cooked output")
+	}
+}
+
+func TestReadCookedMeshes_WidensEightFloatLayout(t *testing.T) {
+	var buf bytes.Buffer
+	le := binary.LittleEndian
+	buf.WriteString("GCMB")
+	binary.Write(&buf, le, [2]uint32{1, 1})
+	binary.Write(&buf, le, uint16(3))
+	buf.WriteString("tri")
+	binary.Write(&buf, le, [4]uint32{8, 3, 3, 0})
+	binary.Write(&buf, le, []float32{
+		0, 0, 0, 0, 0, 1, 0, 0,
+		1, 0, 0, 0, 0, 1, 1, 0,
+		0, 1, 0, 0, 0, 1, 0, 1,
+	})
+	binary.Write(&buf, le, []uint32{0, 1, 2})
+
+	meshes, err := engine.ReadCookedMeshes(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := meshes[0].Vertices
+	if len(v) != 36 {
+		t.Fatalf("vertices = %d floats, want 36", len(v))
+	}
+	if tan := v[8:12]; tan[0] != 1 || tan[1] != 0 || tan[3] != 1 {
+		t.Fatalf("tangent = %v, want +X", tan)
+	}
+}
+
+func TestWriteCookedMeshes_EightFloatLayout(t *testing.T) {
+	md := engine.MeshData{
+		ID: "tri",
+		Vertices: []float32{
+			0, 0, 0, 0, 0, 1, 0, 0, 1, 0, 0, 1,
+			1, 0, 0, 0, 0, 1, 1, 0, 1, 0, 0, 1,
+			0, 1, 0, 0, 0, 1, 0, 1, 1, 0, 0, 1,
+		},
+		Indices: []uint32{0, 1, 2},
+	}
+	var buf bytes.Buffer
+	if err := engine.WriteCookedMeshes(&buf, []engine.MeshData{md}); err != nil {
+		t.Fatal(err)
+	}
+	// magic, version, count, id length, id, then the stride.
+	if stride := binary.LittleEndian.Uint32(buf.Bytes()[4+8+2+3:]); stride != 8 {
+		t.Fatalf("placeholder tangents written with stride %d", stride)
+	}
+
+	meshes, err := engine.ReadCookedMeshes(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := meshes[0]; !got.HasTangents || !reflect.DeepEqual(got.Vertices, md.Vertices) {
+		t.Fatalf("round trip: %+v", got)
+	}
+}
+
+// gridOBJ is a flat n×n grid of quads, which simplifies without error.
+func gridOBJ(n int) string {
+	var b strings.Builder
+	for z := 0; z <= n; z++ {
+		for x := 0; x <= n; x++ {
+			fmt.Fprintf(&b, "v %d 0 %d\n", x, z)
+		}
+	}
+	for z := 0; z < n; z++ {
+		for x := 0; x < n; x++ {
+			i := z*(n+1) + x + 1
+			fmt.Fprintf(&b, "f %d %d %d %d\n", i, i+n+1, i+n+2, i+1)
+		}
+	}
+	return b.String()
+}
+
+func TestRun_CooksLODsFromImportSettings(t *testing.T) {
+	src := t.TempDir()
+	grid := filepath.Join(src, "models", "grid.obj")
+	os.MkdirAll(filepath.Dir(grid), 0755)
+	os.WriteFile(grid, []byte(gridOBJ(8)), 0644)
+	s := assets.DefaultImportSettings(grid)
+	s.LODs = []float32{0.5, 0.25}
+	if err := assets.WriteMeta(grid, assets.Meta{GUID: assets.NewGUID(), File: "grid.obj", Import: s}); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(src, ".cooked")
+	if _, err := Run(Options{SrcDir: src, OutDir: out}); err != nil {
+		t.Fatal(err)
+	}
+	cooked, ok := Open(out).Cooked(filepath.ToSlash(grid))
+	if !ok {
+		t.Fatal("grid was not cooked")
+	}
+	f, err := os.Open(cooked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	meshes, err := engine.ReadCookedMeshes(f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(meshes) != 3 {
+		t.Fatalf("bundle holds %d meshes, want the grid and two LODs", len(meshes))
+	}
+	base := meshes[0]
+	for level, lod := range meshes[1:] {
+		if lod.ID != engine.LODMeshID(base.ID, level+1) {
+			t.Errorf("mesh %d is %q", level+1, lod.ID)
+		}
+		if len(lod.Indices) >= len(base.Indices) {
+			t.Errorf("%s keeps %d of %d indices", lod.ID, len(lod.Indices), len(base.Indices))
+		}
+	}
+
+	prev := engine.Device
+	engine.Device = engine.NewRecordingDevice()
+	defer func() { engine.Device = prev }()
+	mm := engine.NewMeshManager()
+	_, ids, err := assets.ImportCookedMesh(grid, cooked, nil, mm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []string{base.ID}) || mm.LODCount(base.ID) != 2 {
+		t.Fatalf("asset meshes %v with %d LODs, want [%s] with 2", ids, mm.LODCount(base.ID), base.ID)
+	}
+}
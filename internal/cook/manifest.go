@@ -0,0 +1,103 @@
+package cook
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// ManifestName is the manifest file inside the cooked output directory.
+const ManifestName = "manifest.json"
+
+// manifestVersion is bumped whenever a cooked format or the cooking itself
+// changes, which forces a full rebuild.
+const manifestVersion = 2
+
+// Input is one file an entry was cooked from. Size and ModTime let the
+// runtime spot stale outputs without rehashing.
+type Input struct {
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"modTime"` // unix nanoseconds
+}
+
+// Entry records how one source asset was cooked.
+type Entry struct {
+	Kind   string  `json:"kind"` // "mesh" or "texture"
+	Hash   string  `json:"hash"` // sha256 over all inputs
+	Inputs []Input `json:"inputs"`
+	Output string  `json:"output"` // relative to the manifest directory
+
+	// Deps are the files the source reads at load time (glTF buffers and
+	// images, OBJ material libraries and textures). The runtime records them
+	// for hot reload, since a cooked load never opens the source.
+	Deps []string `json:"deps,omitempty"`
+}
+
+// Manifest maps source paths (slash-separated, as passed to the loaders) to
+// their cooked entry.
+type Manifest struct {
+	Version int              `json:"version"`
+	Entries map[string]Entry `json:"entries"`
+
+	dir string
+}
+
+// Open reads the manifest in dir. A missing or outdated manifest yields an
+// empty one, so callers can always fall back to the source assets.
+func Open(dir string) *Manifest {
+	m := &Manifest{Version: manifestVersion, Entries: map[string]Entry{}, dir: dir}
+	raw, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	if err != nil {
+		return m
+	}
+	var disk Manifest
+	if json.Unmarshal(raw, &disk) != nil || disk.Version != manifestVersion || disk.Entries == nil {
+		return m
+	}
+	m.Entries = disk.Entries
+	return m
+}
+
+func (m *Manifest) save() error {
+	raw, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(m.dir, ManifestName), raw, 0644)
+}
+
+// Cooked returns the cooked output for src if one exists and none of its
+// inputs changed since it was built.
+func (m *Manifest) Cooked(src string) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	e, ok := m.Entries[filepath.ToSlash(src)]
+	if !ok || !inputsUnchanged(e.Inputs) {
+		return "", false
+	}
+	out := filepath.Join(m.dir, filepath.FromSlash(e.Output))
+	if _, err := os.Stat(out); err != nil {
+		return "", false
+	}
+	return out, true
+}
+
+func inputsUnchanged(inputs []Input) bool {
+	for _, in := range inputs {
+		fi, err := os.Stat(filepath.FromSlash(in.Path))
+		if err != nil || fi.Size() != in.Size || fi.ModTime().UnixNano() != in.ModTime {
+			return false
+		}
+	}
+	return len(inputs) > 0
+}
+
+// Deps returns the dependencies recorded when src was cooked.
+func (m *Manifest) Deps(src string) []string {
+	if m == nil {
+		return nil
+	}
+	return m.Entries[filepath.ToSlash(src)].Deps
+}
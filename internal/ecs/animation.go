@@ -2,17 +2,82 @@ package ecs
 
 import (
 	"math"
+	"sort"
+
+	"go-engine/Go-Cordance/internal/assets"
 )
 
 type AnimationTrack struct {
 	NodeIndex int
 	Keyframes []TransformKeyframe
+	// Channels lists the channels the source keyed; zero means unknown.
+	Channels TrackChannels
+}
+
+// SelfNode is the node index of tracks that animate the player's own
+// entity rather than a node of its skeleton, as in clips recorded with
+// ClipRecorder for doors and platforms.
+const SelfNode = -1
+
+// Target returns the entity a track animates for a player on ent with the
+// given skeleton nodes, or nil if the node is missing.
+func (tr AnimationTrack) Target(ent *Entity, nodes []*Entity) *Entity {
+	if tr.NodeIndex == SelfNode {
+		return ent
+	}
+	if tr.NodeIndex < 0 || tr.NodeIndex >= len(nodes) {
+		return nil
+	}
+	return nodes[tr.NodeIndex]
+}
+
+// TrackChannels is a set of transform channels. Every keyframe carries all
+// three, so a track needs it to tell a key at the origin from a channel the
+// source never animated.
+type TrackChannels uint8
+
+const (
+	ChannelTranslation TrackChannels = 1 << iota
+	ChannelRotation
+	ChannelScale
+)
+
+// Animates reports whether the track keys channel c. Tracks without
+// Channels (clip files, recordings) animate the channels any key sets.
+func (tr AnimationTrack) Animates(c TrackChannels) bool {
+	if tr.Channels != 0 {
+		return tr.Channels&c != 0
+	}
+	for _, kf := range tr.Keyframes {
+		switch {
+		case c == ChannelTranslation && kf.Position != [3]float32{},
+			c == ChannelRotation && kf.Rotation != [4]float32{},
+			c == ChannelScale && kf.Scale != [3]float32{}:
+			return true
+		}
+	}
+	return false
 }
 
 type AnimationClip struct {
 	Name     string
 	Duration float32
 	Tracks   []AnimationTrack
+	Events   []AnimationEvent // sorted by Time; see SortEvents
+}
+
+// SortEvents orders the clip's events by time, keeping the order of events
+// sharing a time. Playback relies on it to fire events in order.
+func (c *AnimationClip) SortEvents() {
+	sort.SliceStable(c.Events, func(i, j int) bool { return c.Events[i].Time < c.Events[j].Time })
+}
+
+// AnimationEvent marks a named moment in a clip, e.g. a footstep or the
+// frame an attack lands. It fires when the playhead crosses Time.
+type AnimationEvent struct {
+	Time    float32
+	Name    string
+	Payload string
 }
 
 type TransformKeyframe struct {
@@ -29,48 +94,177 @@ type AnimationPlayer struct {
 	Speed        float32
 	Playing      bool
 	NodeEntities []*Entity
+
+	// FiredEvents holds the events crossed during the last update, in
+	// playback order. OnEvent, if set, is called for each of them.
+	FiredEvents []AnimationEvent
+	OnEvent     func(ent *Entity, ev AnimationEvent)
+
+	// RootMotion strips the root bone's horizontal (XZ) translation and
+	// yaw from the pose. The removed motion is exposed per frame in
+	// RootMotionDelta/RootMotionYaw (root parent space, Y up) and, with
+	// ApplyRootMotion, added to the owning entity's Transform.
+	RootMotion      bool
+	ApplyRootMotion bool
+	RootNode        int // node index of the root bone; if the clip has no track for it, the animated node highest in the hierarchy is used
+	RootMotionDelta [3]float32
+	RootMotionYaw   float32
+
+	// ClipAsset is the clip asset last assigned from the inspector.
+	// ClipSources maps clip names to the asset path they were loaded from,
+	// so scenes can store the assignment.
+	ClipAsset   assets.AssetID
+	ClipSources map[string]string
+
+	rootValid bool
+	rootPrev  [3]float32
+	yawPrev   float32
+
+	// played is set once the playhead has moved; until then an event on
+	// the starting time fires too, so an event at 0 is not skipped.
+	played bool
 }
 
-func (ap *AnimationPlayer) Update(dt float32) {
-	if !ap.Playing {
-		return
+// AddClipAsset loads an AssetAnimationClip into Clips and makes it the
+// current clip. It reports false if id is not a clip asset.
+func (ap *AnimationPlayer) AddClipAsset(id assets.AssetID) bool {
+	clip := ResolveClipAsset(id)
+	if clip == nil {
+		return false
+	}
+	if ap.Clips == nil {
+		ap.Clips = make(map[string]*AnimationClip)
 	}
+	if ap.ClipSources == nil {
+		ap.ClipSources = make(map[string]string)
+	}
+	ap.Clips[clip.Name] = clip
+	ap.ClipSources[clip.Name] = assets.Get(id).Path
+	ap.ClipAsset = id
+	ap.Current = clip.Name
+	ap.Time = 0
+	ap.restart()
+	return true
+}
 
-	clip := ap.Clips[ap.Current]
-	if clip == nil || len(clip.Tracks) == 0 {
-		return
+// ReloadClipSource re-resolves every clip that was loaded from path, keeping
+// the playhead if the current clip is among them. It reports whether any
+// clip was replaced.
+func (ap *AnimationPlayer) ReloadClipSource(path string) bool {
+	a := assets.FindAssetByPath(path)
+	if a == nil {
+		return false
 	}
+	replaced := false
+	for name, src := range ap.ClipSources {
+		if src != a.Path {
+			continue
+		}
+		clip := ResolveClipAsset(a.ID)
+		if clip == nil {
+			return replaced
+		}
+		delete(ap.Clips, name)
+		delete(ap.ClipSources, name)
+		ap.Clips[clip.Name] = clip
+		ap.ClipSources[clip.Name] = a.Path
+		if ap.Current == name {
+			ap.Current = clip.Name
+			if ap.Time > clip.Duration {
+				ap.Time = 0
+			}
+		}
+		replaced = true
+	}
+	return replaced
+}
 
-	ap.Time += dt * ap.Speed
-	// temporary debug
-	// log.Printf("Animation %s time=%.3f", ap.Current, ap.Time)
+// Update is a no-op; playback is driven by AnimationSystem.
+func (ap *AnimationPlayer) Update(dt float32) {
+	_ = dt
+}
 
-	if ap.Time > clip.Duration {
-		ap.Time = float32(math.Mod(float64(ap.Time), float64(clip.Duration)))
+// advance moves the playhead by dt*Speed, wrapping around the clip in
+// either direction, and records the events crossed on the way. It returns
+// the signed number of loop wraps.
+func (ap *AnimationPlayer) advance(clip *AnimationClip, dt float32) int {
+	ap.FiredEvents = ap.FiredEvents[:0]
+	if clip.Duration <= 0 {
+		ap.Time = 0
+		return 0
 	}
 
-	for _, track := range clip.Tracks {
-		kf := sampleTrack(track, ap.Time)
+	step := dt * ap.Speed
+	wraps := 0
+	// the first segment played and every segment after a wrap include
+	// their starting boundary, so events sitting exactly on the start, 0
+	// (forward) or Duration (reverse) still fire
+	inclusive := !ap.played
+	ap.played = ap.played || step != 0
 
-		// bounds check to avoid panics
-		if track.NodeIndex < 0 || track.NodeIndex >= len(ap.NodeEntities) {
-			continue
-		}
-		ent := ap.NodeEntities[track.NodeIndex]
-		if ent == nil {
-			continue
+	for step > 0 {
+		target := ap.Time + step
+		if target < clip.Duration {
+			ap.collectEvents(clip, ap.Time, target, inclusive)
+			ap.Time = target
+			break
 		}
-		if track.NodeIndex == 0 { // root bone
-			kf.Position[1] += 0.5 // bob up and down
+		ap.collectEvents(clip, ap.Time, clip.Duration, inclusive)
+		step = target - clip.Duration
+		ap.Time = 0
+		wraps++
+		inclusive = true
+	}
+	for step < 0 {
+		target := ap.Time + step
+		if target > 0 {
+			ap.collectEvents(clip, target, ap.Time, inclusive)
+			ap.Time = target
+			break
 		}
+		ap.collectEvents(clip, 0, ap.Time, inclusive)
+		step = target
+		ap.Time = clip.Duration
+		wraps--
+		inclusive = true
+	}
+	return wraps
+}
 
-		t := ent.GetTransform()
-		t.Position = kf.Position
-		t.Rotation = kf.Rotation
-		t.Scale = kf.Scale
+// collectEvents fires events in (from, to] when playing forward and
+// [from, to) when playing backwards; inclusive closes the open end.
+func (ap *AnimationPlayer) collectEvents(clip *AnimationClip, from, to float32, inclusive bool) {
+	if ap.Speed >= 0 {
+		for _, ev := range clip.Events {
+			if (ev.Time > from || (inclusive && ev.Time == from)) && ev.Time <= to {
+				ap.FiredEvents = append(ap.FiredEvents, ev)
+			}
+		}
+		return
+	}
+	for i := len(clip.Events) - 1; i >= 0; i-- {
+		ev := clip.Events[i]
+		if ev.Time >= from && (ev.Time < to || (inclusive && ev.Time == to)) {
+			ap.FiredEvents = append(ap.FiredEvents, ev)
+		}
 	}
 }
 
+// restart treats the playhead as freshly placed, after a clip change or a
+// scrub: an event on it fires and root motion starts over.
+func (ap *AnimationPlayer) restart() {
+	ap.played = false
+	ap.resetRootMotion()
+}
+
+// resetRootMotion forgets the previous root sample, e.g. after a clip
+// change or a scrub, so the next frame doesn't produce a jump.
+func (ap *AnimationPlayer) resetRootMotion() {
+	ap.rootValid = false
+	ap.RootMotionDelta = [3]float32{}
+	ap.RootMotionYaw = 0
+}
+
 func (ap *AnimationPlayer) EditorName() string {
 	return "AnimationPlayer"
 }
@@ -81,6 +275,10 @@ func (ap *AnimationPlayer) EditorFields() map[string]any {
 		"Speed":   ap.Speed,
 		"Playing": ap.Playing,
 		"Time":    ap.Time,
+
+		"RootMotion":      ap.RootMotion,
+		"ApplyRootMotion": ap.ApplyRootMotion,
+		"ClipAsset":       ap.ClipAsset,
 	}
 
 	// expose clip names for UI
@@ -99,6 +297,7 @@ func (ap *AnimationPlayer) SetEditorField(name string, value any) {
 		if s, ok := value.(string); ok {
 			ap.Current = s
 			ap.Time = 0
+			ap.restart()
 		}
 	case "Speed":
 		if f, ok := value.(float32); ok {
@@ -111,6 +310,22 @@ func (ap *AnimationPlayer) SetEditorField(name string, value any) {
 	case "Time":
 		if f, ok := value.(float32); ok {
 			ap.Time = f
+			ap.restart()
+		}
+	case "RootMotion":
+		if b, ok := value.(bool); ok {
+			ap.RootMotion = b
+			ap.resetRootMotion()
+		}
+	case "ApplyRootMotion":
+		if b, ok := value.(bool); ok {
+			ap.ApplyRootMotion = b
+		}
+	case "ClipAsset":
+		id := assets.AssetID(toInt(value))
+		if !ap.AddClipAsset(id) {
+			// editor-side mirrors have no asset registry; keep the id for the UI
+			ap.ClipAsset = id
 		}
 	}
 }
@@ -119,6 +334,9 @@ func sampleTrack(track AnimationTrack, t float32) TransformKeyframe {
 	if len(kfs) == 0 {
 		return TransformKeyframe{}
 	}
+	if t <= kfs[0].Time {
+		return kfs[0]
+	}
 
 	// find the two keyframes around t
 	for i := 0; i < len(kfs)-1; i++ {
@@ -0,0 +1,70 @@
+package ecs
+
+import (
+	"go-engine/Go-Cordance/internal/assets"
+)
+
+// ClipToFile converts a clip to its asset (on-disk) form.
+func ClipToFile(clip *AnimationClip) assets.ClipFile {
+	f := assets.ClipFile{
+		Name:     clip.Name,
+		Duration: clip.Duration,
+		Tracks:   make([]assets.ClipTrack, 0, len(clip.Tracks)),
+	}
+	for _, tr := range clip.Tracks {
+		keys := make([]assets.ClipKeyframe, len(tr.Keyframes))
+		for i, kf := range tr.Keyframes {
+			keys[i] = assets.ClipKeyframe(kf)
+		}
+		f.Tracks = append(f.Tracks, assets.ClipTrack{Node: tr.NodeIndex, Channels: uint8(tr.Channels), Keyframes: keys})
+	}
+	for _, ev := range clip.Events {
+		f.Events = append(f.Events, assets.ClipEvent(ev))
+	}
+	return f
+}
+
+// ClipFromFile builds a runtime clip from its asset form.
+func ClipFromFile(f assets.ClipFile) *AnimationClip {
+	clip := &AnimationClip{
+		Name:     f.Name,
+		Duration: f.Duration,
+		Tracks:   make([]AnimationTrack, 0, len(f.Tracks)),
+	}
+	for _, tr := range f.Tracks {
+		keys := make([]TransformKeyframe, len(tr.Keyframes))
+		for i, kf := range tr.Keyframes {
+			keys[i] = TransformKeyframe(kf)
+		}
+		clip.Tracks = append(clip.Tracks, AnimationTrack{NodeIndex: tr.Node, Channels: TrackChannels(tr.Channels), Keyframes: keys})
+	}
+	for _, ev := range f.Events {
+		clip.Events = append(clip.Events, AnimationEvent(ev))
+	}
+	clip.SortEvents()
+	return clip
+}
+
+// SaveAnimationClip writes a clip (e.g. a baked retarget); *.clip paths are
+// written as binary, anything else as JSON.
+func SaveAnimationClip(path string, clip *AnimationClip) error {
+	return assets.WriteClipFile(path, ClipToFile(clip))
+}
+
+// LoadAnimationClip reads a clip written by SaveAnimationClip.
+func LoadAnimationClip(path string) (*AnimationClip, error) {
+	f, err := assets.ReadClipFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ClipFromFile(f), nil
+}
+
+// ResolveClipAsset returns a fresh runtime clip for an AssetAnimationClip.
+func ResolveClipAsset(id assets.AssetID) *AnimationClip {
+	f, ok := assets.ResolveClip(id)
+	if !ok {
+		return nil
+	}
+	return ClipFromFile(f)
+}
@@ -26,30 +26,31 @@ func (sys *AnimationSystem) Update(dt float32, ents []*Entity) {
 		}
 
 		clip := player.Clips[player.Current]
-		if clip == nil || len(clip.Tracks) == 0 {
+		if clip == nil {
 			continue
 		}
 		// advance time
-		player.Time += dt * player.Speed
-		if player.Time > clip.Duration {
-			player.Time = 0
+		wraps := player.advance(clip, dt)
+		for _, ev := range player.FiredEvents {
+			if player.OnEvent != nil {
+				player.OnEvent(ent, ev)
+			}
 		}
 
-		// find skeleton
-		skc := ent.GetComponent((*Skeleton)(nil))
-		if skc == nil {
-			// fmt.Println("  BUT: no Skeleton on ent", ent.ID)
-			continue
+		// find skeleton; players set up by hand may only carry NodeEntities
+		nodes := player.NodeEntities
+		if skc := ent.GetComponent((*Skeleton)(nil)); skc != nil {
+			nodes = skc.(*Skeleton).Nodes
+		}
+
+		rootNode := -1
+		if player.RootMotion {
+			rootNode = player.rootTrackNode(clip, nodes)
 		}
-		skeleton := skc.(*Skeleton)
-		// fmt.Println("  Skeleton found on ent", ent.ID, "nodes:", len(skeleton.Nodes))
 
 		// apply each track to its node entity
 		for _, track := range clip.Tracks {
-			if track.NodeIndex < 0 || track.NodeIndex >= len(skeleton.Nodes) {
-				continue
-			}
-			nodeEnt := skeleton.Nodes[track.NodeIndex]
+			nodeEnt := track.Target(ent, nodes)
 			if nodeEnt == nil {
 				continue
 			}
@@ -73,6 +74,10 @@ func (sys *AnimationSystem) Update(dt float32, ents []*Entity) {
 				scl = [3]float32{1, 1, 1}
 			}
 
+			if rootNode >= 0 && track.NodeIndex == rootNode {
+				pos, rot = player.extractRootMotion(ent, track, clip, pos, rot, wraps)
+			}
+
 			// fmt.Printf("Track node=%d t=%.3f pos=%v rot=%v scl=%v\n",
 			// 	track.NodeIndex, player.Time, pos, rot, scl)
 
@@ -90,16 +95,125 @@ func (sys *AnimationSystem) Update(dt float32, ents []*Entity) {
 				}
 				transform.Dirty = true
 			}
-			if clip == nil || len(clip.Tracks) == 0 {
-				// fmt.Printf("Anim: ent %d has clip %q but no tracks or nil clip\n", ent.ID, player.Current)
-				continue
-			}
+		}
+	}
+}
 
-			// fmt.Printf("Anim: ent %d time=%.3f duration=%.3f tracks=%d\n",
-			// 	ent.ID, player.Time, clip.Duration, len(clip.Tracks))
+// rootTrackNode picks the node treated as the root bone for root motion:
+// RootNode if the clip animates it, otherwise the animated node highest in
+// the hierarchy (the lowest index among equals).
+func (player *AnimationPlayer) rootTrackNode(clip *AnimationClip, nodes []*Entity) int {
+	root, rootDepth := -1, 0
+	for _, track := range clip.Tracks {
+		if track.NodeIndex == SelfNode {
+			continue
+		}
+		if track.NodeIndex == player.RootNode {
+			return track.NodeIndex
+		}
+		if track.NodeIndex < 0 || track.NodeIndex >= len(nodes) || nodes[track.NodeIndex] == nil {
+			continue
+		}
+		d := hierarchyDepth(nodes[track.NodeIndex])
+		if root < 0 || d < rootDepth || (d == rootDepth && track.NodeIndex < root) {
+			root, rootDepth = track.NodeIndex, d
+		}
+	}
+	return root
+}
 
+// hierarchyDepth counts the Parent links above e.
+func hierarchyDepth(e *Entity) int {
+	d := 0
+	for {
+		p, ok := e.GetComponent((*Parent)(nil)).(*Parent)
+		if !ok || p.Entity == nil {
+			return d
 		}
+		e = p.Entity
+		d++
+	}
+}
+
+// extractRootMotion removes horizontal translation and yaw from the root
+// bone's sampled pose, pinning it to the clip's first frame, and records the
+// motion removed since the previous frame. Loop wraps add or subtract one
+// full cycle of motion so the character keeps walking instead of snapping
+// back.
+func (player *AnimationPlayer) extractRootMotion(ent *Entity, track AnimationTrack, clip *AnimationClip, pos [3]float32, rot [4]float32, wraps int) ([3]float32, [4]float32) {
+	start := sampleTrack(track, 0)
+	end := sampleTrack(track, clip.Duration)
+	hasRot := start.Rotation != [4]float32{}
+	startYaw := quatYaw(start.Rotation)
+	curYaw := float32(0)
+	if hasRot {
+		curYaw = quatYaw(rot)
+	}
+
+	if player.rootValid {
+		cycle := float32(wraps)
+		player.RootMotionDelta = [3]float32{
+			pos[0] - player.rootPrev[0] + cycle*(end.Position[0]-start.Position[0]),
+			0,
+			pos[2] - player.rootPrev[2] + cycle*(end.Position[2]-start.Position[2]),
+		}
+		player.RootMotionYaw = wrapAngle(curYaw-player.yawPrev) +
+			cycle*wrapAngle(quatYaw(end.Rotation)-startYaw)
+	} else {
+		player.RootMotionDelta = [3]float32{}
+		player.RootMotionYaw = 0
+	}
+	player.rootPrev = pos
+	player.yawPrev = curYaw
+	player.rootValid = true
+
+	pos[0], pos[2] = start.Position[0], start.Position[2]
+	if hasRot {
+		q := yawQuat(startYaw - curYaw).Mul(mgl32.Quat{W: rot[3], V: mgl32.Vec3{rot[0], rot[1], rot[2]}}).Normalize()
+		rot = [4]float32{q.V[0], q.V[1], q.V[2], q.W}
+	}
+
+	if player.ApplyRootMotion {
+		if tr := ent.GetTransform(); tr != nil {
+			q := mgl32.Quat{W: tr.Rotation[3], V: mgl32.Vec3{tr.Rotation[0], tr.Rotation[1], tr.Rotation[2]}}
+			d := mgl32.Vec3{
+				player.RootMotionDelta[0] * tr.Scale[0],
+				0,
+				player.RootMotionDelta[2] * tr.Scale[2],
+			}
+			d = q.Rotate(d)
+			tr.Position[0] += d[0]
+			tr.Position[1] += d[1]
+			tr.Position[2] += d[2]
+
+			q = q.Mul(yawQuat(player.RootMotionYaw)).Normalize()
+			tr.Rotation = [4]float32{q.V[0], q.V[1], q.V[2], q.W}
+			tr.Dirty = true
+		}
+	}
+	return pos, rot
+}
+
+// quatYaw returns the rotation about +Y contained in q (x, y, z, w).
+func quatYaw(q [4]float32) float32 {
+	if q == [4]float32{} {
+		return 0
+	}
+	return 2 * float32(math.Atan2(float64(q[1]), float64(q[3])))
+}
+
+func yawQuat(yaw float32) mgl32.Quat {
+	return mgl32.QuatRotate(yaw, mgl32.Vec3{0, 1, 0})
+}
+
+func wrapAngle(a float32) float32 {
+	for a > math.Pi {
+		a -= 2 * math.Pi
+	}
+	for a < -math.Pi {
+		a += 2 * math.Pi
 	}
+	return a
 }
 
 func findKeyframePairTrack(kfs []TransformKeyframe, time float32) (*TransformKeyframe, *TransformKeyframe) {
@@ -0,0 +1,229 @@
+package ecs
+
+import (
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func eventNames(evs []AnimationEvent) []string {
+	out := []string{}
+	for _, ev := range evs {
+		out = append(out, ev.Name)
+	}
+	return out
+}
+
+func TestAnimationPlayer_EventsAcrossLoopWrap(t *testing.T) {
+	clip := &AnimationClip{
+		Duration: 1,
+		Events: []AnimationEvent{
+			{Time: 0, Name: "start"},
+			{Time: 0.42, Name: "step"},
+			{Time: 0.9, Name: "land"},
+		},
+	}
+	ap := &AnimationPlayer{Speed: 1, Time: 0.8}
+
+	wraps := ap.advance(clip, 0.5)
+	if wraps != 1 {
+		t.Fatalf("expected 1 wrap, got %d", wraps)
+	}
+	if got, want := eventNames(ap.FiredEvents), []string{"land", "start"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v got %v", want, got)
+	}
+
+	ap.advance(clip, 0.2)
+	if got, want := eventNames(ap.FiredEvents), []string{"step"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v got %v", want, got)
+	}
+}
+
+func TestAnimationPlayer_EventsNegativeSpeed(t *testing.T) {
+	clip := &AnimationClip{
+		Duration: 1,
+		Events: []AnimationEvent{
+			{Time: 0.1, Name: "a"},
+			{Time: 0.5, Name: "b"},
+			{Time: 1, Name: "end"},
+		},
+	}
+	ap := &AnimationPlayer{Speed: -1, Time: 0.6}
+
+	wraps := ap.advance(clip, 0.7)
+	if wraps != -1 {
+		t.Fatalf("expected -1 wrap, got %d", wraps)
+	}
+	if got, want := eventNames(ap.FiredEvents), []string{"b", "a", "end"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v got %v", want, got)
+	}
+	if ap.Time < 0.89 || ap.Time > 0.91 {
+		t.Fatalf("expected time 0.9 got %f", ap.Time)
+	}
+}
+
+func TestClipRecorder_KeysSortedAndReplaced(t *testing.T) {
+	door := NewEntity(1)
+	door.AddComponent(NewTransform([3]float32{}))
+
+	rec := NewClipRecorder(door, "door_open")
+	rec.Key()
+
+	rec.Time = 2
+	rec.KeyPose([3]float32{0, 3, 0}, [4]float32{0, 0, 0, 1}, [3]float32{1, 1, 1})
+	rec.Time = 1
+	rec.KeyPose([3]float32{0, 1, 0}, [4]float32{0, 0, 0, 1}, [3]float32{1, 1, 1})
+	rec.KeyPose([3]float32{0, 2, 0}, [4]float32{0, 0, 0, 1}, [3]float32{1, 1, 1})
+
+	clip := rec.Clip()
+	if clip.Duration != 2 || len(clip.Tracks) != 1 || clip.Tracks[0].NodeIndex != SelfNode {
+		t.Fatalf("unexpected clip %+v", clip)
+	}
+	keys := clip.Tracks[0].Keyframes
+	if len(keys) != 3 || keys[1].Time != 1 || keys[1].Position[1] != 2 {
+		t.Fatalf("expected 3 sorted keys with t=1 replaced, got %+v", keys)
+	}
+
+	// a player without skeleton or NodeEntities animates its own entity
+	ap := &AnimationPlayer{Clips: map[string]*AnimationClip{clip.Name: clip}, Current: clip.Name, Speed: 1, Playing: true, RootNode: -1}
+	door.AddComponent(ap)
+	NewAnimationSystem().Update(0.5, []*Entity{door})
+	if y := door.GetTransform().Position[1]; y < 0.99 || y > 1.01 {
+		t.Fatalf("expected door halfway to first key (y=1), got %v", y)
+	}
+
+	// other clips need a skeleton or NodeEntities to animate anything
+	lid := NewEntity(2)
+	lid.AddComponent(NewTransform([3]float32{}))
+	skinned := &AnimationClip{Name: "open", Duration: 2, Tracks: []AnimationTrack{{NodeIndex: 0, Keyframes: keys}}}
+	lid.AddComponent(&AnimationPlayer{Clips: map[string]*AnimationClip{"open": skinned}, Current: "open", Speed: 1, Playing: true})
+	NewAnimationSystem().Update(0.5, []*Entity{lid})
+	if y := lid.GetTransform().Position[1]; y != 0 {
+		t.Fatalf("clip on node 0 moved a player without nodes to y=%v", y)
+	}
+}
+
+func TestAnimationClip_FileRoundTrip(t *testing.T) {
+	clip := &AnimationClip{
+		Name:     "platform",
+		Duration: 1.5,
+		Tracks: []AnimationTrack{{
+			NodeIndex: 3,
+			Channels:  ChannelTranslation | ChannelScale,
+			Keyframes: []TransformKeyframe{
+				{Time: 0, Position: [3]float32{1, 2, 3}, Rotation: [4]float32{0, 0, 0, 1}, Scale: [3]float32{1, 1, 1}},
+				{Time: 1.5, Position: [3]float32{4, 5, 6}, Rotation: [4]float32{0, 1, 0, 0}, Scale: [3]float32{2, 2, 2}},
+			},
+		}},
+		Events: []AnimationEvent{{Time: 0.75, Name: "clank", Payload: "metal"}},
+	}
+
+	for _, name := range []string{"platform.clip.json", "platform.clip"} {
+		path := filepath.Join(t.TempDir(), name)
+		if err := SaveAnimationClip(path, clip); err != nil {
+			t.Fatalf("%s: save: %v", name, err)
+		}
+		got, err := LoadAnimationClip(path)
+		if err != nil {
+			t.Fatalf("%s: load: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, clip) {
+			t.Fatalf("%s: round trip mismatch:\n got %+v\nwant %+v", name, got, clip)
+		}
+	}
+}
+
+func TestAnimationPlayer_EventAtStartFiresOnFirstPlay(t *testing.T) {
+	// Events-only clip, saved out of order.
+	path := filepath.Join(t.TempDir(), "cue.clip.json")
+	err := SaveAnimationClip(path, &AnimationClip{Name: "cue", Duration: 1, Events: []AnimationEvent{
+		{Time: 0.5, Name: "mid"},
+		{Time: 0, Name: "start"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clip, err := LoadAnimationClip(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := eventNames(clip.Events), []string{"start", "mid"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("loaded events %v, want %v", got, want)
+	}
+
+	var fired []string
+	ent := NewEntity(1)
+	ap := &AnimationPlayer{Clips: map[string]*AnimationClip{"cue": clip}, Current: "cue", Speed: 1, Playing: true,
+		OnEvent: func(_ *Entity, ev AnimationEvent) { fired = append(fired, ev.Name) }}
+	ent.AddComponent(ap)
+
+	sys := NewAnimationSystem()
+	sys.Update(0.25, []*Entity{ent})
+	sys.Update(0.5, []*Entity{ent})
+	if want := []string{"start", "mid"}; !reflect.DeepEqual(fired, want) {
+		t.Fatalf("fired %v, want %v", fired, want)
+	}
+
+	// Scrubbing back to the start arms the event again.
+	fired = nil
+	ap.SetEditorField("Time", float32(0))
+	sys.Update(0.1, []*Entity{ent})
+	sys.Update(0.1, []*Entity{ent})
+	if want := []string{"start"}; !reflect.DeepEqual(fired, want) {
+		t.Fatalf("after scrub fired %v, want %v", fired, want)
+	}
+}
+
+func TestAnimationSystem_RootMotion(t *testing.T) {
+	// Node 1 is the hips and parent of node 0, so it is the root bone even
+	// though node 0 has the lower index.
+	hips := NewEntity(2)
+	hips.AddComponent(NewTransform([3]float32{}))
+	leg := NewEntity(3)
+	leg.AddComponent(NewTransform([3]float32{}))
+	leg.AddComponent(NewParent(hips))
+
+	half := float32(math.Sqrt2 / 2)
+	clip := &AnimationClip{Name: "walk", Duration: 1, Tracks: []AnimationTrack{
+		{NodeIndex: 0, Keyframes: []TransformKeyframe{
+			{Time: 0, Rotation: [4]float32{0, 0, 0, 1}},
+			{Time: 1, Rotation: [4]float32{0, 0, 0, 1}},
+		}},
+		{NodeIndex: 1, Keyframes: []TransformKeyframe{
+			{Time: 0, Position: [3]float32{0, 1, 0}, Rotation: [4]float32{0, 0, 0, 1}},
+			{Time: 1, Position: [3]float32{2, 1, 0}, Rotation: [4]float32{0, half, 0, half}}, // 90° yaw
+		}},
+	}}
+	ent := NewEntity(1)
+	ap := &AnimationPlayer{Clips: map[string]*AnimationClip{"walk": clip}, Current: "walk", Speed: 1, Playing: true,
+		NodeEntities: []*Entity{leg, hips}, RootNode: -1, RootMotion: true}
+	ent.AddComponent(ap)
+	if got := ap.rootTrackNode(clip, ap.NodeEntities); got != 1 {
+		t.Fatalf("root track node %d, want the hips (1)", got)
+	}
+
+	near := func(a, b float32) bool { return math.Abs(float64(a-b)) < 1e-4 }
+	deg := func(d float32) float32 { return d * math.Pi / 180 }
+	sys := NewAnimationSystem()
+	steps := []struct {
+		dt, dx, yaw float32
+	}{
+		{0.5, 0, 0},            // first sample only primes the root
+		{0.25, 0.5, deg(22.5)}, // t 0.5 -> 0.75
+		{0.5, 1, deg(45)},      // t 0.75 -> 1.25 wraps: -1 this cycle, +2 for the loop
+		{0.5, 1, deg(45)},      // t 0.25 -> 0.75
+	}
+	for i, s := range steps {
+		sys.Update(s.dt, []*Entity{ent})
+		d := ap.RootMotionDelta
+		if !near(d[0], s.dx) || d[1] != 0 || !near(d[2], 0) || !near(ap.RootMotionYaw, s.yaw) {
+			t.Errorf("step %d: delta %v yaw %v, want x %v yaw %v", i, d, ap.RootMotionYaw, s.dx, s.yaw)
+		}
+		// The hips stay on the first frame horizontally and keep no yaw.
+		p, r := hips.GetTransform().Position, hips.GetTransform().Rotation
+		if !near(p[0], 0) || !near(p[1], 1) || !near(p[2], 0) || !near(quatYaw(r), 0) {
+			t.Errorf("step %d: hips at %v rotated %v", i, p, r)
+		}
+	}
+}
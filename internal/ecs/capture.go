@@ -0,0 +1,67 @@
+package ecs
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"go-engine/Go-Cordance/internal/engine"
+)
+
+// Capture renders entities as Update and Present would, but into an
+// offscreen width x height image instead of the window. The view comes
+// from cam, or from the active Camera among entities when cam is nil,
+// with the aspect ratio of the image. Eye adaptation starts afresh, so
+// the same scene always captures the same image.
+//
+// The camera system, screen size and framebuffer are restored before
+// returning; the HDR target follows the screen size again next frame.
+func (rs *RenderSystem) Capture(entities []*Entity, cam *Camera, width, height int) (*image.RGBA, error) {
+	if cam == nil {
+		cam = activeCamera(entities)
+	}
+	if cam == nil {
+		return nil, fmt.Errorf("capture: no active camera")
+	}
+	target, err := engine.NewCaptureTarget(width, height)
+	if err != nil {
+		return nil, err
+	}
+	defer target.Delete()
+
+	cs := rs.CameraSystem
+	savedCam := *cs
+	screenW, screenH := rs.Renderer.ScreenWidth, rs.Renderer.ScreenHeight
+	defer func() {
+		*cs = savedCam
+		rs.Renderer.ScreenWidth, rs.Renderer.ScreenHeight = screenW, screenH
+		rs.target = 0
+		engine.Device.BindFramebuffer(0)
+		engine.Device.Viewport(0, 0, int32(screenW), int32(screenH))
+	}()
+
+	cs.View = cam.ViewMatrix()
+	cs.Projection = mgl32.Perspective(mgl32.DegToRad(cam.Fov), float32(width)/float32(height), cam.Near, cam.Far)
+	cs.Position = cam.Position
+	rs.Renderer.ScreenWidth, rs.Renderer.ScreenHeight = width, height
+	rs.target = target.Framebuffer()
+	if rs.Post != nil {
+		rs.Post.ResetAdaptation()
+	}
+
+	target.Begin()
+	rs.Update(0, entities)
+	rs.Present()
+	return target.Image(), nil
+}
+
+// activeCamera is the first active Camera among entities, or nil.
+func activeCamera(entities []*Entity) *Camera {
+	for _, e := range entities {
+		if cam, ok := e.GetComponent((*Camera)(nil)).(*Camera); ok && cam.Active {
+			return cam
+		}
+	}
+	return nil
+}
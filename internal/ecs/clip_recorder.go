@@ -0,0 +1,60 @@
+package ecs
+
+import "sort"
+
+// ClipRecorder captures the local Transform of one entity into a clip, so
+// simple animations (doors, platforms) can be authored in the editor by
+// posing the entity with the gizmo. Keys are taken at Time; keying an
+// existing time replaces that key. The clip has a single track on
+// SelfNode, so it animates whichever entity plays it.
+type ClipRecorder struct {
+	Entity *Entity
+	Name   string
+	Time   float32
+
+	keys []TransformKeyframe
+}
+
+func NewClipRecorder(ent *Entity, name string) *ClipRecorder {
+	return &ClipRecorder{Entity: ent, Name: name}
+}
+
+// Key records the entity's current Transform at Time.
+func (r *ClipRecorder) Key() bool {
+	tr := r.Entity.GetTransform()
+	if tr == nil {
+		return false
+	}
+	r.KeyPose(tr.Position, tr.Rotation, tr.Scale)
+	return true
+}
+
+// KeyPose records an explicit pose at Time.
+func (r *ClipRecorder) KeyPose(pos [3]float32, rot [4]float32, scale [3]float32) {
+	kf := TransformKeyframe{Time: r.Time, Position: pos, Rotation: rot, Scale: scale}
+
+	i := sort.Search(len(r.keys), func(i int) bool { return r.keys[i].Time >= r.Time })
+	if i < len(r.keys) && r.keys[i].Time == r.Time {
+		r.keys[i] = kf
+		return
+	}
+	r.keys = append(r.keys, TransformKeyframe{})
+	copy(r.keys[i+1:], r.keys[i:])
+	r.keys[i] = kf
+}
+
+func (r *ClipRecorder) KeyCount() int { return len(r.keys) }
+
+// Clip builds the recorded clip. Duration is the time of the last key.
+func (r *ClipRecorder) Clip() *AnimationClip {
+	clip := &AnimationClip{Name: r.Name}
+	if len(r.keys) == 0 {
+		return clip
+	}
+	clip.Duration = r.keys[len(r.keys)-1].Time
+	clip.Tracks = []AnimationTrack{{
+		NodeIndex: SelfNode,
+		Keyframes: append([]TransformKeyframe(nil), r.keys...),
+	}}
+	return clip
+}
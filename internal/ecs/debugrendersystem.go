@@ -3,7 +3,6 @@ package ecs
 import (
 	"go-engine/Go-Cordance/internal/engine"
 
-	"github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
@@ -32,7 +31,7 @@ func (ds *DebugRenderSystem) Update(_ float32, entities []*Entity) {
 	if ds.CameraSystem == nil {
 		return
 	}
-	gl.UseProgram(ds.Renderer.Program)
+	engine.Device.UseProgram(ds.Renderer.Program)
 	view := ds.CameraSystem.View
 	proj := ds.CameraSystem.Projection
 
@@ -65,40 +64,40 @@ func (ds *DebugRenderSystem) Update(_ float32, entities []*Entity) {
 			model = model.Mul4(q.Mat4())
 		}
 
-		gl.UniformMatrix4fv(ds.Renderer.LocModel, 1, false, &model[0])
-		gl.UniformMatrix4fv(ds.Renderer.LocView, 1, false, &view[0])
-		gl.UniformMatrix4fv(ds.Renderer.LocProj, 1, false, &proj[0])
+		engine.Device.UniformMat4(ds.Renderer.LocModel, model[:])
+		engine.Device.UniformMat4(ds.Renderer.LocView, view[:])
+		engine.Device.UniformMat4(ds.Renderer.LocProj, proj[:])
 
 		if sphere != nil {
 			scale := mgl32.Scale3D(sphere.Radius, sphere.Radius, sphere.Radius)
 			model = model.Mul4(scale)
-			gl.UniformMatrix4fv(ds.Renderer.LocModel, 1, false, &model[0])
+			engine.Device.UniformMat4(ds.Renderer.LocModel, model[:])
 
 			col := [4]float32{1, 0, 0, 1}
-			gl.Uniform4fv(ds.Renderer.LocColor, 1, &col[0])
+			engine.Device.UniformVec4(ds.Renderer.LocColor, col[:])
 			vao := ds.MeshManager.GetVAO("wire_sphere")
-			gl.BindVertexArray(vao)
+			engine.Device.BindVertexArray(vao)
 			count := ds.MeshManager.GetCount("wire_sphere")
-			gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
-			gl.DrawElements(gl.LINES, count, gl.UNSIGNED_INT, gl.PtrOffset(0))
-			gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
-			gl.BindVertexArray(0)
+			engine.Device.SetPolygonMode(engine.PolygonLine)
+			engine.Device.DrawIndexed(engine.Lines, count, engine.IndexUint32)
+			engine.Device.SetPolygonMode(engine.PolygonFill)
+			engine.Device.BindVertexArray(0)
 		}
 
 		if box != nil {
 			scale := mgl32.Scale3D(box.HalfExtents[0]*2, box.HalfExtents[1]*2, box.HalfExtents[2]*2)
 			model = model.Mul4(scale)
-			gl.UniformMatrix4fv(ds.Renderer.LocModel, 1, false, &model[0])
+			engine.Device.UniformMat4(ds.Renderer.LocModel, model[:])
 
 			col := [4]float32{0, 1, 1, 1}
-			gl.Uniform4fv(ds.Renderer.LocColor, 1, &col[0])
+			engine.Device.UniformVec4(ds.Renderer.LocColor, col[:])
 			vao := ds.MeshManager.GetVAO("wire_cube")
-			gl.BindVertexArray(vao)
+			engine.Device.BindVertexArray(vao)
 			count := ds.MeshManager.GetCount("wire_cube")
-			gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
-			gl.DrawElements(gl.LINES, count, gl.UNSIGNED_INT, gl.PtrOffset(0))
-			gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
-			gl.BindVertexArray(0)
+			engine.Device.SetPolygonMode(engine.PolygonLine)
+			engine.Device.DrawIndexed(engine.Lines, count, engine.IndexUint32)
+			engine.Device.SetPolygonMode(engine.PolygonFill)
+			engine.Device.BindVertexArray(0)
 		}
 	}
 }
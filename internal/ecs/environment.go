@@ -0,0 +1,38 @@
+package ecs
+
+// Environment lights the whole scene with an equirectangular .hdr image:
+// the render system bakes it into irradiance, prefiltered specular and
+// BRDF lookup textures and binds them for every PBR material. Only the
+// first Environment in the scene is used.
+type Environment struct {
+	Path      string
+	Intensity float32
+	version   uint64
+}
+
+func NewEnvironment(path string) *Environment {
+	return &Environment{Path: path, Intensity: 1}
+}
+
+func (e *Environment) Update(dt float32) { _ = dt }
+
+func (e *Environment) EditorName() string { return "Environment" }
+
+func (e *Environment) EditorFields() map[string]any {
+	return map[string]any{
+		"Path":      e.Path,
+		"Intensity": e.Intensity,
+	}
+}
+
+func (e *Environment) SetEditorField(name string, value any) {
+	switch name {
+	case "Path":
+		e.Path, _ = value.(string)
+	case "Intensity":
+		e.Intensity = toFloat32(value)
+	}
+	e.version++
+}
+
+func (e *Environment) Version() uint64 { return e.version }
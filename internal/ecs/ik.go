@@ -0,0 +1,172 @@
+package ecs
+
+// IK components live on the end-effector bone entity (foot, hand, head),
+// so a skeleton can carry one chain per limb. Targets are other entities;
+// their world position is read from Transform.WorldMatrix.
+
+// TwoBoneIK bends a root/mid/end chain (thigh/calf/foot, upper arm/forearm/hand)
+// so End reaches Target. Pole, if set, decides which way the middle joint
+// points (knee forward, elbow back).
+type TwoBoneIK struct {
+	Root, Mid, End *Entity
+	Target         *Entity
+	Pole           *Entity
+	Weight         float32
+
+	// goal overrides Target for this frame (set by FootPlacement).
+	goal    [3]float32
+	hasGoal bool
+}
+
+func NewTwoBoneIK(root, mid, end, target *Entity) *TwoBoneIK {
+	return &TwoBoneIK{Root: root, Mid: mid, End: end, Target: target, Weight: 1}
+}
+
+func (ik *TwoBoneIK) Update(dt float32) { _ = dt }
+
+func (ik *TwoBoneIK) EditorName() string { return "TwoBoneIK" }
+
+func (ik *TwoBoneIK) EditorFields() map[string]any {
+	return map[string]any{
+		"Weight": ik.Weight,
+	}
+}
+
+func (ik *TwoBoneIK) SetEditorField(name string, value any) {
+	switch name {
+	case "Weight":
+		ik.Weight = clamp(toFloat32(value), 0, 1)
+	}
+}
+
+// FABRIKChain solves an arbitrary joint chain (tails, tentacles, spines)
+// with forward-and-backward reaching. Joints are ordered root first; the
+// last joint is the effector.
+type FABRIKChain struct {
+	Joints     []*Entity
+	Target     *Entity
+	Iterations int
+	Tolerance  float32
+	Weight     float32
+}
+
+func NewFABRIKChain(joints []*Entity, target *Entity) *FABRIKChain {
+	return &FABRIKChain{
+		Joints:     joints,
+		Target:     target,
+		Iterations: 10,
+		Tolerance:  0.001,
+		Weight:     1,
+	}
+}
+
+func (c *FABRIKChain) Update(dt float32) { _ = dt }
+
+func (c *FABRIKChain) EditorName() string { return "FABRIKChain" }
+
+func (c *FABRIKChain) EditorFields() map[string]any {
+	return map[string]any{
+		"Iterations": c.Iterations,
+		"Tolerance":  c.Tolerance,
+		"Weight":     c.Weight,
+	}
+}
+
+func (c *FABRIKChain) SetEditorField(name string, value any) {
+	switch name {
+	case "Iterations":
+		c.Iterations = toInt(value)
+	case "Tolerance":
+		c.Tolerance = toFloat32(value)
+	case "Weight":
+		c.Weight = clamp(toFloat32(value), 0, 1)
+	}
+}
+
+// LookAtIK turns a bone (head, eye) toward Target. Forward is the bone's
+// local axis that should point at the target; the rotation away from the
+// animated pose is limited to MaxAngle radians.
+type LookAtIK struct {
+	Bone     *Entity
+	Target   *Entity
+	Forward  [3]float32
+	MaxAngle float32
+	Weight   float32
+}
+
+func NewLookAtIK(bone, target *Entity) *LookAtIK {
+	return &LookAtIK{
+		Bone:     bone,
+		Target:   target,
+		Forward:  [3]float32{0, 0, 1},
+		MaxAngle: 1.2, // ~70 degrees
+		Weight:   1,
+	}
+}
+
+func (l *LookAtIK) Update(dt float32) { _ = dt }
+
+func (l *LookAtIK) EditorName() string { return "LookAtIK" }
+
+func (l *LookAtIK) EditorFields() map[string]any {
+	return map[string]any{
+		"Forward":  l.Forward,
+		"MaxAngle": l.MaxAngle,
+		"Weight":   l.Weight,
+	}
+}
+
+func (l *LookAtIK) SetEditorField(name string, value any) {
+	switch name {
+	case "Forward":
+		l.Forward = toVec3(value)
+	case "MaxAngle":
+		l.MaxAngle = toFloat32(value)
+	case "Weight":
+		l.Weight = clamp(toFloat32(value), 0, 1)
+	}
+}
+
+// FootPlacement drives the TwoBoneIK on the same entity so the foot rests
+// on ColliderPlane/ColliderAABB ground instead of sinking into it. A ray is
+// cast down from RayHeight above the animated foot; Offset is the ankle
+// height above the sole.
+type FootPlacement struct {
+	RayHeight float32
+	MaxDrop   float32
+	Offset    float32
+
+	Grounded bool
+	GroundY  float32
+}
+
+func NewFootPlacement() *FootPlacement {
+	return &FootPlacement{
+		RayHeight: 0.5,
+		MaxDrop:   0.5,
+		Offset:    0.08,
+	}
+}
+
+func (f *FootPlacement) Update(dt float32) { _ = dt }
+
+func (f *FootPlacement) EditorName() string { return "FootPlacement" }
+
+func (f *FootPlacement) EditorFields() map[string]any {
+	return map[string]any{
+		"RayHeight": f.RayHeight,
+		"MaxDrop":   f.MaxDrop,
+		"Offset":    f.Offset,
+	}
+}
+
+func (f *FootPlacement) SetEditorField(name string, value any) {
+	switch name {
+	case "RayHeight":
+		f.RayHeight = toFloat32(value)
+	case "MaxDrop":
+		f.MaxDrop = toFloat32(value)
+	case "Offset":
+		f.Offset = toFloat32(value)
+	}
+}
@@ -0,0 +1,442 @@
+package ecs
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// IKSystem adjusts animated skeletons procedurally. Register it after
+// AnimationSystem and TransformSystem (it reads world matrices) and before
+// SkinningSystem. Every bone it rotates gets its subtree's world matrices
+// refreshed immediately, so later solvers and skinning see the result.
+type IKSystem struct {
+	// poses remembers, per bone IK touches, the rotation it had before
+	// the solvers ran and the one they left, so a bone no clip animates
+	// starts each frame from its rest pose instead of last frame's result.
+	poses map[*Entity]*ikPose
+	frame uint64
+}
+
+type ikPose struct {
+	rest, solved [4]float32
+	frame        uint64
+}
+
+func NewIKSystem() *IKSystem {
+	return &IKSystem{poses: make(map[*Entity]*ikPose)}
+}
+
+func (sys *IKSystem) Update(dt float32, ents []*Entity) {
+	_ = dt
+	if sys.poses == nil {
+		sys.poses = make(map[*Entity]*ikPose)
+	}
+	sys.frame++
+
+	bones := ikBones(ents)
+	for _, b := range bones {
+		sys.restorePose(b)
+	}
+	for b, p := range sys.poses {
+		if p.frame != sys.frame {
+			delete(sys.poses, b)
+		}
+	}
+
+	// feet first: they only set goals for the TwoBoneIK on the same entity
+	for _, e := range ents {
+		fp, ok := e.GetComponent((*FootPlacement)(nil)).(*FootPlacement)
+		if !ok {
+			continue
+		}
+		ik, ok := e.GetComponent((*TwoBoneIK)(nil)).(*TwoBoneIK)
+		if !ok {
+			continue
+		}
+		solveFootPlacement(fp, ik, ents)
+	}
+
+	for _, e := range ents {
+		for _, c := range e.Components {
+			switch ik := c.(type) {
+			case *TwoBoneIK:
+				solveTwoBone(ik)
+			case *FABRIKChain:
+				solveFABRIK(ik)
+			case *LookAtIK:
+				solveLookAt(ik)
+			}
+		}
+	}
+
+	for _, b := range bones {
+		sys.poses[b].solved = b.GetTransform().Rotation
+	}
+}
+
+// ikBones lists the bones the IK components in ents may rotate.
+func ikBones(ents []*Entity) []*Entity {
+	var bones []*Entity
+	seen := make(map[*Entity]bool)
+	add := func(es ...*Entity) {
+		for _, e := range es {
+			if e != nil && !seen[e] && e.GetTransform() != nil {
+				seen[e] = true
+				bones = append(bones, e)
+			}
+		}
+	}
+	for _, e := range ents {
+		for _, c := range e.Components {
+			switch ik := c.(type) {
+			case *TwoBoneIK:
+				add(ik.Root, ik.Mid)
+			case *FABRIKChain:
+				if n := len(ik.Joints); n > 1 {
+					add(ik.Joints[:n-1]...)
+				}
+			case *LookAtIK:
+				add(ik.Bone)
+			}
+		}
+	}
+	return bones
+}
+
+// restorePose puts b back in the pose it had before last frame's solve
+// when nothing has written its rotation since (no clip animates it), and
+// otherwise takes the new rotation as the pose to solve and blend from.
+func (sys *IKSystem) restorePose(b *Entity) {
+	tr := b.GetTransform()
+	p, ok := sys.poses[b]
+	if !ok {
+		p = &ikPose{rest: tr.Rotation}
+		sys.poses[b] = p
+	} else if tr.Rotation == p.solved {
+		if tr.Rotation != p.rest {
+			tr.Rotation = p.rest
+			refreshWorld(b)
+		}
+	} else {
+		p.rest = tr.Rotation
+	}
+	p.frame = sys.frame
+}
+
+func solveFootPlacement(fp *FootPlacement, ik *TwoBoneIK, ents []*Entity) {
+	ik.hasGoal = false
+	fp.Grounded = false
+	if ik.End == nil || ik.End.GetTransform() == nil {
+		return
+	}
+
+	foot := worldPosition(ik.End)
+	origin := [3]float32{foot[0], foot[1] + fp.RayHeight, foot[2]}
+	// the character's own bones are skipped in case they carry colliders
+	hit, ok := RaycastColliders(ents, origin, [3]float32{0, -1, 0}, fp.RayHeight+fp.MaxDrop, ik.Root, ik.Mid, ik.End)
+	if !ok {
+		return
+	}
+
+	fp.Grounded = true
+	fp.GroundY = hit.Point[1]
+
+	// only push the foot up; a lifted foot mid-stride stays where the clip put it
+	minY := hit.Point[1] + fp.Offset
+	if foot[1] < minY {
+		ik.goal = [3]float32{foot[0], minY, foot[2]}
+		ik.hasGoal = true
+	}
+}
+
+func solveTwoBone(ik *TwoBoneIK) {
+	if ik.Root == nil || ik.Mid == nil || ik.End == nil || ik.Weight <= 0 {
+		return
+	}
+	var target [3]float32
+	switch {
+	case ik.hasGoal:
+		target = ik.goal
+	case ik.Target != nil && ik.Target.GetTransform() != nil:
+		target = worldPosition(ik.Target)
+	default:
+		return
+	}
+	rootTr, midTr := ik.Root.GetTransform(), ik.Mid.GetTransform()
+	if rootTr == nil || midTr == nil || ik.End.GetTransform() == nil {
+		return
+	}
+	rootRest, midRest := rootTr.Rotation, midTr.Rotation
+
+	var pole *mgl32.Vec3
+	if ik.Pole != nil && ik.Pole.GetTransform() != nil {
+		p := mgl32.Vec3(worldPosition(ik.Pole))
+		pole = &p
+	}
+
+	SolveTwoBone(ik.Root, ik.Mid, ik.End, target, pole)
+	blendSolved(ik.Root, rootRest, ik.Weight)
+	blendSolved(ik.Mid, midRest, ik.Weight)
+}
+
+// SolveTwoBone rotates root and mid so that end reaches target (or gets as
+// close as the bone lengths allow). pole, if non-nil, is a world-space point
+// the middle joint bends toward.
+func SolveTwoBone(root, mid, end *Entity, target [3]float32, pole *mgl32.Vec3) {
+	a := mgl32.Vec3(worldPosition(root))
+	b := mgl32.Vec3(worldPosition(mid))
+	c := mgl32.Vec3(worldPosition(end))
+	t := mgl32.Vec3(target)
+
+	lab := b.Sub(a).Len()
+	lcb := c.Sub(b).Len()
+	if lab < 1e-6 || lcb < 1e-6 {
+		return
+	}
+	const eps = 1e-4
+	lat := clamp(t.Sub(a).Len(), eps, lab+lcb-eps)
+
+	// 1) open/close the middle joint to get the right root->end distance
+	ba := a.Sub(b).Normalize()
+	bc := c.Sub(b).Normalize()
+	cur := acos32(ba.Dot(bc))
+	want := acos32((lab*lab + lcb*lcb - lat*lat) / (2 * lab * lcb))
+
+	axis := ba.Cross(bc)
+	if axis.Len() < 1e-6 {
+		// straight limb: bend in the plane that contains the pole (or any plane)
+		hint := mgl32.Vec3{0, 0, 1}
+		if pole != nil {
+			hint = pole.Sub(a)
+		}
+		axis = c.Sub(a).Cross(hint)
+		if axis.Len() < 1e-6 {
+			axis = c.Sub(a).Cross(mgl32.Vec3{1, 0, 0})
+		}
+	}
+	rotateBoneWorld(mid, mgl32.QuatRotate(want-cur, axis.Normalize()))
+
+	// 2) swing the root so the end points at the target
+	c = mgl32.Vec3(worldPosition(end))
+	rotateBoneWorld(root, quatBetween(c.Sub(a), t.Sub(a)))
+
+	// 3) twist around root->target so the middle joint faces the pole
+	if pole != nil {
+		dir := t.Sub(a)
+		if dir.Len() > 1e-6 {
+			dir = dir.Normalize()
+			b = mgl32.Vec3(worldPosition(mid))
+			from := projectOnPlane(b.Sub(a), dir)
+			to := projectOnPlane(pole.Sub(a), dir)
+			if from.Len() > 1e-6 && to.Len() > 1e-6 {
+				rotateBoneWorld(root, quatBetween(from, to))
+			}
+		}
+	}
+}
+
+func solveFABRIK(chain *FABRIKChain) {
+	n := len(chain.Joints)
+	if n < 2 || chain.Target == nil || chain.Target.GetTransform() == nil || chain.Weight <= 0 {
+		return
+	}
+	rest := make([][4]float32, n)
+	for i, j := range chain.Joints {
+		tr := j.GetTransform()
+		if tr == nil {
+			return
+		}
+		rest[i] = tr.Rotation
+	}
+
+	SolveFABRIK(chain.Joints, worldPosition(chain.Target), chain.Iterations, chain.Tolerance)
+
+	for i := 0; i < n-1; i++ {
+		blendSolved(chain.Joints[i], rest[i], chain.Weight)
+	}
+}
+
+// SolveFABRIK moves the chain's end toward target using forward-and-backward
+// reaching, then turns the solved positions back into bone rotations.
+func SolveFABRIK(joints []*Entity, target [3]float32, iterations int, tolerance float32) {
+	n := len(joints)
+	pos := make([]mgl32.Vec3, n)
+	for i, j := range joints {
+		pos[i] = mgl32.Vec3(worldPosition(j))
+	}
+	lengths := make([]float32, n-1)
+	total := float32(0)
+	for i := 0; i < n-1; i++ {
+		lengths[i] = pos[i+1].Sub(pos[i]).Len()
+		total += lengths[i]
+	}
+
+	t := mgl32.Vec3(target)
+	base := pos[0]
+
+	if t.Sub(base).Len() >= total {
+		// out of reach: stretch straight toward the target
+		dir := t.Sub(base).Normalize()
+		for i := 1; i < n; i++ {
+			pos[i] = pos[i-1].Add(dir.Mul(lengths[i-1]))
+		}
+	} else {
+		if iterations <= 0 {
+			iterations = 10
+		}
+		for iter := 0; iter < iterations; iter++ {
+			if pos[n-1].Sub(t).Len() <= tolerance {
+				break
+			}
+			// backward: pin the end to the target
+			pos[n-1] = t
+			for i := n - 2; i >= 0; i-- {
+				dir := pos[i].Sub(pos[i+1])
+				if dir.Len() < 1e-6 {
+					continue
+				}
+				pos[i] = pos[i+1].Add(dir.Normalize().Mul(lengths[i]))
+			}
+			// forward: pin the root back to its base
+			pos[0] = base
+			for i := 0; i < n-1; i++ {
+				dir := pos[i+1].Sub(pos[i])
+				if dir.Len() < 1e-6 {
+					continue
+				}
+				pos[i+1] = pos[i].Add(dir.Normalize().Mul(lengths[i]))
+			}
+		}
+	}
+
+	// positions -> rotations, root first so each bone sees its parent's result
+	for i := 0; i < n-1; i++ {
+		from := mgl32.Vec3(worldPosition(joints[i+1])).Sub(mgl32.Vec3(worldPosition(joints[i])))
+		to := pos[i+1].Sub(pos[i])
+		if from.Len() < 1e-6 || to.Len() < 1e-6 {
+			continue
+		}
+		rotateBoneWorld(joints[i], quatBetween(from, to))
+	}
+}
+
+func solveLookAt(l *LookAtIK) {
+	if l.Bone == nil || l.Target == nil || l.Target.GetTransform() == nil || l.Weight <= 0 {
+		return
+	}
+	tr := l.Bone.GetTransform()
+	if tr == nil {
+		return
+	}
+	rest := tr.Rotation
+	SolveLookAt(l.Bone, worldPosition(l.Target), l.Forward, l.MaxAngle)
+	blendSolved(l.Bone, rest, l.Weight)
+}
+
+// SolveLookAt turns bone so its local forward axis points at target, by no
+// more than maxAngle radians away from the current pose (maxAngle <= 0 means
+// unlimited).
+func SolveLookAt(bone *Entity, target [3]float32, forward [3]float32, maxAngle float32) {
+	tr := bone.GetTransform()
+	fwd := mgl32.Vec3(forward)
+	if fwd.Len() < 1e-6 {
+		return
+	}
+	worldRot := worldRotation(tr.WorldMatrix)
+	cur := worldRot.Rotate(fwd.Normalize())
+	want := mgl32.Vec3(target).Sub(mgl32.Vec3(worldPosition(bone)))
+	if want.Len() < 1e-6 {
+		return
+	}
+	want = want.Normalize()
+
+	angle := acos32(cur.Dot(want))
+	axis := cur.Cross(want)
+	if axis.Len() < 1e-6 {
+		return
+	}
+	if maxAngle > 0 && angle > maxAngle {
+		angle = maxAngle
+	}
+	rotateBoneWorld(bone, mgl32.QuatRotate(angle, axis.Normalize()))
+}
+
+// rotateBoneWorld applies a world-space rotation to a bone and refreshes the
+// world matrices of its subtree.
+func rotateBoneWorld(e *Entity, q mgl32.Quat) {
+	tr := e.GetTransform()
+	if tr == nil {
+		return
+	}
+	parentRot := mgl32.QuatIdent()
+	if p := parentWorldMatrix(e); p != nil {
+		parentRot = worldRotation(*p)
+	}
+	local := mgl32.Quat{W: tr.Rotation[3], V: mgl32.Vec3{tr.Rotation[0], tr.Rotation[1], tr.Rotation[2]}}
+	local = parentRot.Inverse().Mul(q).Mul(parentRot).Mul(local).Normalize()
+	tr.Rotation = [4]float32{local.V[0], local.V[1], local.V[2], local.W}
+	refreshWorld(e)
+}
+
+// blendSolved slerps a bone from its pre-IK rotation (this frame's clip
+// pose, or the rest pose IKSystem restored) toward the solved one.
+func blendSolved(e *Entity, rest [4]float32, weight float32) {
+	if weight >= 1 {
+		return
+	}
+	tr := e.GetTransform()
+	tr.Rotation = slerpQuat(rest, tr.Rotation, weight)
+	refreshWorld(e)
+}
+
+// refreshWorld recomputes local/world matrices for e and everything below it.
+func refreshWorld(e *Entity) {
+	parent := IdentityMatrix()
+	if p := parentWorldMatrix(e); p != nil {
+		parent = *p
+	}
+	(&TransformSystem{}).updateRecursive(e, &parent)
+}
+
+func parentWorldMatrix(e *Entity) *[16]float32 {
+	pc, ok := e.GetComponent((*Parent)(nil)).(*Parent)
+	if !ok || pc.Entity == nil {
+		return nil
+	}
+	ptr := pc.Entity.GetTransform()
+	if ptr == nil {
+		return nil
+	}
+	return &ptr.WorldMatrix
+}
+
+func worldPosition(e *Entity) [3]float32 {
+	m := e.GetTransform().WorldMatrix
+	return [3]float32{m[12], m[13], m[14]}
+}
+
+// worldRotation extracts the rotation from a column-major TRS matrix.
+func worldRotation(m [16]float32) mgl32.Quat {
+	x := mgl32.Vec3{m[0], m[1], m[2]}
+	y := mgl32.Vec3{m[4], m[5], m[6]}
+	z := mgl32.Vec3{m[8], m[9], m[10]}
+	if x.Len() < 1e-8 || y.Len() < 1e-8 || z.Len() < 1e-8 {
+		return mgl32.QuatIdent()
+	}
+	x, y, z = x.Normalize(), y.Normalize(), z.Normalize()
+	rot := mgl32.Mat3{x[0], x[1], x[2], y[0], y[1], y[2], z[0], z[1], z[2]}
+	return mgl32.Mat4ToQuat(rot.Mat4()).Normalize()
+}
+
+func quatBetween(from, to mgl32.Vec3) mgl32.Quat {
+	return mgl32.QuatBetweenVectors(from.Normalize(), to.Normalize())
+}
+
+func projectOnPlane(v, n mgl32.Vec3) mgl32.Vec3 {
+	return v.Sub(n.Mul(v.Dot(n)))
+}
+
+func acos32(x float32) float32 {
+	return float32(math.Acos(float64(clamp(x, -1, 1))))
+}
@@ -0,0 +1,142 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// buildChain creates a straight chain of entities hanging down -Y, each
+// bone 1 unit long, with world matrices already computed.
+func buildChain(n int) []*Entity {
+	joints := make([]*Entity, n)
+	for i := range joints {
+		e := NewEntity(int64(i + 1))
+		pos := [3]float32{0, -1, 0}
+		if i == 0 {
+			pos = [3]float32{}
+		}
+		e.AddComponent(NewTransform(pos))
+		e.AddComponent(NewChildren())
+		if i > 0 {
+			e.AddComponent(NewParent(joints[i-1]))
+			joints[i-1].GetComponent((*Children)(nil)).(*Children).AddChild(e)
+		}
+		joints[i] = e
+	}
+	refreshWorld(joints[0])
+	return joints
+}
+
+func assertNear(t *testing.T, got, want [3]float32, tol float32) {
+	t.Helper()
+	if mgl32.Vec3(got).Sub(mgl32.Vec3(want)).Len() > tol {
+		t.Fatalf("expected %v got %v", want, got)
+	}
+}
+
+func TestSolveTwoBone_ReachesTargetAndFollowsPole(t *testing.T) {
+	j := buildChain(3)
+	target := [3]float32{0.5, -1.2, 0.3}
+	pole := mgl32.Vec3{0, -1, 5}
+
+	SolveTwoBone(j[0], j[1], j[2], target, &pole)
+
+	assertNear(t, worldPosition(j[2]), target, 1e-3)
+	if knee := worldPosition(j[1]); knee[2] <= 0 {
+		t.Fatalf("expected knee to bend toward the pole (+Z), got %v", knee)
+	}
+}
+
+func TestSolveFABRIK_ReachesTarget(t *testing.T) {
+	j := buildChain(4)
+	target := [3]float32{1.5, -1.5, 0.5}
+
+	SolveFABRIK(j, target, 20, 1e-4)
+
+	assertNear(t, worldPosition(j[3]), target, 1e-2)
+}
+
+func TestSolveLookAt_RespectsAngleLimit(t *testing.T) {
+	j := buildChain(1)
+
+	// target straight to the side; forward is +Z so the full turn is 90 degrees
+	SolveLookAt(j[0], [3]float32{10, 0, 0}, [3]float32{0, 0, 1}, 0.5)
+
+	fwd := worldRotation(j[0].GetTransform().WorldMatrix).Rotate(mgl32.Vec3{0, 0, 1})
+	if angle := acos32(fwd.Dot(mgl32.Vec3{0, 0, 1})); angle < 0.49 || angle > 0.51 {
+		t.Fatalf("expected 0.5 rad turn, got %f", angle)
+	}
+}
+
+func TestIKSystem_FootPlacementOverFrames(t *testing.T) {
+	// A leg hanging from the origin, its foot at y=-2, over ground at
+	// y=-1.8; nothing animates the leg, so only IK moves it.
+	j := buildChain(3)
+	ground := NewEntity(10)
+	plane := NewColliderPlane(-1.8)
+	ground.AddComponent(plane)
+
+	ik := NewTwoBoneIK(j[0], j[1], j[2], nil)
+	ik.Weight = 0.5
+	fp := NewFootPlacement()
+	j[2].AddComponent(ik)
+	j[2].AddComponent(fp)
+
+	ents := append(j, ground)
+	sys := NewIKSystem()
+
+	sys.Update(0, ents)
+	if !fp.Grounded {
+		t.Fatal("foot did not find the ground")
+	}
+	first := worldPosition(j[2])
+	if first[1] <= -2 || first[1] >= -1.8+fp.Offset {
+		t.Fatalf("half-weight foot at y=%v, want between the clip pose and the ground", first[1])
+	}
+
+	// A partial weight must not compound across frames.
+	for i := 0; i < 10; i++ {
+		sys.Update(0, ents)
+	}
+	assertNear(t, worldPosition(j[2]), first, 1e-4)
+
+	ik.Weight = 1
+	sys.Update(0, ents)
+	if y := worldPosition(j[2])[1]; y < -1.8+fp.Offset-1e-3 {
+		t.Fatalf("full-weight foot at y=%v, want it on the ground", y)
+	}
+
+	// Once the ground drops away the leg returns to its rest pose.
+	plane.Y = -3
+	sys.Update(0, ents)
+	if fp.Grounded {
+		t.Fatal("foot still grounded over a distant floor")
+	}
+	assertNear(t, worldPosition(j[2]), [3]float32{0, -2, 0}, 1e-4)
+}
+
+func TestRaycastColliders_PlaneAndBox(t *testing.T) {
+	ground := NewEntity(1)
+	ground.AddComponent(NewColliderPlane(0))
+
+	step := NewEntity(2)
+	step.AddComponent(NewTransform([3]float32{2, 0.25, 0}))
+	step.AddComponent(NewColliderAABB([3]float32{0.5, 0.25, 0.5}))
+
+	ents := []*Entity{ground, step}
+	down := [3]float32{0, -1, 0}
+
+	hit, ok := RaycastColliders(ents, [3]float32{0, 1, 0}, down, 5)
+	if !ok || hit.Entity != ground {
+		t.Fatalf("expected plane hit, got %+v ok=%v", hit, ok)
+	}
+	assertNear(t, hit.Point, [3]float32{0, 0, 0}, 1e-5)
+
+	hit, ok = RaycastColliders(ents, [3]float32{2, 1, 0}, down, 5)
+	if !ok || hit.Entity != step {
+		t.Fatalf("expected box hit, got %+v ok=%v", hit, ok)
+	}
+	assertNear(t, hit.Point, [3]float32{2, 0.5, 0}, 1e-5)
+	assertNear(t, hit.Normal, [3]float32{0, 1, 0}, 1e-5)
+}
@@ -4,7 +4,6 @@ import (
 	"go-engine/Go-Cordance/internal/engine"
 	"math"
 
-	"github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
@@ -50,7 +49,7 @@ func (lds *LightDebugRenderSystem) Update(_ float32, _ []*Entity) {
 		return
 	}
 
-	gl.UseProgram(lds.Renderer.Program)
+	engine.Device.UseProgram(lds.Renderer.Program)
 	view := lds.CameraSystem.View
 	proj := lds.CameraSystem.Projection
 
@@ -112,7 +111,7 @@ func (lds *LightDebugRenderSystem) Update(_ float32, _ []*Entity) {
 			}
 
 			// Make sure we render as lines and set a visible width
-			gl.LineWidth(2)
+			engine.Device.SetLineWidth(2)
 		} else {
 			// Optional: apply regular scale if you use t.Scale elsewhere
 			// Apply scale (default to 1 if zero)
@@ -130,25 +129,25 @@ func (lds *LightDebugRenderSystem) Update(_ float32, _ []*Entity) {
 
 		}
 
-		gl.UniformMatrix4fv(lds.Renderer.LocModel, 1, false, &model[0])
-		gl.UniformMatrix4fv(lds.Renderer.LocView, 1, false, &view[0])
-		gl.UniformMatrix4fv(lds.Renderer.LocProj, 1, false, &proj[0])
+		engine.Device.UniformMat4(lds.Renderer.LocModel, model[:])
+		engine.Device.UniformMat4(lds.Renderer.LocView, view[:])
+		engine.Device.UniformMat4(lds.Renderer.LocProj, proj[:])
 
 		col := [4]float32{1, 1, 1, 1}
 		if c, ok := lds.Colors[e]; ok {
 			col = c
 		}
-		gl.Uniform4fv(lds.Renderer.LocColor, 1, &col[0])
+		engine.Device.UniformVec4(lds.Renderer.LocColor, col[:])
 
 		vao := lds.MeshManager.GetVAO(mesh.ID)
-		gl.BindVertexArray(vao)
+		engine.Device.BindVertexArray(vao)
 		count := lds.MeshManager.GetCount(mesh.ID)
 
 		if mesh.ID == "line" {
-			gl.DrawElements(gl.LINES, count, gl.UNSIGNED_INT, gl.PtrOffset(0))
+			engine.Device.DrawIndexed(engine.Lines, count, engine.IndexUint32)
 		} else {
-			gl.DrawElements(gl.TRIANGLES, count, gl.UNSIGNED_INT, gl.PtrOffset(0))
+			engine.Device.DrawIndexed(engine.Triangles, count, engine.IndexUint32)
 		}
-		gl.BindVertexArray(0)
+		engine.Device.BindVertexArray(0)
 	}
 }
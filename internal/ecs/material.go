@@ -27,7 +27,11 @@ type Material struct {
 	TextureAsset assets.AssetID // future: replace TextureID
 	NormalAsset  assets.AssetID // future: replace NormalID
 	ShaderName   string
-	Shader       *engine.ShaderProgram
+
+	// MaterialAsset is the .mat asset last applied from the editor; editing
+	// that file updates this component in place (see ApplyMaterialFile).
+	MaterialAsset assets.AssetID
+	Shader        *engine.ShaderProgram
 
 	DiffuseTexturePath           string
 	NormalTexturePath            string
@@ -61,6 +65,12 @@ type Material struct {
 	UseTransmission        bool
 	TransmissionTex        uint32 // optional
 
+	// Transparency and culling, as glTF's alphaMode, alphaCutoff and
+	// doubleSided. AlphaMode picks the render queue pass.
+	AlphaMode   engine.AlphaMode
+	AlphaCutoff float32
+	DoubleSided bool
+
 	// (optional) OcclusionID/MetallicRoughnessID can be zero if not present
 
 	Dirty bool
@@ -73,6 +83,8 @@ func NewMaterial(color [4]float32) *Material {
 		Diffuse:   0.8,
 		Specular:  0.5,
 		Shininess: 32.0,
+
+		AlphaCutoff: engine.DefaultAlphaCutoff,
 	}
 }
 
@@ -99,7 +111,8 @@ func (m *Material) EditorFields() map[string]any {
 		"TextureAsset": m.TextureAsset,
 		"NormalAsset":  m.NormalAsset,
 
-		"ShaderName": m.ShaderName,
+		"ShaderName":    m.ShaderName,
+		"MaterialAsset": m.MaterialAsset,
 
 		// New fields for editor (read-only or editable later)
 		"DiffuseTexturePath":           m.DiffuseTexturePath,
@@ -130,6 +143,10 @@ func (m *Material) EditorFields() map[string]any {
 		"UseClearcoat":       m.UseClearcoat,
 		"TransmissionFactor": m.TransmissionFactor,
 		"UseTransmission":    m.UseTransmission,
+
+		"AlphaMode":   int(m.AlphaMode),
+		"AlphaCutoff": m.AlphaCutoff,
+		"DoubleSided": m.DoubleSided,
 	}
 }
 
@@ -164,6 +181,8 @@ func (m *Material) SetEditorField(name string, value any) {
 		m.TextureAsset = assets.AssetID(toInt(value))
 	case "NormalAsset":
 		m.NormalAsset = assets.AssetID(toInt(value))
+	case "MaterialAsset":
+		m.MaterialAsset = assets.AssetID(toInt(value))
 	case "ShaderName":
 		m.ShaderName = value.(string)
 		if m.ShaderName != "" {
@@ -241,7 +260,12 @@ func (m *Material) SetEditorField(name string, value any) {
 		m.TransmissionFactor = toFloat32(value)
 	case "UseTransmission":
 		m.UseTransmission = toBool(value)
-
+	case "AlphaMode":
+		m.AlphaMode = engine.AlphaMode(toInt(value))
+	case "AlphaCutoff":
+		m.AlphaCutoff = toFloat32(value)
+	case "DoubleSided":
+		m.DoubleSided = toBool(value)
 	}
 
 	m.Dirty = true
@@ -266,3 +290,93 @@ func resolveMaterialShader(mat *Material) {
 	}
 	mat.Shader = sp
 }
+
+// ApplyMaterialFile copies the parameters, textures and shader of a .mat
+// asset onto m and records it as m.MaterialAsset. Textures that are not
+// registered yet are imported. It reports false if id is not a material.
+func (m *Material) ApplyMaterialFile(id assets.AssetID) bool {
+	a := assets.Get(id)
+	if a == nil {
+		return false
+	}
+	mf, ok := a.Data.(assets.MaterialFile)
+	if !ok {
+		return false
+	}
+	m.MaterialAsset = id
+
+	p := mf.Params
+	if v, ok := p["baseColor"]; ok {
+		m.BaseColor = toVec4(v)
+	}
+	if v, ok := p["metallic"]; ok {
+		m.Metallic = toFloat32(v)
+	}
+	if v, ok := p["roughness"]; ok {
+		m.Roughness = toFloat32(v)
+	}
+	if v, ok := p["normalScale"]; ok {
+		m.NormalScale = toFloat32(v)
+	}
+	if v, ok := p["sheenColor"]; ok {
+		m.SheenColor = toVec3(v)
+	}
+	if v, ok := p["sheenRoughness"]; ok {
+		m.SheenRoughness = toFloat32(v)
+	}
+	if v, ok := p["clearcoatFactor"]; ok {
+		m.ClearcoatFactor = toFloat32(v)
+		m.UseClearcoat = m.ClearcoatFactor > 0
+	}
+	if v, ok := p["clearcoatRoughness"]; ok {
+		m.ClearcoatRoughness = toFloat32(v)
+	}
+	if v, ok := p["transmissionFactor"]; ok {
+		m.TransmissionFactor = toFloat32(v)
+		m.UseTransmission = m.TransmissionFactor > 0
+	}
+	if v, ok := p["useIBL"]; ok {
+		m.UseIBL = toBool(v)
+	}
+	if v, ok := p["alphaMode"].(string); ok {
+		m.AlphaMode, _ = engine.ParseAlphaMode(v)
+	}
+	if v, ok := p["alphaCutoff"]; ok {
+		m.AlphaCutoff = toFloat32(v)
+	}
+	if v, ok := p["doubleSided"]; ok {
+		m.DoubleSided = toBool(v)
+	}
+
+	texture := func(slot string) (assets.AssetID, uint32, string) {
+		path := mf.Textures[slot]
+		if path == "" {
+			return 0, 0, ""
+		}
+		if t := assets.FindAssetByPath(path); t != nil {
+			return t.ID, assets.ResolveTextureGLID(t.ID), path
+		}
+		tid, glID, err := assets.ImportTexture(path)
+		if err != nil {
+			return 0, 0, path
+		}
+		return tid, glID, path
+	}
+	m.TextureAsset, m.TextureID, m.DiffuseTexturePath = texture("albedo")
+	m.UseTexture = m.TextureAsset != 0
+	m.NormalAsset, m.NormalID, m.NormalTexturePath = texture("normal")
+	m.UseNormal = m.NormalAsset != 0
+	m.OcclusionAsset, m.OcclusionID, m.OcclusionTexturePath = texture("occlusion")
+	m.MetallicRoughnessAsset, m.MetallicRoughnessID, m.MetallicRoughnessTexturePath = texture("metallicRoughness")
+	_, m.ClearcoatTexture, _ = texture("clearcoat")
+	_, m.TransmissionTex, _ = texture("transmission")
+
+	if mf.Shader != "" {
+		if sp, err := engine.GetShaderProgram(mf.Shader); err == nil {
+			m.ShaderName = mf.Shader
+			m.Shader = sp
+		}
+	}
+	m.Dirty = true
+	return true
+}
@@ -0,0 +1,142 @@
+package ecs
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"go-engine/Go-Cordance/internal/engine"
+)
+
+// MeshInstancer draws many copies of one mesh with the entity's Material
+// in a single instanced draw, without an entity per copy (grass, rocks).
+// Instances are local to the entity's Transform; their Color tints the
+// material's base colour.
+//
+// Scatter fills Instances from the scatter settings, which are what scenes
+// save. Code that places instances itself can set Instances directly and
+// leave Count at 0.
+type MeshInstancer struct {
+	MeshID    string
+	Instances []engine.Instance
+
+	// Scatter settings: Count copies spread uniformly through the box of
+	// half size Extent around the entity, each scaled uniformly between
+	// MinScale and MaxScale and, with RandomYaw, turned about Y.
+	Count     int
+	Extent    [3]float32
+	MinScale  float32
+	MaxScale  float32
+	RandomYaw bool
+	Seed      int
+
+	version uint64
+}
+
+func NewMeshInstancer(meshID string) *MeshInstancer {
+	return &MeshInstancer{
+		MeshID:    meshID,
+		Extent:    [3]float32{10, 0, 10},
+		MinScale:  1,
+		MaxScale:  1,
+		RandomYaw: true,
+	}
+}
+
+// Scatter regenerates Instances from the scatter settings. The same
+// settings always give the same instances.
+func (mi *MeshInstancer) Scatter() {
+	rng := rand.New(rand.NewSource(int64(mi.Seed)))
+	between := func(lo, hi float32) float32 { return lo + (hi-lo)*rng.Float32() }
+
+	mi.Instances = mi.Instances[:0]
+	for i := 0; i < mi.Count; i++ {
+		pos := mgl32.Vec3{
+			between(-mi.Extent[0], mi.Extent[0]),
+			between(-mi.Extent[1], mi.Extent[1]),
+			between(-mi.Extent[2], mi.Extent[2]),
+		}
+		var yaw float32
+		if mi.RandomYaw {
+			yaw = between(0, 2*math.Pi)
+		}
+		s := between(mi.MinScale, mi.MaxScale)
+		m := mgl32.Translate3D(pos[0], pos[1], pos[2]).Mul4(mgl32.HomogRotate3DY(yaw)).Mul4(mgl32.Scale3D(s, s, s))
+		mi.Instances = append(mi.Instances, engine.Instance{Model: m, Color: [4]float32{1, 1, 1, 1}})
+	}
+	mi.version++
+}
+
+// appendWorldInstances appends the instances of mi placed by the entity
+// transform t and tinted by base. With a frustum, instances whose mesh
+// bounds fall outside it are dropped and counted in stats; meshes without
+// bounds are never culled.
+func (mi *MeshInstancer) appendWorldInstances(dst []engine.Instance, t *Transform, base [4]float32, frustum *engine.Frustum, stats *engine.CullStats) []engine.Instance {
+	var bounds engine.MeshBounds
+	var hasBounds bool
+	if mm := engine.GlobalMeshManager; mm != nil && frustum != nil {
+		bounds, hasBounds = mm.Bounds(mi.MeshID)
+	}
+	world := mgl32.Mat4(t.WorldMatrix)
+	for _, in := range mi.Instances {
+		m := world.Mul4(mgl32.Mat4(in.Model))
+		if hasBounds {
+			stats.Tested++
+			if !frustum.IntersectsAABB(bounds.Box.Transform(m)) {
+				continue
+			}
+			stats.Visible++
+		}
+		var c [4]float32
+		for i := range c {
+			c[i] = base[i] * in.Color[i]
+		}
+		dst = append(dst, engine.Instance{Model: m, Color: c})
+	}
+	return dst
+}
+
+func (mi *MeshInstancer) Update(dt float32) { _ = dt }
+
+func (mi *MeshInstancer) EditorName() string { return "MeshInstancer" }
+
+func (mi *MeshInstancer) EditorFields() map[string]any {
+	return map[string]any{
+		"MeshID":    mi.MeshID,
+		"Count":     mi.Count,
+		"Extent":    mi.Extent,
+		"MinScale":  mi.MinScale,
+		"MaxScale":  mi.MaxScale,
+		"RandomYaw": mi.RandomYaw,
+		"Seed":      mi.Seed,
+	}
+}
+
+// SetEditorField changes a setting and, for scatter settings, scatters
+// the instances again.
+func (mi *MeshInstancer) SetEditorField(name string, value any) {
+	switch name {
+	case "MeshID":
+		mi.MeshID, _ = value.(string)
+		mi.version++
+		return
+	case "Count":
+		mi.Count = max(toInt(value), 0)
+	case "Extent":
+		mi.Extent = toVec3(value)
+	case "MinScale":
+		mi.MinScale = toFloat32(value)
+	case "MaxScale":
+		mi.MaxScale = toFloat32(value)
+	case "RandomYaw":
+		mi.RandomYaw = toBool(value)
+	case "Seed":
+		mi.Seed = toInt(value)
+	default:
+		return
+	}
+	mi.Scatter()
+}
+
+func (mi *MeshInstancer) Version() uint64 { return mi.version }
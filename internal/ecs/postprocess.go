@@ -0,0 +1,84 @@
+package ecs
+
+import "go-engine/Go-Cordance/internal/engine"
+
+// PostProcess configures the post-processing chain of the camera on the
+// same entity: exposure, tone mapping, bloom, FXAA, vignette and colour
+// grading. The active camera's settings are used; without one the render
+// system falls back to engine.DefaultPostSettings.
+type PostProcess struct {
+	engine.PostSettings
+	version uint64
+}
+
+func NewPostProcess() *PostProcess {
+	return &PostProcess{PostSettings: engine.DefaultPostSettings}
+}
+
+func (p *PostProcess) Update(dt float32) { _ = dt }
+
+func (p *PostProcess) EditorName() string { return "PostProcess" }
+
+func (p *PostProcess) EditorFields() map[string]any {
+	return map[string]any{
+		"AutoExposure":         p.AutoExposure,
+		"ExposureCompensation": p.ExposureCompensation,
+		"MinEV100":             p.MinEV100,
+		"MaxEV100":             p.MaxEV100,
+		"AdaptSpeedUp":         p.AdaptSpeedUp,
+		"AdaptSpeedDown":       p.AdaptSpeedDown,
+		"Tonemap":              int(p.Tonemap),
+		"Bloom":                p.Bloom,
+		"BloomThreshold":       p.BloomThreshold,
+		"BloomKnee":            p.BloomKnee,
+		"BloomIntensity":       p.BloomIntensity,
+		"BloomRadius":          p.BloomRadius,
+		"FXAA":                 p.FXAA,
+		"VignetteIntensity":    p.VignetteIntensity,
+		"VignetteSmoothness":   p.VignetteSmoothness,
+		"LUTPath":              p.LUTPath,
+		"LUTContribution":      p.LUTContribution,
+	}
+}
+
+func (p *PostProcess) SetEditorField(name string, value any) {
+	switch name {
+	case "AutoExposure":
+		p.AutoExposure = toBool(value)
+	case "ExposureCompensation":
+		p.ExposureCompensation = toFloat32(value)
+	case "MinEV100":
+		p.MinEV100 = toFloat32(value)
+	case "MaxEV100":
+		p.MaxEV100 = toFloat32(value)
+	case "AdaptSpeedUp":
+		p.AdaptSpeedUp = toFloat32(value)
+	case "AdaptSpeedDown":
+		p.AdaptSpeedDown = toFloat32(value)
+	case "Tonemap":
+		p.Tonemap = engine.TonemapOperator(toInt(value))
+	case "Bloom":
+		p.Bloom = toBool(value)
+	case "BloomThreshold":
+		p.BloomThreshold = toFloat32(value)
+	case "BloomKnee":
+		p.BloomKnee = toFloat32(value)
+	case "BloomIntensity":
+		p.BloomIntensity = toFloat32(value)
+	case "BloomRadius":
+		p.BloomRadius = toFloat32(value)
+	case "FXAA":
+		p.FXAA = toBool(value)
+	case "VignetteIntensity":
+		p.VignetteIntensity = toFloat32(value)
+	case "VignetteSmoothness":
+		p.VignetteSmoothness = toFloat32(value)
+	case "LUTPath":
+		p.LUTPath, _ = value.(string)
+	case "LUTContribution":
+		p.LUTContribution = toFloat32(value)
+	}
+	p.version++
+}
+
+func (p *PostProcess) Version() uint64 { return p.version }
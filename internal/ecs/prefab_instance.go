@@ -0,0 +1,9 @@
+package ecs
+
+// PrefabInstance marks the root of an entity tree instantiated from a
+// prefab file, so the tree can be rebuilt when the prefab changes.
+type PrefabInstance struct {
+	Path string
+}
+
+func (p *PrefabInstance) Update(dt float32) { _ = dt }
@@ -0,0 +1,113 @@
+package ecs
+
+import "math"
+
+// RayHit describes the closest intersection found by RaycastColliders.
+type RayHit struct {
+	Entity   *Entity
+	Point    [3]float32
+	Normal   [3]float32
+	Distance float32
+}
+
+// RaycastColliders casts a ray against every ColliderPlane and ColliderAABB
+// in entities and returns the closest hit within maxDist. dir must be
+// normalized. Entities listed in ignore are skipped.
+func RaycastColliders(entities []*Entity, origin, dir [3]float32, maxDist float32, ignore ...*Entity) (RayHit, bool) {
+	best := RayHit{Distance: maxDist}
+	found := false
+
+	for _, e := range entities {
+		if containsEntity(ignore, e) {
+			continue
+		}
+		for _, c := range e.Components {
+			var dist float32
+			var normal [3]float32
+			var ok bool
+
+			switch col := c.(type) {
+			case *ColliderPlane:
+				dist, ok = rayPlaneY(origin, dir, col.Y)
+				normal = [3]float32{0, 1, 0}
+			case *ColliderAABB:
+				tr := e.GetTransform()
+				if tr == nil {
+					continue
+				}
+				dist, normal, ok = rayAABB(origin, dir, tr.Position, col.HalfExtents)
+			}
+
+			if ok && dist <= best.Distance {
+				best = RayHit{
+					Entity:   e,
+					Point:    add3(origin, mul3(dir, dist)),
+					Normal:   normal,
+					Distance: dist,
+				}
+				found = true
+			}
+		}
+	}
+	return best, found
+}
+
+// rayPlaneY intersects a ray with the horizontal plane y = planeY
+// (the ColliderPlane convention).
+func rayPlaneY(origin, dir [3]float32, planeY float32) (float32, bool) {
+	if dir[1] > -1e-6 && dir[1] < 1e-6 {
+		return 0, false
+	}
+	t := (planeY - origin[1]) / dir[1]
+	return t, t >= 0
+}
+
+// rayAABB is the slab test against a box centered at center. The returned
+// normal is the face that was entered.
+func rayAABB(origin, dir, center, half [3]float32) (float32, [3]float32, bool) {
+	tmin := float32(0)
+	tmax := float32(math.MaxFloat32)
+	var normal [3]float32
+
+	for axis := 0; axis < 3; axis++ {
+		lo := center[axis] - half[axis]
+		hi := center[axis] + half[axis]
+
+		if dir[axis] > -1e-6 && dir[axis] < 1e-6 {
+			if origin[axis] < lo || origin[axis] > hi {
+				return 0, normal, false
+			}
+			continue
+		}
+
+		inv := 1 / dir[axis]
+		t1 := (lo - origin[axis]) * inv
+		t2 := (hi - origin[axis]) * inv
+		sign := float32(-1)
+		if t1 > t2 {
+			t1, t2 = t2, t1
+			sign = 1
+		}
+		if t1 > tmin {
+			tmin = t1
+			normal = [3]float32{}
+			normal[axis] = sign
+		}
+		if t2 < tmax {
+			tmax = t2
+		}
+		if tmin > tmax {
+			return 0, normal, false
+		}
+	}
+	return tmin, normal, true
+}
+
+func containsEntity(list []*Entity, e *Entity) bool {
+	for _, x := range list {
+		if x == e {
+			return true
+		}
+	}
+	return false
+}
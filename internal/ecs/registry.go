@@ -18,10 +18,14 @@ var ComponentRegistry = map[string]func() Component{
 	"Name":           func() Component { return NewName("") },
 	"Skin":           func() Component { return &Skin{} },
 	"Camera":         func() Component { return NewCamera() },
+	"Environment":    func() Component { return NewEnvironment("") },
+	"PostProcess":    func() Component { return NewPostProcess() },
+	"MeshInstancer":  func() Component { return NewMeshInstancer("") },
 	"AnimationPlayer": func() Component {
 		return &AnimationPlayer{
-			Clips: make(map[string]*AnimationClip),
-			Speed: 1.0,
+			Clips:    make(map[string]*AnimationClip),
+			Speed:    1.0,
+			RootNode: -1,
 		}
 	},
 }
@@ -0,0 +1,246 @@
+package ecs
+
+import (
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"go-engine/Go-Cordance/internal/engine"
+)
+
+// RenderItem is one mesh the main pass draws: an entity's Mesh, one of
+// its MultiMesh submeshes or the copies of a MeshInstancer, with the
+// material that decides its pass.
+type RenderItem struct {
+	Entity    *Entity
+	Transform *Transform
+	Skin      *Skin
+	MeshDrawItem
+
+	// Instances are the visible world-space copies of a MeshInstancer,
+	// tinted by the material; nil for a single mesh at Transform.
+	Instances []engine.Instance
+
+	// Depth is the view-space distance along the camera's forward axis,
+	// measured at the centre of the world bounds.
+	Depth float32
+}
+
+// RenderQueue is the main pass split by alpha mode. Opaque and Masked are
+// sorted front to back so depth testing rejects hidden fragments early;
+// Transparent is sorted back to front so blending composites correctly.
+type RenderQueue struct {
+	Opaque      []RenderItem
+	Masked      []RenderItem
+	Transparent []RenderItem
+}
+
+// Len is the total number of items in the queue.
+func (q *RenderQueue) Len() int {
+	return len(q.Opaque) + len(q.Masked) + len(q.Transparent)
+}
+
+// BuildRenderQueue gathers the drawable meshes of entities, drops those
+// outside frustum (counting them in stats) and sorts them into passes.
+// MeshInstancer copies are culled one by one.
+// Entities need a Transform and a Material; parents with Children are
+// skipped, as their meshes are drawn by the children. A nil frustum keeps
+// everything; stats may be nil. Items at equal depth keep entity order.
+func BuildRenderQueue(entities []*Entity, view mgl32.Mat4, frustum *engine.Frustum, stats *engine.CullStats) RenderQueue {
+	var q RenderQueue
+	if stats == nil {
+		stats = &engine.CullStats{}
+	}
+	var items []MeshDrawItem
+	for _, e := range entities {
+		var t *Transform
+		var mesh *Mesh
+		var mat *Material
+		var normalMap *NormalMap
+		var multi *MultiMesh
+		var multiMat *MultiMaterial
+		var skin *Skin
+		var instancer *MeshInstancer
+		var hasChildren bool
+
+		for _, c := range e.Components {
+			switch v := c.(type) {
+			case *Transform:
+				t = v
+			case *Mesh:
+				mesh = v
+			case *Material:
+				mat = v
+			case *NormalMap:
+				normalMap = v
+			case *MultiMesh:
+				multi = v
+			case *MultiMaterial:
+				multiMat = v
+			case *Skin:
+				skin = v
+			case *MeshInstancer:
+				instancer = v
+			case *Children:
+				hasChildren = true
+			}
+		}
+		if hasChildren || t == nil || mat == nil {
+			continue
+		}
+		depth := viewDepth(view, t)
+		if instancer != nil && instancer.MeshID != "" {
+			if inst := instancer.appendWorldInstances(nil, t, mat.BaseColor, frustum, stats); len(inst) > 0 {
+				q.add(RenderItem{
+					Entity:       e,
+					Transform:    t,
+					MeshDrawItem: MeshDrawItem{MeshID: instancer.MeshID, Material: mat, NormalMap: normalMap},
+					Instances:    inst,
+					Depth:        depth,
+				})
+			}
+		}
+		if frustum != nil && !cullTest(frustum, t, stats) {
+			continue
+		}
+
+		items = collectMeshes(mesh, multi, mat, multiMat, normalMap, items[:0])
+		for _, it := range items {
+			if skin == nil {
+				it.MeshID = lodMesh(it.MeshID, t, depth)
+			}
+			q.add(RenderItem{
+				Entity:       e,
+				Transform:    t,
+				Skin:         skin,
+				MeshDrawItem: it,
+				Depth:        depth,
+			})
+		}
+	}
+
+	sort.SliceStable(q.Opaque, func(i, j int) bool { return q.Opaque[i].Depth < q.Opaque[j].Depth })
+	sort.SliceStable(q.Masked, func(i, j int) bool { return q.Masked[i].Depth < q.Masked[j].Depth })
+	sort.SliceStable(q.Transparent, func(i, j int) bool { return q.Transparent[i].Depth > q.Transparent[j].Depth })
+	return q
+}
+
+// LODScreenSize is the projected size (world bounding radius over view
+// depth) below which a mesh with generated LODs draws its first level;
+// each halving of the size moves one level coarser.
+var LODScreenSize float32 = 0.25
+
+// lodMesh picks the LOD level of mesh id for an entity bounded by t at
+// depth. Meshes without LODs, and entities without bounds, draw id.
+func lodMesh(id string, t *Transform, depth float32) string {
+	mm := engine.GlobalMeshManager
+	if mm == nil || !t.HasBounds || depth <= 0 {
+		return id
+	}
+	n := mm.LODCount(id)
+	if n == 0 {
+		return id
+	}
+	size := t.WorldSphere.Radius / depth
+	level := 0
+	for limit := LODScreenSize; size < limit && level < n; limit /= 2 {
+		level++
+	}
+	return mm.LOD(id, level)
+}
+
+// add appends item to the pass of its material's alpha mode.
+func (q *RenderQueue) add(item RenderItem) {
+	switch item.Material.AlphaMode {
+	case engine.AlphaBlend:
+		q.Transparent = append(q.Transparent, item)
+	case engine.AlphaMask:
+		q.Masked = append(q.Masked, item)
+	default:
+		q.Opaque = append(q.Opaque, item)
+	}
+}
+
+// RenderBatch is queue items drawn under one shader, material and normal
+// map binding. Instanced batches are drawn with a single instanced draw
+// from the instances of all their items; the others hold one item drawn
+// with the model uniform.
+type RenderBatch struct {
+	Items     []RenderItem
+	Instanced bool
+}
+
+type batchKey struct {
+	meshID    string
+	material  *Material
+	shader    *engine.ShaderProgram
+	normalMap *NormalMap
+}
+
+// BatchRenderItems groups the items of one sorted pass by mesh, material,
+// shader and normal map, keeping the batches in the order of their first
+// item. Skinned items are never grouped. Without group every item is its
+// own batch, which keeps the back-to-front order blending needs; a
+// MeshInstancer item is still instanced.
+func BatchRenderItems(items []RenderItem, group bool) []RenderBatch {
+	var batches []RenderBatch
+	index := map[batchKey]int{}
+	for _, it := range items {
+		resolveMaterialShader(it.Material)
+		if group && it.Skin == nil {
+			key := batchKey{it.MeshID, it.Material, it.Material.Shader, it.NormalMap}
+			if i, ok := index[key]; ok {
+				batches[i].Items = append(batches[i].Items, it)
+				batches[i].Instanced = true
+				continue
+			}
+			index[key] = len(batches)
+		}
+		batches = append(batches, RenderBatch{Items: []RenderItem{it}, Instanced: it.Instances != nil})
+	}
+	return batches
+}
+
+// InstanceCount is the number of instances an instanced batch draws.
+func (b *RenderBatch) InstanceCount() int {
+	n := 0
+	for _, it := range b.Items {
+		if it.Instances != nil {
+			n += len(it.Instances)
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// AppendInstances appends the per-instance data of b to dst: the copies
+// of MeshInstancer items, and the world matrix and base colour of the
+// others. Instances of the selected entity get the highlight colour.
+func (b *RenderBatch) AppendInstances(dst []engine.Instance, selected uint64, highlight [4]float32) []engine.Instance {
+	for _, it := range b.Items {
+		start := len(dst)
+		if it.Instances != nil {
+			dst = append(dst, it.Instances...)
+		} else {
+			dst = append(dst, engine.Instance{Model: it.Transform.WorldMatrix, Color: it.Material.BaseColor})
+		}
+		if uint64(it.Entity.ID) == selected {
+			for i := start; i < len(dst); i++ {
+				dst[i].Color = highlight
+			}
+		}
+	}
+	return dst
+}
+
+// viewDepth is how far in front of the camera t's bounds centre (or its
+// origin, without bounds) lies.
+func viewDepth(view mgl32.Mat4, t *Transform) float32 {
+	p := mgl32.Vec3{t.WorldMatrix[12], t.WorldMatrix[13], t.WorldMatrix[14]}
+	if t.HasBounds {
+		b := t.WorldBounds
+		p = mgl32.Vec3{(b.Min[0] + b.Max[0]) / 2, (b.Min[1] + b.Max[1]) / 2, (b.Min[2] + b.Max[2]) / 2}
+	}
+	return -view.Mul4x1(p.Vec4(1)).Z()
+}
@@ -0,0 +1,217 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"go-engine/Go-Cordance/internal/engine"
+)
+
+func alphaEntity(id int64, z float32, mode engine.AlphaMode) *Entity {
+	e := quadEntity(id, [3]float32{0, 0, z}, [4]float32{1, 1, 1, 0.5})
+	e.GetComponent((*Material)(nil)).(*Material).AlphaMode = mode
+	return e
+}
+
+func queueIDs(items []RenderItem) []int64 {
+	var ids []int64
+	for _, it := range items {
+		ids = append(ids, it.Entity.ID)
+	}
+	return ids
+}
+
+func TestBuildRenderQueue(t *testing.T) {
+	view := mgl32.LookAtV(mgl32.Vec3{0, 0, 5}, mgl32.Vec3{}, mgl32.Vec3{0, 1, 0})
+
+	// Two submeshes of one entity land in different passes.
+	multi := NewEntity(7)
+	multi.AddComponent(NewTransform([3]float32{0, 0, -10}))
+	multi.AddComponent(NewMultiMesh([]string{"a", "b"}))
+	multi.AddComponent(NewMaterial([4]float32{1, 1, 1, 1}))
+	glass := NewMaterial([4]float32{1, 1, 1, 0.2})
+	glass.AlphaMode = engine.AlphaBlend
+	mm := NewMultiMaterial()
+	mm.Materials["b"] = glass
+	multi.AddComponent(mm)
+
+	parent := alphaEntity(8, 0, engine.AlphaOpaque)
+	parent.AddComponent(NewChildren())
+
+	entities := []*Entity{
+		alphaEntity(1, -3, engine.AlphaOpaque),
+		alphaEntity(2, 2, engine.AlphaOpaque),
+		alphaEntity(3, -3, engine.AlphaBlend),
+		alphaEntity(4, 1, engine.AlphaBlend),
+		alphaEntity(5, 0, engine.AlphaMask),
+		alphaEntity(6, 1, engine.AlphaMask),
+		multi,
+		parent,
+	}
+	q := BuildRenderQueue(entities, view, nil, nil)
+
+	check := func(pass string, items []RenderItem, want ...int64) {
+		t.Helper()
+		got := queueIDs(items)
+		if len(got) != len(want) {
+			t.Fatalf("%s pass %v, want %v", pass, got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("%s pass %v, want %v", pass, got, want)
+			}
+		}
+	}
+	check("opaque", q.Opaque, 2, 1, 7)
+	check("masked", q.Masked, 6, 5)
+	check("transparent", q.Transparent, 7, 3, 4)
+	if q.Len() != 8 {
+		t.Errorf("queue holds %d items, want 8", q.Len())
+	}
+
+	if it := q.Opaque[0]; it.Depth != 3 || it.MeshID != "quad" {
+		t.Errorf("nearest opaque item at depth %v, mesh %q", it.Depth, it.MeshID)
+	}
+	if it := q.Transparent[0]; it.MeshID != "b" || it.Material != glass {
+		t.Errorf("farthest transparent item is mesh %q", it.MeshID)
+	}
+	if it := q.Opaque[2]; it.MeshID != "a" || it.Material == glass {
+		t.Errorf("opaque submesh is mesh %q", it.MeshID)
+	}
+}
+
+func TestBuildRenderQueue_PicksLODByProjectedSize(t *testing.T) {
+	newRecordedRenderSystem(t)
+	mm := engine.GlobalMeshManager
+	mm.RegisterMeshData(quadMesh(engine.LODMeshID("quad", 1)))
+	mm.RegisterMeshData(quadMesh(engine.LODMeshID("quad", 2)))
+
+	view := mgl32.LookAtV(mgl32.Vec3{0, 0, 5}, mgl32.Vec3{}, mgl32.Vec3{0, 1, 0})
+	entities := []*Entity{
+		alphaEntity(1, 4, engine.AlphaOpaque),   // depth 1: full detail
+		alphaEntity(2, 0, engine.AlphaOpaque),   // depth 5: first level
+		alphaEntity(3, -20, engine.AlphaOpaque), // depth 25: coarsest there is
+	}
+	NewTransformSystem().Update(0, entities)
+	q := BuildRenderQueue(entities, view, nil, nil)
+
+	want := []string{"quad", "quad#lod1", "quad#lod2"}
+	for i, it := range q.Opaque {
+		if it.MeshID != want[i] {
+			t.Errorf("entity %d draws %q, want %q", it.Entity.ID, it.MeshID, want[i])
+		}
+	}
+}
+
+func sharedQuad(id int64, x float32, mat *Material) *Entity {
+	e := NewEntity(id)
+	e.AddComponent(NewTransform([3]float32{x, 0, 0}))
+	e.AddComponent(NewMesh("quad"))
+	e.AddComponent(mat)
+	return e
+}
+
+func TestBatchRenderItems(t *testing.T) {
+	view := mgl32.LookAtV(mgl32.Vec3{0, 0, 5}, mgl32.Vec3{}, mgl32.Vec3{0, 1, 0})
+	stone := NewMaterial([4]float32{0.5, 0.5, 0.5, 1})
+	moss := NewMaterial([4]float32{0, 1, 0, 1})
+
+	skinned := sharedQuad(4, 3, stone)
+	skinned.AddComponent(&Skin{})
+	rocks := NewEntity(6)
+	rocks.AddComponent(NewTransform([3]float32{0, 0, -20}))
+	rocks.AddComponent(stone)
+	inst := NewMeshInstancer("quad")
+	inst.Count, inst.Seed = 5, 7
+	inst.Scatter()
+	rocks.AddComponent(inst)
+
+	entities := []*Entity{
+		sharedQuad(1, 0, stone),
+		sharedQuad(2, 1, moss),
+		sharedQuad(3, 2, stone),
+		skinned,
+		sharedQuad(5, 4, stone),
+		rocks,
+	}
+	q := BuildRenderQueue(entities, view, nil, nil)
+	batches := BatchRenderItems(q.Opaque, true)
+
+	// stone (1, 3, 5 and the rocks), moss, then the skinned quad alone.
+	if len(batches) != 3 {
+		t.Fatalf("got %d batches, want 3", len(batches))
+	}
+	if b := batches[0]; !b.Instanced || b.Items[0].Material != stone || len(b.Items) != 4 || b.InstanceCount() != 8 {
+		t.Errorf("stone batch: instanced %v, %d items, %d instances", b.Instanced, len(b.Items), b.InstanceCount())
+	}
+	if b := batches[1]; b.Instanced || b.Items[0].Entity.ID != 2 {
+		t.Errorf("moss batch: instanced %v, entity %d", b.Instanced, b.Items[0].Entity.ID)
+	}
+	if b := batches[2]; b.Instanced || b.Items[0].Entity.ID != 4 {
+		t.Errorf("skinned batch: instanced %v, entity %d", b.Instanced, b.Items[0].Entity.ID)
+	}
+
+	highlight := [4]float32{1, 1, 0, 1}
+	data := batches[0].AppendInstances(nil, 3, highlight)
+	if len(data) != 8 {
+		t.Fatalf("got %d instances, want 8", len(data))
+	}
+	if data[0].Model != entities[0].GetTransform().WorldMatrix || data[0].Color != stone.BaseColor {
+		t.Errorf("first instance %+v", data[0])
+	}
+	if data[1].Color != highlight || data[2].Color != stone.BaseColor {
+		t.Errorf("selected entity 3 colours %v, %v", data[1].Color, data[2].Color)
+	}
+	// Scattered copies are placed under the instancer's transform.
+	for i, in := range inst.Instances {
+		want := mgl32.Translate3D(0, 0, -20).Mul4(mgl32.Mat4(in.Model))
+		if got := data[3+i].Model; !mgl32.Mat4(got).ApproxEqual(want) {
+			t.Errorf("rock %d at %v, want %v", i, got, want)
+		}
+	}
+
+	// Transparent passes keep their order: only the instancer is instanced.
+	stone.AlphaMode = engine.AlphaBlend
+	q = BuildRenderQueue(entities, view, nil, nil)
+	batches = BatchRenderItems(q.Transparent, false)
+	if len(batches) != 5 {
+		t.Fatalf("got %d transparent batches, want 5", len(batches))
+	}
+	for _, b := range batches {
+		if b.Instanced != (b.Items[0].Entity == rocks) || len(b.Items) != 1 {
+			t.Errorf("transparent batch of entity %d: instanced %v, %d items", b.Items[0].Entity.ID, b.Instanced, len(b.Items))
+		}
+	}
+}
+
+func TestMeshInstancerScatter(t *testing.T) {
+	a := NewMeshInstancer("rock")
+	a.SetEditorField("MinScale", 0.5)
+	a.SetEditorField("MaxScale", 2)
+	a.SetEditorField("Extent", [3]float32{4, 0, 8})
+	a.SetEditorField("Count", 200)
+	if len(a.Instances) != 200 {
+		t.Fatalf("scattered %d instances, want 200", len(a.Instances))
+	}
+	for i, in := range a.Instances {
+		x, y, z := in.Model[12], in.Model[13], in.Model[14]
+		s := mgl32.Vec3{in.Model[0], in.Model[1], in.Model[2]}.Len()
+		if x < -4 || x > 4 || y != 0 || z < -8 || z > 8 || s < 0.5-1e-5 || s > 2+1e-5 {
+			t.Fatalf("instance %d at (%v, %v, %v) scaled %v", i, x, y, z, s)
+		}
+	}
+
+	b := NewMeshInstancer("rock")
+	b.MinScale, b.MaxScale, b.Extent, b.Count = 0.5, 2, [3]float32{4, 0, 8}, 200
+	b.Scatter()
+	for i := range a.Instances {
+		if a.Instances[i] != b.Instances[i] {
+			t.Fatalf("same settings scattered differently at %d", i)
+		}
+	}
+	b.SetEditorField("Seed", 1)
+	if a.Instances[0] == b.Instances[0] {
+		t.Error("a new seed scattered the same instances")
+	}
+}
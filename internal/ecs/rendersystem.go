@@ -6,9 +6,9 @@ import (
 	"go-engine/Go-Cordance/internal/glutil"
 	"log"
 	"math"
+	"sort"
 	"unsafe"
 
-	"github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 )
@@ -23,17 +23,72 @@ type RenderSystem struct {
 	OrbitalEnabled bool
 	SelectedEntity uint64
 
-	DebugShowMode    int32
-	DebugFlipGreen   bool
-	ActiveShader     *engine.ShaderProgram
-	shadowLightIndex int
-	DefaultShader    *engine.ShaderProgram
+	DebugShowMode  int32
+	DebugFlipGreen bool
+	ActiveShader   *engine.ShaderProgram
+	DefaultShader  *engine.ShaderProgram
 
 	// --- NEW: GPU material UBO ---
 	materialUBO     uint32
 	materialBinding uint32
+
+	// Frustum culling counters for the last frame: the camera pass, and
+	// every shadow caster pass together.
+	MainCull   engine.CullStats
+	ShadowCull engine.CullStats
+
+	// Clustered lighting: this frame's lights, directional ones first, and
+	// their assignment to the camera's clusters.
+	Clusters      *engine.LightClusters
+	lights        []engine.LightData
+	localLights   []engine.LightData
+	dirLightCount int
+	lightBuffers  engine.LightBuffers
+
+	// Shadows configures the shadow atlas and point light cube maps;
+	// ShadowViews are the depth renders planned for the current frame.
+	Shadows       engine.ShadowSettings
+	ShadowViews   []engine.ShadowView
+	shadowAtlas   *engine.ShadowAtlas
+	pointShadows  *engine.PointShadowMaps
+	shadowCasters []int // indices into lights
+	cascadeSplits [engine.MaxCascades]float32
+	cascadeCount  int
+
+	// Environment is the image-based lighting baked from the scene's first
+	// Environment component, or nil when there is none.
+	Environment  *engine.Environment
+	envIntensity float32
+	envPath      string         // path Environment was loaded (or failed to load) from
+	envLoad      *assets.Future // bake of envPath still in flight
+
+	// Post is the HDR target the scene renders into and the chain that
+	// resolves it in Present, or nil to draw straight to the default
+	// framebuffer (the post shaders are not loaded).
+	Post         *engine.PostStack
+	postSettings engine.PostSettings
+	frameDt      float32
+
+	// target is the framebuffer frames end up in: the default one, or a
+	// CaptureTarget while Capture runs.
+	target uint32
+
+	// Instanced draws upload their per-instance data to one shared buffer.
+	instances    *engine.InstanceBuffer
+	instanceData []engine.Instance
 }
 
+// lightBufferUnit is the first of the four texture units holding the
+// light, cluster grid, cluster index and shadow view buffers.
+const lightBufferUnit = 5
+
+// pointShadowUnit is the texture unit of the point light cube shadow maps.
+const pointShadowUnit = 9
+
+// minShadowTile is the smallest atlas tile a shadow is shrunk to before
+// it is dropped for the frame.
+const minShadowTile = 128
+
 // std140-compatible layout for the MaterialBlock
 type gpuMaterial struct {
 	BaseColor [4]float32
@@ -75,140 +130,96 @@ func NewRenderSystem(r *engine.Renderer, mm *engine.MeshManager, cs *CameraSyste
 		LightDir:       [3]float32{1.0, -0.7, -0.3},
 		OrbitalEnabled: true,
 		DefaultShader:  engine.MustGetShaderProgram("default_shader"),
+		Clusters:       engine.NewLightClusters(engine.DefaultClusterConfig),
+		Shadows:        engine.DefaultShadowSettings,
 	}
 
 	// --- NEW: create material UBO ---
 	rs.materialBinding = 1 // must match GLSL binding = 1
-	var ubo uint32
-	gl.GenBuffers(1, &ubo)
-	gl.BindBuffer(gl.UNIFORM_BUFFER, ubo)
-	gl.BufferData(gl.UNIFORM_BUFFER, int(unsafe.Sizeof(gpuMaterial{})), nil, gl.DYNAMIC_DRAW)
-	gl.BindBuffer(gl.UNIFORM_BUFFER, 0)
-	rs.materialUBO = ubo
-
-	return rs
-}
-
-func (rs *RenderSystem) computeShadowLightSpace(entities []*Entity) (mgl32.Mat4, int, bool) {
-	var shadowLight *LightComponent
-	var shadowTransform *Transform
-	shadowIndex := -1
-
-	// FIRST PASS: explicit shadow-casting light
-	for i, e := range entities {
-		lc, ok := e.GetComponent((*LightComponent)(nil)).(*LightComponent)
-		if !ok || !lc.CastsShadows {
-			continue
-		}
-		tr, _ := e.GetComponent((*Transform)(nil)).(*Transform)
-		shadowLight = lc
-		shadowTransform = tr
-		shadowIndex = i
-		break
-	}
-
-	if shadowLight == nil || shadowTransform == nil {
-		return mgl32.Ident4(), -1, false
-	}
-
-	// Compute light-space matrix (unchanged)
-	var lightSpace mgl32.Mat4
+	rs.materialUBO = engine.Device.CreateBuffer(engine.UniformBuffer, int(unsafe.Sizeof(gpuMaterial{})), nil, engine.DynamicDraw)
+	engine.Device.BindBuffer(engine.UniformBuffer, 0)
+	rs.instances = engine.NewInstanceBuffer(256)
 
-	switch shadowLight.Type {
-	case LightDirectional:
-		// derive direction from the shadow light's transform, not rs.LightDir
-		q := mgl32.Quat{
-			W: shadowTransform.Rotation[3],
-			V: mgl32.Vec3{
-				shadowTransform.Rotation[0],
-				shadowTransform.Rotation[1],
-				shadowTransform.Rotation[2],
-			},
-		}
-		fwd := q.Rotate(mgl32.Vec3{0, 0, -1})
-		lightDir := fwd.Normalize()
-
-		camPos := mgl32.Vec3{
-			rs.CameraSystem.Position[0],
-			rs.CameraSystem.Position[1],
-			rs.CameraSystem.Position[2],
-		}
-		// you can keep your offset if you like it:
-		sceneCenter := camPos.Sub(lightDir.Mul(20))
-		extent := float32(50.0)
-
-		lightSpace = engine.ComputeDirectionalLightSpaceMatrix(lightDir, sceneCenter, extent)
-
-	case LightSpot:
-		pos := mgl32.Vec3{
-			shadowTransform.Position[0],
-			shadowTransform.Position[1],
-			shadowTransform.Position[2],
-		}
-		q := mgl32.Quat{
-			W: shadowTransform.Rotation[3],
-			V: mgl32.Vec3{
-				shadowTransform.Rotation[0],
-				shadowTransform.Rotation[1],
-				shadowTransform.Rotation[2],
-			},
-		}
-		dir := q.Rotate(mgl32.Vec3{0, 0, -1})
-
-		fov := shadowLight.Angle * (math.Pi / 180.0) * 2
-		proj := mgl32.Perspective(fov, 1.0, 0.1, shadowLight.Range)
-		view := mgl32.LookAtV(pos, pos.Add(dir), mgl32.Vec3{0, 1, 0})
-
-		lightSpace = proj.Mul4(view)
+	post, err := engine.NewPostStack(r.ScreenWidth, r.ScreenHeight)
+	if err != nil {
+		log.Printf("post-processing disabled: %v", err)
+	} else {
+		rs.Post = post
 	}
 
-	return lightSpace, shadowIndex, true
+	return rs
 }
 
 func (rs *RenderSystem) RenderShadowPass(entities []*Entity) {
 	glutil.ClearGLErrors()
-	var meshIDs []string
-	meshIDs = meshIDs[:0]
 
-	if rs.Renderer.ShadowFBO == 0 || rs.Renderer.ShadowProgram == 0 {
-		return
-	}
-	// Pick a shadow-casting light (first found for now)
-	lightSpace, _, ok := rs.computeShadowLightSpace(entities)
-	if !ok {
+	if rs.Renderer.ShadowFBO == 0 || rs.Renderer.ShadowProgram == 0 || len(rs.ShadowViews) == 0 {
 		return
 	}
 
 	// --- Bind FBO ---
-	gl.Viewport(0, 0, int32(rs.Renderer.ShadowWidth), int32(rs.Renderer.ShadowHeight))
-	gl.BindFramebuffer(gl.FRAMEBUFFER, rs.Renderer.ShadowFBO)
+	dev := engine.Device
+	dev.Viewport(0, 0, int32(rs.Renderer.ShadowWidth), int32(rs.Renderer.ShadowHeight))
+	dev.BindFramebuffer(rs.Renderer.ShadowFBO)
 
-	if status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER); status != gl.FRAMEBUFFER_COMPLETE {
-		log.Printf("Shadow FBO incomplete: 0x%X", status)
-	}
-
-	gl.Clear(gl.DEPTH_BUFFER_BIT)
+	dev.Clear(engine.ClearDepthBit)
 
 	// --- Use shadow program + uniforms ---
+	var locLS, locModel, locInstancing int32
 	glutil.RunGLChecked("ShadowPass: UseProgram+Uniforms", func() {
-		gl.UseProgram(rs.Renderer.ShadowProgram)
+		dev.UseProgram(rs.Renderer.ShadowProgram)
 
 		// Only set sampler uniforms if the main program is bound
-		var curProg int32
-		gl.GetIntegerv(gl.CURRENT_PROGRAM, &curProg)
-		if curProg == int32(rs.Renderer.Program) && rs.Renderer.LocShadowMap != -1 {
-			gl.Uniform1i(rs.Renderer.LocShadowMap, 2)
+		if rs.Renderer.ShadowProgram == rs.Renderer.Program && rs.Renderer.LocShadowMap != -1 {
+			dev.UniformInt(rs.Renderer.LocShadowMap, 2)
 		}
 
-		locLS := gl.GetUniformLocation(rs.Renderer.ShadowProgram, gl.Str("lightSpaceMatrix\x00"))
-		gl.UniformMatrix4fv(locLS, 1, false, &lightSpace[0])
+		locLS = dev.UniformLocation(rs.Renderer.ShadowProgram, "lightSpaceMatrix")
+		locModel = dev.UniformLocation(rs.Renderer.ShadowProgram, "model")
+		locInstancing = dev.UniformLocation(rs.Renderer.ShadowProgram, "useInstancing")
 	})
 
-	// --- Draw all meshes into depth map ---
+	// --- Render every view into its atlas tile ---
+	for _, v := range rs.ShadowViews {
+		if v.Cube {
+			continue
+		}
+		dev.Viewport(int32(v.Tile.X), int32(v.Tile.Y), int32(v.Tile.Size), int32(v.Tile.Size))
+		dev.UniformMat4(locLS, v.Matrix[:])
+		rs.drawShadowCasters(entities, v.Matrix, locModel, locInstancing)
+	}
+
+	// --- Point lights: every cube face is its own framebuffer ---
+	for _, v := range rs.ShadowViews {
+		if !v.Cube {
+			continue
+		}
+		dev.BindFramebuffer(rs.pointShadows.FBO(v.CubeLayer, v.Face))
+		dev.Viewport(0, 0, int32(rs.pointShadows.Size), int32(rs.pointShadows.Size))
+		dev.Clear(engine.ClearDepthBit)
+		dev.UniformMat4(locLS, v.Matrix[:])
+		rs.drawShadowCasters(entities, v.Matrix, locModel, locInstancing)
+	}
+
+	// Restore the output framebuffer + viewport
+	dev.BindFramebuffer(rs.target)
+	dev.Viewport(0, 0, int32(rs.Renderer.ScreenWidth), int32(rs.Renderer.ScreenHeight))
+
+	// Bind shadow texture for main pass
+	dev.BindTexture(2, engine.Texture2D, rs.Renderer.ShadowTex)
+}
+
+// drawShadowCasters draws every mesh inside the light volume of
+// lightSpace with the shadow program, instancing MeshInstancer copies.
+func (rs *RenderSystem) drawShadowCasters(entities []*Entity, lightSpace mgl32.Mat4, locModel, locInstancing int32) {
+	dev := engine.Device
+	var meshIDs []string
+	frustum := engine.FrustumFromMatrix(lightSpace)
 	for _, e := range entities {
 		var t *Transform
 		var mesh *Mesh
 		var multi *MultiMesh
+		var instancer *MeshInstancer
 
 		for _, c := range e.Components {
 			switch v := c.(type) {
@@ -218,14 +229,26 @@ func (rs *RenderSystem) RenderShadowPass(entities []*Entity) {
 				mesh = v
 			case *MultiMesh:
 				multi = v
+			case *MeshInstancer:
+				instancer = v
+			}
+		}
+		if t != nil && instancer != nil && instancer.MeshID != "" {
+			rs.instanceData = instancer.appendWorldInstances(rs.instanceData[:0], t, [4]float32{1, 1, 1, 1}, &frustum, &rs.ShadowCull)
+			if len(rs.instanceData) > 0 {
+				dev.UniformInt(locInstancing, 1)
+				rs.drawInstanced(instancer.MeshID, rs.instanceData)
+				dev.UniformInt(locInstancing, 0)
 			}
 		}
 		if t == nil || (mesh == nil && multi == nil) {
 			continue
 		}
+		if !cullTest(&frustum, t, &rs.ShadowCull) {
+			continue
+		}
 
-		locModel := gl.GetUniformLocation(rs.Renderer.ShadowProgram, gl.Str("model\x00"))
-		gl.UniformMatrix4fv(locModel, 1, false, &t.WorldMatrix[0])
+		dev.UniformMat4(locModel, t.WorldMatrix[:])
 		meshIDs = meshIDs[:0]
 		meshIDs = rs.collectShadowMeshes(mesh, multi, meshIDs)
 		// Draw
@@ -236,65 +259,55 @@ func (rs *RenderSystem) RenderShadowPass(entities []*Entity) {
 			vertexCount := rs.MeshManager.GetVertexCount(meshID)
 			ebo := rs.MeshManager.GetEBO(meshID)
 
-			bytesPerIndex := int32(4)
-			if indexType == gl.UNSIGNED_SHORT {
-				bytesPerIndex = 2
-			}
-
-			var eboSize int32
+			var eboSize int
 			if ebo != 0 {
-				gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-				gl.GetBufferParameteriv(gl.ELEMENT_ARRAY_BUFFER, gl.BUFFER_SIZE, &eboSize)
+				eboSize = dev.BufferSize(engine.ElementBuffer, ebo)
 			}
 
 			glutil.RunGLChecked("ShadowPass: draw "+meshID, func() {
-				gl.BindVertexArray(vao)
+				dev.BindVertexArray(vao)
 
 				if ebo != 0 {
-					gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
+					dev.BindBuffer(engine.ElementBuffer, ebo)
 				}
 
-				if indexCount > 0 && ebo != 0 && eboSize >= int32(indexCount)*bytesPerIndex {
-					if meshID == "line" {
-						gl.DrawElements(gl.LINES, indexCount, indexType, gl.PtrOffset(0))
-					} else {
-						gl.DrawElements(gl.TRIANGLES, indexCount, indexType, gl.PtrOffset(0))
-					}
+				prim := meshPrimitive(meshID)
+				if indexCount > 0 && ebo != 0 && eboSize >= int(indexCount)*indexType.Size() {
+					dev.DrawIndexed(prim, indexCount, indexType)
 				} else {
-					if meshID == "line" {
-						gl.DrawArrays(gl.LINES, 0, vertexCount)
-					} else {
-						gl.DrawArrays(gl.TRIANGLES, 0, vertexCount)
-					}
+					dev.DrawArrays(prim, 0, vertexCount)
 				}
 
-				gl.BindVertexArray(0)
+				dev.BindVertexArray(0)
 			})
 		}
 	}
-
-	// Restore default framebuffer + viewport
-	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
-	gl.Viewport(0, 0, int32(rs.Renderer.ScreenWidth), int32(rs.Renderer.ScreenHeight))
-
-	// Bind shadow texture for main pass
-	gl.ActiveTexture(gl.TEXTURE2)
-	gl.BindTexture(gl.TEXTURE_2D, rs.Renderer.ShadowTex)
 }
 
 func (rs *RenderSystem) Update(dt float32, entities []*Entity) {
 	rs.UpdateLightGizmos()
+	rs.MainCull, rs.ShadowCull = engine.CullStats{}, engine.CullStats{}
+	rs.frameDt = dt
+	rs.resolveEnvironment(entities)
+	rs.resolvePostProcess(entities)
+	rs.gatherLights(entities, rs.CameraSystem.View, rs.CameraSystem.Projection)
 	rs.RenderShadowPass(entities)
 
 	rs.RenderMainPass(entities)
 }
 
 func (rs *RenderSystem) RenderMainPass(entities []*Entity) {
+	if rs.Post != nil {
+		if err := rs.Post.Begin(rs.Renderer.ScreenWidth, rs.Renderer.ScreenHeight); err != nil {
+			log.Printf("post-processing disabled: %v", err)
+			rs.Post.Delete()
+			rs.Post = nil
+			engine.Device.BindFramebuffer(rs.target)
+		}
+	}
 	// 1) Bind baseline shader for the frame.
 	//    If you later want a global override, you can use rs.ActiveShader here.
 	// 1) Determine baseline shader for this frame
-	var drawItems []MeshDrawItem
-	drawItems = drawItems[:0]
 	base := rs.DefaultShader
 	if rs.ActiveShader != nil {
 		base = rs.ActiveShader
@@ -314,262 +327,347 @@ func (rs *RenderSystem) RenderMainPass(entities []*Entity) {
 	// Track which shader is currently bound so we only switch when needed.
 	currentShader := base
 	rs.uploadGlobals(entities, currentShader)
+	frustum := engine.FrustumFromMatrix(proj.Mul4(view))
 	// somewhere right before RenderMainPass loop, hack for entity ID 13:
 
-	// 3) Draw all meshes
-	for _, e := range entities {
-		var t *Transform
-		var mesh *Mesh
-		var mat *Material
-		var normalMapComp *NormalMap
-		var multi *MultiMesh
-		var multiMat *MultiMaterial
-		var hasChildren bool
-		var skin *Skin
-
-		for _, c := range e.Components {
-			switch v := c.(type) {
-			case *Transform:
-				t = v
-			case *Mesh:
-				mesh = v
-			case *Material:
-				mat = v
-			case *NormalMap:
-				normalMapComp = v
-			case *MultiMesh:
-				multi = v
-			case *MultiMaterial:
-				multiMat = v
-			case *Skin:
-				skin = v
-			case *Children:
-				hasChildren = true
-
-			}
+	// 3) Draw the queue: opaque and alpha-tested meshes front to back,
+	// then transparent ones back to front, blended over them without
+	// writing depth. Opaque and alpha-tested meshes sharing a mesh and
+	// material are instanced together.
+	queue := BuildRenderQueue(entities, view, &frustum, &rs.MainCull)
+	passes := [...]struct {
+		items []RenderItem
+		blend bool
+	}{
+		{queue.Opaque, false},
+		{queue.Masked, false},
+		{queue.Transparent, true},
+	}
+	dev := engine.Device
+	for _, pass := range passes {
+		dev.SetBlend(pass.blend)
+		dev.SetDepthWrite(!pass.blend)
+		for _, b := range BatchRenderItems(pass.items, !pass.blend) {
+			currentShader = rs.drawBatch(&b, entities, base, currentShader, view, proj)
 		}
+	}
+	dev.SetBlend(false)
+	dev.SetDepthWrite(true)
+	dev.SetCullFace(false)
+}
 
-		if hasChildren {
-			multi = nil
-			mat = nil
-		}
-		// --- Per-material shader selection (additive) ---
-		if t == nil || mat == nil {
-			continue
-		}
-		resolveMaterialShader(mat)
-		desiredShader := base
-		if mat.Shader != nil {
-			desiredShader = mat.Shader
-		}
-		if skin != nil {
-			sp, err := engine.GetShaderProgram("default_skinned")
-			if err == nil {
-				desiredShader = sp
-			}
+// drawBatch binds the shader, uniforms, textures and cull state of a
+// render batch's first item and draws the batch. It returns the program
+// left bound, so globals are only re-uploaded when the program changes.
+func (rs *RenderSystem) drawBatch(b *RenderBatch, entities []*Entity, base, currentShader *engine.ShaderProgram, view, proj mgl32.Mat4) *engine.ShaderProgram {
+	item := &b.Items[0]
+	e, t, mat := item.Entity, item.Transform, item.Material
+	normalMapComp, skin := item.NormalMap, item.Skin
+
+	desiredShader := base
+	if mat.Shader != nil {
+		desiredShader = mat.Shader
+	}
+	if skin != nil {
+		sp, err := engine.GetShaderVariant("default_shader", "SKINNED")
+		if err == nil {
+			desiredShader = sp
 		}
-		// _ = skin
-		if desiredShader != currentShader {
-			currentShader = desiredShader
-			if currentShader != nil {
-				rs.Renderer.SwitchProgram(currentShader)
-				// After switching program, all uniform locations changed,
-				// so we re-upload the global state once for this shader.
-				rs.uploadGlobals(entities, currentShader)
-			}
+	}
+	// _ = skin
+	if desiredShader != currentShader {
+		currentShader = desiredShader
+		if currentShader != nil {
+			rs.Renderer.SwitchProgram(currentShader)
+			// After switching program, all uniform locations changed,
+			// so we re-upload the global state once for this shader.
+			rs.uploadGlobals(entities, currentShader)
 		}
+	}
 
-		engine.SetMat4(rs.Renderer.LocModel, &t.WorldMatrix[0])
-
-		engine.SetMat4(rs.Renderer.LocView, &view[0])
-		engine.SetMat4(rs.Renderer.LocProj, &proj[0])
-		// --- draw each mesh ---
+	engine.SetMat4(rs.Renderer.LocModel, t.WorldMatrix[:])
 
-		if rs.materialUBO != 0 {
+	engine.SetMat4(rs.Renderer.LocView, view[:])
+	engine.SetMat4(rs.Renderer.LocProj, proj[:])
+	// --- draw each mesh ---
 
-			matType := mat.Type
+	if rs.materialUBO != 0 {
 
-			// Default Blinn/Phong shader
-			if currentShader == rs.DefaultShader {
-				matType = int(MaterialBlinnPhong)
-			}
+		matType := mat.Type
 
-			// PBR shader
-			if sp, err := engine.GetShaderProgram("pbr_shader"); err == nil && currentShader == sp {
-				matType = int(MaterialPBR)
-			}
+		// Default Blinn/Phong shader
+		if currentShader == rs.DefaultShader {
+			matType = int(MaterialBlinnPhong)
+		}
 
-			// Toon shader
-			if sp, err := engine.GetShaderProgram("toon_shader"); err == nil && currentShader == sp {
-				matType = int(MaterialToon)
-			}
+		// PBR shader
+		if sp, err := engine.GetShaderProgram("pbr_shader"); err == nil && currentShader == sp {
+			matType = int(MaterialPBR)
+		}
 
-			m := gpuMaterial{
-				BaseColor:    mat.BaseColor,
-				Ambient:      mat.Ambient,
-				Diffuse:      mat.Diffuse,
-				Specular:     mat.Specular,
-				Shininess:    mat.Shininess,
-				Metallic:     mat.Metallic,
-				Roughness:    mat.Roughness,
-				MaterialType: int32(matType),
-			}
-			m.ClearcoatFactor = mat.ClearcoatFactor
-			m.ClearcoatRoughness = mat.ClearcoatRoughness
-			m.SheenColor = mat.SheenColor
-			m.SheenRoughness = mat.SheenRoughness
-			m.TransmissionFactor = mat.TransmissionFactor
-
-			// Selection highlight: override base color if needed
-			if uint64(e.ID) == rs.SelectedEntity {
-				m.BaseColor = [4]float32{1, 1, 0, 1}
-			}
+		// Toon shader
+		if sp, err := engine.GetShaderProgram("toon_shader"); err == nil && currentShader == sp {
+			matType = int(MaterialToon)
+		}
 
-			gl.BindBuffer(gl.UNIFORM_BUFFER, rs.materialUBO)
-			gl.BufferSubData(gl.UNIFORM_BUFFER, 0, int(unsafe.Sizeof(m)), unsafe.Pointer(&m))
-			gl.BindBuffer(gl.UNIFORM_BUFFER, 0)
+		m := gpuMaterial{
+			BaseColor:    mat.BaseColor,
+			Ambient:      mat.Ambient,
+			Diffuse:      mat.Diffuse,
+			Specular:     mat.Specular,
+			Shininess:    mat.Shininess,
+			Metallic:     mat.Metallic,
+			Roughness:    mat.Roughness,
+			MaterialType: int32(matType),
 		}
+		m.ClearcoatFactor = mat.ClearcoatFactor
+		m.ClearcoatRoughness = mat.ClearcoatRoughness
+		m.SheenColor = mat.SheenColor
+		m.SheenRoughness = mat.SheenRoughness
+		m.TransmissionFactor = mat.TransmissionFactor
 
-		engine.SetVec4fv(rs.Renderer.LocBaseCol, &mat.BaseColor[0])
+		// Selection highlight: override base color if needed
 		if uint64(e.ID) == rs.SelectedEntity {
-			highlight := [4]float32{1, 1, 0, 1}
-			engine.SetVec4fv(rs.Renderer.LocBaseCol, &highlight[0])
-		}
-		engine.SetFloat(rs.Renderer.LocAmbient, mat.Ambient)
-		engine.SetFloat(rs.Renderer.LocDiffuse, mat.Diffuse)
-		engine.SetFloat(rs.Renderer.LocSpecular, mat.Specular)
-		engine.SetFloat(rs.Renderer.LocShininess, mat.Shininess)
-
-		// Diffuse texture
-		if mat.TextureAsset != 0 {
-			textureID := assets.ResolveTextureGLID(assets.AssetID(mat.TextureAsset))
-			gl.ActiveTexture(gl.TEXTURE0)
-			gl.BindTexture(gl.TEXTURE_2D, textureID)
-			engine.SetInt(rs.Renderer.LocDiffuseTex, 0)
-			engine.SetInt(rs.Renderer.LocUseTexture, 1)
-		} else {
-			engine.SetInt(rs.Renderer.LocUseTexture, 0)
-		}
-		if mat.OcclusionAsset != 0 {
-			gl.ActiveTexture(gl.TEXTURE3)
-			gl.BindTexture(gl.TEXTURE_2D, mat.OcclusionID)
-			engine.SetInt(rs.Renderer.LocOcclusionMap, 3)
-			engine.SetInt(rs.Renderer.LocUseOcclusionMap, 1)
-		} else {
-			engine.SetInt(rs.Renderer.LocUseOcclusionMap, 0)
+			m.BaseColor = [4]float32{1, 1, 0, 1}
 		}
 
-		if mat.MetallicRoughnessAsset != 0 {
-			gl.ActiveTexture(gl.TEXTURE4)
-			gl.BindTexture(gl.TEXTURE_2D, mat.MetallicRoughnessID)
-			engine.SetInt(rs.Renderer.LocMetallicRoughnessMap, 4)
-			engine.SetInt(rs.Renderer.LocUseMetallicRoughnessMap, 1)
-		} else {
-			engine.SetInt(rs.Renderer.LocUseMetallicRoughnessMap, 0)
-		}
-		// Simple: only drive baseColor UV for now
-		if rs.Renderer.LocUVScaleBase != -1 {
-			scale := [2]float32{1, 1}
-			if mat.UVScale != nil {
-				if s, ok := mat.UVScale["baseColor"]; ok {
-					scale = s
-				}
+		engine.Device.UpdateBuffer(engine.UniformBuffer, rs.materialUBO, 0, int(unsafe.Sizeof(m)), unsafe.Pointer(&m))
+	}
+
+	engine.SetVec4fv(rs.Renderer.LocBaseCol, mat.BaseColor[:])
+	if uint64(e.ID) == rs.SelectedEntity {
+		highlight := [4]float32{1, 1, 0, 1}
+		engine.SetVec4fv(rs.Renderer.LocBaseCol, highlight[:])
+	}
+	engine.SetFloat(rs.Renderer.LocAmbient, mat.Ambient)
+	engine.SetFloat(rs.Renderer.LocDiffuse, mat.Diffuse)
+	engine.SetFloat(rs.Renderer.LocSpecular, mat.Specular)
+	engine.SetFloat(rs.Renderer.LocShininess, mat.Shininess)
+
+	// Diffuse texture
+	if mat.TextureAsset != 0 {
+		textureID := assets.ResolveTextureGLID(assets.AssetID(mat.TextureAsset))
+		engine.Device.BindTexture(0, engine.Texture2D, textureID)
+		engine.SetInt(rs.Renderer.LocDiffuseTex, 0)
+		engine.SetInt(rs.Renderer.LocUseTexture, 1)
+	} else {
+		engine.SetInt(rs.Renderer.LocUseTexture, 0)
+	}
+	if mat.OcclusionAsset != 0 {
+		engine.Device.BindTexture(3, engine.Texture2D, mat.OcclusionID)
+		engine.SetInt(rs.Renderer.LocOcclusionMap, 3)
+		engine.SetInt(rs.Renderer.LocUseOcclusionMap, 1)
+	} else {
+		engine.SetInt(rs.Renderer.LocUseOcclusionMap, 0)
+	}
+
+	if mat.MetallicRoughnessAsset != 0 {
+		engine.Device.BindTexture(4, engine.Texture2D, mat.MetallicRoughnessID)
+		engine.SetInt(rs.Renderer.LocMetallicRoughnessMap, 4)
+		engine.SetInt(rs.Renderer.LocUseMetallicRoughnessMap, 1)
+	} else {
+		engine.SetInt(rs.Renderer.LocUseMetallicRoughnessMap, 0)
+	}
+	// Simple: only drive baseColor UV for now
+	if rs.Renderer.LocUVScaleBase != -1 {
+		scale := [2]float32{1, 1}
+		if mat.UVScale != nil {
+			if s, ok := mat.UVScale["baseColor"]; ok {
+				scale = s
 			}
-			engine.SetVec2(rs.Renderer.LocUVScaleBase, scale[0], scale[1])
 		}
+		engine.SetVec2(rs.Renderer.LocUVScaleBase, scale[0], scale[1])
+	}
 
-		if rs.Renderer.LocUVOffsetBase != -1 {
-			offset := [2]float32{0, 0}
-			if mat.UVOffset != nil {
-				if o, ok := mat.UVOffset["baseColor"]; ok {
-					offset = o
-				}
+	if rs.Renderer.LocUVOffsetBase != -1 {
+		offset := [2]float32{0, 0}
+		if mat.UVOffset != nil {
+			if o, ok := mat.UVOffset["baseColor"]; ok {
+				offset = o
 			}
-			engine.SetVec2(rs.Renderer.LocUVOffsetBase, offset[0], offset[1])
 		}
+		engine.SetVec2(rs.Renderer.LocUVOffsetBase, offset[0], offset[1])
+	}
 
-		// Normal map
-		if normalMapComp != nil && normalMapComp.ID != 0 && rs.MeshManager.HasTangents(mesh.ID) {
-			gl.ActiveTexture(gl.TEXTURE1)
-			gl.BindTexture(gl.TEXTURE_2D, normalMapComp.ID)
-			engine.SetInt(rs.Renderer.LocNormalMap, 1)
-			engine.SetInt(rs.Renderer.LocUseNormalMap, 1)
-		} else {
-			engine.SetInt(rs.Renderer.LocUseNormalMap, 0)
-		}
-		// --------------------------------------------------------
-		// IBL textures
-		// --------------------------------------------------------
-		if mat.UseIBL {
-			if rs.Renderer.LocUseIBL != -1 {
-				engine.SetInt(rs.Renderer.LocUseIBL, 1)
-			}
+	// Normal map
+	if normalMapComp != nil && normalMapComp.ID != 0 && rs.MeshManager.HasTangents(item.MeshID) {
+		engine.Device.BindTexture(1, engine.Texture2D, normalMapComp.ID)
+		engine.SetInt(rs.Renderer.LocNormalMap, 1)
+		engine.SetInt(rs.Renderer.LocUseNormalMap, 1)
+	} else {
+		engine.SetInt(rs.Renderer.LocUseNormalMap, 0)
+	}
+	// --------------------------------------------------------
+	// IBL textures: a material's own maps win, the scene
+	// environment fills in the rest. IBL stays off unless all
+	// three maps are present.
+	// --------------------------------------------------------
+	irradiance, prefiltered, brdfLUT := mat.IrradianceTex, mat.PrefilteredEnvTex, mat.BRDFLUTTex
+	useIBL, envIntensity := mat.UseIBL, float32(1)
+	if env := rs.Environment; env != nil {
+		useIBL, envIntensity = true, rs.envIntensity
+		if irradiance == 0 {
+			irradiance = env.Irradiance
+		}
+		if prefiltered == 0 {
+			prefiltered = env.Prefiltered
+		}
+		if brdfLUT == 0 {
+			brdfLUT = env.BRDFLUT
+		}
+	}
+	if useIBL && irradiance != 0 && prefiltered != 0 && brdfLUT != 0 {
+		engine.SetInt(rs.Renderer.LocUseIBL, 1)
+		engine.SetFloat(rs.Renderer.LocEnvIntensity, envIntensity)
 
-			// irradiance map → texture unit 10
-			if mat.IrradianceTex != 0 && rs.Renderer.LocIrradianceMap != -1 {
-				gl.ActiveTexture(gl.TEXTURE10)
-				gl.BindTexture(gl.TEXTURE_CUBE_MAP, mat.IrradianceTex)
-				engine.SetInt(rs.Renderer.LocIrradianceMap, 10)
-			}
+		// irradiance map → texture unit 10
+		engine.Device.BindTexture(10, engine.TextureCube, irradiance)
+		engine.SetInt(rs.Renderer.LocIrradianceMap, 10)
 
-			// prefiltered env map → texture unit 11
-			if mat.PrefilteredEnvTex != 0 && rs.Renderer.LocPrefilteredEnv != -1 {
-				gl.ActiveTexture(gl.TEXTURE11)
-				gl.BindTexture(gl.TEXTURE_CUBE_MAP, mat.PrefilteredEnvTex)
-				engine.SetInt(rs.Renderer.LocPrefilteredEnv, 11)
-			}
+		// prefiltered env map → texture unit 11
+		engine.Device.BindTexture(11, engine.TextureCube, prefiltered)
+		engine.SetInt(rs.Renderer.LocPrefilteredEnv, 11)
 
-			// BRDF LUT → texture unit 12
-			if mat.BRDFLUTTex != 0 && rs.Renderer.LocBRDFLUT != -1 {
-				gl.ActiveTexture(gl.TEXTURE12)
-				gl.BindTexture(gl.TEXTURE_2D, mat.BRDFLUTTex)
-				engine.SetInt(rs.Renderer.LocBRDFLUT, 12)
-			}
-		} else {
-			if rs.Renderer.LocUseIBL != -1 {
-				engine.SetInt(rs.Renderer.LocUseIBL, 0)
-			}
-		}
-		// Transmission texture
-		if mat.UseTransmission && mat.TransmissionTex != 0 {
-			gl.ActiveTexture(gl.TEXTURE15)
-			gl.BindTexture(gl.TEXTURE_2D, mat.TransmissionTex)
-			engine.SetInt(rs.Renderer.LocTransmissionTex, 15)
-			engine.SetInt(rs.Renderer.LocUseTransmissionTex, 1)
-		} else {
-			engine.SetInt(rs.Renderer.LocUseTransmissionTex, 0)
+		// BRDF LUT → texture unit 12
+		engine.Device.BindTexture(12, engine.Texture2D, brdfLUT)
+		engine.SetInt(rs.Renderer.LocBRDFLUT, 12)
+	} else {
+		engine.SetInt(rs.Renderer.LocUseIBL, 0)
+		// Keep the cube samplers off unit 0: a samplerCube and a sampler2D
+		// sharing a unit fail validation and the draw is dropped.
+		engine.SetInt(rs.Renderer.LocIrradianceMap, 10)
+		engine.SetInt(rs.Renderer.LocPrefilteredEnv, 11)
+		engine.SetInt(rs.Renderer.LocBRDFLUT, 12)
+	}
+	// Transmission texture
+	if mat.UseTransmission && mat.TransmissionTex != 0 {
+		engine.Device.BindTexture(15, engine.Texture2D, mat.TransmissionTex)
+		engine.SetInt(rs.Renderer.LocTransmissionTex, 15)
+		engine.SetInt(rs.Renderer.LocUseTransmissionTex, 1)
+	} else {
+		engine.SetInt(rs.Renderer.LocUseTransmissionTex, 0)
+	}
+
+	if mat.UseClearcoat && mat.ClearcoatTexture != 0 {
+		engine.Device.BindTexture(13, engine.Texture2D, mat.ClearcoatTexture)
+		engine.SetInt(rs.Renderer.LocClearcoatTex, 13)
+		engine.SetInt(rs.Renderer.LocUseClearcoatTex, 1)
+	} else {
+		engine.SetInt(rs.Renderer.LocUseClearcoatTex, 0)
+	}
+	if skin != nil {
+		// assume skin.JointMatrices already filled as joint * inverseBind
+		loc := engine.Device.UniformLocation(currentShader.ID, "uJointMatrices[0]")
+		if loc != -1 && len(skin.JointMatrices) > 0 {
+			engine.Device.UniformMat4(loc, unsafe.Slice(&skin.JointMatrices[0][0], 16*len(skin.JointMatrices)))
 		}
 
-		if mat.UseClearcoat && mat.ClearcoatTexture != 0 {
-			gl.ActiveTexture(gl.TEXTURE13)
-			gl.BindTexture(gl.TEXTURE_2D, mat.ClearcoatTexture)
-			engine.SetInt(rs.Renderer.LocClearcoatTex, 13)
-			engine.SetInt(rs.Renderer.LocUseClearcoatTex, 1)
-		} else {
-			engine.SetInt(rs.Renderer.LocUseClearcoatTex, 0)
-		}
-		if skin != nil {
-			// assume skin.JointMatrices already filled as joint * inverseBind
-			loc := gl.GetUniformLocation(currentShader.ID, gl.Str("uJointMatrices[0]\x00"))
-			if loc != -1 && len(skin.JointMatrices) > 0 {
-				gl.UniformMatrix4fv(
-					loc,
-					int32(len(skin.JointMatrices)),
-					false,
-					&skin.JointMatrices[0][0],
-				)
+		mode := SkinningLinear
+		if skin.Mode == SkinningDualQuat && len(skin.JointDualQuats) > 0 {
+			mode = SkinningDualQuat
+			dqLoc := engine.Device.UniformLocation(currentShader.ID, "uJointDualQuats[0]")
+			if dqLoc != -1 {
+				engine.Device.UniformVec4(dqLoc, unsafe.Slice(&skin.JointDualQuats[0][0], 8*len(skin.JointDualQuats)))
 			}
 		}
+		if modeLoc := engine.Device.UniformLocation(currentShader.ID, "uSkinningMode"); modeLoc != -1 {
+			engine.Device.UniformInt(modeLoc, int32(mode))
+		}
+	}
 
-		drawItems = drawItems[:0]
-		drawItems = rs.collectMeshes(mesh, multi, mat, multiMat, normalMapComp, drawItems)
+	engine.SetInt(rs.Renderer.LocAlphaMode, int32(mat.AlphaMode))
+	engine.SetFloat(rs.Renderer.LocAlphaCutoff, mat.AlphaCutoff)
+	doubleSided := int32(0)
+	if mat.DoubleSided {
+		doubleSided = 1
+	}
+	engine.SetInt(rs.Renderer.LocDoubleSided, doubleSided)
+	engine.Device.SetCullFace(!mat.DoubleSided)
+	vertexColor := int32(0)
+	if rs.MeshManager.HasVertexColors(item.MeshID) {
+		vertexColor = 1
+	}
+	engine.SetInt(rs.Renderer.LocUseVertexColor, vertexColor)
+
+	if b.Instanced {
+		rs.instanceData = b.AppendInstances(rs.instanceData[:0], rs.SelectedEntity, [4]float32{1, 1, 0, 1})
+		engine.SetInt(rs.Renderer.LocUseInstancing, 1)
+		rs.drawInstanced(item.MeshID, rs.instanceData)
+	} else {
+		engine.SetInt(rs.Renderer.LocUseInstancing, 0)
+		rs.drawMesh(item.MeshID, mat, normalMapComp, 0)
+	}
+	return currentShader
+}
+
+// drawInstanced uploads instances and draws that many copies of a mesh.
+func (rs *RenderSystem) drawInstanced(meshID string, instances []engine.Instance) {
+	if len(instances) == 0 || !rs.MeshManager.AttachInstances(meshID, rs.instances) {
+		return
+	}
+	rs.instances.Upload(instances)
+	rs.drawMesh(meshID, nil, nil, int32(len(instances)))
+}
 
-		for _, item := range drawItems {
-			rs.drawMesh(item.MeshID, item.Material, normalMapComp)
+// resolveEnvironment picks up the scene's Environment component. A path
+// seen for the first time is baked on an assets.Async worker; the previous
+// environment (or none) stays in use until the upload has run in Pump.
+// Loading goes through the asset registry, so scenes sharing an
+// environment share its textures.
+func (rs *RenderSystem) resolveEnvironment(entities []*Entity) {
+	var comp *Environment
+	for _, e := range entities {
+		if c, ok := e.GetComponent((*Environment)(nil)).(*Environment); ok {
+			comp = c
+			break
+		}
+	}
+	if comp == nil || comp.Path == "" {
+		rs.Environment, rs.envPath, rs.envLoad = nil, "", nil
+		return
+	}
+	rs.envIntensity = comp.Intensity
+	if comp.Path != rs.envPath {
+		rs.envPath = comp.Path
+		rs.envLoad = assets.Async.LoadEnvironmentAsync(comp.Path)
+	}
+	f := rs.envLoad
+	if f == nil || !f.Ready() {
+		return
+	}
+	rs.envLoad = nil
+	if err := f.Err(); err != nil {
+		log.Printf("environment %s: %v", comp.Path, err)
+		rs.Environment = nil
+		return
+	}
+	rs.Environment = assets.EnvironmentData(f.ID())
+}
+
+// resolvePostProcess takes the post-processing settings from the active
+// camera's entity, or the defaults when it has no PostProcess.
+func (rs *RenderSystem) resolvePostProcess(entities []*Entity) {
+	rs.postSettings = engine.DefaultPostSettings
+	for _, e := range entities {
+		if cam, ok := e.GetComponent((*Camera)(nil)).(*Camera); ok && cam.Active {
+			if pp, ok := e.GetComponent((*PostProcess)(nil)).(*PostProcess); ok {
+				rs.postSettings = pp.PostSettings
+			}
+			return
 		}
 	}
 }
 
+// Present resolves the frame rendered by Update, plus anything drawn into
+// the HDR target after it, into the default framebuffer. Call it once per
+// frame before overlays that should skip tone mapping.
+func (rs *RenderSystem) Present() {
+	if rs.Post == nil {
+		return
+	}
+	rs.Post.ApplyTo(rs.target, rs.postSettings, rs.frameDt)
+}
+
 func selectMaterialShader(mat *Material) {
 	switch mat.ShaderName {
 	case "pbr_shade":
@@ -581,6 +679,181 @@ func selectMaterialShader(mat *Material) {
 	}
 }
 
+// gatherLights collects this frame's lights with the directional ones
+// first, as the lit shaders expect, plans their shadows, assigns the point
+// and spot lights to the camera's clusters and uploads the result for
+// uploadGlobals to bind.
+func (rs *RenderSystem) gatherLights(entities []*Entity, view, proj mgl32.Mat4) {
+	rs.lights = rs.lights[:0]
+	rs.localLights = rs.localLights[:0]
+	rs.shadowCasters = rs.shadowCasters[:0]
+	var localCasters []int
+
+	for _, e := range entities {
+		lc, ok := e.GetComponent((*LightComponent)(nil)).(*LightComponent)
+		if !ok {
+			continue
+		}
+
+		tr, _ := e.GetComponent((*Transform)(nil)).(*Transform)
+
+		// Defaults
+		dir := [3]float32{0, 0, -1}
+		pos := [3]float32{0, 0, 0}
+
+		if tr != nil {
+			// World space, so lights under a parent (imported glTF nodes)
+			// follow it: the translation column and the rotated -Z axis.
+			m := &tr.WorldMatrix
+			pos = [3]float32{m[12], m[13], m[14]}
+			fwd := mgl32.Vec3{-m[8], -m[9], -m[10]}
+			if fwd.LenSqr() > 0 {
+				fwd = fwd.Normalize()
+				dir = [3]float32{fwd.X(), fwd.Y(), fwd.Z()}
+			}
+		}
+
+		// legacy orbital gizmo light override
+		if rs.LightEntity != nil && e == rs.LightEntity && lc.Type == LightDirectional {
+			dir = rs.LightDir
+		}
+		l := engine.LightData{
+			Type:      int32(lc.Type),
+			Color:     lc.Color,
+			Intensity: lc.Intensity,
+			Direction: dir,
+			Position:  pos,
+			Range:     lc.Range,
+			Angle:     lc.Angle,
+		}
+		casts := lc.CastsShadows && tr != nil
+		if lc.Type == LightDirectional {
+			if casts {
+				rs.shadowCasters = append(rs.shadowCasters, len(rs.lights))
+			}
+			rs.lights = append(rs.lights, l)
+		} else {
+			if casts {
+				localCasters = append(localCasters, len(rs.localLights))
+			}
+			rs.localLights = append(rs.localLights, l)
+		}
+	}
+
+	rs.dirLightCount = len(rs.lights)
+	rs.lights = append(rs.lights, rs.localLights...)
+
+	// Local lights nearest the camera get their shadows first.
+	cam := mgl32.Vec3(rs.CameraSystem.Position)
+	dist := func(i int) float32 { return mgl32.Vec3(rs.localLights[i].Position).Sub(cam).LenSqr() }
+	sort.SliceStable(localCasters, func(a, b int) bool { return dist(localCasters[a]) < dist(localCasters[b]) })
+	for _, i := range localCasters {
+		rs.shadowCasters = append(rs.shadowCasters, rs.dirLightCount+i)
+	}
+	rs.planShadows(view, proj)
+
+	rs.Clusters.Build(view, proj, rs.lights)
+	rs.lightBuffers.Upload(rs.lights, rs.Clusters, rs.ShadowViews, rs.Renderer.ShadowWidth)
+}
+
+// planShadows lays this frame's shadow views out: cascades for the first
+// shadow-casting directional light, then one atlas tile per spot light
+// while room lasts, shrinking tiles down to minShadowTile before giving up
+// on a light. Point lights render the six faces of one cube of the point
+// shadow maps each, up to Shadows.PointShadows.
+func (rs *RenderSystem) planShadows(view, proj mgl32.Mat4) {
+	rs.ShadowViews = rs.ShadowViews[:0]
+	rs.cascadeSplits = [engine.MaxCascades]float32{}
+	rs.cascadeCount = 0
+	if rs.Renderer.ShadowFBO == 0 || rs.Renderer.ShadowProgram == 0 {
+		return
+	}
+	if rs.shadowAtlas == nil || rs.shadowAtlas.Size != rs.Renderer.ShadowWidth {
+		rs.shadowAtlas = engine.NewShadowAtlas(rs.Renderer.ShadowWidth)
+	} else {
+		rs.shadowAtlas.Reset()
+	}
+
+	cfg := rs.Shadows
+	cubes := 0
+	for _, i := range rs.shadowCasters {
+		l := &rs.lights[i]
+		first := len(rs.ShadowViews)
+		pos, dir := mgl32.Vec3(l.Position), mgl32.Vec3(l.Direction)
+
+		switch l.Type {
+		case engine.LightTypeDirectional:
+			if rs.cascadeCount > 0 {
+				continue // one cascaded light per frame
+			}
+			n := cfg.Cascades
+			if n < 1 || n > engine.MaxCascades {
+				n = engine.MaxCascades
+			}
+			tiles := rs.shadowAtlas.AllocateN(n, cfg.CascadeResolution, minShadowTile)
+			if tiles == nil {
+				continue
+			}
+			near, far := engine.PerspectiveDepthRange(proj)
+			if cfg.MaxDistance > 0 {
+				far = min(far, cfg.MaxDistance)
+			}
+			splits := engine.CascadeSplits(near, far, n, cfg.SplitLambda)
+			for c, tile := range tiles {
+				m := engine.FitCascade(view, proj, dir, near, splits[c], tile.Size, cfg.Stabilize, cfg.CasterDistance)
+				rs.ShadowViews = append(rs.ShadowViews, engine.ShadowView{Matrix: m, Tile: tile})
+				rs.cascadeSplits[c] = splits[c]
+				near = splits[c]
+			}
+			rs.cascadeCount = n
+
+		case engine.LightTypeSpot:
+			tiles := rs.shadowAtlas.AllocateN(1, cfg.SpotResolution, minShadowTile)
+			if tiles == nil {
+				continue
+			}
+			m := engine.SpotShadowMatrix(pos, dir, l.Angle, l.Range)
+			rs.ShadowViews = append(rs.ShadowViews, engine.ShadowView{Matrix: m, Tile: tiles[0]})
+
+		case engine.LightTypePoint:
+			if cubes >= cfg.PointShadows || !rs.ensurePointShadows() {
+				continue
+			}
+			near, far := engine.PointShadowRange(l.Range)
+			for f, m := range engine.PointShadowMatrices(pos, l.Range) {
+				rs.ShadowViews = append(rs.ShadowViews, engine.ShadowView{
+					Matrix: m, Cube: true, CubeLayer: cubes, Face: f, Near: near, Far: far,
+				})
+			}
+			cubes++
+		}
+		l.Shadow = int32(first + 1)
+	}
+}
+
+// ensurePointShadows (re)creates the point shadow cube maps when
+// PointResolution or PointShadows change. It reports whether they exist.
+func (rs *RenderSystem) ensurePointShadows() bool {
+	size, layers := rs.Shadows.PointResolution, rs.Shadows.PointShadows
+	if p := rs.pointShadows; p != nil && p.Size == size && p.Layers == layers {
+		return true
+	}
+	if rs.pointShadows != nil {
+		rs.pointShadows.Delete()
+		rs.pointShadows = nil
+	}
+	if size <= 0 || layers <= 0 {
+		return false
+	}
+	p, err := engine.NewPointShadowMaps(size, layers)
+	if err != nil {
+		log.Printf("point shadows: %v", err)
+		return false
+	}
+	rs.pointShadows = p
+	return true
+}
+
 // uploadGlobals binds the current rs.Renderer.Program and uploads
 // shadow map, lights, camera position, lightSpace and debug flags.
 // It assumes rs.Renderer.Program already points to the active shader.
@@ -592,11 +865,10 @@ func (rs *RenderSystem) uploadGlobals(entities []*Entity, shader *engine.ShaderP
 		}
 
 		// Bind shadow map
-		gl.ActiveTexture(gl.TEXTURE2)
-		gl.BindTexture(gl.TEXTURE_2D, rs.Renderer.ShadowTex)
+		engine.Device.BindTexture(2, engine.Texture2D, rs.Renderer.ShadowTex)
 
 		if rs.Renderer.LocShadowMap != -1 {
-			gl.Uniform1i(rs.Renderer.LocShadowMap, 2)
+			engine.SetInt(rs.Renderer.LocShadowMap, 2)
 		}
 		if rs.Renderer.LocShadowMapSize != -1 {
 			engine.SetVec2(
@@ -606,109 +878,42 @@ func (rs *RenderSystem) uploadGlobals(entities []*Entity, shader *engine.ShaderP
 			)
 		}
 
-		// --- Rebuild and upload lights ---
 		rs.Renderer.LightColor = [3]float32{1, 1, 1}
 		rs.Renderer.LightIntensity = 1.0
 
-		lights := make([]engine.LightData, 0, 8)
-		shadowLightIdx := -1
-		var shadowLight *LightComponent
-		var shadowTransform *Transform
-
-		for _, e := range entities {
-			lc, ok := e.GetComponent((*LightComponent)(nil)).(*LightComponent)
-			if !ok {
-				continue
-			}
-
-			tr, _ := e.GetComponent((*Transform)(nil)).(*Transform)
-
-			// Defaults
-			dir := [3]float32{0, 0, -1}
-			pos := [3]float32{0, 0, 0}
-
-			if tr != nil {
-				pos = tr.Position
-
-				q := mgl32.Quat{
-					W: tr.Rotation[3],
-					V: mgl32.Vec3{tr.Rotation[0], tr.Rotation[1], tr.Rotation[2]},
-				}
-				fwd := q.Rotate(mgl32.Vec3{0, 0, -1})
-				dir = [3]float32{fwd.X(), fwd.Y(), fwd.Z()}
-			}
-
-			// legacy orbital gizmo light override
-			if rs.LightEntity != nil && e == rs.LightEntity && lc.Type == LightDirectional {
-				dir = rs.LightDir
-			}
-			idx := len(lights)
-			lights = append(lights, engine.LightData{
-				Type:      int32(lc.Type),
-				Color:     lc.Color,
-				Intensity: lc.Intensity,
-				Direction: dir,
-				Position:  pos,
-				Range:     lc.Range,
-				Angle:     lc.Angle,
-			})
-			if shadowLightIdx == -1 && lc.CastsShadows && (lc.Type == LightDirectional || lc.Type == LightSpot) {
-				shadowLightIdx = idx
-				shadowLight = lc
-				shadowTransform = tr
-			}
-		}
-
-		engine.SetInt(rs.Renderer.LocLightCount, int32(len(lights)))
-		for i, L := range lights {
-			if rs.Renderer.LocLightColor[i] != -1 {
-				engine.SetVec3(rs.Renderer.LocLightColor[i], L.Color[0], L.Color[1], L.Color[2])
-			}
-			if rs.Renderer.LocLightIntensity[i] != -1 {
-				engine.SetFloat(rs.Renderer.LocLightIntensity[i], L.Intensity)
-			}
-			if rs.Renderer.LocLightDir[i] != -1 {
-				engine.SetVec3(rs.Renderer.LocLightDir[i], L.Direction[0], L.Direction[1], L.Direction[2])
-			}
-			if rs.Renderer.LocLightPos[i] != -1 {
-				engine.SetVec3(rs.Renderer.LocLightPos[i], L.Position[0], L.Position[1], L.Position[2])
-			}
-			if rs.Renderer.LocLightRange[i] != -1 {
-				engine.SetFloat(rs.Renderer.LocLightRange[i], L.Range)
-			}
-			if rs.Renderer.LocLightAngle[i] != -1 {
-				engine.SetFloat(rs.Renderer.LocLightAngle[i], L.Angle)
-			}
-			if rs.Renderer.LocLightType[i] != -1 {
-				engine.SetInt(rs.Renderer.LocLightType[i], L.Type)
-			}
-		}
+		// Lights were gathered and clustered once for the frame; every
+		// shader just binds the buffers.
+		rs.lightBuffers.Bind(lightBufferUnit)
+		engine.SetInt(rs.Renderer.LocLightBuffer, lightBufferUnit)
+		engine.SetInt(rs.Renderer.LocClusterGrid, lightBufferUnit+1)
+		engine.SetInt(rs.Renderer.LocClusterLights, lightBufferUnit+2)
+		engine.SetInt(rs.Renderer.LocDirLightCount, int32(rs.dirLightCount))
+		cfg := rs.Clusters.Config
+		engine.SetVec3(rs.Renderer.LocClusterDims, float32(cfg.X), float32(cfg.Y), float32(cfg.Z))
+		engine.SetVec2(rs.Renderer.LocClusterDepth, rs.Clusters.Near, rs.Clusters.SliceScale())
 
 		// Upload camera position
 		camPos := rs.CameraSystem.Position
-		gl.Uniform3fv(rs.Renderer.LocViewPos, 1, &camPos[0])
-
-		// Upload lightSpace + shadow light index
-		if shadowLightIdx >= 0 && shadowLight != nil && shadowTransform != nil {
-			lightSpace, _, ok := rs.computeShadowLightSpace(entities)
-			if ok {
-				//log.Printf("Shadow light index = %d", shadowLightIdx)
-				if rs.Renderer.LocLightSpace != -1 {
-					gl.UniformMatrix4fv(rs.Renderer.LocLightSpace, 1, false, &lightSpace[0])
-				}
-				if rs.Renderer.LocShadowLightIndex != -1 {
-					gl.Uniform1i(rs.Renderer.LocShadowLightIndex, int32(shadowLightIdx))
-				}
-			}
+		engine.SetVec3(rs.Renderer.LocViewPos, camPos[0], camPos[1], camPos[2])
+
+		// Shadow atlas views, cascades and filtering
+		engine.SetInt(rs.Renderer.LocShadowViews, lightBufferUnit+3)
+		engine.SetVec4fv(rs.Renderer.LocCascadeSplits, rs.cascadeSplits[:])
+		engine.SetInt(rs.Renderer.LocCascadeCount, int32(rs.cascadeCount))
+		engine.SetInt(rs.Renderer.LocShadowFilter, int32(rs.Shadows.Filter))
+		engine.SetFloat(rs.Renderer.LocShadowFilterRadius, rs.Shadows.FilterRadius)
+		engine.SetFloat(rs.Renderer.LocShadowLightSize, rs.Shadows.LightSize)
+		if rs.pointShadows != nil {
+			engine.Device.BindTexture(pointShadowUnit, engine.TextureCubeArray, rs.pointShadows.Tex)
 		}
+		engine.SetInt(rs.Renderer.LocPointShadowMaps, pointShadowUnit)
 
 		// Debug flags
 		engine.SetInt(rs.Renderer.LocShowMode, rs.DebugShowMode)
 		engine.SetInt(rs.Renderer.LocFlipNormalG, boolToInt(rs.DebugFlipGreen))
 		if shader.HasMaterialBlock {
-			blockIndex := gl.GetUniformBlockIndex(shader.ID, gl.Str("MaterialBlock\x00"))
-			gl.UniformBlockBinding(shader.ID, blockIndex, rs.materialBinding)
-			gl.BindBufferBase(gl.UNIFORM_BUFFER, rs.materialBinding, rs.materialUBO)
+			engine.Device.BindUniformBlock(shader.ID, "MaterialBlock", rs.materialBinding)
+			engine.Device.BindBufferBase(engine.UniformBuffer, rs.materialBinding, rs.materialUBO)
 		}
 
 	})
@@ -763,12 +968,12 @@ func (rs *RenderSystem) selectShaderForPass(entities []*Entity) {
 
 	// fallback to default renderer program
 	if chosen == nil {
-		gl.UseProgram(rs.Renderer.Program)
+		engine.Device.UseProgram(rs.Renderer.Program)
 		return
 	}
 
 	// bind chosen shader ONCE
-	gl.UseProgram(chosen.ID)
+	engine.Device.UseProgram(chosen.ID)
 	rs.Renderer.Program = chosen.ID
 	rs.Renderer.InitUniforms()
 }
@@ -777,9 +982,8 @@ func (rs *RenderSystem) BindMaterialUBO(sp *engine.ShaderProgram) {
 	if !sp.HasMaterialBlock {
 		return
 	}
-	blockIndex := gl.GetUniformBlockIndex(sp.ID, gl.Str("MaterialBlock\x00"))
-	gl.UniformBlockBinding(sp.ID, blockIndex, rs.materialBinding)
-	gl.BindBufferBase(gl.UNIFORM_BUFFER, rs.materialBinding, rs.materialUBO)
+	engine.Device.BindUniformBlock(sp.ID, "MaterialBlock", rs.materialBinding)
+	engine.Device.BindBufferBase(engine.UniformBuffer, rs.materialBinding, rs.materialUBO)
 }
 
 func (rs *RenderSystem) SetGlobalShader(p *engine.ShaderProgram) {
@@ -787,7 +991,7 @@ func (rs *RenderSystem) SetGlobalShader(p *engine.ShaderProgram) {
 	rs.Renderer.SwitchProgram(p)
 }
 
-func (rs *RenderSystem) collectMeshes(
+func collectMeshes(
 	mesh *Mesh,
 	multi *MultiMesh,
 	mat *Material,
@@ -829,10 +1033,13 @@ func (rs *RenderSystem) collectMeshes(
 	return out
 }
 
+// drawMesh draws one mesh, or instances copies of it with an instanced
+// draw when instances is above 0.
 func (rs *RenderSystem) drawMesh(
 	meshID string,
 	mat *Material,
 	normalMap *NormalMap,
+	instances int32,
 ) {
 	vao := rs.MeshManager.GetVAO(meshID)
 	if vao == 0 {
@@ -844,57 +1051,54 @@ func (rs *RenderSystem) drawMesh(
 	vertexCount := rs.MeshManager.GetVertexCount(meshID)
 	ebo := rs.MeshManager.GetEBO(meshID)
 
-	gl.BindVertexArray(vao)
+	dev := engine.Device
+	dev.BindVertexArray(vao)
+
+	prim := meshPrimitive(meshID)
+	if prim == engine.Lines {
+		dev.SetPolygonMode(engine.PolygonLine)
+		defer dev.SetPolygonMode(engine.PolygonFill)
+	}
 
 	if indexCount > 0 {
 		if ebo == 0 {
 			log.Printf("SKIP indexed draw: mesh=%s has indexCount=%d but no EBO", meshID, indexCount)
-			gl.BindVertexArray(0)
+			dev.BindVertexArray(0)
 			return
 		}
 
-		bytesPerIndex := int32(4)
-		if indexType == gl.UNSIGNED_SHORT {
-			bytesPerIndex = 2
-		}
-
-		var eboSize int32
-		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-		gl.GetBufferParameteriv(gl.ELEMENT_ARRAY_BUFFER, gl.BUFFER_SIZE, &eboSize)
-
-		required := int32(indexCount) * bytesPerIndex
+		eboSize := dev.BufferSize(engine.ElementBuffer, ebo)
+		required := int(indexCount) * indexType.Size()
 		if eboSize < required {
 			log.Printf("SKIP draw: mesh=%s indexCount=%d (%d bytes) but EBO size=%d bytes",
 				meshID, indexCount, required, eboSize)
-			gl.BindVertexArray(0)
+			dev.BindVertexArray(0)
 			return
 		}
 
-		if meshID == "line" {
-			gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
-			gl.DrawElements(gl.LINES, indexCount, indexType, gl.PtrOffset(0))
-			gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+		if instances > 0 {
+			dev.DrawIndexedInstanced(prim, indexCount, indexType, instances)
 		} else {
-			gl.DrawElements(gl.TRIANGLES, indexCount, indexType, gl.PtrOffset(0))
+			dev.DrawIndexed(prim, indexCount, indexType)
 		}
 	} else {
-		if meshID == "line" {
-			gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
-			gl.DrawArrays(gl.LINES, 0, vertexCount)
-			gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+		if instances > 0 {
+			dev.DrawArraysInstanced(prim, 0, vertexCount, instances)
 		} else {
-			gl.DrawArrays(gl.TRIANGLES, 0, vertexCount)
+			dev.DrawArrays(prim, 0, vertexCount)
 		}
 	}
-	/*
-		if indexCount > 0 && ebo != 0 {
-			gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-			gl.DrawElements(gl.TRIANGLES, indexCount, indexType, gl.PtrOffset(0))
-		} else {
-			gl.DrawArrays(gl.TRIANGLES, 0, vertexCount)
-		}*/
 
-	gl.BindVertexArray(0)
+	dev.BindVertexArray(0)
+}
+
+// meshPrimitive is the primitive a mesh is drawn with; the built-in "line"
+// mesh is the only line list.
+func meshPrimitive(meshID string) engine.Primitive {
+	if meshID == "line" {
+		return engine.Lines
+	}
+	return engine.Triangles
 }
 
 func (rs *RenderSystem) collectShadowMeshes(mesh *Mesh, multi *MultiMesh, out []string) []string {
@@ -907,3 +1111,17 @@ func (rs *RenderSystem) collectShadowMeshes(mesh *Mesh, multi *MultiMesh, out []
 	}
 	return out
 }
+
+// cullTest reports whether t's world bounds touch the frustum, counting the
+// test in stats. Entities without bounds always pass and are not counted.
+func cullTest(f *engine.Frustum, t *Transform, stats *engine.CullStats) bool {
+	if !t.HasBounds {
+		return true
+	}
+	stats.Tested++
+	if !f.IntersectsSphere(t.WorldSphere) || !f.IntersectsAABB(t.WorldBounds) {
+		return false
+	}
+	stats.Visible++
+	return true
+}
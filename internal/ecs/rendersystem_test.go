@@ -0,0 +1,656 @@
+package ecs
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"go-engine/Go-Cordance/internal/assets"
+	"go-engine/Go-Cordance/internal/engine"
+)
+
+// quadMesh is a unit quad in the XY plane.
+func quadMesh(id string) engine.MeshData {
+	v := func(x, y float32) []float32 { return []float32{x, y, 0, 0, 0, 1, 0, 0, 1, 0, 0, 1} }
+	var verts []float32
+	for _, p := range [][2]float32{{-0.5, -0.5}, {0.5, -0.5}, {0.5, 0.5}, {-0.5, 0.5}} {
+		verts = append(verts, v(p[0], p[1])...)
+	}
+	return engine.MeshData{ID: id, Vertices: verts, Indices: []uint32{0, 1, 2, 0, 2, 3}}
+}
+
+// newRecordedRenderSystem builds a RenderSystem whose renderer and meshes
+// live on a RecordingDevice. Extra programs are registered first, for the
+// duration of the test.
+func newRecordedRenderSystem(t *testing.T, programs ...string) (*RenderSystem, *engine.RecordingDevice) {
+	t.Helper()
+	dev := engine.NewRecordingDevice()
+	prevDev, prevMM := engine.Device, engine.GlobalMeshManager
+	engine.Device = dev
+	t.Cleanup(func() { engine.Device, engine.GlobalMeshManager = prevDev, prevMM })
+
+	mm := engine.NewMeshManager()
+	engine.GlobalMeshManager = mm
+	mm.RegisterMeshData(quadMesh("quad"))
+
+	sp, err := engine.LoadShaderProgram("default_shader", "vs", "fs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.RegisterShaderProgram("default_shader", sp)
+	for _, name := range programs {
+		p, err := engine.LoadShaderProgram(name, "vs", "fs")
+		if err != nil {
+			t.Fatal(err)
+		}
+		engine.RegisterShaderProgram(name, p)
+		t.Cleanup(func() { engine.UnregisterShaderProgram(name) })
+	}
+
+	cam := &CameraSystem{
+		View:       mgl32.LookAtV(mgl32.Vec3{0, 0, 5}, mgl32.Vec3{}, mgl32.Vec3{0, 1, 0}),
+		Projection: mgl32.Perspective(mgl32.DegToRad(60), 1, 0.1, 100),
+	}
+	r := engine.NewRendererWithProgram(sp.ID, 64, 64)
+	return NewRenderSystem(r, mm, cam), dev
+}
+
+func quadEntity(id int64, pos [3]float32, color [4]float32) *Entity {
+	e := NewEntity(id)
+	e.AddComponent(NewTransform(pos))
+	e.AddComponent(NewMesh("quad"))
+	e.AddComponent(NewMaterial(color))
+	return e
+}
+
+func TestRenderSystem_VertexColors(t *testing.T) {
+	rs, dev := newRecordedRenderSystem(t)
+	tinted := quadMesh("tinted")
+	tinted.Colors = [][4]float32{{1, 0, 0, 1}, {0, 1, 0, 1}, {0, 0, 1, 1}, {1, 1, 1, 1}}
+	rs.MeshManager.RegisterMeshData(tinted)
+
+	colorAttrib := false
+	for _, c := range dev.Pending().Commands {
+		if c.Op == "VertexAttrib" && c.ID == rs.MeshManager.GetVAO("tinted") && c.Args[0] == uint32(engine.VertexColorAttrib) {
+			colorAttrib = true
+		}
+	}
+	if !colorAttrib {
+		t.Fatal("vertex colours not bound at VertexColorAttrib")
+	}
+
+	plain := quadEntity(1, [3]float32{-1, 0, 0}, [4]float32{1, 1, 1, 1})
+	colored := quadEntity(2, [3]float32{1, 0, 0}, [4]float32{1, 1, 1, 1})
+	colored.GetComponent((*Mesh)(nil)).(*Mesh).ID = "tinted"
+	entities := []*Entity{plain, colored}
+	NewTransformSystem().Update(0, entities)
+	dev.EndFrame()
+
+	rs.Update(0, entities)
+	frame := dev.EndFrame()
+	if len(frame.Draws) != 2 {
+		t.Fatalf("got %d draws, want 2", len(frame.Draws))
+	}
+	if got := frame.Draws[0].Uniforms["useVertexColor"]; got != int32(0) {
+		t.Errorf("plain quad: useVertexColor = %v", got)
+	}
+	if got := frame.Draws[1].Uniforms["useVertexColor"]; got != int32(1) {
+		t.Errorf("tinted quad: useVertexColor = %v", got)
+	}
+}
+
+func TestRenderSystem_RecordsDrawsInEntityOrder(t *testing.T) {
+	rs, dev := newRecordedRenderSystem(t)
+
+	entities := []*Entity{
+		quadEntity(1, [3]float32{-1, 0, 0}, [4]float32{1, 0, 0, 1}),
+		quadEntity(2, [3]float32{0, 0, 50}, [4]float32{0, 1, 0, 1}), // behind the camera
+		quadEntity(3, [3]float32{1, 0, 0}, [4]float32{0, 0, 1, 1}),
+	}
+	NewTransformSystem().Update(0, entities)
+	dev.EndFrame()
+
+	rs.Update(0, entities)
+	frame := dev.EndFrame()
+
+	if rs.MainCull.Tested != 3 || rs.MainCull.Visible != 2 {
+		t.Fatalf("main pass cull stats %+v, want 3 tested / 2 visible", rs.MainCull)
+	}
+	if len(frame.Draws) != 2 {
+		for _, c := range frame.Commands {
+			t.Log(c)
+		}
+		t.Fatalf("got %d draws, want 2", len(frame.Draws))
+	}
+
+	vao := rs.MeshManager.GetVAO("quad")
+	for i, want := range []*Entity{entities[0], entities[2]} {
+		d := frame.Draws[i]
+		if !d.Indexed || d.Primitive != engine.Triangles || d.Count != 6 || d.VertexArray != vao {
+			t.Errorf("draw %d: %+v", i, d)
+		}
+		if !d.DepthTest || d.Framebuffer != 0 || d.Viewport != [4]int32{0, 0, 64, 64} {
+			t.Errorf("draw %d: state depth=%v fbo=%d viewport=%v", i, d.DepthTest, d.Framebuffer, d.Viewport)
+		}
+		if got := d.Uniforms["model"]; got != want.GetTransform().WorldMatrix {
+			t.Errorf("draw %d: model = %v, want entity %d", i, got, want.ID)
+		}
+		if got := d.Uniforms["BaseColor"]; got != want.GetComponent((*Material)(nil)).(*Material).BaseColor {
+			t.Errorf("draw %d: BaseColor = %v", i, got)
+		}
+		if got := d.Uniforms["view"]; got != [16]float32(rs.CameraSystem.View) {
+			t.Errorf("draw %d: view = %v", i, got)
+		}
+	}
+}
+
+func TestRenderSystem_LineMeshDrawsAsLines(t *testing.T) {
+	rs, dev := newRecordedRenderSystem(t)
+	rs.MeshManager.RegisterMeshData(engine.MeshData{
+		ID:       "line",
+		Vertices: quadMesh("").Vertices[:24],
+		Indices:  []uint32{0, 1},
+	})
+
+	e := NewEntity(1)
+	e.AddComponent(NewTransform([3]float32{}))
+	e.AddComponent(NewMesh("line"))
+	e.AddComponent(NewMaterial([4]float32{1, 1, 1, 1}))
+	NewTransformSystem().Update(0, []*Entity{e})
+	dev.EndFrame()
+
+	rs.Update(0, []*Entity{e})
+	frame := dev.EndFrame()
+
+	if len(frame.Draws) != 1 {
+		t.Fatalf("got %d draws, want 1", len(frame.Draws))
+	}
+	if d := frame.Draws[0]; d.Primitive != engine.Lines || d.PolygonMode != engine.PolygonLine || d.Count != 2 {
+		t.Fatalf("line draw %+v", d)
+	}
+	var last engine.Command
+	for _, c := range frame.Commands {
+		if c.Op == "SetPolygonMode" {
+			last = c
+		}
+	}
+	if last.Op == "" || last.Args[0] != engine.PolygonFill {
+		t.Fatalf("polygon mode not restored, last change %v", last)
+	}
+}
+
+func TestRenderSystem_AlphaPassState(t *testing.T) {
+	rs, dev := newRecordedRenderSystem(t)
+
+	entities := []*Entity{
+		alphaEntity(1, 1, engine.AlphaBlend),
+		alphaEntity(2, -2, engine.AlphaBlend),
+		alphaEntity(3, 0, engine.AlphaMask),
+		alphaEntity(4, -1, engine.AlphaOpaque),
+	}
+	leaf := entities[2].GetComponent((*Material)(nil)).(*Material)
+	leaf.DoubleSided = true
+	leaf.AlphaCutoff = 0.3
+	NewTransformSystem().Update(0, entities)
+	dev.EndFrame()
+
+	rs.Update(0, entities)
+	frame := dev.EndFrame()
+
+	if len(frame.Draws) != 4 {
+		t.Fatalf("got %d draws, want 4", len(frame.Draws))
+	}
+	want := []struct {
+		id                          int64
+		blend, depthWrite, cullFace bool
+		mode                        engine.AlphaMode
+	}{
+		{4, false, true, true, engine.AlphaOpaque},
+		{3, false, true, false, engine.AlphaMask},
+		{2, true, false, true, engine.AlphaBlend},
+		{1, true, false, true, engine.AlphaBlend},
+	}
+	for i, w := range want {
+		d := frame.Draws[i]
+		e := entities[w.id-1]
+		if got := d.Uniforms["model"]; got != e.GetTransform().WorldMatrix {
+			t.Errorf("draw %d is not entity %d", i, w.id)
+		}
+		if d.Blend != w.blend || d.DepthWrite != w.depthWrite || d.CullFace != w.cullFace {
+			t.Errorf("draw %d: blend=%v depthWrite=%v cull=%v", i, d.Blend, d.DepthWrite, d.CullFace)
+		}
+		if got := d.Uniforms["alphaMode"]; got != int32(w.mode) {
+			t.Errorf("draw %d: alphaMode = %v", i, got)
+		}
+	}
+	if got := frame.Draws[1].Uniforms["alphaCutoff"]; got != float32(0.3) {
+		t.Errorf("masked draw alphaCutoff = %v", got)
+	}
+	if got := frame.Draws[1].Uniforms["doubleSided"]; got != int32(1) {
+		t.Errorf("masked draw doubleSided = %v", got)
+	}
+}
+
+func TestRenderSystem_InstancesSharedMeshes(t *testing.T) {
+	rs, dev := newRecordedRenderSystem(t)
+
+	stone := NewMaterial([4]float32{0.5, 0.5, 0.5, 1})
+	grass := NewEntity(4)
+	grass.AddComponent(NewTransform([3]float32{}))
+	grass.AddComponent(stone)
+	inst := NewMeshInstancer("quad")
+	inst.Extent = [3]float32{200, 0, 2}
+	inst.Count = 400
+	inst.Scatter()
+	grass.AddComponent(inst)
+	entities := []*Entity{
+		sharedQuad(1, -1, stone),
+		sharedQuad(2, 1, stone),
+		quadEntity(3, [3]float32{0, 1, 0}, [4]float32{1, 0, 0, 1}),
+		grass,
+	}
+	NewTransformSystem().Update(0, entities)
+	dev.EndFrame()
+
+	rs.Update(0, entities)
+	frame := dev.EndFrame()
+
+	if len(frame.Draws) != 2 {
+		t.Fatalf("got %d draws, want 2", len(frame.Draws))
+	}
+	batch, single := frame.Draws[0], frame.Draws[1]
+	// Most of the 200-unit-wide strip of grass is off screen.
+	visible := rs.MainCull.Visible - 3
+	if visible <= 0 || visible >= inst.Count || rs.MainCull.Tested != inst.Count+3 {
+		t.Fatalf("cull stats %+v for %d instances", rs.MainCull, inst.Count)
+	}
+	if batch.Instances != int32(2+visible) || batch.Uniforms["useInstancing"] != int32(1) {
+		t.Errorf("instanced draw of %d (useInstancing %v), want %d", batch.Instances, batch.Uniforms["useInstancing"], 2+visible)
+	}
+	if single.Instances != 1 || single.Uniforms["useInstancing"] != int32(0) {
+		t.Errorf("plain draw: %d instances, useInstancing %v", single.Instances, single.Uniforms["useInstancing"])
+	}
+
+	divisors := 0
+	for _, c := range frame.Commands {
+		if c.Op == "VertexAttribDivisor" {
+			divisors++
+		}
+	}
+	if divisors != 5 {
+		t.Errorf("set %d attribute divisors, want 5 (model columns and colour)", divisors)
+	}
+	rs.Update(0, entities)
+	for _, c := range dev.EndFrame().Commands {
+		if c.Op == "VertexAttribDivisor" {
+			t.Fatal("instance attributes attached again on the second frame")
+		}
+	}
+}
+
+func TestRenderSystem_ClustersManyLights(t *testing.T) {
+	rs, dev := newRecordedRenderSystem(t)
+
+	entities := []*Entity{quadEntity(1, [3]float32{0, 0, 0}, [4]float32{1, 1, 1, 1})}
+	const points = 300
+	for i := 0; i < points; i++ {
+		e := NewEntity(int64(100 + i))
+		e.AddComponent(NewTransform([3]float32{float32(i%20) - 10, float32(i/20) - 7, -float32(i % 7)}))
+		lc := NewLightComponent()
+		lc.Type = LightPoint
+		lc.Range = 2
+		e.AddComponent(lc)
+		entities = append(entities, e)
+	}
+	// Listed last, but the shaders expect directional lights first.
+	sun := NewEntity(99)
+	sun.AddComponent(NewTransform([3]float32{}))
+	sun.AddComponent(NewLightComponent())
+	entities = append(entities, sun)
+
+	NewTransformSystem().Update(0, entities)
+	dev.EndFrame()
+	rs.Update(0, entities)
+	frame := dev.EndFrame()
+
+	if len(frame.Draws) != 1 {
+		t.Fatalf("got %d draws, want 1", len(frame.Draws))
+	}
+	d := frame.Draws[0]
+	if got := d.Uniforms["dirLightCount"]; got != int32(1) {
+		t.Errorf("dirLightCount = %v, want 1", got)
+	}
+	for unit := uint32(lightBufferUnit); unit < lightBufferUnit+3; unit++ {
+		if d.Textures[unit] == 0 {
+			t.Errorf("no light buffer bound to unit %d", unit)
+		}
+	}
+
+	lightBytes := 0
+	for _, c := range frame.Commands {
+		if c.Op == "ResizeBuffer" && lightBytes == 0 {
+			lightBytes = c.Args[1].(int)
+		}
+	}
+	if want := (points + 1) * 16 * 4; lightBytes != want {
+		t.Errorf("light buffer is %d bytes, want %d", lightBytes, want)
+	}
+
+	if len(rs.Clusters.Indices) == 0 {
+		t.Fatal("no point light was assigned to a cluster")
+	}
+	for _, i := range rs.Clusters.Indices {
+		if i == 0 || i > points {
+			t.Fatalf("cluster lists light %d; want point lights 1..%d", i, points)
+		}
+	}
+}
+
+func TestRenderSystem_ChildLightUsesWorldTransform(t *testing.T) {
+	rs, dev := newRecordedRenderSystem(t)
+
+	// A parent moved to (5,0,0) and turned 90 degrees about Y, with a spot
+	// light child one unit down its local -Z.
+	parent := NewEntity(2)
+	pt := NewTransform([3]float32{5, 0, 0})
+	q := mgl32.QuatRotate(math.Pi/2, mgl32.Vec3{0, 1, 0})
+	pt.Rotation = [4]float32{q.V[0], q.V[1], q.V[2], q.W}
+	parent.AddComponent(pt)
+
+	child := NewEntity(3)
+	child.AddComponent(NewTransform([3]float32{0, 0, -1}))
+	lc := NewLightComponent()
+	lc.Type = LightSpot
+	child.AddComponent(lc)
+	child.AddComponent(NewParent(parent))
+	children := NewChildren()
+	children.AddChild(child)
+	parent.AddComponent(children)
+
+	entities := []*Entity{quadEntity(1, [3]float32{}, [4]float32{1, 1, 1, 1}), parent, child}
+	NewTransformSystem().Update(0, entities)
+	dev.EndFrame()
+	rs.Update(0, entities)
+	dev.EndFrame()
+
+	if len(rs.lights) != 1 {
+		t.Fatalf("got %d lights, want 1", len(rs.lights))
+	}
+	l := rs.lights[0]
+	near := func(a, b [3]float32) bool { return mgl32.Vec3(a).Sub(mgl32.Vec3(b)).Len() < 1e-4 }
+	if want := [3]float32{4, 0, 0}; !near(l.Position, want) {
+		t.Errorf("position = %v, want %v", l.Position, want)
+	}
+	if want := [3]float32{-1, 0, 0}; !near(l.Direction, want) {
+		t.Errorf("direction = %v, want %v", l.Direction, want)
+	}
+}
+
+func TestRenderSystem_PlansShadowAtlas(t *testing.T) {
+	rs, dev := newRecordedRenderSystem(t)
+	shadow, err := engine.LoadShaderProgram("shadow_shader", "vs", "fs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs.Renderer.InitShadowWithProgram(shadow.ID, 4096, 4096)
+
+	light := func(id int64, typ LightType, pos [3]float32) *Entity {
+		e := NewEntity(id)
+		e.AddComponent(NewTransform(pos))
+		lc := NewLightComponent()
+		lc.Type = typ
+		lc.CastsShadows = true
+		e.AddComponent(lc)
+		return e
+	}
+	entities := []*Entity{
+		quadEntity(1, [3]float32{0, 0, 0}, [4]float32{1, 1, 1, 1}),
+		light(2, LightPoint, [3]float32{0, 0, 20}), // farthest from the camera
+		light(3, LightSpot, [3]float32{0, 0, 3}),
+		light(4, LightDirectional, [3]float32{}),
+	}
+	NewTransformSystem().Update(0, entities)
+	dev.EndFrame()
+	rs.Update(0, entities)
+	frame := dev.EndFrame()
+
+	// Four cascades, then the nearer spot light, then six point faces.
+	if len(rs.ShadowViews) != 4+1+6 {
+		t.Fatalf("planned %d shadow views, want 11", len(rs.ShadowViews))
+	}
+	for i, v := range rs.ShadowViews {
+		if point := i >= 5; v.Cube != point || (point && (v.CubeLayer != 0 || v.Face != i-5)) {
+			t.Errorf("view %d: cube=%v layer=%d face=%d", i, v.Cube, v.CubeLayer, v.Face)
+		}
+	}
+	for i, want := range []int32{1, 6, 5} { // sun, point, spot
+		if got := rs.lights[i].Shadow; got != want {
+			t.Errorf("light %d (type %d) shadow = %d, want %d", i, rs.lights[i].Type, got, want)
+		}
+	}
+
+	var shadowDraws int
+	for _, d := range frame.Draws {
+		if d.Framebuffer != rs.Renderer.ShadowFBO {
+			continue
+		}
+		shadowDraws++
+		if d.Viewport[2] != d.Viewport[3] || d.Viewport[2] < minShadowTile {
+			t.Errorf("shadow draw into viewport %v, want a square atlas tile", d.Viewport)
+		}
+	}
+	if shadowDraws == 0 {
+		t.Fatal("quad was not drawn into any shadow view")
+	}
+
+	main := frame.Draws[len(frame.Draws)-1]
+	if main.Framebuffer != 0 || main.Uniforms["cascadeCount"] != int32(4) {
+		t.Fatalf("main draw fbo=%d cascadeCount=%v", main.Framebuffer, main.Uniforms["cascadeCount"])
+	}
+	if main.Textures[lightBufferUnit+3] == 0 {
+		t.Error("shadow view buffer not bound")
+	}
+	if rs.pointShadows == nil || main.Textures[pointShadowUnit] != rs.pointShadows.Tex ||
+		main.Uniforms["pointShadowMaps"] != int32(pointShadowUnit) {
+		t.Error("point shadow cube maps not bound")
+	}
+}
+
+func TestRenderSystem_SceneEnvironment(t *testing.T) {
+	rs, dev := newRecordedRenderSystem(t)
+
+	// Register a small baked environment under the component's path, as
+	// assets.ImportEnvironment would after loading it.
+	img := &engine.HDRImage{Width: 8, Height: 4, Pix: make([]float32, 3*8*4)}
+	for i := range img.Pix {
+		img.Pix[i] = 0.5
+	}
+	env := engine.BakeEnvironment(img, engine.EnvironmentSettings{
+		IrradianceSize: 2, SpecularSize: 4, Samples: 4, LUTSize: 4, LUTSamples: 4,
+	}).Upload()
+	const path = "test/sky.hdr"
+	id := assets.Register(assets.AssetEnvironment, path, env)
+	t.Cleanup(func() { assets.Unregister(id) })
+
+	skyEnv := NewEnvironment(path)
+	skyEnv.Intensity = 0.25
+	sky := NewEntity(2)
+	sky.AddComponent(skyEnv)
+	entities := []*Entity{quadEntity(1, [3]float32{0, 0, 0}, [4]float32{1, 1, 1, 1}), sky}
+
+	NewTransformSystem().Update(0, entities)
+	dev.EndFrame()
+	rs.Update(0, entities)
+	frame := dev.EndFrame()
+
+	if rs.Environment != env {
+		t.Fatal("scene environment not resolved from the asset registry")
+	}
+	d := frame.Draws[len(frame.Draws)-1]
+	if d.Uniforms["useIBL"] != int32(1) || d.Uniforms["envIntensity"] != float32(0.25) {
+		t.Fatalf("useIBL=%v envIntensity=%v", d.Uniforms["useIBL"], d.Uniforms["envIntensity"])
+	}
+	for unit, want := range map[uint32]uint32{10: env.Irradiance, 11: env.Prefiltered, 12: env.BRDFLUT} {
+		if d.Textures[unit] != want {
+			t.Errorf("unit %d bound %d, want %d", unit, d.Textures[unit], want)
+		}
+	}
+
+	// A new path bakes on a worker; the old environment stays until the
+	// upload has been pumped.
+	prevSettings := engine.DefaultEnvironmentSettings
+	engine.DefaultEnvironmentSettings = engine.EnvironmentSettings{
+		IrradianceSize: 2, SpecularSize: 4, Samples: 4, LUTSize: 4, LUTSamples: 4,
+	}
+	t.Cleanup(func() { engine.DefaultEnvironmentSettings = prevSettings })
+	dusk := filepath.Join(t.TempDir(), "dusk.hdr")
+	hdr := []byte("#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n-Y 2 +X 4\n")
+	for i := 0; i < 8; i++ {
+		hdr = append(hdr, 128, 64, 32, 129)
+	}
+	if err := os.WriteFile(dusk, hdr, 0644); err != nil {
+		t.Fatal(err)
+	}
+	skyEnv.Path = dusk
+	rs.Update(0, entities)
+	dev.EndFrame()
+	if rs.Environment != env {
+		t.Fatal("environment replaced before the new one was baked")
+	}
+	assets.Async.WaitAll()
+	rs.Update(0, entities)
+	dev.EndFrame()
+	if rs.Environment == nil || rs.Environment == env {
+		t.Fatal("baked environment not picked up after Pump")
+	}
+	if a := assets.FindAssetByPath(dusk); a != nil {
+		t.Cleanup(func() { assets.Unregister(a.ID) })
+	}
+
+	// Without the component the material is lit by direct lights only.
+	rs.Update(0, entities[:1])
+	frame = dev.EndFrame()
+	if d := frame.Draws[len(frame.Draws)-1]; d.Uniforms["useIBL"] != int32(0) || rs.Environment != nil {
+		t.Errorf("environment still applied after removal: useIBL=%v", d.Uniforms["useIBL"])
+	}
+}
+
+func TestRenderSystem_PostProcessChain(t *testing.T) {
+	rs, dev := newRecordedRenderSystem(t, engine.PostShaders...)
+	if rs.Post == nil {
+		t.Fatal("post stack not created with its shaders loaded")
+	}
+	post := map[uint32]string{}
+	for _, name := range engine.PostShaders {
+		post[engine.MustGetShaderProgram(name).ID] = name
+	}
+
+	camEnt := NewEntity(2)
+	camEnt.AddComponent(NewCamera())
+	pp := NewPostProcess()
+	pp.Tonemap = engine.TonemapFilmic
+	pp.Bloom = true
+	camEnt.AddComponent(pp)
+	entities := []*Entity{quadEntity(1, [3]float32{0, 0, 0}, [4]float32{1, 1, 1, 1}), camEnt}
+
+	NewTransformSystem().Update(0, entities)
+	dev.EndFrame()
+	rs.Update(1.0/60, entities)
+	rs.Present()
+	frame := dev.EndFrame()
+
+	var scene, tonemap, last engine.DrawCall
+	for _, d := range frame.Draws {
+		switch post[d.Program] {
+		case "":
+			scene = d
+		case "post_tonemap_shader":
+			tonemap = d
+		}
+		last = d
+	}
+	if scene.Framebuffer != rs.Post.HDRFramebuffer() || scene.Framebuffer == 0 {
+		t.Errorf("scene drew to framebuffer %d, want the HDR target", scene.Framebuffer)
+	}
+	if tonemap.Uniforms["tonemapper"] != int32(engine.TonemapFilmic) || tonemap.Uniforms["useBloom"] != int32(1) {
+		t.Errorf("tonemap uniforms: tonemapper=%v useBloom=%v", tonemap.Uniforms["tonemapper"], tonemap.Uniforms["useBloom"])
+	}
+	if last.Framebuffer != 0 || post[last.Program] != "post_fxaa_shader" {
+		t.Errorf("last draw %q to framebuffer %d, want FXAA to the screen", post[last.Program], last.Framebuffer)
+	}
+
+	// A camera without PostProcess gets the defaults: no bloom.
+	camEnt.RemoveComponent(pp)
+	rs.Update(1.0/60, entities)
+	rs.Present()
+	for _, d := range dev.EndFrame().Draws {
+		if post[d.Program] == "post_bloom_down_shader" {
+			t.Fatal("bloom ran without a PostProcess component")
+		}
+	}
+}
+
+func TestRenderSystem_CaptureOffscreen(t *testing.T) {
+	rs, dev := newRecordedRenderSystem(t, engine.PostShaders...)
+	entities := []*Entity{quadEntity(1, [3]float32{0, 0, 0}, [4]float32{1, 1, 1, 1})}
+	NewTransformSystem().Update(0, entities)
+	if _, err := rs.Capture(entities, nil, 320, 180); err == nil {
+		t.Fatal("captured without a camera")
+	}
+
+	camEnt := NewEntity(2)
+	cam := NewCamera()
+	cam.Position, cam.Target = [3]float32{0, 0, 5}, [3]float32{}
+	camEnt.AddComponent(cam)
+	entities = append(entities, camEnt)
+	view := rs.CameraSystem.View
+	dev.EndFrame()
+
+	img, err := rs.Capture(entities, nil, 320, 180)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 320 || b.Dy() != 180 || img.Pix[3] != 255 {
+		t.Fatalf("captured %v, alpha %d", b, img.Pix[3])
+	}
+	frame := dev.EndFrame()
+
+	var target uint32
+	var read, deleted bool
+	for _, c := range frame.Commands {
+		switch c.Op {
+		case "CreateFramebuffer":
+			target = c.ID
+		case "ReadPixels":
+			read = c.ID == target && c.Args[2] == int32(320) && c.Args[3] == int32(180)
+		case "DeleteFramebuffer":
+			deleted = c.ID == target
+		}
+	}
+	if !read || !deleted {
+		t.Errorf("capture target %d: read back %v, deleted %v", target, read, deleted)
+	}
+	scene, last := frame.Draws[0], frame.Draws[len(frame.Draws)-1]
+	if scene.Framebuffer != rs.Post.HDRFramebuffer() || scene.Viewport != [4]int32{0, 0, 320, 180} {
+		t.Errorf("scene drew to %d with viewport %v", scene.Framebuffer, scene.Viewport)
+	}
+	if p := scene.Uniforms["projection"].([16]float32); math.Abs(float64(p[5]/p[0])-320.0/180) > 1e-4 {
+		t.Errorf("projection aspect %v, want the image's", p[5]/p[0])
+	}
+	if last.Framebuffer != target || last.Viewport != [4]int32{0, 0, 320, 180} {
+		t.Errorf("post chain resolved to %d with viewport %v, want the capture target", last.Framebuffer, last.Viewport)
+	}
+
+	// The window's frame is untouched.
+	if rs.CameraSystem.View != view || rs.Renderer.ScreenWidth != 64 || dev.CurrentViewport() != [4]int32{0, 0, 64, 64} {
+		t.Errorf("capture left view %v, screen width %d, viewport %v", rs.CameraSystem.View, rs.Renderer.ScreenWidth, dev.CurrentViewport())
+	}
+	rs.Update(1.0/60, entities)
+	rs.Present()
+	if f := dev.EndFrame(); f.Draws[len(f.Draws)-1].Framebuffer != 0 {
+		t.Error("next frame did not resolve to the screen")
+	}
+}
@@ -2,6 +2,18 @@ package ecs
 
 import "fmt"
 
+// SkinningMode selects how joint transforms are blended per vertex.
+type SkinningMode int
+
+const (
+	// SkinningLinear blends joint matrices (LBS). Cheap, supports scale,
+	// but twisting joints collapse ("candy wrapper").
+	SkinningLinear SkinningMode = iota
+	// SkinningDualQuat blends rigid joint transforms as dual quaternions,
+	// which preserves volume under twist. Joint scale is ignored.
+	SkinningDualQuat
+)
+
 type Skin struct {
 	// glTF joint node indices
 	Joints []int
@@ -11,6 +23,18 @@ type Skin struct {
 	JointMatrices       [][16]float32
 	JointEntities       []*Entity
 	SkeletonRootNode    int // glTF node index
+
+	Mode SkinningMode
+	// Filled by SkinningSystem in SkinningDualQuat mode: real part (x,y,z,w)
+	// followed by dual part (x,y,z,w), one entry per joint.
+	JointDualQuats [][8]float32
+
+	// CPUSkinning makes SkinningSystem write the deformed mesh (model space)
+	// into SkinnedPositions/SkinnedNormals every frame and bound the entity
+	// by that pose, so it can be culled. The GPU path does not need it.
+	CPUSkinning      bool
+	SkinnedPositions [][3]float32
+	SkinnedNormals   [][3]float32
 }
 
 func NewSkin(joints []int, ibm [][16]float32, skeletonRoot int) *Skin {
@@ -33,6 +57,17 @@ func (s *Skin) EditorName() string {
 
 func (s *Skin) EditorFields() map[string]any {
 	return map[string]any{
-		"JointCount": len(s.Joints),
+		"JointCount":  len(s.Joints),
+		"Mode":        int(s.Mode),
+		"CPUSkinning": s.CPUSkinning,
+	}
+}
+
+func (s *Skin) SetEditorField(name string, value any) {
+	switch name {
+	case "Mode":
+		s.Mode = SkinningMode(toInt(value))
+	case "CPUSkinning":
+		s.CPUSkinning = toBool(value)
 	}
 }
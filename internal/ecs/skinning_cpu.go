@@ -0,0 +1,158 @@
+package ecs
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Dual quaternions are stored as [8]float32: real part (x,y,z,w) then dual
+// part (x,y,z,w), the same order the skinned shader reads from
+// uJointDualQuats.
+
+// MatToDualQuat converts a rigid joint matrix (column-major) to a unit dual
+// quaternion. Scale is divided out of the rotation columns and discarded.
+func MatToDualQuat(m [16]float32) [8]float32 {
+	var rot mgl32.Mat3
+	for c := 0; c < 3; c++ {
+		col := mgl32.Vec3{m[c*4+0], m[c*4+1], m[c*4+2]}
+		if l := col.Len(); l > 1e-8 {
+			col = col.Mul(1 / l)
+		}
+		rot[c*3+0], rot[c*3+1], rot[c*3+2] = col[0], col[1], col[2]
+	}
+	r := mgl32.Mat4ToQuat(rot.Mat4()).Normalize()
+	t := mgl32.Vec3{m[12], m[13], m[14]}
+
+	// dual = 0.5 * (t, 0) * r
+	dv := t.Mul(r.W).Add(t.Cross(r.V)).Mul(0.5)
+	dw := -0.5 * t.Dot(r.V)
+
+	return [8]float32{r.V[0], r.V[1], r.V[2], r.W, dv[0], dv[1], dv[2], dw}
+}
+
+// blendDualQuats is DLB (dual-quaternion linear blending). Each joint is
+// flipped into the hemisphere of the first influence so the blend takes the
+// short way round, then the result is normalised by the real part.
+func blendDualQuats(dqs [][8]float32, joints [4]uint16, weights [4]float32) [8]float32 {
+	var out [8]float32
+	var pivot [4]float32
+	havePivot := false
+
+	for k := 0; k < 4; k++ {
+		w := weights[k]
+		j := int(joints[k])
+		if w <= 0 || j >= len(dqs) {
+			continue
+		}
+		dq := dqs[j]
+		if !havePivot {
+			pivot = [4]float32{dq[0], dq[1], dq[2], dq[3]}
+			havePivot = true
+		} else if pivot[0]*dq[0]+pivot[1]*dq[1]+pivot[2]*dq[2]+pivot[3]*dq[3] < 0 {
+			w = -w
+		}
+		for i := range out {
+			out[i] += w * dq[i]
+		}
+	}
+
+	n := float32(math.Sqrt(float64(out[0]*out[0] + out[1]*out[1] + out[2]*out[2] + out[3]*out[3])))
+	if n < 1e-8 {
+		return [8]float32{0, 0, 0, 1, 0, 0, 0, 0}
+	}
+	for i := range out {
+		out[i] /= n
+	}
+	return out
+}
+
+// dualQuatTransform applies a unit dual quaternion to a point and a normal.
+func dualQuatTransform(dq [8]float32, p, n [3]float32) ([3]float32, [3]float32) {
+	rv := mgl32.Vec3{dq[0], dq[1], dq[2]}
+	rw := dq[3]
+	dv := mgl32.Vec3{dq[4], dq[5], dq[6]}
+	dw := dq[7]
+
+	rotate := func(v mgl32.Vec3) mgl32.Vec3 {
+		return v.Add(rv.Cross(rv.Cross(v).Add(v.Mul(rw))).Mul(2))
+	}
+	trans := dv.Mul(rw).Sub(rv.Mul(dw)).Add(rv.Cross(dv)).Mul(2)
+
+	pos := rotate(mgl32.Vec3(p)).Add(trans)
+	nrm := rotate(mgl32.Vec3(n))
+	return [3]float32(pos), [3]float32(nrm)
+}
+
+// blendJointMatrices is the LBS weighted sum of joint matrices.
+func blendJointMatrices(mats [][16]float32, joints [4]uint16, weights [4]float32) mgl32.Mat4 {
+	var out mgl32.Mat4
+	for k := 0; k < 4; k++ {
+		w := weights[k]
+		j := int(joints[k])
+		if w <= 0 || j >= len(mats) {
+			continue
+		}
+		for i := range out {
+			out[i] += w * mats[j][i]
+		}
+	}
+	return out
+}
+
+// normalizeInfluences renormalises the 4 weights of a vertex. Data coming
+// from the glTF loader is already capped and normalised; this guards meshes
+// whose weights were edited or generated at runtime.
+func normalizeInfluences(w [4]float32) [4]float32 {
+	sum := w[0] + w[1] + w[2] + w[3]
+	if sum <= 0 {
+		return [4]float32{1, 0, 0, 0}
+	}
+	for k := range w {
+		w[k] /= sum
+	}
+	return w
+}
+
+// SkinMeshCPU deforms bind-pose positions and normals with the skin's
+// current JointMatrices (and JointDualQuats in SkinningDualQuat mode).
+// Results are in the mesh's model space, like the vertex shader output
+// before the model matrix. outPos/outNrm are reused when large enough.
+func SkinMeshCPU(skin *Skin, positions, normals [][3]float32, joints [][4]uint16, weights [][4]float32, outPos, outNrm [][3]float32) ([][3]float32, [][3]float32) {
+	n := len(positions)
+	if cap(outPos) < n {
+		outPos = make([][3]float32, n)
+	}
+	if cap(outNrm) < n {
+		outNrm = make([][3]float32, n)
+	}
+	outPos, outNrm = outPos[:n], outNrm[:n]
+
+	dualQuat := skin.Mode == SkinningDualQuat && len(skin.JointDualQuats) == len(skin.JointMatrices)
+
+	for v := 0; v < n; v++ {
+		var nrm [3]float32
+		if v < len(normals) {
+			nrm = normals[v]
+		}
+		if v >= len(joints) || v >= len(weights) {
+			outPos[v], outNrm[v] = positions[v], nrm
+			continue
+		}
+		w := normalizeInfluences(weights[v])
+
+		if dualQuat {
+			dq := blendDualQuats(skin.JointDualQuats, joints[v], w)
+			p, nn := dualQuatTransform(dq, positions[v], nrm)
+			outPos[v], outNrm[v] = p, [3]float32(mgl32.Vec3(nn).Normalize())
+			continue
+		}
+
+		m := blendJointMatrices(skin.JointMatrices, joints[v], w)
+		p := m.Mul4x1(mgl32.Vec3(positions[v]).Vec4(1)).Vec3()
+		nm := m.Mat3().Inv().Transpose()
+		outPos[v] = [3]float32(p)
+		outNrm[v] = [3]float32(nm.Mul3x1(mgl32.Vec3(nrm)).Normalize())
+	}
+	return outPos, outNrm
+}
@@ -0,0 +1,114 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"go-engine/Go-Cordance/internal/engine"
+)
+
+func twoJointSkin(mode SkinningMode, a, b mgl32.Mat4) *Skin {
+	skin := &Skin{
+		Mode:          mode,
+		JointMatrices: [][16]float32{a, b},
+	}
+	if mode == SkinningDualQuat {
+		skin.JointDualQuats = [][8]float32{MatToDualQuat(a), MatToDualQuat(b)}
+	}
+	return skin
+}
+
+func TestSkinMeshCPU_RigidJointMatchesAcrossModes(t *testing.T) {
+	m := mgl32.Translate3D(1, 2, 3).Mul4(mgl32.HomogRotate3DY(0.7))
+	pos := [][3]float32{{0.5, 1, -0.25}}
+	nrm := [][3]float32{{1, 0, 0}}
+	joints := [][4]uint16{{1, 0, 0, 0}}
+	weights := [][4]float32{{1, 0, 0, 0}}
+
+	want := m.Mul4x1(mgl32.Vec3(pos[0]).Vec4(1)).Vec3()
+	wantN := m.Mat3().Mul3x1(mgl32.Vec3(nrm[0]))
+
+	for _, mode := range []SkinningMode{SkinningLinear, SkinningDualQuat} {
+		skin := twoJointSkin(mode, mgl32.Ident4(), m)
+		p, n := SkinMeshCPU(skin, pos, nrm, joints, weights, nil, nil)
+		assertNear(t, p[0], [3]float32(want), 1e-4)
+		assertNear(t, n[0], [3]float32(wantN), 1e-4)
+	}
+}
+
+func TestSkinMeshCPU_DualQuatPreservesVolumeUnderTwist(t *testing.T) {
+	twist := mgl32.HomogRotate3DX(mgl32.DegToRad(170))
+	pos := [][3]float32{{0, 1, 0}}
+	joints := [][4]uint16{{0, 1, 0, 0}}
+	weights := [][4]float32{{0.5, 0.5, 0, 0}}
+
+	lbs, _ := SkinMeshCPU(twoJointSkin(SkinningLinear, mgl32.Ident4(), twist), pos, nil, joints, weights, nil, nil)
+	dq, _ := SkinMeshCPU(twoJointSkin(SkinningDualQuat, mgl32.Ident4(), twist), pos, nil, joints, weights, nil, nil)
+
+	radius := func(p [3]float32) float32 { return mgl32.Vec2{p[1], p[2]}.Len() }
+	if r := radius(lbs[0]); r > 0.2 {
+		t.Fatalf("expected linear blend to collapse, radius=%v", r)
+	}
+	if r := radius(dq[0]); r < 0.999 || r > 1.001 {
+		t.Fatalf("expected dual quaternion blend to keep radius 1, got %v", r)
+	}
+}
+
+func TestCapJointInfluences_KeepsStrongestFour(t *testing.T) {
+	jointSets := [][][4]uint16{{{1, 2, 3, 4}}, {{5, 6, 2, 7}}}
+	weightSets := [][][4]float32{{{0.3, 0.1, 0.05, 0.2}}, {{0.02, 0.15, 0.12, 0.08}}}
+
+	js, ws := engine.CapJointInfluences(jointSets, weightSets)
+
+	// joint 2 appears in both sets: 0.1 + 0.12
+	wantJ := [4]uint16{1, 2, 4, 6}
+	if js[0] != wantJ {
+		t.Fatalf("expected joints %v got %v", wantJ, js[0])
+	}
+	sum := ws[0][0] + ws[0][1] + ws[0][2] + ws[0][3]
+	if sum < 0.9999 || sum > 1.0001 {
+		t.Fatalf("expected weights to sum to 1, got %v", ws[0])
+	}
+	if ws[0][0] < ws[0][1] || ws[0][1] < ws[0][2] || ws[0][2] < ws[0][3] {
+		t.Fatalf("expected weights sorted strongest first, got %v", ws[0])
+	}
+}
+
+func TestSkinningSystem_BoundsCPUSkinnedPose(t *testing.T) {
+	prev := engine.GlobalMeshManager
+	mm := engine.NewMeshManager()
+	engine.GlobalMeshManager = mm
+	defer func() { engine.GlobalMeshManager = prev }()
+
+	// A unit box whose only joint has moved 5 along +X from its bind pose.
+	mm.PositionData["box"] = [][3]float32{{-1, -1, -1}, {1, 1, 1}}
+	joint := NewEntity(1)
+	joint.AddComponent(NewTransform([3]float32{5, 0, 0}))
+
+	body := NewEntity(2)
+	body.AddComponent(NewTransform([3]float32{}))
+	mesh := NewMesh("box")
+	mesh.Joints = [][4]uint16{{0, 0, 0, 0}, {0, 0, 0, 0}}
+	mesh.Weights = [][4]float32{{1, 0, 0, 0}, {1, 0, 0, 0}}
+	body.AddComponent(mesh)
+	skin := NewSkin([]int{0}, [][16]float32{mgl32.Ident4()}, 0)
+	skin.JointEntities[0] = joint
+	skin.CPUSkinning = true
+	body.AddComponent(skin)
+
+	ents := []*Entity{joint, body}
+	NewTransformSystem().Update(0, ents)
+	if body.GetTransform().HasBounds {
+		t.Fatal("TransformSystem bounded a skinned entity by its bind pose")
+	}
+	NewSkinningSystem(nil).Update(0, ents)
+
+	tr := body.GetTransform()
+	if !tr.HasBounds {
+		t.Fatal("CPU-skinned entity has no bounds")
+	}
+	assertNear(t, tr.WorldBounds.Min, [3]float32{4, -1, -1}, 1e-4)
+	assertNear(t, tr.WorldBounds.Max, [3]float32{6, 1, 1}, 1e-4)
+	assertNear(t, tr.WorldSphere.Center, [3]float32{5, 0, 0}, 1e-4)
+}
@@ -5,8 +5,9 @@ import (
 	"math"
 )
 
-// SkinningSystem currently just exists as a hook for future CPU/GPU skinning.
-// It does NOT change any rendering state yet.
+// SkinningSystem assembles per-joint skinning transforms for the GPU
+// (matrices, or dual quaternions in SkinningDualQuat mode) and, when
+// Skin.CPUSkinning is set, deforms the mesh on the CPU as well.
 type SkinningSystem struct {
 	world   *World
 	checked bool
@@ -117,5 +118,49 @@ func (sys *SkinningSystem) Update(dt float32, ents []*Entity) {
 			modelSpaceJoint := engine.MulMat4(invMeshWorld, jointWorld)
 			skin.JointMatrices[i] = engine.MulMat4(modelSpaceJoint, skin.InverseBindMatrices[i])
 		}
+
+		if skin.Mode == SkinningDualQuat {
+			if len(skin.JointDualQuats) != len(skin.JointMatrices) {
+				skin.JointDualQuats = make([][8]float32, len(skin.JointMatrices))
+			}
+			for i, m := range skin.JointMatrices {
+				skin.JointDualQuats[i] = MatToDualQuat(m)
+			}
+		}
+
+		if skin.CPUSkinning {
+			sys.skinCPU(e, skin)
+			updateSkinnedBounds(meshTr, skin.SkinnedPositions)
+		}
+	}
+}
+
+// skinCPU deforms the bind pose kept by MeshManager for skinned glTF meshes.
+func (sys *SkinningSystem) skinCPU(e *Entity, skin *Skin) {
+	mesh, ok := e.GetComponent((*Mesh)(nil)).(*Mesh)
+	if !ok || engine.GlobalMeshManager == nil {
+		return
+	}
+	positions := engine.GlobalMeshManager.PositionData[mesh.ID]
+	if len(positions) == 0 {
+		return
+	}
+	normals := engine.GlobalMeshManager.NormalData[mesh.ID]
+	skin.SkinnedPositions, skin.SkinnedNormals = SkinMeshCPU(
+		skin, positions, normals, mesh.Joints, mesh.Weights,
+		skin.SkinnedPositions, skin.SkinnedNormals,
+	)
+}
+
+// updateSkinnedBounds bounds the CPU-skinned pose, which TransformSystem
+// leaves unbounded, so the entity is culled by where it actually is.
+func updateSkinnedBounds(tr *Transform, positions [][3]float32) {
+	if len(positions) == 0 {
+		return
+	}
+	local := engine.EmptyAABB()
+	for _, p := range positions {
+		local = local.Extend(p)
 	}
+	setWorldBounds(tr, local, local.BoundingSphere())
 }
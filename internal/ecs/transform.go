@@ -3,6 +3,8 @@ package ecs
 import (
 	"math"
 
+	"go-engine/Go-Cordance/internal/engine"
+
 	"github.com/go-gl/mathgl/mgl32"
 )
 
@@ -18,6 +20,15 @@ type Transform struct {
 	WorldMatrix [16]float32
 	FrozenLocal bool
 	Dirty       bool
+
+	// World-space bounds of the entity's Mesh or MultiMesh, kept by
+	// TransformSystem. HasBounds is false for entities with no mesh bounds
+	// and for skinned ones, whose bind pose says little about the animated
+	// shape, unless SkinningSystem bounds their CPU-skinned pose
+	// (Skin.CPUSkinning); RenderSystem never culls unbounded entities.
+	WorldBounds engine.AABB
+	WorldSphere engine.Sphere
+	HasBounds   bool
 }
 
 func NewTransform(pos [3]float32) *Transform {
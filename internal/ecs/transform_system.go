@@ -1,5 +1,11 @@
 package ecs
 
+import (
+	"math"
+
+	"go-engine/Go-Cordance/internal/engine"
+)
+
 type TransformSystem struct{}
 
 func NewTransformSystem() *TransformSystem {
@@ -47,7 +53,7 @@ func (ts *TransformSystem) updateRecursive(e *Entity, parentWorld *[16]float32)
 			tr.RecalculateLocal() // <--- unchanged
 		}
 		tr.WorldMatrix = MulMat4(*parentWorld, tr.LocalMatrix) // <--- unchanged
-
+		updateWorldBounds(e, tr)
 	}
 
 	children, ok := e.GetComponent((*Children)(nil)).(*Children)
@@ -64,3 +70,58 @@ func (ts *TransformSystem) updateRecursive(e *Entity, parentWorld *[16]float32)
 		ts.updateRecursive(c, nextParent)
 	}
 }
+
+// updateWorldBounds moves the local bounds of e's mesh (or the union of its
+// MultiMesh parts) into world space with tr.WorldMatrix.
+func updateWorldBounds(e *Entity, tr *Transform) {
+	tr.HasBounds = false
+	mm := engine.GlobalMeshManager
+	if mm == nil || e.GetComponent((*Skin)(nil)) != nil {
+		return
+	}
+	var ids []string
+	if multi, ok := e.GetComponent((*MultiMesh)(nil)).(*MultiMesh); ok && multi != nil {
+		ids = multi.Meshes
+	} else if mesh, ok := e.GetComponent((*Mesh)(nil)).(*Mesh); ok && mesh != nil {
+		ids = []string{mesh.ID}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	local := engine.EmptyAABB()
+	for _, id := range ids {
+		b, ok := mm.Bounds(id)
+		if !ok {
+			return // a part we cannot bound: keep the entity always visible
+		}
+		local = local.Union(b.Box)
+	}
+	if local.IsEmpty() {
+		return
+	}
+
+	sphere := local.BoundingSphere()
+	if len(ids) == 1 {
+		b, _ := mm.Bounds(ids[0])
+		sphere = b.Sphere
+	}
+	setWorldBounds(tr, local, sphere)
+}
+
+// setWorldBounds moves model-space bounds into world space with
+// tr.WorldMatrix and marks tr as bounded.
+func setWorldBounds(tr *Transform, local engine.AABB, sphere engine.Sphere) {
+	m := tr.WorldMatrix
+	tr.WorldBounds = local.Transform(m)
+	maxScale := float32(0)
+	for col := 0; col < 3; col++ {
+		x, y, z := m[col*4], m[col*4+1], m[col*4+2]
+		maxScale = max(maxScale, float32(math.Sqrt(float64(x*x+y*y+z*z))))
+	}
+	tr.WorldSphere = engine.Sphere{
+		Center: engine.TransformPoint(m, sphere.Center),
+		Radius: sphere.Radius * maxScale,
+	}
+	tr.HasBounds = true
+}
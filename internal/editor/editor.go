@@ -472,6 +472,45 @@ func editorReadLoop(conn net.Conn, world *ecs.World) {
 				handleAssetThumbnail(t.AssetID, t.MeshID, t.Format, data, t.Hash)
 			})
 
+		case "ClipRecordingState":
+			var m editorlink.MsgClipRecordingState
+			if err := json.Unmarshal(msg.Data, &m); err != nil {
+				log.Printf("editor: bad ClipRecordingState: %v", err)
+				continue
+			}
+			fyne.DoAndWait(func() {
+				state.Global.ClipRecording = state.ClipRecording{
+					Recording: m.Recording,
+					EntityID:  int64(m.EntityID),
+					Name:      m.Name,
+					Time:      m.Time,
+					Keys:      m.Keys,
+				}
+				if state.Global.RefreshUI != nil {
+					state.Global.RefreshUI()
+				}
+			})
+
+		case "MemoryReport":
+			var m editorlink.MsgMemoryReport
+			if err := json.Unmarshal(msg.Data, &m); err != nil {
+				log.Printf("editor: bad MemoryReport: %v", err)
+				continue
+			}
+			fyne.DoAndWait(func() {
+				ui.ShowMemoryReport(m)
+			})
+
+		case "AssetsReloaded":
+			var m editorlink.MsgAssetsReloaded
+			if err := json.Unmarshal(msg.Data, &m); err != nil {
+				log.Printf("editor: bad AssetsReloaded: %v", err)
+				continue
+			}
+			fyne.DoAndWait(func() {
+				clearReloadedThumbnails(m.Paths)
+			})
+
 		case "AssetList":
 			var m editorlink.MsgAssetList
 			json.Unmarshal(msg.Data, &m)
@@ -519,6 +558,15 @@ func editorReadLoop(conn net.Conn, world *ecs.World) {
 						ShaderData: v.ShaderData,
 					}
 				}
+				st.Assets.Clips = make([]state.AssetView, len(m.Clips))
+				for i, v := range m.Clips {
+					st.Assets.Clips[i] = state.AssetView{
+						ID:       v.ID,
+						Path:     v.Path,
+						Type:     v.Type,
+						ClipData: v.ClipData,
+					}
+				}
 
 				if st.RefreshUI != nil {
 					st.RefreshUI()
@@ -529,6 +577,28 @@ func editorReadLoop(conn net.Conn, world *ecs.World) {
 	}
 }
 
+// clearReloadedThumbnails drops the thumbnails of hot-reloaded assets so the
+// asset browser requests fresh ones.
+func clearReloadedThumbnails(paths []string) {
+	st := state.Global
+	changed := map[string]bool{}
+	for _, p := range paths {
+		changed[p] = true
+	}
+	for _, list := range [][]state.AssetView{st.Assets.Textures, st.Assets.Meshes, st.Assets.Materials} {
+		for i := range list {
+			if changed[list[i].Path] {
+				list[i].Thumbnail = ""
+				list[i].MeshThumb = make(map[string]string)
+			}
+		}
+	}
+	log.Printf("editor: game reloaded %d asset(s): %v", len(paths), paths)
+	if st.RefreshUI != nil {
+		st.RefreshUI()
+	}
+}
+
 func equalStringSlices(a, b []string) bool {
 	if len(a) != len(b) {
 		return false
@@ -854,6 +924,9 @@ func updateLocalMaterial(world *ecs.World, entityID int64, fields map[string]any
 	if v, ok := fields["ShaderName"].(string); ok {
 		mat.ShaderName = v
 	}
+	if v, ok := fields["MaterialAsset"].(assets.AssetID); ok {
+		mat.MaterialAsset = v
+	}
 	// Normal flags + IDs/assets
 	useNormalSet := false
 	if v, ok := fields["UseNormal"].(bool); ok {
@@ -1,9 +1,12 @@
 package importer
 
 import (
+	"fmt"
+	"go-engine/Go-Cordance/internal/assets"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 type AssetType string
@@ -12,10 +15,43 @@ const (
 	Texture  AssetType = "texture"
 	Mesh     AssetType = "mesh"
 	Material AssetType = "material"
+	Clip     AssetType = "clip"
 )
 
+// Extensions returns the file extensions the game imports as t.
+func Extensions(t AssetType) []string {
+	switch t {
+	case Texture:
+		return []string{".png", ".jpg", ".jpeg"}
+	case Mesh:
+		return assets.ModelExtensions
+	case Material:
+		return []string{".mat"}
+	case Clip:
+		return []string{assets.ClipExtBinary, filepath.Ext(assets.ClipExtJSON)}
+	}
+	return nil
+}
+
+// accepts reports whether the game will pick up path as t once copied.
+func accepts(t AssetType, path string) bool {
+	if t == Clip {
+		return assets.IsClipPath(path)
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range Extensions(t) {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
 func CopyToAssetFolder(srcPath string, t AssetType) (string, error) {
 	base := filepath.Base(srcPath)
+	if !accepts(t, srcPath) {
+		return "", fmt.Errorf("%s: not a supported %s file (%s)", base, t, strings.Join(Extensions(t), ", "))
+	}
 
 	var dstDir string
 	switch t {
@@ -25,6 +61,8 @@ func CopyToAssetFolder(srcPath string, t AssetType) (string, error) {
 		dstDir = "assets/models"
 	case Material:
 		dstDir = "assets/materials"
+	case Clip:
+		dstDir = "assets/animations"
 	}
 
 	dstPath := filepath.Join(dstDir, base)
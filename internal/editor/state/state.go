@@ -8,6 +8,7 @@ import (
 
 type AssetView struct {
 	ID           uint64            `json:"id"`
+	GUID         string            `json:"guid,omitempty"`
 	Path         string            `json:"path"`
 	Type         string            `json:"type"`
 	Thumbnail    string            `json:"thumbnail,omitempty"` // whole-asset thumb
@@ -16,6 +17,16 @@ type AssetView struct {
 	MeshThumb    map[string]string `json:"mesh_thumb,omitempty"` // meshID -> file path
 	MaterialData map[string]any    `json:"material_data,omitempty"`
 	ShaderData   map[string]any    `json:"shader_data,omitempty"`
+	ClipData     map[string]any    `json:"clip_data,omitempty"`
+}
+
+// ClipRecording mirrors the game's clip recorder (see editorlink.MsgClipRecordingState).
+type ClipRecording struct {
+	Recording bool
+	EntityID  int64
+	Name      string
+	Time      float32
+	Keys      int
 }
 
 type EditorState struct {
@@ -38,6 +49,7 @@ type EditorState struct {
 	RenameIndex         int
 	Console             *widget.RichText
 	GameConsole         *widget.RichText
+	ClipRecording       ClipRecording
 
 	// in EditorState
 
@@ -46,6 +58,7 @@ type EditorState struct {
 		Meshes    []AssetView
 		Materials []AssetView
 		Shaders   []AssetView
+		Clips     []AssetView
 		Prefabs   []PrefabView
 	}
 }
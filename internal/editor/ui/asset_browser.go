@@ -14,6 +14,7 @@ import (
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
@@ -386,6 +387,8 @@ func NewAssetBrowserPanel(st *state.EditorState, win fyne.Window) (fyne.CanvasOb
 				"TextureAsset": ecsMat.TextureAsset,
 				"TextureID":    int(ecsMat.TextureID),
 				"ShaderName":   shaderName,
+				// Lets the game update this entity when the .mat changes.
+				"MaterialAsset": assets.AssetID(av.ID),
 			},
 		}
 
@@ -451,14 +454,23 @@ func NewAssetBrowserPanel(st *state.EditorState, win fyne.Window) (fyne.CanvasOb
 		editorlink.WriteInstantiatePrefab(editorlink.EditorConn, path)
 	}
 
+	clipList := newClipList(st)
+
 	// --- Tabs ---
+	clipTab := container.NewTabItem("Clips", clipList)
 	tabs := container.NewAppTabs(
 		container.NewTabItem("Textures", texList),
 		container.NewTabItem("Meshes", meshList),
 		container.NewTabItem("Materials", matList),
 		container.NewTabItem("Shaders", shaderList),
+		clipTab,
 		container.NewTabItem("Prefabs", prefabList),
 	)
+	tabs.OnSelected = func(t *container.TabItem) {
+		if t == clipTab {
+			clipList.Refresh()
+		}
+	}
 
 	tabs.SetTabLocation(container.TabLocationTop)
 	// Hook into global RefreshUI
@@ -473,7 +485,19 @@ func NewAssetBrowserPanel(st *state.EditorState, win fyne.Window) (fyne.CanvasOb
 		openFileDialog(win, importer.Material)
 	})
 
-	toolbar := container.NewHBox(uploadTexturesBtn, uploadMeshesBtn, uploadMaterialsBtn)
+	uploadClipsBtn := widget.NewButton("Upload Clip", func() {
+		openFileDialog(win, importer.Clip)
+	})
+
+	moveAssetBtn := widget.NewButton("Move Asset", func() {
+		showMoveAssetDialog(st, win)
+	})
+
+	memoryBtn := widget.NewButton("Memory", func() {
+		requestMemoryReport(win)
+	})
+
+	toolbar := container.NewHBox(uploadTexturesBtn, uploadMeshesBtn, uploadMaterialsBtn, uploadClipsBtn, moveAssetBtn, memoryBtn)
 
 	// --- FINAL ROOT ---
 	root := container.NewBorder(toolbar, nil, nil, nil, tabs)
@@ -482,6 +506,35 @@ func NewAssetBrowserPanel(st *state.EditorState, win fyne.Window) (fyne.CanvasOb
 
 }
 
+// showMoveAssetDialog renames/moves an asset on the game side. The asset
+// keeps its GUID, so scenes and materials referencing it stay intact.
+func showMoveAssetDialog(st *state.EditorState, win fyne.Window) {
+	var paths []string
+	for _, group := range [][]state.AssetView{st.Assets.Textures, st.Assets.Meshes, st.Assets.Materials, st.Assets.Shaders, st.Assets.Clips} {
+		for _, a := range group {
+			paths = append(paths, a.Path)
+		}
+	}
+
+	to := widget.NewEntry()
+	from := widget.NewSelect(paths, func(p string) { to.SetText(p) })
+
+	dialog.ShowForm("Move Asset", "Move", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("Asset", from),
+		widget.NewFormItem("New path", to),
+	}, func(ok bool) {
+		if !ok || from.Selected == "" || to.Text == "" || to.Text == from.Selected {
+			return
+		}
+		if editorlink.EditorConn == nil {
+			return
+		}
+		if err := editorlink.WriteMoveAsset(editorlink.EditorConn, from.Selected, to.Text); err != nil {
+			log.Printf("failed to send MoveAsset: %v", err)
+		}
+	}, win)
+}
+
 func makeTextureItem() fyne.CanvasObject {
 	return newTextureDragItem()
 }
@@ -669,6 +722,7 @@ func openFileDialog(win fyne.Window, t importer.AssetType) {
 
 		}
 	}, win)
+	dialog.SetFilter(storage.NewExtensionFileFilter(importer.Extensions(t)))
 
 	dialog.Show()
 }
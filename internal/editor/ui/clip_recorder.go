@@ -0,0 +1,144 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"go-engine/Go-Cordance/internal/editor/state"
+	"go-engine/Go-Cordance/internal/editorlink"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// buildClipRecorderUI is the "Record Clip" section of the inspector. While
+// recording, every gizmo or transform edit of the entity is keyed by the
+// game at the current key time; "Key" captures the pose without editing.
+func buildClipRecorderUI(st *state.EditorState, entityID int64, name string) fyne.CanvasObject {
+	rec := st.ClipRecording
+	box := container.NewVBox()
+
+	if !rec.Recording || rec.EntityID != entityID {
+		clipName := widget.NewEntry()
+		if name == "" {
+			name = fmt.Sprintf("Entity_%d", entityID)
+		}
+		clipName.SetText(name + "_anim")
+
+		startBtn := widget.NewButtonWithIcon("Start Recording", theme.MediaRecordIcon(), func() {
+			if editorlink.EditorConn != nil {
+				go editorlink.WriteStartClipRecording(editorlink.EditorConn, entityID, clipName.Text)
+			}
+		})
+		if rec.Recording {
+			// one recorder at a time, owned by another entity
+			startBtn.Disable()
+		}
+		box.Add(container.NewBorder(nil, nil, widget.NewLabel("Name"), nil, clipName))
+		box.Add(startBtn)
+		return clipRecorderFoldout(st, box, st.Foldout["Record Clip"])
+	}
+
+	status := widget.NewLabel(fmt.Sprintf("Recording %q: %d keys", rec.Name, rec.Keys))
+
+	timeEntry := widget.NewEntry()
+	timeEntry.SetText(strconv.FormatFloat(float64(rec.Time), 'f', 2, 32))
+	timeEntry.OnSubmitted = func(s string) {
+		if editorlink.EditorConn != nil {
+			go editorlink.WriteSetClipRecordTime(editorlink.EditorConn, parse32(s))
+		}
+	}
+	step := func(dt float32) func() {
+		return func() {
+			if editorlink.EditorConn != nil {
+				go editorlink.WriteSetClipRecordTime(editorlink.EditorConn, rec.Time+dt)
+			}
+		}
+	}
+	timeRow := container.NewHBox(
+		widget.NewLabel("Key time"),
+		widget.NewButton("-0.5s", step(-0.5)),
+		timeEntry,
+		widget.NewButton("+0.5s", step(0.5)),
+	)
+
+	keyBtn := widget.NewButton("Key", func() {
+		if editorlink.EditorConn != nil {
+			go editorlink.WriteRecordClipKey(editorlink.EditorConn)
+		}
+	})
+
+	binary := widget.NewCheck("Binary (.clip)", nil)
+	saveBtn := widget.NewButtonWithIcon("Stop & Save", theme.DocumentSaveIcon(), func() {
+		if editorlink.EditorConn != nil {
+			go editorlink.WriteStopClipRecording(editorlink.EditorConn, true, binary.Checked)
+		}
+	})
+	cancelBtn := widget.NewButtonWithIcon("Cancel", theme.CancelIcon(), func() {
+		if editorlink.EditorConn != nil {
+			go editorlink.WriteStopClipRecording(editorlink.EditorConn, false, false)
+		}
+	})
+
+	box.Add(status)
+	box.Add(timeRow)
+	box.Add(keyBtn)
+	box.Add(binary)
+	box.Add(container.NewHBox(saveBtn, cancelBtn))
+	return clipRecorderFoldout(st, box, true)
+}
+
+func clipRecorderFoldout(st *state.EditorState, box fyne.CanvasObject, expanded bool) fyne.CanvasObject {
+	fold := NewFoldout("Record Clip", box, expanded, theme.MediaRecordIcon())
+	fold.SetOnToggle(func(expanded bool) {
+		st.Foldout["Record Clip"] = expanded
+	})
+	return fold
+}
+
+// newClipList lists clip assets; selecting one assigns it to the selected
+// entity's AnimationPlayer (the game adds the component if missing).
+func newClipList(st *state.EditorState) *widget.List {
+	list := widget.NewList(
+		func() int {
+			return len(st.Assets.Clips)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			av := st.Assets.Clips[i]
+			dur, _ := av.ClipData["duration"].(float64)
+			o.(*widget.Label).SetText(fmt.Sprintf("%s (%.2fs)", filepath.Base(av.Path), dur))
+		},
+	)
+
+	list.OnSelected = func(id widget.ListItemID) {
+		defer list.UnselectAll()
+		if st.SelectedIndex < 0 || st.SelectedIndex >= len(st.Entities) {
+			return
+		}
+		ent := st.Entities[st.SelectedIndex]
+		assignClipAsset(ent.ID, st.Assets.Clips[id].ID)
+	}
+	return list
+}
+
+// assignClipAsset sends only the ClipAsset field so the game doesn't also
+// receive a stale Current from the editor's mirror component.
+func assignClipAsset(entityID int64, assetID uint64) {
+	if editorlink.EditorConn == nil {
+		return
+	}
+	msg := editorlink.MsgSetComponent{
+		EntityID: uint64(entityID),
+		Name:     "AnimationPlayer",
+		Fields: map[string]any{
+			"ClipAsset": assetID,
+		},
+	}
+	go editorlink.WriteSetComponent(editorlink.EditorConn, msg)
+}
@@ -61,6 +61,19 @@ func NewHierarchyPanel(st *state.EditorState, win fyne.Window, onSelect func(int
 		}, win)
 	})
 
+	exportBtn := widget.NewButton("Export glTF", func() {
+		dialog.ShowFileSave(func(uc fyne.URIWriteCloser, err error) {
+			if uc == nil || err != nil {
+				return
+			}
+			path := gltfExportPath(uc.URI().Path())
+			uc.Close()
+			if editorlink.EditorConn != nil {
+				go editorlink.WriteExportGLTF(editorlink.EditorConn, 0, path)
+			}
+		}, win)
+	})
+
 	loadBtn := widget.NewButton("Load Scene", func() {
 		dialog.ShowFileOpen(func(ur fyne.URIReadCloser, err error) {
 			if ur == nil {
@@ -225,7 +238,7 @@ func NewHierarchyPanel(st *state.EditorState, win fyne.Window, onSelect func(int
 		},
 	)
 
-	topBar := container.NewHBox(createBtn, dupBtn, delBtn, saveBtn, loadBtn)
+	topBar := container.NewHBox(createBtn, dupBtn, delBtn, saveBtn, loadBtn, exportBtn)
 
 	panel := container.NewBorder(topBar, nil, nil, nil, list)
 
@@ -466,7 +479,29 @@ func showHierarchyContextMenu(
 
 	})
 
-	menu := fyne.NewMenu("", rename, duplicate, delete, savePrefab)
+	exportGLTF := fyne.NewMenuItem("Export as glTF…", func() {
+		dialog.ShowFileSave(func(uc fyne.URIWriteCloser, err error) {
+			if uc == nil || err != nil {
+				return
+			}
+			path := gltfExportPath(uc.URI().Path())
+			uc.Close()
+			if editorlink.EditorConn != nil {
+				go editorlink.WriteExportGLTF(editorlink.EditorConn, row.ID, path)
+			}
+		}, win)
+	})
+
+	menu := fyne.NewMenu("", rename, duplicate, delete, savePrefab, exportGLTF)
 	widget.ShowPopUpMenuAtPosition(menu, fyne.CurrentApp().Driver().CanvasForObject(item), item.Position())
 
 }
+
+// gltfExportPath defaults to .glb unless the user picked .gltf.
+func gltfExportPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gltf", ".glb":
+		return path
+	}
+	return path + ".glb"
+}
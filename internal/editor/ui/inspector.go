@@ -6,8 +6,10 @@ import (
 	"go-engine/Go-Cordance/internal/ecs"
 	state "go-engine/Go-Cordance/internal/editor/state"
 	"go-engine/Go-Cordance/internal/editorlink"
+	"go-engine/Go-Cordance/internal/engine"
 	"log"
 	"math"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -225,6 +227,7 @@ func NewInspectorPanel() (
 			})
 
 			right.Add(addBtn)
+			right.Add(buildClipRecorderUI(st, entInfo.ID, entInfo.Name))
 			// --- Save Prefab As… button ---
 			savePrefabBtn := widget.NewButton("Save Prefab As…", func() {
 				ent := st.Entities[st.SelectedIndex]
@@ -735,7 +738,7 @@ func buildComponentUI(c ecs.EditorInspectable, entityID int64, refresh func()) f
 						}
 					} else {
 						// fallback: try to map TextureID -> asset path
-						texName := lookupTextureName(uint32(v))
+						texName := lookupTextureName(uint64(v))
 						if texName != "" {
 							textureSelect.SetSelected(texName)
 						}
@@ -788,6 +791,35 @@ func buildComponentUI(c ecs.EditorInspectable, entityID int64, refresh func()) f
 
 				}
 			}
+		case assets.AssetID:
+			// --- AnimationPlayer: clip asset picker ---
+			if name == "ClipAsset" {
+				st := state.Global
+				clipNames := make([]string, len(st.Assets.Clips))
+				for i, a := range st.Assets.Clips {
+					clipNames[i] = filepath.Base(a.Path)
+				}
+				clipSelect := widget.NewSelect(clipNames, nil)
+				for i, a := range st.Assets.Clips {
+					if a.ID == uint64(v) {
+						clipSelect.SetSelected(clipNames[i])
+						break
+					}
+				}
+				clipSelect.OnChanged = func(selected string) {
+					if state.Global.IsRebuilding {
+						return
+					}
+					for i, n := range clipNames {
+						if n == selected {
+							c.SetEditorField("ClipAsset", int(st.Assets.Clips[i].ID))
+							assignClipAsset(entityID, st.Assets.Clips[i].ID)
+							return
+						}
+					}
+				}
+				box.Add(container.NewHBox(widget.NewLabel("Clip Asset"), clipSelect))
+			}
 		case []string: // Clips list
 			if name == "Clips" {
 				clipNames := v
@@ -838,6 +870,42 @@ func buildComponentUI(c ecs.EditorInspectable, entityID int64, refresh func()) f
 				}
 				box.Add(container.NewHBox(widget.NewLabel("Type"), dropdown))
 			}
+			// PostProcess tone mapping operator, Material alpha mode
+			if options := enumFieldOptions(name); options != nil {
+				dropdown := widget.NewSelect(options, nil)
+				if v >= 0 && v < len(options) {
+					dropdown.SetSelected(options[v])
+				}
+				dropdown.OnChanged = func(selected string) {
+					if state.Global.IsRebuilding {
+						return
+					}
+					for i, o := range options {
+						if o == selected {
+							c.SetEditorField(name, i)
+						}
+					}
+					sendComponentUpdate(entityID, c)
+				}
+				box.Add(container.NewHBox(widget.NewLabel(name), dropdown))
+			}
+			// --- Default int handler ---
+			if name != "Type" && enumFieldOptions(name) == nil {
+				e := widget.NewEntry()
+				e.SetText(strconv.Itoa(v))
+				e.OnSubmitted = func(s string) {
+					if state.Global.IsRebuilding {
+						return
+					}
+					n, err := strconv.Atoi(strings.TrimSpace(s))
+					if err != nil {
+						return
+					}
+					c.SetEditorField(name, n)
+					sendComponentUpdate(entityID, c)
+				}
+				box.Add(container.NewHBox(widget.NewLabel(name), e))
+			}
 
 		}
 	}
@@ -873,6 +941,18 @@ func buildComponentUI(c ecs.EditorInspectable, entityID int64, refresh func()) f
 
 }
 
+// enumFieldOptions returns the dropdown labels for int editor fields that
+// hold an engine enum, indexed by value, or nil for plain ints.
+func enumFieldOptions(name string) []string {
+	switch name {
+	case "Tonemap":
+		return engine.TonemapNames()
+	case "AlphaMode":
+		return engine.AlphaModeNames()
+	}
+	return nil
+}
+
 func sendComponentUpdate(entityID int64, c ecs.EditorInspectable) {
 	if editorlink.EditorConn == nil {
 		return
@@ -981,22 +1061,22 @@ func sendRemoveComponent(entityID int64, name string) {
 
 }
 
-// lookupTextureID returns an asset's AssetID (as uint32) for a given asset path/name.
+// lookupTextureID returns an asset's AssetID for a given asset path/name.
 // It searches state.Global.Assets.Textures for a matching Path.
-func lookupTextureID(name string) uint32 {
+func lookupTextureID(name string) uint64 {
 	for _, a := range state.Global.Assets.Textures {
 		if a.Path == name {
-			return uint32(a.ID)
+			return a.ID
 		}
 	}
 	return 0
 }
 
-// lookupTextureName returns the asset Path for a given asset id (uint32).
+// lookupTextureName returns the asset Path for a given asset id.
 // It searches state.Global.Assets.Textures for a matching ID.
-func lookupTextureName(id uint32) string {
+func lookupTextureName(id uint64) string {
 	for _, a := range state.Global.Assets.Textures {
-		if uint32(a.ID) == id {
+		if a.ID == id {
 			return a.Path
 		}
 	}
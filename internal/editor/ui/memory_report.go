@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"go-engine/Go-Cordance/internal/editorlink"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// memoryReportWin is the window that asked for the last memory report; the
+// reply arrives asynchronously through ShowMemoryReport.
+var memoryReportWin fyne.Window
+
+func requestMemoryReport(win fyne.Window) {
+	if editorlink.EditorConn == nil {
+		return
+	}
+	memoryReportWin = win
+	if err := editorlink.WriteRequestMemoryReport(editorlink.EditorConn); err != nil {
+		log.Printf("failed to send RequestMemoryReport: %v", err)
+	}
+}
+
+// ShowMemoryReport displays the game's per-asset memory use. Call it on the
+// Fyne thread.
+func ShowMemoryReport(m editorlink.MsgMemoryReport) {
+	if memoryReportWin == nil {
+		return
+	}
+
+	list := widget.NewList(
+		func() int { return len(m.Assets) },
+		func() fyne.CanvasObject {
+			return container.NewGridWithColumns(5,
+				widget.NewLabel(""), widget.NewLabel(""), widget.NewLabel(""),
+				widget.NewLabel(""), widget.NewLabel(""))
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			a := m.Assets[i]
+			cells := o.(*fyne.Container).Objects
+			cells[0].(*widget.Label).SetText(filepath.Base(a.Path))
+			cells[1].(*widget.Label).SetText(a.Type)
+			cells[2].(*widget.Label).SetText(fmt.Sprintf("%d refs", a.Refs))
+			cells[3].(*widget.Label).SetText("CPU " + formatBytes(a.CPUBytes))
+			cells[4].(*widget.Label).SetText("GPU " + formatBytes(a.GPUBytes))
+		},
+	)
+	total := widget.NewLabel(fmt.Sprintf("%d assets — CPU %s, GPU %s",
+		len(m.Assets), formatBytes(m.CPUBytes), formatBytes(m.GPUBytes)))
+
+	d := dialog.NewCustom("Asset Memory", "Close", container.NewBorder(total, nil, nil, nil, list), memoryReportWin)
+	d.Resize(fyne.NewSize(720, 480))
+	d.Show()
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,173 @@
+package editorlink
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"go-engine/Go-Cordance/internal/assets"
+	"go-engine/Go-Cordance/internal/ecs"
+	"go-engine/Go-Cordance/internal/scene"
+)
+
+// ClipDir is where clips recorded in the editor are saved.
+const ClipDir = "assets/animations"
+
+var (
+	recorderMu sync.Mutex
+	recorder   *ecs.ClipRecorder
+)
+
+func startClipRecording(sc *scene.Scene, m MsgStartClipRecording) {
+	ent := sc.World().FindByID(int64(m.EntityID))
+	if ent == nil {
+		log.Printf("editorlink: StartClipRecording: entity %d not found", m.EntityID)
+		return
+	}
+	name := m.Name
+	if name == "" {
+		name = fmt.Sprintf("clip_%d", m.EntityID)
+	}
+	if err := checkClipName(name); err != nil {
+		log.Printf("editorlink: StartClipRecording: %v", err)
+		return
+	}
+
+	recorderMu.Lock()
+	recorder = ecs.NewClipRecorder(ent, name)
+	// the pose at t=0 is the starting point of the animation
+	recorder.Key()
+	recorderMu.Unlock()
+
+	sendClipRecordingState()
+}
+
+func setClipRecordTime(t float32) {
+	recorderMu.Lock()
+	if recorder != nil {
+		if t < 0 {
+			t = 0
+		}
+		recorder.Time = t
+	}
+	recorderMu.Unlock()
+	sendClipRecordingState()
+}
+
+func recordClipKey() {
+	recorderMu.Lock()
+	if recorder != nil {
+		recorder.Key()
+	}
+	recorderMu.Unlock()
+	sendClipRecordingState()
+}
+
+// RecordTransformEdit keys a finished transform edit (gizmo release or
+// inspector SetTransform) if the entity is being recorded.
+func RecordTransformEdit(id int64, pos [3]float32, rot [4]float32, scale [3]float32) {
+	recorderMu.Lock()
+	rec := recorder
+	if rec != nil && rec.Entity.ID == id {
+		rec.KeyPose(pos, rot, scale)
+	}
+	recorderMu.Unlock()
+
+	if rec != nil && rec.Entity.ID == id {
+		sendClipRecordingState()
+	}
+}
+
+func stopClipRecording(sc *scene.Scene, m MsgStopClipRecording) {
+	recorderMu.Lock()
+	rec := recorder
+	recorder = nil
+	recorderMu.Unlock()
+
+	if rec == nil {
+		return
+	}
+	if m.Save {
+		if err := saveRecordedClip(rec, m.Binary); err != nil {
+			log.Printf("editorlink: saving recorded clip %s failed: %v", rec.Name, err)
+		} else {
+			SendAssetList(EditorConn)
+			SendFullSnapshot(sc)
+		}
+	}
+	sendClipRecordingState()
+}
+
+// saveRecordedClip writes the clip, registers it as an asset and assigns it
+// to the entity's AnimationPlayer (adding one if needed).
+func saveRecordedClip(rec *ecs.ClipRecorder, binary bool) error {
+	clip := rec.Clip()
+	if len(clip.Tracks) == 0 {
+		return fmt.Errorf("no keys recorded")
+	}
+	if err := checkClipName(rec.Name); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(ClipDir, 0755); err != nil {
+		return err
+	}
+	ext := assets.ClipExtJSON
+	if binary {
+		ext = assets.ClipExtBinary
+	}
+	path := filepath.Join(ClipDir, rec.Name+ext)
+	if err := ecs.SaveAnimationClip(path, clip); err != nil {
+		return err
+	}
+	id, err := assets.LoadClip(path)
+	if err != nil {
+		return err
+	}
+
+	var player *ecs.AnimationPlayer
+	if c := rec.Entity.GetComponent((*ecs.AnimationPlayer)(nil)); c != nil {
+		player = c.(*ecs.AnimationPlayer)
+	} else {
+		player = ecs.ComponentRegistry["AnimationPlayer"]().(*ecs.AnimationPlayer)
+		rec.Entity.AddComponent(player)
+	}
+	player.AddClipAsset(id)
+
+	log.Printf("editorlink: recorded clip %s (%d keys) saved to %s", rec.Name, rec.KeyCount(), path)
+	return nil
+}
+
+// checkClipName makes sure a clip name is a plain file name, so the clip
+// is saved inside ClipDir.
+func checkClipName(name string) error {
+	if name == "." || name == ".." || strings.ContainsAny(name, `/\:`) {
+		return fmt.Errorf("clip name %q is not a file name", name)
+	}
+	return nil
+}
+
+func sendClipRecordingState() {
+	if EditorConn == nil {
+		return
+	}
+	recorderMu.Lock()
+	msg := MsgClipRecordingState{}
+	if recorder != nil {
+		msg = MsgClipRecordingState{
+			Recording: true,
+			EntityID:  uint64(recorder.Entity.ID),
+			Name:      recorder.Name,
+			Time:      recorder.Time,
+			Keys:      recorder.KeyCount(),
+		}
+	}
+	recorderMu.Unlock()
+
+	if err := WriteClipRecordingState(EditorConn, msg); err != nil {
+		log.Printf("editorlink: failed to send ClipRecordingState: %v", err)
+	}
+}
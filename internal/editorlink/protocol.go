@@ -91,18 +91,54 @@ type MsgAssetList struct {
 	Meshes    []AssetView `json:"meshes"`
 	Materials []AssetView `json:"materials"`
 	Shaders   []AssetView `json:"shaders"`
+	Clips     []AssetView `json:"clips"`
 }
 
 type AssetView struct {
 	ID           uint64         `json:"id"`
+	GUID         string         `json:"guid,omitempty"`
 	Path         string         `json:"path"`
 	Type         string         `json:"type"`
 	MeshIDs      []string       `json:"mesh_ids,omitempty"`
 	MaterialData map[string]any `json:"material_data,omitempty"`
 	ShaderData   map[string]any `json:"shader_data,omitempty"`
+	ClipData     map[string]any `json:"clip_data,omitempty"`
 }
 type MsgRequestAssetList struct{}
 
+// MsgMoveAsset renames or moves an asset file (and its .meta sidecar) on the
+// game side. The asset keeps its GUID and ID, so references stay valid.
+type MsgMoveAsset struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// MsgRequestMemoryReport asks the game for per-asset memory use.
+type MsgRequestMemoryReport struct{}
+
+// AssetMemoryView is one asset's row in MsgMemoryReport.
+type AssetMemoryView struct {
+	ID       uint64 `json:"id"`
+	Path     string `json:"path"`
+	Type     string `json:"type"`
+	Refs     int    `json:"refs"`
+	CPUBytes int64  `json:"cpu_bytes"`
+	GPUBytes int64  `json:"gpu_bytes"`
+}
+
+// MsgMemoryReport lists loaded assets, largest first, with totals.
+type MsgMemoryReport struct {
+	Assets   []AssetMemoryView `json:"assets"`
+	CPUBytes int64             `json:"cpu_bytes"`
+	GPUBytes int64             `json:"gpu_bytes"`
+}
+
+// MsgAssetsReloaded tells the editor which asset files the game hot-reloaded,
+// dependencies first.
+type MsgAssetsReloaded struct {
+	Paths []string `json:"paths"`
+}
+
 // MsgRequestThumbnail asks the game to generate/send a thumbnail for AssetID.
 type MsgRequestThumbnail struct {
 	AssetID uint64 `json:"asset_id"`
@@ -182,6 +218,43 @@ type MsgInstantiatePrefab struct {
 	Path string `json:"path"`
 }
 
+// MsgExportGLTF exports the scene (EntityID 0) or an entity subtree to a
+// .gltf/.glb file.
+type MsgExportGLTF struct {
+	EntityID int64  `json:"entity,omitempty"`
+	Path     string `json:"path"`
+}
+
+// Clip recording (editor -> game). While recording, every finished gizmo
+// or inspector edit of the entity is keyed at the current record time.
+type MsgStartClipRecording struct {
+	EntityID uint64 `json:"entity"`
+	Name     string `json:"name"`
+}
+
+type MsgSetClipRecordTime struct {
+	Time float32 `json:"time"`
+}
+
+type MsgRecordClipKey struct{}
+
+// MsgStopClipRecording ends the recording. With Save the clip is written to
+// assets/animations (binary .clip or .clip.json), registered as an asset
+// and assigned to the entity's AnimationPlayer.
+type MsgStopClipRecording struct {
+	Save   bool `json:"save"`
+	Binary bool `json:"binary"`
+}
+
+// MsgClipRecordingState reports the recorder state (game -> editor).
+type MsgClipRecordingState struct {
+	Recording bool    `json:"recording"`
+	EntityID  uint64  `json:"entity"`
+	Name      string  `json:"name"`
+	Time      float32 `json:"time"`
+	Keys      int     `json:"keys"`
+}
+
 func readMsg(conn net.Conn) (Msg, error) {
 	var m Msg
 	header := make([]byte, 4)
@@ -320,6 +393,18 @@ func WriteRequestAssetList(conn net.Conn) error {
 	return writeMsg(conn, "RequestAssetList", MsgRequestAssetList{})
 }
 
+func WriteMoveAsset(conn net.Conn, from, to string) error {
+	return writeMsg(conn, "MoveAsset", MsgMoveAsset{From: from, To: to})
+}
+
+func WriteRequestMemoryReport(conn net.Conn) error {
+	return writeMsg(conn, "RequestMemoryReport", MsgRequestMemoryReport{})
+}
+
+func WriteAssetsReloaded(conn net.Conn, paths []string) error {
+	return writeMsg(conn, "AssetsReloaded", MsgAssetsReloaded{Paths: paths})
+}
+
 // WriteRequestThumbnail sends a thumbnail request to the game.
 // conn must be a live net.Conn (editorlink.EditorConn).
 func WriteRequestThumbnail(conn net.Conn, assetID uint64, size int) error {
@@ -418,7 +503,34 @@ func WriteSavePrefab(conn net.Conn, id int64, path string) error {
 	return writeMsg(conn, "SavePrefab", msg)
 }
 
+func WriteExportGLTF(conn net.Conn, id int64, path string) error {
+	msg := MsgExportGLTF{EntityID: id, Path: path}
+	return writeMsg(conn, "ExportGLTF", msg)
+}
+
 func WriteInstantiatePrefab(conn net.Conn, path string) error {
 	msg := MsgInstantiatePrefab{Path: path}
 	return writeMsg(conn, "InstantiatePrefab", msg)
 }
+
+func WriteStartClipRecording(conn net.Conn, entityID int64, name string) error {
+	msg := MsgStartClipRecording{EntityID: uint64(entityID), Name: name}
+	return writeMsg(conn, "StartClipRecording", msg)
+}
+
+func WriteSetClipRecordTime(conn net.Conn, t float32) error {
+	return writeMsg(conn, "SetClipRecordTime", MsgSetClipRecordTime{Time: t})
+}
+
+func WriteRecordClipKey(conn net.Conn) error {
+	return writeMsg(conn, "RecordClipKey", MsgRecordClipKey{})
+}
+
+func WriteStopClipRecording(conn net.Conn, save, binary bool) error {
+	msg := MsgStopClipRecording{Save: save, Binary: binary}
+	return writeMsg(conn, "StopClipRecording", msg)
+}
+
+func WriteClipRecordingState(conn net.Conn, msg MsgClipRecordingState) error {
+	return writeMsg(conn, "ClipRecordingState", msg)
+}
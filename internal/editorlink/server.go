@@ -6,6 +6,7 @@ import (
 	"log"
 	"net"
 	"os"
+	"path/filepath"
 
 	"go-engine/Go-Cordance/cmd/game/loader"
 	"go-engine/Go-Cordance/internal/assets"
@@ -20,6 +21,7 @@ import (
 	"go-engine/Go-Cordance/internal/thumbnails"
 
 	"go-engine/Go-Cordance/internal/scene"
+	"go-engine/Go-Cordance/internal/scene/gltf"
 )
 
 var EditorConn net.Conn
@@ -104,6 +106,7 @@ func handleConn(conn net.Conn, sc *scene.Scene, camSys *ecs.CameraSystem) {
 				tr.Rotation = msgST.Rotation
 				tr.Scale = msgST.Scale
 			}
+			RecordTransformEdit(int64(msgST.ID), msgST.Position, msgST.Rotation, msgST.Scale)
 
 			log.Printf("editorlink: updated transform for %d", msgST.ID)
 			if EditorConn != nil {
@@ -264,6 +267,10 @@ func handleConn(conn net.Conn, sc *scene.Scene, camSys *ecs.CameraSystem) {
 			if err := writeMsg(conn, "AssetList", resp); err != nil {
 				log.Printf("editorlink: failed to send AssetList: %v", err)
 			}
+		case "RequestMemoryReport":
+			if err := writeMsg(conn, "MemoryReport", buildMemoryReport()); err != nil {
+				log.Printf("editorlink: failed to send MemoryReport: %v", err)
+			}
 		case "RequestAssetReload":
 			loader.AssetReloadChan <- loader.AssetReloadRequest{
 				Textures: true,
@@ -272,6 +279,7 @@ func handleConn(conn net.Conn, sc *scene.Scene, camSys *ecs.CameraSystem) {
 
 			loader.LoadMaterials()
 			loader.LoadShaders()
+			loader.LoadClips()
 
 			SendAssetList(conn)
 		case "RequestThumbnail":
@@ -315,6 +323,8 @@ func handleConn(conn net.Conn, sc *scene.Scene, camSys *ecs.CameraSystem) {
 			}
 			sc.ReplaceWith(newScene)
 			RebindTransformCallbacks()
+			// Start the grace period for assets only the old scene used.
+			assets.Lifetime.Retain("scene", sc.AssetRefs())
 
 			// Also rebind camera + render system world
 			if RenderSystem != nil {
@@ -347,6 +357,36 @@ func handleConn(conn net.Conn, sc *scene.Scene, camSys *ecs.CameraSystem) {
 				log.Printf("SavePrefab failed: %v", err)
 			}
 
+		case "ExportGLTF":
+			var m MsgExportGLTF
+			json.Unmarshal(msg.Data, &m)
+
+			var err error
+			if m.EntityID != 0 {
+				ent := sc.World().FindByID(m.EntityID)
+				if ent == nil {
+					log.Printf("ExportGLTF: entity %d not found", m.EntityID)
+					continue
+				}
+				err = gltf.ExportEntity(ent, engine.GlobalMeshManager, m.Path)
+			} else {
+				err = gltf.ExportScene(sc, engine.GlobalMeshManager, m.Path)
+			}
+			if err != nil {
+				log.Printf("ExportGLTF failed: %v", err)
+			}
+
+		case "MoveAsset":
+			var m MsgMoveAsset
+			json.Unmarshal(msg.Data, &m)
+
+			if err := assets.Move(m.From, m.To); err != nil {
+				log.Printf("MoveAsset failed: %v", err)
+				continue
+			}
+			retargetAssetPath(sc, m.From, m.To)
+			SendAssetList(conn)
+
 		case "InstantiatePrefab":
 			var m MsgInstantiatePrefab
 			json.Unmarshal(msg.Data, &m)
@@ -363,6 +403,30 @@ func handleConn(conn net.Conn, sc *scene.Scene, camSys *ecs.CameraSystem) {
 
 			SendFullSnapshot(sc)
 
+		case "StartClipRecording":
+			var m MsgStartClipRecording
+			if err := json.Unmarshal(msg.Data, &m); err != nil {
+				log.Printf("editorlink: bad StartClipRecording: %v", err)
+				continue
+			}
+			startClipRecording(sc, m)
+		case "SetClipRecordTime":
+			var m MsgSetClipRecordTime
+			if err := json.Unmarshal(msg.Data, &m); err != nil {
+				log.Printf("editorlink: bad SetClipRecordTime: %v", err)
+				continue
+			}
+			setClipRecordTime(m.Time)
+		case "RecordClipKey":
+			recordClipKey()
+		case "StopClipRecording":
+			var m MsgStopClipRecording
+			if err := json.Unmarshal(msg.Data, &m); err != nil {
+				log.Printf("editorlink: bad StopClipRecording: %v", err)
+				continue
+			}
+			stopClipRecording(sc, m)
+
 		default:
 			log.Printf("editorlink: unknown msg type %q", msg.Type)
 		}
@@ -477,6 +541,9 @@ func buildSceneSnapshot(sc *scene.Scene) SceneSnapshot {
 		if ent.GetComponent((*ecs.Mesh)(nil)) != nil {
 			view.Components = append(view.Components, "Mesh")
 		}
+		if ent.GetComponent((*ecs.AnimationPlayer)(nil)) != nil {
+			view.Components = append(view.Components, "AnimationPlayer")
+		}
 		// Parent
 		if c := ent.GetComponent((*ecs.Parent)(nil)); c != nil {
 			p := c.(*ecs.Parent)
@@ -595,18 +662,45 @@ func SendFullSnapshot(sc *scene.Scene) {
 	}
 }
 
+// retargetAssetPath rewrites path-based references to a moved asset. ID
+// references need no update: the asset keeps its GUID-derived ID.
+func retargetAssetPath(sc *scene.Scene, from, to string) {
+	from, to = filepath.ToSlash(from), filepath.ToSlash(to)
+	swap := func(p *string) {
+		if filepath.ToSlash(*p) == from {
+			*p = to
+		}
+	}
+	for _, e := range sc.Entities() {
+		if mat, ok := e.GetComponent((*ecs.Material)(nil)).(*ecs.Material); ok {
+			swap(&mat.DiffuseTexturePath)
+			swap(&mat.NormalTexturePath)
+			swap(&mat.OcclusionTexturePath)
+			swap(&mat.MetallicRoughnessTexturePath)
+		}
+		if ap, ok := e.GetComponent((*ecs.AnimationPlayer)(nil)).(*ecs.AnimationPlayer); ok {
+			for name, p := range ap.ClipSources {
+				swap(&p)
+				ap.ClipSources[name] = p
+			}
+		}
+	}
+}
+
 func buildAssetList() MsgAssetList {
 	out := MsgAssetList{
 		Textures:  []AssetView{},
 		Meshes:    []AssetView{},
 		Materials: []AssetView{},
 		Shaders:   []AssetView{},
+		Clips:     []AssetView{},
 	}
 
 	for _, a := range assets.All() {
 
 		view := AssetView{
 			ID:   uint64(a.ID),
+			GUID: string(a.GUID),
 			Path: a.Path,
 			Type: assetTypeToString(a.Type),
 		}
@@ -645,8 +739,18 @@ func buildAssetList() MsgAssetList {
 				"vertex":   sf.VertexPath,
 				"fragment": sf.FragmentPath,
 				"defines":  sf.Defines,
+				"keywords": sf.Keywords,
 			}
 			out.Shaders = append(out.Shaders, view)
+		case assets.AssetAnimationClip:
+			cf := a.Data.(assets.ClipFile)
+			view.ClipData = map[string]any{
+				"name":     cf.Name,
+				"duration": cf.Duration,
+				"tracks":   len(cf.Tracks),
+				"events":   len(cf.Events),
+			}
+			out.Clips = append(out.Clips, view)
 
 		}
 	}
@@ -664,10 +768,31 @@ func assetTypeToString(t assets.AssetType) string {
 		return "Material"
 	case assets.AssetShader:
 		return "Shader"
+	case assets.AssetAnimationClip:
+		return "AnimationClip"
+	case assets.AssetEnvironment:
+		return "Environment"
 	}
 	return "Unknown"
 }
 
+func buildMemoryReport() MsgMemoryReport {
+	var out MsgMemoryReport
+	for _, m := range assets.Lifetime.Report() {
+		out.Assets = append(out.Assets, AssetMemoryView{
+			ID:       uint64(m.ID),
+			Path:     m.Path,
+			Type:     assetTypeToString(m.Type),
+			Refs:     m.Refs,
+			CPUBytes: m.CPUBytes,
+			GPUBytes: m.GPUBytes,
+		})
+		out.CPUBytes += m.CPUBytes
+		out.GPUBytes += m.GPUBytes
+	}
+	return out
+}
+
 func SendAssetList(conn net.Conn) {
 	if conn == nil {
 		return
@@ -686,6 +811,7 @@ func RebindTransformCallbacks() {
 	}
 
 	bridge2.SendTransformToEditorFinal = func(id int64, pos [3]float32, rot [4]float32, scale [3]float32) {
+		RecordTransformEdit(id, pos, rot, scale)
 		if EditorConn != nil {
 			msg := MsgSetTransform{
 				ID:       uint64(id),
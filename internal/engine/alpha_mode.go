@@ -0,0 +1,42 @@
+package engine
+
+import "strings"
+
+// AlphaMode is how a material's base colour alpha is used, as in glTF's
+// material.alphaMode.
+type AlphaMode int
+
+const (
+	AlphaOpaque AlphaMode = iota // alpha ignored
+	AlphaMask                    // fragments below the cutoff are discarded
+	AlphaBlend                   // blended over what is behind
+)
+
+// DefaultAlphaCutoff is glTF's default alphaCutoff for AlphaMask.
+const DefaultAlphaCutoff = 0.5
+
+var alphaModeNames = [...]string{"OPAQUE", "MASK", "BLEND"}
+
+func (m AlphaMode) String() string {
+	if m >= 0 && int(m) < len(alphaModeNames) {
+		return alphaModeNames[m]
+	}
+	return "OPAQUE"
+}
+
+// AlphaModeNames lists the modes in order, for editor dropdowns.
+func AlphaModeNames() []string { return alphaModeNames[:] }
+
+// ParseAlphaMode reads a glTF alphaMode, ignoring case. Empty and unknown
+// values are opaque, with ok false for unknown ones.
+func ParseAlphaMode(s string) (m AlphaMode, ok bool) {
+	if s == "" {
+		return AlphaOpaque, true
+	}
+	for i, name := range alphaModeNames {
+		if strings.EqualFold(s, name) {
+			return AlphaMode(i), true
+		}
+	}
+	return AlphaOpaque, false
+}
@@ -0,0 +1,77 @@
+package engine
+
+import "sync"
+
+// Texture sizes are recorded at upload so memory reports do not need a GL
+// query per texture.
+var (
+	textureMu    sync.Mutex
+	textureBytes = map[uint32]int64{}
+)
+
+// mipChainBytes is the RGBA8 size of a full mip chain for w x h.
+func mipChainBytes(w, h int) int64 {
+	var n int64
+	for {
+		n += int64(w) * int64(h) * 4
+		if w == 1 && h == 1 {
+			return n
+		}
+		w, h = max(w/2, 1), max(h/2, 1)
+	}
+}
+
+func recordTextureBytes(tex uint32, n int64) {
+	textureMu.Lock()
+	textureBytes[tex] = n
+	textureMu.Unlock()
+}
+
+// TextureBytes returns the recorded GPU size of a texture loaded by this
+// package, or 0 if unknown.
+func TextureBytes(tex uint32) int64 {
+	textureMu.Lock()
+	defer textureMu.Unlock()
+	return textureBytes[tex]
+}
+
+func forgetTextureBytes(tex uint32) {
+	textureMu.Lock()
+	delete(textureBytes, tex)
+	textureMu.Unlock()
+}
+
+// MeshBytes returns the CPU-side copies and GPU buffer sizes kept for a mesh.
+func (mm *MeshManager) MeshBytes(id string) (cpu, gpu int64) {
+	vc := int64(mm.vertexCounts[id])
+	idx := int64(mm.GetIndexType(id).Size())
+	gpu = vc*int64(mm.layoutType[id])*4 + int64(mm.counts[id])*idx
+	if _, ok := mm.vbos[id+"_joints"]; ok {
+		gpu += vc * 8
+	}
+	if _, ok := mm.vbos[id+"_weights"]; ok {
+		gpu += vc * 16
+	}
+	if _, ok := mm.vbos[id+"_colors"]; ok {
+		gpu += vc * 16
+	}
+
+	if c, ok := mm.cpuMeshes[id]; ok {
+		cpu += int64(len(c.Vertices))*4 + int64(len(c.Indices))*4
+	}
+	cpu += int64(len(mm.JointData[id]))*8 + int64(len(mm.WeightData[id]))*16
+	cpu += int64(len(mm.PositionData[id]))*12 + int64(len(mm.NormalData[id]))*12
+	cpu += int64(len(mm.ColorData[id])) * 16
+	return cpu, gpu
+}
+
+// GLAssetBackend frees and measures the GL resources behind assets; it
+// satisfies assets.Backend.
+type GLAssetBackend struct {
+	Meshes *MeshManager
+}
+
+func (b GLAssetBackend) DeleteTexture(tex uint32)           { DeleteTexture(tex) }
+func (b GLAssetBackend) DeleteMesh(id string)               { b.Meshes.ReleaseMesh(id) }
+func (b GLAssetBackend) TextureBytes(tex uint32) int64      { return TextureBytes(tex) }
+func (b GLAssetBackend) MeshBytes(id string) (int64, int64) { return b.Meshes.MeshBytes(id) }
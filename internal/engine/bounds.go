@@ -0,0 +1,189 @@
+package engine
+
+import "math"
+
+// AABB is an axis-aligned bounding box. The zero value is a point at the
+// origin; use EmptyAABB to start a union.
+type AABB struct {
+	Min, Max [3]float32
+}
+
+// Sphere is a bounding sphere.
+type Sphere struct {
+	Center [3]float32
+	Radius float32
+}
+
+// MeshBounds are the local-space bounds of a mesh, computed when it is
+// registered.
+type MeshBounds struct {
+	Box    AABB
+	Sphere Sphere
+}
+
+// EmptyAABB returns a box that any Extend or Union replaces.
+func EmptyAABB() AABB {
+	inf := float32(math.Inf(1))
+	return AABB{Min: [3]float32{inf, inf, inf}, Max: [3]float32{-inf, -inf, -inf}}
+}
+
+// IsEmpty reports whether the box contains no point.
+func (b AABB) IsEmpty() bool { return b.Min[0] > b.Max[0] }
+
+// Extend grows the box to contain p.
+func (b AABB) Extend(p [3]float32) AABB {
+	for i := 0; i < 3; i++ {
+		b.Min[i] = min(b.Min[i], p[i])
+		b.Max[i] = max(b.Max[i], p[i])
+	}
+	return b
+}
+
+// Union returns the smallest box containing b and o.
+func (b AABB) Union(o AABB) AABB {
+	if o.IsEmpty() {
+		return b
+	}
+	return b.Extend(o.Min).Extend(o.Max)
+}
+
+// Center returns the midpoint of the box.
+func (b AABB) Center() [3]float32 {
+	return [3]float32{(b.Min[0] + b.Max[0]) / 2, (b.Min[1] + b.Max[1]) / 2, (b.Min[2] + b.Max[2]) / 2}
+}
+
+// Extents returns the half-size of the box on each axis.
+func (b AABB) Extents() [3]float32 {
+	return [3]float32{(b.Max[0] - b.Min[0]) / 2, (b.Max[1] - b.Min[1]) / 2, (b.Max[2] - b.Min[2]) / 2}
+}
+
+// Transform returns the world box enclosing b under the column-major affine
+// matrix m (Arvo's method: the centre moves, the extents go through |m|).
+func (b AABB) Transform(m [16]float32) AABB {
+	if b.IsEmpty() {
+		return b
+	}
+	c, e := b.Center(), b.Extents()
+	wc := TransformPoint(m, c)
+	var out AABB
+	for row := 0; row < 3; row++ {
+		r := abs32(m[row])*e[0] + abs32(m[4+row])*e[1] + abs32(m[8+row])*e[2]
+		out.Min[row], out.Max[row] = wc[row]-r, wc[row]+r
+	}
+	return out
+}
+
+// BoundingSphere returns the sphere around the box centre through its corners.
+func (b AABB) BoundingSphere() Sphere {
+	if b.IsEmpty() {
+		return Sphere{}
+	}
+	e := b.Extents()
+	return Sphere{Center: b.Center(), Radius: float32(math.Sqrt(float64(e[0]*e[0] + e[1]*e[1] + e[2]*e[2])))}
+}
+
+// ComputeMeshBounds scans interleaved vertices whose position is the first
+// three of every stride floats. The sphere is centred on the box and sized
+// to the farthest vertex, which is tighter than the box's own sphere.
+func ComputeMeshBounds(vertices []float32, stride int) MeshBounds {
+	box := EmptyAABB()
+	for i := 0; i+2 < len(vertices); i += stride {
+		box = box.Extend([3]float32{vertices[i], vertices[i+1], vertices[i+2]})
+	}
+	if box.IsEmpty() {
+		return MeshBounds{Box: box}
+	}
+	c := box.Center()
+	var r2 float32
+	for i := 0; i+2 < len(vertices); i += stride {
+		dx, dy, dz := vertices[i]-c[0], vertices[i+1]-c[1], vertices[i+2]-c[2]
+		r2 = max(r2, dx*dx+dy*dy+dz*dz)
+	}
+	return MeshBounds{Box: box, Sphere: Sphere{Center: c, Radius: float32(math.Sqrt(float64(r2)))}}
+}
+
+// Plane is the set of points p with dot(Normal, p) + D = 0; the normal
+// points into the frustum.
+type Plane struct {
+	Normal [3]float32
+	D      float32
+}
+
+// Distance is the signed distance of p from the plane, positive inside.
+func (p Plane) Distance(v [3]float32) float32 {
+	return p.Normal[0]*v[0] + p.Normal[1]*v[1] + p.Normal[2]*v[2] + p.D
+}
+
+// Frustum holds the left, right, bottom, top, near and far planes.
+type Frustum [6]Plane
+
+// FrustumFromMatrix extracts the planes of a column-major view-projection
+// matrix with OpenGL clip space (-w..w on every axis), after Gribb and
+// Hartmann. Works for perspective and orthographic projections alike.
+func FrustumFromMatrix(m [16]float32) Frustum {
+	row := func(r int) [4]float32 { return [4]float32{m[r], m[4+r], m[8+r], m[12+r]} }
+	r0, r1, r2, r3 := row(0), row(1), row(2), row(3)
+	plane := func(a [4]float32, sign float32) Plane {
+		p := Plane{
+			Normal: [3]float32{r3[0] + sign*a[0], r3[1] + sign*a[1], r3[2] + sign*a[2]},
+			D:      r3[3] + sign*a[3],
+		}
+		l := float32(math.Sqrt(float64(p.Normal[0]*p.Normal[0] + p.Normal[1]*p.Normal[1] + p.Normal[2]*p.Normal[2])))
+		if l > 0 {
+			p.Normal = [3]float32{p.Normal[0] / l, p.Normal[1] / l, p.Normal[2] / l}
+			p.D /= l
+		}
+		return p
+	}
+	return Frustum{
+		plane(r0, 1), plane(r0, -1),
+		plane(r1, 1), plane(r1, -1),
+		plane(r2, 1), plane(r2, -1),
+	}
+}
+
+// IntersectsSphere reports whether s is at least partly inside f.
+func (f *Frustum) IntersectsSphere(s Sphere) bool {
+	for _, p := range f {
+		if p.Distance(s.Center) < -s.Radius {
+			return false
+		}
+	}
+	return true
+}
+
+// IntersectsAABB reports whether b is at least partly inside f. It tests
+// the corner farthest along each plane normal, so a box that straddles two
+// planes outside a frustum corner can still pass; that only costs a draw.
+func (f *Frustum) IntersectsAABB(b AABB) bool {
+	for _, p := range f {
+		var v [3]float32
+		for i := 0; i < 3; i++ {
+			if p.Normal[i] >= 0 {
+				v[i] = b.Max[i]
+			} else {
+				v[i] = b.Min[i]
+			}
+		}
+		if p.Distance(v) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// CullStats counts bounds tested against a frustum and how many passed.
+type CullStats struct {
+	Tested  int
+	Visible int
+}
+
+// Culled is the number of tested bounds that were rejected.
+func (s CullStats) Culled() int { return s.Tested - s.Visible }
+
+func abs32(f float32) float32 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func near3(a, b [3]float32) bool {
+	for i := range a {
+		if math.Abs(float64(a[i]-b[i])) > 1e-4 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestComputeMeshBounds(t *testing.T) {
+	// Two 12-float vertices; the non-position floats must be ignored.
+	verts := []float32{
+		-1, 0, 2, 9, 9, 9, 9, 9, 9, 9, 9, 9,
+		3, 4, 2, -9, -9, -9, -9, -9, -9, -9, -9, -9,
+	}
+	b := ComputeMeshBounds(verts, 12)
+	if b.Box.Min != [3]float32{-1, 0, 2} || b.Box.Max != [3]float32{3, 4, 2} {
+		t.Fatalf("box %+v", b.Box)
+	}
+	if b.Sphere.Center != [3]float32{1, 2, 2} || math.Abs(float64(b.Sphere.Radius)-math.Sqrt(8)) > 1e-5 {
+		t.Fatalf("sphere %+v", b.Sphere)
+	}
+	if e := ComputeMeshBounds(nil, 12); !e.Box.IsEmpty() {
+		t.Fatalf("empty mesh gave %+v", e.Box)
+	}
+}
+
+func TestAABBTransform(t *testing.T) {
+	box := AABB{Min: [3]float32{-1, -1, -1}, Max: [3]float32{1, 1, 1}}
+	m := mgl32.Translate3D(10, 0, 0).Mul4(mgl32.HomogRotate3DY(math.Pi / 4)).Mul4(mgl32.Scale3D(2, 1, 1))
+	got := box.Transform(m)
+	// The 2x1 footprint rotated 45 degrees spans (2+1)/sqrt2 on X and Z.
+	r := float32(3 / math.Sqrt2)
+	if !near3(got.Min, [3]float32{10 - r, -1, -r}) || !near3(got.Max, [3]float32{10 + r, 1, r}) {
+		t.Fatalf("transformed box %+v", got)
+	}
+}
+
+func TestFrustumPerspective(t *testing.T) {
+	view := mgl32.LookAtV(mgl32.Vec3{0, 0, 0}, mgl32.Vec3{0, 0, -1}, mgl32.Vec3{0, 1, 0})
+	f := FrustumFromMatrix(mgl32.Perspective(mgl32.DegToRad(90), 1, 0.1, 100).Mul4(view))
+
+	unit := func(x, y, z float32) AABB {
+		return AABB{Min: [3]float32{x - 0.5, y - 0.5, z - 0.5}, Max: [3]float32{x + 0.5, y + 0.5, z + 0.5}}
+	}
+	cases := []struct {
+		name string
+		box  AABB
+		want bool
+	}{
+		{"ahead", unit(0, 0, -10), true},
+		{"behind", unit(0, 0, 10), false},
+		{"left", unit(-20, 0, -10), false},
+		{"above", unit(0, 20, -10), false},
+		{"beyond far", unit(0, 0, -200), false},
+		{"straddling near", unit(0, 0, 0), true},
+		{"straddling right", unit(10.2, 0, -10), true},
+	}
+	for _, c := range cases {
+		if got := f.IntersectsAABB(c.box); got != c.want {
+			t.Errorf("%s: box visible=%v, want %v", c.name, got, c.want)
+		}
+		if got := f.IntersectsSphere(c.box.BoundingSphere()); c.want && !got {
+			t.Errorf("%s: sphere culled a visible box", c.name)
+		}
+	}
+}
+
+func TestFrustumOrthographic(t *testing.T) {
+	f := FrustumFromMatrix(mgl32.Ortho(-5, 5, -5, 5, 1, 20))
+	if !f.IntersectsSphere(Sphere{Center: [3]float32{4, 0, -10}, Radius: 0.5}) {
+		t.Error("sphere inside the box was culled")
+	}
+	if f.IntersectsSphere(Sphere{Center: [3]float32{6, 0, -10}, Radius: 0.5}) {
+		t.Error("sphere right of the box was kept")
+	}
+	if f.IntersectsSphere(Sphere{Center: [3]float32{0, 0, 0}, Radius: 0.5}) {
+		t.Error("sphere before the near plane was kept")
+	}
+}
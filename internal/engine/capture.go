@@ -0,0 +1,98 @@
+package engine
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+)
+
+// CaptureTarget is an offscreen RGBA8 framebuffer with a depth buffer
+// that frames are rendered into and read back from as images, at any
+// size independent of the window.
+type CaptureTarget struct {
+	Width, Height int
+
+	fbo   uint32
+	tex   uint32
+	depth uint32 // renderbuffer
+}
+
+// NewCaptureTarget creates a width x height capture target.
+func NewCaptureTarget(width, height int) (*CaptureTarget, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("capture: invalid size %dx%d", width, height)
+	}
+	c := &CaptureTarget{Width: width, Height: height}
+	c.tex = Device.CreateTexture2D(TextureDesc{
+		Width: width, Height: height,
+		Format: RGBA8, Filter: FilterNearest, Wrap: WrapClampToEdge,
+	}, nil)
+	c.depth = Device.CreateRenderbuffer(Depth24, width, height)
+	fbo, err := Device.CreateFramebuffer(FramebufferDesc{Color: c.tex, DepthRenderbuffer: c.depth})
+	if err != nil {
+		Device.DeleteTexture(c.tex)
+		return nil, fmt.Errorf("capture: %w", err)
+	}
+	c.fbo = fbo
+	return c, nil
+}
+
+// Framebuffer is the framebuffer to render into.
+func (c *CaptureTarget) Framebuffer() uint32 { return c.fbo }
+
+// Begin binds the target with a viewport covering it and clears it to
+// opaque black.
+func (c *CaptureTarget) Begin() {
+	Device.BindFramebuffer(c.fbo)
+	Device.Viewport(0, 0, int32(c.Width), int32(c.Height))
+	Device.ClearColor(0, 0, 0, 1)
+	Device.Clear(ClearColorBit | ClearDepthBit)
+}
+
+// Image waits for rendering to finish and reads the target back, top row
+// first. Alpha is forced opaque: blended passes leave coverage, not
+// transparency, in it.
+func (c *CaptureTarget) Image() *image.RGBA {
+	buf := make([]byte, c.Width*c.Height*4)
+	Device.Finish()
+	Device.BindFramebuffer(c.fbo)
+	Device.ReadPixels(0, 0, int32(c.Width), int32(c.Height), buf)
+
+	img := image.NewRGBA(image.Rect(0, 0, c.Width, c.Height))
+	row := c.Width * 4
+	for y := 0; y < c.Height; y++ {
+		src := c.Height - 1 - y
+		copy(img.Pix[y*row:(y+1)*row], buf[src*row:(src+1)*row])
+	}
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+	return img
+}
+
+// Delete frees the framebuffer and colour texture. The depth
+// renderbuffer is left to the context: the device has no call to free
+// one.
+func (c *CaptureTarget) Delete() {
+	if c.fbo != 0 {
+		Device.DeleteFramebuffer(c.fbo)
+	}
+	if c.tex != 0 {
+		Device.DeleteTexture(c.tex)
+	}
+	*c = CaptureTarget{}
+}
+
+// WritePNG encodes img as a PNG file at path.
+func WritePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
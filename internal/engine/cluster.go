@@ -0,0 +1,194 @@
+package engine
+
+import "math"
+
+// ClusterConfig is the froxel grid used for clustered lighting: X by Y
+// screen tiles, each cut into Z depth slices spaced exponentially between
+// the camera's near and far planes.
+type ClusterConfig struct {
+	X, Y, Z int
+}
+
+// DefaultClusterConfig keeps tiles roughly square on a 16:9 screen.
+var DefaultClusterConfig = ClusterConfig{X: 16, Y: 9, Z: 24}
+
+// Count is the number of clusters in the grid.
+func (c ClusterConfig) Count() int { return c.X * c.Y * c.Z }
+
+// Index is the linear index of cluster (x, y, z); x varies fastest.
+func (c ClusterConfig) Index(x, y, z int) int { return (z*c.Y+y)*c.X + x }
+
+// LightClusters assigns point and spot lights to the clusters of a
+// perspective camera. Directional lights reach every cluster and are
+// left out; shaders loop over them separately.
+//
+// Cells holds an (offset, count) pair per cluster into Indices, which
+// lists light indices in the order the lights were passed to Build. This
+// is the layout the fragment shaders read from their buffer textures.
+type LightClusters struct {
+	Config    ClusterConfig
+	Near, Far float32
+
+	Bounds  []AABB // view-space bounds per cluster
+	Cells   []uint32
+	Indices []uint32
+
+	proj [16]float32
+}
+
+func NewLightClusters(cfg ClusterConfig) *LightClusters {
+	return &LightClusters{Config: cfg}
+}
+
+// PerspectiveDepthRange returns the near and far planes of a column-major
+// OpenGL perspective matrix.
+func PerspectiveDepthRange(proj [16]float32) (near, far float32) {
+	a, b := proj[10], proj[14]
+	return b / (a - 1), b / (a + 1)
+}
+
+// Slice returns the depth slice containing a point viewDepth units in
+// front of the camera, clamped to the grid.
+func (lc *LightClusters) Slice(viewDepth float32) int {
+	if viewDepth <= lc.Near {
+		return 0
+	}
+	z := int(math.Log(float64(viewDepth/lc.Near)) * float64(lc.SliceScale()))
+	return min(z, lc.Config.Z-1)
+}
+
+// SliceScale is Z / ln(far/near), the factor shaders multiply ln(depth/near)
+// by to find a fragment's slice.
+func (lc *LightClusters) SliceScale() float32 {
+	return float32(float64(lc.Config.Z) / math.Log(float64(lc.Far/lc.Near)))
+}
+
+// sliceDepth is the view depth where slice k starts.
+func (lc *LightClusters) sliceDepth(k int) float32 {
+	return lc.Near * float32(math.Pow(float64(lc.Far/lc.Near), float64(k)/float64(lc.Config.Z)))
+}
+
+// updateBounds recomputes the view-space cluster boxes when the projection
+// changes. The first slice reaches back to the eye and the last one out to
+// the far plane so that every visible fragment falls inside some cluster.
+func (lc *LightClusters) updateBounds(proj [16]float32) {
+	if proj == lc.proj && len(lc.Bounds) == lc.Config.Count() {
+		return
+	}
+	lc.proj = proj
+	lc.Near, lc.Far = PerspectiveDepthRange(proj)
+
+	cfg := lc.Config
+	lc.Bounds = make([]AABB, cfg.Count())
+	// A point at NDC (nx, ny) and view depth d sits at
+	// x = d*(nx+P[8])/P[0], y = d*(ny+P[9])/P[5], z = -d.
+	unproject := func(nx, ny, d float32) [3]float32 {
+		return [3]float32{d * (nx + proj[8]) / proj[0], d * (ny + proj[9]) / proj[5], -d}
+	}
+	for z := 0; z < cfg.Z; z++ {
+		d0, d1 := lc.sliceDepth(z), lc.sliceDepth(z+1)
+		if z == 0 {
+			d0 = 0
+		}
+		for y := 0; y < cfg.Y; y++ {
+			ny0 := -1 + 2*float32(y)/float32(cfg.Y)
+			ny1 := -1 + 2*float32(y+1)/float32(cfg.Y)
+			for x := 0; x < cfg.X; x++ {
+				nx0 := -1 + 2*float32(x)/float32(cfg.X)
+				nx1 := -1 + 2*float32(x+1)/float32(cfg.X)
+				b := EmptyAABB()
+				for _, d := range [2]float32{d0, d1} {
+					b = b.Extend(unproject(nx0, ny0, d)).
+						Extend(unproject(nx1, ny0, d)).
+						Extend(unproject(nx0, ny1, d)).
+						Extend(unproject(nx1, ny1, d))
+				}
+				lc.Bounds[cfg.Index(x, y, z)] = b
+			}
+		}
+	}
+}
+
+// Build assigns lights to the clusters of the camera given by view and
+// proj. Lights are tested by their bounding sphere in view space.
+func (lc *LightClusters) Build(view, proj [16]float32, lights []LightData) {
+	lc.updateBounds(proj)
+	cfg := lc.Config
+
+	// Bucket light indices per cluster, then flatten.
+	perCluster := make([][]uint32, cfg.Count())
+	for i, l := range lights {
+		s, ok := LightBoundingSphere(l)
+		if !ok {
+			continue
+		}
+		s.Center = TransformPoint(view, s.Center)
+		depth := -s.Center[2]
+		if depth+s.Radius <= 0 {
+			continue // entirely behind the camera
+		}
+		z0, z1 := lc.Slice(depth-s.Radius), lc.Slice(depth+s.Radius)
+		for z := z0; z <= z1; z++ {
+			for c := cfg.Index(0, 0, z); c < cfg.Index(0, 0, z+1); c++ {
+				if sphereIntersectsAABB(s, lc.Bounds[c]) {
+					perCluster[c] = append(perCluster[c], uint32(i))
+				}
+			}
+		}
+	}
+
+	lc.Cells = lc.Cells[:0]
+	lc.Indices = lc.Indices[:0]
+	for _, ids := range perCluster {
+		lc.Cells = append(lc.Cells, uint32(len(lc.Indices)), uint32(len(ids)))
+		lc.Indices = append(lc.Indices, ids...)
+	}
+}
+
+// Lights returns the light indices assigned to cluster (x, y, z).
+func (lc *LightClusters) Lights(x, y, z int) []uint32 {
+	c := lc.Config.Index(x, y, z)
+	off, n := lc.Cells[2*c], lc.Cells[2*c+1]
+	return lc.Indices[off : off+n]
+}
+
+// LightBoundingSphere returns a world-space sphere enclosing everything a
+// point or spot light reaches. Directional lights have none.
+func LightBoundingSphere(l LightData) (Sphere, bool) {
+	switch l.Type {
+	case LightTypePoint:
+		return Sphere{Center: l.Position, Radius: l.Range}, true
+	case LightTypeSpot:
+		// Smallest sphere around the cone with its spherical cap;
+		// Angle is the half-angle in degrees.
+		a := float64(l.Angle) * math.Pi / 180
+		if a >= math.Pi/2 {
+			return Sphere{Center: l.Position, Radius: l.Range}, true
+		}
+		dir := normalize(l.Direction)
+		var dist, r float32
+		if a <= math.Pi/4 {
+			dist = l.Range / (2 * float32(math.Cos(a)))
+			r = dist
+		} else {
+			dist = l.Range * float32(math.Cos(a))
+			r = l.Range * float32(math.Sin(a))
+		}
+		c := [3]float32{l.Position[0] + dir[0]*dist, l.Position[1] + dir[1]*dist, l.Position[2] + dir[2]*dist}
+		return Sphere{Center: c, Radius: r}, true
+	}
+	return Sphere{}, false
+}
+
+func sphereIntersectsAABB(s Sphere, b AABB) bool {
+	var d2 float32
+	for i := 0; i < 3; i++ {
+		v := s.Center[i]
+		if v < b.Min[i] {
+			d2 += (b.Min[i] - v) * (b.Min[i] - v)
+		} else if v > b.Max[i] {
+			d2 += (v - b.Max[i]) * (v - b.Max[i])
+		}
+	}
+	return d2 <= s.Radius*s.Radius
+}
@@ -0,0 +1,157 @@
+package engine
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func testCamera() (view, proj [16]float32) {
+	view = mgl32.LookAtV(mgl32.Vec3{0, 0, 0}, mgl32.Vec3{0, 0, -1}, mgl32.Vec3{0, 1, 0})
+	proj = mgl32.Perspective(mgl32.DegToRad(60), 16.0/9, 0.1, 100)
+	return
+}
+
+// clusterOf returns the cluster a view-space point falls in, as the shaders
+// compute it from the fragment's screen position and depth.
+func clusterOf(lc *LightClusters, proj mgl32.Mat4, p mgl32.Vec3) (x, y, z int) {
+	clip := proj.Mul4x1(p.Vec4(1))
+	nx, ny := clip.X()/clip.W(), clip.Y()/clip.W()
+	x = min(int((nx+1)/2*float32(lc.Config.X)), lc.Config.X-1)
+	y = min(int((ny+1)/2*float32(lc.Config.Y)), lc.Config.Y-1)
+	return x, y, lc.Slice(-p.Z())
+}
+
+func TestPerspectiveDepthRange(t *testing.T) {
+	_, proj := testCamera()
+	n, f := PerspectiveDepthRange(proj)
+	if math.Abs(float64(n-0.1)) > 1e-5 || math.Abs(float64(f-100)) > 1e-2 {
+		t.Fatalf("near/far = %v/%v, want 0.1/100", n, f)
+	}
+}
+
+func TestLightClusters_Slices(t *testing.T) {
+	_, proj := testCamera()
+	lc := NewLightClusters(DefaultClusterConfig)
+	lc.Build(mgl32.Ident4(), proj, nil)
+
+	if got := lc.Slice(0.05); got != 0 {
+		t.Errorf("depth before near in slice %d", got)
+	}
+	if got := lc.Slice(1000); got != lc.Config.Z-1 {
+		t.Errorf("depth past far in slice %d", got)
+	}
+	for k := 1; k < lc.Config.Z; k++ {
+		d := lc.sliceDepth(k)
+		if got := lc.Slice(d * 1.001); got != k {
+			t.Errorf("start of slice %d (depth %v) mapped to %d", k, d, got)
+		}
+	}
+	if len(lc.Cells) != 2*lc.Config.Count() || len(lc.Indices) != 0 {
+		t.Fatalf("empty build gave %d cells, %d indices", len(lc.Cells), len(lc.Indices))
+	}
+}
+
+func TestLightClusters_AssignsPointLight(t *testing.T) {
+	view, proj := testCamera()
+	lc := NewLightClusters(DefaultClusterConfig)
+	lights := []LightData{
+		{Type: LightTypeDirectional, Direction: [3]float32{0, -1, 0}},
+		{Type: LightTypePoint, Position: [3]float32{0, 0, -10}, Range: 1},
+		{Type: LightTypePoint, Position: [3]float32{0, 0, 10}, Range: 1}, // behind the camera
+	}
+	lc.Build(view, proj, lights)
+
+	x, y, z := clusterOf(lc, proj, mgl32.Vec3{0, 0, -10})
+	if got := lc.Lights(x, y, z); !slices.Equal(got, []uint32{1}) {
+		t.Fatalf("cluster (%d,%d,%d) lights %v, want [1]", x, y, z, got)
+	}
+	if got := lc.Lights(0, 0, z); len(got) != 0 {
+		t.Errorf("corner cluster lights %v, want none", got)
+	}
+	if got := lc.Lights(x, y, 0); len(got) != 0 {
+		t.Errorf("nearest cluster lights %v, want none", got)
+	}
+	for _, i := range lc.Indices {
+		if i != 1 {
+			t.Fatalf("light %d assigned to a cluster", i)
+		}
+	}
+}
+
+// Every point a light reaches must land in a cluster that lists the light,
+// no matter how many lights there are.
+func TestLightClusters_ManyLightsConservative(t *testing.T) {
+	view, proj := testCamera()
+	P := mgl32.Mat4(proj)
+	lc := NewLightClusters(DefaultClusterConfig)
+
+	rng := rand.New(rand.NewSource(1))
+	lights := make([]LightData, 500)
+	for i := range lights {
+		l := LightData{
+			Type:      LightTypePoint,
+			Position:  [3]float32{rng.Float32()*40 - 20, rng.Float32()*20 - 10, -rng.Float32() * 60},
+			Direction: [3]float32{rng.Float32() - 0.5, rng.Float32() - 0.5, rng.Float32() - 0.5},
+			Range:     0.5 + rng.Float32()*4,
+			Angle:     5 + rng.Float32()*80,
+		}
+		if i%2 == 1 {
+			l.Type = LightTypeSpot
+		}
+		lights[i] = l
+	}
+	lc.Build(view, proj, lights)
+
+	for i, l := range lights {
+		s, _ := LightBoundingSphere(l)
+		for j := 0; j < 20; j++ {
+			// Random point inside the light's bounding sphere.
+			dir := mgl32.Vec3{rng.Float32() - 0.5, rng.Float32() - 0.5, rng.Float32() - 0.5}.Normalize()
+			p := mgl32.Vec3(s.Center).Add(dir.Mul(s.Radius * rng.Float32()))
+			clip := P.Mul4x1(p.Vec4(1))
+			if clip.W() <= 0.1 || math.Abs(float64(clip.X())) > float64(clip.W()) || math.Abs(float64(clip.Y())) > float64(clip.W()) {
+				continue // off screen
+			}
+			x, y, z := clusterOf(lc, P, p)
+			if !slices.Contains(lc.Lights(x, y, z), uint32(i)) {
+				t.Fatalf("light %d reaches %v but cluster (%d,%d,%d) does not list it", i, p, x, y, z)
+			}
+		}
+	}
+}
+
+func TestLightBoundingSphere_Spot(t *testing.T) {
+	for _, angle := range []float32{10, 30, 45, 60, 80, 120} {
+		l := LightData{Type: LightTypeSpot, Position: [3]float32{1, 2, 3}, Direction: [3]float32{0, 0, -2}, Range: 5, Angle: angle}
+		s, ok := LightBoundingSphere(l)
+		if !ok {
+			t.Fatalf("angle %v: no sphere", angle)
+		}
+		a := float64(min(angle, 90)) * math.Pi / 180
+		rim := mgl32.Vec3{float32(math.Sin(a)) * 5, 0, -float32(math.Cos(a)) * 5}
+		for _, p := range []mgl32.Vec3{{}, {0, 0, -5}, rim} {
+			p = p.Add(mgl32.Vec3(l.Position))
+			if d := p.Sub(mgl32.Vec3(s.Center)).Len(); d > s.Radius+1e-4 {
+				t.Errorf("angle %v: point %v outside sphere %+v", angle, p, s)
+			}
+		}
+	}
+	if _, ok := LightBoundingSphere(LightData{Type: LightTypeDirectional}); ok {
+		t.Error("directional light has a bounding sphere")
+	}
+}
+
+func TestPackLights(t *testing.T) {
+	got := PackLights(nil, []LightData{{
+		Type: LightTypeSpot, Position: [3]float32{1, 2, 3}, Direction: [3]float32{0, -1, 0},
+		Range: 7, Color: [3]float32{0.5, 0.25, 1}, Intensity: 3, Angle: 20, Shadow: 5,
+	}})
+	want := []float32{1, 2, 3, 2, 0, -1, 0, 7, 0.5, 0.25, 1, 3, 20, 4, 0, 0}
+	if !slices.Equal(got, want) {
+		t.Fatalf("PackLights = %v, want %v", got, want)
+	}
+}
@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"fmt"
 	"runtime"
 
 	"github.com/go-gl/gl/v4.1-core/gl"
@@ -29,7 +30,7 @@ func InitGLFW(width, height int, title string) (*glfw.Window, error) {
 	window.MakeContextCurrent()
 	glfw.SwapInterval(1)
 
-	if err := gl.Init(); err != nil {
+	if err := Device.Init(); err != nil {
 		window.Destroy()
 		glfw.Terminate()
 		return nil, err
@@ -40,6 +41,53 @@ func InitGLFW(width, height int, title string) (*glfw.Window, error) {
 	gl.Viewport(0, 0, int32(width), int32(height))
 	return window, nil
 }
+
+// InitHeadlessGL initializes GLFW and makes current the OpenGL context of
+// a hidden width x height window, for rendering offscreen (captures and
+// golden-image tests). When the window system cannot create the context
+// it retries through OSMesa, if libOSMesa is installed. To render without
+// a GPU, use Mesa's llvmpipe: set LIBGL_ALWAYS_SOFTWARE=1 and, with no
+// display, run under Xvfb. Caller must call glfw.Terminate() when done.
+func InitHeadlessGL(width, height int) (window *glfw.Window, err error) {
+	runtime.LockOSThread() // required by GLFW / OpenGL
+	if err := glfw.Init(); err != nil {
+		return nil, err
+	}
+	// glfw.Init only logs window system failures; the first call after
+	// one panics with NotInitialized.
+	defer func() {
+		if r := recover(); r != nil {
+			window, err = nil, fmt.Errorf("headless GL: %v", r)
+		}
+	}()
+
+	for _, api := range []int{glfw.NativeContextAPI, glfw.OSMesaContextAPI} {
+		glfw.DefaultWindowHints()
+		glfw.WindowHint(glfw.Visible, glfw.False)
+		glfw.WindowHint(glfw.ContextVersionMajor, 4)
+		glfw.WindowHint(glfw.ContextVersionMinor, 2)
+		glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+		glfw.WindowHint(glfw.ContextCreationAPI, api)
+		if window, err = glfw.CreateWindow(width, height, "", nil, nil); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		glfw.Terminate()
+		return nil, err
+	}
+	window.MakeContextCurrent()
+
+	if err := Device.Init(); err != nil {
+		window.Destroy()
+		glfw.Terminate()
+		return nil, err
+	}
+
+	gl.Enable(gl.DEPTH_TEST)
+	gl.Viewport(0, 0, int32(width), int32(height))
+	return window, nil
+}
 func PollEvents() {
 	glfw.PollEvents()
 }
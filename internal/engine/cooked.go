@@ -0,0 +1,180 @@
+package engine
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Cooked mesh bundles (.mesh) hold one or more MeshData records already
+// interleaved, tangent-filled and triangulated, so loading them is a straight
+// read + upload. All values are little endian:
+//
+//	magic "GCMB", version u32, mesh count u32
+//	per mesh: id (u16 len + bytes), stride u32 (8 or 12), vertex count u32,
+//	index count u32, flags u32, vertices f32[], indices u32[],
+//	[joints u16x4[], weights f32x4[]] if flags&1, [colors f32x4[]] if flags&2
+const (
+	cookedMeshMagic   = "GCMB"
+	cookedMeshVersion = 1
+
+	cookedHasSkin   = 1 << 0
+	cookedHasColors = 1 << 1
+)
+
+var ErrBadCookedMesh = errors.New("invalid cooked mesh bundle")
+
+// WriteCookedMeshes serialises meshes. Meshes with real tangents keep the
+// 12-float layout; the placeholder tangents of the rest are dropped and the
+// record is written in the 8-float layout, which the reader widens again.
+func WriteCookedMeshes(w io.Writer, meshes []MeshData) error {
+	bw := bufio.NewWriter(w)
+	le := binary.LittleEndian
+
+	bw.WriteString(cookedMeshMagic)
+	binary.Write(bw, le, [2]uint32{cookedMeshVersion, uint32(len(meshes))})
+
+	for _, md := range meshes {
+		if len(md.Vertices)%12 != 0 {
+			return fmt.Errorf("cooked mesh %s: vertex data is not 12-float interleaved", md.ID)
+		}
+		vc := len(md.Vertices) / 12
+		stride, verts := 12, md.Vertices
+		if !md.HasTangents {
+			stride, verts = 8, make([]float32, 0, vc*8)
+			for i := 0; i < vc; i++ {
+				verts = append(verts, md.Vertices[i*12:i*12+8]...)
+			}
+		}
+
+		var flags uint32
+		if len(md.Joints) > 0 {
+			if len(md.Joints) != vc || len(md.Weights) != vc {
+				return fmt.Errorf("cooked mesh %s: skin streams do not match %d vertices", md.ID, vc)
+			}
+			flags |= cookedHasSkin
+		}
+		if len(md.Colors) > 0 {
+			if len(md.Colors) != vc {
+				return fmt.Errorf("cooked mesh %s: color stream does not match %d vertices", md.ID, vc)
+			}
+			flags |= cookedHasColors
+		}
+
+		binary.Write(bw, le, uint16(len(md.ID)))
+		bw.WriteString(md.ID)
+		binary.Write(bw, le, [4]uint32{uint32(stride), uint32(vc), uint32(len(md.Indices)), flags})
+		binary.Write(bw, le, verts)
+		binary.Write(bw, le, md.Indices)
+		if flags&cookedHasSkin != 0 {
+			binary.Write(bw, le, md.Joints)
+			binary.Write(bw, le, md.Weights)
+		}
+		if flags&cookedHasColors != 0 {
+			binary.Write(bw, le, md.Colors)
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadCookedMeshes parses a bundle written by WriteCookedMeshes. Records in
+// the 8-float layout are widened to 12 floats and get tangents computed.
+func ReadCookedMeshes(r io.Reader) ([]MeshData, error) {
+	br := bufio.NewReader(r)
+	le := binary.LittleEndian
+
+	var magic [4]byte
+	var hdr [2]uint32
+	if _, err := io.ReadFull(br, magic[:]); err != nil || string(magic[:]) != cookedMeshMagic {
+		return nil, ErrBadCookedMesh
+	}
+	if err := binary.Read(br, le, &hdr); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadCookedMesh, err)
+	}
+	if hdr[0] != cookedMeshVersion {
+		return nil, fmt.Errorf("%w: version %d", ErrBadCookedMesh, hdr[0])
+	}
+
+	meshes := make([]MeshData, 0, hdr[1])
+	for m := uint32(0); m < hdr[1]; m++ {
+		var idLen uint16
+		if err := binary.Read(br, le, &idLen); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrBadCookedMesh, err)
+		}
+		id := make([]byte, idLen)
+		if _, err := io.ReadFull(br, id); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrBadCookedMesh, err)
+		}
+		var info [4]uint32 // stride, vertex count, index count, flags
+		if err := binary.Read(br, le, &info); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrBadCookedMesh, err)
+		}
+		stride, vc, ic, flags := int(info[0]), int(info[1]), int(info[2]), info[3]
+		if stride != 8 && stride != 12 {
+			return nil, fmt.Errorf("%w: mesh %s has stride %d", ErrBadCookedMesh, id, stride)
+		}
+
+		md := MeshData{
+			ID:          string(id),
+			Vertices:    make([]float32, vc*stride),
+			Indices:     make([]uint32, ic),
+			HasTangents: true, // cooked 12-float data is final; 8-float gets them below
+		}
+		streams := []any{md.Vertices, md.Indices}
+		if flags&cookedHasSkin != 0 {
+			md.Joints = make([][4]uint16, vc)
+			md.Weights = make([][4]float32, vc)
+			streams = append(streams, md.Joints, md.Weights)
+		}
+		if flags&cookedHasColors != 0 {
+			md.Colors = make([][4]float32, vc)
+			streams = append(streams, md.Colors)
+		}
+		for _, s := range streams {
+			if err := binary.Read(br, le, s); err != nil {
+				return nil, fmt.Errorf("%w: mesh %s: %v", ErrBadCookedMesh, id, err)
+			}
+		}
+		for _, idx := range md.Indices {
+			if int(idx) >= vc {
+				return nil, fmt.Errorf("%w: mesh %s index %d >= %d vertices", ErrBadCookedMesh, id, idx, vc)
+			}
+		}
+
+		if stride == 8 {
+			wide := make([]float32, 0, vc*12)
+			for i := 0; i < vc; i++ {
+				wide = append(wide, md.Vertices[i*8:i*8+8]...)
+				wide = append(wide, 0, 0, 0, 1)
+			}
+			computeTangents(wide, md.Indices)
+			md.Vertices = wide
+		}
+		meshes = append(meshes, md)
+	}
+	return meshes, nil
+}
+
+// RegisterCookedMeshes uploads every mesh in a cooked bundle and returns
+// their IDs in file order.
+func (mm *MeshManager) RegisterCookedMeshes(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	meshes, err := ReadCookedMeshes(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	ids := make([]string, 0, len(meshes))
+	for _, md := range meshes {
+		mm.RegisterMeshData(md)
+		ids = append(ids, md.ID)
+	}
+	return ids, nil
+}
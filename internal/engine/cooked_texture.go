@@ -0,0 +1,175 @@
+package engine
+
+import (
+	"bufio"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"os"
+)
+
+// Cooked textures (.tex) store a full RGBA8 mip chain so the runtime skips
+// image decoding and glGenerateMipmap. Little endian:
+//
+//	magic "GCTX", version u32, width u32, height u32, levels u32, flags u32,
+//	then every level's pixels (zlib-compressed as one stream if flags&1)
+const (
+	cookedTexMagic   = "GCTX"
+	cookedTexVersion = 1
+
+	cookedTexZlib = 1 << 0
+)
+
+var ErrBadCookedTexture = errors.New("invalid cooked texture")
+
+// CookedTexture is an RGBA8 mip chain; Levels[0] is the full-size image.
+type CookedTexture struct {
+	Width, Height int
+	Levels        [][]byte
+}
+
+// BuildMipChain box-filters img down to 1x1.
+func BuildMipChain(img *image.RGBA) CookedTexture {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	base := make([]byte, w*h*4)
+	for y := 0; y < h; y++ {
+		copy(base[y*w*4:(y+1)*w*4], img.Pix[y*img.Stride:])
+	}
+
+	t := CookedTexture{Width: w, Height: h, Levels: [][]byte{base}}
+	for w > 1 || h > 1 {
+		nw, nh := max(w/2, 1), max(h/2, 1)
+		src, dst := t.Levels[len(t.Levels)-1], make([]byte, nw*nh*4)
+		for y := 0; y < nh; y++ {
+			for x := 0; x < nw; x++ {
+				// Odd sizes fold the last row/column into the final texel.
+				x0, y0 := x*w/nw, y*h/nh
+				x1, y1 := (x+1)*w/nw, (y+1)*h/nh
+				for c := 0; c < 4; c++ {
+					sum, n := 0, 0
+					for sy := y0; sy < y1; sy++ {
+						for sx := x0; sx < x1; sx++ {
+							sum += int(src[(sy*w+sx)*4+c])
+							n++
+						}
+					}
+					dst[(y*nw+x)*4+c] = byte((sum + n/2) / n)
+				}
+			}
+		}
+		t.Levels = append(t.Levels, dst)
+		w, h = nw, nh
+	}
+	return t
+}
+
+// WriteCookedTexture serialises t, optionally zlib-compressing the pixels.
+func WriteCookedTexture(w io.Writer, t CookedTexture, compress bool) error {
+	bw := bufio.NewWriter(w)
+	var flags uint32
+	if compress {
+		flags |= cookedTexZlib
+	}
+	bw.WriteString(cookedTexMagic)
+	binary.Write(bw, binary.LittleEndian, [5]uint32{
+		cookedTexVersion, uint32(t.Width), uint32(t.Height), uint32(len(t.Levels)), flags,
+	})
+
+	var pw io.Writer = bw
+	var zw *zlib.Writer
+	if compress {
+		zw = zlib.NewWriter(bw)
+		pw = zw
+	}
+	for _, lvl := range t.Levels {
+		if _, err := pw.Write(lvl); err != nil {
+			return err
+		}
+	}
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadCookedTexture parses a texture written by WriteCookedTexture.
+func ReadCookedTexture(r io.Reader) (CookedTexture, error) {
+	br := bufio.NewReader(r)
+	var magic [4]byte
+	var hdr [5]uint32 // version, width, height, levels, flags
+	if _, err := io.ReadFull(br, magic[:]); err != nil || string(magic[:]) != cookedTexMagic {
+		return CookedTexture{}, ErrBadCookedTexture
+	}
+	if err := binary.Read(br, binary.LittleEndian, &hdr); err != nil {
+		return CookedTexture{}, fmt.Errorf("%w: %v", ErrBadCookedTexture, err)
+	}
+	if hdr[0] != cookedTexVersion {
+		return CookedTexture{}, fmt.Errorf("%w: version %d", ErrBadCookedTexture, hdr[0])
+	}
+	w, h, levels := int(hdr[1]), int(hdr[2]), int(hdr[3])
+	if w <= 0 || h <= 0 || levels <= 0 || levels > 32 {
+		return CookedTexture{}, fmt.Errorf("%w: %dx%d with %d levels", ErrBadCookedTexture, w, h, levels)
+	}
+
+	var pr io.Reader = br
+	if hdr[4]&cookedTexZlib != 0 {
+		zr, err := zlib.NewReader(br)
+		if err != nil {
+			return CookedTexture{}, fmt.Errorf("%w: %v", ErrBadCookedTexture, err)
+		}
+		defer zr.Close()
+		pr = zr
+	}
+
+	t := CookedTexture{Width: w, Height: h}
+	for i := 0; i < levels; i++ {
+		lvl := make([]byte, w*h*4)
+		if _, err := io.ReadFull(pr, lvl); err != nil {
+			return CookedTexture{}, fmt.Errorf("%w: level %d: %v", ErrBadCookedTexture, i, err)
+		}
+		t.Levels = append(t.Levels, lvl)
+		w, h = max(w/2, 1), max(h/2, 1)
+	}
+	return t, nil
+}
+
+// LoadCookedTexture uploads a cooked mip chain and returns the GL texture id.
+// Sampler state matches LoadTexture.
+func LoadCookedTexture(path string) (uint32, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	t, err := ReadCookedTexture(f)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return UploadCookedTexture(0, t), nil
+}
+
+// UploadCookedTexture uploads a pre-built mip chain into tex, or into a new
+// texture if tex is 0, and returns the texture.
+func UploadCookedTexture(tex uint32, t CookedTexture) uint32 {
+	desc := TextureDesc{Width: t.Width, Height: t.Height, Format: RGBA8, Filter: FilterLinear, Wrap: WrapRepeat}
+	if tex == 0 {
+		tex = Device.CreateTexture2D(desc, nil)
+	}
+	levels := make([]any, len(t.Levels))
+	var n int64
+	for i, lvl := range t.Levels {
+		levels[i] = lvl
+		n += int64(len(lvl))
+	}
+	Device.UploadTexture2D(tex, desc, levels...)
+
+	recordTextureBytes(tex, n)
+	return tex
+}
@@ -0,0 +1,156 @@
+package engine
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LUT3D is a 3D colour lookup table as stored in Adobe/Resolve .cube
+// files: Size^3 RGB entries with red varying fastest.
+type LUT3D struct {
+	Title     string
+	Size      int
+	Data      []float32 // RGB triples
+	DomainMin [3]float32
+	DomainMax [3]float32
+}
+
+// LoadCubeLUT reads a .cube colour grading table.
+func LoadCubeLUT(path string) (*LUT3D, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	lut, err := ParseCubeLUT(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return lut, nil
+}
+
+// ParseCubeLUT parses a 3D .cube table. 1D tables are rejected.
+func ParseCubeLUT(r io.Reader) (*LUT3D, error) {
+	lut := &LUT3D{DomainMax: [3]float32{1, 1, 1}}
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		fields := strings.Fields(line)
+		switch fields[0] {
+		case "TITLE":
+			lut.Title = strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "TITLE")), `"`)
+			continue
+		case "LUT_1D_SIZE":
+			return nil, errors.New("cube: 1D LUTs are not supported")
+		case "LUT_3D_SIZE":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("cube: line %d: bad LUT_3D_SIZE", n)
+			}
+			size, err := strconv.Atoi(fields[1])
+			if err != nil || size < 2 || size > 256 {
+				return nil, fmt.Errorf("cube: line %d: bad LUT_3D_SIZE %q", n, fields[1])
+			}
+			lut.Size = size
+			lut.Data = make([]float32, 0, 3*size*size*size)
+			continue
+		case "DOMAIN_MIN", "DOMAIN_MAX":
+			v, err := parseCubeTriple(fields[1:])
+			if err != nil {
+				return nil, fmt.Errorf("cube: line %d: %s: %w", n, fields[0], err)
+			}
+			if fields[0] == "DOMAIN_MIN" {
+				lut.DomainMin = v
+			} else {
+				lut.DomainMax = v
+			}
+			continue
+		}
+		if c := fields[0][0]; c >= 'A' && c <= 'Z' {
+			continue // unknown keyword
+		}
+		if lut.Size == 0 {
+			return nil, fmt.Errorf("cube: line %d: data before LUT_3D_SIZE", n)
+		}
+		v, err := parseCubeTriple(fields)
+		if err != nil {
+			return nil, fmt.Errorf("cube: line %d: %w", n, err)
+		}
+		if len(lut.Data) == cap(lut.Data) {
+			return nil, fmt.Errorf("cube: line %d: more than %d entries", n, lut.Size*lut.Size*lut.Size)
+		}
+		lut.Data = append(lut.Data, v[:]...)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if lut.Size == 0 {
+		return nil, errors.New("cube: missing LUT_3D_SIZE")
+	}
+	if want := 3 * lut.Size * lut.Size * lut.Size; len(lut.Data) != want {
+		return nil, fmt.Errorf("cube: %d entries, want %d", len(lut.Data)/3, want/3)
+	}
+	for i := range lut.DomainMin {
+		if lut.DomainMax[i] <= lut.DomainMin[i] {
+			return nil, errors.New("cube: empty domain")
+		}
+	}
+	return lut, nil
+}
+
+func parseCubeTriple(fields []string) ([3]float32, error) {
+	var v [3]float32
+	if len(fields) != 3 {
+		return v, fmt.Errorf("want 3 values, got %d", len(fields))
+	}
+	for i, f := range fields {
+		x, err := strconv.ParseFloat(f, 32)
+		if err != nil {
+			return v, err
+		}
+		v[i] = float32(x)
+	}
+	return v, nil
+}
+
+// at returns entry (r, g, b).
+func (l *LUT3D) at(r, g, b int) [3]float32 {
+	i := 3 * ((b*l.Size+g)*l.Size + r)
+	return [3]float32{l.Data[i], l.Data[i+1], l.Data[i+2]}
+}
+
+// Apply looks c up with trilinear interpolation, clamping to the domain.
+// It is the CPU reference for the tonemap shader's sampler3D lookup.
+func (l *LUT3D) Apply(c [3]float32) [3]float32 {
+	var i0, i1 [3]int
+	var f [3]float32
+	for k := range c {
+		t := (c[k] - l.DomainMin[k]) / (l.DomainMax[k] - l.DomainMin[k])
+		t = clamp01(t) * float32(l.Size-1)
+		i0[k] = int(t)
+		i1[k] = min(i0[k]+1, l.Size-1)
+		f[k] = t - float32(i0[k])
+	}
+	lerp := func(a, b [3]float32, t float32) [3]float32 {
+		return [3]float32{a[0] + (b[0]-a[0])*t, a[1] + (b[1]-a[1])*t, a[2] + (b[2]-a[2])*t}
+	}
+	c00 := lerp(l.at(i0[0], i0[1], i0[2]), l.at(i1[0], i0[1], i0[2]), f[0])
+	c10 := lerp(l.at(i0[0], i1[1], i0[2]), l.at(i1[0], i1[1], i0[2]), f[0])
+	c01 := lerp(l.at(i0[0], i0[1], i1[2]), l.at(i1[0], i0[1], i1[2]), f[0])
+	c11 := lerp(l.at(i0[0], i1[1], i1[2]), l.at(i1[0], i1[1], i1[2]), f[0])
+	return lerp(lerp(c00, c10, f[1]), lerp(c01, c11, f[1]), f[2])
+}
+
+// Upload creates the table's 3D texture.
+func (l *LUT3D) Upload() uint32 {
+	tex := Device.CreateTexture3D(l.Size, RGB16F, l.Data)
+	recordTextureBytes(tex, int64(len(l.Data))*2)
+	return tex
+}
@@ -0,0 +1,266 @@
+package engine
+
+import "fmt"
+
+// RenderDevice is everything the renderers need from the graphics API:
+// buffers, vertex arrays, textures, programs and uniforms, framebuffers,
+// fixed-function state and draw submission. GLDevice implements it on
+// OpenGL 4.1; RecordingDevice captures the command stream instead so the
+// render path can be tested without a context.
+//
+// Object handles are plain uint32s with 0 meaning "none", so existing
+// fields such as Renderer.ShadowFBO keep their types. Uniform setters act on
+// the program bound with UseProgram; a location of -1 is ignored.
+type RenderDevice interface {
+	// Init loads the API for the context current on this thread.
+	Init() error
+
+	// Buffer and pixel data is a slice, a pointer to its first element or
+	// an unsafe.Pointer; nil allocates without uploading.
+	CreateBuffer(target BufferTarget, size int, data any, usage BufferUsage) uint32
+	UpdateBuffer(target BufferTarget, buf uint32, offset, size int, data any)
+	// ResizeBuffer replaces the storage of buf with size bytes of data.
+	ResizeBuffer(target BufferTarget, buf uint32, size int, data any, usage BufferUsage)
+	BindBuffer(target BufferTarget, buf uint32)
+	BindBufferBase(target BufferTarget, index, buf uint32)
+	BufferSize(target BufferTarget, buf uint32) int
+	DeleteBuffer(buf uint32)
+
+	CreateVertexArray() uint32
+	BindVertexArray(vao uint32)
+	// VertexAttrib describes attribute index of the bound vertex array as
+	// size components of typ read from the bound array buffer. Integer
+	// attributes reach the shader unconverted (ivec4 joints).
+	VertexAttrib(index uint32, size int32, typ DataType, integer bool, stride, offset int)
+	// VertexAttribDivisor makes attribute index of the bound vertex array
+	// advance once per divisor instances instead of once per vertex.
+	VertexAttribDivisor(index, divisor uint32)
+	IsVertexArray(vao uint32) bool
+	DeleteVertexArray(vao uint32)
+
+	CreateTexture2D(desc TextureDesc, pixels any) uint32
+	ResizeTexture2D(tex uint32, desc TextureDesc)
+	// UploadTexture2D replaces the pixels and sampler state of tex. levels
+	// holds a mip chain from desc's size down, each level half the last
+	// (at least 1); more than one level filters trilinearly.
+	UploadTexture2D(tex uint32, desc TextureDesc, levels ...any)
+	// CreateBufferTexture returns a texture that reads buf as texels of
+	// format (a samplerBuffer in GLSL). It follows ResizeBuffer.
+	CreateBufferTexture(buf uint32, format TextureFormat) uint32
+	// CreateTextureCube creates a cube map from float pixel levels, each
+	// holding the six faces in +X, -X, +Y, -Y, +Z, -Z order. Level i is
+	// size>>i texels square; more than one level filters trilinearly.
+	CreateTextureCube(size int, format TextureFormat, levels [][6][]float32) uint32
+	// CreateDepthCubeArray creates layers nearest-filtered depth cube maps
+	// of size texels square for rendering into face by face (see
+	// FramebufferDesc.DepthLayer); GLSL reads it as a samplerCubeArray.
+	CreateDepthCubeArray(size, layers int) uint32
+	// CreateTexture3D creates a linearly filtered size^3 volume (colour
+	// lookup tables), x varying fastest in pixels.
+	CreateTexture3D(size int, format TextureFormat, pixels any) uint32
+	// GenerateMipmaps builds the mip chain of a 2D texture from level 0
+	// and switches it to trilinear minification.
+	GenerateMipmaps(tex uint32)
+	BindTexture(unit uint32, target TextureTarget, tex uint32)
+	DeleteTexture(tex uint32)
+
+	CreateRenderbuffer(format TextureFormat, width, height int) uint32
+	ResizeRenderbuffer(rb uint32, format TextureFormat, width, height int)
+	CreateFramebuffer(desc FramebufferDesc) (uint32, error)
+	BindFramebuffer(fbo uint32)
+	DeleteFramebuffer(fbo uint32)
+
+	CreateProgram(vertSrc, fragSrc string) (uint32, error)
+	UseProgram(prog uint32)
+	ProgramLinked(prog uint32) bool
+	UniformLocation(prog uint32, name string) int32
+	// BindUniformBlock binds the named block of prog to binding and reports
+	// whether the block exists.
+	BindUniformBlock(prog uint32, name string, binding uint32) bool
+	HasUniformBlock(prog uint32, name string) bool
+	DeleteProgram(prog uint32)
+
+	UniformInt(loc int32, v int32)
+	UniformFloat(loc int32, v float32)
+	UniformVec2(loc int32, x, y float32)
+	// UniformVec3, UniformVec4 and UniformMat4 upload len(v)/3, /4 or /16
+	// consecutive array elements starting at loc.
+	UniformVec3(loc int32, v []float32)
+	UniformVec4(loc int32, v []float32)
+	UniformMat4(loc int32, m []float32)
+
+	Viewport(x, y, width, height int32)
+	CurrentViewport() [4]int32
+	ClearColor(r, g, b, a float32)
+	Clear(flags ClearFlags)
+	SetDepthTest(on bool)
+	SetDepthFunc(f DepthFunc)
+	// SetBlend toggles blending with straight (non-premultiplied) alpha:
+	// src*a + dst*(1-a).
+	SetBlend(on bool)
+	// SetCullFace toggles culling of back-facing (clockwise) triangles.
+	SetCullFace(on bool)
+	SetDepthWrite(on bool)
+	SetPolygonMode(m PolygonMode)
+	SetLineWidth(w float32)
+
+	DrawIndexed(prim Primitive, count int32, typ IndexType)
+	DrawArrays(prim Primitive, first, count int32)
+	// DrawIndexedInstanced and DrawArraysInstanced draw instances copies,
+	// stepping the attributes that have a divisor.
+	DrawIndexedInstanced(prim Primitive, count int32, typ IndexType, instances int32)
+	DrawArraysInstanced(prim Primitive, first, count, instances int32)
+
+	// ReadPixels copies RGBA8 pixels of the bound framebuffer into dst.
+	ReadPixels(x, y, width, height int32, dst []byte)
+	Finish()
+	// Error pops the oldest pending API error, or returns nil.
+	Error() error
+}
+
+// Device is the device every renderer submits to. Tests swap in a
+// RecordingDevice before building renderers.
+var Device RenderDevice = GLDevice{}
+
+type BufferTarget uint8
+
+const (
+	ArrayBuffer BufferTarget = iota
+	ElementBuffer
+	UniformBuffer
+	TexelBuffer // backing store of a buffer texture
+)
+
+type BufferUsage uint8
+
+const (
+	StaticDraw BufferUsage = iota
+	DynamicDraw
+)
+
+type DataType uint8
+
+const (
+	Float DataType = iota
+	UnsignedShort
+	UnsignedByte
+)
+
+type TextureTarget uint8
+
+const (
+	Texture2D TextureTarget = iota
+	TextureCube
+	TextureBuffer
+	Texture3D
+	TextureCubeArray
+)
+
+type TextureFormat uint8
+
+const (
+	RGBA8   TextureFormat = iota
+	Depth                 // depth component, float; sampleable
+	Depth24               // 24-bit depth, for renderbuffers
+	RGBA32F               // buffer textures only
+	R32UI
+	RG32UI
+	RGB16F // float colour, uploaded from float32 pixels
+	RG16F
+	RGBA16F // HDR render targets
+	R16F
+)
+
+type TextureFilter uint8
+
+const (
+	FilterNearest TextureFilter = iota
+	FilterLinear
+)
+
+type TextureWrap uint8
+
+const (
+	WrapClampToEdge TextureWrap = iota
+	WrapClampToBorder
+	WrapRepeat
+)
+
+// TextureDesc describes a 2D texture without mipmaps.
+type TextureDesc struct {
+	Width, Height int
+	Format        TextureFormat
+	Filter        TextureFilter
+	Wrap          TextureWrap
+	Border        [4]float32 // used with WrapClampToBorder
+}
+
+// FramebufferDesc lists the attachments of a framebuffer. A framebuffer
+// with no colour attachment draws and reads no colour (depth-only passes).
+type FramebufferDesc struct {
+	Color             uint32 // texture
+	DepthTexture      uint32
+	DepthRenderbuffer uint32
+	// DepthCubeArray attaches face DepthLayer%6 of cube DepthLayer/6 of
+	// a CreateDepthCubeArray texture as the depth buffer.
+	DepthCubeArray uint32
+	DepthLayer     int
+}
+
+type ClearFlags uint8
+
+const (
+	ClearColorBit ClearFlags = 1 << iota
+	ClearDepthBit
+)
+
+type DepthFunc uint8
+
+const (
+	DepthLess DepthFunc = iota
+	DepthLessEqual
+)
+
+type PolygonMode uint8
+
+const (
+	PolygonFill PolygonMode = iota
+	PolygonLine
+)
+
+type Primitive uint8
+
+const (
+	Triangles Primitive = iota
+	Lines
+)
+
+func (p Primitive) String() string {
+	if p == Lines {
+		return "lines"
+	}
+	return "triangles"
+}
+
+type IndexType uint8
+
+const (
+	IndexUint32 IndexType = iota
+	IndexUint16
+)
+
+// Size is the size of one index in bytes.
+func (t IndexType) Size() int {
+	if t == IndexUint16 {
+		return 2
+	}
+	return 4
+}
+
+// DeviceError is an error reported by the graphics API.
+type DeviceError struct {
+	Code uint32
+	Name string
+}
+
+func (e DeviceError) Error() string { return fmt.Sprintf("%s (0x%X)", e.Name, e.Code) }
@@ -0,0 +1,509 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"unsafe"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// GLDevice is the OpenGL 4.1 core RenderDevice. It keeps no state of its
+// own: every call goes to the context current on the calling thread.
+type GLDevice struct{}
+
+var (
+	glBufferTargets  = [...]uint32{ArrayBuffer: gl.ARRAY_BUFFER, ElementBuffer: gl.ELEMENT_ARRAY_BUFFER, UniformBuffer: gl.UNIFORM_BUFFER, TexelBuffer: gl.TEXTURE_BUFFER}
+	glBufferUsages   = [...]uint32{StaticDraw: gl.STATIC_DRAW, DynamicDraw: gl.DYNAMIC_DRAW}
+	glDataTypes      = [...]uint32{Float: gl.FLOAT, UnsignedShort: gl.UNSIGNED_SHORT, UnsignedByte: gl.UNSIGNED_BYTE}
+	glTextureTargets = [...]uint32{Texture2D: gl.TEXTURE_2D, TextureCube: gl.TEXTURE_CUBE_MAP, TextureBuffer: gl.TEXTURE_BUFFER, Texture3D: gl.TEXTURE_3D, TextureCubeArray: gl.TEXTURE_CUBE_MAP_ARRAY}
+	glFilters        = [...]int32{FilterNearest: gl.NEAREST, FilterLinear: gl.LINEAR}
+	glWraps          = [...]int32{WrapClampToEdge: gl.CLAMP_TO_EDGE, WrapClampToBorder: gl.CLAMP_TO_BORDER, WrapRepeat: gl.REPEAT}
+	glDepthFuncs     = [...]uint32{DepthLess: gl.LESS, DepthLessEqual: gl.LEQUAL}
+	glPolygonModes   = [...]uint32{PolygonFill: gl.FILL, PolygonLine: gl.LINE}
+	glPrimitives     = [...]uint32{Triangles: gl.TRIANGLES, Lines: gl.LINES}
+	glIndexTypes     = [...]uint32{IndexUint32: gl.UNSIGNED_INT, IndexUint16: gl.UNSIGNED_SHORT}
+)
+
+// glTextureFormat returns the internal format, pixel format and pixel type.
+func glTextureFormat(f TextureFormat) (int32, uint32, uint32) {
+	switch f {
+	case Depth:
+		return gl.DEPTH_COMPONENT, gl.DEPTH_COMPONENT, gl.FLOAT
+	case Depth24:
+		return gl.DEPTH_COMPONENT24, gl.DEPTH_COMPONENT, gl.UNSIGNED_INT
+	case RGBA32F:
+		return gl.RGBA32F, gl.RGBA, gl.FLOAT
+	case R32UI:
+		return gl.R32UI, gl.RED_INTEGER, gl.UNSIGNED_INT
+	case RG32UI:
+		return gl.RG32UI, gl.RG_INTEGER, gl.UNSIGNED_INT
+	case RGB16F:
+		return gl.RGB16F, gl.RGB, gl.FLOAT
+	case RG16F:
+		return gl.RG16F, gl.RG, gl.FLOAT
+	case RGBA16F:
+		return gl.RGBA16F, gl.RGBA, gl.FLOAT
+	case R16F:
+		return gl.R16F, gl.RED, gl.FLOAT
+	}
+	return gl.RGBA, gl.RGBA, gl.UNSIGNED_BYTE
+}
+
+func glPtr(data any) unsafe.Pointer {
+	switch d := data.(type) {
+	case nil:
+		return nil
+	case unsafe.Pointer:
+		return d
+	}
+	return gl.Ptr(data)
+}
+
+func (GLDevice) Init() error {
+	if err := gl.Init(); err != nil {
+		return err
+	}
+	// Filter cube maps across face edges; prefiltered environment levels
+	// are only a few texels wide.
+	gl.Enable(gl.TEXTURE_CUBE_MAP_SEAMLESS)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	gl.CullFace(gl.BACK)
+	return nil
+}
+
+func (GLDevice) CreateBuffer(target BufferTarget, size int, data any, usage BufferUsage) uint32 {
+	var buf uint32
+	gl.GenBuffers(1, &buf)
+	t := glBufferTargets[target]
+	gl.BindBuffer(t, buf)
+	gl.BufferData(t, size, glPtr(data), glBufferUsages[usage])
+	return buf
+}
+
+func (GLDevice) UpdateBuffer(target BufferTarget, buf uint32, offset, size int, data any) {
+	t := glBufferTargets[target]
+	gl.BindBuffer(t, buf)
+	gl.BufferSubData(t, offset, size, glPtr(data))
+	if target == UniformBuffer {
+		gl.BindBuffer(t, 0)
+	}
+}
+
+func (GLDevice) ResizeBuffer(target BufferTarget, buf uint32, size int, data any, usage BufferUsage) {
+	t := glBufferTargets[target]
+	gl.BindBuffer(t, buf)
+	gl.BufferData(t, size, glPtr(data), glBufferUsages[usage])
+}
+
+func (GLDevice) BindBuffer(target BufferTarget, buf uint32) {
+	gl.BindBuffer(glBufferTargets[target], buf)
+}
+
+func (GLDevice) BindBufferBase(target BufferTarget, index, buf uint32) {
+	gl.BindBufferBase(glBufferTargets[target], index, buf)
+}
+
+func (GLDevice) BufferSize(target BufferTarget, buf uint32) int {
+	var size int32
+	t := glBufferTargets[target]
+	gl.BindBuffer(t, buf)
+	gl.GetBufferParameteriv(t, gl.BUFFER_SIZE, &size)
+	return int(size)
+}
+
+func (GLDevice) DeleteBuffer(buf uint32) { gl.DeleteBuffers(1, &buf) }
+
+func (GLDevice) CreateVertexArray() uint32 {
+	var vao uint32
+	gl.GenVertexArrays(1, &vao)
+	return vao
+}
+
+func (GLDevice) BindVertexArray(vao uint32) { gl.BindVertexArray(vao) }
+
+func (GLDevice) VertexAttrib(index uint32, size int32, typ DataType, integer bool, stride, offset int) {
+	if integer {
+		gl.VertexAttribIPointerWithOffset(index, size, glDataTypes[typ], int32(stride), uintptr(offset))
+	} else {
+		gl.VertexAttribPointerWithOffset(index, size, glDataTypes[typ], false, int32(stride), uintptr(offset))
+	}
+	gl.EnableVertexAttribArray(index)
+}
+
+func (GLDevice) VertexAttribDivisor(index, divisor uint32) {
+	gl.VertexAttribDivisor(index, divisor)
+}
+
+func (GLDevice) IsVertexArray(vao uint32) bool { return gl.IsVertexArray(vao) }
+
+func (GLDevice) DeleteVertexArray(vao uint32) { gl.DeleteVertexArrays(1, &vao) }
+
+func (GLDevice) CreateTexture2D(desc TextureDesc, pixels any) uint32 {
+	var tex uint32
+	gl.GenTextures(1, &tex)
+	gl.BindTexture(gl.TEXTURE_2D, tex)
+	internal, format, typ := glTextureFormat(desc.Format)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, internal, int32(desc.Width), int32(desc.Height), 0, format, typ, glPtr(pixels))
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, glFilters[desc.Filter])
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, glFilters[desc.Filter])
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, glWraps[desc.Wrap])
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, glWraps[desc.Wrap])
+	if desc.Wrap == WrapClampToBorder {
+		gl.TexParameterfv(gl.TEXTURE_2D, gl.TEXTURE_BORDER_COLOR, &desc.Border[0])
+	}
+	return tex
+}
+
+func (GLDevice) ResizeTexture2D(tex uint32, desc TextureDesc) {
+	gl.BindTexture(gl.TEXTURE_2D, tex)
+	internal, format, typ := glTextureFormat(desc.Format)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, internal, int32(desc.Width), int32(desc.Height), 0, format, typ, nil)
+}
+
+func (GLDevice) UploadTexture2D(tex uint32, desc TextureDesc, levels ...any) {
+	gl.BindTexture(gl.TEXTURE_2D, tex)
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+	internal, format, typ := glTextureFormat(desc.Format)
+	w, h := int32(desc.Width), int32(desc.Height)
+	for i, pix := range levels {
+		gl.TexImage2D(gl.TEXTURE_2D, int32(i), internal, w, h, 0, format, typ, glPtr(pix))
+		w, h = max(w/2, 1), max(h/2, 1)
+	}
+	minFilter := glFilters[desc.Filter]
+	if len(levels) > 1 {
+		minFilter = gl.LINEAR_MIPMAP_LINEAR
+	}
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_BASE_LEVEL, 0)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAX_LEVEL, int32(max(len(levels)-1, 0)))
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, minFilter)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, glFilters[desc.Filter])
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, glWraps[desc.Wrap])
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, glWraps[desc.Wrap])
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+}
+
+func (GLDevice) CreateBufferTexture(buf uint32, format TextureFormat) uint32 {
+	var tex uint32
+	gl.GenTextures(1, &tex)
+	gl.BindTexture(gl.TEXTURE_BUFFER, tex)
+	internal, _, _ := glTextureFormat(format)
+	gl.TexBuffer(gl.TEXTURE_BUFFER, uint32(internal), buf)
+	return tex
+}
+
+func (GLDevice) CreateTextureCube(size int, format TextureFormat, levels [][6][]float32) uint32 {
+	var tex uint32
+	gl.GenTextures(1, &tex)
+	gl.BindTexture(gl.TEXTURE_CUBE_MAP, tex)
+	internal, pixFormat, typ := glTextureFormat(format)
+	for level, faces := range levels {
+		n := int32(max(size>>level, 1))
+		for f, pix := range faces {
+			gl.TexImage2D(gl.TEXTURE_CUBE_MAP_POSITIVE_X+uint32(f), int32(level), internal, n, n, 0, pixFormat, typ, glPtr(pix))
+		}
+	}
+	minFilter := int32(gl.LINEAR)
+	if len(levels) > 1 {
+		minFilter = gl.LINEAR_MIPMAP_LINEAR
+	}
+	gl.TexParameteri(gl.TEXTURE_CUBE_MAP, gl.TEXTURE_MIN_FILTER, minFilter)
+	gl.TexParameteri(gl.TEXTURE_CUBE_MAP, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_CUBE_MAP, gl.TEXTURE_MAX_LEVEL, int32(len(levels)-1))
+	for _, wrap := range []uint32{gl.TEXTURE_WRAP_S, gl.TEXTURE_WRAP_T, gl.TEXTURE_WRAP_R} {
+		gl.TexParameteri(gl.TEXTURE_CUBE_MAP, wrap, gl.CLAMP_TO_EDGE)
+	}
+	return tex
+}
+
+func (GLDevice) CreateDepthCubeArray(size, layers int) uint32 {
+	var tex uint32
+	gl.GenTextures(1, &tex)
+	gl.BindTexture(gl.TEXTURE_CUBE_MAP_ARRAY, tex)
+	internal, pixFormat, typ := glTextureFormat(Depth)
+	n := int32(size)
+	gl.TexImage3D(gl.TEXTURE_CUBE_MAP_ARRAY, 0, internal, n, n, int32(6*layers), 0, pixFormat, typ, nil)
+	gl.TexParameteri(gl.TEXTURE_CUBE_MAP_ARRAY, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_CUBE_MAP_ARRAY, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	for _, wrap := range []uint32{gl.TEXTURE_WRAP_S, gl.TEXTURE_WRAP_T, gl.TEXTURE_WRAP_R} {
+		gl.TexParameteri(gl.TEXTURE_CUBE_MAP_ARRAY, wrap, gl.CLAMP_TO_EDGE)
+	}
+	return tex
+}
+
+func (GLDevice) CreateTexture3D(size int, format TextureFormat, pixels any) uint32 {
+	var tex uint32
+	gl.GenTextures(1, &tex)
+	gl.BindTexture(gl.TEXTURE_3D, tex)
+	internal, pixFormat, typ := glTextureFormat(format)
+	n := int32(size)
+	gl.TexImage3D(gl.TEXTURE_3D, 0, internal, n, n, n, 0, pixFormat, typ, glPtr(pixels))
+	gl.TexParameteri(gl.TEXTURE_3D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_3D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	for _, wrap := range []uint32{gl.TEXTURE_WRAP_S, gl.TEXTURE_WRAP_T, gl.TEXTURE_WRAP_R} {
+		gl.TexParameteri(gl.TEXTURE_3D, wrap, gl.CLAMP_TO_EDGE)
+	}
+	return tex
+}
+
+func (GLDevice) GenerateMipmaps(tex uint32) {
+	gl.BindTexture(gl.TEXTURE_2D, tex)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAX_LEVEL, 1000)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR_MIPMAP_LINEAR)
+	gl.GenerateMipmap(gl.TEXTURE_2D)
+}
+
+func (GLDevice) BindTexture(unit uint32, target TextureTarget, tex uint32) {
+	gl.ActiveTexture(gl.TEXTURE0 + unit)
+	gl.BindTexture(glTextureTargets[target], tex)
+}
+
+func (GLDevice) DeleteTexture(tex uint32) { gl.DeleteTextures(1, &tex) }
+
+func (d GLDevice) CreateRenderbuffer(format TextureFormat, width, height int) uint32 {
+	var rb uint32
+	gl.GenRenderbuffers(1, &rb)
+	d.ResizeRenderbuffer(rb, format, width, height)
+	return rb
+}
+
+func (GLDevice) ResizeRenderbuffer(rb uint32, format TextureFormat, width, height int) {
+	internal, _, _ := glTextureFormat(format)
+	gl.BindRenderbuffer(gl.RENDERBUFFER, rb)
+	gl.RenderbufferStorage(gl.RENDERBUFFER, uint32(internal), int32(width), int32(height))
+}
+
+func (GLDevice) CreateFramebuffer(desc FramebufferDesc) (uint32, error) {
+	var fbo uint32
+	gl.GenFramebuffers(1, &fbo)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, fbo)
+	defer gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+
+	if desc.Color != 0 {
+		gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, desc.Color, 0)
+		attachments := []uint32{gl.COLOR_ATTACHMENT0}
+		gl.DrawBuffers(1, &attachments[0])
+		gl.ReadBuffer(gl.COLOR_ATTACHMENT0)
+	} else {
+		gl.DrawBuffer(gl.NONE)
+		gl.ReadBuffer(gl.NONE)
+	}
+	if desc.DepthTexture != 0 {
+		gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.TEXTURE_2D, desc.DepthTexture, 0)
+	}
+	if desc.DepthRenderbuffer != 0 {
+		gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.RENDERBUFFER, desc.DepthRenderbuffer)
+	}
+	if desc.DepthCubeArray != 0 {
+		gl.FramebufferTextureLayer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, desc.DepthCubeArray, 0, int32(desc.DepthLayer))
+	}
+
+	if status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER); status != gl.FRAMEBUFFER_COMPLETE {
+		return fbo, fmt.Errorf("framebuffer %d incomplete: 0x%X", fbo, status)
+	}
+	return fbo, nil
+}
+
+func (GLDevice) BindFramebuffer(fbo uint32) { gl.BindFramebuffer(gl.FRAMEBUFFER, fbo) }
+
+func (GLDevice) DeleteFramebuffer(fbo uint32) { gl.DeleteFramebuffers(1, &fbo) }
+
+func (GLDevice) CreateProgram(vertSrc, fragSrc string) (uint32, error) {
+	vs, err := glCompileShader(vertSrc, gl.VERTEX_SHADER)
+	if err != nil {
+		return 0, err
+	}
+	defer gl.DeleteShader(vs)
+	fs, err := glCompileShader(fragSrc, gl.FRAGMENT_SHADER)
+	if err != nil {
+		return 0, err
+	}
+	defer gl.DeleteShader(fs)
+
+	prog := gl.CreateProgram()
+	gl.AttachShader(prog, vs)
+	gl.AttachShader(prog, fs)
+	gl.LinkProgram(prog)
+
+	var status int32
+	gl.GetProgramiv(prog, gl.LINK_STATUS, &status)
+	if status == gl.FALSE {
+		var logLen int32
+		gl.GetProgramiv(prog, gl.INFO_LOG_LENGTH, &logLen)
+		logBuf := make([]byte, logLen+1)
+		gl.GetProgramInfoLog(prog, logLen, nil, &logBuf[0])
+		gl.DeleteProgram(prog)
+		return 0, fmt.Errorf("program link error: %s", strings.TrimRight(string(logBuf), "\x00"))
+	}
+	return prog, nil
+}
+
+func glCompileShader(src string, typ uint32) (uint32, error) {
+	if len(strings.TrimSpace(strings.TrimRight(src, "\x00"))) == 0 {
+		return 0, fmt.Errorf("shader source is empty or whitespace")
+	}
+	shader := gl.CreateShader(typ)
+	csrc, free := gl.Strs(strings.TrimRight(src, "\x00") + "\x00")
+	gl.ShaderSource(shader, 1, csrc, nil)
+	free()
+	gl.CompileShader(shader)
+
+	var status int32
+	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
+	if status == gl.FALSE {
+		var logLen int32
+		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLen)
+		msg := "no info log (driver returned empty log)"
+		if logLen > 1 {
+			logBuf := make([]byte, logLen)
+			gl.GetShaderInfoLog(shader, logLen, nil, &logBuf[0])
+			msg = strings.TrimRight(string(logBuf), "\x00")
+		}
+		gl.DeleteShader(shader)
+		return 0, fmt.Errorf("shader compile error: %s", msg)
+	}
+	return shader, nil
+}
+
+func (GLDevice) UseProgram(prog uint32) { gl.UseProgram(prog) }
+
+func (GLDevice) ProgramLinked(prog uint32) bool {
+	var linked int32
+	gl.GetProgramiv(prog, gl.LINK_STATUS, &linked)
+	return linked != gl.FALSE
+}
+
+func (GLDevice) UniformLocation(prog uint32, name string) int32 {
+	return gl.GetUniformLocation(prog, gl.Str(name+"\x00"))
+}
+
+func (GLDevice) BindUniformBlock(prog uint32, name string, binding uint32) bool {
+	idx := gl.GetUniformBlockIndex(prog, gl.Str(name+"\x00"))
+	if idx == gl.INVALID_INDEX {
+		return false
+	}
+	gl.UniformBlockBinding(prog, idx, binding)
+	return true
+}
+
+func (GLDevice) HasUniformBlock(prog uint32, name string) bool {
+	return gl.GetUniformBlockIndex(prog, gl.Str(name+"\x00")) != gl.INVALID_INDEX
+}
+
+func (GLDevice) DeleteProgram(prog uint32) { gl.DeleteProgram(prog) }
+
+func (GLDevice) UniformInt(loc int32, v int32) {
+	if loc != -1 {
+		gl.Uniform1i(loc, v)
+	}
+}
+
+func (GLDevice) UniformFloat(loc int32, v float32) {
+	if loc != -1 {
+		gl.Uniform1f(loc, v)
+	}
+}
+
+func (GLDevice) UniformVec2(loc int32, x, y float32) {
+	if loc != -1 {
+		gl.Uniform2f(loc, x, y)
+	}
+}
+
+func (GLDevice) UniformVec3(loc int32, v []float32) {
+	if loc != -1 && len(v) >= 3 {
+		gl.Uniform3fv(loc, int32(len(v)/3), &v[0])
+	}
+}
+
+func (GLDevice) UniformVec4(loc int32, v []float32) {
+	if loc != -1 && len(v) >= 4 {
+		gl.Uniform4fv(loc, int32(len(v)/4), &v[0])
+	}
+}
+
+func (GLDevice) UniformMat4(loc int32, m []float32) {
+	if loc != -1 && len(m) >= 16 {
+		gl.UniformMatrix4fv(loc, int32(len(m)/16), false, &m[0])
+	}
+}
+
+func (GLDevice) Viewport(x, y, width, height int32) { gl.Viewport(x, y, width, height) }
+
+func (GLDevice) CurrentViewport() [4]int32 {
+	var vp [4]int32
+	gl.GetIntegerv(gl.VIEWPORT, &vp[0])
+	return vp
+}
+
+func (GLDevice) ClearColor(r, g, b, a float32) { gl.ClearColor(r, g, b, a) }
+
+func (GLDevice) Clear(flags ClearFlags) {
+	var mask uint32
+	if flags&ClearColorBit != 0 {
+		mask |= gl.COLOR_BUFFER_BIT
+	}
+	if flags&ClearDepthBit != 0 {
+		mask |= gl.DEPTH_BUFFER_BIT
+	}
+	gl.Clear(mask)
+}
+
+func glSetCap(cap uint32, on bool) {
+	if on {
+		gl.Enable(cap)
+	} else {
+		gl.Disable(cap)
+	}
+}
+
+func (GLDevice) SetDepthTest(on bool)         { glSetCap(gl.DEPTH_TEST, on) }
+func (GLDevice) SetDepthFunc(f DepthFunc)     { gl.DepthFunc(glDepthFuncs[f]) }
+func (GLDevice) SetBlend(on bool)             { glSetCap(gl.BLEND, on) }
+func (GLDevice) SetCullFace(on bool)          { glSetCap(gl.CULL_FACE, on) }
+func (GLDevice) SetDepthWrite(on bool)        { gl.DepthMask(on) }
+func (GLDevice) SetPolygonMode(m PolygonMode) { gl.PolygonMode(gl.FRONT_AND_BACK, glPolygonModes[m]) }
+func (GLDevice) SetLineWidth(w float32)       { gl.LineWidth(w) }
+
+func (GLDevice) DrawArrays(p Primitive, first, count int32) {
+	gl.DrawArrays(glPrimitives[p], first, count)
+}
+
+func (GLDevice) DrawIndexed(p Primitive, count int32, typ IndexType) {
+	gl.DrawElements(glPrimitives[p], count, glIndexTypes[typ], gl.PtrOffset(0))
+}
+
+func (GLDevice) DrawIndexedInstanced(p Primitive, count int32, typ IndexType, instances int32) {
+	gl.DrawElementsInstanced(glPrimitives[p], count, glIndexTypes[typ], gl.PtrOffset(0), instances)
+}
+
+func (GLDevice) DrawArraysInstanced(p Primitive, first, count, instances int32) {
+	gl.DrawArraysInstanced(glPrimitives[p], first, count, instances)
+}
+
+func (GLDevice) ReadPixels(x, y, width, height int32, dst []byte) {
+	gl.ReadPixels(x, y, width, height, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(dst))
+}
+
+func (GLDevice) Finish() { gl.Finish() }
+
+func (GLDevice) Error() error {
+	code := gl.GetError()
+	if code == gl.NO_ERROR {
+		return nil
+	}
+	name := "GL_UNKNOWN"
+	switch code {
+	case gl.INVALID_ENUM:
+		name = "GL_INVALID_ENUM"
+	case gl.INVALID_VALUE:
+		name = "GL_INVALID_VALUE"
+	case gl.INVALID_OPERATION:
+		name = "GL_INVALID_OPERATION"
+	case gl.INVALID_FRAMEBUFFER_OPERATION:
+		name = "GL_INVALID_FRAMEBUFFER_OPERATION"
+	case gl.OUT_OF_MEMORY:
+		name = "GL_OUT_OF_MEMORY"
+	}
+	return DeviceError{Code: code, Name: name}
+}
@@ -0,0 +1,448 @@
+package engine
+
+import (
+	"fmt"
+	"maps"
+)
+
+// Command is one RenderDevice call captured by a RecordingDevice.
+type Command struct {
+	Op   string // method name, e.g. "UseProgram" or "DrawIndexed"
+	ID   uint32 // object the call targets, if any
+	Name string // uniform name for Uniform* calls
+	Args []any  // remaining arguments; uniform values are copied
+}
+
+func (c Command) String() string {
+	s := c.Op
+	if c.ID != 0 {
+		s += fmt.Sprintf(" #%d", c.ID)
+	}
+	if c.Name != "" {
+		s += " " + c.Name
+	}
+	for _, a := range c.Args {
+		s += fmt.Sprintf(" %v", a)
+	}
+	return s
+}
+
+// DrawCall is a draw together with the state it was issued under.
+type DrawCall struct {
+	Primitive   Primitive
+	Indexed     bool
+	Count       int32
+	Instances   int32 // 1 for plain draws
+	Program     uint32
+	VertexArray uint32
+	Framebuffer uint32
+	Viewport    [4]int32
+	DepthTest   bool
+	Blend       bool
+	CullFace    bool
+	DepthWrite  bool
+	PolygonMode PolygonMode
+	Textures    map[uint32]uint32 // texture unit -> texture
+	Uniforms    map[string]any    // every uniform set on Program so far
+}
+
+// Frame is what a RecordingDevice captured between two EndFrame calls.
+type Frame struct {
+	Commands []Command
+	Draws    []DrawCall
+}
+
+// RecordingDevice is a RenderDevice with no GPU behind it. It hands out
+// handles, tracks the state the GL would hold and records every call, so
+// tests can assert draw order, state and uniform values per frame.
+//
+// Every uniform name resolves to a location, so programs behave as if
+// they declared whatever the caller asks for. Values are kept per program:
+// int32, float32, [2]float32, [3]float32, [4]float32 and [16]float32 for
+// single values, []float32 for arrays.
+type RecordingDevice struct {
+	Frames []Frame
+
+	cur        Frame
+	nextHandle uint32
+	buffers    map[uint32]int
+	vaos       map[uint32]bool
+	programs   map[uint32]*recordedProgram
+	locations  map[int32]recordedLocation
+	nextLoc    int32
+
+	program     uint32
+	vao         uint32
+	fbo         uint32
+	viewport    [4]int32
+	depthTest   bool
+	blend       bool
+	cullFace    bool
+	depthWrite  bool
+	polygonMode PolygonMode
+	textures    map[uint32]uint32
+}
+
+type recordedProgram struct {
+	VertSrc, FragSrc string
+	locs             map[string]int32
+	values           map[string]any
+}
+
+type recordedLocation struct {
+	prog uint32
+	name string
+}
+
+func NewRecordingDevice() *RecordingDevice {
+	return &RecordingDevice{
+		buffers:   map[uint32]int{},
+		vaos:      map[uint32]bool{},
+		programs:  map[uint32]*recordedProgram{},
+		locations: map[int32]recordedLocation{},
+		textures:  map[uint32]uint32{},
+
+		depthWrite: true,
+	}
+}
+
+// EndFrame closes the frame being recorded, appends it to Frames and
+// returns it. Device state carries over to the next frame.
+func (d *RecordingDevice) EndFrame() Frame {
+	f := d.cur
+	d.Frames = append(d.Frames, f)
+	d.cur = Frame{}
+	return f
+}
+
+// Pending returns what has been recorded since the last EndFrame.
+func (d *RecordingDevice) Pending() Frame { return d.cur }
+
+// ProgramSources returns the sources a program was created from.
+func (d *RecordingDevice) ProgramSources(prog uint32) (vert, frag string, ok bool) {
+	p, ok := d.programs[prog]
+	if !ok {
+		return "", "", false
+	}
+	return p.VertSrc, p.FragSrc, true
+}
+
+func (d *RecordingDevice) record(op string, id uint32, args ...any) {
+	d.cur.Commands = append(d.cur.Commands, Command{Op: op, ID: id, Args: args})
+}
+
+func (d *RecordingDevice) handle() uint32 {
+	d.nextHandle++
+	return d.nextHandle
+}
+
+func (d *RecordingDevice) Init() error { return nil }
+
+func (d *RecordingDevice) CreateBuffer(target BufferTarget, size int, data any, usage BufferUsage) uint32 {
+	buf := d.handle()
+	d.buffers[buf] = size
+	d.record("CreateBuffer", buf, target, size, usage)
+	return buf
+}
+
+func (d *RecordingDevice) UpdateBuffer(target BufferTarget, buf uint32, offset, size int, data any) {
+	d.record("UpdateBuffer", buf, target, offset, size)
+}
+
+func (d *RecordingDevice) ResizeBuffer(target BufferTarget, buf uint32, size int, data any, usage BufferUsage) {
+	d.buffers[buf] = size
+	d.record("ResizeBuffer", buf, target, size, usage)
+}
+
+func (d *RecordingDevice) BindBuffer(target BufferTarget, buf uint32) {
+	d.record("BindBuffer", buf, target)
+}
+
+func (d *RecordingDevice) BindBufferBase(target BufferTarget, index, buf uint32) {
+	d.record("BindBufferBase", buf, target, index)
+}
+
+func (d *RecordingDevice) BufferSize(target BufferTarget, buf uint32) int { return d.buffers[buf] }
+
+func (d *RecordingDevice) DeleteBuffer(buf uint32) {
+	delete(d.buffers, buf)
+	d.record("DeleteBuffer", buf)
+}
+
+func (d *RecordingDevice) CreateVertexArray() uint32 {
+	vao := d.handle()
+	d.vaos[vao] = true
+	d.record("CreateVertexArray", vao)
+	return vao
+}
+
+func (d *RecordingDevice) BindVertexArray(vao uint32) {
+	d.vao = vao
+	d.record("BindVertexArray", vao)
+}
+
+func (d *RecordingDevice) VertexAttrib(index uint32, size int32, typ DataType, integer bool, stride, offset int) {
+	d.record("VertexAttrib", d.vao, index, size, typ, integer, stride, offset)
+}
+
+func (d *RecordingDevice) VertexAttribDivisor(index, divisor uint32) {
+	d.record("VertexAttribDivisor", d.vao, index, divisor)
+}
+
+func (d *RecordingDevice) IsVertexArray(vao uint32) bool { return d.vaos[vao] }
+
+func (d *RecordingDevice) DeleteVertexArray(vao uint32) {
+	delete(d.vaos, vao)
+	d.record("DeleteVertexArray", vao)
+}
+
+func (d *RecordingDevice) CreateTexture2D(desc TextureDesc, pixels any) uint32 {
+	tex := d.handle()
+	d.record("CreateTexture2D", tex, desc)
+	return tex
+}
+
+func (d *RecordingDevice) ResizeTexture2D(tex uint32, desc TextureDesc) {
+	d.record("ResizeTexture2D", tex, desc)
+}
+
+func (d *RecordingDevice) UploadTexture2D(tex uint32, desc TextureDesc, levels ...any) {
+	d.record("UploadTexture2D", tex, desc, len(levels))
+}
+
+func (d *RecordingDevice) CreateBufferTexture(buf uint32, format TextureFormat) uint32 {
+	tex := d.handle()
+	d.record("CreateBufferTexture", tex, buf, format)
+	return tex
+}
+
+func (d *RecordingDevice) CreateTextureCube(size int, format TextureFormat, levels [][6][]float32) uint32 {
+	tex := d.handle()
+	d.record("CreateTextureCube", tex, size, format, len(levels))
+	return tex
+}
+
+func (d *RecordingDevice) CreateDepthCubeArray(size, layers int) uint32 {
+	tex := d.handle()
+	d.record("CreateDepthCubeArray", tex, size, layers)
+	return tex
+}
+
+func (d *RecordingDevice) CreateTexture3D(size int, format TextureFormat, pixels any) uint32 {
+	tex := d.handle()
+	d.record("CreateTexture3D", tex, size, format)
+	return tex
+}
+
+func (d *RecordingDevice) GenerateMipmaps(tex uint32) { d.record("GenerateMipmaps", tex) }
+
+func (d *RecordingDevice) BindTexture(unit uint32, target TextureTarget, tex uint32) {
+	d.textures[unit] = tex
+	d.record("BindTexture", tex, unit, target)
+}
+
+func (d *RecordingDevice) DeleteTexture(tex uint32) { d.record("DeleteTexture", tex) }
+
+func (d *RecordingDevice) CreateRenderbuffer(format TextureFormat, width, height int) uint32 {
+	rb := d.handle()
+	d.record("CreateRenderbuffer", rb, format, width, height)
+	return rb
+}
+
+func (d *RecordingDevice) ResizeRenderbuffer(rb uint32, format TextureFormat, width, height int) {
+	d.record("ResizeRenderbuffer", rb, format, width, height)
+}
+
+func (d *RecordingDevice) CreateFramebuffer(desc FramebufferDesc) (uint32, error) {
+	fbo := d.handle()
+	d.record("CreateFramebuffer", fbo, desc)
+	return fbo, nil
+}
+
+func (d *RecordingDevice) BindFramebuffer(fbo uint32) {
+	d.fbo = fbo
+	d.record("BindFramebuffer", fbo)
+}
+
+func (d *RecordingDevice) DeleteFramebuffer(fbo uint32) { d.record("DeleteFramebuffer", fbo) }
+
+func (d *RecordingDevice) CreateProgram(vertSrc, fragSrc string) (uint32, error) {
+	prog := d.handle()
+	d.programs[prog] = &recordedProgram{
+		VertSrc: vertSrc,
+		FragSrc: fragSrc,
+		locs:    map[string]int32{},
+		values:  map[string]any{},
+	}
+	d.record("CreateProgram", prog)
+	return prog, nil
+}
+
+func (d *RecordingDevice) UseProgram(prog uint32) {
+	d.program = prog
+	d.record("UseProgram", prog)
+}
+
+func (d *RecordingDevice) ProgramLinked(prog uint32) bool { return d.programs[prog] != nil }
+
+func (d *RecordingDevice) UniformLocation(prog uint32, name string) int32 {
+	p, ok := d.programs[prog]
+	if !ok {
+		return -1
+	}
+	if loc, ok := p.locs[name]; ok {
+		return loc
+	}
+	loc := d.nextLoc
+	d.nextLoc++
+	p.locs[name] = loc
+	d.locations[loc] = recordedLocation{prog: prog, name: name}
+	return loc
+}
+
+func (d *RecordingDevice) BindUniformBlock(prog uint32, name string, binding uint32) bool {
+	d.record("BindUniformBlock", prog, name, binding)
+	return d.programs[prog] != nil
+}
+
+func (d *RecordingDevice) HasUniformBlock(prog uint32, name string) bool {
+	return d.programs[prog] != nil
+}
+
+func (d *RecordingDevice) DeleteProgram(prog uint32) {
+	delete(d.programs, prog)
+	d.record("DeleteProgram", prog)
+}
+
+// setUniform stores v under the name behind loc for the bound program.
+// Writes to a location of another program are recorded but, as in GL,
+// change nothing.
+func (d *RecordingDevice) setUniform(op string, loc int32, v any) {
+	if loc == -1 {
+		return
+	}
+	l, ok := d.locations[loc]
+	d.cur.Commands = append(d.cur.Commands, Command{Op: op, ID: d.program, Name: l.name, Args: []any{v}})
+	if !ok || l.prog != d.program {
+		return
+	}
+	d.programs[l.prog].values[l.name] = v
+}
+
+func floats(v []float32, n int) any {
+	switch {
+	case len(v) == n && n == 3:
+		return [3]float32(v)
+	case len(v) == n && n == 4:
+		return [4]float32(v)
+	case len(v) == n && n == 16:
+		return [16]float32(v)
+	}
+	return append([]float32(nil), v...)
+}
+
+func (d *RecordingDevice) UniformInt(loc int32, v int32)     { d.setUniform("UniformInt", loc, v) }
+func (d *RecordingDevice) UniformFloat(loc int32, v float32) { d.setUniform("UniformFloat", loc, v) }
+func (d *RecordingDevice) UniformVec2(loc int32, x, y float32) {
+	d.setUniform("UniformVec2", loc, [2]float32{x, y})
+}
+func (d *RecordingDevice) UniformVec3(loc int32, v []float32) {
+	d.setUniform("UniformVec3", loc, floats(v, 3))
+}
+func (d *RecordingDevice) UniformVec4(loc int32, v []float32) {
+	d.setUniform("UniformVec4", loc, floats(v, 4))
+}
+func (d *RecordingDevice) UniformMat4(loc int32, m []float32) {
+	d.setUniform("UniformMat4", loc, floats(m, 16))
+}
+
+func (d *RecordingDevice) Viewport(x, y, width, height int32) {
+	d.viewport = [4]int32{x, y, width, height}
+	d.record("Viewport", 0, x, y, width, height)
+}
+
+func (d *RecordingDevice) CurrentViewport() [4]int32 { return d.viewport }
+
+func (d *RecordingDevice) ClearColor(r, g, b, a float32) { d.record("ClearColor", 0, r, g, b, a) }
+func (d *RecordingDevice) Clear(flags ClearFlags)        { d.record("Clear", d.fbo, flags) }
+
+func (d *RecordingDevice) SetDepthTest(on bool) {
+	d.depthTest = on
+	d.record("SetDepthTest", 0, on)
+}
+
+func (d *RecordingDevice) SetDepthFunc(f DepthFunc) { d.record("SetDepthFunc", 0, f) }
+
+func (d *RecordingDevice) SetBlend(on bool) {
+	d.blend = on
+	d.record("SetBlend", 0, on)
+}
+
+func (d *RecordingDevice) SetCullFace(on bool) {
+	d.cullFace = on
+	d.record("SetCullFace", 0, on)
+}
+
+func (d *RecordingDevice) SetDepthWrite(on bool) {
+	d.depthWrite = on
+	d.record("SetDepthWrite", 0, on)
+}
+
+func (d *RecordingDevice) SetPolygonMode(m PolygonMode) {
+	d.polygonMode = m
+	d.record("SetPolygonMode", 0, m)
+}
+
+func (d *RecordingDevice) SetLineWidth(w float32) { d.record("SetLineWidth", 0, w) }
+
+func (d *RecordingDevice) draw(p Primitive, indexed bool, count, instances int32) {
+	dc := DrawCall{
+		Primitive:   p,
+		Indexed:     indexed,
+		Count:       count,
+		Instances:   instances,
+		Program:     d.program,
+		VertexArray: d.vao,
+		Framebuffer: d.fbo,
+		Viewport:    d.viewport,
+		DepthTest:   d.depthTest,
+		Blend:       d.blend,
+		CullFace:    d.cullFace,
+		DepthWrite:  d.depthWrite,
+		PolygonMode: d.polygonMode,
+		Textures:    maps.Clone(d.textures),
+		Uniforms:    map[string]any{},
+	}
+	if prog, ok := d.programs[d.program]; ok {
+		dc.Uniforms = maps.Clone(prog.values)
+	}
+	d.cur.Draws = append(d.cur.Draws, dc)
+}
+
+func (d *RecordingDevice) DrawIndexed(p Primitive, count int32, typ IndexType) {
+	d.record("DrawIndexed", d.vao, p, count, typ)
+	d.draw(p, true, count, 1)
+}
+
+func (d *RecordingDevice) DrawArrays(p Primitive, first, count int32) {
+	d.record("DrawArrays", d.vao, p, first, count)
+	d.draw(p, false, count, 1)
+}
+
+func (d *RecordingDevice) DrawIndexedInstanced(p Primitive, count int32, typ IndexType, instances int32) {
+	d.record("DrawIndexedInstanced", d.vao, p, count, typ, instances)
+	d.draw(p, true, count, instances)
+}
+
+func (d *RecordingDevice) DrawArraysInstanced(p Primitive, first, count, instances int32) {
+	d.record("DrawArraysInstanced", d.vao, p, first, count, instances)
+	d.draw(p, false, count, instances)
+}
+
+func (d *RecordingDevice) ReadPixels(x, y, width, height int32, dst []byte) {
+	clear(dst)
+	d.record("ReadPixels", d.fbo, x, y, width, height)
+}
+
+func (d *RecordingDevice) Finish()      { d.record("Finish", 0) }
+func (d *RecordingDevice) Error() error { return nil }
@@ -0,0 +1,105 @@
+package engine
+
+// PrefilterLevels is the number of roughness levels in the prefiltered
+// specular cube. pbr_fragment.glsl reads them with
+// textureLod(prefilteredEnvMap, R, roughness * 5.0).
+const PrefilterLevels = 6
+
+// EnvironmentSettings size the maps baked from an environment image.
+type EnvironmentSettings struct {
+	IrradianceSize int // texels per side of the SH irradiance cube
+	SpecularSize   int // texels per side of the sharpest prefiltered level
+	Samples        int // GGX samples per prefiltered texel
+	LUTSize        int
+	LUTSamples     int
+}
+
+var DefaultEnvironmentSettings = EnvironmentSettings{
+	IrradianceSize: 32,
+	SpecularSize:   128,
+	Samples:        64,
+	LUTSize:        64,
+	LUTSamples:     256,
+}
+
+// EnvironmentMaps are the CPU results of baking an environment, ready to
+// upload.
+type EnvironmentMaps struct {
+	SH         SH9
+	Irradiance *CubeMap
+	Specular   []*CubeMap // PrefilterLevels levels, halving in size
+	BRDF       []float32  // LUTSize x LUTSize RG pairs
+	LUTSize    int
+}
+
+// BakeEnvironment precomputes image-based lighting for an
+// equirectangular environment: SH9 irradiance, the GGX-prefiltered
+// specular levels and the split-sum BRDF table.
+func BakeEnvironment(img *HDRImage, s EnvironmentSettings) *EnvironmentMaps {
+	m := &EnvironmentMaps{SH: ProjectSH9(img), LUTSize: s.LUTSize}
+	m.Irradiance = IrradianceCube(&m.SH, s.IrradianceSize)
+
+	// Resample at twice the output size so level 0 stays sharp and the
+	// mips the prefilter reads from are box-filtered, not aliased.
+	src := EquirectToCube(img, 2*s.SpecularSize)
+	m.Specular = PrefilterGGX(src, s.SpecularSize, PrefilterLevels, s.Samples)
+	m.BRDF = BRDFLUT(s.LUTSize, s.LUTSamples)
+	return m
+}
+
+// Environment holds the GPU textures the PBR shader samples for
+// image-based lighting.
+type Environment struct {
+	Irradiance  uint32 // cube map, unit 10
+	Prefiltered uint32 // mipmapped cube map, unit 11
+	BRDFLUT     uint32 // 2D RG table, unit 12
+	SH          SH9
+}
+
+// Upload creates the environment's textures.
+func (m *EnvironmentMaps) Upload() *Environment {
+	env := &Environment{SH: m.SH}
+	env.Irradiance = Device.CreateTextureCube(m.Irradiance.Size, RGB16F, [][6][]float32{m.Irradiance.Faces})
+	recordTextureBytes(env.Irradiance, int64(len(m.Irradiance.Faces[0]))*6*2)
+
+	levels := make([][6][]float32, len(m.Specular))
+	var texels int64
+	for i, c := range m.Specular {
+		levels[i] = c.Faces
+		texels += int64(len(c.Faces[0])) / 3 * 6
+	}
+	env.Prefiltered = Device.CreateTextureCube(m.Specular[0].Size, RGB16F, levels)
+	recordTextureBytes(env.Prefiltered, texels*6)
+
+	env.BRDFLUT = Device.CreateTexture2D(TextureDesc{
+		Width: m.LUTSize, Height: m.LUTSize,
+		Format: RG16F, Filter: FilterLinear, Wrap: WrapClampToEdge,
+	}, m.BRDF)
+	recordTextureBytes(env.BRDFLUT, int64(len(m.BRDF))*2)
+	return env
+}
+
+// LoadEnvironment bakes and uploads the .hdr environment at path with the
+// default settings.
+func LoadEnvironment(path string) (*Environment, error) {
+	img, err := LoadHDR(path)
+	if err != nil {
+		return nil, err
+	}
+	return BakeEnvironment(img, DefaultEnvironmentSettings).Upload(), nil
+}
+
+// Textures lists the environment's textures, for deleting them.
+func (e *Environment) Textures() []uint32 {
+	return []uint32{e.Irradiance, e.Prefiltered, e.BRDFLUT}
+}
+
+func (e *Environment) Delete() {
+	for _, t := range e.Textures() {
+		if t != 0 {
+			Device.DeleteTexture(t)
+			forgetTextureBytes(t)
+		}
+	}
+	*e = Environment{}
+}
@@ -10,8 +10,6 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-
-	"github.com/go-gl/gl/v4.1-core/gl"
 )
 
 type GltfRoot struct {
@@ -27,6 +25,11 @@ type GltfRoot struct {
 	Scenes      []gltfScene      `json:"scenes"`
 	Scene       int              `json:"scene"`
 	Skins       []gltfSkin       `json:"skins"` // default scene index
+	Cameras     []GltfCamera     `json:"cameras"`
+
+	Extensions         GltfRootExtensions `json:"extensions"`
+	ExtensionsUsed     []string           `json:"extensionsUsed"`
+	ExtensionsRequired []string           `json:"extensionsRequired"`
 }
 
 // ---------------------------
@@ -47,17 +50,53 @@ type gltfBufferView struct {
 }
 
 type gltfAccessor struct {
-	BufferView    int    `json:"bufferView"`
-	ByteOffset    int    `json:"byteOffset"`
-	ComponentType int    `json:"componentType"`
-	Count         int    `json:"count"`
-	Type          string `json:"type"`
+	BufferView    int                 `json:"bufferView"` // -1: zero-filled (or sparse-only)
+	ByteOffset    int                 `json:"byteOffset"`
+	ComponentType int                 `json:"componentType"`
+	Normalized    bool                `json:"normalized"`
+	Count         int                 `json:"count"`
+	Type          string              `json:"type"`
+	Sparse        *gltfAccessorSparse `json:"sparse"`
+}
+
+func (a *gltfAccessor) UnmarshalJSON(b []byte) error {
+	type raw gltfAccessor
+	r := raw{BufferView: -1}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+	*a = gltfAccessor(r)
+	return nil
+}
+
+type gltfAccessorSparse struct {
+	Count   int `json:"count"`
+	Indices struct {
+		BufferView    int `json:"bufferView"`
+		ByteOffset    int `json:"byteOffset"`
+		ComponentType int `json:"componentType"`
+	} `json:"indices"`
+	Values struct {
+		BufferView int `json:"bufferView"`
+		ByteOffset int `json:"byteOffset"`
+	} `json:"values"`
 }
 
 type gltfPrimitive struct {
 	Attributes map[string]int `json:"attributes"`
-	Indices    int            `json:"indices"`
-	Material   int            `json:"material"`
+	Indices    int            `json:"indices"`  // -1: non-indexed
+	Material   int            `json:"material"` // -1: default material
+	Mode       int            `json:"mode"`
+}
+
+func (p *gltfPrimitive) UnmarshalJSON(b []byte) error {
+	type raw gltfPrimitive
+	r := raw{Indices: -1, Material: -1, Mode: 4}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+	*p = gltfPrimitive(r)
+	return nil
 }
 
 type gltfMesh struct {
@@ -86,17 +125,42 @@ type gltfMaterial struct {
 	OcclusionTexture *gltfTextureInfo           `json:"occlusionTexture"`
 	Extensions       map[string]json.RawMessage `json:"extensions,omitempty"`
 	AlphaMode        string                     `json:"alphaMode,omitempty"`
+	AlphaCutoff      *float32                   `json:"alphaCutoff,omitempty"`
+	DoubleSided      bool                       `json:"doubleSided,omitempty"`
 }
 
 type gltfImage struct {
-	URI string `json:"uri"`
+	URI        string `json:"uri"`
+	MimeType   string `json:"mimeType"`
+	BufferView int    `json:"bufferView"` // -1 unless embedded (GLB)
+}
+
+func (im *gltfImage) UnmarshalJSON(b []byte) error {
+	type raw gltfImage
+	r := raw{BufferView: -1}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+	*im = gltfImage(r)
+	return nil
 }
 
 // engine.go (gltf structs)
 type gltfTexture struct {
-	Source     int                        `json:"source,omitempty"`
+	Source     int                        `json:"source"` // -1 if absent
 	Extensions map[string]json.RawMessage `json:"extensions,omitempty"`
 }
+
+func (t *gltfTexture) UnmarshalJSON(b []byte) error {
+	type raw gltfTexture
+	r := raw{Source: -1}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+	*t = gltfTexture(r)
+	return nil
+}
+
 type gltfAnimationSampler struct {
 	Input         int    `json:"input"`
 	Output        int    `json:"output"`
@@ -121,14 +185,24 @@ type gltfAnimation struct {
 
 type gltfSkin struct {
 	Joints              []int `json:"joints"`
-	InverseBindMatrices int   `json:"inverseBindMatrices"`
-	Skeleton            int   `json:"skeleton,omitempty"`
+	InverseBindMatrices int   `json:"inverseBindMatrices"` // -1: identity
+	Skeleton            int   `json:"skeleton,omitempty"`  // -1 if absent
+}
+
+func (s *gltfSkin) UnmarshalJSON(b []byte) error {
+	type raw gltfSkin
+	r := raw{InverseBindMatrices: -1, Skeleton: -1}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+	*s = gltfSkin(r)
+	return nil
 }
 
 // helper: return the image source index for a texture, checking EXT_texture_webp
 func textureSourceIndex(t gltfTexture) int {
 	// prefer explicit Source if present
-	if t.Source != 0 {
+	if t.Source >= 0 {
 		return t.Source
 	}
 	// check EXT_texture_webp extension: {"EXT_texture_webp": {"source": <int>}}
@@ -155,6 +229,21 @@ type GltfNode struct {
 	Scale       []float32 `json:"scale"`
 	Matrix      []float32 `json:"matrix"` // 16 floats
 	Skin        int       `json:"skin"`   // NEW: index into GltfRoot.Skins, or -1
+	Camera      int       `json:"camera"` // index into GltfRoot.Cameras, or -1
+
+	Extensions GltfNodeExtensions `json:"extensions"`
+}
+
+// UnmarshalJSON defaults the optional indices to -1; glTF omits them rather
+// than writing 0, which is a valid index.
+func (n *GltfNode) UnmarshalJSON(b []byte) error {
+	type raw GltfNode
+	r := raw{Mesh: -1, Skin: -1, Camera: -1}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+	*n = GltfNode(r)
+	return nil
 }
 
 type gltfScene struct {
@@ -165,18 +254,23 @@ type gltfScene struct {
 // Helpers
 // ---------------------------
 
-func componentByteSize(typ string, comp int) int {
-	var csize int
+func componentSize(comp int) int {
 	switch comp {
-	case 5123: // UNSIGNED_SHORT
-		csize = 2
-	case 5125: // UNSIGNED_INT
-		csize = 4
-	case 5126: // FLOAT
-		csize = 4
+	case 5120, 5121: // BYTE, UNSIGNED_BYTE
+		return 1
+	case 5122, 5123: // SHORT, UNSIGNED_SHORT
+		return 2
+	case 5125, 5126: // UNSIGNED_INT, FLOAT
+		return 4
 	default:
-		panic(fmt.Sprintf("unsupported component type: %d", comp))
+		return 0
 	}
+}
+
+// componentByteSize returns the element size of an accessor, or 0 if the
+// component or accessor type is unknown.
+func componentByteSize(typ string, comp int) int {
+	csize := componentSize(comp)
 
 	switch typ {
 	case "SCALAR":
@@ -187,10 +281,14 @@ func componentByteSize(typ string, comp int) int {
 		return csize * 3
 	case "VEC4":
 		return csize * 4
+	case "MAT2":
+		return csize * 4
+	case "MAT3":
+		return csize * 9
 	case "MAT4":
 		return csize * 16 // <‑‑ ADD THIS
 	default:
-		panic(fmt.Sprintf("unsupported accessor type: %s", typ))
+		return 0
 	}
 }
 
@@ -220,7 +318,17 @@ func GetAccessor(g *GltfRoot, buffers [][]byte, idx int) (AccessorData, error) {
 	}
 	Acc := g.Accessors[idx]
 
-	if Acc.BufferView < 0 || Acc.BufferView >= len(g.BufferViews) {
+	elemSize := componentByteSize(Acc.Type, Acc.ComponentType)
+	if elemSize == 0 {
+		return AccessorData{}, fmt.Errorf("accessor %d: unsupported type %s/%d", idx, Acc.Type, Acc.ComponentType)
+	}
+
+	// sparse and bufferView-less accessors are expanded into a dense copy
+	if Acc.BufferView < 0 || Acc.Sparse != nil {
+		return denseAccessor(g, buffers, Acc, elemSize)
+	}
+
+	if Acc.BufferView >= len(g.BufferViews) {
 		return AccessorData{}, fmt.Errorf("bufferView index out of range: %d", Acc.BufferView)
 	}
 	Bv := g.BufferViews[Acc.BufferView]
@@ -230,7 +338,6 @@ func GetAccessor(g *GltfRoot, buffers [][]byte, idx int) (AccessorData, error) {
 	}
 	Buf := buffers[Bv.Buffer]
 
-	elemSize := componentByteSize(Acc.Type, Acc.ComponentType)
 	Stride := Bv.ByteStride
 	if Stride == 0 {
 		Stride = elemSize
@@ -238,6 +345,10 @@ func GetAccessor(g *GltfRoot, buffers [][]byte, idx int) (AccessorData, error) {
 
 	Base := Bv.ByteOffset + Acc.ByteOffset
 	end := Base + Acc.Count*Stride
+	if Acc.Count > 0 {
+		// the last element needs only elemSize bytes, not a full stride
+		end = Base + (Acc.Count-1)*Stride + elemSize
+	}
 	if end > len(Buf) {
 		return AccessorData{}, fmt.Errorf("accessor out of range: end=%d len=%d", end, len(Buf))
 	}
@@ -249,52 +360,34 @@ func GetAccessor(g *GltfRoot, buffers [][]byte, idx int) (AccessorData, error) {
 // Upload to OpenGL
 // ---------------------------
 
-func uploadMeshToGL(mm *MeshManager, id string, vertices []float32, indices []uint32) {
-	var vao, vbo, ebo uint32
-	gl.GenVertexArrays(1, &vao)
-	gl.GenBuffers(1, &vbo)
-	gl.GenBuffers(1, &ebo)
-
-	gl.BindVertexArray(vao)
+// VertexColorAttrib is the attribute location of per-vertex colours (glTF
+// COLOR_0, PLY red/green/blue). It follows the instance attributes; the
+// lit shaders multiply it into the base colour when useVertexColor is set.
+const VertexColorAttrib = 12
 
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
+func uploadMeshToGL(mm *MeshManager, id string, vertices []float32, indices []uint32) {
+	vao := Device.CreateVertexArray()
+	Device.BindVertexArray(vao)
 
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
+	vbo := Device.CreateBuffer(ArrayBuffer, len(vertices)*4, vertices, StaticDraw)
+	ebo := Device.CreateBuffer(ElementBuffer, len(indices)*4, indices, StaticDraw)
 
 	// pos(3), normal(3), uv(2), tangent(4) => 12 floats per vertex
-	stride := int32(12 * 4)
-
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, stride, 0)
-	gl.EnableVertexAttribArray(0)
+	stride := 12 * 4
 
-	gl.VertexAttribPointerWithOffset(1, 3, gl.FLOAT, false, stride, 3*4)
-	gl.EnableVertexAttribArray(1)
-
-	gl.VertexAttribPointerWithOffset(2, 2, gl.FLOAT, false, stride, 6*4)
-	gl.EnableVertexAttribArray(2)
-
-	gl.VertexAttribPointerWithOffset(3, 4, gl.FLOAT, false, stride, 8*4)
-	gl.EnableVertexAttribArray(3)
+	Device.VertexAttrib(0, 3, Float, false, stride, 0)
+	Device.VertexAttrib(1, 3, Float, false, stride, 3*4)
+	Device.VertexAttrib(2, 2, Float, false, stride, 6*4)
+	Device.VertexAttrib(3, 4, Float, false, stride, 8*4)
 
 	// --- NEW: JOINTS_0 (location = 4) ---
 	// JOINTS_0 (location = 4)
 	if js, ok := mm.JointData[id]; ok && len(js) > 0 {
-		var jointVBO uint32
-		gl.GenBuffers(1, &jointVBO)
-		gl.BindBuffer(gl.ARRAY_BUFFER, jointVBO)
 		size := int(len(js) * 8) // 4 * uint16 = 8 bytes per vertex
-		if len(js) > 0 {
-			gl.BufferData(gl.ARRAY_BUFFER, size, gl.Ptr(&js[0][0]), gl.STATIC_DRAW)
-		} else {
-			gl.BufferData(gl.ARRAY_BUFFER, size, nil, gl.STATIC_DRAW)
-		}
+		jointVBO := Device.CreateBuffer(ArrayBuffer, size, js, StaticDraw)
 
 		// integer attribute: ivec4 in shader
-		gl.VertexAttribIPointer(4, 4, gl.UNSIGNED_SHORT, 8, gl.PtrOffset(0))
-
-		gl.EnableVertexAttribArray(4)
+		Device.VertexAttrib(4, 4, UnsignedShort, true, 8, 0)
 
 		mm.vbos[id+"_joints"] = jointVBO
 	}
@@ -312,39 +405,38 @@ func uploadMeshToGL(mm *MeshManager, id string, vertices []float32, indices []ui
 			}
 		}
 
-		var weightVBO uint32
-		gl.GenBuffers(1, &weightVBO)
-		gl.BindBuffer(gl.ARRAY_BUFFER, weightVBO)
 		size := int(len(ws) * 16) // 4 * float32 = 16 bytes per vertex
-		if len(ws) > 0 {
-			gl.BufferData(gl.ARRAY_BUFFER, size, gl.Ptr(&ws[0][0]), gl.STATIC_DRAW)
-		} else {
-			gl.BufferData(gl.ARRAY_BUFFER, size, nil, gl.STATIC_DRAW)
-		}
-
-		gl.VertexAttribPointerWithOffset(5, 4, gl.FLOAT, false, 16, 0)
-		gl.EnableVertexAttribArray(5)
+		weightVBO := Device.CreateBuffer(ArrayBuffer, size, ws, StaticDraw)
+		Device.VertexAttrib(5, 4, Float, false, 16, 0)
 
 		mm.vbos[id+"_weights"] = weightVBO
 	}
 
-	gl.BindVertexArray(0)
+	// COLOR_0 (location = VertexColorAttrib)
+	if cs, ok := mm.ColorData[id]; ok && len(cs) > 0 {
+		colorVBO := Device.CreateBuffer(ArrayBuffer, len(cs)*16, cs, StaticDraw)
+		Device.VertexAttrib(VertexColorAttrib, 4, Float, false, 16, 0)
+
+		mm.vbos[id+"_colors"] = colorVBO
+	}
+
+	Device.BindVertexArray(0)
 
 	// Store GL objects and counts
 	mm.vaos[id] = vao
 	mm.vbos[id] = vbo
 	mm.ebos[id] = ebo
 	mm.counts[id] = int32(len(indices))
-	mm.indexTypes[id] = gl.UNSIGNED_INT
+	mm.indexTypes[id] = IndexUint32
 	mm.vertexCounts[id] = int32(len(vertices) / 12)
 	mm.layoutType[id] = 12
+	mm.keepCPUMesh(id, vertices, indices)
+	mm.bounds[id] = ComputeMeshBounds(vertices, 12)
 
 	// EBO sanity check (unchanged)
-	var eboSize int32
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-	gl.GetBufferParameteriv(gl.ELEMENT_ARRAY_BUFFER, gl.BUFFER_SIZE, &eboSize)
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, 0)
-	expected := int32(len(indices) * 4)
+	eboSize := Device.BufferSize(ElementBuffer, ebo)
+	Device.BindBuffer(ElementBuffer, 0)
+	expected := len(indices) * 4
 	if eboSize != expected {
 		log.Printf("Warning: EBO size mismatch for mesh %s: got %d, expected %d", id, eboSize, expected)
 	}
@@ -384,25 +476,18 @@ func LoadGLTFOrGLB(path string) (*GltfRoot, [][]byte, error) {
 			return nil, nil, err
 		}
 
-		var g GltfRoot
-		if err := json.Unmarshal(raw, &g); err != nil {
+		g, err := parseGLTFJSON(raw, path)
+		if err != nil {
 			return nil, nil, err
 		}
 
-		// Load external buffers
-		baseDir := filepath.Dir(path)
-		buffers := make([][]byte, len(g.Buffers))
-
-		for i, b := range g.Buffers {
-			bufPath := filepath.Join(baseDir, b.URI)
-			data, err := os.ReadFile(bufPath)
-			if err != nil {
-				return nil, nil, err
-			}
-			buffers[i] = data
+		// External or embedded (data:) buffers
+		buffers, err := loadGLTFBuffers(g, filepath.Dir(path))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
 		}
 
-		return &g, buffers, nil
+		return g, buffers, nil
 
 	default:
 		return nil, nil, fmt.Errorf("unsupported mesh format: %s", ext)
@@ -430,25 +515,28 @@ func (mm *MeshManager) RegisterGLTFMulti(path string) ([]string, error) {
 // ---------------------------
 
 func (mm *MeshManager) loadGLTFInternal(id, path string, multi bool) ([]string, error) {
+	meshes, err := DecodeGLTFMeshes(id, path, multi)
+	if err != nil {
+		return nil, err
+	}
+	meshIDs := make([]string, 0, len(meshes))
+	for _, md := range meshes {
+		mm.RegisterMeshData(md)
+		meshIDs = append(meshIDs, md.ID)
+	}
+	return meshIDs, nil
+}
+
+// DecodeGLTFMeshes decodes the primitives of a glTF/GLB file into upload-ready
+// MeshData without touching GL. IDs follow RegisterGLTF/RegisterGLTFMulti.
+func DecodeGLTFMeshes(id, path string, multi bool) ([]MeshData, error) {
 
-	var meshIDs []string
+	var meshes []MeshData
 	g, buffers, err := LoadGLTFOrGLB(path)
 	if err != nil {
 		return nil, err
 	}
 
-	if strings.ToLower(filepath.Ext(path)) == ".gltf" {
-		// load external buffers
-		baseDir := filepath.Dir(path)
-		buffers = make([][]byte, len(g.Buffers))
-		for i, b := range g.Buffers {
-			data, err := os.ReadFile(filepath.Join(baseDir, b.URI))
-			if err != nil {
-				return nil, err
-			}
-			buffers[i] = data
-		}
-	}
 	// Build world transforms for all nodes
 	nodeWorld := make([][16]float32, len(g.Nodes))
 
@@ -500,20 +588,34 @@ func (mm *MeshManager) loadGLTFInternal(id, path string, multi bool) ([]string,
 			if multi {
 				meshID = fmt.Sprintf("%s/%d", meshName, pi)
 			}
-			meshIDs = append(meshIDs, meshID)
+
+			if prim.Mode < 4 {
+				log.Printf("gltf: %s: skipping %s (points/lines are not rendered)", filepath.Base(path), meshID)
+				continue
+			}
+			md := MeshData{ID: meshID}
 
 			// POSITION
-			posA, err := GetAccessor(g, buffers, prim.Attributes["POSITION"])
+			posIdx, ok := prim.Attributes["POSITION"]
+			if !ok {
+				return nil, fmt.Errorf("%s: primitive %s has no POSITION", filepath.Base(path), meshID)
+			}
+			posA, err := GetAccessor(g, buffers, posIdx)
 			if err != nil {
 				return nil, err
 			}
 
 			count := posA.Acc.Count
 
-			// NORMAL
-			norA, err := GetAccessor(g, buffers, prim.Attributes["NORMAL"])
-			if err != nil {
-				return nil, err
+			// NORMAL (optional; generated from the triangles when missing)
+			var norA AccessorData
+			hasNormal := false
+			if norIdx, ok := prim.Attributes["NORMAL"]; ok {
+				norA, err = GetAccessor(g, buffers, norIdx)
+				if err != nil {
+					return nil, err
+				}
+				hasNormal = true
 			}
 
 			// UV (optional)
@@ -537,149 +639,118 @@ func (mm *MeshManager) loadGLTFInternal(id, path string, multi bool) ([]string,
 				}
 				hasTan = true
 			}
-			// JOINTS_0 (optional)
-			var jointsA AccessorData
-			hasJoints := false
-			if jIdx, ok := prim.Attributes["JOINTS_0"]; ok {
-				jointsA, err = GetAccessor(g, buffers, jIdx)
-				if err == nil {
-					hasJoints = true
+
+			// COLOR_0 (optional, VEC3 or VEC4)
+			if colIdx, ok := prim.Attributes["COLOR_0"]; ok {
+				colA, err := GetAccessor(g, buffers, colIdx)
+				if err != nil {
+					return nil, err
 				}
+				colors := make([][4]float32, count)
+				for i := range colors {
+					colors[i] = [4]float32{colA.Float(i, 0), colA.Float(i, 1), colA.Float(i, 2), 1}
+					if colA.Acc.Type == "VEC4" {
+						colors[i][3] = colA.Float(i, 3)
+					}
+				}
+				md.Colors = colors
 			}
-
-			// WEIGHTS_0 (optional)
-			var weightsA AccessorData
-			hasWeights := false
-			if wIdx, ok := prim.Attributes["WEIGHTS_0"]; ok {
-				weightsA, err = GetAccessor(g, buffers, wIdx)
-				if err == nil {
-					hasWeights = true
+			// JOINTS_n / WEIGHTS_n (optional). Sets beyond the first are folded
+			// into the strongest 4 influences per vertex.
+			var jointSets [][][4]uint16
+			var weightSets [][][4]float32
+			for set := 0; ; set++ {
+				jIdx, okJ := prim.Attributes[fmt.Sprintf("JOINTS_%d", set)]
+				wIdx, okW := prim.Attributes[fmt.Sprintf("WEIGHTS_%d", set)]
+				if !okJ || !okW {
+					break
+				}
+				jointsA, err := GetAccessor(g, buffers, jIdx)
+				if err != nil {
+					break
 				}
+				weightsA, err := GetAccessor(g, buffers, wIdx)
+				if err != nil {
+					break
+				}
+				js, err := decodeJoints(jointsA)
+				if err != nil {
+					return nil, err
+				}
+				ws, err := decodeWeights(weightsA)
+				if err != nil {
+					return nil, err
+				}
+				jointSets = append(jointSets, js)
+				weightSets = append(weightSets, ws)
 			}
+			skinned := len(jointSets) > 0
 
-			// INDICES
-			idxA, err := GetAccessor(g, buffers, prim.Indices)
-			if err != nil {
+			// INDICES (non-indexed primitives draw their vertices in order)
+			var indices []uint32
+			if prim.Indices >= 0 {
+				idxA, err := GetAccessor(g, buffers, prim.Indices)
+				if err != nil {
+					return nil, err
+				}
+				indices = make([]uint32, idxA.Acc.Count)
+				for i := range indices {
+					if indices[i], err = idxA.Index(i); err != nil {
+						return nil, err
+					}
+				}
+			} else {
+				indices = make([]uint32, count)
+				for i := range indices {
+					indices[i] = uint32(i)
+				}
+			}
+			if indices, err = triangulate(prim.Mode, indices); err != nil {
 				return nil, err
 			}
 
-			// Decode indices
-			indices := make([]uint32, idxA.Acc.Count)
-			switch idxA.Acc.ComponentType {
-			case 5123: // UNSIGNED_SHORT
-				for i := 0; i < idxA.Acc.Count; i++ {
-					off := idxA.Base + i*idxA.Stride
-					b := idxA.Buf[off : off+2]
-					indices[i] = uint32(b[0]) | uint32(b[1])<<8
-				}
-			case 5125: // UNSIGNED_INT
-				for i := 0; i < idxA.Acc.Count; i++ {
-					off := idxA.Base + i*idxA.Stride
-					b := idxA.Buf[off : off+4]
-					indices[i] = uint32(b[0]) |
-						uint32(b[1])<<8 |
-						uint32(b[2])<<16 |
-						uint32(b[3])<<24
-				}
-			default:
-				return nil, fmt.Errorf("unsupported index type: %d", idxA.Acc.ComponentType)
+			// Positions are needed up front when normals have to be generated.
+			positions := make([][3]float32, count)
+			for i := range positions {
+				positions[i] = [3]float32{posA.Float(i, 0), posA.Float(i, 1), posA.Float(i, 2)}
+			}
+			var genNormals [][3]float32
+			if !hasNormal {
+				genNormals = generateNormals(positions, indices)
 			}
 
 			// Build interleaved vertices
 			vertices := make([]float32, 0, count*12)
 
-			// Build interleaved vertices
-
-			if hasJoints {
-				js := make([][4]uint16, count)
-				for i := 0; i < count; i++ {
-					off := jointsA.Base + i*jointsA.Stride
-
-					switch jointsA.Acc.ComponentType {
-					case 5121: // UNSIGNED_BYTE
-						js[i] = [4]uint16{
-							uint16(jointsA.Buf[off+0]),
-							uint16(jointsA.Buf[off+1]),
-							uint16(jointsA.Buf[off+2]),
-							uint16(jointsA.Buf[off+3]),
-						}
-
-					case 5123: // UNSIGNED_SHORT
-						js[i] = [4]uint16{
-							uint16(jointsA.Buf[off+0]) | uint16(jointsA.Buf[off+1])<<8,
-							uint16(jointsA.Buf[off+2]) | uint16(jointsA.Buf[off+3])<<8,
-							uint16(jointsA.Buf[off+4]) | uint16(jointsA.Buf[off+5])<<8,
-							uint16(jointsA.Buf[off+6]) | uint16(jointsA.Buf[off+7])<<8,
-						}
-
-					default:
-						panic(fmt.Sprintf("unsupported JOINTS_0 component type: %d", jointsA.Acc.ComponentType))
-					}
-				}
-				mm.JointData[meshID] = js
-			}
-			if hasWeights {
-				ws := make([][4]float32, count)
-				for i := 0; i < count; i++ {
-					off := weightsA.Base + i*weightsA.Stride
-					switch weightsA.Acc.ComponentType {
-					case 5126: // FLOAT
-						ws[i] = [4]float32{
-							BytesToFloat32(weightsA.Buf[off+0:]),
-							BytesToFloat32(weightsA.Buf[off+4:]),
-							BytesToFloat32(weightsA.Buf[off+8:]),
-							BytesToFloat32(weightsA.Buf[off+12:]),
-						}
-					case 5121: // UNSIGNED_BYTE (normalized)
-						ws[i] = [4]float32{
-							float32(weightsA.Buf[off+0]) / 255.0,
-							float32(weightsA.Buf[off+1]) / 255.0,
-							float32(weightsA.Buf[off+2]) / 255.0,
-							float32(weightsA.Buf[off+3]) / 255.0,
-						}
-					case 5123: // UNSIGNED_SHORT (normalized)
-						ws[i] = [4]float32{
-							float32(uint16(weightsA.Buf[off+0])|uint16(weightsA.Buf[off+1])<<8) / 65535.0,
-							float32(uint16(weightsA.Buf[off+2])|uint16(weightsA.Buf[off+3])<<8) / 65535.0,
-							float32(uint16(weightsA.Buf[off+4])|uint16(weightsA.Buf[off+5])<<8) / 65535.0,
-							float32(uint16(weightsA.Buf[off+6])|uint16(weightsA.Buf[off+7])<<8) / 65535.0,
-						}
-					default:
-						return nil, fmt.Errorf("unsupported WEIGHTS_0 component type: %d", weightsA.Acc.ComponentType)
-					}
-
-				}
-				mm.WeightData[meshID] = ws
+			if skinned {
+				md.Joints, md.Weights = CapJointInfluences(jointSets, weightSets)
 			}
 			for i := 0; i < count; i++ {
 				// POSITION
-				pOff := posA.Base + i*posA.Stride
-				px := BytesToFloat32(posA.Buf[pOff+0:])
-				py := BytesToFloat32(posA.Buf[pOff+4:])
-				pz := BytesToFloat32(posA.Buf[pOff+8:])
+				px, py, pz := positions[i][0], positions[i][1], positions[i][2]
 
 				// NORMAL
-				nOff := norA.Base + i*norA.Stride
-				nx := BytesToFloat32(norA.Buf[nOff+0:])
-				ny := BytesToFloat32(norA.Buf[nOff+4:])
-				nz := BytesToFloat32(norA.Buf[nOff+8:])
+				var nx, ny, nz float32
+				if hasNormal {
+					nx, ny, nz = norA.Float(i, 0), norA.Float(i, 1), norA.Float(i, 2)
+				} else {
+					nx, ny, nz = genNormals[i][0], genNormals[i][1], genNormals[i][2]
+				}
 
-				// UV
+				// UV (normalized UNSIGNED_BYTE/SHORT texcoords are dequantised)
 				var u, v float32
 				if hasUV {
-					uvOff := uvA.Base + i*uvA.Stride
-					u = BytesToFloat32(uvA.Buf[uvOff+0:])
-					v = BytesToFloat32(uvA.Buf[uvOff+4:])
+					u = uvA.Float(i, 0)
+					v = uvA.Float(i, 1)
 				}
 
 				// TANGENT
 				tx, ty, tz, tw := float32(1), float32(0), float32(0), float32(1)
 				if hasTan {
-					tOff := tanA.Base + i*tanA.Stride
-					tx = BytesToFloat32(tanA.Buf[tOff+0:])
-					ty = BytesToFloat32(tanA.Buf[tOff+4:])
-					tz = BytesToFloat32(tanA.Buf[tOff+8:])
-					tw = BytesToFloat32(tanA.Buf[tOff+12:])
+					tx = tanA.Float(i, 0)
+					ty = tanA.Float(i, 1)
+					tz = tanA.Float(i, 2)
+					tw = tanA.Float(i, 3)
 				}
 
 				vertices = append(vertices,
@@ -690,13 +761,15 @@ func (mm *MeshManager) loadGLTFInternal(id, path string, multi bool) ([]string,
 				)
 			}
 
-			// Upload
-			uploadMeshToGL(mm, meshID, vertices, indices)
+			md.Vertices = vertices
+			md.Indices = indices
+			md.HasTangents = hasTan
+			meshes = append(meshes, md)
 		}
 
 	}
 
-	return meshIDs, nil
+	return meshes, nil
 
 }
 
@@ -722,6 +795,10 @@ type LoadedMeshMaterial struct {
 	SheenColor     [3]float32
 	SheenRoughness float32
 	SpecularFactor float32
+
+	AlphaMode   AlphaMode
+	AlphaCutoff float32
+	DoubleSided bool
 }
 
 // LoadGLTFMaterials returns material info for the first mesh/primitive,
@@ -737,7 +814,7 @@ func LoadGLTFMaterialsMulti(path string) ([]LoadedMeshMaterial, error) {
 }
 
 func loadGLTFMaterialsInternal(id, path string, multi bool) ([]LoadedMeshMaterial, error) {
-	g, _, err := LoadGLTFOrGLB(path)
+	g, buffers, err := LoadGLTFOrGLB(path)
 	if err != nil {
 		return nil, err
 	}
@@ -763,8 +840,9 @@ func loadGLTFMaterialsInternal(id, path string, multi bool) ([]LoadedMeshMateria
 			}
 
 			m := LoadedMeshMaterial{
-				MeshID:    meshID,
-				BaseColor: [4]float32{1, 1, 1, 1},
+				MeshID:      meshID,
+				BaseColor:   [4]float32{1, 1, 1, 1},
+				AlphaCutoff: DefaultAlphaCutoff,
 			}
 
 			// inside loadGLTFMaterialsInternal, replace the existing "if prim.Material >= 0 ..." block
@@ -791,11 +869,7 @@ func loadGLTFMaterialsInternal(id, path string, multi bool) ([]LoadedMeshMateria
 						return ""
 					}
 					tex := g.Textures[ti.Index]
-					imgIndex := textureSourceIndex(tex)
-					if imgIndex >= 0 && imgIndex < len(g.Images) {
-						return filepath.Join(baseDir, g.Images[imgIndex].URI)
-					}
-					return ""
+					return imagePath(g, buffers, baseDir, textureSourceIndex(tex))
 				}
 
 				// Base color (diffuse)
@@ -926,10 +1000,16 @@ func loadGLTFMaterialsInternal(id, path string, multi bool) ([]LoadedMeshMateria
 					}
 				}
 
-				// Alpha mode (optional)
-				if gm.AlphaMode != "" {
-					// store if you want to handle transparency later
+				// Alpha mode, cutoff and culling
+				mode, ok := ParseAlphaMode(gm.AlphaMode)
+				if !ok {
+					log.Printf("gltf %s: material %q: unknown alphaMode %q, using OPAQUE", path, gm.Name, gm.AlphaMode)
+				}
+				m.AlphaMode = mode
+				if gm.AlphaCutoff != nil {
+					m.AlphaCutoff = *gm.AlphaCutoff
 				}
+				m.DoubleSided = gm.DoubleSided
 			}
 
 			results = append(results, m)
@@ -1005,14 +1085,28 @@ func ComposeNodeTransform(n GltfNode) [16]float32 {
 	return composeNodeTransform(n)
 }
 
+// LoadGLTFRoot parses only the JSON of a .gltf or .glb file.
 func LoadGLTFRoot(path string) (*GltfRoot, error) {
+	if strings.ToLower(filepath.Ext(path)) == ".glb" {
+		g, _, err := loadGLB(path)
+		return g, err
+	}
 	raw, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
+	return parseGLTFJSON(raw, path)
+}
+
+// parseGLTFJSON decodes the glTF JSON and rejects files that require
+// extensions this loader does not implement.
+func parseGLTFJSON(raw []byte, path string) (*GltfRoot, error) {
 	var g GltfRoot
 	if err := json.Unmarshal(raw, &g); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	if err := checkRequiredExtensions(&g); err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
 	}
 	return &g, nil
 }
@@ -1047,18 +1141,21 @@ func loadGLB(path string) (*GltfRoot, [][]byte, error) {
 	if jsonChunkType != "JSON" {
 		return nil, nil, fmt.Errorf("first GLB chunk is not JSON")
 	}
+	if offset+jsonChunkLen > len(raw) {
+		return nil, nil, fmt.Errorf("GLB JSON chunk out of range")
+	}
 
 	jsonBytes := raw[offset : offset+jsonChunkLen]
 	offset += jsonChunkLen
 
-	var g GltfRoot
-	if err := json.Unmarshal(jsonBytes, &g); err != nil {
+	g, err := parseGLTFJSON(jsonBytes, path)
+	if err != nil {
 		return nil, nil, err
 	}
 
 	// --- BIN chunk (optional) ---
-	var buffers [][]byte
-	if offset < len(raw) {
+	var bin []byte
+	if offset+8 <= len(raw) {
 		binChunkLen := int(binary.LittleEndian.Uint32(raw[offset : offset+4]))
 		binChunkType := string(raw[offset+4 : offset+8])
 		offset += 8
@@ -1066,12 +1163,31 @@ func loadGLB(path string) (*GltfRoot, [][]byte, error) {
 		if binChunkType != "BIN\x00" {
 			return nil, nil, fmt.Errorf("second GLB chunk is not BIN")
 		}
+		if offset+binChunkLen > len(raw) {
+			return nil, nil, fmt.Errorf("GLB BIN chunk out of range")
+		}
+
+		bin = raw[offset : offset+binChunkLen]
+	}
 
-		binBytes := raw[offset : offset+binChunkLen]
-		buffers = append(buffers, binBytes)
+	// buffer 0 without a uri is the BIN chunk; any others are external or data: URIs
+	buffers := make([][]byte, len(g.Buffers))
+	for i, b := range g.Buffers {
+		if i == 0 && b.URI == "" {
+			if bin == nil {
+				return nil, nil, fmt.Errorf("GLB buffer 0 has no BIN chunk")
+			}
+			buffers[i] = bin
+			continue
+		}
+		data, err := loadURI(filepath.Dir(path), b.URI)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: buffer %d: %w", filepath.Base(path), i, err)
+		}
+		buffers[i] = data
 	}
 
-	return &g, buffers, nil
+	return g, buffers, nil
 }
 
 func MulMat4(a, b [16]float32) [16]float32 {
@@ -0,0 +1,331 @@
+package engine
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ---------------------------
+// URIs (buffers and images)
+// ---------------------------
+
+// loadURI reads a buffer or image URI: either an embedded data: URI or a
+// (percent-encoded) path relative to the glTF file.
+func loadURI(baseDir, uri string) ([]byte, error) {
+	if strings.HasPrefix(uri, "data:") {
+		return decodeDataURI(uri)
+	}
+	p, err := url.PathUnescape(uri)
+	if err != nil {
+		p = uri
+	}
+	return os.ReadFile(filepath.Join(baseDir, filepath.FromSlash(p)))
+}
+
+// decodeDataURI decodes "data:[<mime>][;base64],<payload>".
+func decodeDataURI(uri string) ([]byte, error) {
+	comma := strings.IndexByte(uri, ',')
+	if comma < 0 {
+		return nil, fmt.Errorf("malformed data URI")
+	}
+	header, payload := uri[len("data:"):comma], uri[comma+1:]
+	if strings.HasSuffix(header, ";base64") {
+		data, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			return nil, fmt.Errorf("data URI: %w", err)
+		}
+		return data, nil
+	}
+	s, err := url.PathUnescape(payload)
+	if err != nil {
+		return nil, fmt.Errorf("data URI: %w", err)
+	}
+	return []byte(s), nil
+}
+
+// loadGLTFBuffers resolves every buffer of a .gltf file.
+func loadGLTFBuffers(g *GltfRoot, baseDir string) ([][]byte, error) {
+	buffers := make([][]byte, len(g.Buffers))
+	for i, b := range g.Buffers {
+		data, err := loadURI(baseDir, b.URI)
+		if err != nil {
+			return nil, fmt.Errorf("buffer %d: %w", i, err)
+		}
+		if len(data) < b.ByteLength {
+			return nil, fmt.Errorf("buffer %d: %d bytes, byteLength is %d", i, len(data), b.ByteLength)
+		}
+		buffers[i] = data
+	}
+	return buffers, nil
+}
+
+// imagePath returns a file path LoadTexture can open for image imgIndex.
+// Images embedded as data URIs or bufferViews (GLB) are written once to a
+// cache directory keyed by their content hash.
+func imagePath(g *GltfRoot, buffers [][]byte, baseDir string, imgIndex int) string {
+	if imgIndex < 0 || imgIndex >= len(g.Images) {
+		return ""
+	}
+	img := g.Images[imgIndex]
+
+	var data []byte
+	switch {
+	case img.BufferView >= 0:
+		_, view, err := bufferViewBytes(g, buffers, img.BufferView)
+		if err != nil {
+			return ""
+		}
+		data = view
+	case strings.HasPrefix(img.URI, "data:"):
+		d, err := decodeDataURI(img.URI)
+		if err != nil {
+			return ""
+		}
+		data = d
+		if img.MimeType == "" {
+			img.MimeType = strings.SplitN(img.URI[len("data:"):], ";", 2)[0]
+		}
+	case img.URI != "":
+		p, err := url.PathUnescape(img.URI)
+		if err != nil {
+			p = img.URI
+		}
+		return filepath.Join(baseDir, filepath.FromSlash(p))
+	default:
+		return ""
+	}
+
+	sum := sha1.Sum(data)
+	dir := filepath.Join(os.TempDir(), "go-cordance-gltf")
+	path := filepath.Join(dir, hex.EncodeToString(sum[:])+imageExt(img.MimeType))
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return ""
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return ""
+	}
+	return path
+}
+
+func imageExt(mime string) string {
+	switch mime {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/webp":
+		return ".webp"
+	default:
+		return ".png"
+	}
+}
+
+// ---------------------------
+// Accessors
+// ---------------------------
+
+// bufferViewBytes returns the bytes covered by a bufferView.
+func bufferViewBytes(g *GltfRoot, buffers [][]byte, idx int) (gltfBufferView, []byte, error) {
+	if idx < 0 || idx >= len(g.BufferViews) {
+		return gltfBufferView{}, nil, fmt.Errorf("bufferView index out of range: %d", idx)
+	}
+	bv := g.BufferViews[idx]
+	if bv.Buffer < 0 || bv.Buffer >= len(buffers) {
+		return bv, nil, fmt.Errorf("buffer index out of range: %d", bv.Buffer)
+	}
+	buf := buffers[bv.Buffer]
+	end := bv.ByteOffset + bv.ByteLength
+	if bv.ByteOffset < 0 || end > len(buf) {
+		return bv, nil, fmt.Errorf("bufferView %d out of range: end=%d len=%d", idx, end, len(buf))
+	}
+	return bv, buf[bv.ByteOffset:end], nil
+}
+
+// denseAccessor materialises an accessor without a bufferView (all zeros)
+// and/or with sparse substitutions into a tightly packed buffer, so readers
+// can keep using Base/Stride.
+func denseAccessor(g *GltfRoot, buffers [][]byte, acc gltfAccessor, elemSize int) (AccessorData, error) {
+	dense := make([]byte, acc.Count*elemSize)
+	var bv gltfBufferView
+
+	if acc.BufferView >= 0 {
+		view, data, err := bufferViewBytes(g, buffers, acc.BufferView)
+		if err != nil {
+			return AccessorData{}, err
+		}
+		bv = view
+		stride := bv.ByteStride
+		if stride == 0 {
+			stride = elemSize
+		}
+		if acc.Count > 0 && acc.ByteOffset+(acc.Count-1)*stride+elemSize > len(data) {
+			return AccessorData{}, fmt.Errorf("accessor out of range of bufferView %d", acc.BufferView)
+		}
+		for i := 0; i < acc.Count; i++ {
+			off := acc.ByteOffset + i*stride
+			copy(dense[i*elemSize:], data[off:off+elemSize])
+		}
+	}
+
+	if sp := acc.Sparse; sp != nil && sp.Count > 0 {
+		_, idxData, err := bufferViewBytes(g, buffers, sp.Indices.BufferView)
+		if err != nil {
+			return AccessorData{}, fmt.Errorf("sparse indices: %w", err)
+		}
+		_, valData, err := bufferViewBytes(g, buffers, sp.Values.BufferView)
+		if err != nil {
+			return AccessorData{}, fmt.Errorf("sparse values: %w", err)
+		}
+		ct := sp.Indices.ComponentType
+		if ct != 5121 && ct != 5123 && ct != 5125 {
+			return AccessorData{}, fmt.Errorf("sparse indices: unsupported component type %d", ct)
+		}
+		idxSize := componentSize(ct)
+		idxData = idxData[min(sp.Indices.ByteOffset, len(idxData)):]
+		valData = valData[min(sp.Values.ByteOffset, len(valData)):]
+		if len(idxData) < sp.Count*idxSize || len(valData) < sp.Count*elemSize {
+			return AccessorData{}, fmt.Errorf("sparse accessor out of range")
+		}
+
+		for i := 0; i < sp.Count; i++ {
+			var target int
+			switch idxSize {
+			case 1:
+				target = int(idxData[i])
+			case 2:
+				target = int(binary.LittleEndian.Uint16(idxData[i*2:]))
+			case 4:
+				target = int(binary.LittleEndian.Uint32(idxData[i*4:]))
+			}
+			if target >= acc.Count {
+				return AccessorData{}, fmt.Errorf("sparse index %d out of range (count %d)", target, acc.Count)
+			}
+			copy(dense[target*elemSize:(target+1)*elemSize], valData[i*elemSize:])
+		}
+	}
+
+	return AccessorData{Acc: acc, Bv: bv, Buf: dense, Base: 0, Stride: elemSize}, nil
+}
+
+// Float returns component c of element i, converting integer types to float
+// and dequantising normalized ones (texcoords, colors, KHR_mesh_quantization).
+func (a AccessorData) Float(i, c int) float32 {
+	off := a.Base + i*a.Stride + c*componentSize(a.Acc.ComponentType)
+	b := a.Buf[off:]
+	norm := a.Acc.Normalized
+
+	switch a.Acc.ComponentType {
+	case 5120: // BYTE
+		v := float32(int8(b[0]))
+		if norm {
+			return float32(math.Max(float64(v/127), -1))
+		}
+		return v
+	case 5121: // UNSIGNED_BYTE
+		v := float32(b[0])
+		if norm {
+			return v / 255
+		}
+		return v
+	case 5122: // SHORT
+		v := float32(int16(binary.LittleEndian.Uint16(b)))
+		if norm {
+			return float32(math.Max(float64(v/32767), -1))
+		}
+		return v
+	case 5123: // UNSIGNED_SHORT
+		v := float32(binary.LittleEndian.Uint16(b))
+		if norm {
+			return v / 65535
+		}
+		return v
+	case 5125: // UNSIGNED_INT
+		return float32(binary.LittleEndian.Uint32(b))
+	default: // FLOAT
+		return BytesToFloat32(b)
+	}
+}
+
+// Index returns element i of a scalar integer accessor (indices).
+func (a AccessorData) Index(i int) (uint32, error) {
+	off := a.Base + i*a.Stride
+	switch a.Acc.ComponentType {
+	case 5121:
+		return uint32(a.Buf[off]), nil
+	case 5123:
+		return uint32(binary.LittleEndian.Uint16(a.Buf[off:])), nil
+	case 5125:
+		return binary.LittleEndian.Uint32(a.Buf[off:]), nil
+	default:
+		return 0, fmt.Errorf("unsupported index type: %d", a.Acc.ComponentType)
+	}
+}
+
+// triangulate converts strip and fan indices to a triangle list.
+func triangulate(mode int, idx []uint32) ([]uint32, error) {
+	switch mode {
+	case 4: // TRIANGLES
+		return idx, nil
+	case 5: // TRIANGLE_STRIP
+		var out []uint32
+		for i := 2; i < len(idx); i++ {
+			if i%2 == 0 {
+				out = append(out, idx[i-2], idx[i-1], idx[i])
+			} else {
+				out = append(out, idx[i-1], idx[i-2], idx[i])
+			}
+		}
+		return out, nil
+	case 6: // TRIANGLE_FAN
+		var out []uint32
+		for i := 2; i < len(idx); i++ {
+			out = append(out, idx[0], idx[i-1], idx[i])
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unsupported primitive mode %d (points/lines)", mode)
+	}
+}
+
+// generateNormals computes area-weighted vertex normals for primitives
+// without a NORMAL attribute.
+func generateNormals(pos [][3]float32, indices []uint32) [][3]float32 {
+	out := make([][3]float32, len(pos))
+	for t := 0; t+2 < len(indices); t += 3 {
+		a, b, c := indices[t], indices[t+1], indices[t+2]
+		if int(a) >= len(pos) || int(b) >= len(pos) || int(c) >= len(pos) {
+			continue
+		}
+		p0, p1, p2 := pos[a], pos[b], pos[c]
+		e1 := [3]float32{p1[0] - p0[0], p1[1] - p0[1], p1[2] - p0[2]}
+		e2 := [3]float32{p2[0] - p0[0], p2[1] - p0[1], p2[2] - p0[2]}
+		n := [3]float32{
+			e1[1]*e2[2] - e1[2]*e2[1],
+			e1[2]*e2[0] - e1[0]*e2[2],
+			e1[0]*e2[1] - e1[1]*e2[0],
+		}
+		for _, v := range [3]uint32{a, b, c} {
+			out[v][0] += n[0]
+			out[v][1] += n[1]
+			out[v][2] += n[2]
+		}
+	}
+	for i, n := range out {
+		l := float32(math.Sqrt(float64(n[0]*n[0] + n[1]*n[1] + n[2]*n[2])))
+		if l > 0 {
+			out[i] = [3]float32{n[0] / l, n[1] / l, n[2] / l}
+		} else {
+			out[i] = [3]float32{0, 1, 0}
+		}
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ErrUnsupportedGLTFExtension is returned (wrapped) when a file lists an
+// extension in extensionsRequired that the loader does not implement.
+var ErrUnsupportedGLTFExtension = errors.New("unsupported required glTF extension")
+
+// supportedGLTFExtensions are the extensions the loader understands well
+// enough to accept them in extensionsRequired. KHR_texture_transform (whose
+// rotation is dropped), KHR_materials_specular and KHR_materials_sheen are
+// read when merely used, but only approximately, so files that require
+// them are refused.
+var supportedGLTFExtensions = map[string]bool{
+	"KHR_lights_punctual":   true,
+	"KHR_mesh_quantization": true,
+	"EXT_texture_webp":      true,
+}
+
+func checkRequiredExtensions(g *GltfRoot) error {
+	var missing []string
+	for _, ext := range g.ExtensionsRequired {
+		if !supportedGLTFExtensions[ext] {
+			missing = append(missing, ext)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrUnsupportedGLTFExtension, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// ---------------------------
+// Cameras
+// ---------------------------
+
+type GltfCamera struct {
+	Name         string            `json:"name"`
+	Type         string            `json:"type"` // "perspective" or "orthographic"
+	Perspective  *GltfPerspective  `json:"perspective"`
+	Orthographic *GltfOrthographic `json:"orthographic"`
+}
+
+type GltfPerspective struct {
+	AspectRatio float32 `json:"aspectRatio"`
+	Yfov        float32 `json:"yfov"` // radians
+	Zfar        float32 `json:"zfar"` // 0: infinite
+	Znear       float32 `json:"znear"`
+}
+
+type GltfOrthographic struct {
+	Xmag  float32 `json:"xmag"`
+	Ymag  float32 `json:"ymag"`
+	Zfar  float32 `json:"zfar"`
+	Znear float32 `json:"znear"`
+}
+
+// ---------------------------
+// KHR_lights_punctual
+// ---------------------------
+
+type GltfRootExtensions struct {
+	LightsPunctual *struct {
+		Lights []GltfLight `json:"lights"`
+	} `json:"KHR_lights_punctual"`
+}
+
+type GltfNodeExtensions struct {
+	LightsPunctual *struct {
+		Light int `json:"light"`
+	} `json:"KHR_lights_punctual"`
+}
+
+type GltfLight struct {
+	Name      string    `json:"name"`
+	Type      string    `json:"type"` // "directional", "point" or "spot"
+	Color     []float32 `json:"color"`
+	Intensity float32   `json:"intensity"`
+	Range     float32   `json:"range"` // 0: infinite
+	Spot      *GltfSpot `json:"spot"`
+}
+
+type GltfSpot struct {
+	InnerConeAngle float32 `json:"innerConeAngle"` // radians
+	OuterConeAngle float32 `json:"outerConeAngle"` // radians
+}
+
+func (l *GltfLight) UnmarshalJSON(b []byte) error {
+	type raw GltfLight
+	r := raw{Intensity: 1}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+	*l = GltfLight(r)
+	return nil
+}
+
+func (s *GltfSpot) UnmarshalJSON(b []byte) error {
+	type raw GltfSpot
+	r := raw{OuterConeAngle: math.Pi / 4}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+	*s = GltfSpot(r)
+	return nil
+}
+
+// NodeLight returns the punctual light attached to n, if any.
+func (g *GltfRoot) NodeLight(n GltfNode) (GltfLight, bool) {
+	ext := n.Extensions.LightsPunctual
+	lights := g.Extensions.LightsPunctual
+	if ext == nil || lights == nil || ext.Light < 0 || ext.Light >= len(lights.Lights) {
+		return GltfLight{}, false
+	}
+	return lights.Lights[ext.Light], true
+}
@@ -0,0 +1,152 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeGLTF writes a .gltf whose single buffer is embedded as a data URI.
+func writeGLTF(t *testing.T, bin []byte, body string) string {
+	t.Helper()
+	uri := "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(bin)
+	js := fmt.Sprintf(`{"asset":{"version":"2.0"},"buffers":[{"uri":%q,"byteLength":%d}],%s}`, uri, len(bin), body)
+	path := filepath.Join(t.TempDir(), "test.gltf")
+	if err := os.WriteFile(path, []byte(js), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestGLTF_DataURISparseAndNormalized(t *testing.T) {
+	var bin bytes.Buffer
+	// view 0 @0: 3 VEC3 floats (base positions)
+	for _, f := range []float32{0, 0, 0, 1, 1, 1, 2, 2, 2} {
+		binary.Write(&bin, binary.LittleEndian, f)
+	}
+	// view 1 @36: sparse indices (u16) [2], padded to 4
+	binary.Write(&bin, binary.LittleEndian, []uint16{2, 0})
+	// view 2 @40: sparse values, one VEC3
+	binary.Write(&bin, binary.LittleEndian, []float32{9, 8, 7})
+	// view 3 @52: normalized UNSIGNED_BYTE VEC2 texcoords
+	bin.Write([]byte{0, 255, 51, 102})
+
+	path := writeGLTF(t, bin.Bytes(), `
+		"bufferViews":[
+			{"buffer":0,"byteOffset":0,"byteLength":36},
+			{"buffer":0,"byteOffset":36,"byteLength":2},
+			{"buffer":0,"byteOffset":40,"byteLength":12},
+			{"buffer":0,"byteOffset":52,"byteLength":4}],
+		"accessors":[
+			{"bufferView":0,"componentType":5126,"count":3,"type":"VEC3",
+			 "sparse":{"count":1,"indices":{"bufferView":1,"componentType":5123},"values":{"bufferView":2}}},
+			{"componentType":5126,"count":2,"type":"VEC3",
+			 "sparse":{"count":1,"indices":{"bufferView":1,"componentType":5123},"values":{"bufferView":2}}},
+			{"bufferView":3,"componentType":5121,"normalized":true,"count":2,"type":"VEC2"}],
+		"nodes":[{"name":"empty"},{"mesh":0,"extensions":{"KHR_lights_punctual":{"light":0}}}],
+		"extensions":{"KHR_lights_punctual":{"lights":[{"type":"spot","color":[1,0,0]}]}},
+		"extensionsRequired":["KHR_lights_punctual"]`)
+
+	g, buffers, err := LoadGLTFOrGLB(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	pos, err := GetAccessor(g, buffers, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := [3][3]float32{}
+	for i := range got {
+		got[i] = [3]float32{pos.Float(i, 0), pos.Float(i, 1), pos.Float(i, 2)}
+	}
+	if got != [3][3]float32{{0, 0, 0}, {1, 1, 1}, {9, 8, 7}} {
+		t.Fatalf("sparse positions = %v", got)
+	}
+
+	// zero-filled accessor of count 2: sparse index 2 must be rejected
+	if _, err := GetAccessor(g, buffers, 1); err == nil {
+		t.Fatal("expected out-of-range sparse index error")
+	}
+
+	uv, err := GetAccessor(g, buffers, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u, v := uv.Float(0, 0), uv.Float(0, 1); u != 0 || v != 1 {
+		t.Fatalf("uv0 = %v,%v", u, v)
+	}
+	if u := uv.Float(1, 0); math.Abs(float64(u-0.2)) > 1e-6 {
+		t.Fatalf("uv1.u = %v", u)
+	}
+
+	if g.Nodes[0].Mesh != -1 || g.Nodes[0].Camera != -1 || g.Nodes[0].Skin != -1 {
+		t.Fatalf("absent node indices should be -1: %+v", g.Nodes[0])
+	}
+	l, ok := g.NodeLight(g.Nodes[1])
+	if !ok || l.Type != "spot" || l.Intensity != 1 || l.Spot != nil {
+		t.Fatalf("light = %+v ok=%v", l, ok)
+	}
+}
+
+func TestGLTF_UnsupportedRequiredExtension(t *testing.T) {
+	path := writeGLTF(t, []byte{0, 0, 0, 0},
+		`"extensionsRequired":["KHR_draco_mesh_compression","KHR_texture_transform"]`)
+
+	_, _, err := LoadGLTFOrGLB(path)
+	if !errors.Is(err, ErrUnsupportedGLTFExtension) || !strings.Contains(err.Error(), "KHR_texture_transform") {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := LoadGLTFRoot(path); !errors.Is(err, ErrUnsupportedGLTFExtension) {
+		t.Fatalf("LoadGLTFRoot err = %v", err)
+	}
+}
+
+func TestGLTF_MaterialAlphaModes(t *testing.T) {
+	path := writeGLTF(t, []byte{0, 0, 0, 0}, `
+		"materials":[
+			{"name":"leaves","alphaMode":"MASK","alphaCutoff":0.3,"doubleSided":true},
+			{"name":"glass","alphaMode":"BLEND","pbrMetallicRoughness":{"baseColorFactor":[1,1,1,0.25]}},
+			{"name":"plain"},
+			{"name":"odd","alphaMode":"ADDITIVE"}],
+		"meshes":[{"name":"m","primitives":[
+			{"attributes":{},"material":0},
+			{"attributes":{},"material":1},
+			{"attributes":{},"material":2},
+			{"attributes":{},"material":3}]}]`)
+
+	mats, err := LoadGLTFMaterialsMulti(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mats) != 4 {
+		t.Fatalf("%d materials", len(mats))
+	}
+	want := []struct {
+		mode   AlphaMode
+		cutoff float32
+		double bool
+	}{
+		{AlphaMask, 0.3, true},
+		{AlphaBlend, DefaultAlphaCutoff, false},
+		{AlphaOpaque, DefaultAlphaCutoff, false},
+		{AlphaOpaque, DefaultAlphaCutoff, false}, // unknown modes fall back
+	}
+	for i, w := range want {
+		m := mats[i]
+		if m.AlphaMode != w.mode || m.AlphaCutoff != w.cutoff || m.DoubleSided != w.double {
+			t.Errorf("%s: mode %v cutoff %v doubleSided %v, want %v %v %v",
+				m.MeshID, m.AlphaMode, m.AlphaCutoff, m.DoubleSided, w.mode, w.cutoff, w.double)
+		}
+	}
+	if mats[1].BaseColor[3] != 0.25 {
+		t.Errorf("glass alpha %v", mats[1].BaseColor[3])
+	}
+}
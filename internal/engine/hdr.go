@@ -0,0 +1,153 @@
+package engine
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+// HDRImage is a floating-point RGB image, stored top row first.
+type HDRImage struct {
+	Width, Height int
+	Pix           []float32 // RGB triples
+}
+
+// At returns the colour of pixel (x, y).
+func (img *HDRImage) At(x, y int) [3]float32 {
+	i := 3 * (y*img.Width + x)
+	return [3]float32{img.Pix[i], img.Pix[i+1], img.Pix[i+2]}
+}
+
+// LoadHDR reads a Radiance RGBE (.hdr) file.
+func LoadHDR(path string) (*HDRImage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, err := DecodeHDR(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return img, nil
+}
+
+// DecodeHDR decodes a Radiance RGBE image with flat or run-length encoded
+// scanlines. Only the standard "-Y h +X w" and the flipped "+Y h +X w"
+// orientations are supported.
+func DecodeHDR(r io.Reader) (*HDRImage, error) {
+	br := bufio.NewReader(r)
+
+	magic, err := br.ReadString('\n')
+	if err != nil || !strings.HasPrefix(magic, "#?") {
+		return nil, errors.New("hdr: missing #? signature")
+	}
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, errors.New("hdr: truncated header")
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		if f, ok := strings.CutPrefix(line, "FORMAT="); ok && f != "32-bit_rle_rgbe" {
+			return nil, fmt.Errorf("hdr: unsupported format %q", f)
+		}
+	}
+
+	res, err := br.ReadString('\n')
+	if err != nil {
+		return nil, errors.New("hdr: missing resolution")
+	}
+	var ySign, xSign byte
+	var w, h int
+	if _, err := fmt.Sscanf(res, "%cY %d %cX %d", &ySign, &h, &xSign, &w); err != nil || xSign != '+' || (ySign != '-' && ySign != '+') {
+		return nil, fmt.Errorf("hdr: unsupported resolution line %q", strings.TrimSpace(res))
+	}
+	if w <= 0 || h <= 0 {
+		return nil, fmt.Errorf("hdr: bad size %dx%d", w, h)
+	}
+
+	img := &HDRImage{Width: w, Height: h, Pix: make([]float32, 3*w*h)}
+	scan := make([]byte, 4*w)
+	for y := 0; y < h; y++ {
+		if err := readHDRScanline(br, scan, w); err != nil {
+			return nil, fmt.Errorf("hdr: scanline %d: %w", y, err)
+		}
+		row := y
+		if ySign == '+' {
+			row = h - 1 - y
+		}
+		dst := img.Pix[3*row*w:]
+		for x := 0; x < w; x++ {
+			rgbe := scan[4*x : 4*x+4]
+			if rgbe[3] == 0 {
+				dst[3*x], dst[3*x+1], dst[3*x+2] = 0, 0, 0
+				continue
+			}
+			f := float32(math.Ldexp(1, int(rgbe[3])-(128+8)))
+			dst[3*x] = float32(rgbe[0]) * f
+			dst[3*x+1] = float32(rgbe[1]) * f
+			dst[3*x+2] = float32(rgbe[2]) * f
+		}
+	}
+	return img, nil
+}
+
+// readHDRScanline reads one scanline of w pixels into scan as RGBE
+// quadruples. New-style RLE lines start with 2, 2 and the width and store
+// each channel separately as runs.
+func readHDRScanline(br *bufio.Reader, scan []byte, w int) error {
+	head, err := br.Peek(4)
+	if err != nil {
+		return err
+	}
+	if w < 8 || w > 0x7fff || head[0] != 2 || head[1] != 2 || head[2]&0x80 != 0 {
+		_, err := io.ReadFull(br, scan)
+		return err
+	}
+	br.Discard(4)
+	if int(head[2])<<8|int(head[3]) != w {
+		return errors.New("scanline width mismatch")
+	}
+	for c := 0; c < 4; c++ {
+		for x := 0; x < w; {
+			n, err := br.ReadByte()
+			if err != nil {
+				return err
+			}
+			if n > 128 {
+				count := int(n) - 128
+				v, err := br.ReadByte()
+				if err != nil {
+					return err
+				}
+				if x+count > w {
+					return errors.New("run past end of scanline")
+				}
+				for ; count > 0; count-- {
+					scan[4*x+c] = v
+					x++
+				}
+				continue
+			}
+			if n == 0 || x+int(n) > w {
+				return errors.New("bad literal run")
+			}
+			for i := 0; i < int(n); i++ {
+				v, err := br.ReadByte()
+				if err != nil {
+					return err
+				}
+				scan[4*x+c] = v
+				x++
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,382 @@
+package engine
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// CubeMap is a square RGB float cube map. Faces are in GL order (+X, -X,
+// +Y, -Y, +Z, -Z), each stored row by row the way glTexImage2D expects.
+type CubeMap struct {
+	Size  int
+	Faces [6][]float32
+}
+
+func NewCubeMap(size int) *CubeMap {
+	c := &CubeMap{Size: size}
+	for f := range c.Faces {
+		c.Faces[f] = make([]float32, 3*size*size)
+	}
+	return c
+}
+
+// CubeDir returns the unit direction through texel (x, y) of face.
+func (c *CubeMap) CubeDir(face, x, y int) mgl32.Vec3 {
+	s := 2*(float32(x)+0.5)/float32(c.Size) - 1
+	t := 2*(float32(y)+0.5)/float32(c.Size) - 1
+	return cubeFaceDir(face, s, t)
+}
+
+// cubeFaceDir maps face coordinates s, t in [-1, 1] to a direction, as
+// laid out in the GL spec's cube map face selection table.
+func cubeFaceDir(face int, s, t float32) mgl32.Vec3 {
+	var d mgl32.Vec3
+	switch face {
+	case 0:
+		d = mgl32.Vec3{1, -t, -s}
+	case 1:
+		d = mgl32.Vec3{-1, -t, s}
+	case 2:
+		d = mgl32.Vec3{s, 1, t}
+	case 3:
+		d = mgl32.Vec3{s, -1, -t}
+	case 4:
+		d = mgl32.Vec3{s, -t, 1}
+	default:
+		d = mgl32.Vec3{-s, -t, -1}
+	}
+	return d.Normalize()
+}
+
+// cubeFaceCoords is the inverse of cubeFaceDir.
+func cubeFaceCoords(d mgl32.Vec3) (face int, s, t float32) {
+	face = CubeFace(d)
+	ma := abs32(d[face/2])
+	switch face {
+	case 0:
+		return face, -d.Z() / ma, -d.Y() / ma
+	case 1:
+		return face, d.Z() / ma, -d.Y() / ma
+	case 2:
+		return face, d.X() / ma, d.Z() / ma
+	case 3:
+		return face, d.X() / ma, -d.Z() / ma
+	case 4:
+		return face, d.X() / ma, -d.Y() / ma
+	}
+	return face, -d.X() / ma, -d.Y() / ma
+}
+
+// Sample returns the bilinearly filtered colour in direction d. Filtering
+// clamps at face edges rather than blending across them.
+func (c *CubeMap) Sample(d mgl32.Vec3) [3]float32 {
+	face, s, t := cubeFaceCoords(d)
+	return bilinear(c.Faces[face], c.Size, c.Size, (s+1)/2*float32(c.Size)-0.5, (t+1)/2*float32(c.Size)-0.5, false)
+}
+
+// Downsample returns the next mip level of c, averaging 2x2 texels.
+func (c *CubeMap) Downsample() *CubeMap {
+	n := max(c.Size/2, 1)
+	out := NewCubeMap(n)
+	for f := range c.Faces {
+		src, dst := c.Faces[f], out.Faces[f]
+		for y := 0; y < n; y++ {
+			for x := 0; x < n; x++ {
+				for ch := 0; ch < 3; ch++ {
+					var sum float32
+					for _, o := range [4][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+						sx, sy := min(2*x+o[0], c.Size-1), min(2*y+o[1], c.Size-1)
+						sum += src[3*(sy*c.Size+sx)+ch]
+					}
+					dst[3*(y*n+x)+ch] = sum / 4
+				}
+			}
+		}
+	}
+	return out
+}
+
+// bilinear samples an RGB image at texel-centre coordinates x, y. wrapX
+// repeats horizontally (equirectangular maps); otherwise edges clamp.
+func bilinear(pix []float32, w, h int, x, y float32, wrapX bool) [3]float32 {
+	x0, y0 := int(math.Floor(float64(x))), int(math.Floor(float64(y)))
+	fx, fy := x-float32(x0), y-float32(y0)
+	col := func(x int) int {
+		if wrapX {
+			return ((x % w) + w) % w
+		}
+		return max(0, min(x, w-1))
+	}
+	row := func(y int) int { return max(0, min(y, h-1)) }
+	var out [3]float32
+	for _, tap := range [4]struct {
+		x, y int
+		w    float32
+	}{
+		{x0, y0, (1 - fx) * (1 - fy)},
+		{x0 + 1, y0, fx * (1 - fy)},
+		{x0, y0 + 1, (1 - fx) * fy},
+		{x0 + 1, y0 + 1, fx * fy},
+	} {
+		i := 3 * (row(tap.y)*w + col(tap.x))
+		out[0] += pix[i] * tap.w
+		out[1] += pix[i+1] * tap.w
+		out[2] += pix[i+2] * tap.w
+	}
+	return out
+}
+
+// EquirectDir returns the direction at equirectangular coordinates u, v in
+// [0, 1]: v = 0 is straight up (+Y), and u = 0.5 looks down -Z.
+func EquirectDir(u, v float32) mgl32.Vec3 {
+	phi := float64(u-0.5) * 2 * math.Pi
+	theta := float64(v) * math.Pi
+	st := math.Sin(theta)
+	return mgl32.Vec3{float32(st * math.Sin(phi)), float32(math.Cos(theta)), -float32(st * math.Cos(phi))}
+}
+
+// SampleEquirect returns the bilinearly filtered colour of an
+// equirectangular image in direction d.
+func SampleEquirect(img *HDRImage, d mgl32.Vec3) [3]float32 {
+	d = d.Normalize()
+	u := 0.5 + float32(math.Atan2(float64(d.X()), float64(-d.Z())))/(2*math.Pi)
+	v := float32(math.Acos(float64(max(-1, min(d.Y(), 1))))) / math.Pi
+	return bilinear(img.Pix, img.Width, img.Height, u*float32(img.Width)-0.5, v*float32(img.Height)-0.5, true)
+}
+
+// EquirectToCube resamples an equirectangular image onto a cube map.
+func EquirectToCube(img *HDRImage, size int) *CubeMap {
+	c := NewCubeMap(size)
+	c.fill(func(d mgl32.Vec3) [3]float32 { return SampleEquirect(img, d) })
+	return c
+}
+
+func (c *CubeMap) fill(f func(d mgl32.Vec3) [3]float32) {
+	for face := range c.Faces {
+		for y := 0; y < c.Size; y++ {
+			for x := 0; x < c.Size; x++ {
+				v := f(c.CubeDir(face, x, y))
+				copy(c.Faces[face][3*(y*c.Size+x):], v[:])
+			}
+		}
+	}
+}
+
+// SH9 holds the first three bands of a spherical harmonic projection of
+// RGB radiance, in the order (0,0), (1,-1), (1,0), (1,1), (2,-2) ... (2,2).
+type SH9 [9][3]float32
+
+// shBasis evaluates the nine real SH basis functions at unit direction d.
+func shBasis(d mgl32.Vec3) [9]float32 {
+	x, y, z := d.X(), d.Y(), d.Z()
+	return [9]float32{
+		0.282095,
+		0.488603 * y,
+		0.488603 * z,
+		0.488603 * x,
+		1.092548 * x * y,
+		1.092548 * y * z,
+		0.315392 * (3*z*z - 1),
+		1.092548 * x * z,
+		0.546274 * (x*x - y*y),
+	}
+}
+
+// ProjectSH9 projects an equirectangular environment onto SH9, weighting
+// each pixel by the solid angle it covers.
+func ProjectSH9(img *HDRImage) SH9 {
+	var sh SH9
+	dPhi := 2 * math.Pi / float64(img.Width)
+	dTheta := math.Pi / float64(img.Height)
+	for y := 0; y < img.Height; y++ {
+		v := (float32(y) + 0.5) / float32(img.Height)
+		w := float32(dPhi * dTheta * math.Sin(float64(v)*math.Pi))
+		for x := 0; x < img.Width; x++ {
+			u := (float32(x) + 0.5) / float32(img.Width)
+			b := shBasis(EquirectDir(u, v))
+			i := 3 * (y*img.Width + x)
+			for k := range sh {
+				for ch := 0; ch < 3; ch++ {
+					sh[k][ch] += img.Pix[i+ch] * b[k] * w
+				}
+			}
+		}
+	}
+	return sh
+}
+
+// shBandScale is the clamped-cosine convolution per band divided by pi
+// (Ramamoorthi and Hanrahan), so a constant environment of radiance L has
+// irradiance L: the shaders multiply it by albedo without a 1/pi.
+var shBandScale = [9]float32{1, 2.0 / 3, 2.0 / 3, 2.0 / 3, 0.25, 0.25, 0.25, 0.25, 0.25}
+
+// Irradiance returns the diffuse lighting for surface normal n, as stored
+// in the irradiance map.
+func (sh *SH9) Irradiance(n mgl32.Vec3) [3]float32 {
+	b := shBasis(n.Normalize())
+	var out [3]float32
+	for k := range sh {
+		for ch := 0; ch < 3; ch++ {
+			out[ch] += shBandScale[k] * sh[k][ch] * b[k]
+		}
+	}
+	for ch := range out {
+		out[ch] = max(out[ch], 0) // ringing can dip below zero
+	}
+	return out
+}
+
+// IrradianceCube bakes sh into a cube map of size texels per side.
+func IrradianceCube(sh *SH9, size int) *CubeMap {
+	c := NewCubeMap(size)
+	c.fill(sh.Irradiance)
+	return c
+}
+
+// hammersley returns point i of an n-point Hammersley set.
+func hammersley(i, n int) (float32, float32) {
+	b := uint32(i)
+	b = (b << 16) | (b >> 16)
+	b = ((b & 0x55555555) << 1) | ((b & 0xAAAAAAAA) >> 1)
+	b = ((b & 0x33333333) << 2) | ((b & 0xCCCCCCCC) >> 2)
+	b = ((b & 0x0F0F0F0F) << 4) | ((b & 0xF0F0F0F0) >> 4)
+	b = ((b & 0x00FF00FF) << 8) | ((b & 0xFF00FF00) >> 8)
+	return float32(i) / float32(n), float32(b) * 2.3283064365386963e-10
+}
+
+// importanceSampleGGX returns a half vector around n distributed by the
+// GGX normal distribution with alpha = roughness^2.
+func importanceSampleGGX(u1, u2, roughness float32, n mgl32.Vec3) mgl32.Vec3 {
+	a := roughness * roughness
+	phi := 2 * math.Pi * float64(u1)
+	cosTheta := math.Sqrt(float64((1 - u2) / (1 + (a*a-1)*u2)))
+	sinTheta := math.Sqrt(1 - cosTheta*cosTheta)
+	h := mgl32.Vec3{float32(sinTheta * math.Cos(phi)), float32(sinTheta * math.Sin(phi)), float32(cosTheta)}
+
+	up := mgl32.Vec3{0, 0, 1}
+	if abs32(n.Z()) > 0.999 {
+		up = mgl32.Vec3{1, 0, 0}
+	}
+	tx := up.Cross(n).Normalize()
+	ty := n.Cross(tx)
+	return tx.Mul(h.X()).Add(ty.Mul(h.Y())).Add(n.Mul(h.Z())).Normalize()
+}
+
+func distributionGGX(nDotH, roughness float32) float32 {
+	a2 := roughness * roughness * roughness * roughness
+	d := nDotH*nDotH*(a2-1) + 1
+	return a2 / (math.Pi * d * d)
+}
+
+// PrefilterGGX convolves src with the GGX lobe for levels roughness values
+// 0, 1/(levels-1) ... 1, returning one cube per level at size, size/2 ...
+// texels. It assumes the view direction equals the normal and the
+// reflection vector (the split-sum approximation); samples are GGX
+// importance samples, each read from the mip of src whose texels match the
+// sample's solid angle so few samples stay free of fireflies.
+func PrefilterGGX(src *CubeMap, size, levels, samples int) []*CubeMap {
+	mips := []*CubeMap{src}
+	for mips[len(mips)-1].Size > 1 {
+		mips = append(mips, mips[len(mips)-1].Downsample())
+	}
+	texelSolidAngle := 4 * math.Pi / (6 * float64(src.Size*src.Size))
+
+	out := make([]*CubeMap, levels)
+	for level := range out {
+		c := NewCubeMap(max(size>>level, 1))
+		roughness := float32(level) / float32(max(levels-1, 1))
+		if level == 0 {
+			// A mirror reflects the environment unfiltered.
+			c.fill(func(d mgl32.Vec3) [3]float32 { return sampleMips(mips, d, 0) })
+			out[level] = c
+			continue
+		}
+		c.fill(func(n mgl32.Vec3) [3]float32 {
+			var sum [3]float32
+			var weight float32
+			for i := 0; i < samples; i++ {
+				u1, u2 := hammersley(i, samples)
+				h := importanceSampleGGX(u1, u2, roughness, n)
+				l := h.Mul(2 * n.Dot(h)).Sub(n)
+				nDotL := n.Dot(l)
+				if nDotL <= 0 {
+					continue
+				}
+				// With v = n the pdf of l is D(h) / 4.
+				pdf := distributionGGX(max(n.Dot(h), 0), roughness) / 4
+				sampleSolidAngle := 1 / (float64(samples)*float64(pdf) + 1e-4)
+				lod := 0.5*math.Log2(sampleSolidAngle/texelSolidAngle) + 1
+				col := sampleMips(mips, l, float32(lod))
+				for ch := range sum {
+					sum[ch] += col[ch] * nDotL
+				}
+				weight += nDotL
+			}
+			for ch := range sum {
+				sum[ch] /= max(weight, 1e-6)
+			}
+			return sum
+		})
+		out[level] = c
+	}
+	return out
+}
+
+// sampleMips samples a mip chain trilinearly at lod.
+func sampleMips(mips []*CubeMap, d mgl32.Vec3, lod float32) [3]float32 {
+	lod = max(0, min(lod, float32(len(mips)-1)))
+	i := int(lod)
+	a := mips[i].Sample(d)
+	if i+1 >= len(mips) {
+		return a
+	}
+	b := mips[i+1].Sample(d)
+	f := lod - float32(i)
+	return [3]float32{a[0] + (b[0]-a[0])*f, a[1] + (b[1]-a[1])*f, a[2] + (b[2]-a[2])*f}
+}
+
+// geometrySmithIBL is the Smith-Schlick geometry term with the IBL
+// remapping k = alpha / 2.
+func geometrySmithIBL(nDotV, nDotL, roughness float32) float32 {
+	k := roughness * roughness / 2
+	g1 := func(x float32) float32 { return x / (x*(1-k) + k) }
+	return g1(nDotV) * g1(nDotL)
+}
+
+// IntegrateBRDF returns the split-sum scale and bias applied to F0 for a
+// view angle and roughness: specular = prefiltered * (F0*A + B).
+func IntegrateBRDF(nDotV, roughness float32, samples int) (a, b float32) {
+	v := mgl32.Vec3{float32(math.Sqrt(float64(1 - nDotV*nDotV))), 0, nDotV}
+	n := mgl32.Vec3{0, 0, 1}
+	for i := 0; i < samples; i++ {
+		u1, u2 := hammersley(i, samples)
+		h := importanceSampleGGX(u1, u2, roughness, n)
+		l := h.Mul(2 * v.Dot(h)).Sub(v)
+		nDotL, nDotH, vDotH := max(l.Z(), 0), max(h.Z(), 0), max(v.Dot(h), 0)
+		if nDotL <= 0 {
+			continue
+		}
+		vis := geometrySmithIBL(nDotV, nDotL, roughness) * vDotH / (nDotH * nDotV)
+		fc := float32(math.Pow(float64(1-vDotH), 5))
+		a += (1 - fc) * vis
+		b += fc * vis
+	}
+	return a / float32(samples), b / float32(samples)
+}
+
+// BRDFLUT tabulates IntegrateBRDF as size x size RG pairs: x runs over
+// N.V and rows over roughness, matching the shaders' lookup
+// texture(brdfLUT, vec2(NdotV, roughness)).
+func BRDFLUT(size, samples int) []float32 {
+	lut := make([]float32, 2*size*size)
+	for y := 0; y < size; y++ {
+		roughness := (float32(y) + 0.5) / float32(size)
+		for x := 0; x < size; x++ {
+			nDotV := (float32(x) + 0.5) / float32(size)
+			a, b := IntegrateBRDF(nDotV, roughness, samples)
+			lut[2*(y*size+x)], lut[2*(y*size+x)+1] = a, b
+		}
+	}
+	return lut
+}
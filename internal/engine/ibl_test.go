@@ -0,0 +1,210 @@
+package engine
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// equirect fills a w x h environment from a function of direction.
+func equirect(w, h int, f func(d mgl32.Vec3) [3]float32) *HDRImage {
+	img := &HDRImage{Width: w, Height: h, Pix: make([]float32, 3*w*h)}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := f(EquirectDir((float32(x)+0.5)/float32(w), (float32(y)+0.5)/float32(h)))
+			copy(img.Pix[3*(y*w+x):], c[:])
+		}
+	}
+	return img
+}
+
+func closeRGB(a, b [3]float32, eps float32) bool {
+	for i := range a {
+		if abs32(a[i]-b[i]) > eps {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDecodeHDR(t *testing.T) {
+	// 8 pixels wide so the second scanline may use new-style RLE.
+	var buf bytes.Buffer
+	buf.WriteString("#?RADIANCE\nFORMAT=32-bit_rle_rgbe\nEXPOSURE=1.0\n\n-Y 2 +X 8\n")
+	// Row 0, flat: 1.0 (128 at exponent 129) then 0.5 (128 at 128), repeated.
+	for x := 0; x < 8; x++ {
+		if x%2 == 0 {
+			buf.Write([]byte{128, 64, 0, 129})
+		} else {
+			buf.Write([]byte{128, 128, 128, 128})
+		}
+	}
+	// Row 1, RLE: R, G and B runs of 8, E as 8 literals.
+	buf.Write([]byte{2, 2, 0, 8})
+	buf.Write([]byte{128 + 8, 64})
+	buf.Write([]byte{128 + 8, 32})
+	buf.Write([]byte{128 + 8, 0})
+	buf.Write([]byte{8, 129, 129, 129, 129, 130, 130, 130, 0})
+
+	img, err := DecodeHDR(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Width != 8 || img.Height != 2 {
+		t.Fatalf("size %dx%d", img.Width, img.Height)
+	}
+	for _, c := range []struct {
+		x, y int
+		want [3]float32
+	}{
+		{0, 0, [3]float32{1, 0.5, 0}},
+		{1, 0, [3]float32{0.5, 0.5, 0.5}},
+		{0, 1, [3]float32{0.5, 0.25, 0}},
+		{4, 1, [3]float32{1, 0.5, 0}},
+		{7, 1, [3]float32{0, 0, 0}}, // zero exponent is black
+	} {
+		if got := img.At(c.x, c.y); got != c.want {
+			t.Errorf("pixel (%d,%d) = %v, want %v", c.x, c.y, got, c.want)
+		}
+	}
+
+	if _, err := DecodeHDR(bytes.NewBufferString("P6\n")); err == nil {
+		t.Error("decoded a file without the #? signature")
+	}
+	if _, err := DecodeHDR(bytes.NewBufferString("#?RADIANCE\n\n-Y 2 +X 8\n\x02\x02\x00\x08\x90\x01")); err == nil {
+		t.Error("decoded a truncated scanline")
+	}
+}
+
+func TestCubeMapDirections(t *testing.T) {
+	c := NewCubeMap(8)
+	for face := 0; face < 6; face++ {
+		for _, xy := range [][2]int{{0, 0}, {3, 5}, {7, 7}} {
+			d := c.CubeDir(face, xy[0], xy[1])
+			f, s, tt := cubeFaceCoords(d)
+			if f != face {
+				t.Fatalf("texel %v of face %d points at face %d", xy, face, f)
+			}
+			x, y := (s+1)/2*8-0.5, (tt+1)/2*8-0.5
+			if abs32(x-float32(xy[0])) > 1e-4 || abs32(y-float32(xy[1])) > 1e-4 {
+				t.Fatalf("face %d texel %v round-trips to (%v, %v)", face, xy, x, y)
+			}
+		}
+	}
+	// The face centres point along the GL axes.
+	for face, want := range []mgl32.Vec3{{1, 0, 0}, {-1, 0, 0}, {0, 1, 0}, {0, -1, 0}, {0, 0, 1}, {0, 0, -1}} {
+		if d := cubeFaceDir(face, 0, 0); d.Sub(want).Len() > 1e-6 {
+			t.Errorf("face %d centre %v", face, d)
+		}
+	}
+}
+
+func TestEquirectToCube(t *testing.T) {
+	// Colour encodes direction, so resampling must reproduce it.
+	img := equirect(256, 128, func(d mgl32.Vec3) [3]float32 { return [3]float32{d.X() + 1, d.Y() + 1, d.Z() + 1} })
+	c := EquirectToCube(img, 16)
+	for face := 0; face < 6; face++ {
+		d := c.CubeDir(face, 5, 9)
+		got := c.Sample(d)
+		if !closeRGB(got, [3]float32{d.X() + 1, d.Y() + 1, d.Z() + 1}, 0.03) {
+			t.Fatalf("face %d direction %v sampled %v", face, d, got)
+		}
+	}
+}
+
+func TestIrradianceSH9(t *testing.T) {
+	constant := equirect(64, 32, func(mgl32.Vec3) [3]float32 { return [3]float32{2, 1, 0.5} })
+	sh := ProjectSH9(constant)
+	rng := rand.New(rand.NewSource(3))
+	for i := 0; i < 50; i++ {
+		n := mgl32.Vec3{rng.Float32()*2 - 1, rng.Float32()*2 - 1, rng.Float32()*2 - 1}
+		if got := sh.Irradiance(n); !closeRGB(got, [3]float32{2, 1, 0.5}, 0.01) {
+			t.Fatalf("constant environment: irradiance %v towards %v", got, n)
+		}
+	}
+
+	// L = 1 + y convolves to 1 + 2/3 n.y exactly (bands 0 and 1 only).
+	gradient := equirect(128, 64, func(d mgl32.Vec3) [3]float32 { return [3]float32{1 + d.Y(), 1 + d.Y(), 1 + d.Y()} })
+	sh = ProjectSH9(gradient)
+	for i := 0; i < 50; i++ {
+		n := mgl32.Vec3{rng.Float32()*2 - 1, rng.Float32()*2 - 1, rng.Float32()*2 - 1}.Normalize()
+		want := 1 + 2.0/3*n.Y()
+		if got := sh.Irradiance(n); !closeRGB(got, [3]float32{want, want, want}, 0.01) {
+			t.Fatalf("gradient environment: irradiance %v towards %v, want %v", got, n, want)
+		}
+	}
+
+	// The baked cube stores the same values.
+	c := IrradianceCube(&sh, 4)
+	d := c.CubeDir(2, 1, 2)
+	if want := sh.Irradiance(d); !closeRGB(c.Sample(d), want, 1e-4) {
+		t.Errorf("irradiance cube %v, want %v", c.Sample(d), want)
+	}
+}
+
+func TestPrefilterGGX(t *testing.T) {
+	src := NewCubeMap(16)
+	src.fill(func(mgl32.Vec3) [3]float32 { return [3]float32{3, 2, 1} })
+	levels := PrefilterGGX(src, 8, PrefilterLevels, 32)
+	if len(levels) != PrefilterLevels {
+		t.Fatalf("%d levels", len(levels))
+	}
+	for i, c := range levels {
+		if want := max(8>>i, 1); c.Size != want {
+			t.Fatalf("level %d is %d texels, want %d", i, c.Size, want)
+		}
+		for f := range c.Faces {
+			for j := 0; j < len(c.Faces[f]); j += 3 {
+				if got := [3]float32{c.Faces[f][j], c.Faces[f][j+1], c.Faces[f][j+2]}; !closeRGB(got, [3]float32{3, 2, 1}, 1e-3) {
+					t.Fatalf("level %d face %d: %v in a constant environment", i, f, got)
+				}
+			}
+		}
+	}
+
+	// A single bright direction spreads out as roughness grows.
+	src = NewCubeMap(16)
+	src.fill(func(d mgl32.Vec3) [3]float32 {
+		if d.Y() > 0.97 {
+			return [3]float32{1, 1, 1}
+		}
+		return [3]float32{}
+	})
+	levels = PrefilterGGX(src, 16, PrefilterLevels, 64)
+	up, side := mgl32.Vec3{0, 1, 0}, mgl32.Vec3{0.5, 0.5, 0}.Normalize()
+	if levels[0].Sample(up)[0] < 0.99 || levels[0].Sample(side)[0] != 0 {
+		t.Errorf("mirror level blurred: up %v, side %v", levels[0].Sample(up), levels[0].Sample(side))
+	}
+	if s := levels[PrefilterLevels-1].Sample(side)[0]; s <= 0 {
+		t.Errorf("roughest level has no light at 45 degrees: %v", s)
+	}
+	if a, b := levels[1].Sample(up)[0], levels[PrefilterLevels-1].Sample(up)[0]; a <= b {
+		t.Errorf("peak did not fall with roughness: %v then %v", a, b)
+	}
+}
+
+func TestBRDFLUT(t *testing.T) {
+	// A smooth surface seen head-on reflects F0: scale 1, bias 0.
+	if a, b := IntegrateBRDF(0.999, 0.02, 256); math.Abs(float64(a-1)) > 0.02 || b > 0.02 {
+		t.Errorf("smooth head-on: (%v, %v), want (1, 0)", a, b)
+	}
+	const size = 16
+	lut := BRDFLUT(size, 64)
+	if len(lut) != 2*size*size {
+		t.Fatalf("LUT has %d values", len(lut))
+	}
+	for i := 0; i < len(lut); i += 2 {
+		a, b := lut[i], lut[i+1]
+		if a < 0 || b < 0 || a+b > 1.001 {
+			t.Fatalf("texel %d: (%v, %v) out of range", i/2, a, b)
+		}
+	}
+	// Seen head-on, rough surfaces reflect less than smooth ones.
+	at := func(x, y int) float32 { return lut[2*(y*size+x)] + lut[2*(y*size+x)+1] }
+	if at(size-1, 0) <= at(size-1, size-1) {
+		t.Errorf("head-on reflectance not falling with roughness: %v vs %v", at(size-1, 0), at(size-1, size-1))
+	}
+}
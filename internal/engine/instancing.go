@@ -0,0 +1,85 @@
+package engine
+
+import "unsafe"
+
+// Instance is the per-instance data of an instanced draw. Shaders read
+// Model at attribute locations InstanceModelAttrib to +3 (one column
+// each) and Color at InstanceColorAttrib, and use them in place of the
+// model uniform and material base colour when useInstancing is set.
+type Instance struct {
+	Model [16]float32
+	Color [4]float32
+}
+
+const (
+	InstanceModelAttrib = 7
+	InstanceColorAttrib = 11
+)
+
+const instanceStride = int(unsafe.Sizeof(Instance{}))
+
+// InstanceBuffer is a growable vertex buffer of Instances. Every draw
+// uploads its instances to the start of the buffer, so a vertex array
+// attached once (see MeshManager.AttachInstances) reads the current batch.
+type InstanceBuffer struct {
+	buf      uint32
+	capacity int // in instances
+}
+
+// NewInstanceBuffer allocates room for capacity instances; Upload grows
+// it as needed.
+func NewInstanceBuffer(capacity int) *InstanceBuffer {
+	capacity = max(capacity, 1)
+	return &InstanceBuffer{
+		buf:      Device.CreateBuffer(ArrayBuffer, capacity*instanceStride, nil, DynamicDraw),
+		capacity: capacity,
+	}
+}
+
+// Buffer is the device buffer behind b.
+func (b *InstanceBuffer) Buffer() uint32 { return b.buf }
+
+// Upload replaces the contents of b with instances, at least doubling the
+// buffer when they do not fit.
+func (b *InstanceBuffer) Upload(instances []Instance) {
+	if len(instances) == 0 {
+		return
+	}
+	if len(instances) > b.capacity {
+		b.capacity = max(len(instances), 2*b.capacity)
+		Device.ResizeBuffer(ArrayBuffer, b.buf, b.capacity*instanceStride, nil, DynamicDraw)
+	}
+	Device.UpdateBuffer(ArrayBuffer, b.buf, 0, len(instances)*instanceStride, unsafe.Pointer(&instances[0]))
+}
+
+func (b *InstanceBuffer) Delete() {
+	if b.buf != 0 {
+		Device.DeleteBuffer(b.buf)
+		b.buf = 0
+	}
+}
+
+// AttachInstances wires the instance attributes of b into the vertex
+// array of mesh id, once per mesh and buffer. Plain draws of the mesh are
+// unaffected: their shaders ignore the attributes. It reports false for
+// unknown meshes.
+func (mm *MeshManager) AttachInstances(id string, b *InstanceBuffer) bool {
+	vao := mm.vaos[id]
+	if vao == 0 {
+		return false
+	}
+	if mm.instanceBufs[id] == b.buf {
+		return true
+	}
+	Device.BindVertexArray(vao)
+	Device.BindBuffer(ArrayBuffer, b.buf)
+	for col := uint32(0); col < 4; col++ {
+		Device.VertexAttrib(InstanceModelAttrib+col, 4, Float, false, instanceStride, int(col)*16)
+		Device.VertexAttribDivisor(InstanceModelAttrib+col, 1)
+	}
+	Device.VertexAttrib(InstanceColorAttrib, 4, Float, false, instanceStride, 64)
+	Device.VertexAttribDivisor(InstanceColorAttrib, 1)
+	Device.BindVertexArray(0)
+	mm.instanceBufs[id] = b.buf
+	return true
+}
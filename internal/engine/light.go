@@ -1,5 +1,12 @@
 package engine
 
+// Light types as stored in LightData.Type and read by the shaders.
+const (
+	LightTypeDirectional int32 = iota
+	LightTypePoint
+	LightTypeSpot
+)
+
 type LightData struct {
 	Type      int32
 	Color     [3]float32
@@ -8,4 +15,102 @@ type LightData struct {
 	Position  [3]float32
 	Range     float32
 	Angle     float32
+	// Shadow is one past the index of the light's first ShadowView, so
+	// the zero value means no shadow. Directional lights own one view per
+	// cascade, point lights six cube faces and spot lights one.
+	Shadow int32
+}
+
+// lightTexels is the number of RGBA32F texels PackLights writes per light.
+const lightTexels = 4
+
+// PackLights lays lights out as the shaders' lightBuffer expects, four
+// texels per light:
+//
+//	position.xyz, type
+//	direction.xyz, range
+//	color.rgb, intensity
+//	angle, first shadow view (-1 for none), 0, 0
+func PackLights(dst []float32, lights []LightData) []float32 {
+	dst = dst[:0]
+	for _, l := range lights {
+		dst = append(dst,
+			l.Position[0], l.Position[1], l.Position[2], float32(l.Type),
+			l.Direction[0], l.Direction[1], l.Direction[2], l.Range,
+			l.Color[0], l.Color[1], l.Color[2], l.Intensity,
+			l.Angle, float32(l.Shadow-1), 0, 0,
+		)
+	}
+	return dst
+}
+
+// LightBuffers holds the buffer textures the lit shaders read: the packed
+// lights, the per-cluster (offset, count) grid, the cluster light lists and
+// the shadow views.
+type LightBuffers struct {
+	lightBuf, gridBuf, indexBuf, shadowBuf uint32
+	lightTex, gridTex, indexTex, shadowTex uint32
+
+	packed, packedShadows []float32
+}
+
+// Upload replaces the contents of all four buffers, creating them on first
+// use. Buffer textures may not be empty, so each buffer holds at least one
+// element.
+func (b *LightBuffers) Upload(lights []LightData, clusters *LightClusters, shadows []ShadowView, atlasSize int) {
+	if b.lightBuf == 0 {
+		b.lightBuf = Device.CreateBuffer(TexelBuffer, 16, nil, DynamicDraw)
+		b.gridBuf = Device.CreateBuffer(TexelBuffer, 8, nil, DynamicDraw)
+		b.indexBuf = Device.CreateBuffer(TexelBuffer, 4, nil, DynamicDraw)
+		b.shadowBuf = Device.CreateBuffer(TexelBuffer, 16, nil, DynamicDraw)
+		b.lightTex = Device.CreateBufferTexture(b.lightBuf, RGBA32F)
+		b.gridTex = Device.CreateBufferTexture(b.gridBuf, RG32UI)
+		b.indexTex = Device.CreateBufferTexture(b.indexBuf, R32UI)
+		b.shadowTex = Device.CreateBufferTexture(b.shadowBuf, RGBA32F)
+	}
+
+	b.packed = PackLights(b.packed, lights)
+	if len(b.packed) == 0 {
+		b.packed = append(b.packed, make([]float32, 4*lightTexels)...)
+	}
+	Device.ResizeBuffer(TexelBuffer, b.lightBuf, 4*len(b.packed), b.packed, DynamicDraw)
+
+	cells, indices := clusters.Cells, clusters.Indices
+	if len(cells) == 0 {
+		cells = []uint32{0, 0}
+	}
+	if len(indices) == 0 {
+		indices = []uint32{0}
+	}
+	Device.ResizeBuffer(TexelBuffer, b.gridBuf, 4*len(cells), cells, DynamicDraw)
+	Device.ResizeBuffer(TexelBuffer, b.indexBuf, 4*len(indices), indices, DynamicDraw)
+
+	b.packedShadows = PackShadowViews(b.packedShadows, shadows, atlasSize)
+	if len(b.packedShadows) == 0 {
+		b.packedShadows = append(b.packedShadows, make([]float32, 4*shadowViewTexels)...)
+	}
+	Device.ResizeBuffer(TexelBuffer, b.shadowBuf, 4*len(b.packedShadows), b.packedShadows, DynamicDraw)
+}
+
+// Bind binds the light, grid, index and shadow view textures to units
+// first through first+3.
+func (b *LightBuffers) Bind(first uint32) {
+	Device.BindTexture(first, TextureBuffer, b.lightTex)
+	Device.BindTexture(first+1, TextureBuffer, b.gridTex)
+	Device.BindTexture(first+2, TextureBuffer, b.indexTex)
+	Device.BindTexture(first+3, TextureBuffer, b.shadowTex)
+}
+
+func (b *LightBuffers) Delete() {
+	for _, t := range []uint32{b.lightTex, b.gridTex, b.indexTex, b.shadowTex} {
+		if t != 0 {
+			Device.DeleteTexture(t)
+		}
+	}
+	for _, buf := range []uint32{b.lightBuf, b.gridBuf, b.indexBuf, b.shadowBuf} {
+		if buf != 0 {
+			Device.DeleteBuffer(buf)
+		}
+	}
+	*b = LightBuffers{}
 }
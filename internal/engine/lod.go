@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LOD meshes are registered like any other mesh, under the ID of the mesh
+// they simplify with a "#lod<level>" suffix (level 1 is the first, most
+// detailed one). MeshManager indexes them by base ID so renderers can ask
+// for a level without knowing which were generated.
+const lodSuffix = "#lod"
+
+// LODMeshID names level (1-based) of id's LOD chain.
+func LODMeshID(id string, level int) string { return fmt.Sprintf("%s%s%d", id, lodSuffix, level) }
+
+// ParseLODMeshID splits a LODMeshID into the base mesh ID and level.
+func ParseLODMeshID(id string) (base string, level int, ok bool) {
+	i := strings.LastIndex(id, lodSuffix)
+	if i <= 0 {
+		return "", 0, false
+	}
+	level, err := strconv.Atoi(id[i+len(lodSuffix):])
+	if err != nil || level < 1 {
+		return "", 0, false
+	}
+	return id[:i], level, true
+}
+
+// registerLOD records id under its base mesh if it is a LOD mesh.
+func (mm *MeshManager) registerLOD(id string) {
+	base, level, ok := ParseLODMeshID(id)
+	if !ok {
+		return
+	}
+	levels := mm.lods[base]
+	for len(levels) < level {
+		levels = append(levels, "")
+	}
+	levels[level-1] = id
+	mm.lods[base] = levels
+}
+
+// releaseLODs drops id's LOD meshes along with it, or id from its base
+// mesh's chain if it is a LOD itself.
+func (mm *MeshManager) releaseLODs(id string) {
+	if base, level, ok := ParseLODMeshID(id); ok {
+		if levels := mm.lods[base]; level <= len(levels) {
+			levels[level-1] = ""
+		}
+		return
+	}
+	levels := mm.lods[id]
+	delete(mm.lods, id)
+	for _, l := range levels {
+		if l != "" {
+			mm.ReleaseMesh(l)
+		}
+	}
+}
+
+// LODCount is how many LOD levels were registered for id.
+func (mm *MeshManager) LODCount(id string) int { return len(mm.lods[id]) }
+
+// LOD returns the mesh to draw for id at level (0 is id itself): the
+// requested level or, if it was not generated, the closest finer one.
+func (mm *MeshManager) LOD(id string, level int) string {
+	levels := mm.lods[id]
+	for l := min(level, len(levels)); l > 0; l-- {
+		if levels[l-1] != "" {
+			return levels[l-1]
+		}
+	}
+	return id
+}
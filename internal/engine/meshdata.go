@@ -0,0 +1,59 @@
+package engine
+
+// MeshData is a decoded mesh ready for upload: 12-float interleaved vertices
+// (pos, normal, uv, tangent) plus the optional per-vertex streams that
+// uploadMeshToGL binds at locations 4-6.
+type MeshData struct {
+	ID       string
+	Vertices []float32
+	Indices  []uint32
+	Joints   [][4]uint16
+	Weights  [][4]float32
+	Colors   [][4]float32
+
+	// HasTangents is false when the source had no tangents and the vertex
+	// tangent slots hold the (1,0,0,1) placeholder.
+	HasTangents bool
+}
+
+// ApplyImportSettings bakes a uniform scale into the positions of static
+// meshes (skinned meshes keep theirs to match the inverse bind matrices) and,
+// if genTangents is set, computes tangents the source did not provide.
+func (md *MeshData) ApplyImportSettings(scale float32, genTangents bool) {
+	if scale != 0 && scale != 1 && len(md.Joints) == 0 {
+		for i := 0; i+2 < len(md.Vertices); i += 12 {
+			md.Vertices[i] *= scale
+			md.Vertices[i+1] *= scale
+			md.Vertices[i+2] *= scale
+		}
+	}
+	if genTangents && !md.HasTangents {
+		computeTangents(md.Vertices, md.Indices)
+		md.HasTangents = true
+	}
+}
+
+// RegisterMeshData uploads md under md.ID. Skinned meshes also keep their
+// bind pose on the CPU for ecs.SkinMeshCPU.
+func (mm *MeshManager) RegisterMeshData(md MeshData) {
+	if len(md.Colors) > 0 {
+		mm.ColorData[md.ID] = md.Colors
+	}
+	if len(md.Joints) > 0 {
+		mm.JointData[md.ID] = md.Joints
+		mm.WeightData[md.ID] = md.Weights
+
+		count := len(md.Vertices) / 12
+		positions := make([][3]float32, count)
+		normals := make([][3]float32, count)
+		for i := 0; i < count; i++ {
+			v := md.Vertices[i*12:]
+			positions[i] = [3]float32{v[0], v[1], v[2]}
+			normals[i] = [3]float32{v[3], v[4], v[5]}
+		}
+		mm.PositionData[md.ID] = positions
+		mm.NormalData[md.ID] = normals
+	}
+	uploadMeshToGL(mm, md.ID, md.Vertices, md.Indices)
+	mm.registerLOD(md.ID)
+}
@@ -4,8 +4,6 @@ package engine
 import (
 	"log"
 	"math"
-
-	"github.com/go-gl/gl/v4.1-core/gl"
 )
 
 var GlobalMeshManager *MeshManager
@@ -21,11 +19,57 @@ type MeshManager struct {
 	ebos map[string]uint32
 
 	// new bookkeeping
-	indexTypes   map[string]uint32 // gl.UNSIGNED_INT or gl.UNSIGNED_SHORT
-	vertexCounts map[string]int32  // number of vertices (for DrawArrays fallback if needed)
-	layoutType   map[string]int    // 8 or 12
+	indexTypes   map[string]IndexType
+	vertexCounts map[string]int32 // number of vertices (for DrawArrays fallback if needed)
+	layoutType   map[string]int   // 8 or 12
 	JointData    map[string][][4]uint16
 	WeightData   map[string][][4]float32
+
+	// Bind-pose positions/normals, kept for skinned meshes only (CPU skinning).
+	PositionData map[string][][3]float32
+	NormalData   map[string][][3]float32
+
+	// Per-vertex colours (RGBA), uploaded at VertexColorAttrib.
+	ColorData map[string][][4]float32
+
+	// CPU copies of 12-float meshes (see CPUMesh), used by exporters.
+	cpuMeshes map[string]CPUMesh
+
+	// Local bounds of every triangle mesh, for culling.
+	bounds map[string]MeshBounds
+
+	// Instance buffer wired into each mesh's VAO (see AttachInstances).
+	instanceBufs map[string]uint32
+
+	// LOD mesh IDs per base mesh, level 1 first (see LODMeshID).
+	lods map[string][]string
+}
+
+// CPUMesh is the interleaved vertex data of a mesh as uploaded:
+// pos(3), normal(3), uv(2), tangent(4) per vertex.
+type CPUMesh struct {
+	Vertices []float32
+	Indices  []uint32
+}
+
+func (mm *MeshManager) keepCPUMesh(id string, vertices []float32, indices []uint32) {
+	mm.cpuMeshes[id] = CPUMesh{Vertices: vertices, Indices: indices}
+}
+
+// CPUMesh returns the CPU-side data of a mesh registered with the 12-float
+// layout (glTF, OBJ and the built-in primitives).
+func (mm *MeshManager) CPUMesh(id string) (CPUMesh, bool) {
+	m, ok := mm.cpuMeshes[id]
+	return m, ok
+}
+
+// SkinWeights returns the per-vertex joints and weights of a skinned mesh.
+func (mm *MeshManager) SkinWeights(id string) ([][4]uint16, [][4]float32, bool) {
+	js, ok := mm.JointData[id]
+	if !ok || len(js) == 0 {
+		return nil, nil, false
+	}
+	return js, mm.WeightData[id], true
 }
 
 func NewMeshManager() *MeshManager {
@@ -34,14 +78,33 @@ func NewMeshManager() *MeshManager {
 		counts:       make(map[string]int32),
 		vbos:         make(map[string]uint32),
 		ebos:         make(map[string]uint32),
-		indexTypes:   make(map[string]uint32),
+		indexTypes:   make(map[string]IndexType),
 		vertexCounts: make(map[string]int32),
 		layoutType:   make(map[string]int),
 		JointData:    make(map[string][][4]uint16),
 		WeightData:   make(map[string][][4]float32),
+		PositionData: make(map[string][][3]float32),
+		NormalData:   make(map[string][][3]float32),
+		ColorData:    make(map[string][][4]float32),
+		cpuMeshes:    make(map[string]CPUMesh),
+		bounds:       make(map[string]MeshBounds),
+		instanceBufs: make(map[string]uint32),
+		lods:         make(map[string][]string),
 	}
 }
 
+// Bounds returns the local-space bounds of a mesh. Debug and gizmo meshes
+// have none and are never culled.
+func (mm *MeshManager) Bounds(id string) (MeshBounds, bool) {
+	b, ok := mm.bounds[id]
+	return b, ok
+}
+
+// HasVertexColors reports whether a mesh was uploaded with vertex colours.
+func (mm *MeshManager) HasVertexColors(id string) bool {
+	return len(mm.ColorData[id]) > 0
+}
+
 func (mm *MeshManager) HasTangents(id string) bool {
 	return mm.layoutType[id] == 12
 }
@@ -50,6 +113,30 @@ func (mm *MeshManager) GetCount(MeshID string) int32 {
 	return mm.counts[MeshID]
 }
 
+// uploadVertices creates a vertex array over interleaved float vertices
+// and uint32 indices. Attribute i reads sizes[i] floats of each vertex, in
+// order, so the stride is their sum.
+func uploadVertices(vertices []float32, indices []uint32, sizes ...int32) (vao, vbo, ebo uint32) {
+	vao = Device.CreateVertexArray()
+	Device.BindVertexArray(vao)
+
+	vbo = Device.CreateBuffer(ArrayBuffer, len(vertices)*4, vertices, StaticDraw)
+	ebo = Device.CreateBuffer(ElementBuffer, len(indices)*4, indices, StaticDraw)
+
+	var stride int
+	for _, n := range sizes {
+		stride += int(n)
+	}
+	offset := 0
+	for i, n := range sizes {
+		Device.VertexAttrib(uint32(i), n, Float, false, stride*4, offset*4)
+		offset += int(n)
+	}
+
+	Device.BindVertexArray(0)
+	return vao, vbo, ebo
+}
+
 // Triangle with only positions (layout location 0)
 func (mm *MeshManager) RegisterTriangle(id string) {
 	vertices := []float32{
@@ -61,38 +148,15 @@ func (mm *MeshManager) RegisterTriangle(id string) {
 
 	indices := []uint32{0, 1, 2}
 
-	var vao, vbo, ebo uint32
-	gl.GenVertexArrays(1, &vao)
-	gl.GenBuffers(1, &vbo)
-	gl.GenBuffers(1, &ebo)
-
-	gl.BindVertexArray(vao)
-
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
-
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
-
-	stride := int32(8 * 4)
-
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, stride, 0)
-	gl.EnableVertexAttribArray(0)
-
-	gl.VertexAttribPointerWithOffset(1, 3, gl.FLOAT, false, stride, 3*4)
-	gl.EnableVertexAttribArray(1)
-
-	gl.VertexAttribPointerWithOffset(2, 2, gl.FLOAT, false, stride, 6*4)
-	gl.EnableVertexAttribArray(2)
-
-	gl.BindVertexArray(0)
+	vao, vbo, ebo := uploadVertices(vertices, indices, 3, 3, 2)
 
 	mm.vaos[id] = vao
 	mm.vbos[id] = vbo
 	mm.ebos[id] = ebo
 	mm.counts[id] = int32(len(indices))
-	mm.indexTypes[id] = gl.UNSIGNED_INT
+	mm.indexTypes[id] = IndexUint32
 	mm.vertexCounts[id] = 3
+	mm.bounds[id] = ComputeMeshBounds(vertices, 8)
 }
 
 func (mm *MeshManager) RegisterLine(id string) {
@@ -103,24 +167,8 @@ func (mm *MeshManager) RegisterLine(id string) {
 	}
 	indices := []uint32{0, 1}
 
-	var vao uint32
-	gl.GenVertexArrays(1, &vao)
-	gl.BindVertexArray(vao)
-
-	var vbo, ebo uint32
-	gl.GenBuffers(1, &vbo)
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
-
-	gl.GenBuffers(1, &ebo)
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
-
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, 3*4, 0)
-	gl.EnableVertexAttribArray(0)
-
-	gl.BindVertexArray(0)
-	mm.indexTypes[id] = gl.UNSIGNED_INT
+	vao, vbo, ebo := uploadVertices(vertices, indices, 3)
+	mm.indexTypes[id] = IndexUint32
 	mm.vertexCounts[id] = int32(len(vertices) / 3) // 3 floats per vertex
 	mm.counts[id] = int32(len(indices))
 	mm.vbos[id] = vbo
@@ -195,14 +243,7 @@ func (mm *MeshManager) RegisterCube(id string) {
 	}
 	computeTangents(vertices12, indices)
 
-	var vao, vbo, ebo uint32
-	gl.GenVertexArrays(1, &vao)
-	gl.GenBuffers(1, &vbo)
-	gl.GenBuffers(1, &ebo)
-
-	gl.BindVertexArray(vao)
 	// validate indices: ensure max index < vertexCount
-	// 8 floats per vertex for cube
 	var maxIdx uint32 = 0
 	for _, idx := range indices {
 		if idx > maxIdx {
@@ -214,39 +255,17 @@ func (mm *MeshManager) RegisterCube(id string) {
 		return
 	}
 
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices12)*4, gl.Ptr(vertices12), gl.STATIC_DRAW)
-
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
-
-	stride := int32(12 * 4) // 8 floats per vertex
-	/*if stride == 12 {
-		computeTangents(vertices, indices)
-	}*/
-	// position
-	// set attribute pointers using offset overloads (safe under Go 1.14+)
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, stride, 0)
-	gl.EnableVertexAttribArray(0)
-	gl.VertexAttribPointerWithOffset(1, 3, gl.FLOAT, false, stride, 3*4)
-	gl.EnableVertexAttribArray(1)
-	gl.VertexAttribPointerWithOffset(2, 2, gl.FLOAT, false, stride, 6*4)
-	gl.EnableVertexAttribArray(2)
-	gl.VertexAttribPointerWithOffset(3, 4, gl.FLOAT, false, stride, 8*4)
-	gl.EnableVertexAttribArray(3)
-
-	gl.BindVertexArray(0)
-	// now query the VAO state while still bound
-	// POST-REG full diagnostic - place while VAO still bound, right after setting pointers
-	// DIAG: place immediately after each VertexAttribPointerWithOffset call (while VAO still bound)
-
-	mm.indexTypes[id] = gl.UNSIGNED_INT
+	vao, vbo, ebo := uploadVertices(vertices12, indices, 3, 3, 2, 4)
+
+	mm.indexTypes[id] = IndexUint32
 	mm.vertexCounts[id] = int32(len(vertices12) / 12)
 	mm.counts[id] = int32(len(indices))
 	mm.vbos[id] = vbo
 	mm.ebos[id] = ebo
 	mm.vaos[id] = vao
 	mm.layoutType[id] = 12
+	mm.bounds[id] = ComputeMeshBounds(vertices12, 12)
+	mm.keepCPUMesh(id, vertices12, indices)
 
 	mm.verifyEBOSize(ebo, int32(len(indices)*4), id)
 
@@ -271,25 +290,7 @@ func (mm *MeshManager) RegisterWireCube(id string) {
 	}
 	// same VAO/VBO/EBO setup as before
 	// store in mm.vaos[id], mm.counts[id]
-	var vao, vbo, ebo uint32
-	gl.GenVertexArrays(1, &vao)
-	gl.GenBuffers(1, &vbo)
-	gl.GenBuffers(1, &ebo)
-
-	gl.BindVertexArray(vao)
-
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
-
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
-
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, 3*4, 0)
-	gl.EnableVertexAttribArray(0)
-	gl.VertexAttribPointerWithOffset(1, 3, gl.FLOAT, false, 6*4, 3*4)
-	gl.EnableVertexAttribArray(1)
-
-	gl.BindVertexArray(0)
+	vao, vbo, ebo := uploadVertices(vertices, indices, 3)
 	mm.layoutType[id] = 8
 
 	mm.vaos[id] = vao
@@ -325,28 +326,9 @@ func (mm *MeshManager) RegisterWireSphere(id string, slices, stacks int) {
 		}
 	}
 
-	var vao, vbo, ebo uint32
-	gl.GenVertexArrays(1, &vao)
-	gl.GenBuffers(1, &vbo)
-	gl.GenBuffers(1, &ebo)
-
-	gl.BindVertexArray(vao)
-
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
-
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
-
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, 3*4, 0)
-	gl.EnableVertexAttribArray(0)
+	vao, vbo, ebo := uploadVertices(vertices, indices, 3)
 
-	gl.VertexAttribPointerWithOffset(1, 3, gl.FLOAT, false, 6*4, 3*4)
-	gl.EnableVertexAttribArray(1)
-
-	gl.BindVertexArray(0)
-
-	mm.indexTypes[id] = gl.UNSIGNED_INT
+	mm.indexTypes[id] = IndexUint32
 	mm.vertexCounts[id] = int32(len(vertices) / 3)
 	mm.counts[id] = int32(len(indices))
 	mm.vbos[id] = vbo
@@ -401,39 +383,16 @@ func (mm *MeshManager) RegisterCube8(id string) {
 		20, 21, 22, 22, 23, 20,
 	}
 
-	var vao, vbo, ebo uint32
-	gl.GenVertexArrays(1, &vao)
-	gl.GenBuffers(1, &vbo)
-	gl.GenBuffers(1, &ebo)
-
-	gl.BindVertexArray(vao)
-
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
-
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
-
-	stride := int32(8 * 4)
-
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, stride, 0)
-	gl.EnableVertexAttribArray(0)
-
-	gl.VertexAttribPointerWithOffset(1, 3, gl.FLOAT, false, stride, 3*4)
-	gl.EnableVertexAttribArray(1)
-
-	gl.VertexAttribPointerWithOffset(2, 2, gl.FLOAT, false, stride, 6*4)
-	gl.EnableVertexAttribArray(2)
-
-	gl.BindVertexArray(0)
+	vao, vbo, ebo := uploadVertices(vertices, indices, 3, 3, 2)
 
 	mm.vaos[id] = vao
 	mm.vbos[id] = vbo
 	mm.ebos[id] = ebo
 	mm.counts[id] = int32(len(indices))
-	mm.indexTypes[id] = gl.UNSIGNED_INT
+	mm.indexTypes[id] = IndexUint32
 	mm.vertexCounts[id] = int32(len(vertices) / 8)
 	mm.layoutType[id] = 8
+	mm.bounds[id] = ComputeMeshBounds(vertices, 8)
 
 	mm.verifyEBOSize(ebo, int32(len(indices)*4), id)
 }
@@ -450,8 +409,9 @@ func (mm *MeshManager) RegisterPlane(id string) {
 	}
 
 	indices := []uint32{
-		0, 1, 2,
-		2, 3, 0,
+		// counter-clockwise seen from +Y
+		0, 2, 1,
+		0, 3, 2,
 	}
 
 	// Expand to 12 floats per vertex
@@ -465,43 +425,17 @@ func (mm *MeshManager) RegisterPlane(id string) {
 
 	computeTangents(verts12, indices)
 
-	// Upload to GL
-	var vao, vbo, ebo uint32
-	gl.GenVertexArrays(1, &vao)
-	gl.GenBuffers(1, &vbo)
-	gl.GenBuffers(1, &ebo)
-
-	gl.BindVertexArray(vao)
-
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(verts12)*4, gl.Ptr(verts12), gl.STATIC_DRAW)
-
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
-
-	stride := int32(12 * 4)
-
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, stride, 0)
-	gl.EnableVertexAttribArray(0)
-
-	gl.VertexAttribPointerWithOffset(1, 3, gl.FLOAT, false, stride, 3*4)
-	gl.EnableVertexAttribArray(1)
-
-	gl.VertexAttribPointerWithOffset(2, 2, gl.FLOAT, false, stride, 6*4)
-	gl.EnableVertexAttribArray(2)
-
-	gl.VertexAttribPointerWithOffset(3, 4, gl.FLOAT, false, stride, 8*4)
-	gl.EnableVertexAttribArray(3)
-
-	gl.BindVertexArray(0)
+	vao, vbo, ebo := uploadVertices(verts12, indices, 3, 3, 2, 4)
 
 	mm.vaos[id] = vao
 	mm.vbos[id] = vbo
 	mm.ebos[id] = ebo
 	mm.counts[id] = int32(len(indices))
-	mm.indexTypes[id] = gl.UNSIGNED_INT
+	mm.indexTypes[id] = IndexUint32
 	mm.vertexCounts[id] = int32(vertexCount)
 	mm.layoutType[id] = 12
+	mm.bounds[id] = ComputeMeshBounds(verts12, 12)
+	mm.keepCPUMesh(id, verts12, indices)
 
 	mm.verifyEBOSize(ebo, int32(len(indices)*4), id)
 }
@@ -523,12 +457,12 @@ func (mm *MeshManager) RegisterPlaneDoubleSided(id string) {
 
 	indices := []uint32{
 		// FRONT
-		0, 1, 2,
-		2, 3, 0,
+		0, 2, 1,
+		0, 3, 2,
 
 		// BACK (winding reversed)
-		4, 5, 6,
-		6, 7, 4,
+		4, 6, 5,
+		4, 7, 6,
 	}
 
 	// Expand to 12 floats per vertex
@@ -542,43 +476,17 @@ func (mm *MeshManager) RegisterPlaneDoubleSided(id string) {
 
 	computeTangents(verts12, indices)
 
-	// Upload to GL
-	var vao, vbo, ebo uint32
-	gl.GenVertexArrays(1, &vao)
-	gl.GenBuffers(1, &vbo)
-	gl.GenBuffers(1, &ebo)
-
-	gl.BindVertexArray(vao)
-
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(verts12)*4, gl.Ptr(verts12), gl.STATIC_DRAW)
-
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
-
-	stride := int32(12 * 4)
-
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, stride, 0)
-	gl.EnableVertexAttribArray(0)
-
-	gl.VertexAttribPointerWithOffset(1, 3, gl.FLOAT, false, stride, 3*4)
-	gl.EnableVertexAttribArray(1)
-
-	gl.VertexAttribPointerWithOffset(2, 2, gl.FLOAT, false, stride, 6*4)
-	gl.EnableVertexAttribArray(2)
-
-	gl.VertexAttribPointerWithOffset(3, 4, gl.FLOAT, false, stride, 8*4)
-	gl.EnableVertexAttribArray(3)
-
-	gl.BindVertexArray(0)
+	vao, vbo, ebo := uploadVertices(verts12, indices, 3, 3, 2, 4)
 
 	mm.vaos[id] = vao
 	mm.vbos[id] = vbo
 	mm.ebos[id] = ebo
 	mm.counts[id] = int32(len(indices))
-	mm.indexTypes[id] = gl.UNSIGNED_INT
+	mm.indexTypes[id] = IndexUint32
 	mm.vertexCounts[id] = int32(vertexCount)
 	mm.layoutType[id] = 12
+	mm.bounds[id] = ComputeMeshBounds(verts12, 12)
+	mm.keepCPUMesh(id, verts12, indices)
 
 	mm.verifyEBOSize(ebo, int32(len(indices)*4), id)
 }
@@ -615,8 +523,9 @@ func (mm *MeshManager) RegisterSphere(id string, slices, stacks int) {
 			first := uint32(i*(slices+1) + j)
 			second := first + uint32(slices+1)
 
-			indices = append(indices, first, second, first+1)
-			indices = append(indices, second, second+1, first+1)
+			// counter-clockwise seen from outside
+			indices = append(indices, first, first+1, second)
+			indices = append(indices, second, first+1, second+1)
 		}
 	}
 
@@ -632,43 +541,17 @@ func (mm *MeshManager) RegisterSphere(id string, slices, stacks int) {
 	// --- Compute tangents ---
 	computeTangents(verts12, indices)
 
-	// --- Upload to GL ---
-	var vao, vbo, ebo uint32
-	gl.GenVertexArrays(1, &vao)
-	gl.GenBuffers(1, &vbo)
-	gl.GenBuffers(1, &ebo)
-
-	gl.BindVertexArray(vao)
-
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(verts12)*4, gl.Ptr(verts12), gl.STATIC_DRAW)
-
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
-
-	stride := int32(12 * 4)
-
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, stride, 0)
-	gl.EnableVertexAttribArray(0)
-
-	gl.VertexAttribPointerWithOffset(1, 3, gl.FLOAT, false, stride, 3*4)
-	gl.EnableVertexAttribArray(1)
-
-	gl.VertexAttribPointerWithOffset(2, 2, gl.FLOAT, false, stride, 6*4)
-	gl.EnableVertexAttribArray(2)
-
-	gl.VertexAttribPointerWithOffset(3, 4, gl.FLOAT, false, stride, 8*4)
-	gl.EnableVertexAttribArray(3)
-
-	gl.BindVertexArray(0)
+	vao, vbo, ebo := uploadVertices(verts12, indices, 3, 3, 2, 4)
 
 	mm.vaos[id] = vao
 	mm.vbos[id] = vbo
 	mm.ebos[id] = ebo
 	mm.counts[id] = int32(len(indices))
-	mm.indexTypes[id] = gl.UNSIGNED_INT
+	mm.indexTypes[id] = IndexUint32
 	mm.vertexCounts[id] = int32(vertexCount)
 	mm.layoutType[id] = 12
+	mm.bounds[id] = ComputeMeshBounds(verts12, 12)
+	mm.keepCPUMesh(id, verts12, indices)
 
 	mm.verifyEBOSize(ebo, int32(len(indices)*4), id)
 }
@@ -685,15 +568,15 @@ func DumpVAOs(mm *MeshManager, ids []string, tag string) {
 func (mm *MeshManager) Delete() {
 	// delete VAOs
 	for _, vao := range mm.vaos {
-		gl.DeleteVertexArrays(1, &vao)
+		Device.DeleteVertexArray(vao)
 	}
 	// delete VBOs
 	for _, vbo := range mm.vbos {
-		gl.DeleteBuffers(1, &vbo)
+		Device.DeleteBuffer(vbo)
 	}
 	// delete EBOs
 	for _, ebo := range mm.ebos {
-		gl.DeleteBuffers(1, &ebo)
+		Device.DeleteBuffer(ebo)
 	}
 	// clear maps
 	mm.vaos = nil
@@ -702,6 +585,38 @@ func (mm *MeshManager) Delete() {
 	mm.counts = nil
 }
 
+// ReleaseMesh frees the GL objects and CPU streams of one mesh so it can be
+// registered again under the same ID.
+func (mm *MeshManager) ReleaseMesh(id string) {
+	if vao, ok := mm.vaos[id]; ok {
+		Device.DeleteVertexArray(vao)
+	}
+	for _, key := range []string{id, id + "_joints", id + "_weights", id + "_colors"} {
+		if vbo, ok := mm.vbos[key]; ok {
+			Device.DeleteBuffer(vbo)
+			delete(mm.vbos, key)
+		}
+	}
+	if ebo, ok := mm.ebos[id]; ok {
+		Device.DeleteBuffer(ebo)
+	}
+	delete(mm.vaos, id)
+	delete(mm.ebos, id)
+	delete(mm.counts, id)
+	delete(mm.indexTypes, id)
+	delete(mm.vertexCounts, id)
+	delete(mm.layoutType, id)
+	delete(mm.JointData, id)
+	delete(mm.WeightData, id)
+	delete(mm.PositionData, id)
+	delete(mm.NormalData, id)
+	delete(mm.ColorData, id)
+	delete(mm.cpuMeshes, id)
+	delete(mm.bounds, id)
+	delete(mm.instanceBufs, id)
+	mm.releaseLODs(id)
+}
+
 // RegisterGizmoArrow creates a simple arrow mesh pointing +Z (shaft + cone tip).
 func (mm *MeshManager) RegisterGizmoArrow(id string) {
 	// Simple low-poly arrow: shaft (two triangles as a thin quad) + cone tip (4 triangles)
@@ -740,26 +655,9 @@ func (mm *MeshManager) RegisterGizmoArrow(id string) {
 		7, 4, 8,
 	}
 
-	var vao, vbo, ebo uint32
-	gl.GenVertexArrays(1, &vao)
-	gl.GenBuffers(1, &vbo)
-	gl.GenBuffers(1, &ebo)
-
-	gl.BindVertexArray(vao)
-
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
-
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
+	vao, vbo, ebo := uploadVertices(vertices, indices, 3)
 
-	// position attribute only (location 0)
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, 3*4, 0)
-	gl.EnableVertexAttribArray(0)
-
-	gl.BindVertexArray(0)
-
-	mm.indexTypes[id] = gl.UNSIGNED_INT
+	mm.indexTypes[id] = IndexUint32
 	mm.vertexCounts[id] = int32(len(vertices) / 3)
 	mm.counts[id] = int32(len(indices))
 	mm.vbos[id] = vbo
@@ -783,25 +681,9 @@ func (mm *MeshManager) RegisterGizmoPlane(id string) {
 		2, 3, 0,
 	}
 
-	var vao, vbo, ebo uint32
-	gl.GenVertexArrays(1, &vao)
-	gl.GenBuffers(1, &vbo)
-	gl.GenBuffers(1, &ebo)
-
-	gl.BindVertexArray(vao)
-
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
-
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
+	vao, vbo, ebo := uploadVertices(vertices, indices, 3)
 
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, 3*4, 0)
-	gl.EnableVertexAttribArray(0)
-
-	gl.BindVertexArray(0)
-
-	mm.indexTypes[id] = gl.UNSIGNED_INT
+	mm.indexTypes[id] = IndexUint32
 	mm.vertexCounts[id] = int32(len(vertices) / 3)
 	mm.counts[id] = int32(len(indices))
 	mm.vbos[id] = vbo
@@ -824,25 +706,9 @@ func (mm *MeshManager) RegisterGizmoCircle(id string, segments int) {
 		indices = append(indices, uint32(i), uint32((i+1)%segments))
 	}
 
-	var vao, vbo, ebo uint32
-	gl.GenVertexArrays(1, &vao)
-	gl.GenBuffers(1, &vbo)
-	gl.GenBuffers(1, &ebo)
-
-	gl.BindVertexArray(vao)
-
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
+	vao, vbo, ebo := uploadVertices(vertices, indices, 3)
 
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
-
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, 3*4, 0)
-	gl.EnableVertexAttribArray(0)
-
-	gl.BindVertexArray(0)
-
-	mm.indexTypes[id] = gl.UNSIGNED_INT
+	mm.indexTypes[id] = IndexUint32
 	mm.vertexCounts[id] = int32(len(vertices) / 3)
 	mm.counts[id] = int32(len(indices))
 	mm.vbos[id] = vbo
@@ -878,41 +744,13 @@ func (mm *MeshManager) RegisterBillboardQuad(id string) {
 
 	computeTangents(verts12, indices)
 
-	// Upload to GL
-	var vao, vbo, ebo uint32
-	gl.GenVertexArrays(1, &vao)
-	gl.GenBuffers(1, &vbo)
-	gl.GenBuffers(1, &ebo)
-
-	gl.BindVertexArray(vao)
-
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(verts12)*4, gl.Ptr(verts12), gl.STATIC_DRAW)
-
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
-
-	stride := int32(12 * 4)
-
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, stride, 0)
-	gl.EnableVertexAttribArray(0)
-
-	gl.VertexAttribPointerWithOffset(1, 3, gl.FLOAT, false, stride, 3*4)
-	gl.EnableVertexAttribArray(1)
-
-	gl.VertexAttribPointerWithOffset(2, 2, gl.FLOAT, false, stride, 6*4)
-	gl.EnableVertexAttribArray(2)
-
-	gl.VertexAttribPointerWithOffset(3, 4, gl.FLOAT, false, stride, 8*4)
-	gl.EnableVertexAttribArray(3)
-
-	gl.BindVertexArray(0)
+	vao, vbo, ebo := uploadVertices(verts12, indices, 3, 3, 2, 4)
 
 	mm.vaos[id] = vao
 	mm.vbos[id] = vbo
 	mm.ebos[id] = ebo
 	mm.counts[id] = int32(len(indices))
-	mm.indexTypes[id] = gl.UNSIGNED_INT
+	mm.indexTypes[id] = IndexUint32
 	mm.vertexCounts[id] = int32(vertexCount)
 	mm.layoutType[id] = 12
 
@@ -930,8 +768,8 @@ func (mm *MeshManager) RegisterSinglePlane(id string) {
 	}
 
 	indices := []uint32{
-		0, 1, 2,
-		2, 3, 0,
+		0, 2, 1,
+		0, 3, 2,
 	}
 
 	// Expand to 12 floats per vertex (pos3, normal3, uv2, tangent3, w1)
@@ -945,43 +783,17 @@ func (mm *MeshManager) RegisterSinglePlane(id string) {
 
 	computeTangents(verts12, indices)
 
-	// Upload to GL
-	var vao, vbo, ebo uint32
-	gl.GenVertexArrays(1, &vao)
-	gl.GenBuffers(1, &vbo)
-	gl.GenBuffers(1, &ebo)
-
-	gl.BindVertexArray(vao)
-
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(verts12)*4, gl.Ptr(verts12), gl.STATIC_DRAW)
-
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
-
-	stride := int32(12 * 4)
-
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, stride, 0)
-	gl.EnableVertexAttribArray(0)
-
-	gl.VertexAttribPointerWithOffset(1, 3, gl.FLOAT, false, stride, 3*4)
-	gl.EnableVertexAttribArray(1)
-
-	gl.VertexAttribPointerWithOffset(2, 2, gl.FLOAT, false, stride, 6*4)
-	gl.EnableVertexAttribArray(2)
-
-	gl.VertexAttribPointerWithOffset(3, 4, gl.FLOAT, false, stride, 8*4)
-	gl.EnableVertexAttribArray(3)
-
-	gl.BindVertexArray(0)
+	vao, vbo, ebo := uploadVertices(verts12, indices, 3, 3, 2, 4)
 
 	mm.vaos[id] = vao
 	mm.vbos[id] = vbo
 	mm.ebos[id] = ebo
 	mm.counts[id] = int32(len(indices))
-	mm.indexTypes[id] = gl.UNSIGNED_INT
+	mm.indexTypes[id] = IndexUint32
 	mm.vertexCounts[id] = int32(vertexCount)
 	mm.layoutType[id] = 12
+	mm.bounds[id] = ComputeMeshBounds(verts12, 12)
+	mm.keepCPUMesh(id, verts12, indices)
 
 	mm.verifyEBOSize(ebo, int32(len(indices)*4), id)
 }
@@ -990,17 +802,14 @@ func (mm *MeshManager) RegisterSinglePlane(id string) {
 
 // SetMeshIndexInfo allows external code (glTF/OBJ importers) to register
 // the index type and vertex count for a mesh that was created elsewhere.
-func (mm *MeshManager) SetMeshIndexInfo(id string, indexType uint32, vertexCount int32) {
+func (mm *MeshManager) SetMeshIndexInfo(id string, indexType IndexType, vertexCount int32) {
 	mm.indexTypes[id] = indexType
 	mm.vertexCounts[id] = vertexCount
 }
 
-// GetIndexType returns the recorded index type for a mesh (default UNSIGNED_INT).
-func (mm *MeshManager) GetIndexType(id string) uint32 {
-	if t, ok := mm.indexTypes[id]; ok {
-		return t
-	}
-	return gl.UNSIGNED_INT
+// GetIndexType returns the recorded index type for a mesh (default IndexUint32).
+func (mm *MeshManager) GetIndexType(id string) IndexType {
+	return mm.indexTypes[id]
 }
 
 // GetVertexCount returns the recorded vertex count for a mesh (fallbacks to index count).
@@ -1018,10 +827,7 @@ func (mm *MeshManager) GetVertexCount(id string) int32 {
 // verifyEBOSize logs a warning if the element buffer size doesn't match expected bytes.
 // bytesPerIndex should be 4 for uint32 indices, 2 for uint16 indices.
 func (mm *MeshManager) verifyEBOSize(ebo uint32, expectedBytes int32, meshID string) {
-	var eboSize int32
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-	gl.GetBufferParameteriv(gl.ELEMENT_ARRAY_BUFFER, gl.BUFFER_SIZE, &eboSize)
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, 0)
+	eboSize := int32(Device.BufferSize(ElementBuffer, ebo))
 	if eboSize != expectedBytes {
 		// Use Printf/Log as appropriate in your project; keep it lightweight
 		// This warns you early if an index upload used a different element size.
@@ -0,0 +1,176 @@
+package engine
+
+import (
+	"image"
+	"testing"
+)
+
+func TestBuiltinMeshesOnRecordingDevice(t *testing.T) {
+	dev := NewRecordingDevice()
+	old := Device
+	Device = dev
+	defer func() { Device = old }()
+
+	mm := NewMeshManager()
+	mm.RegisterCube("cube")
+	mm.RegisterSphere("sphere", 8, 4)
+	mm.RegisterPlane("plane")
+	mm.RegisterWireCube("wire")
+	mm.RegisterGizmoArrow("arrow")
+
+	for id, count := range map[string]int32{"cube": 36, "sphere": 8 * 4 * 6, "plane": 6, "wire": 24, "arrow": 42} {
+		if mm.GetVAO(id) == 0 || mm.GetVBO(id) == 0 || mm.GetEBO(id) == 0 {
+			t.Errorf("%s: missing vertex array or buffers", id)
+		}
+		if got := mm.GetCount(id); got != count {
+			t.Errorf("%s: %d indices, want %d", id, got, count)
+		}
+		if got := dev.BufferSize(ElementBuffer, mm.GetEBO(id)); got != int(count)*4 {
+			t.Errorf("%s: index buffer of %d bytes", id, got)
+		}
+	}
+
+	// The lit primitives interleave pos, normal, uv and tangent.
+	var attribs []Command
+	for _, c := range dev.Pending().Commands {
+		if c.Op == "VertexAttrib" {
+			attribs = append(attribs, c)
+		}
+	}
+	if len(attribs) < 4 || attribs[3].Args[4] != 48 || attribs[3].Args[5] != 32 {
+		t.Errorf("cube attributes %v", attribs)
+	}
+
+	mm.Delete()
+	deleted := 0
+	for _, c := range dev.Pending().Commands {
+		if c.Op == "DeleteVertexArray" {
+			deleted++
+		}
+	}
+	if deleted != 5 {
+		t.Errorf("deleted %d vertex arrays, want 5", deleted)
+	}
+}
+
+func TestTextureUploadOnRecordingDevice(t *testing.T) {
+	dev := NewRecordingDevice()
+	old := Device
+	Device = dev
+	defer func() { Device = old }()
+
+	tex := NewPlaceholderTexture()
+	cooked := BuildMipChain(image.NewRGBA(image.Rect(0, 0, 4, 2)))
+	if got := UploadCookedTexture(tex, cooked); got != tex {
+		t.Fatalf("cooked upload made texture %d, want %d in place", got, tex)
+	}
+	DeleteTexture(tex)
+
+	var ops []string
+	var levels []any
+	for _, c := range dev.Pending().Commands {
+		ops = append(ops, c.Op)
+		if c.Op == "UploadTexture2D" {
+			levels = append(levels, c.Args[1])
+		}
+	}
+	want := []string{"CreateTexture2D", "UploadTexture2D", "GenerateMipmaps", "UploadTexture2D", "DeleteTexture"}
+	if len(ops) != len(want) {
+		t.Fatalf("ops %v, want %v", ops, want)
+	}
+	for i := range want {
+		if ops[i] != want[i] {
+			t.Fatalf("ops %v, want %v", ops, want)
+		}
+	}
+	if levels[1] != len(cooked.Levels) {
+		t.Errorf("cooked upload sent %v levels, want %d", levels[1], len(cooked.Levels))
+	}
+}
+
+// TestBuiltinMeshesWindCounterClockwise checks that every triangle of the
+// lit primitives faces along its vertex normals, so back-face culling of
+// single-sided materials keeps the outside.
+func TestBuiltinMeshesWindCounterClockwise(t *testing.T) {
+	old := Device
+	Device = NewRecordingDevice()
+	defer func() { Device = old }()
+
+	mm := NewMeshManager()
+	mm.RegisterTriangle("triangle")
+	mm.RegisterCube("cube")
+	mm.RegisterCube8("cube8")
+	mm.RegisterPlane("plane")
+	mm.RegisterPlaneDoubleSided("plane2")
+	mm.RegisterSinglePlane("single")
+	mm.RegisterBillboardQuad("billboard")
+	mm.RegisterSphere("sphere", 12, 6)
+
+	meshes := map[string]struct {
+		v      []float32
+		idx    []uint32
+		stride int
+	}{
+		"triangle": {[]float32{0, 0.5, 0, 0, 0, 1, 0.5, 1, -0.5, -0.5, 0, 0, 0, 1, 0, 0, 0.5, -0.5, 0, 0, 0, 1, 1, 0}, []uint32{0, 1, 2}, 8},
+	}
+	for _, id := range []string{"cube", "plane", "plane2", "single", "sphere"} {
+		m, _ := mm.CPUMesh(id)
+		meshes[id] = struct {
+			v      []float32
+			idx    []uint32
+			stride int
+		}{m.Vertices, m.Indices, 12}
+	}
+	for id, m := range meshes {
+		for i := 0; i+2 < len(m.idx); i += 3 {
+			var p [3][3]float32
+			var n [3]float32
+			for k := 0; k < 3; k++ {
+				v := m.v[int(m.idx[i+k])*m.stride:]
+				p[k] = [3]float32{v[0], v[1], v[2]}
+				n[0], n[1], n[2] = n[0]+v[3], n[1]+v[4], n[2]+v[5]
+			}
+			e1 := [3]float32{p[1][0] - p[0][0], p[1][1] - p[0][1], p[1][2] - p[0][2]}
+			e2 := [3]float32{p[2][0] - p[0][0], p[2][1] - p[0][1], p[2][2] - p[0][2]}
+			c := [3]float32{e1[1]*e2[2] - e1[2]*e2[1], e1[2]*e2[0] - e1[0]*e2[2], e1[0]*e2[1] - e1[1]*e2[0]}
+			area := c[0]*c[0] + c[1]*c[1] + c[2]*c[2]
+			if area < 1e-12 {
+				continue // degenerate triangles at the sphere's poles
+			}
+			if c[0]*n[0]+c[1]*n[1]+c[2]*n[2] <= 0 {
+				t.Errorf("%s: triangle %d winds clockwise", id, i/3)
+				break
+			}
+		}
+	}
+}
+
+func TestMeshManagerLODs(t *testing.T) {
+	old := Device
+	Device = NewRecordingDevice()
+	defer func() { Device = old }()
+
+	tri := func(id string) MeshData {
+		return MeshData{ID: id, Vertices: make([]float32, 3*12), Indices: []uint32{0, 1, 2}}
+	}
+	mm := NewMeshManager()
+	mm.RegisterMeshData(tri("rock"))
+	mm.RegisterMeshData(tri(LODMeshID("rock", 2))) // level 1 was not generated
+
+	if n := mm.LODCount("rock"); n != 2 {
+		t.Fatalf("LODCount = %d, want 2", n)
+	}
+	for level, want := range []string{"rock", "rock", "rock#lod2", "rock#lod2"} {
+		if got := mm.LOD("rock", level); got != want {
+			t.Errorf("LOD(rock, %d) = %q, want %q", level, got, want)
+		}
+	}
+	if base, level, ok := ParseLODMeshID("a#b#lod12"); !ok || base != "a#b" || level != 12 {
+		t.Errorf("ParseLODMeshID = %q, %d, %v", base, level, ok)
+	}
+
+	mm.ReleaseMesh("rock")
+	if mm.GetVAO("rock#lod2") != 0 || mm.LODCount("rock") != 0 {
+		t.Error("releasing a mesh kept its LODs")
+	}
+}
@@ -0,0 +1,187 @@
+package engine
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// OBJMaterial is one newmtl block of a .mtl file. Texture paths are
+// resolved against the .mtl's directory.
+type OBJMaterial struct {
+	Name      string
+	Diffuse   [3]float32 // Kd
+	Specular  [3]float32 // Ks
+	Shininess float32    // Ns, 0..1000
+	Alpha     float32    // d, or 1 - Tr
+
+	// PBR extension; the Has flags tell a stated zero from an absent value.
+	Roughness    float32 // Pr
+	Metallic     float32 // Pm
+	HasRoughness bool
+	HasMetallic  bool
+
+	DiffuseMap string // map_Kd
+	NormalMap  string // norm, map_Bump or bump
+	BumpScale  float32
+}
+
+// ParseMTL reads every material in a .mtl file, keyed by name.
+func ParseMTL(path string) (map[string]OBJMaterial, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dir := filepath.Dir(path)
+	out := map[string]OBJMaterial{}
+	var cur *OBJMaterial
+	flush := func() {
+		if cur != nil {
+			out[cur.Name] = *cur
+		}
+	}
+
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		key, args := fields[0], fields[1:]
+		if key == "newmtl" {
+			if len(args) == 0 {
+				return nil, lineError(path, lineNo, "newmtl needs a name")
+			}
+			flush()
+			cur = &OBJMaterial{Name: strings.Join(args, " "), Diffuse: [3]float32{1, 1, 1}, Alpha: 1, BumpScale: 1}
+			continue
+		}
+		if cur == nil {
+			return nil, lineError(path, lineNo, "%s before newmtl", key)
+		}
+
+		var err error
+		switch strings.ToLower(key) {
+		case "kd":
+			cur.Diffuse, err = mtlColor(args)
+		case "ks":
+			cur.Specular, err = mtlColor(args)
+		case "ns":
+			cur.Shininess, err = mtlScalar(args)
+		case "d":
+			cur.Alpha, err = mtlScalar(args)
+		case "tr":
+			var tr float32
+			tr, err = mtlScalar(args)
+			cur.Alpha = 1 - tr
+		case "pr":
+			cur.Roughness, err = mtlScalar(args)
+			cur.HasRoughness = err == nil
+		case "pm":
+			cur.Metallic, err = mtlScalar(args)
+			cur.HasMetallic = err == nil
+		case "map_kd":
+			var file string
+			file, _, err = mtlTexture(args)
+			if file != "" {
+				cur.DiffuseMap = filepath.Join(dir, file)
+			}
+		case "norm", "map_bump", "bump":
+			var file string
+			var scale float32
+			file, scale, err = mtlTexture(args)
+			if file != "" {
+				cur.NormalMap = filepath.Join(dir, file)
+				cur.BumpScale = scale
+			}
+		}
+		if err != nil {
+			return nil, lineError(path, lineNo, "%s: %v", key, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+	}
+	flush()
+	return out, nil
+}
+
+func mtlScalar(args []string) (float32, error) {
+	if len(args) < 1 {
+		return 0, fmt.Errorf("missing value")
+	}
+	v, err := parseFloats(args[:1])
+	if err != nil {
+		return 0, err
+	}
+	return v[0], nil
+}
+
+// mtlColor parses "r g b" or a single grey value. The "spectral" and "xyz"
+// forms are not supported.
+func mtlColor(args []string) ([3]float32, error) {
+	if len(args) == 1 {
+		v, err := mtlScalar(args)
+		return [3]float32{v, v, v}, err
+	}
+	if len(args) != 3 {
+		return [3]float32{}, fmt.Errorf("want 1 or 3 values, got %d", len(args))
+	}
+	v, err := parseFloats(args)
+	if err != nil {
+		return [3]float32{}, err
+	}
+	return [3]float32{v[0], v[1], v[2]}, nil
+}
+
+// mtlTextureOptions lists how many values each texture option takes; -o,
+// -s and -t take up to three.
+var mtlTextureOptions = map[string]int{
+	"-blendu": 1, "-blendv": 1, "-boost": 1, "-cc": 1, "-clamp": 1,
+	"-imfchan": 1, "-texres": 1, "-type": 1, "-bm": 1, "-mm": 2,
+	"-o": 3, "-s": 3, "-t": 3,
+}
+
+// mtlTexture splits a map statement into its file name and bump multiplier
+// (-bm), skipping the other options.
+func mtlTexture(args []string) (string, float32, error) {
+	scale := float32(1)
+	i := 0
+	for i < len(args) {
+		n, ok := mtlTextureOptions[strings.ToLower(args[i])]
+		if !ok {
+			break
+		}
+		opt := strings.ToLower(args[i])
+		i++
+		for taken := 0; taken < n && i < len(args); taken++ {
+			if taken > 0 && n == 3 {
+				if _, err := strconv.ParseFloat(args[i], 32); err != nil {
+					break
+				}
+			}
+			if opt == "-bm" {
+				v, err := strconv.ParseFloat(args[i], 32)
+				if err != nil {
+					return "", 0, fmt.Errorf("bad -bm value %q", args[i])
+				}
+				scale = float32(v)
+			}
+			i++
+		}
+	}
+	if i >= len(args) {
+		return "", 0, fmt.Errorf("missing file name")
+	}
+	// Exporters on Windows write backslashes.
+	file := strings.ReplaceAll(strings.Join(args[i:], " "), "\\", "/")
+	return filepath.FromSlash(file), scale, nil
+}
@@ -4,247 +4,380 @@ import (
 	"bufio"
 	"fmt"
 	"log"
+	"math"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
-
-	"github.com/go-gl/gl/v4.1-core/gl"
 )
 
-// parseIndex handles v, v/t, v//n, v/t/n and returns 1-based indices (0 means missing)
-func parseIndex(s string) (int, int, int, error) {
-	parts := strings.Split(s, "/")
-	var vi, ti, ni int
-	var err error
-	if len(parts) >= 1 && parts[0] != "" {
-		vi, err = strconv.Atoi(parts[0])
+// OBJModel is a decoded OBJ file. Faces are split into one mesh per run of
+// object, group and material, in file order.
+type OBJModel struct {
+	Meshes []MeshData
+	// MeshMaterials maps a mesh ID to the usemtl name its faces used.
+	MeshMaterials map[string]string
+	// Materials holds every material from the file's mtllib statements.
+	Materials map[string]OBJMaterial
+	// MaterialLibs are the .mtl paths the file referenced, resolved
+	// against its directory.
+	MaterialLibs []string
+}
+
+// lineError reports a malformed statement with its file and line.
+func lineError(path string, line int, format string, args ...any) error {
+	return fmt.Errorf("%s:%d: %s", path, line, fmt.Sprintf(format, args...))
+}
+
+// parseFloats parses every field as a float32.
+func parseFloats(fields []string) ([]float32, error) {
+	out := make([]float32, len(fields))
+	for i, s := range fields {
+		f, err := strconv.ParseFloat(s, 32)
 		if err != nil {
-			return 0, 0, 0, err
+			return nil, fmt.Errorf("bad number %q", s)
 		}
+		out[i] = float32(f)
 	}
-	if len(parts) >= 2 && parts[1] != "" {
-		ti, err = strconv.Atoi(parts[1])
-		if err != nil {
-			return 0, 0, 0, err
-		}
+	return out, nil
+}
+
+// parseIndex handles v, v/t, v//n, v/t/n and returns 1-based (or negative,
+// relative) indices; 0 means missing.
+func parseIndex(s string) (int, int, int, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) > 3 || parts[0] == "" {
+		return 0, 0, 0, fmt.Errorf("bad face vertex %q", s)
 	}
-	if len(parts) >= 3 && parts[2] != "" {
-		ni, err = strconv.Atoi(parts[2])
-		if err != nil {
-			return 0, 0, 0, err
+	var idx [3]int
+	for i, p := range parts {
+		if p == "" {
+			continue
 		}
+		n, err := strconv.Atoi(p)
+		if err != nil || n == 0 {
+			return 0, 0, 0, fmt.Errorf("bad face vertex %q", s)
+		}
+		idx[i] = n
 	}
-	return vi, ti, ni, nil
+	return idx[0], idx[1], idx[2], nil
 }
 
-// RegisterOBJ loads a basic OBJ and registers an interleaved mesh (pos, normal, uv).
-// Supports negative indices and will generate normals if none are present.
-func (mm *MeshManager) RegisterOBJ(id, path string) error {
+// resolveOBJIndex turns a 1-based or negative index into a 0-based one
+// against the count seen so far; missing (0) becomes -1.
+func resolveOBJIndex(idx, count int) (int, bool) {
+	switch {
+	case idx == 0:
+		return -1, true
+	case idx > 0:
+		return idx - 1, idx <= count
+	default:
+		return count + idx, count+idx >= 0
+	}
+}
+
+// RegisterOBJ loads an OBJ and registers one interleaved mesh per
+// object/group/material, returning their IDs.
+func (mm *MeshManager) RegisterOBJ(id, path string) ([]string, error) {
+	model, err := DecodeOBJModel(id, path)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(model.Meshes))
+	for _, md := range model.Meshes {
+		mm.RegisterMeshData(md)
+		ids = append(ids, md.ID)
+	}
+	return ids, nil
+}
+
+type objCorner struct{ vi, ti, ni int }
+
+type objFace struct {
+	corners []objCorner
+	smooth  int
+}
+
+type objPart struct {
+	object, group, material string
+	faces                   []objFace
+}
+
+// DecodeOBJModel parses an OBJ and its material libraries without touching
+// GL. Polygons are fan-triangulated and negative indices resolve against
+// the vertices defined so far. Faces without normals get them generated
+// per smoothing group: "s off" faces are flat, faces sharing a group are
+// smoothed together. Faces before any "s" statement are treated as smooth.
+//
+// A file with a single part yields one mesh named id; otherwise meshes are
+// named id/<object>_<group>_<material>, leaving out empty parts.
+func DecodeOBJModel(id, path string) (*OBJModel, error) {
 	f, err := os.Open(path)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer f.Close()
 
-	var positions [][]float32
-	var normals [][]float32
-	var uvs [][]float32
+	var positions, normals, uvs [][]float32
+	var libs []string
+	var parts []*objPart
+	cur := &objPart{}
+	smooth := 1
 
-	// temporary face storage to allow negative index resolution
-	type faceElem struct{ vi, ti, ni int }
-	var faces [][]faceElem
+	// part returns the part new faces go to, starting a new one if the
+	// object, group or material changed since the last face.
+	part := func() *objPart {
+		if len(cur.faces) > 0 {
+			parts = append(parts, cur)
+			cur = &objPart{object: cur.object, group: cur.group, material: cur.material}
+		}
+		return cur
+	}
 
 	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	lineNo := 0
 	for scanner.Scan() {
+		lineNo++
 		line := strings.TrimSpace(scanner.Text())
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
 		fields := strings.Fields(line)
+		args := fields[1:]
 		switch fields[0] {
 		case "v":
-			if len(fields) < 4 {
-				continue
+			// x y z, optionally followed by w or vertex colours
+			if len(args) < 3 {
+				return nil, lineError(path, lineNo, "v needs 3 coordinates, got %d", len(args))
+			}
+			p, err := parseFloats(args[:3])
+			if err != nil {
+				return nil, lineError(path, lineNo, "v: %v", err)
 			}
-			x, _ := strconv.ParseFloat(fields[1], 32)
-			y, _ := strconv.ParseFloat(fields[2], 32)
-			z, _ := strconv.ParseFloat(fields[3], 32)
-			positions = append(positions, []float32{float32(x), float32(y), float32(z)})
+			positions = append(positions, p)
 		case "vt":
-			if len(fields) < 3 {
-				continue
+			if len(args) < 1 {
+				return nil, lineError(path, lineNo, "vt needs at least 1 coordinate")
+			}
+			n := min(len(args), 2)
+			t, err := parseFloats(args[:n])
+			if err != nil {
+				return nil, lineError(path, lineNo, "vt: %v", err)
 			}
-			u, _ := strconv.ParseFloat(fields[1], 32)
-			v, _ := strconv.ParseFloat(fields[2], 32)
-			uvs = append(uvs, []float32{float32(u), float32(v)})
+			if n == 1 {
+				t = append(t, 0)
+			}
+			uvs = append(uvs, t)
 		case "vn":
-			if len(fields) < 4 {
-				continue
+			if len(args) < 3 {
+				return nil, lineError(path, lineNo, "vn needs 3 components, got %d", len(args))
+			}
+			n, err := parseFloats(args[:3])
+			if err != nil {
+				return nil, lineError(path, lineNo, "vn: %v", err)
 			}
-			nx, _ := strconv.ParseFloat(fields[1], 32)
-			ny, _ := strconv.ParseFloat(fields[2], 32)
-			nz, _ := strconv.ParseFloat(fields[3], 32)
-			normals = append(normals, []float32{float32(nx), float32(ny), float32(nz)})
+			normals = append(normals, n)
 		case "f":
-			if len(fields) < 4 {
-				continue
-			}
-			// triangulate polygon fan
-			elems := fields[1:]
-			var face []faceElem
-			for _, e := range elems {
-				vi, ti, ni, err := parseIndex(e)
+			if len(args) < 3 {
+				return nil, lineError(path, lineNo, "face needs at least 3 vertices, got %d", len(args))
+			}
+			face := objFace{smooth: smooth}
+			for _, a := range args {
+				vi, ti, ni, err := parseIndex(a)
 				if err != nil {
-					return fmt.Errorf("parseIndex error: %v", err)
+					return nil, lineError(path, lineNo, "%v", err)
+				}
+				var c objCorner
+				var ok bool
+				if c.vi, ok = resolveOBJIndex(vi, len(positions)); !ok {
+					return nil, lineError(path, lineNo, "vertex index %d out of range (%d defined)", vi, len(positions))
+				}
+				if c.ti, ok = resolveOBJIndex(ti, len(uvs)); !ok {
+					return nil, lineError(path, lineNo, "texcoord index %d out of range (%d defined)", ti, len(uvs))
+				}
+				if c.ni, ok = resolveOBJIndex(ni, len(normals)); !ok {
+					return nil, lineError(path, lineNo, "normal index %d out of range (%d defined)", ni, len(normals))
 				}
-				face = append(face, faceElem{vi, ti, ni})
+				face.corners = append(face.corners, c)
 			}
-			// triangulate
-			for i := 1; i < len(face)-1; i++ {
-				faces = append(faces, []faceElem{face[0], face[i], face[i+1]})
+			cur.faces = append(cur.faces, face)
+		case "o":
+			part().object = strings.Join(args, " ")
+			cur.group = ""
+		case "g":
+			part().group = strings.Join(args, " ")
+		case "usemtl":
+			part().material = strings.Join(args, " ")
+		case "mtllib":
+			for _, lib := range args {
+				libs = append(libs, filepath.Join(filepath.Dir(path), lib))
+			}
+		case "s":
+			if len(args) != 1 {
+				return nil, lineError(path, lineNo, "s needs one argument")
+			}
+			if args[0] == "off" {
+				smooth = 0
+			} else if smooth, err = strconv.Atoi(args[0]); err != nil {
+				return nil, lineError(path, lineNo, "bad smoothing group %q", args[0])
 			}
 		}
 	}
 	if err := scanner.Err(); err != nil {
-		return err
+		return nil, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+	}
+	if len(cur.faces) > 0 {
+		parts = append(parts, cur)
 	}
 
-	// If normals are missing, compute them after we resolve indices
-	needNormals := len(normals) == 0
-
-	// Resolve negative indices and build unique vertex list
-	vertMap := make(map[string]uint32)
-	var vertices []float32
-	var indices []uint32
-	var nextIndex uint32 = 0
+	model := &OBJModel{
+		MeshMaterials: map[string]string{},
+		Materials:     map[string]OBJMaterial{},
+		MaterialLibs:  libs,
+	}
+	for _, lib := range libs {
+		mats, err := ParseMTL(lib)
+		if err != nil {
+			// A missing library only costs the materials; the geometry is fine.
+			log.Printf("OBJ %s: %v", path, err)
+			continue
+		}
+		for name, m := range mats {
+			model.Materials[name] = m
+		}
+	}
 
-	// helper to resolve 1-based or negative indices to 0-based
-	resolve := func(idx, length int) int {
-		if idx == 0 {
-			return -1
+	used := map[string]int{}
+	for i, p := range parts {
+		meshID := id
+		if len(parts) > 1 {
+			meshID = id + "/" + objPartName(p, i)
+			if n := used[meshID]; n > 0 {
+				used[meshID]++
+				meshID = fmt.Sprintf("%s_%d", meshID, n)
+			} else {
+				used[meshID] = 1
+			}
+		}
+		md, err := buildOBJMesh(meshID, p.faces, positions, uvs, normals)
+		if err != nil {
+			return nil, err
 		}
-		if idx > 0 {
-			return idx - 1
+		model.Meshes = append(model.Meshes, md)
+		if p.material != "" {
+			model.MeshMaterials[meshID] = p.material
 		}
-		// negative index: relative to end
-		return length + idx
 	}
+	return model, nil
+}
 
-	// If normals missing, create a placeholder normals slice sized to positions (will fill later)
-	if needNormals {
-		normals = make([][]float32, len(positions))
-		for i := range normals {
-			normals[i] = []float32{0, 0, 0}
+func objPartName(p *objPart, index int) string {
+	var names []string
+	for _, n := range []string{p.object, p.group, p.material} {
+		if n != "" && (len(names) == 0 || names[len(names)-1] != n) {
+			names = append(names, n)
 		}
 	}
+	if len(names) == 0 {
+		return strconv.Itoa(index)
+	}
+	return strings.Join(names, "_")
+}
 
-	// Build vertices and indices
-	for _, tri := range faces {
-		for _, e := range tri {
-			vi := resolve(e.vi, len(positions))
-			ti := resolve(e.ti, len(uvs))
-			ni := resolve(e.ni, len(normals))
+// buildOBJMesh welds the faces' corners into 12-float vertices
+// (pos, normal, uv, tangent) and triangulates each polygon as a fan.
+func buildOBJMesh(id string, faces []objFace, positions, uvs, normals [][]float32) (MeshData, error) {
+	type vertKey struct{ vi, ti, ni, smooth int }
+	vertMap := map[vertKey]uint32{}
+	var vertices []float32
+	var indices []uint32
+	var generate []bool // vertex needs a generated normal
 
-			// clamp missing to -1
-			if vi < 0 {
-				vi = -1
-			}
-			if ti < 0 {
-				ti = -1
-			}
-			if ni < 0 {
-				ni = -1
+	for fi, face := range faces {
+		corner := make([]uint32, len(face.corners))
+		for ci, c := range face.corners {
+			key := vertKey{c.vi, c.ti, c.ni, 0}
+			if c.ni < 0 {
+				// Flat faces never share generated normals; smooth faces
+				// share them within their group.
+				key.smooth = face.smooth
+				if face.smooth == 0 {
+					key.smooth = -1 - fi
+				}
 			}
-
-			key := fmt.Sprintf("%d/%d/%d", vi, ti, ni)
 			idx, ok := vertMap[key]
 			if !ok {
-				var px, py, pz float32
-				if vi >= 0 {
-					p := positions[vi]
-					px, py, pz = p[0], p[1], p[2]
-				}
+				p := positions[c.vi]
 				var tx, ty float32
-				if ti >= 0 {
-					t := uvs[ti]
-					tx, ty = t[0], t[1]
+				if c.ti >= 0 {
+					tx, ty = uvs[c.ti][0], uvs[c.ti][1]
 				}
 				var nx, ny, nz float32
-				if ni >= 0 {
-					n := normals[ni]
+				if c.ni >= 0 {
+					n := normals[c.ni]
 					nx, ny, nz = n[0], n[1], n[2]
 				}
-				// append interleaved vertex: pos(3), normal(3), uv(2)
-				// previously: vertices = append(vertices, px, py, pz, nx, ny, nz, tx, ty)
-				// now append tangent placeholder:
-				// pos(3), normal(3), uv(2), tangent(4 placeholder: x,y,z,w)
-				vertices = append(vertices, px, py, pz, nx, ny, nz, tx, ty, 0.0, 0.0, 0.0, 1.0)
-
-				//vertices = append(vertices, px, py, pz, nx, ny, nz, tx, ty)
-				idx = nextIndex
+				idx = uint32(len(vertices) / 12)
+				vertices = append(vertices, p[0], p[1], p[2], nx, ny, nz, tx, ty, 0, 0, 0, 1)
+				generate = append(generate, c.ni < 0)
 				vertMap[key] = idx
-				nextIndex++
 			}
-			indices = append(indices, idx)
+			corner[ci] = idx
+		}
+		for i := 1; i < len(corner)-1; i++ {
+			indices = append(indices, corner[0], corner[i], corner[i+1])
 		}
 	}
 
-	// If normals were missing, compute them per-vertex now
-	if needNormals {
-		computeNormals(vertices, indices)
-		// after computeNormals, vertices' normal slots are filled
-	}
+	generateVertexNormals(vertices, indices, generate)
 	computeTangents(vertices, indices)
 
-	// create GL buffers
-	var vao, vbo, ebo uint32
-	gl.GenVertexArrays(1, &vao)
-	gl.GenBuffers(1, &vbo)
-	gl.GenBuffers(1, &ebo)
-
-	gl.BindVertexArray(vao)
-
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
-
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
-
-	stride := int32(12 * 4)
-
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, stride, 0)
-	gl.EnableVertexAttribArray(0)
-	gl.VertexAttribPointerWithOffset(1, 3, gl.FLOAT, false, stride, (3 * 4))
-	gl.EnableVertexAttribArray(1)
-	gl.VertexAttribPointerWithOffset(2, 2, gl.FLOAT, false, stride, 6*4)
-	gl.EnableVertexAttribArray(2)
-	gl.VertexAttribPointerWithOffset(3, 4, gl.FLOAT, false, stride, 8*4)
-
-	gl.EnableVertexAttribArray(3)
-	gl.BindVertexArray(0)
-
-	mm.vaos[id] = vao
-	mm.vbos[id] = vbo
-	mm.ebos[id] = ebo
-	mm.counts[id] = int32(len(indices))
-	mm.indexTypes[id] = gl.UNSIGNED_INT
-	mm.vertexCounts[id] = int32(len(vertices) / 12) // OBJ code builds 12-float interleaved vertices
-	mm.verifyEBOSize(ebo, int32(len(indices)*4), id)
-	mm.layoutType[id] = 12
-	// compute vertexCount for OBJ (you build 12-float interleaved vertices)
-	vertexCount := int32(len(vertices) / 12)
-
-	// validate indices
-	var maxIdx uint32 = 0
+	vertexCount := uint32(len(vertices) / 12)
 	for _, idx := range indices {
-		if idx > maxIdx {
-			maxIdx = idx
+		if idx >= vertexCount {
+			return MeshData{}, fmt.Errorf("OBJ mesh %s: index %d >= vertexCount %d", id, idx, vertexCount)
 		}
 	}
-	if int(maxIdx) >= int(vertexCount) {
-		log.Printf("ERROR: OBJ mesh %s has max index %d >= vertexCount %d", id, maxIdx, vertexCount)
-		return fmt.Errorf("OBJ mesh %s: max index %d >= vertexCount %d", id, maxIdx, vertexCount)
-	}
+	return MeshData{ID: id, Vertices: vertices, Indices: indices, HasTangents: true}, nil
+}
 
-	return nil
+// generateVertexNormals fills the normals of the flagged 12-float vertices
+// with the area-weighted sum of their triangles' face normals.
+func generateVertexNormals(vertices []float32, indices []uint32, generate []bool) {
+	const stride = 12
+	need := false
+	for _, g := range generate {
+		need = need || g
+	}
+	if !need {
+		return
+	}
+	for i := 0; i+2 < len(indices); i += 3 {
+		a, b, c := int(indices[i])*stride, int(indices[i+1])*stride, int(indices[i+2])*stride
+		ux, uy, uz := vertices[b]-vertices[a], vertices[b+1]-vertices[a+1], vertices[b+2]-vertices[a+2]
+		vx, vy, vz := vertices[c]-vertices[a], vertices[c+1]-vertices[a+1], vertices[c+2]-vertices[a+2]
+		nx, ny, nz := uy*vz-uz*vy, uz*vx-ux*vz, ux*vy-uy*vx
+		for _, v := range [3]int{a, b, c} {
+			if generate[v/stride] {
+				vertices[v+3] += nx
+				vertices[v+4] += ny
+				vertices[v+5] += nz
+			}
+		}
+	}
+	for v, g := range generate {
+		if !g {
+			continue
+		}
+		o := v*stride + 3
+		l := float32(math.Sqrt(float64(vertices[o]*vertices[o] + vertices[o+1]*vertices[o+1] + vertices[o+2]*vertices[o+2])))
+		if l > 0 {
+			vertices[o] /= l
+			vertices[o+1] /= l
+			vertices[o+2] /= l
+		} else {
+			vertices[o+1] = 1
+		}
+	}
 }
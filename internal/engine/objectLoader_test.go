@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestOBJ_SplitsPartsAndReadsMTL(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"box.obj": `mtllib box.mtl
+o lid
+v 0 0 0
+v 1 0 0
+v 1 0 1
+v 0 0 1
+vt 0 0
+usemtl wood
+s off
+f -4/1 -3/1 -2/1 -1/1
+o body
+v 0 1 0
+v 1 1 0
+v 1 1 1
+vn 0 1 0
+usemtl wood
+f 5//1 6//1 7//1
+usemtl metal
+s 1
+f 5 6 7
+f 5 7 6
+`,
+		"box.mtl": `newmtl wood
+Kd 0.5 0.25 0.125
+Ks 1
+Ns 250
+Tr 0.25
+map_Kd -s 2 2 -bm 3 tex/wood.png
+map_Bump -bm 0.5 tex\wood_n.png
+
+newmtl metal
+Pr 0.2
+Pm 1
+`,
+	})
+
+	model, err := DecodeOBJModel("box", filepath.Join(dir, "box.obj"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, md := range model.Meshes {
+		ids = append(ids, md.ID)
+	}
+	if got := strings.Join(ids, ","); got != "box/lid_wood,box/body_wood,box/body_metal" {
+		t.Fatalf("mesh IDs = %s", got)
+	}
+
+	// The quad is fan-triangulated from negative indices; with "s off" its
+	// generated normal is flat.
+	lid := model.Meshes[0]
+	if len(lid.Indices) != 6 || len(lid.Vertices) != 4*12 {
+		t.Fatalf("lid: %d indices, %d floats", len(lid.Indices), len(lid.Vertices))
+	}
+	for v := 0; v < 4; v++ {
+		if n := lid.Vertices[v*12+3 : v*12+6]; n[0] != 0 || n[1] != -1 || n[2] != 0 {
+			t.Fatalf("lid vertex %d normal = %v", v, n)
+		}
+	}
+	if n := len(model.Meshes[2].Indices); n != 6 {
+		t.Fatalf("body_metal has %d indices, want 6", n)
+	}
+	if model.MeshMaterials["box/body_metal"] != "metal" || model.MeshMaterials["box/lid_wood"] != "wood" {
+		t.Fatalf("mesh materials = %v", model.MeshMaterials)
+	}
+
+	wood := model.Materials["wood"]
+	if wood.Diffuse != [3]float32{0.5, 0.25, 0.125} || wood.Specular != [3]float32{1, 1, 1} ||
+		wood.Shininess != 250 || wood.Alpha != 0.75 || wood.HasRoughness {
+		t.Fatalf("wood = %+v", wood)
+	}
+	if wood.DiffuseMap != filepath.Join(dir, "tex", "wood.png") ||
+		wood.NormalMap != filepath.Join(dir, "tex", "wood_n.png") || wood.BumpScale != 0.5 {
+		t.Fatalf("wood maps = %q %q %v", wood.DiffuseMap, wood.NormalMap, wood.BumpScale)
+	}
+	metal := model.Materials["metal"]
+	if !metal.HasRoughness || metal.Roughness != 0.2 || !metal.HasMetallic || metal.Metallic != 1 {
+		t.Fatalf("metal = %+v", metal)
+	}
+}
+
+func TestOBJ_SinglePartKeepsID(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"tri.obj": "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\n"})
+	model, err := DecodeOBJModel("tri", filepath.Join(dir, "tri.obj"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(model.Meshes) != 1 || model.Meshes[0].ID != "tri" {
+		t.Fatalf("meshes = %+v", model.Meshes)
+	}
+}
+
+func TestOBJ_ErrorsCarryLineNumbers(t *testing.T) {
+	cases := map[string]string{
+		"v 0 0 0\nv 1 0 0\nv 0 1 0\n\nf 1 2 4\n": "bad.obj:5: vertex index 4 out of range",
+		"v 0 0 0\nv 1 x 0\n":                     "bad.obj:2: v: bad number",
+		"v 0 0 0\nf 1 -2 1\n":                    "bad.obj:2: vertex index -2 out of range",
+		"# c\nv 0 0 0\nv 0 0 0\nf 1/1/1/1 2 1\n": "bad.obj:4: bad face vertex",
+	}
+	for src, want := range cases {
+		dir := writeFiles(t, map[string]string{"bad.obj": src})
+		_, err := DecodeOBJModel("bad", filepath.Join(dir, "bad.obj"))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: err = %v, want %q", src, err, want)
+		}
+	}
+
+	dir := writeFiles(t, map[string]string{"bad.mtl": "newmtl a\nKd 1 2\n"})
+	if _, err := ParseMTL(filepath.Join(dir, "bad.mtl")); err == nil || !strings.Contains(err.Error(), "bad.mtl:2: Kd") {
+		t.Errorf("mtl err = %v", err)
+	}
+}
@@ -0,0 +1,397 @@
+package engine
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// plyProperty is one property line of a PLY header. List properties have
+// a count type and an item type.
+type plyProperty struct {
+	name      string
+	typ       string
+	list      bool
+	countType string
+}
+
+type plyElement struct {
+	name  string
+	count int
+	props []plyProperty
+}
+
+// DecodePLY reads an ASCII or binary (either endianness) PLY into 12-float
+// MeshData. Vertices take x/y/z, nx/ny/nz, u/v (or s/t, texture_u/v) and
+// red/green/blue/alpha, which become MeshData.Colors. Faces are read from
+// vertex_indices (or vertex_index) and fan-triangulated; other elements
+// are skipped. Missing normals are generated smooth, since PLY vertices
+// are shared between faces.
+func DecodePLY(id, path string) (MeshData, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return MeshData{}, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	format, elems, lines, err := readPLYHeader(path, r)
+	if err != nil {
+		return MeshData{}, err
+	}
+
+	var read plyReader
+	switch format {
+	case "ascii":
+		read = &plyASCII{r: r, path: path, line: lines}
+	case "binary_little_endian":
+		read = &plyBinary{r: r, path: path, order: binary.LittleEndian}
+	case "binary_big_endian":
+		read = &plyBinary{r: r, path: path, order: binary.BigEndian}
+	default:
+		return MeshData{}, fmt.Errorf("%s: unsupported format %q", path, format)
+	}
+
+	var vertices []float32
+	var colors [][4]float32
+	var indices []uint32
+	var haveNormals bool
+	vertexCount := -1
+
+	for _, el := range elems {
+		switch el.name {
+		case "vertex":
+			vertexCount = el.count
+			vertices = make([]float32, 0, el.count*12)
+			slot := map[string]int{}
+			for i, p := range el.props {
+				slot[p.name] = i
+			}
+			_, haveNormals = slot["nx"]
+			hasColor := false
+			for _, c := range []string{"red", "green", "blue"} {
+				if _, ok := slot[c]; ok {
+					hasColor = true
+				}
+			}
+			vals := make([]float64, len(el.props))
+			get := func(def float64, names ...string) float64 {
+				for _, n := range names {
+					if i, ok := slot[n]; ok {
+						return vals[i]
+					}
+				}
+				return def
+			}
+			for v := 0; v < el.count; v++ {
+				if err := read.start(); err != nil {
+					return MeshData{}, fmt.Errorf("%s: vertex %d: %w", read.pos(), v, err)
+				}
+				for i, p := range el.props {
+					if p.list {
+						if err := skipPLYList(read, p); err != nil {
+							return MeshData{}, fmt.Errorf("%s: vertex %d: %w", read.pos(), v, err)
+						}
+						continue
+					}
+					if vals[i], err = read.value(p.typ); err != nil {
+						return MeshData{}, fmt.Errorf("%s: vertex %d %s: %w", read.pos(), v, p.name, err)
+					}
+					if p.name == "red" || p.name == "green" || p.name == "blue" || p.name == "alpha" {
+						// Integer colours are normalized to their type's range.
+						switch plyTypeSize(p.typ) {
+						case 1:
+							vals[i] /= 255
+						case 2:
+							vals[i] /= 65535
+						}
+					}
+				}
+				vertices = append(vertices,
+					float32(get(0, "x")), float32(get(0, "y")), float32(get(0, "z")),
+					float32(get(0, "nx")), float32(get(0, "ny")), float32(get(0, "nz")),
+					float32(get(0, "u", "s", "texture_u", "texture_s")), float32(get(0, "v", "t", "texture_v", "texture_t")),
+					0, 0, 0, 1)
+				if hasColor {
+					colors = append(colors, [4]float32{
+						float32(get(1, "red")), float32(get(1, "green")), float32(get(1, "blue")), float32(get(1, "alpha")),
+					})
+				}
+			}
+
+		case "face":
+			if vertexCount < 0 {
+				return MeshData{}, fmt.Errorf("%s: face element before vertex element", path)
+			}
+			for fi := 0; fi < el.count; fi++ {
+				if err := read.start(); err != nil {
+					return MeshData{}, fmt.Errorf("%s: face %d: %w", read.pos(), fi, err)
+				}
+				for _, p := range el.props {
+					if !p.list || (p.name != "vertex_indices" && p.name != "vertex_index") {
+						if err := skipPLYProperty(read, p); err != nil {
+							return MeshData{}, fmt.Errorf("%s: face %d: %w", read.pos(), fi, err)
+						}
+						continue
+					}
+					n, err := readPLYCount(read, p)
+					if err != nil {
+						return MeshData{}, fmt.Errorf("%s: face %d: %w", read.pos(), fi, err)
+					}
+					poly := make([]uint32, n)
+					for k := range poly {
+						idx, err := read.value(p.typ)
+						if err != nil {
+							return MeshData{}, fmt.Errorf("%s: face %d: %w", read.pos(), fi, err)
+						}
+						if idx < 0 || int(idx) >= vertexCount {
+							return MeshData{}, fmt.Errorf("%s: face %d: vertex index %d out of range (%d vertices)", read.pos(), fi, int(idx), vertexCount)
+						}
+						poly[k] = uint32(idx)
+					}
+					for k := 1; k+1 < len(poly); k++ {
+						indices = append(indices, poly[0], poly[k], poly[k+1])
+					}
+				}
+			}
+
+		default:
+			for i := 0; i < el.count; i++ {
+				if err := read.start(); err != nil {
+					return MeshData{}, fmt.Errorf("%s: %s %d: %w", read.pos(), el.name, i, err)
+				}
+				for _, p := range el.props {
+					if err := skipPLYProperty(read, p); err != nil {
+						return MeshData{}, fmt.Errorf("%s: %s %d: %w", read.pos(), el.name, i, err)
+					}
+				}
+			}
+		}
+	}
+	if len(indices) == 0 {
+		return MeshData{}, fmt.Errorf("%s: no faces", path)
+	}
+
+	if !haveNormals {
+		generate := make([]bool, len(vertices)/12)
+		for i := range generate {
+			generate[i] = true
+		}
+		generateVertexNormals(vertices, indices, generate)
+	}
+	computeTangents(vertices, indices)
+	return MeshData{ID: id, Vertices: vertices, Indices: indices, Colors: colors, HasTangents: true}, nil
+}
+
+// readPLYHeader parses the header up to end_header and returns the format,
+// the elements in file order and the number of lines read.
+func readPLYHeader(path string, r *bufio.Reader) (string, []plyElement, int, error) {
+	var format string
+	var elems []plyElement
+	lineNo := 0
+	for {
+		raw, err := r.ReadString('\n')
+		if err != nil {
+			return "", nil, lineNo, fmt.Errorf("%s: header: %w", path, err)
+		}
+		lineNo++
+		fields := strings.Fields(raw)
+		if lineNo == 1 {
+			if len(fields) != 1 || fields[0] != "ply" {
+				return "", nil, lineNo, lineError(path, lineNo, "not a PLY file")
+			}
+			continue
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "format":
+			if len(fields) != 3 {
+				return "", nil, lineNo, lineError(path, lineNo, "want \"format <type> <version>\"")
+			}
+			format = fields[1]
+		case "comment", "obj_info":
+		case "element":
+			if len(fields) != 3 {
+				return "", nil, lineNo, lineError(path, lineNo, "want \"element <name> <count>\"")
+			}
+			n, err := strconv.Atoi(fields[2])
+			if err != nil || n < 0 {
+				return "", nil, lineNo, lineError(path, lineNo, "bad element count %q", fields[2])
+			}
+			elems = append(elems, plyElement{name: fields[1], count: n})
+		case "property":
+			if len(elems) == 0 {
+				return "", nil, lineNo, lineError(path, lineNo, "property before element")
+			}
+			var p plyProperty
+			switch {
+			case len(fields) == 5 && fields[1] == "list":
+				p = plyProperty{name: fields[4], typ: fields[3], list: true, countType: fields[2]}
+			case len(fields) == 3:
+				p = plyProperty{name: fields[2], typ: fields[1]}
+			default:
+				return "", nil, lineNo, lineError(path, lineNo, "malformed property")
+			}
+			for _, t := range []string{p.typ, p.countType} {
+				if t != "" && plyTypeSize(t) == 0 {
+					return "", nil, lineNo, lineError(path, lineNo, "unknown type %q", t)
+				}
+			}
+			el := &elems[len(elems)-1]
+			el.props = append(el.props, p)
+		case "end_header":
+			if format == "" {
+				return "", nil, lineNo, fmt.Errorf("%s: header has no format line", path)
+			}
+			return format, elems, lineNo, nil
+		default:
+			return "", nil, lineNo, lineError(path, lineNo, "unexpected %q in header", fields[0])
+		}
+	}
+}
+
+// plyTypeSize returns the byte size of a PLY scalar type, or 0 if unknown.
+func plyTypeSize(t string) int {
+	switch t {
+	case "char", "uchar", "int8", "uint8":
+		return 1
+	case "short", "ushort", "int16", "uint16":
+		return 2
+	case "int", "uint", "float", "int32", "uint32", "float32":
+		return 4
+	case "double", "float64":
+		return 8
+	}
+	return 0
+}
+
+func skipPLYProperty(read plyReader, p plyProperty) error {
+	if p.list {
+		return skipPLYList(read, p)
+	}
+	_, err := read.value(p.typ)
+	return err
+}
+
+// maxPLYListCount bounds a list's length so a corrupt count fails cleanly
+// instead of allocating without limit.
+const maxPLYListCount = 1 << 16
+
+// readPLYCount reads the count of list property p.
+func readPLYCount(read plyReader, p plyProperty) (int, error) {
+	n, err := read.value(p.countType)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 || n > maxPLYListCount || n != math.Trunc(n) {
+		return 0, fmt.Errorf("%s count %v out of range", p.name, n)
+	}
+	return int(n), nil
+}
+
+func skipPLYList(read plyReader, p plyProperty) error {
+	n, err := readPLYCount(read, p)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if _, err := read.value(p.typ); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// plyReader reads element data one scalar at a time. start is called
+// before each element instance, which in ASCII files is one line; pos
+// names the current position for errors.
+type plyReader interface {
+	start() error
+	value(typ string) (float64, error)
+	pos() string
+}
+
+type plyASCII struct {
+	r      *bufio.Reader
+	path   string
+	line   int
+	fields []string
+}
+
+func (a *plyASCII) start() error {
+	for {
+		raw, err := a.r.ReadString('\n')
+		if err != nil && (err != io.EOF || raw == "") {
+			if err == io.EOF {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		a.line++
+		if a.fields = strings.Fields(raw); len(a.fields) > 0 {
+			return nil
+		}
+	}
+}
+
+func (a *plyASCII) value(typ string) (float64, error) {
+	if len(a.fields) == 0 {
+		return 0, fmt.Errorf("too few values")
+	}
+	s := a.fields[0]
+	a.fields = a.fields[1:]
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("bad %s %q", typ, s)
+	}
+	return v, nil
+}
+
+func (a *plyASCII) pos() string { return fmt.Sprintf("%s:%d", a.path, a.line) }
+
+type plyBinary struct {
+	r     *bufio.Reader
+	path  string
+	order binary.ByteOrder
+	buf   [8]byte
+}
+
+func (b *plyBinary) start() error { return nil }
+
+func (b *plyBinary) pos() string { return b.path }
+
+func (b *plyBinary) value(typ string) (float64, error) {
+	n := plyTypeSize(typ)
+	if _, err := io.ReadFull(b.r, b.buf[:n]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+	p := b.buf[:n]
+	switch typ {
+	case "char", "int8":
+		return float64(int8(p[0])), nil
+	case "uchar", "uint8":
+		return float64(p[0]), nil
+	case "short", "int16":
+		return float64(int16(b.order.Uint16(p))), nil
+	case "ushort", "uint16":
+		return float64(b.order.Uint16(p)), nil
+	case "int", "int32":
+		return float64(int32(b.order.Uint32(p))), nil
+	case "uint", "uint32":
+		return float64(b.order.Uint32(p)), nil
+	case "float", "float32":
+		return float64(math.Float32frombits(b.order.Uint32(p))), nil
+	default:
+		return math.Float64frombits(b.order.Uint64(p)), nil
+	}
+}
@@ -0,0 +1,471 @@
+package engine
+
+import (
+	"fmt"
+	"log"
+	"math"
+)
+
+// Post-processing programs, loaded from assets/shaders like every other
+// shader.
+const (
+	postLuminanceShader = "post_luminance_shader"
+	postAdaptShader     = "post_adapt_shader"
+	postBloomDownShader = "post_bloom_down_shader"
+	postBloomUpShader   = "post_bloom_up_shader"
+	postTonemapShader   = "post_tonemap_shader"
+	postFXAAShader      = "post_fxaa_shader"
+)
+
+// PostShaders names every program NewPostStack needs.
+var PostShaders = []string{
+	postLuminanceShader, postAdaptShader, postBloomDownShader,
+	postBloomUpShader, postTonemapShader, postFXAAShader,
+}
+
+const (
+	meteringSize = 64 // texels per side of the log-luminance target
+	bloomLevels  = 6  // downsample levels, the first at half resolution
+)
+
+// PostSettings configure a camera's post-processing chain.
+type PostSettings struct {
+	// AutoExposure meters the frame's average luminance and adapts to it;
+	// otherwise exposure is 2^ExposureCompensation.
+	AutoExposure         bool
+	ExposureCompensation float32 // EV stops, added in both modes
+	MinEV100, MaxEV100   float32 // auto exposure range
+	AdaptSpeedUp         float32 // 1/s, towards brighter scenes
+	AdaptSpeedDown       float32 // 1/s, towards darker scenes
+
+	Tonemap TonemapOperator
+
+	Bloom          bool
+	BloomThreshold float32 // luminance where bloom starts
+	BloomKnee      float32 // width of the soft threshold
+	BloomIntensity float32
+	BloomRadius    float32 // upsample tent radius, in texels
+
+	FXAA bool
+
+	VignetteIntensity  float32 // 0 disables
+	VignetteSmoothness float32
+
+	// LUTPath names a .cube colour grading table applied after gamma,
+	// blended in by LUTContribution.
+	LUTPath         string
+	LUTContribution float32
+}
+
+var DefaultPostSettings = PostSettings{
+	MinEV100:           -4,
+	MaxEV100:           16,
+	AdaptSpeedUp:       3,
+	AdaptSpeedDown:     1,
+	Tonemap:            TonemapACES,
+	BloomThreshold:     1,
+	BloomKnee:          0.5,
+	BloomIntensity:     0.05,
+	BloomRadius:        1,
+	FXAA:               true,
+	VignetteSmoothness: 0.5,
+	LUTContribution:    1,
+}
+
+// Exposure is the multiplier applied to scene radiance, given the
+// adapted average luminance (used only with AutoExposure).
+func (s PostSettings) Exposure(adapted float32) float32 {
+	e := float32(math.Exp2(float64(s.ExposureCompensation)))
+	if !s.AutoExposure {
+		return e
+	}
+	ev := min(max(EV100FromLuminance(adapted), s.MinEV100), s.MaxEV100)
+	return e * ExposureFromEV100(ev)
+}
+
+type postTarget struct {
+	fbo, tex      uint32
+	width, height int
+	format        TextureFormat
+}
+
+func (t *postTarget) desc() TextureDesc {
+	return TextureDesc{
+		Width: t.width, Height: t.height,
+		Format: t.format, Filter: FilterLinear, Wrap: WrapClampToEdge,
+	}
+}
+
+func newPostTarget(width, height int, format TextureFormat, depth uint32) (postTarget, error) {
+	t := postTarget{width: width, height: height, format: format}
+	t.tex = Device.CreateTexture2D(t.desc(), nil)
+	fbo, err := Device.CreateFramebuffer(FramebufferDesc{Color: t.tex, DepthRenderbuffer: depth})
+	if err != nil {
+		Device.DeleteTexture(t.tex)
+		return postTarget{}, err
+	}
+	t.fbo = fbo
+	return t, nil
+}
+
+func (t *postTarget) resize(width, height int) {
+	if t.width == width && t.height == height {
+		return
+	}
+	t.width, t.height = width, height
+	Device.ResizeTexture2D(t.tex, t.desc())
+}
+
+func (t *postTarget) delete() {
+	if t.fbo != 0 {
+		Device.DeleteFramebuffer(t.fbo)
+	}
+	if t.tex != 0 {
+		Device.DeleteTexture(t.tex)
+	}
+	*t = postTarget{}
+}
+
+type postLUT struct {
+	lut *LUT3D
+	tex uint32 // 0 when the table failed to load
+}
+
+// PostStack owns the HDR scene target and the post-processing chain that
+// resolves it to the default framebuffer: auto exposure, bloom, tone
+// mapping with vignette and colour grading, then FXAA.
+type PostStack struct {
+	Width, Height int
+
+	hdr      postTarget
+	hdrDepth uint32 // renderbuffer
+	ldr      postTarget
+	down, up []postTarget
+
+	metering   postTarget    // log2 luminance, mipmapped to one texel
+	adapt      [2]postTarget // adapted luminance, ping-ponged
+	adaptCur   int
+	adaptValid bool
+
+	vao  uint32 // empty; the fullscreen triangle comes from gl_VertexID
+	luts map[string]postLUT
+}
+
+// NewPostStack creates the chain for a width x height screen. It fails
+// when the post shaders have not been loaded.
+func NewPostStack(width, height int) (*PostStack, error) {
+	for _, name := range PostShaders {
+		if _, err := GetShaderProgram(name); err != nil {
+			return nil, err
+		}
+	}
+	p := &PostStack{luts: map[string]postLUT{}}
+	p.vao = Device.CreateVertexArray()
+
+	var err error
+	if p.metering, err = newPostTarget(meteringSize, meteringSize, R16F, 0); err != nil {
+		p.Delete()
+		return nil, err
+	}
+	for i := range p.adapt {
+		if p.adapt[i], err = newPostTarget(1, 1, R16F, 0); err != nil {
+			p.Delete()
+			return nil, err
+		}
+	}
+	if err := p.Resize(width, height); err != nil {
+		p.Delete()
+		return nil, err
+	}
+	return p, nil
+}
+
+// Resize fits the screen-sized targets to a new screen size.
+func (p *PostStack) Resize(width, height int) error {
+	width, height = max(width, 1), max(height, 1)
+	if width == p.Width && height == p.Height {
+		return nil
+	}
+	p.Width, p.Height = width, height
+	if p.hdr.fbo == 0 {
+		return p.createScreenTargets()
+	}
+
+	p.hdr.resize(width, height)
+	Device.ResizeRenderbuffer(p.hdrDepth, Depth24, width, height)
+	p.ldr.resize(width, height)
+	w, h := width, height
+	for i := range p.down {
+		w, h = max(w/2, 1), max(h/2, 1)
+		p.down[i].resize(w, h)
+		if i < len(p.up) {
+			p.up[i].resize(w, h)
+		}
+	}
+	return nil
+}
+
+func (p *PostStack) createScreenTargets() error {
+	p.hdrDepth = Device.CreateRenderbuffer(Depth24, p.Width, p.Height)
+	var err error
+	if p.hdr, err = newPostTarget(p.Width, p.Height, RGBA16F, p.hdrDepth); err != nil {
+		return fmt.Errorf("post: HDR target: %w", err)
+	}
+	if p.ldr, err = newPostTarget(p.Width, p.Height, RGBA8, 0); err != nil {
+		return fmt.Errorf("post: LDR target: %w", err)
+	}
+	// The smallest downsample level is only read, so it has no upsample
+	// partner.
+	w, h := p.Width, p.Height
+	for i := 0; i < bloomLevels; i++ {
+		w, h = max(w/2, 1), max(h/2, 1)
+		d, err := newPostTarget(w, h, RGBA16F, 0)
+		if err != nil {
+			return fmt.Errorf("post: bloom level %d: %w", i, err)
+		}
+		p.down = append(p.down, d)
+		if i == bloomLevels-1 {
+			break
+		}
+		u, err := newPostTarget(w, h, RGBA16F, 0)
+		if err != nil {
+			return fmt.Errorf("post: bloom level %d: %w", i, err)
+		}
+		p.up = append(p.up, u)
+	}
+	return nil
+}
+
+// HDRFramebuffer is the framebuffer the scene renders into.
+func (p *PostStack) HDRFramebuffer() uint32 { return p.hdr.fbo }
+
+// Begin binds and clears the HDR target, resizing it first if the screen
+// changed.
+func (p *PostStack) Begin(width, height int) error {
+	if err := p.Resize(width, height); err != nil {
+		return err
+	}
+	Device.BindFramebuffer(p.hdr.fbo)
+	Device.Viewport(0, 0, int32(p.Width), int32(p.Height))
+	Device.Clear(ClearColorBit | ClearDepthBit)
+	return nil
+}
+
+// Apply resolves the HDR target into the default framebuffer. dt drives
+// eye adaptation. Depth testing is restored on return; blending is left
+// off.
+func (p *PostStack) Apply(s PostSettings, dt float32) {
+	p.ApplyTo(0, s, dt)
+}
+
+// ApplyTo is Apply resolving into framebuffer out, which must be at
+// least the stack's size.
+func (p *PostStack) ApplyTo(out uint32, s PostSettings, dt float32) {
+	dev := Device
+	dev.SetDepthTest(false)
+	dev.SetBlend(false)
+	dev.BindVertexArray(p.vao)
+
+	if s.AutoExposure {
+		p.meter(s, dt)
+	} else {
+		p.adaptValid = false
+	}
+	var bloom uint32
+	if s.Bloom {
+		bloom = p.bloom(s)
+	}
+
+	tonemapped := out
+	if s.FXAA {
+		tonemapped = p.ldr.fbo
+	}
+	p.tonemap(s, bloom, tonemapped)
+	if s.FXAA {
+		sp := MustGetShaderProgram(postFXAAShader)
+		p.pass(sp, out, p.Width, p.Height)
+		dev.BindTexture(0, Texture2D, p.ldr.tex)
+		SetInt(sp.Loc("ldrTex"), 0)
+		SetVec2(sp.Loc("texelSize"), 1/float32(p.Width), 1/float32(p.Height))
+		dev.DrawArrays(Triangles, 0, 3)
+	}
+
+	dev.BindVertexArray(0)
+	dev.SetDepthTest(true)
+}
+
+// ResetAdaptation makes the next auto-exposed frame start from its own
+// metered luminance instead of adapting from the previous frames.
+func (p *PostStack) ResetAdaptation() { p.adaptValid = false }
+
+// pass binds sp and a target for a fullscreen draw.
+func (p *PostStack) pass(sp *ShaderProgram, fbo uint32, width, height int) {
+	Device.BindFramebuffer(fbo)
+	Device.Viewport(0, 0, int32(width), int32(height))
+	Device.UseProgram(sp.ID)
+}
+
+// meter averages the frame's log luminance and steps eye adaptation.
+func (p *PostStack) meter(s PostSettings, dt float32) {
+	dev := Device
+	sp := MustGetShaderProgram(postLuminanceShader)
+	p.pass(sp, p.metering.fbo, meteringSize, meteringSize)
+	dev.BindTexture(0, Texture2D, p.hdr.tex)
+	SetInt(sp.Loc("hdrTex"), 0)
+	SetVec2(sp.Loc("texelSize"), 1.0/meteringSize, 1.0/meteringSize)
+	dev.DrawArrays(Triangles, 0, 3)
+	dev.GenerateMipmaps(p.metering.tex)
+
+	prev := p.adapt[p.adaptCur]
+	p.adaptCur ^= 1
+	sp = MustGetShaderProgram(postAdaptShader)
+	p.pass(sp, p.adapt[p.adaptCur].fbo, 1, 1)
+	dev.BindTexture(0, Texture2D, p.metering.tex)
+	dev.BindTexture(1, Texture2D, prev.tex)
+	SetInt(sp.Loc("lumTex"), 0)
+	SetInt(sp.Loc("prevTex"), 1)
+	SetFloat(sp.Loc("lumLevel"), float32(math.Log2(meteringSize)))
+	SetFloat(sp.Loc("dt"), dt)
+	SetFloat(sp.Loc("speedUp"), s.AdaptSpeedUp)
+	SetFloat(sp.Loc("speedDown"), s.AdaptSpeedDown)
+	reset := int32(0)
+	if !p.adaptValid {
+		reset = 1
+	}
+	SetInt(sp.Loc("reset"), reset)
+	dev.DrawArrays(Triangles, 0, 3)
+	p.adaptValid = true
+}
+
+// bloom runs the downsample and upsample chains and returns the
+// half-resolution bloom texture.
+func (p *PostStack) bloom(s PostSettings) uint32 {
+	dev := Device
+	sp := MustGetShaderProgram(postBloomDownShader)
+	src, sw, sh := p.hdr.tex, p.Width, p.Height
+	for i, d := range p.down {
+		p.pass(sp, d.fbo, d.width, d.height)
+		dev.BindTexture(0, Texture2D, src)
+		SetInt(sp.Loc("srcTex"), 0)
+		SetVec2(sp.Loc("texelSize"), 1/float32(sw), 1/float32(sh))
+		prefilter := int32(0)
+		if i == 0 {
+			prefilter = 1
+		}
+		SetInt(sp.Loc("prefilter"), prefilter)
+		SetFloat(sp.Loc("threshold"), s.BloomThreshold)
+		SetFloat(sp.Loc("knee"), max(s.BloomKnee, 1e-4))
+		dev.DrawArrays(Triangles, 0, 3)
+		src, sw, sh = d.tex, d.width, d.height
+	}
+
+	sp = MustGetShaderProgram(postBloomUpShader)
+	for i := len(p.up) - 1; i >= 0; i-- {
+		u := p.up[i]
+		p.pass(sp, u.fbo, u.width, u.height)
+		dev.BindTexture(0, Texture2D, src)
+		dev.BindTexture(1, Texture2D, p.down[i].tex)
+		SetInt(sp.Loc("srcTex"), 0)
+		SetInt(sp.Loc("baseTex"), 1)
+		SetVec2(sp.Loc("texelSize"), 1/float32(sw), 1/float32(sh))
+		SetFloat(sp.Loc("radius"), s.BloomRadius)
+		dev.DrawArrays(Triangles, 0, 3)
+		src, sw, sh = u.tex, u.width, u.height
+	}
+	return src
+}
+
+func (p *PostStack) tonemap(s PostSettings, bloom, fbo uint32) {
+	dev := Device
+	sp := MustGetShaderProgram(postTonemapShader)
+	p.pass(sp, fbo, p.Width, p.Height)
+	dev.BindTexture(0, Texture2D, p.hdr.tex)
+	SetInt(sp.Loc("hdrTex"), 0)
+
+	useBloom := int32(0)
+	if bloom != 0 {
+		useBloom = 1
+		dev.BindTexture(1, Texture2D, bloom)
+	}
+	SetInt(sp.Loc("bloomTex"), 1)
+	SetInt(sp.Loc("useBloom"), useBloom)
+	// Each upsample adds a level, so normalise by the number summed.
+	SetFloat(sp.Loc("bloomIntensity"), s.BloomIntensity/float32(len(p.down)))
+
+	auto := int32(0)
+	if s.AutoExposure {
+		auto = 1
+		dev.BindTexture(2, Texture2D, p.adapt[p.adaptCur].tex)
+	}
+	SetInt(sp.Loc("adaptTex"), 2)
+	SetInt(sp.Loc("autoExposure"), auto)
+	SetFloat(sp.Loc("exposure"), float32(math.Exp2(float64(s.ExposureCompensation))))
+	SetVec2(sp.Loc("evRange"), s.MinEV100, s.MaxEV100)
+	SetInt(sp.Loc("tonemapper"), int32(s.Tonemap))
+	SetFloat(sp.Loc("vignetteIntensity"), s.VignetteIntensity)
+	SetFloat(sp.Loc("vignetteSmoothness"), s.VignetteSmoothness)
+
+	useLUT := int32(0)
+	if l := p.lut(s.LUTPath); l.tex != 0 && s.LUTContribution > 0 {
+		useLUT = 1
+		dev.BindTexture(3, Texture3D, l.tex)
+		SetFloat(sp.Loc("lutSize"), float32(l.lut.Size))
+		SetFloat(sp.Loc("lutContribution"), s.LUTContribution)
+		SetVec3(sp.Loc("lutDomainMin"), l.lut.DomainMin[0], l.lut.DomainMin[1], l.lut.DomainMin[2])
+		SetVec3(sp.Loc("lutDomainMax"), l.lut.DomainMax[0], l.lut.DomainMax[1], l.lut.DomainMax[2])
+	}
+	SetInt(sp.Loc("lutTex"), 3)
+	SetInt(sp.Loc("useLUT"), useLUT)
+	dev.DrawArrays(Triangles, 0, 3)
+}
+
+// lut loads and uploads a colour grading table once per path; failures
+// are logged once and leave grading off.
+func (p *PostStack) lut(path string) postLUT {
+	if path == "" {
+		return postLUT{}
+	}
+	if l, ok := p.luts[path]; ok {
+		return l
+	}
+	var l postLUT
+	lut, err := LoadCubeLUT(path)
+	if err != nil {
+		log.Printf("post: colour LUT %v", err)
+	} else {
+		l = postLUT{lut: lut, tex: lut.Upload()}
+	}
+	p.luts[path] = l
+	return l
+}
+
+// Delete frees every target, table and the vertex array. The depth
+// renderbuffer is left to the context: the device has no call to free
+// one.
+func (p *PostStack) Delete() {
+	p.hdr.delete()
+	p.ldr.delete()
+	for i := range p.down {
+		p.down[i].delete()
+	}
+	for i := range p.up {
+		p.up[i].delete()
+	}
+	p.down, p.up = nil, nil
+	p.Width, p.Height = 0, 0
+	p.metering.delete()
+	for i := range p.adapt {
+		p.adapt[i].delete()
+	}
+	for path, l := range p.luts {
+		if l.tex != 0 {
+			Device.DeleteTexture(l.tex)
+			forgetTextureBytes(l.tex)
+		}
+		delete(p.luts, path)
+	}
+	if p.vao != 0 {
+		Device.DeleteVertexArray(p.vao)
+		p.vao = 0
+	}
+}
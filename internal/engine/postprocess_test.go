@@ -0,0 +1,244 @@
+package engine
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+const identityCube = `# identity
+TITLE "Identity 2"
+LUT_3D_SIZE 2
+DOMAIN_MIN 0 0 0
+DOMAIN_MAX 1 1 1
+0 0 0
+1 0 0
+0 1 0
+1 1 0
+0 0 1
+1 0 1
+0 1 1
+1 1 1
+`
+
+func TestParseCubeLUT(t *testing.T) {
+	lut, err := ParseCubeLUT(strings.NewReader(identityCube))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lut.Title != "Identity 2" || lut.Size != 2 || len(lut.Data) != 24 {
+		t.Fatalf("parsed %q size %d with %d values", lut.Title, lut.Size, len(lut.Data))
+	}
+	for _, c := range [][3]float32{{0, 0, 0}, {0.25, 0.5, 0.75}, {1, 1, 1}, {0.9, 0.1, 0.4}} {
+		if got := lut.Apply(c); !closeRGB(got, c, 1e-6) {
+			t.Errorf("identity LUT maps %v to %v", c, got)
+		}
+	}
+	if got := lut.Apply([3]float32{-1, 2, 0.5}); !closeRGB(got, [3]float32{0, 1, 0.5}, 1e-6) {
+		t.Errorf("out-of-domain input not clamped: %v", got)
+	}
+
+	// Swapping red and blue shows the red-fastest ordering.
+	swap := strings.NewReader("LUT_3D_SIZE 2\n0 0 0\n0 0 1\n0 1 0\n0 1 1\n1 0 0\n1 0 1\n1 1 0\n1 1 1\n")
+	lut, err = ParseCubeLUT(swap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := lut.Apply([3]float32{1, 0.5, 0}); !closeRGB(got, [3]float32{0, 0.5, 1}, 1e-6) {
+		t.Errorf("red/blue swap gave %v", got)
+	}
+
+	for name, src := range map[string]string{
+		"1D":        "LUT_1D_SIZE 2\n0 0 0\n1 1 1\n",
+		"no size":   "0 0 0\n",
+		"short":     "LUT_3D_SIZE 2\n0 0 0\n",
+		"long":      strings.Replace(identityCube, "1 1 1\n", "1 1 1\n1 1 1\n", 1),
+		"bad value": "LUT_3D_SIZE 2\n0 0 x\n",
+		"domain":    "LUT_3D_SIZE 2\nDOMAIN_MIN 1 0 0\nDOMAIN_MAX 1 1 1\n",
+	} {
+		if _, err := ParseCubeLUT(strings.NewReader(src)); err == nil {
+			t.Errorf("%s: parsed without error", name)
+		}
+	}
+}
+
+func TestTonemap(t *testing.T) {
+	for op := TonemapACES; op <= TonemapNone; op++ {
+		prev := float32(-1)
+		for _, x := range []float32{0, 0.01, 0.1, 0.5, 1, 2, 8, 100} {
+			c := Tonemap(op, [3]float32{x, x, x})
+			if c[0] < 0 || c[0] > 1 || c[0] != c[1] || c[1] != c[2] {
+				t.Fatalf("%v(%v) = %v", op, x, c)
+			}
+			if c[0] < prev {
+				t.Fatalf("%v not monotonic at %v: %v after %v", op, x, c[0], prev)
+			}
+			prev = c[0]
+		}
+		if got := Tonemap(op, [3]float32{}); got != [3]float32{} {
+			t.Errorf("%v maps black to %v", op, got)
+		}
+	}
+	if got := Tonemap(TonemapReinhard, [3]float32{reinhardWhite, reinhardWhite, reinhardWhite}); !closeRGB(got, [3]float32{1, 1, 1}, 1e-5) {
+		t.Errorf("Reinhard white point maps to %v", got)
+	}
+	if got := Tonemap(TonemapFilmic, [3]float32{hableWhite / 2, hableWhite / 2, hableWhite / 2}); !closeRGB(got, [3]float32{1, 1, 1}, 1e-5) {
+		t.Errorf("filmic white point maps to %v", got)
+	}
+	if TonemapFilmic.String() != "Filmic" || TonemapOperator(9).String() != "Unknown" {
+		t.Errorf("operator names %q, %q", TonemapFilmic, TonemapOperator(9))
+	}
+}
+
+func TestExposure(t *testing.T) {
+	// 12.5/100 cd/m^2 meters EV100 0, which exposes by 1/1.2.
+	if ev := EV100FromLuminance(0.125); abs32(ev) > 1e-5 {
+		t.Errorf("EV100 of 0.125 = %v", ev)
+	}
+	if e := ExposureFromEV100(0); abs32(e-1/1.2) > 1e-6 {
+		t.Errorf("exposure at EV100 0 = %v", e)
+	}
+	// Each doubling of luminance is one stop, halving exposure.
+	if d := EV100FromLuminance(8) - EV100FromLuminance(4); abs32(d-1) > 1e-5 {
+		t.Errorf("doubling luminance moved %v stops", d)
+	}
+
+	s := DefaultPostSettings
+	if e := s.Exposure(1000); e != 1 {
+		t.Errorf("manual exposure %v, want 1", e)
+	}
+	s.ExposureCompensation = -1
+	if e := s.Exposure(0); e != 0.5 {
+		t.Errorf("manual exposure at -1 EV = %v", e)
+	}
+	s = DefaultPostSettings
+	s.AutoExposure = true
+	// Metered scenes map their average to the same exposed value,
+	// 1 / (1.2 * 100/12.5).
+	for _, l := range []float32{0.5, 5, 50} {
+		if got := l * s.Exposure(l); abs32(got-1/9.6) > 1e-4 {
+			t.Errorf("average %v exposes to %v", l, got)
+		}
+	}
+	// Outside the EV range exposure stops following.
+	if a, b := s.Exposure(1e-9), s.Exposure(1e-8); a != b {
+		t.Errorf("exposure below MinEV100 still changing: %v vs %v", a, b)
+	}
+}
+
+func TestAdaptLuminance(t *testing.T) {
+	if got := AdaptLuminance(1, 3, 0, 2, 1); got != 1 {
+		t.Errorf("zero dt moved to %v", got)
+	}
+	want := 1 + 2*(1-float32(math.Exp(-0.5*2)))
+	if got := AdaptLuminance(1, 3, 0.5, 2, 1); abs32(got-want) > 1e-6 {
+		t.Errorf("brightening: %v, want %v", got, want)
+	}
+	want = 3 - 2*(1-float32(math.Exp(-0.5)))
+	if got := AdaptLuminance(3, 1, 0.5, 2, 1); abs32(got-want) > 1e-6 {
+		t.Errorf("darkening: %v, want %v", got, want)
+	}
+	l := float32(0.1)
+	for i := 0; i < 600; i++ {
+		l = AdaptLuminance(l, 4, 1.0/60, 3, 1)
+	}
+	if abs32(l-4) > 1e-3 {
+		t.Errorf("did not converge: %v", l)
+	}
+}
+
+func TestLogAverageLuminance(t *testing.T) {
+	// Geometric mean: one pixel at 4 and one at 1 average to 2.
+	pix := []float32{4, 4, 4, 1, 1, 1}
+	if got := LogAverageLuminance(pix); abs32(got-2) > 1e-3 {
+		t.Errorf("log average %v, want 2", got)
+	}
+	if got := Luminance([3]float32{1, 1, 1}); abs32(got-1) > 1e-6 {
+		t.Errorf("luminance of white %v", got)
+	}
+}
+
+func TestPostStack(t *testing.T) {
+	dev := NewRecordingDevice()
+	old := Device
+	Device = dev
+	defer func() { Device = old }()
+
+	if _, err := NewPostStack(320, 200); err == nil {
+		t.Fatal("created a post stack without its shaders")
+	}
+	programs := map[uint32]string{}
+	for _, name := range PostShaders {
+		sp, err := LoadShaderProgram(name, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		RegisterShaderProgram(name, sp)
+		programs[sp.ID] = name
+		defer UnregisterShaderProgram(name)
+	}
+
+	p, err := NewPostStack(320, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Begin(320, 200); err != nil {
+		t.Fatal(err)
+	}
+	s := DefaultPostSettings
+	s.AutoExposure, s.Bloom = true, true
+	p.Apply(s, 1.0/60)
+	f := dev.EndFrame()
+
+	var order []string
+	for _, d := range f.Draws {
+		order = append(order, strings.TrimSuffix(strings.TrimPrefix(programs[d.Program], "post_"), "_shader"))
+	}
+	want := []string{"luminance", "adapt"}
+	for i := 0; i < bloomLevels; i++ {
+		want = append(want, "bloom_down")
+	}
+	for i := 0; i < bloomLevels-1; i++ {
+		want = append(want, "bloom_up")
+	}
+	want = append(want, "tonemap", "fxaa")
+	if strings.Join(order, " ") != strings.Join(want, " ") {
+		t.Fatalf("passes %v, want %v", order, want)
+	}
+
+	tm, fx := f.Draws[len(f.Draws)-2], f.Draws[len(f.Draws)-1]
+	if tm.Framebuffer != p.ldr.fbo || tm.Textures[0] != p.hdr.tex || tm.Textures[1] != p.up[0].tex {
+		t.Errorf("tonemap drew to %d from %v", tm.Framebuffer, tm.Textures)
+	}
+	if tm.Uniforms["autoExposure"] != int32(1) || tm.Uniforms["useBloom"] != int32(1) {
+		t.Errorf("tonemap uniforms %v", tm.Uniforms)
+	}
+	if fx.Framebuffer != 0 || fx.Viewport != [4]int32{0, 0, 320, 200} || fx.DepthTest {
+		t.Errorf("FXAA drew to %d, viewport %v, depth %v", fx.Framebuffer, fx.Viewport, fx.DepthTest)
+	}
+	if first := f.Draws[2]; first.Uniforms["prefilter"] != int32(1) || first.Viewport != [4]int32{0, 0, 160, 100} {
+		t.Errorf("first bloom level: prefilter %v, viewport %v", first.Uniforms["prefilter"], first.Viewport)
+	}
+	if f.Draws[1].Uniforms["reset"] != int32(1) {
+		t.Error("first adaptation did not start from the metered value")
+	}
+
+	// Without bloom, FXAA or auto exposure the tonemap pass is the only one
+	// and writes to the screen; the next auto-exposed frame resets.
+	s = DefaultPostSettings
+	s.FXAA = false
+	p.Begin(640, 400)
+	p.Apply(s, 1.0/60)
+	f = dev.EndFrame()
+	if len(f.Draws) != 1 || f.Draws[0].Framebuffer != 0 || f.Draws[0].Viewport != [4]int32{0, 0, 640, 400} {
+		t.Fatalf("plain resolve: %+v", f.Draws)
+	}
+	if p.down[0].width != 320 || p.down[0].height != 200 {
+		t.Errorf("bloom level 0 is %dx%d after resize", p.down[0].width, p.down[0].height)
+	}
+	s.AutoExposure = true
+	p.Apply(s, 1.0/60)
+	if f = dev.EndFrame(); f.Draws[1].Uniforms["reset"] != int32(1) {
+		t.Error("adaptation carried over a frame without auto exposure")
+	}
+}
@@ -4,9 +4,7 @@ package engine
 import (
 	"fmt"
 	"log"
-	"strings"
 
-	"github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
@@ -27,17 +25,16 @@ type Renderer struct {
 	LocDiffuseTex int32
 	LocUseTexture int32
 
-	LocLightCount     int32
-	LocLightDir       [8]int32
-	LocLightColor     [8]int32
-	LocLightIntensity [8]int32
+	// clustered lights, read from buffer textures (see LightBuffers)
+	LocDirLightCount int32
+	LocLightBuffer   int32
+	LocClusterGrid   int32
+	LocClusterLights int32
+	LocClusterDims   int32
+	LocClusterDepth  int32
 
 	LightColor     [3]float32
 	LightIntensity float32
-	LocLightPos    [8]int32
-	LocLightRange  [8]int32
-	LocLightAngle  [8]int32
-	LocLightType   [8]int32
 
 	// new debug / normal map uniforms
 	LocNormalMap    int32
@@ -55,10 +52,17 @@ type Renderer struct {
 	ShadowProgram uint32
 
 	// uniform locations
-	LocLightSpace       int32
-	LocShadowMap        int32
-	LocShadowMapSize    int32
-	LocShadowLightIndex int32
+	LocLightSpace    int32
+	LocShadowMap     int32
+	LocShadowMapSize int32
+	// shadow atlas views, cascades and filtering (see ShadowSettings)
+	LocShadowViews        int32
+	LocCascadeSplits      int32
+	LocCascadeCount       int32
+	LocShadowFilter       int32
+	LocShadowFilterRadius int32
+	LocShadowLightSize    int32
+	LocPointShadowMaps    int32
 	// store screen size for viewport restore
 	LocOcclusionMap            int32
 	LocUseOcclusionMap         int32
@@ -73,8 +77,20 @@ type Renderer struct {
 	LocPrefilteredEnv int32
 	LocBRDFLUT        int32
 	LocUseIBL         int32
+	LocEnvIntensity   int32
+
+	// alpha mode and double-sided lighting (see AlphaMode)
+	LocAlphaMode   int32
+	LocAlphaCutoff int32
+	LocDoubleSided int32
+
+	// per-instance model matrix and colour attributes (see Instance)
+	LocUseInstancing int32
 	LocuJointMatrices [128]int32
 
+	// vertex colour attribute (see VertexColorAttrib)
+	LocUseVertexColor int32
+
 	LocClearcoatTex          int32
 	LocUseClearcoatTex       int32
 	LocClearcoatRoughTex     int32
@@ -102,103 +118,103 @@ func (r *Renderer) InitUniforms() {
 	r.LocNormalMap = -1
 	r.LocUseNormalMap = -1
 	r.LocViewPos = -1
-	r.LocLightCount = -1
+	r.LocDirLightCount = -1
+	r.LocLightBuffer = -1
+	r.LocClusterGrid = -1
+	r.LocClusterLights = -1
+	r.LocClusterDims = -1
+	r.LocClusterDepth = -1
 	r.LocLightSpace = -1
 	r.LocShadowMap = -1
 	r.LocShadowMapSize = -1
-	r.LocShadowLightIndex = -1
+	r.LocShadowViews = -1
+	r.LocCascadeSplits = -1
+	r.LocCascadeCount = -1
+	r.LocShadowFilter = -1
+	r.LocShadowFilterRadius = -1
+	r.LocShadowLightSize = -1
+	r.LocPointShadowMaps = -1
 	r.LocShowMode = -1
 	r.LocFlipNormalG = -1
-	for i := 0; i < 8; i++ {
-		r.LocLightColor[i] = -1
-		r.LocLightIntensity[i] = -1
-		r.LocLightDir[i] = -1
-		r.LocLightPos[i] = -1
-		r.LocLightRange[i] = -1
-		r.LocLightAngle[i] = -1
-		r.LocLightType[i] = -1
-	}
 
 	// Re-query for the active program
-	gl.UseProgram(r.Program)
-
-	r.LocModel = gl.GetUniformLocation(r.Program, gl.Str("model\x00"))
-	r.LocView = gl.GetUniformLocation(r.Program, gl.Str("view\x00"))
-	r.LocProj = gl.GetUniformLocation(r.Program, gl.Str("projection\x00"))
-	r.LocBaseCol = gl.GetUniformLocation(r.Program, gl.Str("BaseColor\x00"))
-	r.LocAmbient = gl.GetUniformLocation(r.Program, gl.Str("matAmbient\x00"))
-	r.LocDiffuse = gl.GetUniformLocation(r.Program, gl.Str("matDiffuse\x00"))
-	r.LocSpecular = gl.GetUniformLocation(r.Program, gl.Str("matSpecular\x00"))
-	r.LocShininess = gl.GetUniformLocation(r.Program, gl.Str("matShininess\x00"))
-
-	r.LocDiffuseTex = gl.GetUniformLocation(r.Program, gl.Str("diffuseTex\x00"))
-	r.LocUseTexture = gl.GetUniformLocation(r.Program, gl.Str("useTexture\x00"))
-	r.LocNormalMap = gl.GetUniformLocation(r.Program, gl.Str("normalMap\x00"))
-	r.LocUseNormalMap = gl.GetUniformLocation(r.Program, gl.Str("useNormalMap\x00"))
-
-	r.LocViewPos = gl.GetUniformLocation(r.Program, gl.Str("viewPos\x00"))
-
-	r.LocLightCount = gl.GetUniformLocation(r.Program, gl.Str("lightCount\x00"))
-	r.LocLightSpace = gl.GetUniformLocation(r.Program, gl.Str("lightSpaceMatrix\x00"))
-	r.LocShadowMap = gl.GetUniformLocation(r.Program, gl.Str("shadowMap\x00"))
-	r.LocShadowMapSize = gl.GetUniformLocation(r.Program, gl.Str("uShadowMapSize\x00"))
-	r.LocShadowLightIndex = gl.GetUniformLocation(r.Program, gl.Str("shadowLightIndex\x00"))
-
-	r.LocShowMode = gl.GetUniformLocation(r.Program, gl.Str("showMode\x00"))
-	r.LocFlipNormalG = gl.GetUniformLocation(r.Program, gl.Str("flipNormalGreen\x00"))
-
-	// Light arrays
-	for i := 0; i < 8; i++ {
-		nameDir := fmt.Sprintf("lightDir[%d]\x00", i)
-		nameCol := fmt.Sprintf("lightColor[%d]\x00", i)
-		nameInt := fmt.Sprintf("lightIntensity[%d]\x00", i)
-		namePos := fmt.Sprintf("lightPos[%d]\x00", i)
-		nameRange := fmt.Sprintf("lightRange[%d]\x00", i)
-		nameAngle := fmt.Sprintf("lightAngle[%d]\x00", i)
-		nameType := fmt.Sprintf("lightType[%d]\x00", i)
+	Device.UseProgram(r.Program)
+
+	r.LocModel = Device.UniformLocation(r.Program, "model")
+	r.LocView = Device.UniformLocation(r.Program, "view")
+	r.LocProj = Device.UniformLocation(r.Program, "projection")
+	r.LocBaseCol = Device.UniformLocation(r.Program, "BaseColor")
+	r.LocAmbient = Device.UniformLocation(r.Program, "matAmbient")
+	r.LocDiffuse = Device.UniformLocation(r.Program, "matDiffuse")
+	r.LocSpecular = Device.UniformLocation(r.Program, "matSpecular")
+	r.LocShininess = Device.UniformLocation(r.Program, "matShininess")
+
+	r.LocDiffuseTex = Device.UniformLocation(r.Program, "diffuseTex")
+	r.LocUseTexture = Device.UniformLocation(r.Program, "useTexture")
+	r.LocNormalMap = Device.UniformLocation(r.Program, "normalMap")
+	r.LocUseNormalMap = Device.UniformLocation(r.Program, "useNormalMap")
+
+	r.LocViewPos = Device.UniformLocation(r.Program, "viewPos")
+
+	r.LocDirLightCount = Device.UniformLocation(r.Program, "dirLightCount")
+	r.LocLightBuffer = Device.UniformLocation(r.Program, "lightBuffer")
+	r.LocClusterGrid = Device.UniformLocation(r.Program, "clusterGrid")
+	r.LocClusterLights = Device.UniformLocation(r.Program, "clusterLights")
+	r.LocClusterDims = Device.UniformLocation(r.Program, "clusterDims")
+	r.LocClusterDepth = Device.UniformLocation(r.Program, "clusterDepth")
+	r.LocLightSpace = Device.UniformLocation(r.Program, "lightSpaceMatrix")
+	r.LocShadowMap = Device.UniformLocation(r.Program, "shadowMap")
+	r.LocShadowMapSize = Device.UniformLocation(r.Program, "uShadowMapSize")
+	r.LocShadowViews = Device.UniformLocation(r.Program, "shadowViews")
+	r.LocCascadeSplits = Device.UniformLocation(r.Program, "cascadeSplits")
+	r.LocCascadeCount = Device.UniformLocation(r.Program, "cascadeCount")
+	r.LocShadowFilter = Device.UniformLocation(r.Program, "shadowFilter")
+	r.LocShadowFilterRadius = Device.UniformLocation(r.Program, "shadowFilterRadius")
+	r.LocShadowLightSize = Device.UniformLocation(r.Program, "shadowLightSize")
+	r.LocPointShadowMaps = Device.UniformLocation(r.Program, "pointShadowMaps")
+
+	r.LocShowMode = Device.UniformLocation(r.Program, "showMode")
+	r.LocFlipNormalG = Device.UniformLocation(r.Program, "flipNormalGreen")
 
-		r.LocLightDir[i] = gl.GetUniformLocation(r.Program, gl.Str(nameDir))
-		r.LocLightColor[i] = gl.GetUniformLocation(r.Program, gl.Str(nameCol))
-		r.LocLightIntensity[i] = gl.GetUniformLocation(r.Program, gl.Str(nameInt))
-		r.LocLightPos[i] = gl.GetUniformLocation(r.Program, gl.Str(namePos))
-		r.LocLightRange[i] = gl.GetUniformLocation(r.Program, gl.Str(nameRange))
-		r.LocLightAngle[i] = gl.GetUniformLocation(r.Program, gl.Str(nameAngle))
-		r.LocLightType[i] = gl.GetUniformLocation(r.Program, gl.Str(nameType))
-	}
 	// main shader will sample shadow map and receive lightSpaceMatrix
-	r.LocLightSpace = gl.GetUniformLocation(r.Program, gl.Str("lightSpaceMatrix\x00"))
-	r.LocShadowMap = gl.GetUniformLocation(r.Program, gl.Str("shadowMap\x00"))
-	r.LocShadowMapSize = gl.GetUniformLocation(r.Program, gl.Str("uShadowMapSize\x00"))
+	r.LocLightSpace = Device.UniformLocation(r.Program, "lightSpaceMatrix")
+	r.LocShadowMap = Device.UniformLocation(r.Program, "shadowMap")
+	r.LocShadowMapSize = Device.UniformLocation(r.Program, "uShadowMapSize")
 
 	//for debugging purpose
 	// renderer.InitUniforms (add these lines)
-	r.LocNormalMap = gl.GetUniformLocation(r.Program, gl.Str("normalMap\x00"))
-	r.LocUseNormalMap = gl.GetUniformLocation(r.Program, gl.Str("useNormalMap\x00"))
-	r.LocFlipNormalG = gl.GetUniformLocation(r.Program, gl.Str("flipNormalGreen\x00"))
-	r.LocShowMode = gl.GetUniformLocation(r.Program, gl.Str("showMode\x00"))
-	r.LocShadowLightIndex = gl.GetUniformLocation(r.Program, gl.Str("shadowLightIndex\x00"))
-	r.LocOcclusionMap = gl.GetUniformLocation(r.Program, gl.Str("occlusionMap\x00"))
-	r.LocUseOcclusionMap = gl.GetUniformLocation(r.Program, gl.Str("useOcclusionMap\x00"))
-
-	r.LocMetallicRoughnessMap = gl.GetUniformLocation(r.Program, gl.Str("metallicRoughnessMap\x00"))
-	r.LocUseMetallicRoughnessMap = gl.GetUniformLocation(r.Program, gl.Str("useMetallicRoughnessMap\x00"))
-
-	r.LocUVScaleBase = gl.GetUniformLocation(r.Program, gl.Str("uvScaleBase\x00"))
-	r.LocUVOffsetBase = gl.GetUniformLocation(r.Program, gl.Str("uvOffsetBase\x00"))
-	r.LocIrradianceMap = gl.GetUniformLocation(r.Program, gl.Str("irradianceMap\x00"))
-	r.LocPrefilteredEnv = gl.GetUniformLocation(r.Program, gl.Str("prefilteredEnvMap\x00"))
-	r.LocBRDFLUT = gl.GetUniformLocation(r.Program, gl.Str("brdfLUT\x00"))
-	r.LocUseIBL = gl.GetUniformLocation(r.Program, gl.Str("useIBL\x00"))
-	r.LocClearcoatTex = gl.GetUniformLocation(r.Program, gl.Str("clearcoatTex\x00"))
-	r.LocUseClearcoatTex = gl.GetUniformLocation(r.Program, gl.Str("useClearcoatTex\x00"))
-
-	r.LocClearcoatRoughTex = gl.GetUniformLocation(r.Program, gl.Str("clearcoatRoughnessTex\x00"))
-	r.LocUseClearcoatRoughTex = gl.GetUniformLocation(r.Program, gl.Str("useClearcoatRoughnessTex\x00"))
-
-	r.LocClearcoatNormalTex = gl.GetUniformLocation(r.Program, gl.Str("clearcoatNormalTex\x00"))
-	r.LocUseClearcoatNormalTex = gl.GetUniformLocation(r.Program, gl.Str("useClearcoatNormalTex\x00"))
-	r.LocTransmissionTex = gl.GetUniformLocation(r.Program, gl.Str("transmissionTex\x00"))
-	r.LocUseTransmissionTex = gl.GetUniformLocation(r.Program, gl.Str("useTransmissionTex\x00"))
+	r.LocNormalMap = Device.UniformLocation(r.Program, "normalMap")
+	r.LocUseNormalMap = Device.UniformLocation(r.Program, "useNormalMap")
+	r.LocFlipNormalG = Device.UniformLocation(r.Program, "flipNormalGreen")
+	r.LocShowMode = Device.UniformLocation(r.Program, "showMode")
+	r.LocOcclusionMap = Device.UniformLocation(r.Program, "occlusionMap")
+	r.LocUseOcclusionMap = Device.UniformLocation(r.Program, "useOcclusionMap")
+
+	r.LocMetallicRoughnessMap = Device.UniformLocation(r.Program, "metallicRoughnessMap")
+	r.LocUseMetallicRoughnessMap = Device.UniformLocation(r.Program, "useMetallicRoughnessMap")
+
+	r.LocUVScaleBase = Device.UniformLocation(r.Program, "uvScaleBase")
+	r.LocUVOffsetBase = Device.UniformLocation(r.Program, "uvOffsetBase")
+	r.LocIrradianceMap = Device.UniformLocation(r.Program, "irradianceMap")
+	r.LocPrefilteredEnv = Device.UniformLocation(r.Program, "prefilteredEnvMap")
+	r.LocBRDFLUT = Device.UniformLocation(r.Program, "brdfLUT")
+	r.LocUseIBL = Device.UniformLocation(r.Program, "useIBL")
+	r.LocEnvIntensity = Device.UniformLocation(r.Program, "envIntensity")
+	r.LocAlphaMode = Device.UniformLocation(r.Program, "alphaMode")
+	r.LocAlphaCutoff = Device.UniformLocation(r.Program, "alphaCutoff")
+	r.LocDoubleSided = Device.UniformLocation(r.Program, "doubleSided")
+	r.LocUseInstancing = Device.UniformLocation(r.Program, "useInstancing")
+	r.LocUseVertexColor = Device.UniformLocation(r.Program, "useVertexColor")
+	r.LocClearcoatTex = Device.UniformLocation(r.Program, "clearcoatTex")
+	r.LocUseClearcoatTex = Device.UniformLocation(r.Program, "useClearcoatTex")
+
+	r.LocClearcoatRoughTex = Device.UniformLocation(r.Program, "clearcoatRoughnessTex")
+	r.LocUseClearcoatRoughTex = Device.UniformLocation(r.Program, "useClearcoatRoughnessTex")
+
+	r.LocClearcoatNormalTex = Device.UniformLocation(r.Program, "clearcoatNormalTex")
+	r.LocUseClearcoatNormalTex = Device.UniformLocation(r.Program, "useClearcoatNormalTex")
+	r.LocTransmissionTex = Device.UniformLocation(r.Program, "transmissionTex")
+	r.LocUseTransmissionTex = Device.UniformLocation(r.Program, "useTransmissionTex")
 	// --- NEW: joint matrices ---
 
 	names := map[string]int32{
@@ -208,7 +224,6 @@ func (r *Renderer) InitUniforms() {
 		"matSpecular": r.LocSpecular, "matShininess": r.LocShininess,
 		"diffuseTex": r.LocDiffuseTex, "useTexture": r.LocUseTexture,
 	}
-	//log.Println("LocLightSpace =", r.LocLightSpace, "LocShadowLightIndex =", r.LocShadowLightIndex)
 
 	for n, loc := range names {
 		if loc == -1 {
@@ -293,9 +308,9 @@ func newRendererBase(width, height int) *Renderer {
 		LightIntensity: 1.0,
 	}
 
-	gl.Enable(gl.DEPTH_TEST)
-	gl.ClearColor(0.1, 0.1, 0.1, 1.0)
-	gl.Viewport(0, 0, int32(width), int32(height))
+	Device.SetDepthTest(true)
+	Device.ClearColor(0.1, 0.1, 0.1, 1.0)
+	Device.Viewport(0, 0, int32(width), int32(height))
 
 	return r
 }
@@ -311,7 +326,7 @@ func NewRenderer(vertexSrc, fragmentSrc string, width, height int) *Renderer {
 func NewRendererWithProgram(program uint32, width, height int) *Renderer {
 	r := newRendererBase(width, height)
 	r.Program = program
-	gl.UseProgram(program)
+	Device.UseProgram(program)
 	r.InitUniforms()
 	return r
 }
@@ -319,58 +334,14 @@ func NewRendererWithProgram(program uint32, width, height int) *Renderer {
 // optional: switch program on an existing renderer (for hot-reload)
 func (r *Renderer) SetProgram(program uint32) {
 	r.Program = program
-	gl.UseProgram(program)
+	Device.UseProgram(program)
 	r.InitUniforms()
 }
-func compileShader(src string, t uint32) uint32 {
-	if len(strings.TrimSpace(src)) == 0 {
-		panic("compileShader: shader source is empty or whitespace")
-	}
-
-	shader := gl.CreateShader(t)
-	csrc, free := gl.Strs(src + "\x00")
-	gl.ShaderSource(shader, 1, csrc, nil)
-	free()
-	gl.CompileShader(shader)
-
-	var status int32
-	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
-	if status == gl.FALSE {
-		var logLen int32
-		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLen)
-
-		if logLen > 1 {
-			logBuf := make([]byte, logLen)
-			gl.GetShaderInfoLog(shader, logLen, nil, &logBuf[0])
-			panic(fmt.Sprintf("shader compile error: %s", logBuf))
-		}
-		panic("shader compile error: no long info log (driver returned empty log)")
-	}
-
-	return shader
-}
-
 func compileProgram(vert, frag string) uint32 {
-	vs := compileShader(vert, gl.VERTEX_SHADER)
-	fs := compileShader(frag, gl.FRAGMENT_SHADER)
-
-	prog := gl.CreateProgram()
-	gl.AttachShader(prog, vs)
-	gl.AttachShader(prog, fs)
-	gl.LinkProgram(prog)
-
-	var status int32
-	gl.GetProgramiv(prog, gl.LINK_STATUS, &status)
-	if status == gl.FALSE {
-		var logLen int32
-		gl.GetProgramiv(prog, gl.INFO_LOG_LENGTH, &logLen)
-		log := make([]byte, logLen)
-		gl.GetProgramInfoLog(prog, logLen, nil, &log[0])
-		panic(fmt.Sprintf("program link error: %s", log))
+	prog, err := Device.CreateProgram(vert, frag)
+	if err != nil {
+		panic(err.Error())
 	}
-
-	gl.DeleteShader(vs)
-	gl.DeleteShader(fs)
 	return prog
 }
 
@@ -388,26 +359,21 @@ type DebugRenderer struct {
 func NewDebugRendererWithProg(prog uint32) *DebugRenderer {
 	dr := &DebugRenderer{
 		Program:  prog,
-		LocModel: gl.GetUniformLocation(prog, gl.Str("model\x00")),
-		LocView:  gl.GetUniformLocation(prog, gl.Str("view\x00")),
-		LocProj:  gl.GetUniformLocation(prog, gl.Str("projection\x00")),
-		LocColor: gl.GetUniformLocation(prog, gl.Str("debugColor\x00")),
+		LocModel: Device.UniformLocation(prog, "model"),
+		LocView:  Device.UniformLocation(prog, "view"),
+		LocProj:  Device.UniformLocation(prog, "projection"),
+		LocColor: Device.UniformLocation(prog, "debugColor"),
 	}
 
 	// Create VAO/VBO for a single line segment
-	gl.GenVertexArrays(1, &dr.lineVAO)
-	gl.GenBuffers(1, &dr.lineVBO)
-
-	gl.BindVertexArray(dr.lineVAO)
-	gl.BindBuffer(gl.ARRAY_BUFFER, dr.lineVBO)
+	dr.lineVAO = Device.CreateVertexArray()
+	Device.BindVertexArray(dr.lineVAO)
 
 	// allocate space for 2 vec3 positions (start + end)
-	gl.BufferData(gl.ARRAY_BUFFER, 6*4, nil, gl.DYNAMIC_DRAW)
+	dr.lineVBO = Device.CreateBuffer(ArrayBuffer, 6*4, nil, DynamicDraw)
+	Device.VertexAttrib(0, 3, Float, false, 3*4, 0)
 
-	gl.EnableVertexAttribArray(0)
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, 3*4, 0)
-
-	gl.BindVertexArray(0)
+	Device.BindVertexArray(0)
 
 	return dr
 }
@@ -443,14 +409,14 @@ func NewDebugRenderer(vertexSrc, fragmentSrc string) *DebugRenderer {
 	return NewDebugRendererWithProg(prog)
 }
 func (dr *DebugRenderer) DrawLine(start, end mgl32.Vec3, color mgl32.Vec3, view, proj mgl32.Mat4) {
-	gl.UseProgram(dr.Program)
+	Device.UseProgram(dr.Program)
 
 	// Upload uniforms
 	model := mgl32.Ident4()
-	gl.UniformMatrix4fv(dr.LocView, 1, false, &view[0])
-	gl.UniformMatrix4fv(dr.LocProj, 1, false, &proj[0])
-	gl.UniformMatrix4fv(dr.LocModel, 1, false, &model[0])
-	gl.Uniform3fv(dr.LocColor, 1, &color[0])
+	Device.UniformMat4(dr.LocView, view[:])
+	Device.UniformMat4(dr.LocProj, proj[:])
+	Device.UniformMat4(dr.LocModel, model[:])
+	Device.UniformVec3(dr.LocColor, color[:])
 
 	// Upload line vertices
 	verts := []float32{
@@ -458,12 +424,11 @@ func (dr *DebugRenderer) DrawLine(start, end mgl32.Vec3, color mgl32.Vec3, view,
 		end.X(), end.Y(), end.Z(),
 	}
 
-	gl.BindBuffer(gl.ARRAY_BUFFER, dr.lineVBO)
-	gl.BufferSubData(gl.ARRAY_BUFFER, 0, len(verts)*4, gl.Ptr(verts))
+	Device.UpdateBuffer(ArrayBuffer, dr.lineVBO, 0, len(verts)*4, verts)
 
-	gl.BindVertexArray(dr.lineVAO)
-	gl.DrawArrays(gl.LINES, 0, 2)
-	gl.BindVertexArray(0)
+	Device.BindVertexArray(dr.lineVAO)
+	Device.DrawArrays(Lines, 0, 2)
+	Device.BindVertexArray(0)
 }
 
 // Create depth-only FBO + texture and compile shadow shader program.
@@ -472,29 +437,7 @@ func (r *Renderer) InitShadow(shadowVertSrc, shadowFragSrc string, width, height
 	r.ShadowWidth = width
 	r.ShadowHeight = height
 
-	// create depth texture
-	var tex uint32
-	gl.GenTextures(1, &tex)
-	gl.BindTexture(gl.TEXTURE_2D, tex)
-	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.DEPTH_COMPONENT, int32(width), int32(height), 0, gl.DEPTH_COMPONENT, gl.FLOAT, nil)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_BORDER)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_BORDER)
-	border := []float32{1.0, 1.0, 1.0, 1.0}
-	gl.TexParameterfv(gl.TEXTURE_2D, gl.TEXTURE_BORDER_COLOR, &border[0])
-
-	// create FBO
-	var fbo uint32
-	gl.GenFramebuffers(1, &fbo)
-	gl.BindFramebuffer(gl.FRAMEBUFFER, fbo)
-	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.TEXTURE_2D, tex, 0)
-	gl.DrawBuffer(gl.NONE)
-	gl.ReadBuffer(gl.NONE)
-	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
-
-	r.ShadowFBO = fbo
-	r.ShadowTex = tex
+	r.ShadowFBO, r.ShadowTex = createShadowTarget(width, height)
 
 	// compile shadow shader program
 	r.ShadowProgram = compileProgram(shadowVertSrc, shadowFragSrc)
@@ -509,29 +452,7 @@ func (r *Renderer) InitShadowWithProgram(program uint32, width, height int) {
 	r.ShadowWidth = width
 	r.ShadowHeight = height
 
-	// create depth texture
-	var tex uint32
-	gl.GenTextures(1, &tex)
-	gl.BindTexture(gl.TEXTURE_2D, tex)
-	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.DEPTH_COMPONENT, int32(width), int32(height), 0, gl.DEPTH_COMPONENT, gl.FLOAT, nil)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_BORDER)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_BORDER)
-	border := []float32{1.0, 1.0, 1.0, 1.0}
-	gl.TexParameterfv(gl.TEXTURE_2D, gl.TEXTURE_BORDER_COLOR, &border[0])
-
-	// create FBO
-	var fbo uint32
-	gl.GenFramebuffers(1, &fbo)
-	gl.BindFramebuffer(gl.FRAMEBUFFER, fbo)
-	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.TEXTURE_2D, tex, 0)
-	gl.DrawBuffer(gl.NONE)
-	gl.ReadBuffer(gl.NONE)
-	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
-
-	r.ShadowFBO = fbo
-	r.ShadowTex = tex
+	r.ShadowFBO, r.ShadowTex = createShadowTarget(width, height)
 
 	// compile shadow shader program
 	r.ShadowProgram = program
@@ -542,6 +463,25 @@ func (r *Renderer) InitShadowWithProgram(program uint32, width, height int) {
 	// But store a location name for convenience (we'll get it from main program in InitUniforms)
 }
 
+// createShadowTarget creates a depth texture that samples as 1 (lit)
+// outside its border, attached to a depth-only framebuffer.
+func createShadowTarget(width, height int) (fbo, tex uint32) {
+	tex = Device.CreateTexture2D(TextureDesc{
+		Width:  width,
+		Height: height,
+		Format: Depth,
+		Filter: FilterNearest,
+		Wrap:   WrapClampToBorder,
+		Border: [4]float32{1.0, 1.0, 1.0, 1.0},
+	}, nil)
+
+	fbo, err := Device.CreateFramebuffer(FramebufferDesc{DepthTexture: tex})
+	if err != nil {
+		log.Printf("shadow target: %v", err)
+	}
+	return fbo, tex
+}
+
 // engine/renderer.go
 func (r *Renderer) SwitchProgram(p *ShaderProgram) {
 	if p == nil {
@@ -4,8 +4,6 @@ import (
 	"fmt"
 	"os"
 	"strings"
-
-	"github.com/go-gl/gl/v4.1-core/gl"
 )
 
 type ShaderProgram struct {
@@ -15,52 +13,52 @@ type ShaderProgram struct {
 }
 
 func LoadShaderProgram(name, vertSrc, fragSrc string) (*ShaderProgram, error) {
-	vs := compileShader(vertSrc, gl.VERTEX_SHADER)
-	fs := compileShader(fragSrc, gl.FRAGMENT_SHADER)
-
-	prog, err := buildProgram(vs, fs)
+	prog, err := Device.CreateProgram(vertSrc, fragSrc)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("shader %q: %w", name, err)
 	}
 
-	gl.DeleteShader(vs)
-	gl.DeleteShader(fs)
-
 	sp := &ShaderProgram{
 		ID:       prog,
 		Uniforms: map[string]int32{},
 	}
 
 	// --- NEW: detect MaterialBlock ---
-	blockIndex := gl.GetUniformBlockIndex(prog, gl.Str("MaterialBlock\x00"))
-	sp.HasMaterialBlock = blockIndex != gl.INVALID_INDEX
+	sp.HasMaterialBlock = Device.HasUniformBlock(prog, "MaterialBlock")
 
 	return sp, nil
 }
 func (sp *ShaderProgram) Reload(vertSrc, fragSrc string) error {
-	newVS := compileShader(vertSrc, gl.VERTEX_SHADER)
-	newFS := compileShader(fragSrc, gl.FRAGMENT_SHADER)
-	newProg, err := buildProgram(newVS, newFS)
+	newProg, err := Device.CreateProgram(vertSrc, fragSrc)
 	if err != nil {
 		return err
 	}
 
-	gl.DeleteShader(newVS)
-	gl.DeleteShader(newFS)
-
 	// Delete old program
-	gl.DeleteProgram(sp.ID)
+	Device.DeleteProgram(sp.ID)
 
 	sp.ID = newProg
 	sp.Uniforms = map[string]int32{}
 
 	// Re-detect UBO block
-	blockIndex := gl.GetUniformBlockIndex(sp.ID, gl.Str("MaterialBlock\x00"))
-	sp.HasMaterialBlock = blockIndex != gl.INVALID_INDEX
+	sp.HasMaterialBlock = Device.HasUniformBlock(sp.ID, "MaterialBlock")
 
 	return nil
 }
 
+// Loc returns the location of a uniform, caching it until the next Reload.
+func (sp *ShaderProgram) Loc(name string) int32 {
+	if loc, ok := sp.Uniforms[name]; ok {
+		return loc
+	}
+	if sp.Uniforms == nil {
+		sp.Uniforms = map[string]int32{}
+	}
+	loc := Device.UniformLocation(sp.ID, name)
+	sp.Uniforms[name] = loc
+	return loc
+}
+
 // global registry of compiled shader programs
 var shaderPrograms = map[string]*ShaderProgram{}
 
@@ -69,6 +67,12 @@ func RegisterShaderProgram(name string, prog *ShaderProgram) {
 	shaderPrograms[name] = prog
 }
 
+// UnregisterShaderProgram removes a program from the registry without
+// deleting it.
+func UnregisterShaderProgram(name string) {
+	delete(shaderPrograms, name)
+}
+
 // GetShaderProgram retrieves a compiled shader program by name.
 func GetShaderProgram(name string) (*ShaderProgram, error) {
 	p, ok := shaderPrograms[name]
@@ -87,27 +91,8 @@ func MustGetShaderProgram(name string) *ShaderProgram {
 	return p
 }
 
-func buildProgram(vs, fs uint32) (uint32, error) {
-	prog := gl.CreateProgram()
-	gl.AttachShader(prog, vs)
-	gl.AttachShader(prog, fs)
-	gl.LinkProgram(prog)
-
-	var status int32
-	gl.GetProgramiv(prog, gl.LINK_STATUS, &status)
-	if status == gl.FALSE {
-		var logLen int32
-		gl.GetProgramiv(prog, gl.INFO_LOG_LENGTH, &logLen)
-		logBuf := make([]byte, logLen+1)
-		gl.GetProgramInfoLog(prog, logLen, nil, &logBuf[0])
-		gl.DeleteProgram(prog)
-		return 0, fmt.Errorf("program link error: %s", string(logBuf))
-	}
-	return prog, nil
-}
-
 // LoadShaderSource reads a shader file, strips a UTF-8 BOM if present,
-// normalizes CRLF to LF, and returns a null-terminated string suitable for gl.Strs.
+// normalizes CRLF to LF, and returns a null-terminated string.
 func LoadShaderSource(path string) (string, error) {
 	b, err := os.ReadFile(path)
 	if err != nil {
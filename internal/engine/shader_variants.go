@@ -0,0 +1,154 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ShaderVariantSource is the GLSL of one shader variant.
+type ShaderVariantSource struct {
+	Vertex, Fragment string
+	// MapError, if set, rewrites compile errors of this source, e.g. to
+	// name the files behind #line source-string numbers.
+	MapError func(error) error
+}
+
+// ShaderVariantBuilder assembles the source of a variant from its
+// keywords, given in ShaderVariantKey order.
+type ShaderVariantBuilder func(keywords []string) (ShaderVariantSource, error)
+
+type shaderVariantSet struct {
+	keywords []string // declared
+	build    ShaderVariantBuilder
+	programs map[string]*ShaderProgram // by variant key
+}
+
+var shaderVariants = map[string]*shaderVariantSet{}
+
+// RegisterShaderVariants declares the keywords of shader name and how to
+// build its variants. Registering again (after its source changed) keeps
+// the variants compiled so far: ReloadShaderVariants rebuilds them in
+// place, so programs held by materials stay valid.
+func RegisterShaderVariants(name string, keywords []string, build ShaderVariantBuilder) {
+	set := shaderVariants[name]
+	if set == nil {
+		set = &shaderVariantSet{programs: map[string]*ShaderProgram{}}
+		shaderVariants[name] = set
+	}
+	set.keywords = append([]string(nil), keywords...)
+	set.build = build
+}
+
+// UnregisterShaderVariants forgets the variants of shader name without
+// deleting their programs.
+func UnregisterShaderVariants(name string) {
+	delete(shaderVariants, name)
+}
+
+// ShaderVariantKey is the cache key of a keyword set: the keywords sorted,
+// without duplicates, joined by "+". The base variant's key is "".
+func ShaderVariantKey(keywords []string) string {
+	return strings.Join(variantKeywords(keywords), "+")
+}
+
+func variantKeywords(keywords []string) []string {
+	k := append([]string(nil), keywords...)
+	sort.Strings(k)
+	return slices.Compact(k)
+}
+
+// GetShaderVariant returns shader name compiled with keywords enabled,
+// building and caching it on first use. Keywords the shader does not
+// declare are an error. Without keywords it returns the base variant, or
+// for shaders without variants the program registered under name.
+func GetShaderVariant(name string, keywords ...string) (*ShaderProgram, error) {
+	set := shaderVariants[name]
+	if set == nil {
+		if len(keywords) == 0 {
+			return GetShaderProgram(name)
+		}
+		return nil, fmt.Errorf("shader %q has no variants", name)
+	}
+	kw := variantKeywords(keywords)
+	key := strings.Join(kw, "+")
+	if sp, ok := set.programs[key]; ok {
+		return sp, nil
+	}
+	for _, k := range kw {
+		if !slices.Contains(set.keywords, k) {
+			return nil, fmt.Errorf("shader %q does not declare keyword %q", name, k)
+		}
+	}
+
+	src, err := set.build(kw)
+	if err != nil {
+		return nil, fmt.Errorf("shader %q variant [%s]: %w", name, key, err)
+	}
+	sp, err := LoadShaderProgram(variantName(name, key), src.Vertex, src.Fragment)
+	if err != nil {
+		return nil, src.mapError(err)
+	}
+	set.programs[key] = sp
+	return sp, nil
+}
+
+// ShaderVariantKeys lists the compiled variants of shader name, sorted.
+func ShaderVariantKeys(name string) []string {
+	set := shaderVariants[name]
+	if set == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(set.programs))
+	for k := range set.programs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ReloadShaderVariants rebuilds every compiled variant of shader name in
+// place. A variant that fails keeps its old program; the first error is
+// returned after trying them all.
+func ReloadShaderVariants(name string) error {
+	set := shaderVariants[name]
+	if set == nil {
+		return fmt.Errorf("shader %q has no variants", name)
+	}
+	var first error
+	for _, key := range ShaderVariantKeys(name) {
+		var kw []string
+		if key != "" {
+			kw = strings.Split(key, "+")
+		}
+		err := func() error {
+			src, err := set.build(kw)
+			if err != nil {
+				return err
+			}
+			if err := set.programs[key].Reload(src.Vertex, src.Fragment); err != nil {
+				return src.mapError(err)
+			}
+			return nil
+		}()
+		if err != nil && first == nil {
+			first = fmt.Errorf("shader %q variant [%s]: %w", name, key, err)
+		}
+	}
+	return first
+}
+
+func (s ShaderVariantSource) mapError(err error) error {
+	if s.MapError == nil {
+		return err
+	}
+	return s.MapError(err)
+}
+
+func variantName(name, key string) string {
+	if key == "" {
+		return name
+	}
+	return name + "[" + key + "]"
+}
@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestShaderVariants(t *testing.T) {
+	old := Device
+	Device = NewRecordingDevice()
+	defer func() { Device = old }()
+
+	var builds []string
+	build := func(keywords []string) (ShaderVariantSource, error) {
+		key := strings.Join(keywords, "+")
+		builds = append(builds, key)
+		if key == "BROKEN" {
+			return ShaderVariantSource{}, errors.New("no such file")
+		}
+		return ShaderVariantSource{Vertex: "v " + key, Fragment: "f " + key}, nil
+	}
+	RegisterShaderVariants("lit", []string{"SKINNED", "FOG", "BROKEN"}, build)
+	defer UnregisterShaderVariants("lit")
+
+	base, err := GetShaderVariant("lit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	both, err := GetShaderVariant("lit", "SKINNED", "FOG", "SKINNED")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := GetShaderVariant("lit", "FOG", "SKINNED")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != both || base == both {
+		t.Error("variants are not cached by keyword set")
+	}
+	if strings.Join(builds, ",") != ",FOG+SKINNED" {
+		t.Errorf("built %q, want each variant once", builds)
+	}
+	if got := strings.Join(ShaderVariantKeys("lit"), ","); got != ",FOG+SKINNED" {
+		t.Errorf("keys %q", got)
+	}
+
+	if _, err := GetShaderVariant("lit", "SHINY"); err == nil {
+		t.Error("compiled an undeclared keyword")
+	}
+	if _, err := GetShaderVariant("lit", "BROKEN"); err == nil || !strings.Contains(err.Error(), "[BROKEN]") {
+		t.Errorf("failed build: %v", err)
+	}
+	if _, err := GetShaderVariant("plain", "FOG"); err == nil {
+		t.Error("keywords on a shader without variants")
+	}
+
+	id := both.ID
+	builds = nil
+	if err := ReloadShaderVariants("lit"); err != nil {
+		t.Fatal(err)
+	}
+	if both.ID == id {
+		t.Error("reload kept the old program")
+	}
+	if p, _ := GetShaderVariant("lit", "SKINNED", "FOG"); p != both {
+		t.Error("reload replaced the cached program")
+	}
+	if strings.Join(builds, ",") != ",FOG+SKINNED" {
+		t.Errorf("reload built %q", builds)
+	}
+}
+
+func TestShaderVariants_BaseFallback(t *testing.T) {
+	sp := &ShaderProgram{ID: 7}
+	RegisterShaderProgram("plain", sp)
+	defer UnregisterShaderProgram("plain")
+	if got, err := GetShaderVariant("plain"); err != nil || got != sp {
+		t.Errorf("GetShaderVariant = %v, %v; want the registered program", got, err)
+	}
+}
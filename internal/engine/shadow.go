@@ -1,6 +1,10 @@
 package engine
 
-import "github.com/go-gl/mathgl/mgl32"
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
 
 // ComputeDirectionalLightSpaceMatrix computes an orthographic light-space matrix.
 // lightDir is world-space direction (pointing from light toward scene, e.g. {0,-1,0}).
@@ -22,3 +26,212 @@ func ComputeDirectionalLightSpaceMatrix(lightDir mgl32.Vec3, sceneCenter mgl32.V
 
 	return lightProj.Mul4(lightView)
 }
+
+// ShadowFilter selects how the lit shaders sample the shadow atlas.
+type ShadowFilter int32
+
+const (
+	ShadowHard ShadowFilter = iota // one depth comparison
+	ShadowPCF                      // 5x5 percentage-closer filter
+	ShadowPCSS                     // PCF widened by the blocker distance
+)
+
+// MaxCascades is the most cascades a directional light can have; the
+// shaders receive the split depths as a vec4.
+const MaxCascades = 4
+
+// ShadowSettings configure how shadow-casting lights are laid out in the
+// shadow atlas and filtered.
+type ShadowSettings struct {
+	// Cascades splits the camera frustum for the shadowed directional
+	// light, up to MaxCascades. SplitLambda blends uniform (0) and
+	// logarithmic (1) split distances; cascades end at MaxDistance or the
+	// camera's far plane, whichever is nearer.
+	Cascades    int
+	SplitLambda float32
+	MaxDistance float32
+	// Stabilize fits each cascade to a bounding sphere and snaps it to
+	// whole texels, so shadow edges do not shimmer as the camera moves.
+	Stabilize bool
+	// CasterDistance keeps casters this far towards the light from a
+	// cascade in its depth range.
+	CasterDistance float32
+
+	// Atlas tile sizes in texels. Tiles shrink when the atlas runs out of
+	// room.
+	CascadeResolution int
+	SpotResolution    int
+	// Point lights shadow into cube depth maps of PointResolution texels
+	// per face instead of the atlas. PointShadows caps how many, nearest
+	// the camera first.
+	PointResolution int
+	PointShadows    int
+
+	Filter       ShadowFilter
+	FilterRadius float32 // PCF kernel radius in texels
+	LightSize    float32 // PCSS light size as a fraction of a tile
+}
+
+var DefaultShadowSettings = ShadowSettings{
+	Cascades:          4,
+	SplitLambda:       0.75,
+	MaxDistance:       100,
+	Stabilize:         true,
+	CasterDistance:    50,
+	CascadeResolution: 1024,
+	SpotResolution:    1024,
+	PointResolution:   512,
+	PointShadows:      4,
+	Filter:            ShadowPCF,
+	FilterRadius:      1.5,
+	LightSize:         0.02,
+}
+
+// CascadeSplits returns the far distance of each of n cascades covering
+// near..far, using the practical split scheme: a lambda-weighted blend of
+// logarithmic and uniform splits.
+func CascadeSplits(near, far float32, n int, lambda float32) []float32 {
+	splits := make([]float32, n)
+	for i := 1; i <= n; i++ {
+		p := float64(i) / float64(n)
+		log := float64(near) * math.Pow(float64(far/near), p)
+		uni := float64(near) + float64(far-near)*p
+		splits[i-1] = float32(float64(lambda)*log + float64(1-lambda)*uni)
+	}
+	splits[n-1] = far
+	return splits
+}
+
+// frustumSliceCorners returns the world-space corners of the part of a
+// perspective camera's frustum between view depths near and far.
+func frustumSliceCorners(view, proj mgl32.Mat4, near, far float32) [8]mgl32.Vec3 {
+	inv := proj.Mul4(view).Inv()
+	// NDC depth of a point d units in front of the camera.
+	ndcZ := func(d float32) float32 { return (-proj[10]*d + proj[14]) / d }
+	var corners [8]mgl32.Vec3
+	i := 0
+	for _, z := range [2]float32{ndcZ(near), ndcZ(far)} {
+		for _, y := range [2]float32{-1, 1} {
+			for _, x := range [2]float32{-1, 1} {
+				p := inv.Mul4x1(mgl32.Vec4{x, y, z, 1})
+				corners[i] = p.Vec3().Mul(1 / p.W())
+				i++
+			}
+		}
+	}
+	return corners
+}
+
+// lookAtDir is LookAtV from eye along dir, with an up vector that cannot
+// be parallel to dir.
+func lookAtDir(eye, dir mgl32.Vec3) mgl32.Mat4 {
+	up := mgl32.Vec3{0, 1, 0}
+	if d := dir.Normalize(); math.Abs(float64(d.Y())) > 0.99 {
+		up = mgl32.Vec3{0, 0, 1}
+	}
+	return mgl32.LookAtV(eye, eye.Add(dir), up)
+}
+
+// FitCascade returns the light-space matrix of a directional light
+// (lightDir points from the light into the scene) that covers the camera
+// frustum between view depths near and far. resolution is the cascade's
+// tile size in texels, used to snap stabilised cascades to the texel grid.
+func FitCascade(view, proj mgl32.Mat4, lightDir mgl32.Vec3, near, far float32, resolution int, stabilize bool, casterDistance float32) mgl32.Mat4 {
+	corners := frustumSliceCorners(view, proj, near, far)
+	dir := lightDir.Normalize()
+
+	if stabilize {
+		var center mgl32.Vec3
+		for _, c := range corners {
+			center = center.Add(c)
+		}
+		center = center.Mul(1.0 / 8)
+		var radius float32
+		for _, c := range corners {
+			radius = max(radius, c.Sub(center).Len())
+		}
+		// Quantise so float noise in the corners cannot change the scale.
+		radius = float32(math.Ceil(float64(radius)*16) / 16)
+
+		eye := center.Sub(dir.Mul(radius + casterDistance))
+		lightView := lookAtDir(eye, dir)
+		lightProj := mgl32.Ortho(-radius, radius, -radius, radius, 0, 2*radius+casterDistance)
+
+		// Move the projection so the world origin lands on a texel
+		// corner; the whole grid then only ever moves by whole texels.
+		m := lightProj.Mul4(lightView)
+		half := float32(resolution) / 2
+		o := m.Mul4x1(mgl32.Vec4{0, 0, 0, 1})
+		ox, oy := o.X()*half, o.Y()*half
+		lightProj[12] += (float32(math.Round(float64(ox))) - ox) / half
+		lightProj[13] += (float32(math.Round(float64(oy))) - oy) / half
+		return lightProj.Mul4(lightView)
+	}
+
+	lightView := lookAtDir(mgl32.Vec3{}, dir)
+	b := EmptyAABB()
+	for _, c := range corners {
+		b = b.Extend(TransformPoint(lightView, c))
+	}
+	// The light looks down -Z: the box's Max.Z side faces the light.
+	lightProj := mgl32.Ortho(b.Min[0], b.Max[0], b.Min[1], b.Max[1], -b.Max[2]-casterDistance, -b.Min[2])
+	return lightProj.Mul4(lightView)
+}
+
+// SpotShadowMatrix is the light-space matrix of a spot light with the
+// given half-angle in degrees.
+func SpotShadowMatrix(pos, dir mgl32.Vec3, angle, rng float32) mgl32.Mat4 {
+	fov := min(2*angle, 179) * (math.Pi / 180)
+	proj := mgl32.Perspective(fov, 1, shadowNear(rng), rng)
+	return proj.Mul4(lookAtDir(pos, dir))
+}
+
+// Cube faces in the order the shaders pick them: +X, -X, +Y, -Y, +Z, -Z.
+var cubeFaces = [6]struct{ dir, up mgl32.Vec3 }{
+	{mgl32.Vec3{1, 0, 0}, mgl32.Vec3{0, -1, 0}},
+	{mgl32.Vec3{-1, 0, 0}, mgl32.Vec3{0, -1, 0}},
+	{mgl32.Vec3{0, 1, 0}, mgl32.Vec3{0, 0, 1}},
+	{mgl32.Vec3{0, -1, 0}, mgl32.Vec3{0, 0, -1}},
+	{mgl32.Vec3{0, 0, 1}, mgl32.Vec3{0, -1, 0}},
+	{mgl32.Vec3{0, 0, -1}, mgl32.Vec3{0, -1, 0}},
+}
+
+// PointShadowMatrices returns the six 90-degree light-space matrices of a
+// point light's cube shadow, one per face. A point is shadowed by the face
+// of its major axis relative to the light.
+func PointShadowMatrices(pos mgl32.Vec3, rng float32) [6]mgl32.Mat4 {
+	proj := mgl32.Perspective(math.Pi/2, 1, shadowNear(rng), rng)
+	var m [6]mgl32.Mat4
+	for i, f := range cubeFaces {
+		m[i] = proj.Mul4(mgl32.LookAtV(pos, pos.Add(f.dir), f.up))
+	}
+	return m
+}
+
+// CubeFace returns the face of PointShadowMatrices that covers d, the
+// offset from the light.
+func CubeFace(d mgl32.Vec3) int {
+	ax, ay, az := abs32(d.X()), abs32(d.Y()), abs32(d.Z())
+	switch {
+	case ax >= ay && ax >= az:
+		if d.X() > 0 {
+			return 0
+		}
+		return 1
+	case ay >= az:
+		if d.Y() > 0 {
+			return 2
+		}
+		return 3
+	}
+	if d.Z() > 0 {
+		return 4
+	}
+	return 5
+}
+
+// PointShadowRange returns the near and far planes of PointShadowMatrices
+// for a light of range rng.
+func PointShadowRange(rng float32) (near, far float32) { return shadowNear(rng), rng }
+
+func shadowNear(rng float32) float32 { return max(0.05, rng*0.005) }
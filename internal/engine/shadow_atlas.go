@@ -0,0 +1,121 @@
+package engine
+
+import "github.com/go-gl/mathgl/mgl32"
+
+// ShadowTile is a square region of the shadow atlas, in texels.
+type ShadowTile struct {
+	X, Y, Size int
+}
+
+// Rect returns the tile as (x, y, width, height) in atlas UV space.
+func (t ShadowTile) Rect(atlasSize int) [4]float32 {
+	s := float32(atlasSize)
+	return [4]float32{float32(t.X) / s, float32(t.Y) / s, float32(t.Size) / s, float32(t.Size) / s}
+}
+
+// ShadowAtlas hands out power-of-two tiles of one square depth texture.
+// Larger free tiles are split into quarters on demand; the atlas is
+// cleared with Reset at the start of every frame.
+type ShadowAtlas struct {
+	Size int
+	free map[int][]ShadowTile // by tile size, in allocation order
+}
+
+func NewShadowAtlas(size int) *ShadowAtlas {
+	a := &ShadowAtlas{Size: size}
+	a.Reset()
+	return a
+}
+
+// Reset makes the whole atlas free again.
+func (a *ShadowAtlas) Reset() {
+	a.free = map[int][]ShadowTile{a.Size: {{Size: a.Size}}}
+}
+
+// Allocate returns a free tile of size texels, which must be a power of two
+// no larger than the atlas, or false when none is left.
+func (a *ShadowAtlas) Allocate(size int) (ShadowTile, bool) {
+	s := size
+	for s <= a.Size && len(a.free[s]) == 0 {
+		s *= 2
+	}
+	if s > a.Size {
+		return ShadowTile{}, false
+	}
+	for s > size {
+		t := a.pop(s)
+		h := s / 2
+		a.free[h] = append(a.free[h],
+			ShadowTile{t.X, t.Y, h}, ShadowTile{t.X + h, t.Y, h},
+			ShadowTile{t.X, t.Y + h, h}, ShadowTile{t.X + h, t.Y + h, h})
+		s = h
+	}
+	return a.pop(size), true
+}
+
+// AllocateN allocates n tiles of the same size, starting at size texels
+// and halving down to minSize until all n fit. It returns nil when they
+// do not fit even at minSize.
+func (a *ShadowAtlas) AllocateN(n, size, minSize int) []ShadowTile {
+	for ; size >= minSize; size /= 2 {
+		tiles := make([]ShadowTile, 0, n)
+		for len(tiles) < n {
+			t, ok := a.Allocate(size)
+			if !ok {
+				break
+			}
+			tiles = append(tiles, t)
+		}
+		if len(tiles) == n {
+			return tiles
+		}
+		for _, t := range tiles {
+			a.Free(t)
+		}
+	}
+	return nil
+}
+
+// Free returns a tile to the atlas. Freed quarters are not merged back
+// into larger tiles until the next Reset.
+func (a *ShadowAtlas) Free(t ShadowTile) {
+	a.free[t.Size] = append([]ShadowTile{t}, a.free[t.Size]...)
+}
+
+func (a *ShadowAtlas) pop(size int) ShadowTile {
+	t := a.free[size][0]
+	a.free[size] = a.free[size][1:]
+	return t
+}
+
+// ShadowView is one depth render into the shadow atlas, or, for a point
+// light, into Face of cube CubeLayer of the point shadow cube maps.
+type ShadowView struct {
+	Matrix mgl32.Mat4 // world to light clip space
+	Tile   ShadowTile
+
+	Cube      bool
+	CubeLayer int
+	Face      int
+	Near, Far float32 // depth range of a cube face
+}
+
+// shadowViewTexels is the number of RGBA32F texels PackShadowViews writes
+// per view.
+const shadowViewTexels = 5
+
+// PackShadowViews lays views out as the shaders' shadowViews buffer
+// expects: the four matrix columns, then the tile's atlas rect, or for a
+// cube face its near and far planes and cube layer.
+func PackShadowViews(dst []float32, views []ShadowView, atlasSize int) []float32 {
+	dst = dst[:0]
+	for _, v := range views {
+		r := v.Tile.Rect(atlasSize)
+		if v.Cube {
+			r = [4]float32{v.Near, v.Far, float32(v.CubeLayer), 0}
+		}
+		dst = append(dst, v.Matrix[:]...)
+		dst = append(dst, r[:]...)
+	}
+	return dst
+}
@@ -0,0 +1,38 @@
+package engine
+
+// PointShadowMaps are the cube depth maps point lights shadow into: a cube
+// array of Layers cubes, Size texels per face, and a depth-only
+// framebuffer per cube face (six per layer, in PointShadowMatrices order).
+type PointShadowMaps struct {
+	Size, Layers int
+	Tex          uint32
+	FBOs         []uint32
+}
+
+// NewPointShadowMaps creates layers cube depth maps of size texels per
+// face on Device.
+func NewPointShadowMaps(size, layers int) (*PointShadowMaps, error) {
+	p := &PointShadowMaps{Size: size, Layers: layers}
+	p.Tex = Device.CreateDepthCubeArray(size, layers)
+	for i := 0; i < 6*layers; i++ {
+		fbo, err := Device.CreateFramebuffer(FramebufferDesc{DepthCubeArray: p.Tex, DepthLayer: i})
+		p.FBOs = append(p.FBOs, fbo)
+		if err != nil {
+			p.Delete()
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// FBO returns the framebuffer rendering into face of cube layer.
+func (p *PointShadowMaps) FBO(layer, face int) uint32 { return p.FBOs[6*layer+face] }
+
+// Delete releases the cube array and its framebuffers.
+func (p *PointShadowMaps) Delete() {
+	for _, fbo := range p.FBOs {
+		Device.DeleteFramebuffer(fbo)
+	}
+	Device.DeleteTexture(p.Tex)
+	p.FBOs, p.Tex = nil, 0
+}
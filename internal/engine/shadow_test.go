@@ -0,0 +1,178 @@
+package engine
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func insideClip(m mgl32.Mat4, p mgl32.Vec3, eps float32) bool {
+	c := m.Mul4x1(p.Vec4(1))
+	for i := 0; i < 3; i++ {
+		if v := c[i] / c.W(); v < -1-eps || v > 1+eps {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCascadeSplits(t *testing.T) {
+	uni := CascadeSplits(1, 101, 4, 0)
+	for i, want := range []float32{26, 51, 76, 101} {
+		if math.Abs(float64(uni[i]-want)) > 1e-3 {
+			t.Fatalf("uniform splits %v", uni)
+		}
+	}
+	log := CascadeSplits(1, 1000, 3, 1)
+	for i, want := range []float32{10, 100, 1000} {
+		if math.Abs(float64(log[i]-want)) > 1e-2 {
+			t.Fatalf("logarithmic splits %v", log)
+		}
+	}
+	mixed := CascadeSplits(0.1, 100, 4, 0.75)
+	for i := 1; i < len(mixed); i++ {
+		if mixed[i] <= mixed[i-1] {
+			t.Fatalf("splits not increasing: %v", mixed)
+		}
+	}
+}
+
+func TestFitCascade_CoversSlice(t *testing.T) {
+	view := mgl32.LookAtV(mgl32.Vec3{3, 2, 8}, mgl32.Vec3{0, 0, 0}, mgl32.Vec3{0, 1, 0})
+	proj := mgl32.Perspective(mgl32.DegToRad(60), 16.0/9, 0.1, 200)
+	lightDir := mgl32.Vec3{-0.3, -1, -0.4}
+	for _, stabilize := range []bool{false, true} {
+		splits := CascadeSplits(0.1, 100, 4, 0.75)
+		near := float32(0.1)
+		for i, far := range splits {
+			m := FitCascade(view, proj, lightDir, near, far, 1024, stabilize, 50)
+			for _, c := range frustumSliceCorners(view, proj, near, far) {
+				if !insideClip(m, c, 1e-3) {
+					t.Fatalf("stabilize=%v cascade %d: corner %v outside the light volume", stabilize, i, c)
+				}
+			}
+			// A caster between the light and the slice must still render.
+			mid := frustumSliceCorners(view, proj, near, far)[0]
+			if !insideClip(m, mid.Sub(lightDir.Normalize().Mul(40)), 1e-3) {
+				t.Errorf("stabilize=%v cascade %d: caster towards the light clipped", stabilize, i)
+			}
+			near = far
+		}
+	}
+}
+
+func TestFitCascade_StableUnderCameraMotion(t *testing.T) {
+	proj := mgl32.Perspective(mgl32.DegToRad(60), 1, 0.1, 100)
+	lightDir := mgl32.Vec3{0.2, -1, 0.1}
+	const res = 1024
+
+	var scale float32
+	for i := 0; i < 20; i++ {
+		// Move and turn the camera a little every frame.
+		a := float32(i) * 0.13
+		eye := mgl32.Vec3{float32(i) * 0.037, 1, float32(i) * 0.051}
+		view := mgl32.LookAtV(eye, eye.Add(mgl32.Vec3{float32(math.Sin(float64(a))), 0, -float32(math.Cos(float64(a)))}), mgl32.Vec3{0, 1, 0})
+		m := FitCascade(view, proj, lightDir, 0.1, 10, res, true, 50)
+
+		if i == 0 {
+			scale = m[0]*m[0] + m[4]*m[4] + m[8]*m[8]
+		} else if s := m[0]*m[0] + m[4]*m[4] + m[8]*m[8]; math.Abs(float64(s-scale)) > 1e-6 {
+			t.Fatalf("frame %d: cascade scale changed from %v to %v", i, scale, s)
+		}
+		// The world origin must sit on a texel corner.
+		o := m.Mul4x1(mgl32.Vec4{0, 0, 0, 1})
+		for _, v := range []float32{o.X(), o.Y()} {
+			texel := float64(v) * res / 2
+			if math.Abs(texel-math.Round(texel)) > 1e-2 {
+				t.Fatalf("frame %d: origin at texel %v, not snapped", i, texel)
+			}
+		}
+	}
+}
+
+func TestPointShadowMatrices_FaceCoversMajorAxis(t *testing.T) {
+	pos := mgl32.Vec3{1, 2, 3}
+	faces := PointShadowMatrices(pos, 10)
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 500; i++ {
+		d := mgl32.Vec3{rng.Float32()*2 - 1, rng.Float32()*2 - 1, rng.Float32()*2 - 1}.Normalize().Mul(0.5 + rng.Float32()*9)
+		if !insideClip(faces[CubeFace(d)], pos.Add(d), 1e-4) {
+			t.Fatalf("offset %v: outside face %d", d, CubeFace(d))
+		}
+	}
+}
+
+// The lit shaders rebuild a point's cube face depth from its major axis
+// and the packed near and far planes; check that against the face matrix.
+func TestPointShadowRange_MatchesFaceDepth(t *testing.T) {
+	pos := mgl32.Vec3{1, 2, 3}
+	faces := PointShadowMatrices(pos, 10)
+	n, f := PointShadowRange(10)
+	rng := rand.New(rand.NewSource(3))
+	for i := 0; i < 100; i++ {
+		d := mgl32.Vec3{rng.Float32()*2 - 1, rng.Float32()*2 - 1, rng.Float32()*2 - 1}.Normalize().Mul(0.5 + rng.Float32()*9)
+		p := faces[CubeFace(d)].Mul4x1(pos.Add(d).Vec4(1))
+		z := max(abs32(d.X()), abs32(d.Y()), abs32(d.Z()))
+		want := ((f+n)/(f-n)-2*f*n/((f-n)*z))*0.5 + 0.5
+		if got := p.Z()/p.W()*0.5 + 0.5; abs32(got-want) > 1e-4 {
+			t.Fatalf("offset %v: face depth %v, rebuilt %v", d, got, want)
+		}
+	}
+}
+
+func TestSpotShadowMatrix(t *testing.T) {
+	pos, dir := mgl32.Vec3{0, 5, 0}, mgl32.Vec3{0, -1, 0} // straight down
+	m := SpotShadowMatrix(pos, dir, 30, 20)
+	if !insideClip(m, mgl32.Vec3{0, 0, 0}, 0) {
+		t.Error("point on the axis outside the spot frustum")
+	}
+	if insideClip(m, mgl32.Vec3{10, 0, 0}, 0) {
+		t.Error("point outside the cone inside the spot frustum")
+	}
+}
+
+func TestShadowAtlas(t *testing.T) {
+	a := NewShadowAtlas(4096)
+	var tiles []ShadowTile
+	for _, size := range []int{1024, 1024, 1024, 1024, 2048, 512, 512} {
+		tile, ok := a.Allocate(size)
+		if !ok {
+			t.Fatalf("allocating %d failed after %v", size, tiles)
+		}
+		tiles = append(tiles, tile)
+	}
+	last, ok := a.Allocate(2048)
+	if !ok {
+		t.Fatal("the last free quarter was not allocated")
+	}
+	tiles = append(tiles, last)
+	if _, ok := a.Allocate(2048); ok {
+		t.Fatal("allocated a fifth quarter")
+	}
+	for i, x := range tiles {
+		if x.X < 0 || x.Y < 0 || x.X+x.Size > 4096 || x.Y+x.Size > 4096 {
+			t.Fatalf("tile %v outside atlas", x)
+		}
+		for _, y := range tiles[i+1:] {
+			if x.X < y.X+y.Size && y.X < x.X+x.Size && x.Y < y.Y+y.Size && y.Y < x.Y+x.Size {
+				t.Fatalf("tiles %v and %v overlap", x, y)
+			}
+		}
+	}
+
+	// Six faces shrink until they fit in what is left.
+	faces := a.AllocateN(6, 1024, 128)
+	if len(faces) != 6 || faces[0].Size != 512 {
+		t.Fatalf("AllocateN gave %v", faces)
+	}
+
+	a.Reset()
+	if tile, ok := a.Allocate(4096); !ok || tile != (ShadowTile{Size: 4096}) {
+		t.Fatalf("after Reset got %v, %v", tile, ok)
+	}
+	if got := (ShadowTile{X: 1024, Y: 2048, Size: 512}).Rect(4096); got != [4]float32{0.25, 0.5, 0.125, 0.125} {
+		t.Fatalf("Rect = %v", got)
+	}
+}
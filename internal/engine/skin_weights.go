@@ -0,0 +1,131 @@
+package engine
+
+import "fmt"
+
+// MaxJointInfluences is the number of joints a skinned vertex can reference.
+// It matches the uvec4/vec4 aJoints/aWeights attributes in the skinned shader.
+const MaxJointInfluences = 4
+
+// CapJointInfluences merges one or more JOINTS_n/WEIGHTS_n sets into a
+// single set of MaxJointInfluences per vertex. The strongest influences are
+// kept and the weights are renormalised to sum to 1. A vertex with no weight
+// at all is bound fully to its first joint so it doesn't collapse to the origin.
+func CapJointInfluences(jointSets [][][4]uint16, weightSets [][][4]float32) ([][4]uint16, [][4]float32) {
+	if len(jointSets) == 0 || len(weightSets) == 0 {
+		return nil, nil
+	}
+	count := len(jointSets[0])
+	js := make([][4]uint16, count)
+	ws := make([][4]float32, count)
+
+	for v := 0; v < count; v++ {
+		var topJ [MaxJointInfluences]uint16
+		var topW [MaxJointInfluences]float32
+
+		for set := range jointSets {
+			if set >= len(weightSets) || v >= len(jointSets[set]) || v >= len(weightSets[set]) {
+				continue
+			}
+			for k := 0; k < 4; k++ {
+				insertInfluence(&topJ, &topW, jointSets[set][v][k], weightSets[set][v][k])
+			}
+		}
+
+		sum := topW[0] + topW[1] + topW[2] + topW[3]
+		if sum <= 0 {
+			topJ = [4]uint16{jointSets[0][v][0]}
+			topW = [4]float32{1}
+			sum = 1
+		}
+		for k := range topW {
+			topW[k] /= sum
+		}
+		js[v], ws[v] = topJ, topW
+	}
+	return js, ws
+}
+
+// insertInfluence keeps topW sorted strongest first. Weights for a joint
+// already present are accumulated (exporters sometimes split one joint
+// across sets).
+func insertInfluence(topJ *[4]uint16, topW *[4]float32, j uint16, w float32) {
+	if w <= 0 {
+		return
+	}
+	for k := range topW {
+		if topW[k] > 0 && topJ[k] == j {
+			w += topW[k]
+			copy(topJ[k:], topJ[k+1:])
+			copy(topW[k:], topW[k+1:])
+			topJ[3], topW[3] = 0, 0
+			break
+		}
+	}
+	for k := range topW {
+		if w > topW[k] {
+			copy(topJ[k+1:], topJ[k:3])
+			copy(topW[k+1:], topW[k:3])
+			topJ[k], topW[k] = j, w
+			return
+		}
+	}
+}
+
+func decodeJoints(a AccessorData) ([][4]uint16, error) {
+	js := make([][4]uint16, a.Acc.Count)
+	for i := range js {
+		off := a.Base + i*a.Stride
+		switch a.Acc.ComponentType {
+		case 5121: // UNSIGNED_BYTE
+			js[i] = [4]uint16{
+				uint16(a.Buf[off+0]),
+				uint16(a.Buf[off+1]),
+				uint16(a.Buf[off+2]),
+				uint16(a.Buf[off+3]),
+			}
+		case 5123: // UNSIGNED_SHORT
+			js[i] = [4]uint16{
+				uint16(a.Buf[off+0]) | uint16(a.Buf[off+1])<<8,
+				uint16(a.Buf[off+2]) | uint16(a.Buf[off+3])<<8,
+				uint16(a.Buf[off+4]) | uint16(a.Buf[off+5])<<8,
+				uint16(a.Buf[off+6]) | uint16(a.Buf[off+7])<<8,
+			}
+		default:
+			return nil, fmt.Errorf("unsupported JOINTS component type: %d", a.Acc.ComponentType)
+		}
+	}
+	return js, nil
+}
+
+func decodeWeights(a AccessorData) ([][4]float32, error) {
+	ws := make([][4]float32, a.Acc.Count)
+	for i := range ws {
+		off := a.Base + i*a.Stride
+		switch a.Acc.ComponentType {
+		case 5126: // FLOAT
+			ws[i] = [4]float32{
+				BytesToFloat32(a.Buf[off+0:]),
+				BytesToFloat32(a.Buf[off+4:]),
+				BytesToFloat32(a.Buf[off+8:]),
+				BytesToFloat32(a.Buf[off+12:]),
+			}
+		case 5121: // UNSIGNED_BYTE (normalized)
+			ws[i] = [4]float32{
+				float32(a.Buf[off+0]) / 255.0,
+				float32(a.Buf[off+1]) / 255.0,
+				float32(a.Buf[off+2]) / 255.0,
+				float32(a.Buf[off+3]) / 255.0,
+			}
+		case 5123: // UNSIGNED_SHORT (normalized)
+			ws[i] = [4]float32{
+				float32(uint16(a.Buf[off+0])|uint16(a.Buf[off+1])<<8) / 65535.0,
+				float32(uint16(a.Buf[off+2])|uint16(a.Buf[off+3])<<8) / 65535.0,
+				float32(uint16(a.Buf[off+4])|uint16(a.Buf[off+5])<<8) / 65535.0,
+				float32(uint16(a.Buf[off+6])|uint16(a.Buf[off+7])<<8) / 65535.0,
+			}
+		default:
+			return nil, fmt.Errorf("unsupported WEIGHTS component type: %d", a.Acc.ComponentType)
+		}
+	}
+	return ws, nil
+}
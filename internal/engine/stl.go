@@ -0,0 +1,152 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// DecodeSTL reads an ASCII or binary STL into 12-float MeshData. Facets
+// keep their own vertices so CAD edges stay sharp; a facet whose stored
+// normal is zero gets one from its winding. STL has no texture
+// coordinates, so the generated tangents only give normal mapping a stable
+// frame.
+func DecodeSTL(id, path string) (MeshData, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return MeshData{}, err
+	}
+
+	var facets [][4][3]float32 // normal, then three corners
+	if isBinarySTL(data) {
+		facets = readBinarySTL(data)
+	} else {
+		facets, err = readASCIISTL(path, data)
+	}
+	if err != nil {
+		return MeshData{}, err
+	}
+	if len(facets) == 0 {
+		return MeshData{}, fmt.Errorf("%s: no facets", path)
+	}
+
+	vertices := make([]float32, 0, len(facets)*3*12)
+	indices := make([]uint32, 0, len(facets)*3)
+	for _, f := range facets {
+		n := f[0]
+		if n == [3]float32{} || hasNaN(n) {
+			n = faceNormal(f[1], f[2], f[3])
+		}
+		for _, p := range f[1:] {
+			indices = append(indices, uint32(len(vertices)/12))
+			vertices = append(vertices, p[0], p[1], p[2], n[0], n[1], n[2], 0, 0, 0, 0, 0, 1)
+		}
+	}
+	computeTangents(vertices, indices)
+	return MeshData{ID: id, Vertices: vertices, Indices: indices, HasTangents: true}, nil
+}
+
+// isBinarySTL tells the formats apart by size: a binary file is exactly
+// an 80-byte header, a facet count and 50 bytes per facet. Checking the
+// "solid" prefix alone is not enough, as some exporters write it into
+// binary headers too.
+func isBinarySTL(data []byte) bool {
+	if len(data) < 84 {
+		return false
+	}
+	n := binary.LittleEndian.Uint32(data[80:84])
+	return uint64(len(data)) == 84+50*uint64(n)
+}
+
+// readBinarySTL reads the 50-byte facet records: 12 floats followed by an
+// attribute count that is ignored.
+func readBinarySTL(data []byte) [][4][3]float32 {
+	n := int(binary.LittleEndian.Uint32(data[80:84]))
+	facets := make([][4][3]float32, n)
+	for i := range facets {
+		rec := data[84+50*i:]
+		for j := 0; j < 12; j++ {
+			facets[i][j/3][j%3] = math.Float32frombits(binary.LittleEndian.Uint32(rec[4*j:]))
+		}
+	}
+	return facets
+}
+
+func readASCIISTL(path string, data []byte) ([][4][3]float32, error) {
+	var facets [][4][3]float32
+	var cur [4][3]float32
+	corners := 0
+	inFacet := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.ToLower(fields[0]) {
+		case "solid", "endsolid", "outer", "endloop":
+		case "facet":
+			if len(fields) != 5 || strings.ToLower(fields[1]) != "normal" {
+				return nil, lineError(path, lineNo, "want \"facet normal nx ny nz\"")
+			}
+			v, err := parseFloats(fields[2:])
+			if err != nil {
+				return nil, lineError(path, lineNo, "facet normal: %v", err)
+			}
+			cur, corners, inFacet = [4][3]float32{{v[0], v[1], v[2]}}, 0, true
+		case "vertex":
+			if !inFacet {
+				return nil, lineError(path, lineNo, "vertex outside a facet")
+			}
+			if corners == 3 {
+				return nil, lineError(path, lineNo, "facet has more than 3 vertices")
+			}
+			if len(fields) != 4 {
+				return nil, lineError(path, lineNo, "vertex needs 3 coordinates, got %d", len(fields)-1)
+			}
+			v, err := parseFloats(fields[1:])
+			if err != nil {
+				return nil, lineError(path, lineNo, "vertex: %v", err)
+			}
+			corners++
+			cur[corners] = [3]float32{v[0], v[1], v[2]}
+		case "endfacet":
+			if !inFacet || corners != 3 {
+				return nil, lineError(path, lineNo, "facet has %d vertices, want 3", corners)
+			}
+			facets = append(facets, cur)
+			inFacet = false
+		default:
+			return nil, lineError(path, lineNo, "unexpected %q", fields[0])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+	}
+	if inFacet {
+		return nil, fmt.Errorf("%s: unterminated facet", path)
+	}
+	return facets, nil
+}
+
+func faceNormal(a, b, c [3]float32) [3]float32 {
+	ux, uy, uz := b[0]-a[0], b[1]-a[1], b[2]-a[2]
+	vx, vy, vz := c[0]-a[0], c[1]-a[1], c[2]-a[2]
+	n := [3]float32{uy*vz - uz*vy, uz*vx - ux*vz, ux*vy - uy*vx}
+	l := float32(math.Sqrt(float64(n[0]*n[0] + n[1]*n[1] + n[2]*n[2])))
+	if l == 0 {
+		return [3]float32{0, 1, 0}
+	}
+	return [3]float32{n[0] / l, n[1] / l, n[2] / l}
+}
+
+func hasNaN(v [3]float32) bool {
+	return v[0] != v[0] || v[1] != v[1] || v[2] != v[2]
+}
@@ -0,0 +1,150 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const asciiSTL = `solid tri
+  facet normal 0 0 0
+    outer loop
+      vertex 0 0 0
+      vertex 1 0 0
+      vertex 0 1 0
+    endloop
+  endfacet
+endsolid tri
+`
+
+func TestSTL_ASCIIAndBinaryMatch(t *testing.T) {
+	var bin bytes.Buffer
+	bin.Write(make([]byte, 80))
+	binary.Write(&bin, binary.LittleEndian, uint32(1))
+	binary.Write(&bin, binary.LittleEndian, [12]float32{0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 1, 0})
+	binary.Write(&bin, binary.LittleEndian, uint16(0))
+	dir := writeFiles(t, map[string]string{"a.stl": asciiSTL, "b.stl": bin.String()})
+
+	a, err := DecodeSTL("tri", filepath.Join(dir, "a.stl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := DecodeSTL("tri", filepath.Join(dir, "b.stl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Fatalf("ascii and binary differ:\n%+v\n%+v", a, b)
+	}
+	// The zero stored normal is replaced by the winding normal.
+	if n := a.Vertices[3:6]; n[0] != 0 || n[1] != 0 || n[2] != 1 {
+		t.Fatalf("normal = %v", n)
+	}
+	if len(a.Indices) != 3 || !a.HasTangents {
+		t.Fatalf("mesh = %+v", a)
+	}
+
+	bad := strings.Replace(asciiSTL, "vertex 1 0 0", "vertex 1 0", 1)
+	dir = writeFiles(t, map[string]string{"bad.stl": bad})
+	if _, err := DecodeSTL("bad", filepath.Join(dir, "bad.stl")); err == nil || !strings.Contains(err.Error(), "bad.stl:5:") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestPLY_ColorsAndGeneratedNormals(t *testing.T) {
+	header := `ply
+format %s 1.0
+comment made by hand
+element vertex 4
+property float x
+property float y
+property float z
+property uchar red
+property uchar green
+property uchar blue
+element face 1
+property list uchar int vertex_indices
+end_header
+`
+	ascii := strings.Replace(header, "%s", "ascii", 1) + `0 0 0 255 0 0
+1 0 0 0 255 0
+1 1 0 0 0 255
+0 1 0 255 255 255
+4 0 1 2 3
+`
+	var bin bytes.Buffer
+	bin.WriteString(strings.Replace(header, "%s", "binary_big_endian", 1))
+	pos := [][3]float32{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}}
+	rgb := [][3]byte{{255, 0, 0}, {0, 255, 0}, {0, 0, 255}, {255, 255, 255}}
+	for i := range pos {
+		binary.Write(&bin, binary.BigEndian, pos[i])
+		bin.Write(rgb[i][:])
+	}
+	bin.WriteByte(4)
+	binary.Write(&bin, binary.BigEndian, []int32{0, 1, 2, 3})
+	dir := writeFiles(t, map[string]string{"a.ply": ascii, "b.ply": bin.String()})
+
+	a, err := DecodePLY("quad", filepath.Join(dir, "a.ply"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := DecodePLY("quad", filepath.Join(dir, "b.ply"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Fatalf("ascii and binary differ:\n%+v\n%+v", a, b)
+	}
+	if !reflect.DeepEqual(a.Indices, []uint32{0, 1, 2, 0, 2, 3}) {
+		t.Fatalf("indices = %v", a.Indices)
+	}
+	if a.Colors[1] != [4]float32{0, 1, 0, 1} || a.Colors[3] != [4]float32{1, 1, 1, 1} {
+		t.Fatalf("colors = %v", a.Colors)
+	}
+	for v := 0; v < 4; v++ {
+		n := a.Vertices[v*12+3 : v*12+6]
+		if math.Abs(float64(n[2]-1)) > 1e-6 {
+			t.Fatalf("vertex %d normal = %v", v, n)
+		}
+	}
+
+	bad := strings.Replace(ascii, "1 1 0 0 0 255", "1 1 0 0 0", 1)
+	dir = writeFiles(t, map[string]string{"bad.ply": bad})
+	if _, err := DecodePLY("bad", filepath.Join(dir, "bad.ply")); err == nil || !strings.Contains(err.Error(), "bad.ply:16: vertex 2 blue") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestPLY_BadListCount(t *testing.T) {
+	header := `ply
+format binary_little_endian 1.0
+element vertex 3
+property float x
+property float y
+property float z
+element face 1
+property list %s int vertex_indices
+end_header
+`
+	cases := []struct {
+		name, countType string
+		count           any
+	}{
+		{"negative", "char", int8(-1)},
+		{"huge", "uint", uint32(1 << 31)},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		buf.WriteString(strings.Replace(header, "%s", c.countType, 1))
+		binary.Write(&buf, binary.LittleEndian, make([]float32, 9))
+		binary.Write(&buf, binary.LittleEndian, c.count)
+		dir := writeFiles(t, map[string]string{"bad.ply": buf.String()})
+		if _, err := DecodePLY("bad", filepath.Join(dir, "bad.ply")); err == nil || !strings.Contains(err.Error(), "vertex_indices count") {
+			t.Errorf("%s count: err = %v", c.name, err)
+		}
+	}
+}
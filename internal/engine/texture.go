@@ -9,8 +9,6 @@ import (
 	"strings"
 
 	"golang.org/x/image/webp"
-
-	"github.com/go-gl/gl/v4.1-core/gl"
 )
 
 // LoadTextureWithColorSpace loads an image and creates an OpenGL texture.
@@ -26,16 +24,11 @@ func LoadTextureWithColorSpace(path string, srgb bool) (uint32, error) {
 	return LoadTexture(path) // temporary fallback if you can't change now
 }
 
-func DeleteTexture(tex uint32) {
-	if tex != 0 {
-		gl.DeleteTextures(1, &tex)
-	}
-}
-
-func LoadTexture(path string) (uint32, error) {
+// DecodeImageFile decodes a PNG, JPEG or WebP file into RGBA8 pixels.
+func DecodeImageFile(path string) (*image.RGBA, error) {
 	imgFile, err := os.Open(path)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 	defer imgFile.Close()
 
@@ -46,7 +39,7 @@ func LoadTexture(path string) (uint32, error) {
 	if strings.HasSuffix(strings.ToLower(path), ".webp") {
 		img, err = webp.Decode(imgFile)
 		if err != nil {
-			return 0, fmt.Errorf("webp decode failed: %w", err)
+			return nil, fmt.Errorf("webp decode failed: %w", err)
 		}
 		format = "webp"
 	} else {
@@ -60,7 +53,7 @@ func LoadTexture(path string) (uint32, error) {
 
 			img, err = png.Decode(imgFile)
 			if err != nil {
-				return 0, fmt.Errorf("decode failed: %w", err)
+				return nil, fmt.Errorf("decode failed: %w", err)
 			}
 			format = "png"
 		}
@@ -71,25 +64,56 @@ func LoadTexture(path string) (uint32, error) {
 	// Convert to RGBA
 	rgba := image.NewRGBA(img.Bounds())
 	draw.Draw(rgba, rgba.Bounds(), img, image.Point{}, draw.Src)
+	return rgba, nil
+}
+
+func DeleteTexture(tex uint32) {
+	if tex != 0 {
+		Device.DeleteTexture(tex)
+		forgetTextureBytes(tex)
+	}
+}
 
-	// Upload to GL
-	var tex uint32
-	gl.GenTextures(1, &tex)
-	gl.BindTexture(gl.TEXTURE_2D, tex)
-	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+func LoadTexture(path string) (uint32, error) {
+	rgba, err := DecodeImageFile(path)
+	if err != nil {
+		return 0, err
+	}
+	tex := UploadTextureRGBA(0, rgba)
+	fmt.Printf("Loaded texture %s -> GL id %d (%dx%d)\n", path, tex, rgba.Rect.Dx(), rgba.Rect.Dy())
+	return tex, nil
+}
 
-	w := int32(rgba.Rect.Dx())
-	h := int32(rgba.Rect.Dy())
-	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, w, h, 0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(rgba.Pix))
+// UploadTextureRGBA uploads rgba with a generated mip chain into tex, or
+// into a new texture if tex is 0, and returns the texture.
+func UploadTextureRGBA(tex uint32, rgba *image.RGBA) uint32 {
+	w, h := rgba.Rect.Dx(), rgba.Rect.Dy()
+	desc := TextureDesc{Width: w, Height: h, Format: RGBA8, Filter: FilterLinear, Wrap: WrapRepeat}
+	if tex == 0 {
+		tex = Device.CreateTexture2D(desc, nil)
+	}
+	Device.UploadTexture2D(tex, desc, rgba.Pix)
+	Device.GenerateMipmaps(tex)
 
-	gl.GenerateMipmap(gl.TEXTURE_2D)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR_MIPMAP_LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.REPEAT)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.REPEAT)
+	recordTextureBytes(tex, mipChainBytes(w, h))
+	return tex
+}
 
-	gl.BindTexture(gl.TEXTURE_2D, 0)
+// NewPlaceholderTexture creates a 1x1 white texture that an asynchronous
+// load later fills in place with UploadTextureRGBA or UploadCookedTexture.
+func NewPlaceholderTexture() uint32 {
+	white := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	copy(white.Pix, []byte{255, 255, 255, 255})
+	return UploadTextureRGBA(0, white)
+}
 
-	fmt.Printf("Loaded texture %s -> GL id %d (%dx%d)\n", path, tex, w, h)
-	return tex, nil
+// ReloadTexture decodes path into the existing texture object tex, so
+// materials holding the GL ID pick up the new pixels without rebinding.
+func ReloadTexture(tex uint32, path string) error {
+	rgba, err := DecodeImageFile(path)
+	if err != nil {
+		return err
+	}
+	UploadTextureRGBA(tex, rgba)
+	return nil
 }
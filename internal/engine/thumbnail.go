@@ -11,7 +11,6 @@ import (
 	"math"
 	"runtime"
 
-	"github.com/go-gl/gl/v4.2-core/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
 )
 
@@ -213,10 +212,10 @@ func (tr *ThumbnailRenderer) glThread() {
 
 	tr.win.MakeContextCurrent()
 
-	// gl.Init is safe to call once per context; main has already called it,
+	// Init is safe to call once per context; main has already called it,
 	// but calling again here is harmless in go-gl.
-	if err := gl.Init(); err != nil {
-		log.Printf("thumbnail: gl.Init failed: %v", err)
+	if err := Device.Init(); err != nil {
+		log.Printf("thumbnail: device init failed: %v", err)
 		return
 	}
 
@@ -250,37 +249,26 @@ func (tr *ThumbnailRenderer) glThread() {
 // --- FBO / program setup (same as before, but used only on GL thread) ---
 
 func (tr *ThumbnailRenderer) initFBO() {
-	gl.GenFramebuffers(1, &tr.fbo)
-	gl.BindFramebuffer(gl.FRAMEBUFFER, tr.fbo)
-
-	// color texture
-	gl.GenTextures(1, &tr.colorTex)
-	gl.BindTexture(gl.TEXTURE_2D, tr.colorTex)
-	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, int32(tr.width), int32(tr.height), 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
-	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, tr.colorTex, 0)
-
-	// depth renderbuffer
-	gl.GenRenderbuffers(1, &tr.depthRb)
-	gl.BindRenderbuffer(gl.RENDERBUFFER, tr.depthRb)
-	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH_COMPONENT24, int32(tr.width), int32(tr.height))
-	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.RENDERBUFFER, tr.depthRb)
-
-	attachments := []uint32{gl.COLOR_ATTACHMENT0}
-	gl.DrawBuffers(1, &attachments[0])
-	gl.ReadBuffer(gl.COLOR_ATTACHMENT0)
-
-	status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER)
-	if status != gl.FRAMEBUFFER_COMPLETE {
-		log.Printf("Thumbnail FBO incomplete: 0x%X", status)
+	tr.colorTex = Device.CreateTexture2D(tr.colorDesc(), nil)
+	tr.depthRb = Device.CreateRenderbuffer(Depth24, tr.width, tr.height)
+
+	fbo, err := Device.CreateFramebuffer(FramebufferDesc{Color: tr.colorTex, DepthRenderbuffer: tr.depthRb})
+	tr.fbo = fbo
+	if err != nil {
+		log.Printf("Thumbnail FBO incomplete: %v", err)
 	} else {
 		log.Printf("Thumbnail FBO complete, colorTex=%d depthRb=%d", tr.colorTex, tr.depthRb)
 	}
+}
 
-	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+func (tr *ThumbnailRenderer) colorDesc() TextureDesc {
+	return TextureDesc{
+		Width:  tr.width,
+		Height: tr.height,
+		Format: RGBA8,
+		Filter: FilterLinear,
+		Wrap:   WrapClampToEdge,
+	}
 }
 
 func (tr *ThumbnailRenderer) ensureFBOSize(size int) {
@@ -293,45 +281,27 @@ func (tr *ThumbnailRenderer) ensureFBOSize(size int) {
 	tr.width = size
 	tr.height = size
 
-	gl.BindFramebuffer(gl.FRAMEBUFFER, tr.fbo)
-
-	gl.BindTexture(gl.TEXTURE_2D, tr.colorTex)
-	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, int32(tr.width), int32(tr.height), 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
-
-	gl.BindRenderbuffer(gl.RENDERBUFFER, tr.depthRb)
-	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH_COMPONENT24, int32(tr.width), int32(tr.height))
-
-	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	Device.ResizeTexture2D(tr.colorTex, tr.colorDesc())
+	Device.ResizeRenderbuffer(tr.depthRb, Depth24, tr.width, tr.height)
 }
 
 func (tr *ThumbnailRenderer) initProgram() error {
-	vs, err := thumbCompileShader(thumbVertexShaderSrc, gl.VERTEX_SHADER)
-	if err != nil {
-		return fmt.Errorf("compile thumbnail vertex shader: %w", err)
-	}
-	fs, err := thumbCompileShader(thumbFragmentShaderSrc, gl.FRAGMENT_SHADER)
+	prog, err := Device.CreateProgram(thumbVertexShaderSrc, thumbFragmentShaderSrc)
 	if err != nil {
-		gl.DeleteShader(vs)
-		return fmt.Errorf("compile thumbnail fragment shader: %w", err)
-	}
-	prog, err := linkProgram(vs, fs)
-	gl.DeleteShader(vs)
-	gl.DeleteShader(fs)
-	if err != nil {
-		return fmt.Errorf("link thumbnail program: %w", err)
+		return fmt.Errorf("thumbnail program: %w", err)
 	}
 
 	tr.program = prog
-	tr.locModel = gl.GetUniformLocation(prog, gl.Str("uModel\x00"))
-	tr.locView = gl.GetUniformLocation(prog, gl.Str("uView\x00"))
-	tr.locProj = gl.GetUniformLocation(prog, gl.Str("uProj\x00"))
-	tr.locBaseCol = gl.GetUniformLocation(prog, gl.Str("uBaseColor\x00"))
-	tr.locUseTex = gl.GetUniformLocation(prog, gl.Str("uUseTexture\x00"))
-	tr.locDiffuse = gl.GetUniformLocation(prog, gl.Str("uDiffuseTex\x00"))
+	tr.locModel = Device.UniformLocation(prog, "uModel")
+	tr.locView = Device.UniformLocation(prog, "uView")
+	tr.locProj = Device.UniformLocation(prog, "uProj")
+	tr.locBaseCol = Device.UniformLocation(prog, "uBaseColor")
+	tr.locUseTex = Device.UniformLocation(prog, "uUseTexture")
+	tr.locDiffuse = Device.UniformLocation(prog, "uDiffuseTex")
 
-	gl.UseProgram(tr.program)
-	gl.Uniform1i(tr.locDiffuse, 0)
-	gl.UseProgram(0)
+	Device.UseProgram(tr.program)
+	Device.UniformInt(tr.locDiffuse, 0)
+	Device.UseProgram(0)
 
 	return nil
 }
@@ -406,27 +376,22 @@ func (tr *ThumbnailRenderer) renderOne(meshID string, size int) ([]byte, string,
 
 	tr.ensureFBOSize(size)
 
-	var vp [4]int32
-	gl.GetIntegerv(gl.VIEWPORT, &vp[0])
-
-	gl.BindFramebuffer(gl.FRAMEBUFFER, tr.fbo)
-	gl.ReadBuffer(gl.COLOR_ATTACHMENT0)
+	vp := Device.CurrentViewport()
 
-	var fb int32
-	gl.GetIntegerv(gl.FRAMEBUFFER_BINDING, &fb)
+	Device.BindFramebuffer(tr.fbo)
 
-	gl.Viewport(0, 0, int32(size), int32(size))
+	Device.Viewport(0, 0, int32(size), int32(size))
 
-	gl.Disable(gl.BLEND)
-	gl.Enable(gl.DEPTH_TEST)
-	gl.DepthFunc(gl.LESS)
+	Device.SetBlend(false)
+	Device.SetDepthTest(true)
+	Device.SetDepthFunc(DepthLess)
 
-	gl.ClearColor(0.0, 0.0, 0.0, 1.0)
-	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	Device.ClearColor(0.0, 0.0, 0.0, 1.0)
+	Device.Clear(ClearColorBit | ClearDepthBit)
 
-	gl.Finish()
+	Device.Finish()
 	test := make([]uint8, 4)
-	gl.ReadPixels(0, 0, 1, 1, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(test))
+	Device.ReadPixels(0, 0, 1, 1, test)
 
 	vao := tr.mm.GetVAO(meshID)
 
@@ -441,12 +406,12 @@ func (tr *ThumbnailRenderer) renderOne(meshID string, size int) ([]byte, string,
 	ebo := tr.mm.GetEBO(meshID)
 
 	if vao == 0 {
-		gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
-		gl.Viewport(vp[0], vp[1], vp[2], vp[3])
+		Device.BindFramebuffer(0)
+		Device.Viewport(vp[0], vp[1], vp[2], vp[3])
 		return nil, "", fmt.Errorf("mesh %s has VAO=0", meshID)
 	}
 
-	gl.UseProgram(tr.program)
+	Device.UseProgram(tr.program)
 
 	_ = [16]float32{
 		0.5, 0, 0, 0,
@@ -468,31 +433,30 @@ func (tr *ThumbnailRenderer) renderOne(meshID string, size int) ([]byte, string,
 
 	model := scale(0.60) // was 0.8
 
-	gl.UniformMatrix4fv(tr.locModel, 1, false, &model[0])
-	gl.UniformMatrix4fv(tr.locView, 1, false, &view[0])
-	gl.UniformMatrix4fv(tr.locProj, 1, false, &proj[0])
+	Device.UniformMat4(tr.locModel, model[:])
+	Device.UniformMat4(tr.locView, view[:])
+	Device.UniformMat4(tr.locProj, proj[:])
 
 	base := [4]float32{1, 1, 1, 1}
-	gl.Uniform4fv(tr.locBaseCol, 1, &base[0])
-	gl.Uniform1i(tr.locUseTex, 0)
+	Device.UniformVec4(tr.locBaseCol, base[:])
+	Device.UniformInt(tr.locUseTex, 0)
 
-	gl.BindVertexArray(vao)
+	Device.BindVertexArray(vao)
 	if count > 0 && ebo != 0 {
-		gl.DrawElements(gl.TRIANGLES, count, indexType, gl.PtrOffset(0))
+		Device.DrawIndexed(Triangles, count, indexType)
 	} else {
-		gl.DrawArrays(gl.TRIANGLES, 0, vertexCount)
+		Device.DrawArrays(Triangles, 0, vertexCount)
 	}
-	gl.BindVertexArray(0)
+	Device.BindVertexArray(0)
 
-	gl.Finish()
+	Device.Finish()
 
 	buf := make([]uint8, size*size*4)
-	gl.BindFramebuffer(gl.FRAMEBUFFER, tr.fbo)
-	gl.ReadBuffer(gl.COLOR_ATTACHMENT0)
-	gl.ReadPixels(0, 0, int32(size), int32(size), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(buf))
+	Device.BindFramebuffer(tr.fbo)
+	Device.ReadPixels(0, 0, int32(size), int32(size), buf)
 
-	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
-	gl.Viewport(vp[0], vp[1], vp[2], vp[3])
+	Device.BindFramebuffer(0)
+	Device.Viewport(vp[0], vp[1], vp[2], vp[3])
 
 	img := image.NewRGBA(image.Rect(0, 0, size, size))
 	rowStride := size * 4
@@ -523,22 +487,20 @@ func (tr *ThumbnailRenderer) renderGroup(meshIDs []string, size int) ([]byte, st
 
 	tr.ensureFBOSize(size)
 
-	var vp [4]int32
-	gl.GetIntegerv(gl.VIEWPORT, &vp[0])
+	vp := Device.CurrentViewport()
 
-	gl.BindFramebuffer(gl.FRAMEBUFFER, tr.fbo)
-	gl.ReadBuffer(gl.COLOR_ATTACHMENT0)
+	Device.BindFramebuffer(tr.fbo)
 
-	gl.Viewport(0, 0, int32(size), int32(size))
+	Device.Viewport(0, 0, int32(size), int32(size))
 
-	gl.Disable(gl.BLEND)
-	gl.Enable(gl.DEPTH_TEST)
-	gl.DepthFunc(gl.LESS)
+	Device.SetBlend(false)
+	Device.SetDepthTest(true)
+	Device.SetDepthFunc(DepthLess)
 
-	gl.ClearColor(0.0, 0.0, 0.0, 1.0)
-	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	Device.ClearColor(0.0, 0.0, 0.0, 1.0)
+	Device.Clear(ClearColorBit | ClearDepthBit)
 
-	gl.UseProgram(tr.program)
+	Device.UseProgram(tr.program)
 
 	proj := perspective(45.0*(math.Pi/180.0), 1.0, 0.1, 100.0)
 	view := lookAt(
@@ -548,14 +510,14 @@ func (tr *ThumbnailRenderer) renderGroup(meshIDs []string, size int) ([]byte, st
 	)
 	model := scale(0.60)
 
-	gl.UniformMatrix4fv(tr.locModel, 1, false, &model[0])
-	gl.UniformMatrix4fv(tr.locView, 1, false, &view[0])
-	gl.UniformMatrix4fv(tr.locProj, 1, false, &proj[0])
+	Device.UniformMat4(tr.locModel, model[:])
+	Device.UniformMat4(tr.locView, view[:])
+	Device.UniformMat4(tr.locProj, proj[:])
 
 	base := [4]float32{1, 1, 1, 1}
-	gl.Uniform4fv(tr.locBaseCol, 1, &base[0])
-	gl.Uniform1i(tr.locUseTex, 0)
-	gl.Disable(gl.DEPTH_TEST)
+	Device.UniformVec4(tr.locBaseCol, base[:])
+	Device.UniformInt(tr.locUseTex, 0)
+	Device.SetDepthTest(false)
 
 	for _, meshID := range meshIDs {
 		vao := tr.mm.GetVAO(meshID)
@@ -571,22 +533,22 @@ func (tr *ThumbnailRenderer) renderGroup(meshIDs []string, size int) ([]byte, st
 		vertexCount := tr.mm.GetVertexCount(meshID)
 		ebo := tr.mm.GetEBO(meshID)
 
-		gl.BindVertexArray(vao)
+		Device.BindVertexArray(vao)
 		if count > 0 && ebo != 0 {
-			gl.DrawElements(gl.TRIANGLES, count, indexType, gl.PtrOffset(0))
+			Device.DrawIndexed(Triangles, count, indexType)
 		} else {
-			gl.DrawArrays(gl.TRIANGLES, 0, vertexCount)
+			Device.DrawArrays(Triangles, 0, vertexCount)
 		}
 	}
 
-	gl.BindVertexArray(0)
-	gl.Finish()
+	Device.BindVertexArray(0)
+	Device.Finish()
 
 	buf := make([]uint8, size*size*4)
-	gl.ReadPixels(0, 0, int32(size), int32(size), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(buf))
+	Device.ReadPixels(0, 0, int32(size), int32(size), buf)
 
-	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
-	gl.Viewport(vp[0], vp[1], vp[2], vp[3])
+	Device.BindFramebuffer(0)
+	Device.Viewport(vp[0], vp[1], vp[2], vp[3])
 
 	img := image.NewRGBA(image.Rect(0, 0, size, size))
 	rowStride := size * 4
@@ -607,47 +569,6 @@ func (tr *ThumbnailRenderer) renderGroup(meshIDs []string, size int) ([]byte, st
 	return data, hex.EncodeToString(h[:]), nil
 }
 
-// --- local shader helpers, confined to this file ---
-
-func thumbCompileShader(source string, shaderType uint32) (uint32, error) {
-	shader := gl.CreateShader(shaderType)
-	csources, free := gl.Strs(source + "\x00")
-	gl.ShaderSource(shader, 1, csources, nil)
-	free()
-	gl.CompileShader(shader)
-
-	var status int32
-	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
-	if status == gl.FALSE {
-		var logLen int32
-		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLen)
-		logBuf := make([]byte, logLen+1)
-		gl.GetShaderInfoLog(shader, logLen, nil, &logBuf[0])
-		gl.DeleteShader(shader)
-		return 0, fmt.Errorf("shader compile error: %s", string(logBuf))
-	}
-	return shader, nil
-}
-
-func linkProgram(vs, fs uint32) (uint32, error) {
-	prog := gl.CreateProgram()
-	gl.AttachShader(prog, vs)
-	gl.AttachShader(prog, fs)
-	gl.LinkProgram(prog)
-
-	var status int32
-	gl.GetProgramiv(prog, gl.LINK_STATUS, &status)
-	if status == gl.FALSE {
-		var logLen int32
-		gl.GetProgramiv(prog, gl.INFO_LOG_LENGTH, &logLen)
-		logBuf := make([]byte, logLen+1)
-		gl.GetProgramInfoLog(prog, logLen, nil, &logBuf[0])
-		gl.DeleteProgram(prog)
-		return 0, fmt.Errorf("program link error: %s", string(logBuf))
-	}
-	return prog, nil
-}
-
 func (tr *ThumbnailRenderer) rebuildVAO(meshID string) uint32 {
 	vbo := tr.mm.GetVBO(meshID)
 	ebo := tr.mm.GetEBO(meshID)
@@ -655,41 +576,35 @@ func (tr *ThumbnailRenderer) rebuildVAO(meshID string) uint32 {
 	if vbo == 0 {
 		return 0
 	}
-	var vao uint32
-	gl.GenVertexArrays(1, &vao)
-	gl.BindVertexArray(vao)
+	vao := Device.CreateVertexArray()
+	Device.BindVertexArray(vao)
 
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
+	Device.BindBuffer(ArrayBuffer, vbo)
 	if ebo != 0 {
-		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
+		Device.BindBuffer(ElementBuffer, ebo)
 	}
 
-	stride := int32(layout * 4)
+	stride := layout * 4
 
 	// pos
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, stride, 0)
-	gl.EnableVertexAttribArray(0)
-
+	Device.VertexAttrib(0, 3, Float, false, stride, 0)
 	// normal
-	gl.VertexAttribPointerWithOffset(1, 3, gl.FLOAT, false, stride, 3*4)
-	gl.EnableVertexAttribArray(1)
-
+	Device.VertexAttrib(1, 3, Float, false, stride, 3*4)
 	// uv
-	gl.VertexAttribPointerWithOffset(2, 2, gl.FLOAT, false, stride, 6*4)
-	gl.EnableVertexAttribArray(2)
+	Device.VertexAttrib(2, 2, Float, false, stride, 6*4)
 
 	if layout == 12 {
-		gl.VertexAttribPointerWithOffset(3, 4, gl.FLOAT, false, stride, 8*4)
-		gl.EnableVertexAttribArray(3)
+		Device.VertexAttrib(3, 4, Float, false, stride, 8*4)
 	}
 
-	gl.BindVertexArray(0)
+	Device.BindVertexArray(0)
 	return vao
 }
+
+// vaoExistsInThisContext reports whether vao names a vertex array of the
+// thumbnail context; VAOs are not shared with the main context.
 func (tr *ThumbnailRenderer) vaoExistsInThisContext(vao uint32) bool {
-	var tmp int32
-	gl.GetVertexArrayiv(vao, gl.VERTEX_ARRAY_BINDING, &tmp)
-	return gl.GetError() == gl.NO_ERROR
+	return Device.IsVertexArray(vao)
 }
 
 func scale(s float32) [16]float32 {
@@ -746,29 +661,28 @@ func normalize(v [3]float32) [3]float32 {
 }
 
 func (tr *ThumbnailRenderer) renderMaterial(mat *PreviewMaterial, size int) ([]byte, string, error) {
-	gl.Uniform4fv(tr.locBaseCol, 1, &mat.BaseColor[0])
+	Device.UniformVec4(tr.locBaseCol, mat.BaseColor[:])
 
 	if mat.UseTexture && mat.TextureID != 0 {
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, mat.TextureID)
-		gl.Uniform1i(tr.locUseTex, 1)
+		Device.BindTexture(0, Texture2D, mat.TextureID)
+		Device.UniformInt(tr.locUseTex, 1)
 	} else {
-		gl.Uniform1i(tr.locUseTex, 0)
+		Device.UniformInt(tr.locUseTex, 0)
 	}
 
 	// same sphere rendering as before
 
 	tr.ensureFBOSize(size)
 
-	gl.BindFramebuffer(gl.FRAMEBUFFER, tr.fbo)
-	gl.Viewport(0, 0, int32(size), int32(size))
+	Device.BindFramebuffer(tr.fbo)
+	Device.Viewport(0, 0, int32(size), int32(size))
 
-	gl.Disable(gl.BLEND)
-	gl.Enable(gl.DEPTH_TEST)
-	gl.ClearColor(0.1, 0.1, 0.1, 1.0)
-	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	Device.SetBlend(false)
+	Device.SetDepthTest(true)
+	Device.ClearColor(0.1, 0.1, 0.1, 1.0)
+	Device.Clear(ClearColorBit | ClearDepthBit)
 
-	gl.UseProgram(tr.program)
+	Device.UseProgram(tr.program)
 
 	// Camera
 	proj := perspective(45*(math.Pi/180), 1.0, 0.1, 100.0)
@@ -781,19 +695,18 @@ func (tr *ThumbnailRenderer) renderMaterial(mat *PreviewMaterial, size int) ([]b
 	view := lookAt(eye, center, up)
 	model := scale(1.25)
 
-	gl.UniformMatrix4fv(tr.locModel, 1, false, &model[0])
-	gl.UniformMatrix4fv(tr.locView, 1, false, &view[0])
-	gl.UniformMatrix4fv(tr.locProj, 1, false, &proj[0])
+	Device.UniformMat4(tr.locModel, model[:])
+	Device.UniformMat4(tr.locView, view[:])
+	Device.UniformMat4(tr.locProj, proj[:])
 
 	// Material uniforms
-	gl.Uniform4fv(tr.locBaseCol, 1, &mat.BaseColor[0])
+	Device.UniformVec4(tr.locBaseCol, mat.BaseColor[:])
 
 	if mat.UseTexture && mat.TextureID != 0 {
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, uint32(mat.TextureID))
-		gl.Uniform1i(tr.locUseTex, 1)
+		Device.BindTexture(0, Texture2D, uint32(mat.TextureID))
+		Device.UniformInt(tr.locUseTex, 1)
 	} else {
-		gl.Uniform1i(tr.locUseTex, 0)
+		Device.UniformInt(tr.locUseTex, 0)
 	}
 	vao := tr.mm.GetVAO("__preview_sphere")
 	if vao == 0 || !tr.vaoExistsInThisContext(vao) {
@@ -806,13 +719,13 @@ func (tr *ThumbnailRenderer) renderMaterial(mat *PreviewMaterial, size int) ([]b
 	indexType := tr.mm.GetIndexType("__preview_sphere")
 	_ = tr.mm.GetEBO("__preview_sphere")
 
-	gl.BindVertexArray(vao)
-	gl.DrawElements(gl.TRIANGLES, count, indexType, gl.PtrOffset(0))
-	gl.BindVertexArray(0)
+	Device.BindVertexArray(vao)
+	Device.DrawIndexed(Triangles, count, indexType)
+	Device.BindVertexArray(0)
 
 	// Read pixels → PNG
 	buf := make([]uint8, size*size*4)
-	gl.ReadPixels(0, 0, int32(size), int32(size), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(buf))
+	Device.ReadPixels(0, 0, int32(size), int32(size), buf)
 
 	// Flip vertically
 	img := image.NewRGBA(image.Rect(0, 0, size, size))
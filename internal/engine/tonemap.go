@@ -0,0 +1,112 @@
+package engine
+
+import "math"
+
+// TonemapOperator selects the curve post_tonemap applies after exposure.
+type TonemapOperator int
+
+const (
+	TonemapACES     TonemapOperator = iota // Narkowicz's ACES filmic fit
+	TonemapFilmic                          // Hable's Uncharted 2 curve
+	TonemapReinhard                        // extended Reinhard on luminance
+	TonemapNone                            // clamp only
+)
+
+var tonemapNames = [...]string{"ACES", "Filmic", "Reinhard", "None"}
+
+func (op TonemapOperator) String() string {
+	if op >= 0 && int(op) < len(tonemapNames) {
+		return tonemapNames[op]
+	}
+	return "Unknown"
+}
+
+// TonemapNames lists the operators in order, for editor dropdowns.
+func TonemapNames() []string { return tonemapNames[:] }
+
+const (
+	hableWhite    = 11.2 // linear white point of the filmic curve
+	reinhardWhite = 4.0  // luminance that maps to 1 under Reinhard
+)
+
+// Luminance is the Rec. 709 luminance of a linear colour.
+func Luminance(c [3]float32) float32 {
+	return 0.2126*c[0] + 0.7152*c[1] + 0.0722*c[2]
+}
+
+// Tonemap maps an exposed linear colour to [0, 1]. It mirrors the
+// operators in post_tonemap_fragment.glsl.
+func Tonemap(op TonemapOperator, c [3]float32) [3]float32 {
+	switch op {
+	case TonemapACES:
+		for i, x := range c {
+			c[i] = clamp01((x * (2.51*x + 0.03)) / (x*(2.43*x+0.59) + 0.14))
+		}
+	case TonemapFilmic:
+		w := hable(hableWhite)
+		for i, x := range c {
+			c[i] = clamp01(hable(2*x) / w)
+		}
+	case TonemapReinhard:
+		l := Luminance(c)
+		if l <= 0 {
+			return [3]float32{}
+		}
+		s := (1 + l/(reinhardWhite*reinhardWhite)) / (1 + l)
+		for i := range c {
+			c[i] = clamp01(c[i] * s)
+		}
+	default:
+		for i := range c {
+			c[i] = clamp01(c[i])
+		}
+	}
+	return c
+}
+
+func hable(x float32) float32 {
+	const a, b, c, d, e, f = 0.15, 0.50, 0.10, 0.20, 0.02, 0.30
+	return (x*(a*x+c*b)+d*e)/(x*(a*x+b)+d*f) - e/f
+}
+
+func clamp01(x float32) float32 {
+	return min(max(x, 0), 1)
+}
+
+// EV100FromLuminance is the exposure value at ISO 100 that meters an
+// average scene luminance (cd/m^2) with the reflected-light constant
+// K = 12.5.
+func EV100FromLuminance(l float32) float32 {
+	return float32(math.Log2(float64(max(l, 1e-5)) * 100 / 12.5))
+}
+
+// ExposureFromEV100 is the multiplier that maps scene luminance to
+// [0, 1] for a camera set to ev100, saturating at 1.2 times the metered
+// luminance (the standard sensor model).
+func ExposureFromEV100(ev100 float32) float32 {
+	return 1 / (1.2 * float32(math.Exp2(float64(ev100))))
+}
+
+// AdaptLuminance moves the eye's adapted luminance towards target,
+// exponentially in time: speedUp applies when the scene gets brighter,
+// speedDown when it gets darker.
+func AdaptLuminance(current, target, dt, speedUp, speedDown float32) float32 {
+	speed := speedDown
+	if target > current {
+		speed = speedUp
+	}
+	return current + (target-current)*(1-float32(math.Exp(float64(-dt*speed))))
+}
+
+// LogAverageLuminance is the geometric mean luminance of RGB pixels, the
+// quantity the post_luminance pass and its mip chain compute on the GPU.
+func LogAverageLuminance(pix []float32) float32 {
+	if len(pix) < 3 {
+		return 0
+	}
+	var sum float64
+	for i := 0; i+2 < len(pix); i += 3 {
+		sum += math.Log2(float64(Luminance([3]float32{pix[i], pix[i+1], pix[i+2]})) + 1e-4)
+	}
+	return float32(math.Exp2(sum / float64(len(pix)/3)))
+}
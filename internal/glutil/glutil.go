@@ -2,48 +2,24 @@ package glutil
 
 import (
 	"log"
-	"strconv"
 
-	"github.com/go-gl/gl/v4.1-core/gl"
+	"go-engine/Go-Cordance/internal/engine"
 )
 
-// ClearGLErrors drains the GL error queue.
+// ClearGLErrors drains the device error queue.
 func ClearGLErrors() {
-	for {
-		if err := gl.GetError(); err == gl.NO_ERROR {
-			return
-		}
-	}
-}
-
-// glErrToString maps common GL error codes to readable strings.
-func glErrToString(e uint32) string {
-	switch e {
-	case gl.NO_ERROR:
-		return "GL_NO_ERROR"
-	case gl.INVALID_ENUM:
-		return "GL_INVALID_ENUM"
-	case gl.INVALID_VALUE:
-		return "GL_INVALID_VALUE"
-	case gl.INVALID_OPERATION:
-		return "GL_INVALID_OPERATION"
-	case gl.INVALID_FRAMEBUFFER_OPERATION:
-		return "GL_INVALID_FRAMEBUFFER_OPERATION"
-	case gl.OUT_OF_MEMORY:
-		return "GL_OUT_OF_MEMORY"
-	default:
-		return "GL_UNKNOWN(0x" + strconv.FormatUint(uint64(e), 16) + ")"
+	for engine.Device.Error() != nil {
 	}
 }
 
-// LogGLErrors logs all errors currently in the GL error queue with context.
+// LogGLErrors logs all errors currently in the device error queue with context.
 func LogGLErrors(context string) {
 	for {
-		err := gl.GetError()
-		if err == gl.NO_ERROR {
+		err := engine.Device.Error()
+		if err == nil {
 			return
 		}
-		log.Printf("GLERR [%s]: 0x%X %s", context, err, glErrToString(err))
+		log.Printf("GLERR [%s]: %v", context, err)
 		panic("Stopped")
 	}
 }
@@ -0,0 +1,209 @@
+// Package golden compares rendered images against reference ("golden")
+// PNGs with a perceptual tolerance, so rendering changes that alter how
+// materials look fail tests while rasteriser noise does not.
+//
+// Goldens live in Dir, named after the check, and are committed with the
+// tests. A missing golden fails the check; run the tests with
+// -golden.update to record new goldens or rewrite them after an intended
+// change, and review the PNGs before committing them.
+package golden
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-engine/Go-Cordance/internal/engine"
+)
+
+var update = flag.Bool("golden.update", false, "rewrite golden images from the captured ones")
+
+// Dir is where Check finds golden images, relative to the test's package
+// directory unless absolute.
+var Dir = filepath.Join("testdata", "golden")
+
+// Tolerance is how far a capture may drift from its golden.
+type Tolerance struct {
+	// MaxDeltaE is the largest CIE76 colour difference (Euclidean
+	// distance in L*a*b*) at which pixels still match. About 2.3 is a
+	// just noticeable difference.
+	MaxDeltaE float64
+	// Shift lets a pixel match any golden pixel up to Shift pixels away,
+	// absorbing edges that rasterisers place a pixel apart.
+	Shift int
+	// MaxDiffFraction is the fraction of pixels allowed not to match.
+	MaxDiffFraction float64
+}
+
+// DefaultTolerance accepts what differs between GPU drivers and Mesa's
+// llvmpipe for the same frame: small colour error and the odd edge pixel.
+var DefaultTolerance = Tolerance{MaxDeltaE: 3, Shift: 1, MaxDiffFraction: 0.002}
+
+// Diff summarises how a capture differs from its golden.
+type Diff struct {
+	Pixels     int     // pixels compared
+	Different  int     // pixels that did not match
+	MaxDeltaE  float64 // largest colour difference of an unmatched pixel
+	MeanDeltaE float64 // mean colour difference against the same pixel
+	// Image shows the golden in dim grey with unmatched pixels in red,
+	// brighter the larger their difference.
+	Image *image.RGBA
+
+	tol Tolerance
+}
+
+// OK reports whether few enough pixels differ.
+func (d Diff) OK() bool {
+	return float64(d.Different) <= d.tol.MaxDiffFraction*float64(d.Pixels)
+}
+
+func (d Diff) String() string {
+	return fmt.Sprintf("%d of %d pixels differ (max ΔE %.1f, mean ΔE %.2f)", d.Different, d.Pixels, d.MaxDeltaE, d.MeanDeltaE)
+}
+
+// Compare matches got against want pixel by pixel. Alpha is ignored:
+// captures are opaque. It fails when the images differ in size.
+func Compare(got, want image.Image, tol Tolerance) (Diff, error) {
+	gb, wb := got.Bounds(), want.Bounds()
+	if gb.Dx() != wb.Dx() || gb.Dy() != wb.Dy() {
+		return Diff{}, fmt.Errorf("golden: image is %dx%d, golden %dx%d", gb.Dx(), gb.Dy(), wb.Dx(), wb.Dy())
+	}
+	w, h := gb.Dx(), gb.Dy()
+	g, r := toLab(got), toLab(want)
+
+	d := Diff{Pixels: w * h, Image: image.NewRGBA(image.Rect(0, 0, w, h)), tol: tol}
+	var sum float64
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			p := g[y*w+x]
+			e := deltaE(p, r[y*w+x])
+			sum += e
+			best := e
+			for dy := -tol.Shift; dy <= tol.Shift && best > tol.MaxDeltaE; dy++ {
+				for dx := -tol.Shift; dx <= tol.Shift; dx++ {
+					xx, yy := x+dx, y+dy
+					if xx < 0 || yy < 0 || xx >= w || yy >= h {
+						continue
+					}
+					best = math.Min(best, deltaE(p, r[yy*w+xx]))
+				}
+			}
+
+			grey := uint8(r[y*w+x][0] * 0.6) // L* is 0..100
+			c := color.RGBA{grey, grey, grey, 255}
+			if best > tol.MaxDeltaE {
+				d.Different++
+				d.MaxDeltaE = math.Max(d.MaxDeltaE, best)
+				c = color.RGBA{uint8(math.Min(128+best*4, 255)), 0, 0, 255}
+			}
+			d.Image.SetRGBA(x, y, c)
+		}
+	}
+	d.MeanDeltaE = sum / float64(d.Pixels)
+	return d, nil
+}
+
+// Check compares got with the golden image name. On a mismatch the test
+// fails and the capture and its diff are written to a temporary directory
+// for inspection. Under -golden.update it records got as the golden
+// instead.
+func Check(t testing.TB, name string, got image.Image, tol Tolerance) {
+	t.Helper()
+	path := filepath.Join(Dir, name+".png")
+	want, err := Load(path)
+	if errors.Is(err, fs.ErrNotExist) && !*update {
+		t.Fatalf("%s: no golden %s; run with -golden.update to record it", name, path)
+	}
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := engine.WritePNG(path, got); err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("%s: recorded golden %s", name, path)
+		return
+	}
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+
+	d, err := Compare(got, want, tol)
+	if err == nil && d.OK() {
+		return
+	}
+	dir, mkErr := os.MkdirTemp("", "golden-"+name+"-")
+	if mkErr != nil {
+		t.Fatal(mkErr)
+	}
+	engine.WritePNG(filepath.Join(dir, "actual.png"), got)
+	if err != nil {
+		t.Errorf("%s: %v; capture in %s", name, err, dir)
+		return
+	}
+	engine.WritePNG(filepath.Join(dir, "diff.png"), d.Image)
+	t.Errorf("%s: %v; capture and diff in %s", name, d, dir)
+}
+
+// Load decodes the PNG at path.
+func Load(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+// toLab converts img to CIE L*a*b* (D65), row by row.
+func toLab(img image.Image) [][3]float64 {
+	b := img.Bounds()
+	out := make([][3]float64, 0, b.Dx()*b.Dy())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			out = append(out, lab(srgbLinear[c.R], srgbLinear[c.G], srgbLinear[c.B]))
+		}
+	}
+	return out
+}
+
+var srgbLinear = func() (t [256]float64) {
+	for i := range t {
+		c := float64(i) / 255
+		if c <= 0.04045 {
+			t[i] = c / 12.92
+		} else {
+			t[i] = math.Pow((c+0.055)/1.055, 2.4)
+		}
+	}
+	return t
+}()
+
+// lab converts linear sRGB to L*a*b* against the D65 white point.
+func lab(r, g, b float64) [3]float64 {
+	x := (0.4124*r + 0.3576*g + 0.1805*b) / 0.95047
+	y := 0.2126*r + 0.7152*g + 0.0722*b
+	z := (0.0193*r + 0.1192*g + 0.9505*b) / 1.08883
+	f := func(t float64) float64 {
+		if t > 216.0/24389 {
+			return math.Cbrt(t)
+		}
+		return (24389.0/27*t + 16) / 116
+	}
+	fx, fy, fz := f(x), f(y), f(z)
+	return [3]float64{116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)}
+}
+
+func deltaE(a, b [3]float64) float64 {
+	l, u, v := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return math.Sqrt(l*l + u*u + v*v)
+}
@@ -0,0 +1,131 @@
+package golden
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// disc draws a white disc of radius r centred at (cx, cy) on grey.
+func disc(w, h int, cx, cy, r float64) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{60, 60, 60, 255}
+			if dx, dy := float64(x)+0.5-cx, float64(y)+0.5-cy; dx*dx+dy*dy < r*r {
+				c = color.RGBA{240, 240, 240, 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestCompare(t *testing.T) {
+	want := disc(32, 32, 16, 16, 10)
+
+	d, err := Compare(want, want, DefaultTolerance)
+	if err != nil || !d.OK() || d.Different != 0 || d.MeanDeltaE != 0 {
+		t.Fatalf("identical images: %v, %v", d, err)
+	}
+
+	// Faint noise stays under the colour tolerance.
+	noisy := disc(32, 32, 16, 16, 10)
+	for i := 0; i < len(noisy.Pix); i += 4 * 7 {
+		noisy.Pix[i]++
+	}
+	if d, _ := Compare(noisy, want, DefaultTolerance); !d.OK() || d.Different != 0 {
+		t.Errorf("noise: %v", d)
+	}
+
+	// An edge a pixel off only matches with Shift.
+	moved := disc(32, 32, 17, 16, 10)
+	if d, _ := Compare(moved, want, DefaultTolerance); !d.OK() {
+		t.Errorf("shifted edge with Shift 1: %v", d)
+	}
+	strict := Tolerance{MaxDeltaE: 3}
+	if d, _ := Compare(moved, want, strict); d.OK() || d.Different == 0 {
+		t.Errorf("shifted edge with Shift 0: %v", d)
+	}
+
+	// A changed colour fails and shows up red in the diff image.
+	tinted := disc(32, 32, 16, 16, 10)
+	tinted.SetRGBA(16, 16, color.RGBA{240, 200, 200, 255})
+	tinted.SetRGBA(17, 16, color.RGBA{240, 200, 200, 255})
+	d, _ = Compare(tinted, want, Tolerance{MaxDeltaE: 3, Shift: 1})
+	if d.OK() || d.Different != 2 || d.MaxDeltaE < 10 {
+		t.Errorf("tinted pixels: %v", d)
+	}
+	if c := d.Image.RGBAAt(16, 16); c.R < 128 || c.G != 0 {
+		t.Errorf("diff image at a tinted pixel is %v", c)
+	}
+	if c := d.Image.RGBAAt(0, 0); c.R != c.G {
+		t.Errorf("diff image at a matching pixel is %v", c)
+	}
+
+	if _, err := Compare(disc(16, 32, 8, 16, 4), want, DefaultTolerance); err == nil {
+		t.Error("compared images of different sizes")
+	}
+}
+
+// fatalTB records whether a check failed; Fatalf ends the goroutine like
+// testing.T does.
+type fatalTB struct {
+	testing.TB
+	failed bool
+}
+
+func (f *fatalTB) Helper() {}
+
+func (f *fatalTB) Fatalf(string, ...any) {
+	f.failed = true
+	runtime.Goexit()
+}
+
+func (f *fatalTB) Errorf(string, ...any) { f.failed = true }
+
+func (f *fatalTB) Logf(string, ...any) {}
+
+func TestCheckMissingGolden(t *testing.T) {
+	old := Dir
+	Dir = t.TempDir()
+	defer func() { Dir = old }()
+
+	img := disc(16, 16, 8, 8, 5)
+	check := func() bool {
+		tb := &fatalTB{TB: t}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			Check(tb, "disc", img, DefaultTolerance)
+		}()
+		<-done
+		return !tb.failed
+	}
+	if check() {
+		t.Fatal("missing golden passed")
+	}
+	if _, err := os.Stat(filepath.Join(Dir, "disc.png")); err == nil {
+		t.Fatal("missing golden recorded without -golden.update")
+	}
+
+	*update = true
+	ok := check()
+	*update = false
+	if !ok {
+		t.Fatal("recording under -golden.update failed")
+	}
+	got, err := Load(filepath.Join(Dir, "disc.png"))
+	if err != nil {
+		t.Fatalf("golden not recorded: %v", err)
+	}
+	if d, _ := Compare(got, img, Tolerance{}); d.Different != 0 {
+		t.Errorf("recorded golden differs: %v", d)
+	}
+	if !check() {
+		t.Error("capture does not match its recorded golden")
+	}
+}
@@ -0,0 +1,122 @@
+package golden_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	loader "go-engine/Go-Cordance/cmd/game/loader"
+	"go-engine/Go-Cordance/internal/ecs"
+	"go-engine/Go-Cordance/internal/engine"
+	"go-engine/Go-Cordance/internal/golden"
+	"go-engine/Go-Cordance/internal/scene"
+)
+
+const captureSize = 128
+
+// TestMaterialGoldens renders a lit sphere per material model and alpha
+// mode and compares each with its golden. It needs an OpenGL context; see
+// engine.InitHeadlessGL for running it on Mesa's llvmpipe. All GL work
+// stays on this test's goroutine, so cases are not subtests.
+func TestMaterialGoldens(t *testing.T) {
+	if testing.Short() {
+		t.Skip("renders with OpenGL")
+	}
+	dir, err := filepath.Abs(golden.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden.Dir = dir
+
+	window, err := engine.InitHeadlessGL(captureSize, captureSize)
+	if err != nil {
+		t.Skipf("no OpenGL context: %v", err)
+	}
+	defer engine.TerminateGLFW()
+	defer window.Destroy()
+
+	// Shaders and meshes load relative to the repository root.
+	wd, _ := os.Getwd()
+	if err := os.Chdir(filepath.Join("..", "..")); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	loader.LoadShaders()
+	if err := loader.LoadAllShaders(); err != nil {
+		t.Fatal(err)
+	}
+
+	prog := engine.MustGetShaderProgram("default_shader")
+	renderer := engine.NewRendererWithProgram(prog.ID, captureSize, captureSize)
+	renderer.InitUniforms()
+	renderer.InitShadowWithProgram(engine.MustGetShaderProgram("shadow_shader").ID, 1024, 1024)
+	meshMgr := engine.NewMeshManager()
+	engine.GlobalMeshManager = meshMgr
+	meshMgr.RegisterSphere("sphere", 32, 16)
+	meshMgr.RegisterPlane("plane")
+	rs := ecs.NewRenderSystem(renderer, meshMgr, ecs.NewCameraSystem(nil))
+
+	cases := []struct {
+		name string
+		mat  func(m *ecs.Material)
+	}{
+		{"blinn_phong", func(m *ecs.Material) {}},
+		{"pbr_metal", func(m *ecs.Material) {
+			m.ShaderName, m.Metallic, m.Roughness = "pbr_shader", 1, 0.3
+		}},
+		{"pbr_dielectric", func(m *ecs.Material) {
+			m.ShaderName, m.Metallic, m.Roughness = "pbr_shader", 0, 0.6
+		}},
+		{"toon", func(m *ecs.Material) { m.ShaderName = "toon_shader" }},
+		{"alpha_blend", func(m *ecs.Material) {
+			m.BaseColor[3], m.AlphaMode = 0.4, engine.AlphaBlend
+		}},
+		{"alpha_mask", func(m *ecs.Material) {
+			m.BaseColor[3], m.AlphaMode, m.AlphaCutoff = 0.4, engine.AlphaMask, 0.5
+		}},
+	}
+	for _, c := range cases {
+		mat := ecs.NewMaterial([4]float32{0.8, 0.3, 0.2, 1})
+		c.mat(mat)
+		sc := materialScene(mat)
+		sc.Update(0)
+		img, err := sc.Capture(rs, captureSize, captureSize)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		golden.Check(t, c.name, img, golden.DefaultTolerance)
+	}
+}
+
+// materialScene is a sphere in mat over a grey floor, lit by one
+// shadow-casting directional light and seen through the scene camera.
+func materialScene(mat *ecs.Material) *scene.Scene {
+	sc := scene.New()
+	cam := sc.Camera()
+	cam.Position, cam.Target = [3]float32{0, 1.5, 3.5}, [3]float32{0, 0.5, 0}
+
+	sphere := sc.AddEntity()
+	sphere.AddComponent(ecs.NewTransform([3]float32{0, 0.8, 0}))
+	sphere.AddComponent(ecs.NewMesh("sphere"))
+	sphere.AddComponent(mat)
+
+	floor := sc.AddEntity()
+	ft := ecs.NewTransform([3]float32{0, 0, 0})
+	ft.Scale = [3]float32{4, 1, 4}
+	floor.AddComponent(ft)
+	floor.AddComponent(ecs.NewMesh("plane"))
+	floor.AddComponent(ecs.NewMaterial([4]float32{0.5, 0.5, 0.5, 1}))
+
+	light := sc.AddEntity()
+	lt := ecs.NewTransform([3]float32{0, 4, 0})
+	q := mgl32.QuatRotate(mgl32.DegToRad(-60), mgl32.Vec3{1, 0, 0.5}.Normalize())
+	lt.Rotation = [4]float32{q.V[0], q.V[1], q.V[2], q.W}
+	light.AddComponent(lt)
+	lc := ecs.NewLightComponent()
+	lc.Intensity, lc.CastsShadows = 2, true
+	light.AddComponent(lc)
+	return sc
+}
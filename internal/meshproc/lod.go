@@ -0,0 +1,48 @@
+package meshproc
+
+import (
+	"go-engine/Go-Cordance/internal/engine"
+)
+
+// LODID names level (1-based) of a mesh's LOD chain.
+func LODID(id string, level int) string { return engine.LODMeshID(id, level) }
+
+// BuildLODChain simplifies md once per ratio, each the fraction of md's
+// triangles to keep, in decreasing order. Every level is simplified from
+// the one before, cache- and fetch-optimised, and stripped of unused
+// vertices. The chain ends early when a level cannot get smaller within
+// maxError, so it may hold fewer meshes than ratios.
+func BuildLODChain(md engine.MeshData, ratios []float32, maxError float32) []engine.MeshData {
+	var chain []engine.MeshData
+	prev := md
+	for i, r := range ratios {
+		target := int(float32(len(md.Indices)/3)*r) * 3
+		indices, _ := Simplify(&prev, target, maxError)
+		if len(indices) >= len(prev.Indices) {
+			break
+		}
+		lod := clone(prev)
+		lod.ID = LODID(md.ID, i+1)
+		lod.Indices = indices
+		OptimizeVertexCache(&lod)
+		OptimizeVertexFetch(&lod)
+		chain = append(chain, lod)
+		prev = lod
+	}
+	return chain
+}
+
+func clone(md engine.MeshData) engine.MeshData {
+	md.Vertices = append([]float32(nil), md.Vertices...)
+	md.Indices = append([]uint32(nil), md.Indices...)
+	if md.Joints != nil {
+		md.Joints = append([][4]uint16(nil), md.Joints...)
+	}
+	if md.Weights != nil {
+		md.Weights = append([][4]float32(nil), md.Weights...)
+	}
+	if md.Colors != nil {
+		md.Colors = append([][4]float32(nil), md.Colors...)
+	}
+	return md
+}
@@ -0,0 +1,111 @@
+// Package meshproc is the CPU-side mesh processing shared by the importers
+// and the cooker: vertex welding, normal and MikkTSpace-style tangent
+// generation, vertex cache, overdraw and fetch optimisation, and quadric
+// error simplification for LOD chains.
+//
+// Everything works on engine.MeshData (12-float vertices: position, normal,
+// uv, tangent) and keeps the optional joint, weight and colour streams in
+// step with the vertices. No function depends on map iteration order or
+// timing, so the same input always gives bit-identical output and results
+// can be checked against golden files.
+package meshproc
+
+import (
+	"math"
+
+	"go-engine/Go-Cordance/internal/engine"
+)
+
+const stride = 12
+
+func vertexCount(md *engine.MeshData) int { return len(md.Vertices) / stride }
+
+// Optimize welds identical vertices and reorders triangles and vertices
+// for the post-transform cache, overdraw and vertex fetch, in that order.
+func Optimize(md *engine.MeshData) {
+	Weld(md, 0)
+	OptimizeVertexCache(md)
+	OptimizeOverdraw(md)
+	OptimizeVertexFetch(md)
+}
+
+// remap rebuilds md so old vertex i becomes vertex to[i] of n. When several
+// old vertices map to the same new one the first keeps its data; to[i] ==
+// unused drops the vertex, which must then not be referenced.
+func remap(md *engine.MeshData, to []uint32, n int) {
+	verts := make([]float32, n*stride)
+	var joints [][4]uint16
+	var weights [][4]float32
+	var colors [][4]float32
+	if len(md.Joints) > 0 {
+		joints = make([][4]uint16, n)
+	}
+	if len(md.Weights) > 0 {
+		weights = make([][4]float32, n)
+	}
+	if len(md.Colors) > 0 {
+		colors = make([][4]float32, n)
+	}
+
+	written := make([]bool, n)
+	for old, nv := range to {
+		if nv == unused || written[nv] {
+			continue
+		}
+		written[nv] = true
+		copy(verts[int(nv)*stride:int(nv+1)*stride], md.Vertices[old*stride:(old+1)*stride])
+		if joints != nil {
+			joints[nv] = md.Joints[old]
+		}
+		if weights != nil {
+			weights[nv] = md.Weights[old]
+		}
+		if colors != nil {
+			colors[nv] = md.Colors[old]
+		}
+	}
+	for i, idx := range md.Indices {
+		md.Indices[i] = to[idx]
+	}
+	md.Vertices, md.Joints, md.Weights, md.Colors = verts, joints, weights, colors
+}
+
+const unused = ^uint32(0)
+
+// RemoveDegenerate drops triangles that reference a vertex twice.
+func RemoveDegenerate(md *engine.MeshData) {
+	out := md.Indices[:0]
+	for i := 0; i+2 < len(md.Indices); i += 3 {
+		a, b, c := md.Indices[i], md.Indices[i+1], md.Indices[i+2]
+		if a != b && b != c && a != c {
+			out = append(out, a, b, c)
+		}
+	}
+	md.Indices = out
+}
+
+type vec3 [3]float64
+
+// attr3 reads the 3-float attribute at offset off of vertex v: 0 for the
+// position, 3 for the normal.
+func attr3(verts []float32, v uint32, off int) vec3 {
+	o := int(v)*stride + off
+	return vec3{float64(verts[o]), float64(verts[o+1]), float64(verts[o+2])}
+}
+
+func sub(a, b vec3) vec3           { return vec3{a[0] - b[0], a[1] - b[1], a[2] - b[2]} }
+func add(a, b vec3) vec3           { return vec3{a[0] + b[0], a[1] + b[1], a[2] + b[2]} }
+func scale(a vec3, s float64) vec3 { return vec3{a[0] * s, a[1] * s, a[2] * s} }
+func dot(a, b vec3) float64        { return a[0]*b[0] + a[1]*b[1] + a[2]*b[2] }
+func cross(a, b vec3) vec3 {
+	return vec3{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
+func length(a vec3) float64 { return math.Sqrt(dot(a, a)) }
+
+func normalize(a vec3) (vec3, bool) {
+	l := length(a)
+	if l == 0 || math.IsNaN(l) || math.IsInf(l, 0) {
+		return vec3{}, false
+	}
+	return scale(a, 1/l), true
+}
@@ -0,0 +1,157 @@
+package meshproc
+
+import (
+	"reflect"
+	"testing"
+
+	"go-engine/Go-Cordance/internal/engine"
+)
+
+func vertex(x, y, z, u, v float32) []float32 {
+	return []float32{x, y, z, 0, 0, 1, u, v, 0, 0, 0, 0}
+}
+
+// grid builds an n x n quad grid in the XY plane with unique vertices.
+func grid(n int) engine.MeshData {
+	md := engine.MeshData{ID: "grid"}
+	for y := 0; y <= n; y++ {
+		for x := 0; x <= n; x++ {
+			fx, fy := float32(x)/float32(n), float32(y)/float32(n)
+			md.Vertices = append(md.Vertices, vertex(fx, fy, 0, fx, fy)...)
+		}
+	}
+	row := uint32(n + 1)
+	for y := uint32(0); y < uint32(n); y++ {
+		for x := uint32(0); x < uint32(n); x++ {
+			a := y*row + x
+			md.Indices = append(md.Indices, a, a+1, a+row+1, a, a+row+1, a+row)
+		}
+	}
+	return md
+}
+
+func TestWeld_MergesDuplicatesAndDropsDegenerates(t *testing.T) {
+	md := engine.MeshData{
+		Vertices: append(append(append(append(
+			vertex(0, 0, 0, 0, 0), vertex(1, 0, 0, 1, 0)...),
+			vertex(0, 1, 0, 0, 1)...), vertex(1, 0, 0, 1, 0)...), // duplicate of 1
+			vertex(1, 1, 0, 1, 1)...),
+		Indices: []uint32{0, 1, 2, 3, 4, 2, 1, 3, 2},
+		Colors:  [][4]float32{{1, 0, 0, 1}, {0, 1, 0, 1}, {0, 0, 1, 1}, {0, 1, 0, 1}, {1, 1, 1, 1}},
+	}
+	if removed := Weld(&md, 0); removed != 1 {
+		t.Fatalf("removed %d vertices, want 1", removed)
+	}
+	if got := vertexCount(&md); got != 4 || len(md.Colors) != 4 {
+		t.Fatalf("%d vertices, %d colours, want 4", got, len(md.Colors))
+	}
+	// The last triangle (1, 3, 2) became (1, 1, 2) and was dropped.
+	if want := []uint32{0, 1, 2, 1, 3, 2}; !reflect.DeepEqual(md.Indices, want) {
+		t.Fatalf("indices %v, want %v", md.Indices, want)
+	}
+	if md.Colors[3] != [4]float32{1, 1, 1, 1} {
+		t.Fatalf("colour stream not remapped: %v", md.Colors)
+	}
+}
+
+func TestGenerateTangents_MirroredUVs(t *testing.T) {
+	// Two triangles sharing an edge, the right one with U mirrored. The
+	// shared vertices are used with both windings and must be split.
+	md := engine.MeshData{
+		Vertices: append(append(append(append(
+			vertex(-1, 0, 0, 0, 0), vertex(0, 0, 0, 1, 0)...),
+			vertex(0, 1, 0, 1, 1)...), vertex(1, 0, 0, 0, 0)...),
+			vertex(-1, 1, 0, 0, 1)...),
+		Indices: []uint32{0, 1, 2, 0, 2, 4, 1, 3, 2},
+	}
+	if added := GenerateTangents(&md); added != 2 {
+		t.Fatalf("added %d vertices, want 2", added)
+	}
+	if !md.HasTangents {
+		t.Fatal("HasTangents not set")
+	}
+	tangent := func(v uint32) [4]float32 {
+		var t [4]float32
+		copy(t[:], md.Vertices[int(v)*stride+8:])
+		return t
+	}
+	for _, v := range md.Indices[:6] {
+		if got := tangent(v); got != [4]float32{1, 0, 0, 1} {
+			t.Fatalf("left vertex %d tangent %v, want +X w=+1", v, got)
+		}
+	}
+	for _, v := range md.Indices[6:] {
+		if got := tangent(v); got != [4]float32{-1, 0, 0, -1} {
+			t.Fatalf("mirrored vertex %d tangent %v, want -X w=-1", v, got)
+		}
+	}
+}
+
+func TestOptimizeVertexCache_ImprovesACMR(t *testing.T) {
+	md := grid(32)
+	// Scramble the triangle order deterministically.
+	ntri := len(md.Indices) / 3
+	scrambled := make([]uint32, 0, len(md.Indices))
+	for i := 0; i < ntri; i++ {
+		t := (i * 97) % ntri
+		scrambled = append(scrambled, md.Indices[3*t:3*t+3]...)
+	}
+	md.Indices = scrambled
+
+	before := ACMR(md.Indices, 16)
+	OptimizeVertexCache(&md)
+	after := ACMR(md.Indices, 16)
+	if after >= before || after > 1 {
+		t.Fatalf("ACMR %.3f -> %.3f, want an improvement to at most 1", before, after)
+	}
+	if len(md.Indices) != 3*ntri {
+		t.Fatalf("lost triangles: %d indices", len(md.Indices))
+	}
+}
+
+func TestOptimize_Deterministic(t *testing.T) {
+	a, b := grid(12), grid(12)
+	for _, md := range []*engine.MeshData{&a, &b} {
+		GenerateTangents(md)
+		Optimize(md)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("two runs over the same input differ")
+	}
+	la := BuildLODChain(a, []float32{0.5, 0.25}, 0.01)
+	lb := BuildLODChain(b, []float32{0.5, 0.25}, 0.01)
+	if !reflect.DeepEqual(la, lb) {
+		t.Fatal("two LOD chains over the same input differ")
+	}
+}
+
+func TestSimplify_GridKeepsBorder(t *testing.T) {
+	md := grid(16)
+	target := len(md.Indices) / 4
+	indices, rel := Simplify(&md, target, 0.01)
+	if len(indices) > target {
+		t.Fatalf("%d indices, want at most %d", len(indices), target)
+	}
+	if rel != 0 {
+		t.Fatalf("flat grid simplified with error %v, want 0", rel)
+	}
+	used := map[uint32]bool{}
+	for _, v := range indices {
+		used[v] = true
+	}
+	for v := 0; v < vertexCount(&md); v++ {
+		p := attr3(md.Vertices, uint32(v), 0)
+		border := p[0] == 0 || p[0] == 1 || p[1] == 0 || p[1] == 1
+		if border && !used[uint32(v)] {
+			t.Fatalf("border vertex %d at %v was collapsed", v, p)
+		}
+	}
+
+	chain := BuildLODChain(md, []float32{0.5, 0.25}, 0.01)
+	if len(chain) != 2 || chain[0].ID != "grid#lod1" || chain[1].ID != "grid#lod2" {
+		t.Fatalf("unexpected chain: %d levels", len(chain))
+	}
+	if len(chain[1].Indices) >= len(chain[0].Indices) {
+		t.Fatal("LOD 2 is not smaller than LOD 1")
+	}
+}
@@ -0,0 +1,75 @@
+package meshproc
+
+import (
+	"math"
+
+	"go-engine/Go-Cordance/internal/engine"
+)
+
+// GenerateNormals replaces every vertex normal with the angle-weighted
+// average of the face normals around its position. Vertices that share a
+// position but differ in other attributes (UV seams) get the same normal,
+// so seams do not show in the shading. Use Weld first if hard edges should
+// come from split vertices only.
+func GenerateNormals(md *engine.MeshData) {
+	n := vertexCount(md)
+	group := positionGroups(md.Vertices, n)
+	acc := make([]vec3, n)
+
+	for i := 0; i+2 < len(md.Indices); i += 3 {
+		tri := [3]uint32{md.Indices[i], md.Indices[i+1], md.Indices[i+2]}
+		p := [3]vec3{attr3(md.Vertices, tri[0], 0), attr3(md.Vertices, tri[1], 0), attr3(md.Vertices, tri[2], 0)}
+		fn, ok := normalize(cross(sub(p[1], p[0]), sub(p[2], p[0])))
+		if !ok {
+			continue
+		}
+		for c := 0; c < 3; c++ {
+			w := cornerAngle(p[c], p[(c+1)%3], p[(c+2)%3])
+			g := group[tri[c]]
+			acc[g] = add(acc[g], scale(fn, w))
+		}
+	}
+	for v := 0; v < n; v++ {
+		nrm, ok := normalize(acc[group[v]])
+		if !ok {
+			nrm = vec3{0, 1, 0}
+		}
+		o := v*stride + 3
+		md.Vertices[o], md.Vertices[o+1], md.Vertices[o+2] = float32(nrm[0]), float32(nrm[1]), float32(nrm[2])
+	}
+}
+
+// positionGroups maps each vertex to the first vertex with a bit-identical
+// position.
+func positionGroups(verts []float32, n int) []uint32 {
+	first := make(map[[3]uint32]uint32, n)
+	group := make([]uint32, n)
+	for v := 0; v < n; v++ {
+		o := v * stride
+		k := [3]uint32{bits(verts[o]), bits(verts[o+1]), bits(verts[o+2])}
+		if g, ok := first[k]; ok {
+			group[v] = g
+		} else {
+			first[k] = uint32(v)
+			group[v] = uint32(v)
+		}
+	}
+	return group
+}
+
+func bits(f float32) uint32 {
+	if f == 0 {
+		return 0
+	}
+	return math.Float32bits(f)
+}
+
+// cornerAngle is the angle at a between the edges to b and c.
+func cornerAngle(a, b, c vec3) float64 {
+	e1, ok1 := normalize(sub(b, a))
+	e2, ok2 := normalize(sub(c, a))
+	if !ok1 || !ok2 {
+		return 0
+	}
+	return math.Acos(math.Max(-1, math.Min(1, dot(e1, e2))))
+}
@@ -0,0 +1,112 @@
+package meshproc
+
+import (
+	"sort"
+
+	"go-engine/Go-Cordance/internal/engine"
+)
+
+// overdrawCache is the FIFO size used to find cluster boundaries.
+const overdrawCache = 16
+
+// OptimizeOverdraw reorders clusters of triangles so that surfaces facing
+// away from the mesh centre, which tend to occlude the rest, draw first.
+// Run it after OptimizeVertexCache: clusters are cut where that order
+// already restarts (a triangle whose three vertices all miss the cache),
+// so the cache efficiency inside each cluster is kept. The sort is stable,
+// making the result deterministic.
+func OptimizeOverdraw(md *engine.MeshData) {
+	ntri := len(md.Indices) / 3
+	if ntri < 2 {
+		return
+	}
+
+	// Cluster boundaries.
+	var starts []int
+	fifo := make([]uint32, 0, overdrawCache)
+	inFIFO := func(v uint32) bool {
+		for _, c := range fifo {
+			if c == v {
+				return true
+			}
+		}
+		return false
+	}
+	for t := 0; t < ntri; t++ {
+		tri := md.Indices[3*t : 3*t+3]
+		misses := 0
+		for _, v := range tri {
+			if !inFIFO(v) {
+				misses++
+				if len(fifo) == overdrawCache {
+					fifo = fifo[1:]
+				}
+				fifo = append(fifo, v)
+			}
+		}
+		if t == 0 || misses == 3 {
+			starts = append(starts, t)
+		}
+	}
+	if len(starts) < 2 {
+		return
+	}
+
+	// Mesh centre as the area-weighted centroid.
+	var centre vec3
+	var total float64
+	for t := 0; t < ntri; t++ {
+		c, n := triCentroidNormal(md, t)
+		a := length(n)
+		centre = add(centre, scale(c, a))
+		total += a
+	}
+	if total > 0 {
+		centre = scale(centre, 1/total)
+	}
+
+	type cluster struct {
+		start, end int
+		key        float64
+	}
+	clusters := make([]cluster, len(starts))
+	for i, s := range starts {
+		end := ntri
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		var c, n vec3
+		var area float64
+		for t := s; t < end; t++ {
+			tc, tn := triCentroidNormal(md, t)
+			a := length(tn)
+			c = add(c, scale(tc, a))
+			n = add(n, tn)
+			area += a
+		}
+		cl := cluster{start: s, end: end}
+		if area > 0 {
+			c = scale(c, 1/area)
+			if nn, ok := normalize(n); ok {
+				cl.key = dot(sub(c, centre), nn)
+			}
+		}
+		clusters[i] = cl
+	}
+	sort.SliceStable(clusters, func(i, j int) bool { return clusters[i].key > clusters[j].key })
+
+	out := make([]uint32, 0, ntri*3)
+	for _, cl := range clusters {
+		out = append(out, md.Indices[3*cl.start:3*cl.end]...)
+	}
+	md.Indices = out
+}
+
+// triCentroidNormal returns a triangle's centroid and its normal scaled by
+// twice its area.
+func triCentroidNormal(md *engine.MeshData, t int) (vec3, vec3) {
+	a := attr3(md.Vertices, md.Indices[3*t], 0)
+	b := attr3(md.Vertices, md.Indices[3*t+1], 0)
+	c := attr3(md.Vertices, md.Indices[3*t+2], 0)
+	return scale(add(add(a, b), c), 1.0/3), cross(sub(b, a), sub(c, a))
+}
@@ -0,0 +1,231 @@
+package meshproc
+
+import (
+	"math"
+	"sort"
+
+	"go-engine/Go-Cordance/internal/engine"
+)
+
+// quadric is a symmetric 4x4 error quadric (Garland and Heckbert) stored
+// as its upper triangle.
+type quadric [10]float64
+
+func planeQuadric(n vec3, d, w float64) quadric {
+	a, b, c := n[0], n[1], n[2]
+	return quadric{
+		w * a * a, w * a * b, w * a * c, w * a * d,
+		w * b * b, w * b * c, w * b * d,
+		w * c * c, w * c * d,
+		w * d * d,
+	}
+}
+
+func (q *quadric) add(o quadric) {
+	for i := range q {
+		q[i] += o[i]
+	}
+}
+
+// eval returns the squared distance-like error of moving to p.
+func (q quadric) eval(p vec3) float64 {
+	x, y, z := p[0], p[1], p[2]
+	return q[0]*x*x + 2*q[1]*x*y + 2*q[2]*x*z + 2*q[3]*x +
+		q[4]*y*y + 2*q[5]*y*z + 2*q[6]*y +
+		q[7]*z*z + 2*q[8]*z +
+		q[9]
+}
+
+type collapseCandidate struct {
+	from, to uint32
+	cost     float64
+}
+
+// Simplify reduces md towards targetIndexCount indices with quadric-error
+// edge collapses and returns the new index list together with the error
+// reached, relative to the bounding-box diagonal. No collapse whose error
+// exceeds maxError (also relative) is made, so the target may not be met.
+//
+// Each collapse moves one vertex onto a neighbour, so md.Vertices stays
+// valid for the result; run OptimizeVertexFetch on a mesh using it to drop
+// the vertices no longer referenced. Vertices on borders, on non-manifold
+// edges or on attribute seams (one position, several vertices) never move,
+// which keeps silhouettes and UV layouts intact.
+func Simplify(md *engine.MeshData, targetIndexCount int, maxError float32) ([]uint32, float32) {
+	indices := append([]uint32(nil), md.Indices[:len(md.Indices)/3*3]...)
+	n := vertexCount(md)
+	if len(indices) <= targetIndexCount || n == 0 {
+		return indices, 0
+	}
+	verts := md.Vertices
+	pos := func(v uint32) vec3 { return attr3(verts, v, 0) }
+
+	lo, hi := pos(0), pos(0)
+	for v := uint32(1); int(v) < n; v++ {
+		p := pos(v)
+		for i := 0; i < 3; i++ {
+			lo[i], hi[i] = math.Min(lo[i], p[i]), math.Max(hi[i], p[i])
+		}
+	}
+	extent := length(sub(hi, lo))
+	if extent == 0 {
+		return indices, 0
+	}
+	limit := float64(maxError) * extent
+	limit *= limit
+
+	// Seams: positions shared by several vertices.
+	group := positionGroups(verts, n)
+	groupSize := make([]int, n)
+	for _, g := range group {
+		groupSize[g]++
+	}
+	single := func(v uint32) bool { return groupSize[group[v]] == 1 }
+
+	// Quadrics per position from the area-weighted planes around it.
+	q := make([]quadric, n)
+	for i := 0; i+2 < len(indices); i += 3 {
+		a, b, c := pos(indices[i]), pos(indices[i+1]), pos(indices[i+2])
+		nrm := cross(sub(b, a), sub(c, a))
+		area := length(nrm) / 2
+		nu, ok := normalize(nrm)
+		if !ok {
+			continue
+		}
+		pq := planeQuadric(nu, -dot(nu, a), area)
+		for _, v := range indices[i : i+3] {
+			q[group[v]].add(pq)
+		}
+	}
+
+	var reached float64
+	for len(indices) > targetIndexCount {
+		// Borders and non-manifold edges, by position.
+		edgeUse := map[[2]uint32]int{}
+		for i := 0; i+2 < len(indices); i += 3 {
+			for e := 0; e < 3; e++ {
+				edgeUse[posEdge(group, indices[i+e], indices[i+(e+1)%3])]++
+			}
+		}
+		locked := make([]bool, n)
+		for e, uses := range edgeUse {
+			if uses != 2 {
+				locked[e[0]], locked[e[1]] = true, true
+			}
+		}
+		movable := func(v uint32) bool { return single(v) && !locked[group[v]] }
+
+		// Candidates in first-seen order, then by cost.
+		var cands []collapseCandidate
+		seen := map[[2]uint32]bool{}
+		for i := 0; i+2 < len(indices); i += 3 {
+			for e := 0; e < 3; e++ {
+				a, b := indices[i+e], indices[i+(e+1)%3]
+				k := [2]uint32{min(a, b), max(a, b)}
+				if seen[k] {
+					continue
+				}
+				seen[k] = true
+				best := collapseCandidate{cost: math.Inf(1)}
+				for _, d := range [2][2]uint32{{a, b}, {b, a}} {
+					if !movable(d[0]) || !single(d[1]) {
+						continue
+					}
+					qq := q[group[d[0]]]
+					qq.add(q[group[d[1]]])
+					if c := qq.eval(pos(d[1])); c < best.cost {
+						best = collapseCandidate{from: d[0], to: d[1], cost: math.Max(c, 0)}
+					}
+				}
+				if !math.IsInf(best.cost, 1) {
+					cands = append(cands, best)
+				}
+			}
+		}
+		if len(cands) == 0 {
+			break
+		}
+		sort.SliceStable(cands, func(i, j int) bool { return cands[i].cost < cands[j].cost })
+
+		tris := make([][]int, n)
+		for i := 0; i+2 < len(indices); i += 3 {
+			for _, v := range indices[i : i+3] {
+				tris[v] = append(tris[v], i)
+			}
+		}
+
+		goal := (len(indices) - targetIndexCount + 2) / 3
+		removed := 0
+		touched := make([]bool, n)
+		target := make([]uint32, n)
+		for v := range target {
+			target[v] = uint32(v)
+		}
+		applied := 0
+		for _, c := range cands {
+			if c.cost > limit || removed >= goal {
+				break
+			}
+			if touched[c.from] || touched[c.to] || flips(indices, tris[c.from], c.from, c.to, pos) {
+				continue
+			}
+			target[c.from] = c.to
+			q[group[c.to]].add(q[group[c.from]])
+			reached = math.Max(reached, c.cost)
+			applied++
+			for _, t := range tris[c.from] {
+				shared := false
+				for _, v := range indices[t : t+3] {
+					touched[v] = true
+					shared = shared || v == c.to
+				}
+				if shared {
+					removed++
+				}
+			}
+		}
+		if applied == 0 {
+			break
+		}
+
+		out := indices[:0]
+		for i := 0; i+2 < len(indices); i += 3 {
+			a, b, c := target[indices[i]], target[indices[i+1]], target[indices[i+2]]
+			if a != b && b != c && a != c {
+				out = append(out, a, b, c)
+			}
+		}
+		indices = out
+	}
+	return indices, float32(math.Sqrt(reached) / extent)
+}
+
+func posEdge(group []uint32, a, b uint32) [2]uint32 {
+	ga, gb := group[a], group[b]
+	return [2]uint32{min(ga, gb), max(ga, gb)}
+}
+
+// flips reports whether moving from onto to would turn over or collapse
+// to zero area any triangle that keeps both of its other corners.
+func flips(indices []uint32, tris []int, from, to uint32, pos func(uint32) vec3) bool {
+	for _, t := range tris {
+		tri := indices[t : t+3]
+		if tri[0] == to || tri[1] == to || tri[2] == to {
+			continue // removed by the collapse
+		}
+		var before, after [3]vec3
+		for i, v := range tri {
+			before[i] = pos(v)
+			after[i] = before[i]
+			if v == from {
+				after[i] = pos(to)
+			}
+		}
+		n0 := cross(sub(before[1], before[0]), sub(before[2], before[0]))
+		n1 := cross(sub(after[1], after[0]), sub(after[2], after[0]))
+		if dot(n0, n1) <= 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package meshproc
+
+import (
+	"go-engine/Go-Cordance/internal/engine"
+)
+
+type tangentGroupKey struct {
+	attr       [8]uint32 // position, normal, uv
+	preserving bool
+}
+
+// GenerateTangents fills the tangent slots (xyz, handedness in w) the way
+// MikkTSpace does, so normal maps baked by tools that use it light
+// correctly:
+//
+//   - each triangle's tangent comes from its UV gradient, sign-corrected
+//     for mirrored UVs;
+//   - at each corner it is projected onto the vertex normal and weighted
+//     by the corner angle;
+//   - corners are averaged over all triangles whose vertex has the same
+//     position, normal and UV and whose UV winding agrees;
+//   - w is +1 for orientation-preserving UVs and -1 for mirrored ones,
+//     with bitangent = w * cross(normal, tangent).
+//
+// Unlike the reference implementation, corners are not further split by
+// fan connectivity. A vertex used by both mirrored and unmirrored
+// triangles is split in two. The function returns the number of vertices
+// added and sets md.HasTangents.
+func GenerateTangents(md *engine.MeshData) int {
+	n := vertexCount(md)
+	verts := md.Vertices
+
+	groupOf := map[tangentGroupKey]int{}
+	var groupSign []bool
+	var groupTan []vec3
+	cornerGroup := make([]int, len(md.Indices))
+
+	for i := 0; i+2 < len(md.Indices); i += 3 {
+		tri := [3]uint32{md.Indices[i], md.Indices[i+1], md.Indices[i+2]}
+		p := [3]vec3{attr3(verts, tri[0], 0), attr3(verts, tri[1], 0), attr3(verts, tri[2], 0)}
+		var uv [3][2]float64
+		for c, v := range tri {
+			o := int(v)*stride + 6
+			uv[c] = [2]float64{float64(verts[o]), float64(verts[o+1])}
+		}
+		d1, d2 := sub(p[1], p[0]), sub(p[2], p[0])
+		t21 := [2]float64{uv[1][0] - uv[0][0], uv[1][1] - uv[0][1]}
+		t31 := [2]float64{uv[2][0] - uv[0][0], uv[2][1] - uv[0][1]}
+		area := t21[0]*t31[1] - t21[1]*t31[0]
+		preserving := area > 0
+
+		os, osOK := normalize(sub(scale(d1, t31[1]), scale(d2, t21[1])))
+		if !preserving {
+			os = scale(os, -1)
+		}
+		osOK = osOK && area != 0
+
+		for c, v := range tri {
+			k := tangentGroupKey{preserving: preserving}
+			for j := 0; j < 8; j++ {
+				k.attr[j] = bits(verts[int(v)*stride+j])
+			}
+			g, ok := groupOf[k]
+			if !ok {
+				g = len(groupTan)
+				groupOf[k] = g
+				groupTan = append(groupTan, vec3{})
+				groupSign = append(groupSign, preserving)
+			}
+			cornerGroup[i+c] = g
+			if !osOK {
+				continue
+			}
+
+			nrm := attr3(verts, v, 3)
+			t, ok := normalize(sub(os, scale(nrm, dot(nrm, os))))
+			if !ok {
+				continue
+			}
+			e1, ok1 := normalize(projectOnto(sub(p[(c+1)%3], p[c]), nrm))
+			e2, ok2 := normalize(projectOnto(sub(p[(c+2)%3], p[c]), nrm))
+			if !ok1 || !ok2 {
+				continue
+			}
+			w := cornerAngle(vec3{}, e1, e2)
+			groupTan[g] = add(groupTan[g], scale(t, w))
+		}
+	}
+
+	// Give each (vertex, group) pair a vertex: the first keeps the index,
+	// later ones get copies appended in corner order.
+	type pair struct {
+		v uint32
+		g int
+	}
+	slot := map[pair]uint32{}
+	firstGroup := make([]int, n)
+	for i := range firstGroup {
+		firstGroup[i] = -1
+	}
+	added := 0
+	for i, v := range md.Indices {
+		g := cornerGroup[i]
+		if firstGroup[v] == -1 {
+			firstGroup[v] = g
+			slot[pair{v, g}] = v
+		}
+		nv, ok := slot[pair{v, g}]
+		if !ok {
+			nv = uint32(vertexCount(md))
+			md.Vertices = append(md.Vertices, md.Vertices[int(v)*stride:int(v+1)*stride]...)
+			if len(md.Joints) > 0 {
+				md.Joints = append(md.Joints, md.Joints[v])
+			}
+			if len(md.Weights) > 0 {
+				md.Weights = append(md.Weights, md.Weights[v])
+			}
+			if len(md.Colors) > 0 {
+				md.Colors = append(md.Colors, md.Colors[v])
+			}
+			slot[pair{v, g}] = nv
+			added++
+		}
+		md.Indices[i] = nv
+		setTangent(md.Vertices, nv, groupTan[g], groupSign[g])
+	}
+	md.HasTangents = true
+	return added
+}
+
+func projectOnto(v, n vec3) vec3 { return sub(v, scale(n, dot(n, v))) }
+
+// setTangent writes a normalized tangent, or any unit vector perpendicular
+// to the normal when the group had no usable UVs.
+func setTangent(verts []float32, v uint32, t vec3, preserving bool) {
+	nrm := attr3(verts, v, 3)
+	t, ok := normalize(projectOnto(t, nrm))
+	if !ok {
+		axis := vec3{1, 0, 0}
+		if nrm[0]*nrm[0] > 0.5 {
+			axis = vec3{0, 1, 0}
+		}
+		if t, ok = normalize(projectOnto(axis, nrm)); !ok {
+			t = axis
+		}
+	}
+	w := float32(1)
+	if !preserving {
+		w = -1
+	}
+	o := int(v)*stride + 8
+	verts[o], verts[o+1], verts[o+2], verts[o+3] = float32(t[0]), float32(t[1]), float32(t[2]), w
+}
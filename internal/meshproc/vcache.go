@@ -0,0 +1,177 @@
+package meshproc
+
+import (
+	"math"
+
+	"go-engine/Go-Cordance/internal/engine"
+)
+
+// Vertex cache optimisation follows Tom Forsyth's "Linear-Speed Vertex
+// Cache Optimisation": vertices are scored by their position in a
+// simulated LRU cache and by how many unemitted triangles still use them,
+// and the best-scoring triangle next to the cache is emitted next.
+const (
+	cacheSize         = 32
+	cacheDecayPower   = 1.5
+	lastTriScore      = 0.75
+	valenceBoostScale = 2.0
+	valenceBoostPower = 0.5
+)
+
+func vertexScore(cachePos, remaining int) float64 {
+	if remaining == 0 {
+		return -1
+	}
+	score := 0.0
+	switch {
+	case cachePos < 0:
+	case cachePos < 3:
+		score = lastTriScore
+	default:
+		score = math.Pow(1-float64(cachePos-3)/float64(cacheSize-3), cacheDecayPower)
+	}
+	return score + valenceBoostScale*math.Pow(float64(remaining), -valenceBoostPower)
+}
+
+// OptimizeVertexCache reorders triangles for the GPU's post-transform
+// vertex cache. Ties go to the earlier triangle, and when no cached vertex
+// has triangles left the next unemitted triangle in input order starts a
+// new strip, so the result is deterministic.
+func OptimizeVertexCache(md *engine.MeshData) {
+	ntri := len(md.Indices) / 3
+	if ntri == 0 {
+		return
+	}
+	n := vertexCount(md)
+
+	// Triangle lists per vertex.
+	offset := make([]int, n+1)
+	for _, v := range md.Indices[:ntri*3] {
+		offset[v+1]++
+	}
+	for v := 0; v < n; v++ {
+		offset[v+1] += offset[v]
+	}
+	fill := append([]int(nil), offset[:n]...)
+	adj := make([]int, ntri*3)
+	for i, v := range md.Indices[:ntri*3] {
+		adj[fill[v]] = i / 3
+		fill[v]++
+	}
+
+	remaining := make([]int, n)
+	for v := 0; v < n; v++ {
+		remaining[v] = offset[v+1] - offset[v]
+	}
+	cachePos := make([]int, n)
+	score := make([]float64, n)
+	for v := range cachePos {
+		cachePos[v] = -1
+		score[v] = vertexScore(-1, remaining[v])
+	}
+	emitted := make([]bool, ntri)
+	triScore := func(t int) float64 {
+		return score[md.Indices[3*t]] + score[md.Indices[3*t+1]] + score[md.Indices[3*t+2]]
+	}
+
+	out := make([]uint32, 0, ntri*3)
+	var cache []uint32
+	nextInput := 0
+	for len(out) < ntri*3 {
+		// Best triangle touching the cache.
+		best, bestScore := -1, -1.0
+		for _, v := range cache {
+			for _, t := range adj[offset[v]:offset[v+1]] {
+				if emitted[t] {
+					continue
+				}
+				if s := triScore(t); s > bestScore || (s == bestScore && t < best) {
+					best, bestScore = t, s
+				}
+			}
+		}
+		if best < 0 {
+			for emitted[nextInput] {
+				nextInput++
+			}
+			best = nextInput
+		}
+
+		emitted[best] = true
+		tri := md.Indices[3*best : 3*best+3]
+		out = append(out, tri...)
+
+		// Move the triangle's vertices to the front of the LRU cache.
+		next := make([]uint32, 0, cacheSize+3)
+		next = append(next, tri...)
+		for _, v := range cache {
+			if v != tri[0] && v != tri[1] && v != tri[2] {
+				next = append(next, v)
+			}
+		}
+		for _, v := range tri {
+			remaining[v]--
+		}
+		for i, v := range next {
+			if i < cacheSize {
+				cachePos[v] = i
+			} else {
+				cachePos[v] = -1
+			}
+			score[v] = vertexScore(cachePos[v], remaining[v])
+		}
+		if len(next) > cacheSize {
+			next = next[:cacheSize]
+		}
+		cache = next
+	}
+	md.Indices = out
+}
+
+// OptimizeVertexFetch renumbers vertices in order of first use, so the
+// vertex buffer is read front to back, and drops unreferenced vertices.
+func OptimizeVertexFetch(md *engine.MeshData) {
+	n := vertexCount(md)
+	to := make([]uint32, n)
+	for i := range to {
+		to[i] = unused
+	}
+	next := uint32(0)
+	for _, v := range md.Indices {
+		if to[v] == unused {
+			to[v] = next
+			next++
+		}
+	}
+	remap(md, to, int(next))
+}
+
+// ACMR returns the average number of vertex shader invocations per
+// triangle with a FIFO post-transform cache of the given size: 0.5 is the
+// ideal for large regular meshes, 3 the worst case.
+func ACMR(indices []uint32, cache int) float64 {
+	ntri := len(indices) / 3
+	if ntri == 0 {
+		return 0
+	}
+	fifo := make([]uint32, 0, cache)
+	misses := 0
+	for _, v := range indices[:ntri*3] {
+		hit := false
+		for _, c := range fifo {
+			if c == v {
+				hit = true
+				break
+			}
+		}
+		if hit {
+			continue
+		}
+		misses++
+		if len(fifo) == cache {
+			fifo = fifo[1:]
+		}
+		fifo = append(fifo, v)
+	}
+	return float64(misses) / float64(ntri)
+}
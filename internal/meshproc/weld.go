@@ -0,0 +1,64 @@
+package meshproc
+
+import (
+	"math"
+
+	"go-engine/Go-Cordance/internal/engine"
+)
+
+type weldKey struct {
+	attr    [stride]int64
+	joints  [4]uint16
+	weights [4]int64
+	color   [4]int64
+}
+
+// Weld merges vertices whose attributes and streams all match, then drops
+// triangles that collapsed. With eps 0 values must be bit-identical (after
+// folding -0 into 0); otherwise they are snapped to a grid of size eps, so
+// values closer than eps usually, but not always, merge. The first vertex
+// of each group keeps its exact data. It returns the number of vertices
+// removed.
+func Weld(md *engine.MeshData, eps float32) int {
+	q := func(f float32) int64 {
+		if eps > 0 {
+			return int64(math.Round(float64(f) / float64(eps)))
+		}
+		if f == 0 {
+			return 0
+		}
+		return int64(math.Float32bits(f))
+	}
+
+	n := vertexCount(md)
+	to := make([]uint32, n)
+	seen := make(map[weldKey]uint32, n)
+	next := uint32(0)
+	for v := 0; v < n; v++ {
+		var k weldKey
+		for i := 0; i < stride; i++ {
+			k.attr[i] = q(md.Vertices[v*stride+i])
+		}
+		if len(md.Joints) > 0 {
+			k.joints = md.Joints[v]
+		}
+		for i := 0; i < 4; i++ {
+			if len(md.Weights) > 0 {
+				k.weights[i] = q(md.Weights[v][i])
+			}
+			if len(md.Colors) > 0 {
+				k.color[i] = q(md.Colors[v][i])
+			}
+		}
+		if id, ok := seen[k]; ok {
+			to[v] = id
+			continue
+		}
+		seen[k] = next
+		to[v] = next
+		next++
+	}
+	remap(md, to, int(next))
+	RemoveDegenerate(md)
+	return n - int(next)
+}
@@ -0,0 +1,84 @@
+package scene
+
+import (
+	"sort"
+
+	"go-engine/Go-Cordance/internal/assets"
+	"go-engine/Go-Cordance/internal/ecs"
+)
+
+// AssetRefs returns the IDs of the assets the scene's components reference,
+// for assets.Lifetime.Retain.
+func (s *Scene) AssetRefs() []assets.AssetID {
+	refs := referencedAssets(s.Entities())
+	ids := make([]assets.AssetID, len(refs))
+	for i, a := range refs {
+		ids[i] = a.ID
+	}
+	return ids
+}
+
+// referencedAssets collects the model, material, texture, clip and
+// environment assets that ents point at, ordered by path.
+func referencedAssets(ents []*ecs.Entity) []*assets.Asset {
+	meshAssets := map[string]*assets.Asset{}
+	for _, a := range assets.All() {
+		switch d := a.Data.(type) {
+		case string:
+			if a.Type == assets.AssetMesh {
+				meshAssets[d] = a
+			}
+		case []string:
+			for _, id := range d {
+				meshAssets[id] = a
+			}
+		}
+	}
+
+	seen := map[assets.AssetID]*assets.Asset{}
+	add := func(a *assets.Asset) {
+		if a != nil {
+			seen[a.ID] = a
+		}
+	}
+	addMaterial := func(m *ecs.Material) {
+		for _, id := range []assets.AssetID{m.MaterialAsset, m.TextureAsset, m.NormalAsset, m.OcclusionAsset, m.MetallicRoughnessAsset} {
+			add(assets.Get(id))
+		}
+	}
+	for _, e := range ents {
+		if c := e.GetComponent((*ecs.Mesh)(nil)); c != nil {
+			add(meshAssets[c.(*ecs.Mesh).ID])
+		}
+		if c := e.GetComponent((*ecs.MultiMesh)(nil)); c != nil {
+			for _, id := range c.(*ecs.MultiMesh).Meshes {
+				add(meshAssets[id])
+			}
+		}
+		if c := e.GetComponent((*ecs.Material)(nil)); c != nil {
+			addMaterial(c.(*ecs.Material))
+		}
+		if c := e.GetComponent((*ecs.MultiMaterial)(nil)); c != nil {
+			for _, m := range c.(*ecs.MultiMaterial).Materials {
+				addMaterial(m)
+			}
+		}
+		if c := e.GetComponent((*ecs.AnimationPlayer)(nil)); c != nil {
+			ap := c.(*ecs.AnimationPlayer)
+			add(assets.Get(ap.ClipAsset))
+			for _, path := range ap.ClipSources {
+				add(assets.FindAssetByPath(path))
+			}
+		}
+		if c := e.GetComponent((*ecs.Environment)(nil)); c != nil {
+			add(assets.FindAssetByPath(c.(*ecs.Environment).Path))
+		}
+	}
+
+	out := make([]*assets.Asset, 0, len(seen))
+	for _, a := range seen {
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
@@ -0,0 +1,46 @@
+package scene
+
+import (
+	"image"
+
+	"go-engine/Go-Cordance/internal/ecs"
+	"go-engine/Go-Cordance/internal/engine"
+)
+
+// Capture renders the scene with rs into a width x height image, seen
+// from its active Camera entity or, without one, from the scene camera.
+// The scene is not updated first; call Update to settle transforms.
+func (s *Scene) Capture(rs *ecs.RenderSystem, width, height int) (*image.RGBA, error) {
+	var cam *ecs.Camera
+	if !s.hasActiveCamera() {
+		c := s.camera
+		cam = &ecs.Camera{
+			Position: c.Position,
+			Target:   c.Target,
+			Up:       c.Up,
+			Fov:      c.Fov,
+			Near:     c.Near,
+			Far:      c.Far,
+			Active:   true,
+		}
+	}
+	return rs.Capture(s.entities, cam, width, height)
+}
+
+// CapturePNG is Capture writing the image to a PNG file at path.
+func (s *Scene) CapturePNG(rs *ecs.RenderSystem, path string, width, height int) error {
+	img, err := s.Capture(rs, width, height)
+	if err != nil {
+		return err
+	}
+	return engine.WritePNG(path, img)
+}
+
+func (s *Scene) hasActiveCamera() bool {
+	for _, e := range s.entities {
+		if cam, ok := e.GetComponent((*ecs.Camera)(nil)).(*ecs.Camera); ok && cam.Active {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,750 @@
+package gltf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go-engine/Go-Cordance/internal/assets"
+	"go-engine/Go-Cordance/internal/ecs"
+	"go-engine/Go-Cordance/internal/engine"
+	"go-engine/Go-Cordance/internal/scene"
+)
+
+// MeshSource provides the CPU-side mesh data written by the exporter.
+// *engine.MeshManager implements it.
+type MeshSource interface {
+	CPUMesh(id string) (engine.CPUMesh, bool)
+	SkinWeights(id string) ([][4]uint16, [][4]float32, bool)
+}
+
+// ExportScene writes every root entity of s (and their subtrees) to a
+// .gltf (with a sibling .bin) or .glb file.
+func ExportScene(s *scene.Scene, meshes MeshSource, path string) error {
+	var roots []*ecs.Entity
+	for _, e := range s.Entities() {
+		if p, ok := e.GetComponent((*ecs.Parent)(nil)).(*ecs.Parent); ok && p.Entity != nil {
+			continue
+		}
+		roots = append(roots, e)
+	}
+	return exportRoots(roots, meshes, path)
+}
+
+// ExportEntity writes root and its descendants; root becomes the single
+// scene root, keeping its local transform.
+func ExportEntity(root *ecs.Entity, meshes MeshSource, path string) error {
+	return exportRoots([]*ecs.Entity{root}, meshes, path)
+}
+
+func exportRoots(roots []*ecs.Entity, meshes MeshSource, path string) error {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".gltf" && ext != ".glb" {
+		return fmt.Errorf("export: unsupported format %q (want .gltf or .glb)", ext)
+	}
+
+	x := &exporter{
+		meshes: meshes,
+		dir:    filepath.Dir(path),
+		nodeOf: make(map[*ecs.Entity]int),
+		meshOf: make(map[exMeshKey]int),
+		geomOf: make(map[exGeomKey]exGeom),
+		matOf:  make(map[*ecs.Material]int),
+		skinOf: make(map[*ecs.Skin]int),
+		texOf:  make(map[string]int),
+	}
+	x.doc.Asset = exAsset{Version: "2.0", Generator: "Go-Cordance"}
+	x.doc.Scenes = []exScene{{Nodes: []int{}}}
+
+	for _, r := range roots {
+		x.doc.Scenes[0].Nodes = append(x.doc.Scenes[0].Nodes, x.addNode(r))
+	}
+	// meshes, skins and animations reference nodes, so they come second
+	for _, e := range x.order {
+		x.addMesh(e)
+	}
+	for _, e := range x.order {
+		x.addAnimations(e)
+	}
+
+	if ext == ".glb" {
+		return x.writeGLB(path)
+	}
+	return x.writeGLTF(path)
+}
+
+// ---------------------------
+// Output document
+// ---------------------------
+
+type exAsset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator,omitempty"`
+}
+
+type exScene struct {
+	Nodes []int `json:"nodes"`
+}
+
+type exNode struct {
+	Name        string    `json:"name,omitempty"`
+	Children    []int     `json:"children,omitempty"`
+	Translation []float32 `json:"translation,omitempty"`
+	Rotation    []float32 `json:"rotation,omitempty"`
+	Scale       []float32 `json:"scale,omitempty"`
+	Mesh        *int      `json:"mesh,omitempty"`
+	Skin        *int      `json:"skin,omitempty"`
+}
+
+type exBuffer struct {
+	ByteLength int    `json:"byteLength"`
+	URI        string `json:"uri,omitempty"`
+}
+
+type exBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	ByteStride int `json:"byteStride,omitempty"`
+	Target     int `json:"target,omitempty"`
+}
+
+type exAccessor struct {
+	BufferView    int       `json:"bufferView"`
+	ComponentType int       `json:"componentType"`
+	Count         int       `json:"count"`
+	Type          string    `json:"type"`
+	Min           []float32 `json:"min,omitempty"`
+	Max           []float32 `json:"max,omitempty"`
+}
+
+type exPrimitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    *int           `json:"indices,omitempty"`
+	Material   *int           `json:"material,omitempty"`
+}
+
+type exMesh struct {
+	Name       string        `json:"name,omitempty"`
+	Primitives []exPrimitive `json:"primitives"`
+}
+
+type exTextureInfo struct {
+	Index      int            `json:"index"`
+	TexCoord   int            `json:"texCoord,omitempty"`
+	Scale      *float32       `json:"scale,omitempty"` // normalTexture only
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+type exPBR struct {
+	BaseColorFactor          []float32      `json:"baseColorFactor"`
+	BaseColorTexture         *exTextureInfo `json:"baseColorTexture,omitempty"`
+	MetallicFactor           float32        `json:"metallicFactor"`
+	RoughnessFactor          float32        `json:"roughnessFactor"`
+	MetallicRoughnessTexture *exTextureInfo `json:"metallicRoughnessTexture,omitempty"`
+}
+
+type exMaterial struct {
+	Name             string         `json:"name,omitempty"`
+	PBR              exPBR          `json:"pbrMetallicRoughness"`
+	NormalTexture    *exTextureInfo `json:"normalTexture,omitempty"`
+	OcclusionTexture *exTextureInfo `json:"occlusionTexture,omitempty"`
+	AlphaMode        string         `json:"alphaMode,omitempty"`
+	AlphaCutoff      *float32       `json:"alphaCutoff,omitempty"` // MASK only
+	DoubleSided      bool           `json:"doubleSided,omitempty"`
+}
+
+type exTexture struct {
+	Source int `json:"source"`
+}
+
+type exImage struct {
+	URI string `json:"uri"`
+}
+
+type exSkin struct {
+	Joints              []int `json:"joints"`
+	InverseBindMatrices *int  `json:"inverseBindMatrices,omitempty"`
+}
+
+type exAnimSampler struct {
+	Input         int    `json:"input"`
+	Output        int    `json:"output"`
+	Interpolation string `json:"interpolation"`
+}
+
+type exAnimChannel struct {
+	Sampler int `json:"sampler"`
+	Target  struct {
+		Node int    `json:"node"`
+		Path string `json:"path"`
+	} `json:"target"`
+}
+
+type exAnimation struct {
+	Name     string          `json:"name,omitempty"`
+	Channels []exAnimChannel `json:"channels"`
+	Samplers []exAnimSampler `json:"samplers"`
+}
+
+type exDoc struct {
+	Asset          exAsset        `json:"asset"`
+	ExtensionsUsed []string       `json:"extensionsUsed,omitempty"`
+	Scene          int            `json:"scene"`
+	Scenes         []exScene      `json:"scenes"`
+	Nodes          []exNode       `json:"nodes,omitempty"`
+	Meshes         []exMesh       `json:"meshes,omitempty"`
+	Materials      []exMaterial   `json:"materials,omitempty"`
+	Textures       []exTexture    `json:"textures,omitempty"`
+	Images         []exImage      `json:"images,omitempty"`
+	Skins          []exSkin       `json:"skins,omitempty"`
+	Animations     []exAnimation  `json:"animations,omitempty"`
+	Accessors      []exAccessor   `json:"accessors,omitempty"`
+	BufferViews    []exBufferView `json:"bufferViews,omitempty"`
+	Buffers        []exBuffer     `json:"buffers,omitempty"`
+}
+
+// ---------------------------
+// Exporter
+// ---------------------------
+
+const (
+	glFloat         = 5126
+	glUnsignedShort = 5123
+	glUnsignedInt   = 5125
+
+	glArrayBuffer        = 34962
+	glElementArrayBuffer = 34963
+)
+
+type exMeshKey struct {
+	id  string
+	mat *ecs.Material
+}
+
+type exGeomKey struct {
+	id      string
+	skinned bool
+}
+
+// exGeom is the attribute/index accessors of one mesh, shared by every
+// material variant of it.
+type exGeom struct {
+	attributes map[string]int
+	indices    int
+}
+
+type exporter struct {
+	doc    exDoc
+	bin    bytes.Buffer
+	meshes MeshSource
+	dir    string
+
+	order  []*ecs.Entity
+	nodeOf map[*ecs.Entity]int
+	meshOf map[exMeshKey]int
+	geomOf map[exGeomKey]exGeom
+	matOf  map[*ecs.Material]int
+	skinOf map[*ecs.Skin]int
+	texOf  map[string]int
+}
+
+func (x *exporter) addNode(e *ecs.Entity) int {
+	idx := len(x.doc.Nodes)
+	x.nodeOf[e] = idx
+	x.order = append(x.order, e)
+
+	n := exNode{Name: entityName(e)}
+	if tr := e.GetTransform(); tr != nil {
+		if tr.Position != ([3]float32{}) {
+			n.Translation = tr.Position[:]
+		}
+		if q := normalizeQuat(tr.Rotation); q != [4]float32{0, 0, 0, 1} {
+			n.Rotation = q[:]
+		}
+		if tr.Scale != [3]float32{1, 1, 1} {
+			n.Scale = tr.Scale[:]
+		}
+	}
+	x.doc.Nodes = append(x.doc.Nodes, n)
+
+	if ch, ok := e.GetComponent((*ecs.Children)(nil)).(*ecs.Children); ok {
+		for _, c := range ch.Entities {
+			if c == nil {
+				continue
+			}
+			if _, seen := x.nodeOf[c]; seen {
+				continue // a node may only have one parent
+			}
+			ci := x.addNode(c)
+			x.doc.Nodes[idx].Children = append(x.doc.Nodes[idx].Children, ci)
+		}
+	}
+	return idx
+}
+
+func entityName(e *ecs.Entity) string {
+	if n, ok := e.GetComponent((*ecs.Name)(nil)).(*ecs.Name); ok && n.Value != "" {
+		return n.Value
+	}
+	return fmt.Sprintf("Entity_%d", e.ID)
+}
+
+// addMesh attaches the entity's Mesh (and Skin, if all its joints were
+// exported) to its node.
+func (x *exporter) addMesh(e *ecs.Entity) {
+	m, ok := e.GetComponent((*ecs.Mesh)(nil)).(*ecs.Mesh)
+	if !ok || m.ID == "" {
+		if _, multi := e.GetComponent((*ecs.MultiMesh)(nil)).(*ecs.MultiMesh); multi {
+			log.Printf("gltf export: %s has a MultiMesh, which the exporter does not support; skipped", entityName(e))
+		}
+		return
+	}
+	data, ok := x.meshes.CPUMesh(m.ID)
+	if !ok || len(data.Vertices) < 12 {
+		log.Printf("gltf export: mesh %q has no CPU data; skipped", m.ID)
+		return
+	}
+
+	skinIdx := -1
+	var joints [][4]uint16
+	var weights [][4]float32
+	if sk, ok := e.GetComponent((*ecs.Skin)(nil)).(*ecs.Skin); ok {
+		js, ws, ok := x.meshes.SkinWeights(m.ID)
+		if ok && len(js) == len(data.Vertices)/12 && len(ws) == len(js) {
+			if skinIdx = x.addSkin(sk); skinIdx >= 0 {
+				joints, weights = js, ws
+			}
+		}
+	}
+
+	mat, _ := e.GetComponent((*ecs.Material)(nil)).(*ecs.Material)
+	key := exMeshKey{id: m.ID, mat: mat}
+	if skinIdx >= 0 {
+		key.id += "#skinned"
+	}
+	meshIdx, ok := x.meshOf[key]
+	if !ok {
+		geom := x.addGeometry(m.ID, data, joints, weights)
+		prim := exPrimitive{Attributes: geom.attributes, Indices: intPtr(geom.indices)}
+		if mat != nil {
+			prim.Material = intPtr(x.addMaterial(mat))
+		}
+		meshIdx = len(x.doc.Meshes)
+		x.doc.Meshes = append(x.doc.Meshes, exMesh{Name: m.ID, Primitives: []exPrimitive{prim}})
+		x.meshOf[key] = meshIdx
+	}
+
+	n := &x.doc.Nodes[x.nodeOf[e]]
+	n.Mesh = intPtr(meshIdx)
+	if skinIdx >= 0 {
+		n.Skin = intPtr(skinIdx)
+	}
+}
+
+func (x *exporter) addGeometry(id string, data engine.CPUMesh, joints [][4]uint16, weights [][4]float32) exGeom {
+	key := exGeomKey{id: id, skinned: joints != nil}
+	if g, ok := x.geomOf[key]; ok {
+		return g
+	}
+
+	count := len(data.Vertices) / 12
+	pos := make([]float32, 0, count*3)
+	nrm := make([]float32, 0, count*3)
+	uv := make([]float32, 0, count*2)
+	tan := make([]float32, 0, count*4)
+	validTangents := true
+	for i := 0; i < count; i++ {
+		v := data.Vertices[i*12 : i*12+12]
+		pos = append(pos, v[0], v[1], v[2])
+		n := normalize3([3]float32{v[3], v[4], v[5]})
+		if n == ([3]float32{}) {
+			n = [3]float32{0, 1, 0}
+		}
+		nrm = append(nrm, n[0], n[1], n[2])
+		uv = append(uv, v[6], v[7])
+		t := normalize3([3]float32{v[8], v[9], v[10]})
+		w := float32(1)
+		if v[11] < 0 {
+			w = -1
+		}
+		if t == ([3]float32{}) {
+			validTangents = false
+		}
+		tan = append(tan, t[0], t[1], t[2], w)
+	}
+
+	g := exGeom{attributes: map[string]int{}}
+	pmin, pmax := minMax(pos, 3)
+	g.attributes["POSITION"] = x.floatAccessor(pos, "VEC3", 3, glArrayBuffer, pmin, pmax)
+	g.attributes["NORMAL"] = x.floatAccessor(nrm, "VEC3", 3, glArrayBuffer, nil, nil)
+	g.attributes["TEXCOORD_0"] = x.floatAccessor(uv, "VEC2", 2, glArrayBuffer, nil, nil)
+	if validTangents {
+		g.attributes["TANGENT"] = x.floatAccessor(tan, "VEC4", 4, glArrayBuffer, nil, nil)
+	}
+
+	if joints != nil {
+		var jb bytes.Buffer
+		binary.Write(&jb, binary.LittleEndian, joints)
+		view := x.addView(jb.Bytes(), 8, glArrayBuffer)
+		g.attributes["JOINTS_0"] = x.addAccessor(exAccessor{BufferView: view, ComponentType: glUnsignedShort, Count: len(joints), Type: "VEC4"})
+
+		ws := make([]float32, 0, len(weights)*4)
+		for _, w := range weights {
+			s := w[0] + w[1] + w[2] + w[3]
+			if s <= 0 {
+				w, s = [4]float32{1, 0, 0, 0}, 1
+			}
+			ws = append(ws, w[0]/s, w[1]/s, w[2]/s, w[3]/s)
+		}
+		g.attributes["WEIGHTS_0"] = x.floatAccessor(ws, "VEC4", 4, glArrayBuffer, nil, nil)
+	}
+
+	var ib bytes.Buffer
+	binary.Write(&ib, binary.LittleEndian, data.Indices)
+	view := x.addView(ib.Bytes(), 0, glElementArrayBuffer)
+	g.indices = x.addAccessor(exAccessor{BufferView: view, ComponentType: glUnsignedInt, Count: len(data.Indices), Type: "SCALAR"})
+
+	x.geomOf[key] = g
+	return g
+}
+
+func (x *exporter) addSkin(sk *ecs.Skin) int {
+	if i, ok := x.skinOf[sk]; ok {
+		return i
+	}
+	if len(sk.JointEntities) == 0 {
+		return -1
+	}
+	s := exSkin{}
+	for _, je := range sk.JointEntities {
+		ni, ok := x.nodeOf[je]
+		if !ok {
+			log.Printf("gltf export: skin joint outside the exported subtree; skin skipped")
+			return -1
+		}
+		s.Joints = append(s.Joints, ni)
+	}
+	if len(sk.InverseBindMatrices) == len(sk.JointEntities) {
+		flat := make([]float32, 0, len(sk.InverseBindMatrices)*16)
+		for _, m := range sk.InverseBindMatrices {
+			flat = append(flat, m[:]...)
+		}
+		s.InverseBindMatrices = intPtr(x.floatAccessor(flat, "MAT4", 16, 0, nil, nil))
+	}
+	x.doc.Skins = append(x.doc.Skins, s)
+	x.skinOf[sk] = len(x.doc.Skins) - 1
+	return x.skinOf[sk]
+}
+
+func (x *exporter) addMaterial(m *ecs.Material) int {
+	if i, ok := x.matOf[m]; ok {
+		return i
+	}
+	out := exMaterial{PBR: exPBR{
+		BaseColorFactor: clampColor(m.BaseColor),
+		MetallicFactor:  clamp01(m.Metallic),
+		RoughnessFactor: clamp01(m.Roughness),
+	}}
+
+	texPath := func(path string, asset assets.AssetID) string {
+		if path != "" {
+			return path
+		}
+		if a := assets.Get(asset); asset != 0 && a != nil {
+			return a.Path
+		}
+		return ""
+	}
+	out.PBR.BaseColorTexture = x.textureInfo(m, "baseColor", texPath(m.DiffuseTexturePath, m.TextureAsset))
+	out.PBR.MetallicRoughnessTexture = x.textureInfo(m, "metallicRoughness", texPath(m.MetallicRoughnessTexturePath, m.MetallicRoughnessAsset))
+	out.NormalTexture = x.textureInfo(m, "normal", texPath(m.NormalTexturePath, m.NormalAsset))
+	if out.NormalTexture != nil && m.NormalScale != 0 {
+		out.NormalTexture.Scale = &m.NormalScale
+	}
+	out.OcclusionTexture = x.textureInfo(m, "occlusion", texPath(m.OcclusionTexturePath, m.OcclusionAsset))
+
+	if m.AlphaMode != engine.AlphaOpaque {
+		out.AlphaMode = m.AlphaMode.String()
+	}
+	if m.AlphaMode == engine.AlphaMask {
+		cutoff := m.AlphaCutoff
+		out.AlphaCutoff = &cutoff
+	}
+	out.DoubleSided = m.DoubleSided
+
+	x.matOf[m] = len(x.doc.Materials)
+	x.doc.Materials = append(x.doc.Materials, out)
+	return x.matOf[m]
+}
+
+// textureInfo references the image at path (relative to the output file)
+// and carries the material's texCoord and KHR_texture_transform for slot.
+func (x *exporter) textureInfo(m *ecs.Material, slot, path string) *exTextureInfo {
+	if path == "" {
+		return nil
+	}
+	ti, ok := x.texOf[path]
+	if !ok {
+		uri := filepath.ToSlash(path)
+		if abs, err := filepath.Abs(path); err == nil {
+			if absDir, err := filepath.Abs(x.dir); err == nil {
+				if rel, err := filepath.Rel(absDir, abs); err == nil {
+					uri = filepath.ToSlash(rel)
+				}
+			}
+		}
+		x.doc.Images = append(x.doc.Images, exImage{URI: uri})
+		x.doc.Textures = append(x.doc.Textures, exTexture{Source: len(x.doc.Images) - 1})
+		ti = len(x.doc.Textures) - 1
+		x.texOf[path] = ti
+	}
+
+	info := &exTextureInfo{Index: ti, TexCoord: m.TexCoordMap[slot]}
+	scale, hasScale := m.UVScale[slot]
+	offset, hasOffset := m.UVOffset[slot]
+	if hasScale || hasOffset {
+		if !hasScale {
+			scale = [2]float32{1, 1}
+		}
+		info.Extensions = map[string]any{
+			"KHR_texture_transform": map[string]any{"offset": offset, "scale": scale},
+		}
+		x.useExtension("KHR_texture_transform")
+	}
+	return info
+}
+
+func (x *exporter) useExtension(name string) {
+	for _, e := range x.doc.ExtensionsUsed {
+		if e == name {
+			return
+		}
+	}
+	x.doc.ExtensionsUsed = append(x.doc.ExtensionsUsed, name)
+}
+
+// addAnimations writes the clips of an AnimationPlayer; track node indices
+// resolve the same way AnimationSystem does.
+func (x *exporter) addAnimations(e *ecs.Entity) {
+	player, ok := e.GetComponent((*ecs.AnimationPlayer)(nil)).(*ecs.AnimationPlayer)
+	if !ok || len(player.Clips) == 0 {
+		return
+	}
+	nodes := player.NodeEntities
+	if sk, ok := e.GetComponent((*ecs.Skeleton)(nil)).(*ecs.Skeleton); ok {
+		nodes = sk.Nodes
+	}
+
+	names := make([]string, 0, len(player.Clips))
+	for name := range player.Clips {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		clip := player.Clips[name]
+		anim := exAnimation{Name: clip.Name}
+		if anim.Name == "" {
+			anim.Name = name
+		}
+		for _, track := range clip.Tracks {
+			target, ok := x.nodeOf[track.Target(e, nodes)]
+			if !ok {
+				continue
+			}
+			x.addTrack(&anim, target, track)
+		}
+		if len(anim.Channels) > 0 {
+			x.doc.Animations = append(x.doc.Animations, anim)
+		}
+	}
+}
+
+func (x *exporter) addTrack(anim *exAnimation, node int, track ecs.AnimationTrack) {
+	// input times must be strictly increasing
+	var ks []ecs.TransformKeyframe
+	for _, k := range track.Keyframes {
+		if len(ks) > 0 && k.Time <= ks[len(ks)-1].Time {
+			continue
+		}
+		ks = append(ks, k)
+	}
+	if dropped := len(track.Keyframes) - len(ks); dropped > 0 {
+		log.Printf("gltf export: animation %q, node %q: %d keys with non-increasing times dropped", anim.Name, x.doc.Nodes[node].Name, dropped)
+	}
+	hasPos := track.Animates(ecs.ChannelTranslation)
+	hasRot := track.Animates(ecs.ChannelRotation)
+	hasScale := track.Animates(ecs.ChannelScale)
+	if len(ks) == 0 || !hasPos && !hasRot && !hasScale {
+		return
+	}
+
+	times := make([]float32, len(ks))
+	var pos, rot, scl []float32
+	for i, k := range ks {
+		times[i] = k.Time
+		pos = append(pos, k.Position[:]...)
+		q := normalizeQuat(k.Rotation)
+		rot = append(rot, q[:]...)
+		scl = append(scl, k.Scale[:]...)
+	}
+	input := x.floatAccessor(times, "SCALAR", 1, 0, []float32{times[0]}, []float32{times[len(times)-1]})
+
+	channel := func(path string, data []float32, typ string, n int) {
+		out := x.floatAccessor(data, typ, n, 0, nil, nil)
+		anim.Samplers = append(anim.Samplers, exAnimSampler{Input: input, Output: out, Interpolation: "LINEAR"})
+		ch := exAnimChannel{Sampler: len(anim.Samplers) - 1}
+		ch.Target.Node = node
+		ch.Target.Path = path
+		anim.Channels = append(anim.Channels, ch)
+	}
+	if hasPos {
+		channel("translation", pos, "VEC3", 3)
+	}
+	if hasRot {
+		channel("rotation", rot, "VEC4", 4)
+	}
+	if hasScale {
+		channel("scale", scl, "VEC3", 3)
+	}
+}
+
+// ---------------------------
+// Binary buffer
+// ---------------------------
+
+// addView appends data to the binary buffer, 4-byte aligned.
+func (x *exporter) addView(data []byte, stride, target int) int {
+	for x.bin.Len()%4 != 0 {
+		x.bin.WriteByte(0)
+	}
+	x.doc.BufferViews = append(x.doc.BufferViews, exBufferView{
+		Buffer:     0,
+		ByteOffset: x.bin.Len(),
+		ByteLength: len(data),
+		ByteStride: stride,
+		Target:     target,
+	})
+	x.bin.Write(data)
+	return len(x.doc.BufferViews) - 1
+}
+
+func (x *exporter) addAccessor(a exAccessor) int {
+	x.doc.Accessors = append(x.doc.Accessors, a)
+	return len(x.doc.Accessors) - 1
+}
+
+// floatAccessor writes tightly packed floats. Vertex attributes get a
+// byteStride (required when a view is used by vertex attributes).
+func (x *exporter) floatAccessor(data []float32, typ string, n, target int, min, max []float32) int {
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, data)
+	stride := 0
+	if target == glArrayBuffer {
+		stride = n * 4
+	}
+	view := x.addView(b.Bytes(), stride, target)
+	return x.addAccessor(exAccessor{
+		BufferView:    view,
+		ComponentType: glFloat,
+		Count:         len(data) / n,
+		Type:          typ,
+		Min:           min,
+		Max:           max,
+	})
+}
+
+func (x *exporter) writeGLTF(path string) error {
+	if x.bin.Len() > 0 {
+		binName := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)) + ".bin"
+		x.doc.Buffers = []exBuffer{{ByteLength: x.bin.Len(), URI: binName}}
+		if err := os.WriteFile(filepath.Join(x.dir, binName), x.bin.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+	js, err := json.MarshalIndent(x.doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, js, 0644)
+}
+
+func (x *exporter) writeGLB(path string) error {
+	if x.bin.Len() > 0 {
+		x.doc.Buffers = []exBuffer{{ByteLength: x.bin.Len()}}
+	}
+	js, err := json.Marshal(x.doc)
+	if err != nil {
+		return err
+	}
+	for len(js)%4 != 0 {
+		js = append(js, ' ')
+	}
+	bin := x.bin.Bytes()
+	for len(bin)%4 != 0 {
+		bin = append(bin, 0)
+	}
+
+	total := 12 + 8 + len(js)
+	if len(bin) > 0 {
+		total += 8 + len(bin)
+	}
+	var out bytes.Buffer
+	out.WriteString("glTF")
+	binary.Write(&out, binary.LittleEndian, uint32(2))
+	binary.Write(&out, binary.LittleEndian, uint32(total))
+	binary.Write(&out, binary.LittleEndian, uint32(len(js)))
+	out.WriteString("JSON")
+	out.Write(js)
+	if len(bin) > 0 {
+		binary.Write(&out, binary.LittleEndian, uint32(len(bin)))
+		out.WriteString("BIN\x00")
+		out.Write(bin)
+	}
+	return os.WriteFile(path, out.Bytes(), 0644)
+}
+
+// ---------------------------
+// Helpers
+// ---------------------------
+
+func intPtr(i int) *int { return &i }
+
+func minMax(data []float32, n int) ([]float32, []float32) {
+	if len(data) < n {
+		return nil, nil
+	}
+	lo := append([]float32(nil), data[:n]...)
+	hi := append([]float32(nil), data[:n]...)
+	for i := n; i+n <= len(data); i += n {
+		for c := 0; c < n; c++ {
+			lo[c] = float32(math.Min(float64(lo[c]), float64(data[i+c])))
+			hi[c] = float32(math.Max(float64(hi[c]), float64(data[i+c])))
+		}
+	}
+	return lo, hi
+}
+
+func normalizeQuat(q [4]float32) [4]float32 {
+	l := float32(math.Sqrt(float64(q[0]*q[0] + q[1]*q[1] + q[2]*q[2] + q[3]*q[3])))
+	if l == 0 {
+		return [4]float32{0, 0, 0, 1}
+	}
+	return [4]float32{q[0] / l, q[1] / l, q[2] / l, q[3] / l}
+}
+
+func clamp01(v float32) float32 {
+	return float32(math.Min(1, math.Max(0, float64(v))))
+}
+
+func clampColor(c [4]float32) []float32 {
+	return []float32{clamp01(c[0]), clamp01(c[1]), clamp01(c[2]), clamp01(c[3])}
+}
@@ -0,0 +1,630 @@
+package gltf
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-engine/Go-Cordance/internal/ecs"
+	"go-engine/Go-Cordance/internal/engine"
+	"go-engine/Go-Cordance/internal/scene"
+)
+
+type fakeMeshes struct {
+	cpu     map[string]engine.CPUMesh
+	joints  map[string][][4]uint16
+	weights map[string][][4]float32
+}
+
+func (f fakeMeshes) CPUMesh(id string) (engine.CPUMesh, bool) {
+	m, ok := f.cpu[id]
+	return m, ok
+}
+
+func (f fakeMeshes) SkinWeights(id string) ([][4]uint16, [][4]float32, bool) {
+	js, ok := f.joints[id]
+	return js, f.weights[id], ok
+}
+
+// quad is a unit quad in the 12-float layout.
+func quad() engine.CPUMesh {
+	return engine.CPUMesh{
+		Vertices: []float32{
+			-1, 0, -1, 0, 1, 0, 0, 0, 1, 0, 0, 1,
+			1, 0, -1, 0, 1, 0, 1, 0, 1, 0, 0, 1,
+			1, 0, 1, 0, 1, 0, 1, 1, 1, 0, 0, 1,
+			-1, 0, 1, 0, 1, 0, 0, 1, 1, 0, 0, 1,
+		},
+		Indices: []uint32{0, 2, 1, 0, 3, 2},
+	}
+}
+
+func attach(parent, child *ecs.Entity) {
+	child.AddComponent(ecs.NewParent(parent))
+	ch, ok := parent.GetComponent((*ecs.Children)(nil)).(*ecs.Children)
+	if !ok {
+		ch = ecs.NewChildren()
+		parent.AddComponent(ch)
+	}
+	ch.AddChild(child)
+}
+
+func buildExportScene() (*scene.Scene, fakeMeshes) {
+	s := scene.New()
+
+	root := s.AddEntity()
+	root.AddComponent(ecs.NewName("Level"))
+	root.AddComponent(ecs.NewTransform([3]float32{1, 2, 3}))
+
+	floor := s.AddEntity()
+	floor.AddComponent(ecs.NewTransform([3]float32{0, 0, 0}))
+	floor.AddComponent(ecs.NewMesh("floor"))
+	mat := ecs.NewMaterial([4]float32{0.5, 0.5, 0.5, 1})
+	mat.DiffuseTexturePath = "textures/floor.png"
+	mat.AlphaMode, mat.AlphaCutoff, mat.DoubleSided = engine.AlphaMask, 0.25, true
+	floor.AddComponent(mat)
+	attach(root, floor)
+
+	joint := s.AddEntity()
+	joint.AddComponent(ecs.NewTransform([3]float32{0, 1, 0}))
+	attach(root, joint)
+
+	flag := s.AddEntity()
+	flag.AddComponent(ecs.NewTransform([3]float32{0, 0, 0}))
+	flag.AddComponent(ecs.NewMesh("flag"))
+	sk := ecs.NewSkin([]int{0}, [][16]float32{engine.IdentityMatrix()}, 0)
+	sk.JointEntities = []*ecs.Entity{joint}
+	flag.AddComponent(sk)
+	attach(root, flag)
+
+	clip := &ecs.AnimationClip{Name: "wave", Duration: 1, Tracks: []ecs.AnimationTrack{{
+		NodeIndex: 0,
+		Keyframes: []ecs.TransformKeyframe{
+			{Time: 0, Position: [3]float32{0, 1, 0}, Rotation: [4]float32{0, 0, 0, 1}, Scale: [3]float32{1, 1, 1}},
+			{Time: 1, Position: [3]float32{0, 2, 0}, Rotation: [4]float32{0, 0, 0, 2}, Scale: [3]float32{1, 1, 1}},
+		},
+	}}}
+	root.AddComponent(&ecs.AnimationPlayer{
+		Clips:        map[string]*ecs.AnimationClip{"wave": clip},
+		NodeEntities: []*ecs.Entity{joint},
+	})
+
+	meshes := fakeMeshes{
+		cpu:     map[string]engine.CPUMesh{"floor": quad(), "flag": quad()},
+		joints:  map[string][][4]uint16{"flag": make([][4]uint16, 4)},
+		weights: map[string][][4]float32{"flag": {{1}, {1}, {0.5}, {2}}},
+	}
+	return s, meshes
+}
+
+func TestExport_PassesValidationAndReloads(t *testing.T) {
+	s, meshes := buildExportScene()
+
+	for _, name := range []string{"level.gltf", "level.glb"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := ExportScene(s, meshes, path); err != nil {
+				t.Fatalf("export: %v", err)
+			}
+
+			doc, bin := readExported(t, path)
+			for _, err := range validateGLTF(doc, bin) {
+				t.Error(err)
+			}
+
+			g, buffers, err := engine.LoadGLTFOrGLB(path)
+			if err != nil {
+				t.Fatalf("reload: %v", err)
+			}
+			if len(g.Nodes) != 4 || len(g.Meshes) != 2 || len(g.Skins) != 1 || len(g.Animations) != 1 {
+				t.Fatalf("reloaded nodes=%d meshes=%d skins=%d anims=%d",
+					len(g.Nodes), len(g.Meshes), len(g.Skins), len(g.Animations))
+			}
+			if g.Nodes[0].Name != "Level" || len(g.Nodes[0].Children) != 3 {
+				t.Fatalf("root node = %+v", g.Nodes[0])
+			}
+			pos, err := engine.GetAccessor(g, buffers, g.Meshes[0].Primitives[0].Attributes["POSITION"])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if x, z := pos.Float(2, 0), pos.Float(2, 2); x != 1 || z != 1 {
+				t.Fatalf("vertex 2 = %v,%v", x, z)
+			}
+			mats, err := engine.LoadGLTFMaterialsMulti(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			floor := mats[0]
+			if floor.AlphaMode != engine.AlphaMask || floor.AlphaCutoff != 0.25 || !floor.DoubleSided {
+				t.Fatalf("floor material alpha %v cutoff %v doubleSided %v", floor.AlphaMode, floor.AlphaCutoff, floor.DoubleSided)
+			}
+		})
+	}
+}
+
+func TestExport_EntitySubtree(t *testing.T) {
+	s, meshes := buildExportScene()
+	floor := s.Entities()[1]
+
+	path := filepath.Join(t.TempDir(), "floor.gltf")
+	if err := ExportEntity(floor, meshes, path); err != nil {
+		t.Fatal(err)
+	}
+	doc, bin := readExported(t, path)
+	for _, err := range validateGLTF(doc, bin) {
+		t.Error(err)
+	}
+	if len(doc.Nodes) != 1 || len(doc.Skins) != 0 || len(doc.Animations) != 0 {
+		t.Fatalf("subtree export: nodes=%d skins=%d anims=%d", len(doc.Nodes), len(doc.Skins), len(doc.Animations))
+	}
+	if len(doc.Images) != 1 || filepath.IsAbs(doc.Images[0].URI) {
+		t.Fatalf("images = %+v, want one relative uri", doc.Images)
+	}
+}
+
+func TestExport_AnimatedChannelsOnly(t *testing.T) {
+	s := scene.New()
+	e := s.AddEntity()
+	e.AddComponent(ecs.NewTransform([3]float32{0, 0, 0}))
+	turn := [4]float32{0, 0.7071068, 0, 0.7071068}
+	clip := &ecs.AnimationClip{Name: "turn", Duration: 1, Tracks: []ecs.AnimationTrack{{
+		NodeIndex: ecs.SelfNode,
+		Channels:  ecs.ChannelRotation,
+		Keyframes: []ecs.TransformKeyframe{
+			{Time: 0, Rotation: [4]float32{0, 0, 0, 1}, Scale: [3]float32{1, 1, 1}},
+			{Time: 1, Rotation: turn, Scale: [3]float32{1, 1, 1}},
+			{Time: 1, Rotation: turn, Scale: [3]float32{1, 1, 1}},
+		},
+	}}}
+	e.AddComponent(&ecs.AnimationPlayer{Clips: map[string]*ecs.AnimationClip{"turn": clip}})
+
+	path := filepath.Join(t.TempDir(), "turn.gltf")
+	if err := ExportScene(s, fakeMeshes{}, path); err != nil {
+		t.Fatal(err)
+	}
+	doc, bin := readExported(t, path)
+	for _, err := range validateGLTF(doc, bin) {
+		t.Error(err)
+	}
+	if len(doc.Animations) != 1 || len(doc.Animations[0].Channels) != 1 {
+		t.Fatalf("animations = %+v, want one channel", doc.Animations)
+	}
+	a := doc.Animations[0]
+	if p := a.Channels[0].Target.Path; p != "rotation" {
+		t.Errorf("exported %s for a rotation-only track", p)
+	}
+	if n := doc.Accessors[a.Samplers[0].Input].Count; n != 2 {
+		t.Errorf("input has %d keys, want the repeated time dropped", n)
+	}
+}
+
+// ---------------------------
+// Validator (port of the Khronos glTF-Validator rules we rely on)
+// ---------------------------
+
+type vDoc struct {
+	Asset struct {
+		Version string `json:"version"`
+	} `json:"asset"`
+	Scene  *int `json:"scene"`
+	Scenes []struct {
+		Nodes []int `json:"nodes"`
+	} `json:"scenes"`
+	Nodes []struct {
+		Name        string    `json:"name"`
+		Children    []int     `json:"children"`
+		Rotation    []float32 `json:"rotation"`
+		Translation []float32 `json:"translation"`
+		Scale       []float32 `json:"scale"`
+		Mesh        *int      `json:"mesh"`
+		Skin        *int      `json:"skin"`
+	} `json:"nodes"`
+	Meshes []struct {
+		Primitives []struct {
+			Attributes map[string]int `json:"attributes"`
+			Indices    *int           `json:"indices"`
+			Material   *int           `json:"material"`
+		} `json:"primitives"`
+	} `json:"meshes"`
+	Materials []struct {
+		PBR struct {
+			BaseColorFactor  []float32 `json:"baseColorFactor"`
+			BaseColorTexture *struct {
+				Index int `json:"index"`
+			} `json:"baseColorTexture"`
+		} `json:"pbrMetallicRoughness"`
+	} `json:"materials"`
+	Textures []struct {
+		Source *int `json:"source"`
+	} `json:"textures"`
+	Images []struct {
+		URI string `json:"uri"`
+	} `json:"images"`
+	Skins []struct {
+		Joints              []int `json:"joints"`
+		InverseBindMatrices *int  `json:"inverseBindMatrices"`
+	} `json:"skins"`
+	Animations []struct {
+		Channels []struct {
+			Sampler int `json:"sampler"`
+			Target  struct {
+				Node *int   `json:"node"`
+				Path string `json:"path"`
+			} `json:"target"`
+		} `json:"channels"`
+		Samplers []struct {
+			Input         int    `json:"input"`
+			Output        int    `json:"output"`
+			Interpolation string `json:"interpolation"`
+		} `json:"samplers"`
+	} `json:"animations"`
+	Accessors []struct {
+		BufferView    *int      `json:"bufferView"`
+		ByteOffset    int       `json:"byteOffset"`
+		ComponentType int       `json:"componentType"`
+		Count         int       `json:"count"`
+		Type          string    `json:"type"`
+		Min           []float32 `json:"min"`
+		Max           []float32 `json:"max"`
+	} `json:"accessors"`
+	BufferViews []struct {
+		Buffer     int `json:"buffer"`
+		ByteOffset int `json:"byteOffset"`
+		ByteLength int `json:"byteLength"`
+		ByteStride int `json:"byteStride"`
+		Target     int `json:"target"`
+	} `json:"bufferViews"`
+	Buffers []struct {
+		ByteLength int    `json:"byteLength"`
+		URI        string `json:"uri"`
+	} `json:"buffers"`
+}
+
+func readExported(t *testing.T, path string) (vDoc, [][]byte) {
+	t.Helper()
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc vDoc
+	var bins [][]byte
+	if filepath.Ext(path) == ".glb" {
+		if string(raw[:4]) != "glTF" || int(binary.LittleEndian.Uint32(raw[8:])) != len(raw) {
+			t.Fatal("bad GLB header")
+		}
+		jsonLen := int(binary.LittleEndian.Uint32(raw[12:]))
+		if jsonLen%4 != 0 {
+			t.Fatal("GLB JSON chunk not padded to 4 bytes")
+		}
+		if err := json.Unmarshal(raw[20:20+jsonLen], &doc); err != nil {
+			t.Fatal(err)
+		}
+		off := 20 + jsonLen
+		if off < len(raw) {
+			binLen := int(binary.LittleEndian.Uint32(raw[off:]))
+			bins = append(bins, raw[off+8:off+8+binLen])
+		}
+		return doc, bins
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range doc.Buffers {
+		data, err := os.ReadFile(filepath.Join(filepath.Dir(path), b.URI))
+		if err != nil {
+			t.Fatal(err)
+		}
+		bins = append(bins, data)
+	}
+	return doc, bins
+}
+
+var vComponents = map[string]int{"SCALAR": 1, "VEC2": 2, "VEC3": 3, "VEC4": 4, "MAT4": 16}
+var vCompSize = map[int]int{5121: 1, 5123: 2, 5125: 4, 5126: 4}
+
+func validateGLTF(d vDoc, bins [][]byte) []error {
+	var errs []error
+	fail := func(format string, args ...any) { errs = append(errs, fmt.Errorf(format, args...)) }
+	inRange := func(what string, i, n int) bool {
+		if i < 0 || i >= n {
+			fail("%s: index %d out of range (%d)", what, i, n)
+			return false
+		}
+		return true
+	}
+
+	if d.Asset.Version != "2.0" {
+		fail("asset.version = %q", d.Asset.Version)
+	}
+
+	// buffers / views
+	for i, b := range d.Buffers {
+		if i >= len(bins) || len(bins[i]) < b.ByteLength || len(bins[i]) > b.ByteLength+3 {
+			fail("BUFFER_BYTE_LENGTH_MISMATCH: buffer %d", i)
+		}
+	}
+	for i, v := range d.BufferViews {
+		if !inRange("bufferView.buffer", v.Buffer, len(d.Buffers)) {
+			continue
+		}
+		if v.ByteOffset+v.ByteLength > d.Buffers[v.Buffer].ByteLength {
+			fail("BUFFER_VIEW_TOO_LONG: bufferView %d", i)
+		}
+		if v.Target == 34963 && v.ByteStride != 0 {
+			fail("BUFFER_VIEW_INVALID_BYTE_STRIDE: index view %d has byteStride", i)
+		}
+		if v.ByteStride != 0 && (v.ByteStride < 4 || v.ByteStride > 252 || v.ByteStride%4 != 0) {
+			fail("bufferView %d byteStride %d", i, v.ByteStride)
+		}
+	}
+
+	// accessors
+	floats := func(ai int) [][]float32 {
+		a := d.Accessors[ai]
+		v := d.BufferViews[*a.BufferView]
+		n, cs := vComponents[a.Type], vCompSize[a.ComponentType]
+		stride := v.ByteStride
+		if stride == 0 {
+			stride = n * cs
+		}
+		out := make([][]float32, a.Count)
+		for i := range out {
+			base := v.ByteOffset + a.ByteOffset + i*stride
+			for c := 0; c < n; c++ {
+				b := bins[v.Buffer][base+c*cs:]
+				switch a.ComponentType {
+				case 5126:
+					out[i] = append(out[i], math.Float32frombits(binary.LittleEndian.Uint32(b)))
+				case 5125:
+					out[i] = append(out[i], float32(binary.LittleEndian.Uint32(b)))
+				case 5123:
+					out[i] = append(out[i], float32(binary.LittleEndian.Uint16(b)))
+				default:
+					out[i] = append(out[i], float32(b[0]))
+				}
+			}
+		}
+		return out
+	}
+	for i, a := range d.Accessors {
+		n, cs := vComponents[a.Type], vCompSize[a.ComponentType]
+		if n == 0 || cs == 0 {
+			fail("accessor %d: invalid type %s/%d", i, a.Type, a.ComponentType)
+			continue
+		}
+		if a.BufferView == nil || !inRange("accessor.bufferView", *a.BufferView, len(d.BufferViews)) {
+			continue
+		}
+		v := d.BufferViews[*a.BufferView]
+		if (v.ByteOffset+a.ByteOffset)%cs != 0 {
+			fail("ACCESSOR_TOTAL_OFFSET_ALIGNMENT: accessor %d", i)
+		}
+		stride := v.ByteStride
+		if stride == 0 {
+			stride = n * cs
+		}
+		if stride < n*cs {
+			fail("ACCESSOR_SMALL_BYTESTRIDE: accessor %d", i)
+		}
+		if a.Count > 0 && a.ByteOffset+stride*(a.Count-1)+n*cs > v.ByteLength {
+			fail("ACCESSOR_TOO_LONG: accessor %d", i)
+			continue
+		}
+		if a.Min != nil || a.Max != nil {
+			data := floats(i)
+			for c := 0; c < n && len(data) > 0; c++ {
+				lo, hi := data[0][c], data[0][c]
+				for _, e := range data {
+					lo, hi = float32(math.Min(float64(lo), float64(e[c]))), float32(math.Max(float64(hi), float64(e[c])))
+				}
+				if len(a.Min) != n || len(a.Max) != n || a.Min[c] != lo || a.Max[c] != hi {
+					fail("ACCESSOR_MIN_MAX_MISMATCH: accessor %d", i)
+					break
+				}
+			}
+		}
+	}
+	unit := func(what string, v []float32, tol float64) {
+		var l float64
+		for _, x := range v {
+			l += float64(x) * float64(x)
+		}
+		if math.Abs(math.Sqrt(l)-1) > tol {
+			fail("%s not unit length: %v", what, v)
+		}
+	}
+
+	// nodes / hierarchy
+	parents := make([]int, len(d.Nodes))
+	for i := range parents {
+		parents[i] = -1
+	}
+	for i, n := range d.Nodes {
+		for _, c := range n.Children {
+			if !inRange("node.children", c, len(d.Nodes)) {
+				continue
+			}
+			if parents[c] >= 0 {
+				fail("NODE_MULTIPLE_PARENTS: node %d", c)
+			}
+			parents[c] = i
+		}
+		if n.Rotation != nil {
+			unit(fmt.Sprintf("node %d rotation", i), n.Rotation, 1e-5)
+		}
+		if n.Mesh != nil {
+			inRange("node.mesh", *n.Mesh, len(d.Meshes))
+		}
+		if n.Skin != nil {
+			inRange("node.skin", *n.Skin, len(d.Skins))
+			if n.Mesh == nil {
+				fail("NODE_SKIN_WITH_NON_SKINNED_MESH: node %d has skin but no mesh", i)
+			}
+		}
+	}
+	for i := range d.Nodes {
+		seen := map[int]bool{}
+		for p := i; p >= 0; p = parents[p] {
+			if seen[p] {
+				fail("NODE_LOOP: node %d", i)
+				break
+			}
+			seen[p] = true
+		}
+	}
+	if d.Scene != nil {
+		inRange("scene", *d.Scene, len(d.Scenes))
+	}
+	for _, sc := range d.Scenes {
+		for _, r := range sc.Nodes {
+			if inRange("scene.nodes", r, len(d.Nodes)) && parents[r] >= 0 {
+				fail("SCENE_NON_ROOT_NODE: node %d", r)
+			}
+		}
+	}
+
+	// meshes
+	for mi, m := range d.Meshes {
+		for pi, p := range m.Primitives {
+			count := -1
+			for name, ai := range p.Attributes {
+				if !inRange("attribute "+name, ai, len(d.Accessors)) {
+					continue
+				}
+				a := d.Accessors[ai]
+				if count >= 0 && a.Count != count {
+					fail("MESH_PRIMITIVE_UNEQUAL_ACCESSOR_COUNT: mesh %d/%d", mi, pi)
+				}
+				count = a.Count
+				switch name {
+				case "POSITION":
+					if a.Min == nil || a.Max == nil {
+						fail("MESH_PRIMITIVE_POSITION_ACCESSOR_WITHOUT_BOUNDS: mesh %d/%d", mi, pi)
+					}
+				case "NORMAL":
+					for _, n := range floats(ai) {
+						unit("NORMAL", n, 5e-4)
+					}
+				case "TANGENT":
+					for _, tv := range floats(ai) {
+						unit("TANGENT", tv[:3], 5e-4)
+						if tv[3] != 1 && tv[3] != -1 {
+							fail("ACCESSOR_INVALID_SIGN: tangent w %v", tv[3])
+						}
+					}
+				case "WEIGHTS_0":
+					for _, w := range floats(ai) {
+						if s := w[0] + w[1] + w[2] + w[3]; math.Abs(float64(s-1)) > 5e-4 {
+							fail("ACCESSOR_WEIGHTS_NON_NORMALIZED: sum %v", s)
+						}
+					}
+				}
+			}
+			if _, ok := p.Attributes["POSITION"]; !ok {
+				fail("mesh %d/%d has no POSITION", mi, pi)
+			}
+			if p.Indices != nil && inRange("indices", *p.Indices, len(d.Accessors)) {
+				a := d.Accessors[*p.Indices]
+				if a.Type != "SCALAR" || a.Count%3 != 0 {
+					fail("MESH_PRIMITIVE_INDICES: mesh %d/%d", mi, pi)
+				}
+				if d.BufferViews[*a.BufferView].Target != 34963 {
+					fail("MESH_PRIMITIVE_INDICES_ACCESSOR_INVALID_TARGET: mesh %d/%d", mi, pi)
+				}
+				for _, idx := range floats(*p.Indices) {
+					if int(idx[0]) >= count {
+						fail("ACCESSOR_INDEX_OOB: mesh %d/%d index %v", mi, pi, idx[0])
+						break
+					}
+				}
+			}
+			if p.Material != nil {
+				inRange("primitive.material", *p.Material, len(d.Materials))
+			}
+		}
+	}
+
+	// skins: joints must be in range and used JOINTS must index them
+	for i, n := range d.Nodes {
+		if n.Skin == nil || n.Mesh == nil || *n.Skin >= len(d.Skins) || *n.Mesh >= len(d.Meshes) {
+			continue
+		}
+		sk := d.Skins[*n.Skin]
+		for _, p := range d.Meshes[*n.Mesh].Primitives {
+			ai, ok := p.Attributes["JOINTS_0"]
+			if !ok {
+				fail("NODE_SKINNED_MESH_WITHOUT_JOINTS: node %d", i)
+				continue
+			}
+			for _, j := range floats(ai) {
+				for _, x := range j {
+					if int(x) >= len(sk.Joints) {
+						fail("ACCESSOR_JOINTS_INDEX_OOB: node %d joint %v", i, x)
+					}
+				}
+			}
+		}
+	}
+	for i, sk := range d.Skins {
+		for _, j := range sk.Joints {
+			inRange("skin.joints", j, len(d.Nodes))
+		}
+		if sk.InverseBindMatrices != nil && inRange("skin.inverseBindMatrices", *sk.InverseBindMatrices, len(d.Accessors)) {
+			if d.Accessors[*sk.InverseBindMatrices].Count < len(sk.Joints) {
+				fail("INVALID_IBM_ACCESSOR_COUNT: skin %d", i)
+			}
+		}
+	}
+
+	// materials / textures
+	for i, m := range d.Materials {
+		if len(m.PBR.BaseColorFactor) != 4 {
+			fail("material %d baseColorFactor", i)
+		}
+		if m.PBR.BaseColorTexture != nil {
+			inRange("baseColorTexture", m.PBR.BaseColorTexture.Index, len(d.Textures))
+		}
+	}
+	for _, tx := range d.Textures {
+		if tx.Source != nil {
+			inRange("texture.source", *tx.Source, len(d.Images))
+		}
+	}
+
+	// animations
+	for ai, a := range d.Animations {
+		for _, ch := range a.Channels {
+			if !inRange("channel.sampler", ch.Sampler, len(a.Samplers)) || ch.Target.Node == nil {
+				continue
+			}
+			inRange("channel.target.node", *ch.Target.Node, len(d.Nodes))
+			s := a.Samplers[ch.Sampler]
+			if !inRange("sampler.input", s.Input, len(d.Accessors)) || !inRange("sampler.output", s.Output, len(d.Accessors)) {
+				continue
+			}
+			in, out := d.Accessors[s.Input], d.Accessors[s.Output]
+			if in.Min == nil || in.Max == nil {
+				fail("ANIMATION_SAMPLER_INPUT_ACCESSOR_WITHOUT_BOUNDS: animation %d", ai)
+			}
+			times := floats(s.Input)
+			for k := 1; k < len(times); k++ {
+				if times[k][0] <= times[k-1][0] {
+					fail("ACCESSOR_ANIMATION_INPUT_NON_INCREASING: animation %d", ai)
+				}
+			}
+			if s.Interpolation == "LINEAR" && out.Count != in.Count {
+				fail("ANIMATION_SAMPLER_OUTPUT_ACCESSOR_INVALID_COUNT: animation %d", ai)
+			}
+			if ch.Target.Path == "rotation" {
+				for _, q := range floats(s.Output) {
+					unit("animation rotation", q, 1e-5)
+				}
+			}
+		}
+	}
+	return errs
+}
@@ -26,17 +26,28 @@ func LoadGLTFAnimations(path string) (map[string]*ecs.AnimationClip, error) {
 		var duration float32
 		// nodeIndex -> []TransformKeyframe
 		perNode := map[int][]ecs.TransformKeyframe{}
+		channels := map[int]ecs.TrackChannels{}
 
 		for _, ch := range anim.Channels {
+			switch ch.Target.Path {
+			case "translation", "rotation", "scale":
+			default:
+				continue // morph target weights are not animated
+			}
 			sampler := anim.Samplers[ch.Sampler]
 
-			inputAcc, _ := engine.GetAccessor(g, buffers, sampler.Input)
-			outputAcc, _ := engine.GetAccessor(g, buffers, sampler.Output)
+			inputAcc, err := engine.GetAccessor(g, buffers, sampler.Input)
+			if err != nil {
+				return nil, fmt.Errorf("animation %s: %w", name, err)
+			}
+			outputAcc, err := engine.GetAccessor(g, buffers, sampler.Output)
+			if err != nil {
+				return nil, fmt.Errorf("animation %s: %w", name, err)
+			}
 
 			times := make([]float32, inputAcc.Acc.Count)
 			for i := 0; i < inputAcc.Acc.Count; i++ {
-				off := inputAcc.Base + i*inputAcc.Stride
-				t := engine.BytesToFloat32(inputAcc.Buf[off:])
+				t := inputAcc.Float(i, 0)
 				times[i] = t
 				if t > duration {
 					duration = t
@@ -57,34 +68,33 @@ func LoadGLTFAnimations(path string) (map[string]*ecs.AnimationClip, error) {
 				kf := kfs[i]
 				kf.Time = t
 
-				off := outputAcc.Base + i*outputAcc.Stride
-
+				// outputs may be normalized integers (e.g. quantized rotations);
+				// CUBICSPLINE stores in-tangent, value, out-tangent per key
+				o, vi := outputAcc, i
+				if sampler.Interpolation == "CUBICSPLINE" {
+					vi = 3*i + 1
+				}
 				switch ch.Target.Path {
 				case "translation":
-					kf.Position = [3]float32{
-						engine.BytesToFloat32(outputAcc.Buf[off+0:]),
-						engine.BytesToFloat32(outputAcc.Buf[off+4:]),
-						engine.BytesToFloat32(outputAcc.Buf[off+8:]),
-					}
+					kf.Position = [3]float32{o.Float(vi, 0), o.Float(vi, 1), o.Float(vi, 2)}
 				case "rotation":
-					kf.Rotation = [4]float32{
-						engine.BytesToFloat32(outputAcc.Buf[off+0:]),
-						engine.BytesToFloat32(outputAcc.Buf[off+4:]),
-						engine.BytesToFloat32(outputAcc.Buf[off+8:]),
-						engine.BytesToFloat32(outputAcc.Buf[off+12:]),
-					}
+					kf.Rotation = [4]float32{o.Float(vi, 0), o.Float(vi, 1), o.Float(vi, 2), o.Float(vi, 3)}
 				case "scale":
-					kf.Scale = [3]float32{
-						engine.BytesToFloat32(outputAcc.Buf[off+0:]),
-						engine.BytesToFloat32(outputAcc.Buf[off+4:]),
-						engine.BytesToFloat32(outputAcc.Buf[off+8:]),
-					}
+					kf.Scale = [3]float32{o.Float(vi, 0), o.Float(vi, 1), o.Float(vi, 2)}
 				}
 
 				kfs[i] = kf
 			}
 
 			perNode[nodeIndex] = kfs
+			switch ch.Target.Path {
+			case "translation":
+				channels[nodeIndex] |= ecs.ChannelTranslation
+			case "rotation":
+				channels[nodeIndex] |= ecs.ChannelRotation
+			case "scale":
+				channels[nodeIndex] |= ecs.ChannelScale
+			}
 		}
 
 		clip.Duration = duration
@@ -96,6 +106,7 @@ func LoadGLTFAnimations(path string) (map[string]*ecs.AnimationClip, error) {
 			clip.Tracks = append(clip.Tracks, ecs.AnimationTrack{
 				NodeIndex: nodeIdx,
 				Keyframes: kfs,
+				Channels:  channels[nodeIdx],
 			})
 		}
 
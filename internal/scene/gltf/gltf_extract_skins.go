@@ -160,6 +160,11 @@ func ExtractGLTFSkins(path string) (map[string]*ecs.Skin, error) {
 				// Trust the glTF data. Do NOT transpose. Do NOT recompute.
 				data.ibm = append(data.ibm, m)
 			}
+		} else {
+			// no inverseBindMatrices: each one is the identity
+			for range s.Joints {
+				data.ibm = append(data.ibm, engine.IdentityMatrix())
+			}
 		}
 		// --- recompute IBMs from node bind-pose and replace accessor IBMs when inconsistent ---
 		// --- recompute IBMs from node bind-pose and replace accessor IBMs when inconsistent ---
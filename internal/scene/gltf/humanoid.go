@@ -3,6 +3,8 @@ package gltf
 import (
 	"go-engine/Go-Cordance/internal/ecs"
 	"go-engine/Go-Cordance/internal/engine"
+
+	"github.com/go-gl/mathgl/mgl32"
 )
 
 type HumanoidBone int
@@ -27,62 +29,84 @@ const (
 	HumanoidRightUpperLeg
 	HumanoidRightLowerLeg
 	HumanoidRightFoot
+
+	humanoidBoneCount
 )
 
+// humanoidBoneNames are the names used in rig profile JSON.
+var humanoidBoneNames = [humanoidBoneCount]string{
+	"Hips", "Spine", "Chest", "Neck", "Head",
+	"LeftShoulder", "LeftUpperArm", "LeftLowerArm", "LeftHand",
+	"RightShoulder", "RightUpperArm", "RightLowerArm", "RightHand",
+	"LeftUpperLeg", "LeftLowerLeg", "LeftFoot",
+	"RightUpperLeg", "RightLowerLeg", "RightFoot",
+}
+
+func (b HumanoidBone) String() string {
+	if b < 0 || b >= humanoidBoneCount {
+		return "Unknown"
+	}
+	return humanoidBoneNames[b]
+}
+
+// ParseHumanoidBone converts a profile bone name back to a HumanoidBone.
+func ParseHumanoidBone(name string) (HumanoidBone, bool) {
+	for i, n := range humanoidBoneNames {
+		if n == name {
+			return HumanoidBone(i), true
+		}
+	}
+	return 0, false
+}
+
 type HumanoidRig struct {
 	Nodes      []*ecs.Entity        // same as Skeleton.Nodes
 	BoneToNode map[HumanoidBone]int // bone -> glTF node index
 	NodeToBone map[int]HumanoidBone // optional reverse map
+	Profile    string               // name of the rig profile used for mapping
+
+	// Bind pose, indexed by glTF node index. Retargeting works relative to it.
+	Parents   []int
+	BindLocal []ecs.TransformKeyframe
+	BindWorld []mgl32.Mat4
 }
 
-// BuildHumanoidRigFromGLTF builds a rig using node names.
+// BuildHumanoidRigFromGLTF builds a rig using node names, picking the
+// registered rig profile that matches the most bones.
 func BuildHumanoidRigFromGLTF(g *engine.GltfRoot, nodes []*ecs.Entity) *HumanoidRig {
+	names := make([]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		names[i] = n.Name
+	}
+
+	profile := DetectRigProfile(names)
+	if profile == nil {
+		profile = &RigProfile{Name: "none"}
+	}
+	return BuildHumanoidRigWithProfile(g, nodes, profile)
+}
+
+// BuildHumanoidRigWithProfile builds a rig using an explicit profile.
+// Bones the profile cannot resolve are mapped to -1.
+func BuildHumanoidRigWithProfile(g *engine.GltfRoot, nodes []*ecs.Entity, profile *RigProfile) *HumanoidRig {
 	rig := &HumanoidRig{
 		Nodes:      nodes,
 		BoneToNode: make(map[HumanoidBone]int),
 		NodeToBone: make(map[int]HumanoidBone),
+		Profile:    profile.Name,
 	}
 
-	// helper
-	find := func(pred func(name string) bool) int {
-		for i, n := range g.Nodes {
-			if pred(n.Name) {
-				return i
-			}
-		}
-		return -1
+	names := make([]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		names[i] = n.Name
 	}
 
-	// crawling_man naming (from your dump):
-	// pelvis, spine_01, spine_02, spine_03, neck_01, head,
-	// clavicle_l, upperarm_l, lowerarm_l, hand_l,
-	// clavicle_r, upperarm_r, lowerarm_r, hand_r,
-	// thigh_l, calf_l, foot_l,
-	// thigh_r, calf_r, foot_r
-
-	rig.BoneToNode[HumanoidHips] = find(func(n string) bool { return n == "pelvis" })
-	rig.BoneToNode[HumanoidSpine] = find(func(n string) bool { return n == "spine_01" })
-	rig.BoneToNode[HumanoidChest] = find(func(n string) bool { return n == "spine_03" })
-	rig.BoneToNode[HumanoidNeck] = find(func(n string) bool { return n == "neck_01" })
-	rig.BoneToNode[HumanoidHead] = find(func(n string) bool { return n == "head" })
-
-	rig.BoneToNode[HumanoidLeftShoulder] = find(func(n string) bool { return n == "clavicle_l" })
-	rig.BoneToNode[HumanoidLeftUpperArm] = find(func(n string) bool { return n == "upperarm_l" })
-	rig.BoneToNode[HumanoidLeftLowerArm] = find(func(n string) bool { return n == "lowerarm_l" })
-	rig.BoneToNode[HumanoidLeftHand] = find(func(n string) bool { return n == "hand_l" })
-
-	rig.BoneToNode[HumanoidRightShoulder] = find(func(n string) bool { return n == "clavicle_r" })
-	rig.BoneToNode[HumanoidRightUpperArm] = find(func(n string) bool { return n == "upperarm_r" })
-	rig.BoneToNode[HumanoidRightLowerArm] = find(func(n string) bool { return n == "lowerarm_r" })
-	rig.BoneToNode[HumanoidRightHand] = find(func(n string) bool { return n == "hand_r" })
-
-	rig.BoneToNode[HumanoidLeftUpperLeg] = find(func(n string) bool { return n == "thigh_l" })
-	rig.BoneToNode[HumanoidLeftLowerLeg] = find(func(n string) bool { return n == "calf_l" })
-	rig.BoneToNode[HumanoidLeftFoot] = find(func(n string) bool { return n == "foot_l" })
-
-	rig.BoneToNode[HumanoidRightUpperLeg] = find(func(n string) bool { return n == "thigh_r" })
-	rig.BoneToNode[HumanoidRightLowerLeg] = find(func(n string) bool { return n == "calf_r" })
-	rig.BoneToNode[HumanoidRightFoot] = find(func(n string) bool { return n == "foot_r" })
+	for b := HumanoidBone(0); b < humanoidBoneCount; b++ {
+		rig.BoneToNode[b] = -1
+	}
+	for b, idx := range profile.match(names) {
+		rig.BoneToNode[b] = idx
+	}
 
 	// build reverse map
 	for b, idx := range rig.BoneToNode {
@@ -91,41 +115,122 @@ func BuildHumanoidRigFromGLTF(g *engine.GltfRoot, nodes []*ecs.Entity) *Humanoid
 		}
 	}
 
+	rig.buildBindPose(g)
 	return rig
 }
-func RetargetClip(srcRig, dstRig *HumanoidRig, srcClip *ecs.AnimationClip) *ecs.AnimationClip {
-	boneMap := BuildBoneMap(srcRig, dstRig)
 
-	dstClip := &ecs.AnimationClip{
-		Name:     srcClip.Name + "_retargeted",
-		Duration: srcClip.Duration,
-		Tracks:   []ecs.AnimationTrack{},
-	}
+// buildBindPose records the rest TRS of every node plus its model-space matrix.
+func (rig *HumanoidRig) buildBindPose(g *engine.GltfRoot) {
+	n := len(g.Nodes)
+	rig.Parents = make([]int, n)
+	rig.BindLocal = make([]ecs.TransformKeyframe, n)
+	rig.BindWorld = make([]mgl32.Mat4, n)
 
-	for _, track := range srcClip.Tracks {
-		dstNode, ok := boneMap[track.NodeIndex]
-		if !ok {
-			continue
+	for i := range rig.Parents {
+		rig.Parents[i] = -1
+	}
+	for i, node := range g.Nodes {
+		for _, c := range node.Children {
+			if c >= 0 && c < n {
+				rig.Parents[c] = i
+			}
 		}
+		rig.BindLocal[i] = nodeRestTRS(node)
+	}
 
-		newTrack := ecs.AnimationTrack{
-			NodeIndex: dstNode,
-			Keyframes: track.Keyframes, // naive copy for now
+	done := make([]bool, n)
+	var world func(i int) mgl32.Mat4
+	world = func(i int) mgl32.Mat4 {
+		if done[i] {
+			return rig.BindWorld[i]
 		}
+		kf := rig.BindLocal[i]
+		m := trsMat4(kf.Position, kf.Rotation, kf.Scale)
+		if p := rig.Parents[i]; p >= 0 {
+			m = world(p).Mul4(m)
+		}
+		rig.BindWorld[i] = m
+		done[i] = true
+		return m
+	}
+	for i := 0; i < n; i++ {
+		world(i)
+	}
+}
+
+// nodeRestTRS returns the glTF rest transform of a node, applying the
+// spec defaults for missing fields.
+func nodeRestTRS(n engine.GltfNode) ecs.TransformKeyframe {
+	if len(n.Matrix) == 16 {
+		var m [16]float32
+		copy(m[:], n.Matrix)
+		pos, rot, scl := engine.DecomposeTRS(m)
+		return ecs.TransformKeyframe{Position: pos, Rotation: rot, Scale: scl}
+	}
 
-		dstClip.Tracks = append(dstClip.Tracks, newTrack)
+	kf := ecs.TransformKeyframe{
+		Rotation: [4]float32{0, 0, 0, 1},
+		Scale:    [3]float32{1, 1, 1},
+	}
+	if len(n.Translation) == 3 {
+		copy(kf.Position[:], n.Translation)
+	}
+	if len(n.Rotation) == 4 {
+		copy(kf.Rotation[:], n.Rotation)
+	}
+	if len(n.Scale) == 3 {
+		copy(kf.Scale[:], n.Scale)
 	}
+	return kf
+}
+
+func trsMat4(pos [3]float32, rot [4]float32, scl [3]float32) mgl32.Mat4 {
+	return mgl32.Translate3D(pos[0], pos[1], pos[2]).
+		Mul4(toQuat(rot).Mat4()).
+		Mul4(mgl32.Scale3D(scl[0], scl[1], scl[2]))
+}
 
-	return dstClip
+func toQuat(q [4]float32) mgl32.Quat {
+	return mgl32.Quat{W: q[3], V: mgl32.Vec3{q[0], q[1], q[2]}}
+}
+
+func fromQuat(q mgl32.Quat) [4]float32 {
+	return [4]float32{q.V[0], q.V[1], q.V[2], q.W}
 }
 
 func BuildBoneMap(src, dst *HumanoidRig) map[int]int {
 	out := map[int]int{}
 	for bone, srcNode := range src.BoneToNode {
-		dstNode := dst.BoneToNode[bone]
-		if srcNode >= 0 && dstNode >= 0 {
+		dstNode, ok := dst.BoneToNode[bone]
+		if ok && srcNode >= 0 && dstNode >= 0 {
 			out[srcNode] = dstNode
 		}
 	}
 	return out
 }
+
+// AddFootPlacement puts a TwoBoneIK + FootPlacement on both feet of the rig
+// so they rest on collider ground. Legs the profile couldn't map are skipped.
+func AddFootPlacement(rig *HumanoidRig) {
+	legs := [][3]HumanoidBone{
+		{HumanoidLeftUpperLeg, HumanoidLeftLowerLeg, HumanoidLeftFoot},
+		{HumanoidRightUpperLeg, HumanoidRightLowerLeg, HumanoidRightFoot},
+	}
+	for _, leg := range legs {
+		var chain [3]*ecs.Entity
+		for i, b := range leg {
+			idx := rig.BoneToNode[b]
+			if idx < 0 || idx >= len(rig.Nodes) || rig.Nodes[idx] == nil {
+				chain[0] = nil
+				break
+			}
+			chain[i] = rig.Nodes[idx]
+		}
+		if chain[0] == nil {
+			continue
+		}
+		foot := chain[2]
+		foot.AddComponent(ecs.NewTwoBoneIK(chain[0], chain[1], foot, nil))
+		foot.AddComponent(ecs.NewFootPlacement())
+	}
+}
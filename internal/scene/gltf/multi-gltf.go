@@ -123,6 +123,11 @@ func LoadGLTFMulti(sc *scene.Scene, path string) (*ecs.Entity, error) {
 			m.SpecularFactor = info.SpecularFactor
 		}
 
+		// Transparency and culling
+		m.AlphaMode = info.AlphaMode
+		m.AlphaCutoff = info.AlphaCutoff
+		m.DoubleSided = info.DoubleSided
+
 		// Import textures with correct color space
 		// Base color / albedo -> sRGB
 		// Base color (sRGB)
@@ -0,0 +1,82 @@
+package gltf
+
+import (
+	"fmt"
+	"go-engine/Go-Cordance/internal/assets"
+	"go-engine/Go-Cordance/internal/ecs"
+	"go-engine/Go-Cordance/internal/engine"
+	"go-engine/Go-Cordance/internal/scene"
+	"math"
+	"path/filepath"
+	"strings"
+)
+
+// LoadOBJ loads an OBJ through the asset loader, waiting for it if it is
+// still in flight, and spawns it with SpawnOBJ.
+func LoadOBJ(sc *scene.Scene, path string) (*ecs.Entity, error) {
+	id, err := assets.Async.Wait(assets.Async.LoadMeshAsync(path, "", nil, engine.GlobalMeshManager))
+	if err != nil {
+		return nil, err
+	}
+	return SpawnOBJ(sc, id)
+}
+
+// SpawnOBJ spawns OBJ mesh asset id like LoadGLTFMulti: a MultiMesh root
+// with one child per object/group/material part, each carrying the
+// material its usemtl named in the .mtl.
+func SpawnOBJ(sc *scene.Scene, id assets.AssetID) (*ecs.Entity, error) {
+	a := assets.Get(id)
+	meshIDs := assets.MeshIDs(id)
+	if a == nil || len(meshIDs) == 0 {
+		return nil, fmt.Errorf("asset %d is not a mesh", id)
+	}
+
+	materials := map[string]*ecs.Material{}
+	for meshID, info := range assets.OBJMaterials(id) {
+		materials[meshID] = objMaterial(info)
+	}
+
+	root := SpawnMultiMesh(sc, meshIDs, materials, nil)
+	name := filepath.Base(a.Path)
+	root.AddComponent(ecs.NewName(strings.TrimSuffix(name, filepath.Ext(name))))
+	return root, nil
+}
+
+// objMaterial maps an MTL material onto the engine's material. Without the
+// PBR extension, roughness is derived from the Phong exponent.
+func objMaterial(info engine.OBJMaterial) *ecs.Material {
+	m := ecs.NewMaterial([4]float32{info.Diffuse[0], info.Diffuse[1], info.Diffuse[2], info.Alpha})
+	m.Specular = (info.Specular[0] + info.Specular[1] + info.Specular[2]) / 3
+	if info.Shininess > 0 {
+		m.Shininess = info.Shininess
+	}
+	if info.HasRoughness {
+		m.Roughness = info.Roughness
+	} else {
+		m.Roughness = float32(math.Sqrt(2 / (float64(info.Shininess) + 2)))
+	}
+	m.Metallic = info.Metallic
+	if info.Alpha < 1 {
+		m.AlphaMode = engine.AlphaBlend
+	}
+
+	if info.DiffuseMap != "" {
+		m.DiffuseTexturePath = info.DiffuseMap
+		if assetID, glID, err := assets.ImportTextureWithSRGB(info.DiffuseMap, true); err == nil {
+			m.UseTexture = true
+			m.TextureID = glID
+			m.TextureAsset = assetID
+		}
+	}
+	if info.NormalMap != "" {
+		m.NormalTexturePath = info.NormalMap
+		m.NormalScale = info.BumpScale
+		if assetID, glID, err := assets.ImportTextureWithSRGB(info.NormalMap, false); err == nil {
+			m.UseNormal = true
+			m.NormalID = glID
+			m.NormalAsset = assetID
+		}
+	}
+	m.Dirty = true
+	return m
+}
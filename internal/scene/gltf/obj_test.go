@@ -0,0 +1,65 @@
+package gltf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-engine/Go-Cordance/internal/ecs"
+	"go-engine/Go-Cordance/internal/engine"
+	"go-engine/Go-Cordance/internal/scene"
+)
+
+func TestLoadOBJ_AppliesMTLMaterials(t *testing.T) {
+	prevDev, prevMM := engine.Device, engine.GlobalMeshManager
+	engine.Device = engine.NewRecordingDevice()
+	engine.GlobalMeshManager = engine.NewMeshManager()
+	defer func() { engine.Device, engine.GlobalMeshManager = prevDev, prevMM }()
+
+	dir := t.TempDir()
+	write := func(name, src string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	write("case.mtl", "newmtl red\nKd 1 0 0\nNs 10\n\nnewmtl glass\nKd 0 0 1\nd 0.25\n")
+	path := write("case.obj", "mtllib case.mtl\n"+
+		"v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\n"+
+		"usemtl red\nf 1 2 3\n"+
+		"usemtl glass\nf 1 3 4\n")
+
+	sc := scene.New()
+	root, err := LoadOBJ(sc, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	children := root.GetComponent((*ecs.Children)(nil)).(*ecs.Children)
+	if len(children.Entities) != 2 {
+		t.Fatalf("spawned %d parts, want 2", len(children.Entities))
+	}
+	want := map[string]struct {
+		color [4]float32
+		mode  engine.AlphaMode
+	}{
+		"case/red":   {[4]float32{1, 0, 0, 1}, engine.AlphaOpaque},
+		"case/glass": {[4]float32{0, 0, 1, 0.25}, engine.AlphaBlend},
+	}
+	for _, child := range children.Entities {
+		mesh := child.GetComponent((*ecs.Mesh)(nil)).(*ecs.Mesh)
+		w, ok := want[mesh.ID]
+		if !ok {
+			t.Errorf("unexpected part %q", mesh.ID)
+			continue
+		}
+		mat, ok := child.GetComponent((*ecs.Material)(nil)).(*ecs.Material)
+		if !ok {
+			t.Errorf("%s: no material", mesh.ID)
+			continue
+		}
+		if mat.BaseColor != w.color || mat.AlphaMode != w.mode {
+			t.Errorf("%s: color %v mode %v, want %v %v", mesh.ID, mat.BaseColor, mat.AlphaMode, w.color, w.mode)
+		}
+	}
+}
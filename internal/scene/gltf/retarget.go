@@ -0,0 +1,172 @@
+package gltf
+
+import (
+	"go-engine/Go-Cordance/internal/ecs"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// RetargetOptions controls how a clip is moved from one rig to another.
+type RetargetOptions struct {
+	// RootMotion keeps the horizontal hips translation of the source clip.
+	// When false the hips only move vertically (in model space).
+	RootMotion bool
+	// ScaleHeight scales hips translation by the ratio of the two rigs'
+	// bind-pose hip heights so a short character doesn't float or sink.
+	ScaleHeight bool
+}
+
+func DefaultRetargetOptions() RetargetOptions {
+	return RetargetOptions{RootMotion: true, ScaleHeight: true}
+}
+
+// RetargetClip retargets srcClip from srcRig to dstRig with default options.
+func RetargetClip(srcRig, dstRig *HumanoidRig, srcClip *ecs.AnimationClip) *ecs.AnimationClip {
+	return RetargetClipWithOptions(srcRig, dstRig, srcClip, DefaultRetargetOptions())
+}
+
+// RetargetClipWithOptions transfers each mapped bone's rotation as a
+// model-space delta from its bind pose, so rigs whose bones have different
+// local axes or rest orientations still end up in the same pose. Bone
+// lengths of the destination rig are kept; only the hips receive
+// translation.
+func RetargetClipWithOptions(srcRig, dstRig *HumanoidRig, srcClip *ecs.AnimationClip, opts RetargetOptions) *ecs.AnimationClip {
+	boneMap := BuildBoneMap(srcRig, dstRig)
+
+	dstClip := &ecs.AnimationClip{
+		Name:     srcClip.Name + "_retargeted",
+		Duration: srcClip.Duration,
+		Tracks:   []ecs.AnimationTrack{},
+		Events:   append([]ecs.AnimationEvent(nil), srcClip.Events...),
+	}
+	dstClip.SortEvents()
+
+	heightScale := float32(1)
+	if opts.ScaleHeight {
+		heightScale = hipHeightRatio(srcRig, dstRig)
+	}
+	srcHips := srcRig.BoneToNode[HumanoidHips]
+
+	for _, track := range srcClip.Tracks {
+		dstNode, ok := boneMap[track.NodeIndex]
+		if !ok {
+			continue
+		}
+		srcNode := track.NodeIndex
+		if !srcRig.hasBind(srcNode) || !dstRig.hasBind(dstNode) {
+			continue
+		}
+
+		srcBind := srcRig.BindLocal[srcNode]
+		dstBind := dstRig.BindLocal[dstNode]
+		srcParentRot := srcRig.parentWorldRotation(srcNode)
+		dstParentRot := dstRig.parentWorldRotation(dstNode)
+		srcBindRotInv := toQuat(srcBind.Rotation).Normalize().Inverse()
+		dstBindRot := toQuat(dstBind.Rotation).Normalize()
+
+		newTrack := ecs.AnimationTrack{
+			NodeIndex: dstNode,
+			Keyframes: make([]ecs.TransformKeyframe, len(track.Keyframes)),
+			Channels:  ecs.ChannelTranslation | ecs.ChannelRotation | ecs.ChannelScale,
+		}
+		hasRot := track.Animates(ecs.ChannelRotation)
+		hasPos := srcNode == srcHips && track.Animates(ecs.ChannelTranslation)
+
+		for i, kf := range track.Keyframes {
+			out := ecs.TransformKeyframe{
+				Time:     kf.Time,
+				Position: dstBind.Position,
+				Rotation: dstBind.Rotation,
+				Scale:    dstBind.Scale,
+			}
+
+			// rotation: delta in parent space -> model space -> destination parent space
+			if hasRot {
+				delta := toQuat(kf.Rotation).Normalize().Mul(srcBindRotInv)
+				modelDelta := srcParentRot.Mul(delta).Mul(srcParentRot.Inverse())
+				dstDelta := dstParentRot.Inverse().Mul(modelDelta).Mul(dstParentRot)
+				out.Rotation = fromQuat(dstDelta.Mul(dstBindRot).Normalize())
+			}
+
+			if hasPos {
+				out.Position = retargetHipsTranslation(srcRig, dstRig, srcNode, dstNode, kf.Position, heightScale, opts)
+			}
+
+			newTrack.Keyframes[i] = out
+		}
+
+		dstClip.Tracks = append(dstClip.Tracks, newTrack)
+	}
+
+	return dstClip
+}
+
+// BakeRetargetedClip retargets srcClip and saves the result as an animation
+// clip file so it can be loaded later without either source model.
+func BakeRetargetedClip(srcRig, dstRig *HumanoidRig, srcClip *ecs.AnimationClip, opts RetargetOptions, path string) (*ecs.AnimationClip, error) {
+	clip := RetargetClipWithOptions(srcRig, dstRig, srcClip, opts)
+	if err := ecs.SaveAnimationClip(path, clip); err != nil {
+		return nil, err
+	}
+	return clip, nil
+}
+
+func retargetHipsTranslation(srcRig, dstRig *HumanoidRig, srcNode, dstNode int, pos [3]float32, heightScale float32, opts RetargetOptions) [3]float32 {
+	srcBind := srcRig.BindLocal[srcNode]
+	dstBind := dstRig.BindLocal[dstNode]
+
+	delta := mgl32.Vec3{
+		pos[0] - srcBind.Position[0],
+		pos[1] - srcBind.Position[1],
+		pos[2] - srcBind.Position[2],
+	}
+
+	// local delta -> model space (includes parent scale, e.g. cm armatures)
+	modelDelta := srcRig.parentWorld(srcNode).Mul4x1(delta.Vec4(0)).Vec3().Mul(heightScale)
+	if !opts.RootMotion {
+		modelDelta[0] = 0
+		modelDelta[2] = 0
+	}
+	dstDelta := dstRig.parentWorld(dstNode).Inv().Mul4x1(modelDelta.Vec4(0)).Vec3()
+
+	return [3]float32{
+		dstBind.Position[0] + dstDelta[0],
+		dstBind.Position[1] + dstDelta[1],
+		dstBind.Position[2] + dstDelta[2],
+	}
+}
+
+// hipHeightRatio compares the model-space height of both rigs' hips.
+func hipHeightRatio(src, dst *HumanoidRig) float32 {
+	sh, dh := src.BoneToNode[HumanoidHips], dst.BoneToNode[HumanoidHips]
+	if !src.hasBind(sh) || !dst.hasBind(dh) {
+		return 1
+	}
+	srcHeight := src.BindWorld[sh].Col(3).Y()
+	dstHeight := dst.BindWorld[dh].Col(3).Y()
+	if srcHeight < 1e-5 && srcHeight > -1e-5 {
+		return 1
+	}
+	return dstHeight / srcHeight
+}
+
+func (rig *HumanoidRig) hasBind(node int) bool {
+	return node >= 0 && node < len(rig.BindLocal)
+}
+
+func (rig *HumanoidRig) parentWorld(node int) mgl32.Mat4 {
+	if p := rig.Parents[node]; p >= 0 {
+		return rig.BindWorld[p]
+	}
+	return mgl32.Ident4()
+}
+
+// parentWorldRotation composes bind rotations from the root down to the
+// node's parent, ignoring scale.
+func (rig *HumanoidRig) parentWorldRotation(node int) mgl32.Quat {
+	q := mgl32.QuatIdent()
+	for p := rig.Parents[node]; p >= 0; p = rig.Parents[p] {
+		q = toQuat(rig.BindLocal[p].Rotation).Normalize().Mul(q)
+	}
+	return q
+}
@@ -0,0 +1,111 @@
+package gltf
+
+import (
+	"math"
+	"testing"
+
+	"go-engine/Go-Cordance/internal/ecs"
+	"go-engine/Go-Cordance/internal/engine"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func axisAngle(deg float32, axis mgl32.Vec3) []float32 {
+	q := mgl32.QuatRotate(mgl32.DegToRad(deg), axis)
+	return []float32{q.V[0], q.V[1], q.V[2], q.W}
+}
+
+func sameRotation(a, b mgl32.Quat) bool {
+	return math.Abs(float64(a.Dot(b))) > 0.9999
+}
+
+func near3(a, b [3]float32) bool {
+	return mgl32.Vec3(a).ApproxEqualThreshold(mgl32.Vec3(b), 1e-4)
+}
+
+// retargetRigs returns a Mixamo source rig with identity rest rotations and
+// hips 1 unit up, and a UE destination rig with rotated rest poses and
+// hips half as high.
+func retargetRigs() (src, dst *HumanoidRig) {
+	srcRoot := &engine.GltfRoot{Nodes: []engine.GltfNode{
+		{Name: "Armature", Children: []int{1}},
+		{Name: "mixamorig:Hips", Children: []int{2}, Translation: []float32{0, 1, 0}},
+		{Name: "mixamorig:Spine", Translation: []float32{0, 0.2, 0}},
+	}}
+	dstRoot := &engine.GltfRoot{Nodes: []engine.GltfNode{
+		{Name: "pelvis", Children: []int{1}, Translation: []float32{0, 0.5, 0}, Rotation: axisAngle(90, mgl32.Vec3{1, 0, 0})},
+		{Name: "spine_01", Translation: []float32{0, 0.1, 0}, Rotation: axisAngle(90, mgl32.Vec3{0, 0, 1})},
+	}}
+	return BuildHumanoidRigFromGLTF(srcRoot, nil), BuildHumanoidRigFromGLTF(dstRoot, nil)
+}
+
+func TestRetargetClip_BindPoseRelative(t *testing.T) {
+	src, dst := retargetRigs()
+	if src.Profile != "mixamo" || dst.Profile != "ue" {
+		t.Fatalf("profiles %s -> %s", src.Profile, dst.Profile)
+	}
+	if r := hipHeightRatio(src, dst); math.Abs(float64(r-0.5)) > 1e-5 {
+		t.Errorf("hipHeightRatio = %v, want 0.5", r)
+	}
+
+	yaw := axisAngle(90, mgl32.Vec3{0, 1, 0})
+	clip := &ecs.AnimationClip{Name: "walk", Duration: 2, Tracks: []ecs.AnimationTrack{
+		{
+			// The hips pass through the origin; that key is real.
+			NodeIndex: 1,
+			Channels:  ecs.ChannelTranslation,
+			Keyframes: []ecs.TransformKeyframe{
+				{Time: 0, Position: [3]float32{0, 1, 0}},
+				{Time: 1, Position: [3]float32{}},
+				{Time: 2, Position: [3]float32{2, 1, 0}},
+			},
+		},
+		{
+			NodeIndex: 2,
+			Channels:  ecs.ChannelRotation,
+			Keyframes: []ecs.TransformKeyframe{
+				{Time: 0, Rotation: [4]float32{0, 0, 0, 1}},
+				{Time: 1, Rotation: [4]float32{yaw[0], yaw[1], yaw[2], yaw[3]}},
+			},
+		},
+	}}
+
+	out := RetargetClip(src, dst, clip)
+	if len(out.Tracks) != 2 || out.Tracks[0].NodeIndex != 0 || out.Tracks[1].NodeIndex != 1 {
+		t.Fatalf("tracks %+v", out.Tracks)
+	}
+
+	// Hips: bind-relative translation, scaled to the shorter rig and
+	// brought into the pelvis' parent space (the scene root here).
+	hips := out.Tracks[0].Keyframes
+	wantPos := [][3]float32{{0, 0.5, 0}, {0, 0, 0}, {1, 0.5, 0}}
+	for i, want := range wantPos {
+		if !near3(hips[i].Position, want) {
+			t.Errorf("hips key %d at %v, want %v", i, hips[i].Position, want)
+		}
+		if hips[i].Rotation != dst.BindLocal[0].Rotation {
+			t.Errorf("hips key %d rotated to %v without a rotation channel", i, hips[i].Rotation)
+		}
+	}
+
+	// Spine: the same model-space turn applied on top of the destination's
+	// rest orientation.
+	pelvisRot := toQuat(dst.BindLocal[0].Rotation)
+	restWorld := pelvisRot.Mul(toQuat(dst.BindLocal[1].Rotation))
+	spine := out.Tracks[1].Keyframes
+	if got := pelvisRot.Mul(toQuat(spine[0].Rotation)); !sameRotation(got, restWorld) {
+		t.Errorf("rest key: world rotation %v, want %v", got, restWorld)
+	}
+	want := toQuat([4]float32{yaw[0], yaw[1], yaw[2], yaw[3]}).Mul(restWorld)
+	if got := pelvisRot.Mul(toQuat(spine[1].Rotation)); !sameRotation(got, want) {
+		t.Errorf("turned key: world rotation %v, want %v", got, want)
+	}
+	if spine[1].Position != dst.BindLocal[1].Position {
+		t.Errorf("spine moved to %v", spine[1].Position)
+	}
+
+	noRoot := RetargetClipWithOptions(src, dst, clip, RetargetOptions{ScaleHeight: true})
+	if p := noRoot.Tracks[0].Keyframes[2].Position; !near3(p, [3]float32{0, 0.5, 0}) {
+		t.Errorf("without root motion the hips moved to %v", p)
+	}
+}
@@ -0,0 +1,234 @@
+package gltf
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// RigProfile maps humanoid bones to the node names used by one naming
+// convention. Profiles are plain JSON so new rigs can be supported without
+// touching code:
+//
+//	{
+//	  "name": "mixamo",
+//	  "bones": { "Hips": ["Hips"], "LeftUpperArm": ["LeftArm"] }
+//	}
+//
+// Each bone lists candidate names in order of preference; the first one
+// found in the glTF wins.
+type RigProfile struct {
+	Name     string              `json:"name"`
+	Prefixes []string            `json:"prefixes,omitempty"` // stripped before matching, e.g. "DEF-"
+	Bones    map[string][]string `json:"bones"`
+}
+
+var rigProfiles = builtinRigProfiles()
+
+// RegisterRigProfile makes a profile available to DetectRigProfile.
+// A profile with the same name replaces the existing one.
+func RegisterRigProfile(p *RigProfile) {
+	for i, existing := range rigProfiles {
+		if existing.Name == p.Name {
+			rigProfiles[i] = p
+			return
+		}
+	}
+	rigProfiles = append(rigProfiles, p)
+}
+
+// RigProfiles returns all registered profiles (built-in and user supplied).
+func RigProfiles() []*RigProfile {
+	return append([]*RigProfile(nil), rigProfiles...)
+}
+
+// FindRigProfile returns a registered profile by name, or nil.
+func FindRigProfile(name string) *RigProfile {
+	for _, p := range rigProfiles {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// LoadRigProfile reads a profile from a .rig.json file.
+func LoadRigProfile(path string) (*RigProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var p RigProfile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	for boneName := range p.Bones {
+		if _, ok := ParseHumanoidBone(boneName); !ok {
+			return nil, fmt.Errorf("rig profile %s: unknown humanoid bone %q", path, boneName)
+		}
+	}
+	return &p, nil
+}
+
+// SaveRigProfile writes a profile as indented JSON.
+func SaveRigProfile(path string, p *RigProfile) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// DetectRigProfile picks the registered profile that matches the most
+// humanoid bones in the given node names. It returns nil if nothing matches.
+func DetectRigProfile(nodeNames []string) *RigProfile {
+	var best *RigProfile
+	bestScore := 0
+	for _, p := range rigProfiles {
+		score := 0
+		for _, idx := range p.match(nodeNames) {
+			if idx >= 0 {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestScore = p, score
+		}
+	}
+	return best
+}
+
+// match resolves every humanoid bone of the profile to a node index
+// (-1 when missing).
+func (p *RigProfile) match(nodeNames []string) map[HumanoidBone]int {
+	normalized := make([]string, len(nodeNames))
+	for i, n := range nodeNames {
+		normalized[i] = p.normalizeBoneName(n)
+	}
+
+	out := make(map[HumanoidBone]int, len(p.Bones))
+	for boneName, candidates := range p.Bones {
+		bone, ok := ParseHumanoidBone(boneName)
+		if !ok {
+			continue
+		}
+		out[bone] = -1
+	search:
+		for _, c := range candidates {
+			want := p.normalizeBoneName(c)
+			for i, n := range normalized {
+				if n == want {
+					out[bone] = i
+					break search
+				}
+			}
+		}
+	}
+	return out
+}
+
+// normalizeBoneName makes names comparable across exporters: namespaces
+// ("mixamorig:Hips") and profile prefixes are dropped, case and separators
+// are ignored.
+func (p *RigProfile) normalizeBoneName(name string) string {
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		name = name[i+1:]
+	}
+	for _, prefix := range p.Prefixes {
+		if strings.HasPrefix(name, prefix) {
+			name = name[len(prefix):]
+			break
+		}
+	}
+	name = strings.ToLower(name)
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '_', '.', '-', ' ':
+			return -1
+		}
+		return r
+	}, name)
+}
+
+func builtinRigProfiles() []*RigProfile {
+	return []*RigProfile{
+		{
+			// Unreal mannequin naming (crawling_man uses this).
+			Name: "ue",
+			Bones: map[string][]string{
+				"Hips":          {"pelvis"},
+				"Spine":         {"spine_01"},
+				"Chest":         {"spine_03", "spine_02"},
+				"Neck":          {"neck_01"},
+				"Head":          {"head"},
+				"LeftShoulder":  {"clavicle_l"},
+				"LeftUpperArm":  {"upperarm_l"},
+				"LeftLowerArm":  {"lowerarm_l"},
+				"LeftHand":      {"hand_l"},
+				"RightShoulder": {"clavicle_r"},
+				"RightUpperArm": {"upperarm_r"},
+				"RightLowerArm": {"lowerarm_r"},
+				"RightHand":     {"hand_r"},
+				"LeftUpperLeg":  {"thigh_l"},
+				"LeftLowerLeg":  {"calf_l"},
+				"LeftFoot":      {"foot_l"},
+				"RightUpperLeg": {"thigh_r"},
+				"RightLowerLeg": {"calf_r"},
+				"RightFoot":     {"foot_r"},
+			},
+		},
+		{
+			// Mixamo rigs; the "mixamorig:" namespace is stripped by normalizeBoneName.
+			Name: "mixamo",
+			Bones: map[string][]string{
+				"Hips":          {"Hips"},
+				"Spine":         {"Spine"},
+				"Chest":         {"Spine2", "Spine1"},
+				"Neck":          {"Neck"},
+				"Head":          {"Head"},
+				"LeftShoulder":  {"LeftShoulder"},
+				"LeftUpperArm":  {"LeftArm"},
+				"LeftLowerArm":  {"LeftForeArm"},
+				"LeftHand":      {"LeftHand"},
+				"RightShoulder": {"RightShoulder"},
+				"RightUpperArm": {"RightArm"},
+				"RightLowerArm": {"RightForeArm"},
+				"RightHand":     {"RightHand"},
+				"LeftUpperLeg":  {"LeftUpLeg"},
+				"LeftLowerLeg":  {"LeftLeg"},
+				"LeftFoot":      {"LeftFoot"},
+				"RightUpperLeg": {"RightUpLeg"},
+				"RightLowerLeg": {"RightLeg"},
+				"RightFoot":     {"RightFoot"},
+			},
+		},
+		{
+			// Blender metarig / Rigify deform bones.
+			Name:     "blender",
+			Prefixes: []string{"DEF-", "ORG-"},
+			Bones: map[string][]string{
+				"Hips":          {"hips", "pelvis"},
+				"Spine":         {"spine.001", "spine"},
+				"Chest":         {"chest", "spine.003", "spine.002"},
+				"Neck":          {"neck", "spine.004"},
+				"Head":          {"head", "spine.006"},
+				"LeftShoulder":  {"shoulder.L"},
+				"LeftUpperArm":  {"upper_arm.L", "upperarm.L"},
+				"LeftLowerArm":  {"forearm.L", "lower_arm.L"},
+				"LeftHand":      {"hand.L"},
+				"RightShoulder": {"shoulder.R"},
+				"RightUpperArm": {"upper_arm.R", "upperarm.R"},
+				"RightLowerArm": {"forearm.R", "lower_arm.R"},
+				"RightHand":     {"hand.R"},
+				"LeftUpperLeg":  {"thigh.L"},
+				"LeftLowerLeg":  {"shin.L"},
+				"LeftFoot":      {"foot.L"},
+				"RightUpperLeg": {"thigh.R"},
+				"RightLowerLeg": {"shin.R"},
+				"RightFoot":     {"foot.R"},
+			},
+		},
+	}
+}
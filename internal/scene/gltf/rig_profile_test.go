@@ -0,0 +1,102 @@
+package gltf
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectRigProfile(t *testing.T) {
+	cases := []struct {
+		profile string
+		names   []string
+		bones   map[HumanoidBone]int // expected node index per bone
+	}{
+		{
+			"mixamo",
+			[]string{"Armature", "mixamorig:Hips", "mixamorig:Spine", "mixamorig:Spine1", "mixamorig:Spine2",
+				"mixamorig:LeftArm", "mixamorig:LeftForeArm", "mixamorig:LeftUpLeg"},
+			map[HumanoidBone]int{HumanoidHips: 1, HumanoidSpine: 2, HumanoidChest: 4, HumanoidLeftUpperArm: 5, HumanoidLeftUpperLeg: 7},
+		},
+		{
+			"ue",
+			[]string{"root", "pelvis", "spine_01", "spine_02", "clavicle_l", "upperarm_l", "thigh_r", "calf_r"},
+			map[HumanoidBone]int{HumanoidHips: 1, HumanoidSpine: 2, HumanoidChest: 3, HumanoidLeftUpperArm: 5, HumanoidRightLowerLeg: 7},
+		},
+		{
+			"blender",
+			[]string{"rig", "DEF-hips", "DEF-spine.001", "DEF-upper_arm.L", "ORG-forearm.L", "DEF-thigh.R", "DEF-shin.R"},
+			map[HumanoidBone]int{HumanoidHips: 1, HumanoidSpine: 2, HumanoidLeftUpperArm: 3, HumanoidLeftLowerArm: 4, HumanoidRightLowerLeg: 6},
+		},
+		{
+			// A bare "spine" is the spine, never the hips.
+			"blender",
+			[]string{"DEF-spine", "DEF-chest", "DEF-thigh.L", "DEF-shin.L", "DEF-foot.L"},
+			map[HumanoidBone]int{HumanoidHips: -1, HumanoidSpine: 0, HumanoidChest: 1},
+		},
+	}
+	for _, c := range cases {
+		p := DetectRigProfile(c.names)
+		if p == nil || p.Name != c.profile {
+			t.Errorf("%v: detected %v, want %s", c.names, p, c.profile)
+			continue
+		}
+		got := p.match(c.names)
+		for bone, want := range c.bones {
+			if got[bone] != want {
+				t.Errorf("%s: %v mapped to node %d, want %d", c.profile, bone, got[bone], want)
+			}
+		}
+	}
+
+	if p := DetectRigProfile([]string{"Cube", "Light", "Camera"}); p != nil {
+		t.Errorf("detected %s for a scene without bones", p.Name)
+	}
+}
+
+func TestLoadRigProfile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	p, err := LoadRigProfile(write("ok.rig.json", `{"name":"custom","prefixes":["CC_Base_"],"bones":{"Hips":["Pelvis"],"LeftHand":["L_Hand"]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "custom" || len(p.Bones) != 2 || p.Bones["LeftHand"][0] != "L_Hand" {
+		t.Errorf("loaded %+v", p)
+	}
+	if got := p.match([]string{"CC_Base_Pelvis"})[HumanoidHips]; got != 0 {
+		t.Errorf("prefixed hips mapped to %d", got)
+	}
+
+	bad := []struct {
+		file, data, want string
+	}{
+		{"bone.rig.json", `{"name":"x","bones":{"Tail":["tail"]}}`, `unknown humanoid bone "Tail"`},
+		{"json.rig.json", `{"name":`, "unexpected end of JSON input"},
+	}
+	for _, c := range bad {
+		if _, err := LoadRigProfile(write(c.file, c.data)); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: error %v, want %q", c.file, err, c.want)
+		}
+	}
+	if _, err := LoadRigProfile(filepath.Join(dir, "missing.rig.json")); !os.IsNotExist(err) {
+		t.Errorf("missing file: %v", err)
+	}
+
+	path := filepath.Join(dir, "saved.rig.json")
+	if err := SaveRigProfile(path, p); err != nil {
+		t.Fatal(err)
+	}
+	again, err := LoadRigProfile(path)
+	if err != nil || again.Name != p.Name || again.Prefixes[0] != "CC_Base_" {
+		t.Errorf("round trip: %+v, %v", again, err)
+	}
+}
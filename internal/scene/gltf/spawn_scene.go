@@ -2,6 +2,8 @@ package gltf
 
 import (
 	"fmt"
+	"log"
+	"math"
 
 	"go-engine/Go-Cordance/internal/ecs"
 	"go-engine/Go-Cordance/internal/engine"
@@ -10,6 +12,9 @@ import (
 
 // SpawnGLTFScene loads the full glTF scene graph (nodes, transforms, meshes)
 // and creates entities with Parent/Children + Transform + Mesh + Material.
+// Node cameras and KHR_lights_punctual lights become Camera / LightComponent
+// on the node entity; imported cameras are spawned inactive, and
+// orthographic ones are skipped with a warning.
 func SpawnGLTFScene(s *scene.Scene, mm *engine.MeshManager, path string) ([]*ecs.Entity, error) {
 	// 1. Load geometry for all meshes/primitives
 	_, err := mm.RegisterGLTFMulti(path)
@@ -38,9 +43,9 @@ func SpawnGLTFScene(s *scene.Scene, mm *engine.MeshManager, path string) ([]*ecs
 	var entities []*ecs.Entity
 
 	// Recursive node spawner
-	var spawnNode func(nodeIndex int, parent *ecs.Entity)
+	var spawnNode func(nodeIndex int, parent *ecs.Entity, parentWorld [16]float32)
 
-	spawnNode = func(nodeIndex int, parent *ecs.Entity) {
+	spawnNode = func(nodeIndex int, parent *ecs.Entity, parentWorld [16]float32) {
 		n := root.Nodes[nodeIndex]
 
 		// --- Node entity (holds the transform + hierarchy) ---
@@ -49,6 +54,19 @@ func SpawnGLTFScene(s *scene.Scene, mm *engine.MeshManager, path string) ([]*ecs
 		// Local transform from glTF node TRS/matrix
 		M := engine.ComposeNodeTransform(n)
 		nodeEnt.AddComponent(ecs.NewTransformFromMatrix(M))
+		world := engine.MulMat4(parentWorld, M)
+
+		// --- Camera / punctual light on the node ---
+		if n.Camera >= 0 && n.Camera < len(root.Cameras) {
+			if cam, err := cameraFromGLTF(root.Cameras[n.Camera], world); err == nil {
+				nodeEnt.AddComponent(cam)
+			} else {
+				log.Printf("gltf %s: node %q: %v", path, n.Name, err)
+			}
+		}
+		if l, ok := root.NodeLight(n); ok {
+			nodeEnt.AddComponent(lightFromGLTF(l))
+		}
 
 		// Parent/Children wiring
 		if parent != nil {
@@ -120,7 +138,7 @@ func SpawnGLTFScene(s *scene.Scene, mm *engine.MeshManager, path string) ([]*ecs
 
 		// --- Recurse into children nodes ---
 		for _, child := range n.Children {
-			spawnNode(child, nodeEnt)
+			spawnNode(child, nodeEnt, world)
 		}
 	}
 
@@ -130,9 +148,77 @@ func SpawnGLTFScene(s *scene.Scene, mm *engine.MeshManager, path string) ([]*ecs
 		// fall back to scene 0 if invalid
 		sceneIndex = 0
 	}
+	if len(root.Scenes) == 0 {
+		return entities, nil
+	}
 	for _, nodeIndex := range root.Scenes[sceneIndex].Nodes {
-		spawnNode(nodeIndex, nil)
+		spawnNode(nodeIndex, nil, engine.IdentityMatrix())
 	}
 
 	return entities, nil
 }
+
+// cameraFromGLTF places a Camera at the node's world transform; glTF cameras
+// look down their local -Z with +Y up. Camera is perspective-only, so
+// orthographic cameras are refused rather than imported with a made-up
+// field of view.
+func cameraFromGLTF(c engine.GltfCamera, world [16]float32) (*ecs.Camera, error) {
+	if c.Perspective == nil {
+		return nil, fmt.Errorf("camera %q: %s cameras are not supported", c.Name, c.Type)
+	}
+	cam := ecs.NewCamera()
+	cam.Active = false
+
+	pos := [3]float32{world[12], world[13], world[14]}
+	fwd := normalize3([3]float32{-world[8], -world[9], -world[10]})
+	cam.Position = pos
+	cam.Target = [3]float32{pos[0] + fwd[0], pos[1] + fwd[1], pos[2] + fwd[2]}
+	cam.Up = normalize3([3]float32{world[4], world[5], world[6]})
+
+	p := c.Perspective
+	cam.Fov = p.Yfov * 180 / math.Pi
+	cam.Near = p.Znear
+	if p.Zfar > 0 {
+		cam.Far = p.Zfar
+	}
+	if p.AspectRatio > 0 {
+		cam.Aspect = p.AspectRatio
+	}
+	return cam, nil
+}
+
+// lightFromGLTF converts a KHR_lights_punctual light. The node's world
+// transform places and orients it: directional and spot lights point down
+// the node's -Z axis, which is the forward RenderSystem uses.
+func lightFromGLTF(l engine.GltfLight) *ecs.LightComponent {
+	lc := ecs.NewLightComponent()
+	switch l.Type {
+	case "point":
+		lc.Type = ecs.LightPoint
+	case "spot":
+		lc.Type = ecs.LightSpot
+		if l.Spot != nil {
+			lc.Angle = l.Spot.OuterConeAngle * 180 / math.Pi
+		} else {
+			lc.Angle = 45
+		}
+	default:
+		lc.Type = ecs.LightDirectional
+	}
+	if len(l.Color) >= 3 {
+		lc.Color = [3]float32{l.Color[0], l.Color[1], l.Color[2]}
+	}
+	lc.Intensity = l.Intensity
+	if l.Range > 0 {
+		lc.Range = l.Range
+	}
+	return lc
+}
+
+func normalize3(v [3]float32) [3]float32 {
+	l := float32(math.Sqrt(float64(v[0]*v[0] + v[1]*v[1] + v[2]*v[2])))
+	if l == 0 {
+		return v
+	}
+	return [3]float32{v[0] / l, v[1] / l, v[2] / l}
+}
@@ -0,0 +1,29 @@
+package gltf
+
+import (
+	"testing"
+
+	"go-engine/Go-Cordance/internal/engine"
+)
+
+func TestCameraFromGLTF(t *testing.T) {
+	world := [16]float32{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 1, 5, 1}
+
+	cam, err := cameraFromGLTF(engine.GltfCamera{Type: "perspective",
+		Perspective: &engine.GltfPerspective{Yfov: 0.5, Znear: 0.05, AspectRatio: 2}}, world)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cam.Active || cam.Position != [3]float32{0, 1, 5} || cam.Target != [3]float32{0, 1, 4} {
+		t.Errorf("camera placed at %v looking at %v", cam.Position, cam.Target)
+	}
+	if cam.Near != 0.05 || cam.Far != 100 || cam.Aspect != 2 {
+		t.Errorf("clip range %v..%v, aspect %v", cam.Near, cam.Far, cam.Aspect)
+	}
+
+	ortho := engine.GltfCamera{Name: "top", Type: "orthographic",
+		Orthographic: &engine.GltfOrthographic{Xmag: 2, Ymag: 2, Zfar: 10}}
+	if cam, err := cameraFromGLTF(ortho, world); err == nil {
+		t.Errorf("orthographic camera imported as %+v", cam)
+	}
+}
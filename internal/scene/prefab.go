@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 
 	"go-engine/Go-Cordance/internal/assets"
 	"go-engine/Go-Cordance/internal/ecs"
@@ -51,7 +52,11 @@ func (s *Scene) SavePrefab(path string, root *ecs.Entity) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0644)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	assets.Deps.Set(path, prefabDependencies(ents))
+	return nil
 }
 
 // -----------------------------------------------------------------------------
@@ -72,7 +77,7 @@ func (s *Scene) InstantiatePrefab(path string) (*ecs.Entity, []*ecs.Entity, erro
 	// 1. Create empty entities (new IDs)
 	idMap := make(map[int64]*ecs.Entity)
 	for _, se := range prefab.Scene.Entities {
-		e := ecs.NewEntity(s.nextID)
+		e := ecs.NewEntity(atomic.AddInt64(&s.nextID, 1))
 		s.AddExisting(e)
 		idMap[se.ID] = e
 	}
@@ -103,6 +108,8 @@ func (s *Scene) InstantiatePrefab(path string) (*ecs.Entity, []*ecs.Entity, erro
 					Joints              []int
 					InverseBindMatrices [][16]float32
 					Skeleton            int
+					Mode                int
+					CPUSkinning         bool
 				}
 				b, _ := json.Marshal(raw)
 				json.Unmarshal(b, &s)
@@ -111,6 +118,8 @@ func (s *Scene) InstantiatePrefab(path string) (*ecs.Entity, []*ecs.Entity, erro
 					skelIndex = -1
 				}
 				skin := ecs.NewSkin(s.Joints, s.InverseBindMatrices, skelIndex)
+				skin.Mode = ecs.SkinningMode(s.Mode)
+				skin.CPUSkinning = s.CPUSkinning
 				e.AddComponent(skin)
 			case "Skeleton":
 				var s struct {
@@ -153,10 +162,11 @@ func (s *Scene) InstantiatePrefab(path string) (*ecs.Entity, []*ecs.Entity, erro
 					UseNormal  bool
 					NormalID   uint32
 
-					TextureAsset assets.AssetID
-					NormalAsset  assets.AssetID
-					ShaderName   string
-					Shader       *engine.ShaderProgram
+					TextureAsset  assets.AssetID
+					NormalAsset   assets.AssetID
+					MaterialAsset assets.AssetID
+					ShaderName    string
+					Shader        *engine.ShaderProgram
 
 					DiffuseTexturePath           string
 					NormalTexturePath            string
@@ -190,6 +200,10 @@ func (s *Scene) InstantiatePrefab(path string) (*ecs.Entity, []*ecs.Entity, erro
 					UseTransmission        bool
 					TransmissionTex        uint32
 
+					AlphaMode   string
+					AlphaCutoff *float32
+					DoubleSided bool
+
 					Dirty bool
 				}
 
@@ -212,6 +226,7 @@ func (s *Scene) InstantiatePrefab(path string) (*ecs.Entity, []*ecs.Entity, erro
 
 				mat.TextureAsset = m.TextureAsset
 				mat.NormalAsset = m.NormalAsset
+				mat.MaterialAsset = m.MaterialAsset
 				mat.ShaderName = m.ShaderName
 				mat.Shader = m.Shader
 
@@ -248,6 +263,11 @@ func (s *Scene) InstantiatePrefab(path string) (*ecs.Entity, []*ecs.Entity, erro
 				mat.TransmissionFactor = m.TransmissionFactor
 				mat.UseTransmission = m.UseTransmission
 				mat.TransmissionTex = m.TransmissionTex
+				mat.AlphaMode, _ = engine.ParseAlphaMode(m.AlphaMode)
+				if m.AlphaCutoff != nil {
+					mat.AlphaCutoff = *m.AlphaCutoff
+				}
+				mat.DoubleSided = m.DoubleSided
 
 				mat.Dirty = m.Dirty
 
@@ -270,6 +290,9 @@ func (s *Scene) InstantiatePrefab(path string) (*ecs.Entity, []*ecs.Entity, erro
 				b, _ := json.Marshal(raw)
 				json.Unmarshal(b, &t)
 				e.AddComponent(ecs.NewNormalMap(t.ID))
+
+			case "AnimationPlayer":
+				e.AddComponent(deserializeAnimationPlayer(raw))
 			}
 		}
 	}
@@ -294,7 +317,45 @@ func (s *Scene) InstantiatePrefab(path string) (*ecs.Entity, []*ecs.Entity, erro
 
 	// Return new root
 	root := idMap[prefab.RootID]
-	return root, idMapToSlice(idMap), nil
+	root.AddComponent(&ecs.PrefabInstance{Path: filepath.ToSlash(filepath.Clean(path))})
+	ents := idMapToSlice(idMap)
+	assets.Deps.Set(path, prefabDependencies(ents))
+	return root, ents, nil
+}
+
+// ReloadPrefab rebuilds every live instance of the prefab at path from the
+// file, keeping the root's Transform and parent. It returns the number of
+// instances replaced.
+func (s *Scene) ReloadPrefab(path string) (int, error) {
+	path = filepath.ToSlash(filepath.Clean(path))
+	var roots []*ecs.Entity
+	for _, e := range s.entities {
+		if pi, ok := e.GetComponent((*ecs.PrefabInstance)(nil)).(*ecs.PrefabInstance); ok && pi.Path == path {
+			roots = append(roots, e)
+		}
+	}
+
+	for i, old := range roots {
+		root, _, err := s.InstantiatePrefab(path)
+		if err != nil {
+			return i, err
+		}
+		if tr, ntr := old.GetTransform(), root.GetTransform(); tr != nil && ntr != nil {
+			ntr.Position, ntr.Rotation, ntr.Scale = tr.Position, tr.Rotation, tr.Scale
+			ntr.RecalculateLocal()
+		}
+		if p, ok := old.GetComponent((*ecs.Parent)(nil)).(*ecs.Parent); ok && p.Entity != nil {
+			root.AddComponent(ecs.NewParent(p.Entity))
+			if ch, ok := p.Entity.GetComponent((*ecs.Children)(nil)).(*ecs.Children); ok {
+				ch.Remove(old)
+				ch.AddChild(root)
+			}
+		}
+		for _, e := range collectSubtree(old) {
+			s.DeleteEntityByID(e.ID)
+		}
+	}
+	return len(roots), nil
 }
 
 // -----------------------------------------------------------------------------
@@ -317,6 +378,17 @@ func collectSubtree(root *ecs.Entity) []*ecs.Entity {
 	return out
 }
 
+// prefabDependencies lists the files of the assets the entities reference,
+// for the asset dependency graph.
+func prefabDependencies(ents []*ecs.Entity) []string {
+	refs := referencedAssets(ents)
+	deps := make([]string, 0, len(refs))
+	for _, a := range refs {
+		deps = append(deps, a.Path)
+	}
+	return deps
+}
+
 func idMapToSlice(m map[int64]*ecs.Entity) []*ecs.Entity {
 	out := make([]*ecs.Entity, 0, len(m))
 	for _, e := range m {
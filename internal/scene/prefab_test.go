@@ -0,0 +1,63 @@
+package scene
+
+import (
+	"path/filepath"
+	"testing"
+
+	"go-engine/Go-Cordance/internal/ecs"
+)
+
+func TestReloadPrefab(t *testing.T) {
+	s := New()
+	root := s.AddEntity()
+	root.AddComponent(ecs.NewTransform([3]float32{}))
+	lamp := s.AddEntity()
+	lamp.AddComponent(ecs.NewTransform([3]float32{0, 1, 0}))
+	lamp.AddComponent(ecs.NewParent(root))
+	children := ecs.NewChildren()
+	children.AddChild(lamp)
+	root.AddComponent(children)
+
+	path := filepath.Join(t.TempDir(), "lamp.json")
+	if err := s.SavePrefab(path, root); err != nil {
+		t.Fatal(err)
+	}
+
+	holder := s.AddEntity()
+	holder.AddComponent(ecs.NewChildren())
+	inst, _, err := s.InstantiatePrefab(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inst.AddComponent(ecs.NewParent(holder))
+	holder.GetComponent((*ecs.Children)(nil)).(*ecs.Children).AddChild(inst)
+	inst.GetTransform().Position = [3]float32{5, 0, 0}
+
+	// Raise the lamp in the prefab.
+	lamp.GetTransform().Position = [3]float32{0, 2, 0}
+	if err := s.SavePrefab(path, root); err != nil {
+		t.Fatal(err)
+	}
+	s.DeleteEntityByID(root.ID)
+	s.DeleteEntityByID(lamp.ID)
+
+	n, err := s.ReloadPrefab(path)
+	if err != nil || n != 1 {
+		t.Fatalf("ReloadPrefab = %d, %v", n, err)
+	}
+	if len(s.Entities()) != 3 {
+		t.Fatalf("%d entities after reload, want holder and one instance", len(s.Entities()))
+	}
+	kids := holder.GetComponent((*ecs.Children)(nil)).(*ecs.Children).Entities
+	if len(kids) != 1 || kids[0] == inst {
+		t.Fatalf("holder children %v", kids)
+	}
+	fresh := kids[0]
+	if p := fresh.GetTransform().Position; p != [3]float32{5, 0, 0} {
+		t.Errorf("instance moved back to %v", p)
+	}
+	lampKids := fresh.GetComponent((*ecs.Children)(nil)).(*ecs.Children).Entities
+	if len(lampKids) != 1 || lampKids[0].GetTransform().Position != [3]float32{0, 2, 0} {
+		t.Errorf("lamp not rebuilt from the file: %+v", lampKids[0].GetTransform())
+	}
+}
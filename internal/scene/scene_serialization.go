@@ -6,6 +6,7 @@ import (
 	"go-engine/Go-Cordance/internal/ecs"
 	"go-engine/Go-Cordance/internal/engine"
 	"os"
+	"strings"
 )
 
 type SerializedScene struct {
@@ -41,6 +42,7 @@ func serializeMaterial(m *ecs.Material) map[string]interface{} {
 	out["normalID"] = m.NormalID
 	out["textureAsset"] = m.TextureAsset
 	out["normalAsset"] = m.NormalAsset
+	out["materialAsset"] = m.MaterialAsset
 	out["shaderName"] = m.ShaderName
 	out["shader"] = m.Shader
 	out["diffuseTexturePath"] = m.DiffuseTexturePath
@@ -74,11 +76,41 @@ func serializeMaterial(m *ecs.Material) map[string]interface{} {
 	out["transmissionFactor"] = m.TransmissionFactor
 	out["useTransmission"] = m.UseTransmission
 	out["transMissionTex"] = m.TransmissionTex
+	out["alphaMode"] = m.AlphaMode.String()
+	out["alphaCutoff"] = m.AlphaCutoff
+	out["doubleSided"] = m.DoubleSided
 	out["dirty"] = m.Dirty
 
+	// GUIDs let references survive asset moves and ID collisions on load.
+	guids := map[string]assets.GUID{}
+	for field, id := range map[string]assets.AssetID{
+		"textureAsset":           m.TextureAsset,
+		"normalAsset":            m.NormalAsset,
+		"occlusionAsset":         m.OcclusionAsset,
+		"metallicRoughnessAsset": m.MetallicRoughnessAsset,
+		"materialAsset":          m.MaterialAsset,
+	} {
+		if a := assets.Get(id); a != nil {
+			guids[field] = a.GUID
+		}
+	}
+	if len(guids) > 0 {
+		out["assetGUIDs"] = guids
+	}
+
 	return out
 }
 
+// resolveAssetGUID prefers the asset a saved GUID points at over the saved ID.
+func resolveAssetGUID(guids map[string]assets.GUID, field string, id assets.AssetID) assets.AssetID {
+	if g, ok := guids[field]; ok {
+		if a := assets.FindAssetByGUID(g); a != nil {
+			return a.ID
+		}
+	}
+	return id
+}
+
 func serializeDiffuseTexture(t *ecs.DiffuseTexture) map[string]interface{} {
 	return map[string]interface{}{"id": t.ID}
 }
@@ -86,6 +118,53 @@ func serializeNormalMap(t *ecs.NormalMap) map[string]interface{} {
 	return map[string]interface{}{"id": t.ID}
 }
 
+// serializeAnimationPlayer stores clips by asset path; clips that only
+// exist in memory (glTF-embedded, retargeted) are rebuilt by code instead.
+func serializeAnimationPlayer(ap *ecs.AnimationPlayer) map[string]interface{} {
+	paths := make([]string, 0, len(ap.ClipSources))
+	for _, p := range ap.ClipSources {
+		paths = append(paths, p)
+	}
+	return map[string]interface{}{
+		"clips":   paths,
+		"current": ap.Current,
+		"speed":   ap.Speed,
+		"playing": ap.Playing,
+	}
+}
+
+func deserializeAnimationPlayer(raw interface{}) *ecs.AnimationPlayer {
+	var a struct {
+		Clips   []string
+		Current string
+		Speed   float32
+		Playing bool
+	}
+	b, _ := json.Marshal(raw)
+	json.Unmarshal(b, &a)
+
+	ap := ecs.ComponentRegistry["AnimationPlayer"]().(*ecs.AnimationPlayer)
+	for _, path := range a.Clips {
+		var id assets.AssetID
+		if existing := assets.FindAssetByPath(path); existing != nil {
+			id = existing.ID
+		} else {
+			loaded, err := assets.LoadClip(path)
+			if err != nil {
+				continue
+			}
+			id = loaded
+		}
+		ap.AddClipAsset(id)
+	}
+	if _, ok := ap.Clips[a.Current]; ok {
+		ap.Current = a.Current
+	}
+	ap.Speed = a.Speed
+	ap.Playing = a.Playing
+	return ap
+}
+
 func serializeParent(p *ecs.Parent) map[string]interface{} {
 	return map[string]interface{}{
 		"parent": p.Entity.ID,
@@ -183,6 +262,50 @@ func (s *Scene) Save(path string) error {
 				"value": n.(*ecs.Name).Value,
 			}
 		}
+		if ap := e.GetComponent((*ecs.AnimationPlayer)(nil)); ap != nil {
+			se.Components["AnimationPlayer"] = serializeAnimationPlayer(ap.(*ecs.AnimationPlayer))
+		}
+		if c := e.GetComponent((*ecs.Environment)(nil)); c != nil {
+			env := c.(*ecs.Environment)
+			se.Components["Environment"] = map[string]interface{}{
+				"path":      env.Path,
+				"intensity": env.Intensity,
+			}
+		}
+		if c := e.GetComponent((*ecs.PostProcess)(nil)); c != nil {
+			pp := c.(*ecs.PostProcess)
+			se.Components["PostProcess"] = map[string]interface{}{
+				"autoExposure":         pp.AutoExposure,
+				"exposureCompensation": pp.ExposureCompensation,
+				"minEV100":             pp.MinEV100,
+				"maxEV100":             pp.MaxEV100,
+				"adaptSpeedUp":         pp.AdaptSpeedUp,
+				"adaptSpeedDown":       pp.AdaptSpeedDown,
+				"tonemap":              pp.Tonemap.String(),
+				"bloom":                pp.Bloom,
+				"bloomThreshold":       pp.BloomThreshold,
+				"bloomKnee":            pp.BloomKnee,
+				"bloomIntensity":       pp.BloomIntensity,
+				"bloomRadius":          pp.BloomRadius,
+				"fxaa":                 pp.FXAA,
+				"vignetteIntensity":    pp.VignetteIntensity,
+				"vignetteSmoothness":   pp.VignetteSmoothness,
+				"lutPath":              pp.LUTPath,
+				"lutContribution":      pp.LUTContribution,
+			}
+		}
+		if c := e.GetComponent((*ecs.MeshInstancer)(nil)); c != nil {
+			mi := c.(*ecs.MeshInstancer)
+			se.Components["MeshInstancer"] = map[string]interface{}{
+				"meshID":    mi.MeshID,
+				"count":     mi.Count,
+				"extent":    mi.Extent,
+				"minScale":  mi.MinScale,
+				"maxScale":  mi.MaxScale,
+				"randomYaw": mi.RandomYaw,
+				"seed":      mi.Seed,
+			}
+		}
 		// example Camera component
 		// example Camera component
 		if c := e.GetComponent((*ecs.Camera)(nil)); c != nil {
@@ -394,10 +517,11 @@ func Load(path string) (*Scene, error) {
 					NormalID   uint32 // raw GL normal map ID
 
 					// --- New asset pipeline fields (optional, non-breaking) ---
-					TextureAsset assets.AssetID // future: replace TextureID
-					NormalAsset  assets.AssetID // future: replace NormalID
-					ShaderName   string
-					Shader       *engine.ShaderProgram
+					TextureAsset  assets.AssetID // future: replace TextureID
+					NormalAsset   assets.AssetID // future: replace NormalID
+					MaterialAsset assets.AssetID
+					ShaderName    string
+					Shader        *engine.ShaderProgram
 
 					DiffuseTexturePath           string
 					NormalTexturePath            string
@@ -433,7 +557,13 @@ func Load(path string) (*Scene, error) {
 
 					// (optional) OcclusionID/MetallicRoughnessID can be zero if not present
 
+					AlphaMode   string
+					AlphaCutoff *float32
+					DoubleSided bool
+
 					Dirty bool
+
+					AssetGUIDs map[string]assets.GUID
 				}
 				b, _ := json.Marshal(raw)
 				json.Unmarshal(b, &m)
@@ -452,6 +582,7 @@ func Load(path string) (*Scene, error) {
 				mat.NormalID = m.NormalID
 				mat.TextureAsset = m.TextureAsset
 				mat.NormalAsset = m.NormalAsset
+				mat.MaterialAsset = m.MaterialAsset
 				mat.ShaderName = m.ShaderName
 				mat.Shader = m.Shader
 
@@ -487,8 +618,19 @@ func Load(path string) (*Scene, error) {
 				mat.TransmissionFactor = m.TransmissionFactor
 				mat.UseTransmission = m.UseTransmission
 				mat.TransmissionTex = m.TransmissionTex
+				mat.AlphaMode, _ = engine.ParseAlphaMode(m.AlphaMode)
+				if m.AlphaCutoff != nil {
+					mat.AlphaCutoff = *m.AlphaCutoff
+				}
+				mat.DoubleSided = m.DoubleSided
 				mat.Dirty = m.Dirty
 
+				mat.TextureAsset = resolveAssetGUID(m.AssetGUIDs, "textureAsset", mat.TextureAsset)
+				mat.NormalAsset = resolveAssetGUID(m.AssetGUIDs, "normalAsset", mat.NormalAsset)
+				mat.OcclusionAsset = resolveAssetGUID(m.AssetGUIDs, "occlusionAsset", mat.OcclusionAsset)
+				mat.MetallicRoughnessAsset = resolveAssetGUID(m.AssetGUIDs, "metallicRoughnessAsset", mat.MetallicRoughnessAsset)
+				mat.MaterialAsset = resolveAssetGUID(m.AssetGUIDs, "materialAsset", mat.MaterialAsset)
+
 				e.AddComponent(mat)
 
 			case "DiffuseTexture":
@@ -502,6 +644,59 @@ func Load(path string) (*Scene, error) {
 				b, _ := json.Marshal(raw)
 				json.Unmarshal(b, &t)
 				e.AddComponent(ecs.NewNormalMap(t.ID))
+
+			case "AnimationPlayer":
+				e.AddComponent(deserializeAnimationPlayer(raw))
+
+			case "Environment":
+				var c struct {
+					Path      string
+					Intensity float32
+				}
+				b, _ := json.Marshal(raw)
+				json.Unmarshal(b, &c)
+				env := ecs.NewEnvironment(c.Path)
+				env.Intensity = c.Intensity
+				e.AddComponent(env)
+
+			case "PostProcess":
+				// Start from the defaults so fields missing from older
+				// scenes keep sensible values.
+				pp := ecs.NewPostProcess()
+				c := struct {
+					engine.PostSettings
+					Tonemap string
+				}{PostSettings: pp.PostSettings, Tonemap: pp.Tonemap.String()}
+				b, _ := json.Marshal(raw)
+				json.Unmarshal(b, &c)
+				pp.PostSettings = c.PostSettings
+				for i, name := range engine.TonemapNames() {
+					if strings.EqualFold(name, c.Tonemap) {
+						pp.Tonemap = engine.TonemapOperator(i)
+					}
+				}
+				e.AddComponent(pp)
+
+			case "MeshInstancer":
+				// Only the scatter settings are saved; the instances
+				// are scattered again from them.
+				mi := ecs.NewMeshInstancer("")
+				c := struct {
+					MeshID    string
+					Count     int
+					Extent    [3]float32
+					MinScale  float32
+					MaxScale  float32
+					RandomYaw bool
+					Seed      int
+				}{Extent: mi.Extent, MinScale: mi.MinScale, MaxScale: mi.MaxScale, RandomYaw: mi.RandomYaw}
+				b, _ := json.Marshal(raw)
+				json.Unmarshal(b, &c)
+				mi.MeshID, mi.Count, mi.Extent = c.MeshID, c.Count, c.Extent
+				mi.MinScale, mi.MaxScale = c.MinScale, c.MaxScale
+				mi.RandomYaw, mi.Seed = c.RandomYaw, c.Seed
+				mi.Scatter()
+				e.AddComponent(mi)
 			}
 		}
 	}
@@ -659,9 +854,14 @@ func serializeEntity(e *ecs.Entity) SerializedEntity {
 			"Joints":              s.Joints,
 			"InverseBindMatrices": s.InverseBindMatrices,
 			"Skeleton":            s.SkeletonRootNode,
+			"Mode":                int(s.Mode),
+			"CPUSkinning":         s.CPUSkinning,
 			// JointMatrices / JointEntities are runtime-only, skip them
 		}
 	}
+	if ap, ok := e.GetComponent((*ecs.AnimationPlayer)(nil)).(*ecs.AnimationPlayer); ok {
+		se.Components["AnimationPlayer"] = serializeAnimationPlayer(ap)
+	}
 	// serializeEntity
 	if skel, ok := e.GetComponent((*ecs.Skeleton)(nil)).(*ecs.Skeleton); ok {
 		ids := make([]int64, len(skel.Nodes))
@@ -0,0 +1,173 @@
+package shaderlang
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Preprocessor assembles GLSL stages from files that #include others:
+//
+//	#include "include/lights.glsl"
+//
+// Paths are relative to the including file. A file is pasted at most once
+// per stage, so shared headers need no include guards; including a file
+// that is still being expanded is an error. Includes are pasted even
+// inside #ifdef blocks; the compiler then skips what is disabled. The
+// output carries #line
+// directives whose source-string numbers index Files, so compiler messages
+// can be mapped back to the right file and line with MapLog.
+//
+// Use one Preprocessor for all stages of a program: they then number their
+// files distinctly and one MapLog serves whichever stage failed.
+type Preprocessor struct {
+	// Files lists every file read, by source-string number.
+	Files []string
+	// ReadFile loads a file; nil means os.ReadFile.
+	ReadFile func(path string) ([]byte, error)
+}
+
+var includeRe = regexp.MustCompile(`^\s*#\s*include\s+"([^"]+)"\s*(//.*)?$`)
+
+// Process expands the includes of the stage at path. Each of defines is
+// written as "#define name value" and each keyword as "#define name 1",
+// right after #version.
+func (p *Preprocessor) Process(path string, defines map[string]any, keywords []string) (string, error) {
+	path = filepath.ToSlash(filepath.Clean(path))
+	lines, err := p.read(path)
+	if err != nil {
+		return "", err
+	}
+	root := p.fileIndex(path)
+
+	var out strings.Builder
+	start := 0
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "#version") {
+			for _, l := range lines[:i+1] {
+				out.WriteString(l + "\n")
+			}
+			start = i + 1
+			break
+		}
+	}
+	writeDefines(&out, defines, keywords)
+	fmt.Fprintf(&out, "#line %d %d\n", start+1, root)
+
+	done := map[string]bool{}
+	if err := p.expand(&out, path, lines[start:], start, []string{path}, done); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// expand writes lines of file (the first being line first+1) with their
+// includes pasted in. stack holds the files being expanded, outermost
+// first.
+func (p *Preprocessor) expand(out *strings.Builder, file string, lines []string, first int, stack []string, done map[string]bool) error {
+	done[file] = true
+	idx := p.fileIndex(file)
+	for i, line := range lines {
+		m := includeRe.FindStringSubmatch(line)
+		if m == nil {
+			if strings.HasPrefix(strings.TrimSpace(line), "#version") && len(stack) > 1 {
+				return fmt.Errorf("%s:%d: #version in an included file", file, first+i+1)
+			}
+			out.WriteString(line + "\n")
+			continue
+		}
+
+		inc := filepath.ToSlash(filepath.Join(filepath.Dir(file), m[1]))
+		for j, s := range stack {
+			if s == inc {
+				chain := append(append([]string(nil), stack[j:]...), inc)
+				return fmt.Errorf("%s:%d: include cycle: %s", file, first+i+1, strings.Join(chain, " -> "))
+			}
+		}
+		if done[inc] {
+			out.WriteString("\n")
+			continue
+		}
+		sub, err := p.read(inc)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", file, first+i+1, err)
+		}
+		fmt.Fprintf(out, "#line 1 %d\n", p.fileIndex(inc))
+		if err := p.expand(out, inc, sub, 0, append(stack, inc), done); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "#line %d %d\n", first+i+2, idx)
+	}
+	return nil
+}
+
+// read loads path as lines, without a UTF-8 BOM or carriage returns.
+func (p *Preprocessor) read(path string) ([]string, error) {
+	readFile := p.ReadFile
+	if readFile == nil {
+		readFile = os.ReadFile
+	}
+	data, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := strings.TrimPrefix(string(data), "\ufeff")
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n"), nil
+}
+
+func (p *Preprocessor) fileIndex(path string) int {
+	path = filepath.ToSlash(filepath.Clean(path))
+	for i, f := range p.Files {
+		if f == path {
+			return i
+		}
+	}
+	p.Files = append(p.Files, path)
+	return len(p.Files) - 1
+}
+
+// logLocRe matches the "source:line" (Mesa, Intel, AMD) and
+// "source(line)" (NVIDIA) locations starting a compiler message, also
+// after a prefix such as "ERROR: ".
+var logLocRe = regexp.MustCompile(`(?m)(^|: )(\d+)([:(]\d+)`)
+
+// MapLog replaces the source-string numbers in a compiler log with the
+// names of the files they stand for.
+func (p *Preprocessor) MapLog(log string) string {
+	return logLocRe.ReplaceAllStringFunc(log, func(m string) string {
+		sub := logLocRe.FindStringSubmatch(m)
+		n, err := strconv.Atoi(sub[2])
+		if err != nil || n >= len(p.Files) {
+			return m
+		}
+		return sub[1] + p.Files[n] + sub[3]
+	})
+}
+
+// MapError is MapLog applied to the message of err.
+func (p *Preprocessor) MapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return errors.New(p.MapLog(err.Error()))
+}
+
+func writeDefines(out *strings.Builder, defines map[string]any, keywords []string) {
+	names := make([]string, 0, len(defines))
+	for k := range defines {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		fmt.Fprintf(out, "#define %s %v\n", k, defines[k])
+	}
+	for _, k := range keywords {
+		fmt.Fprintf(out, "#define %s 1\n", k)
+	}
+}
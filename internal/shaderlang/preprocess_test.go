@@ -0,0 +1,122 @@
+package shaderlang
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func memFiles(files map[string]string) func(string) ([]byte, error) {
+	return func(path string) ([]byte, error) {
+		s, ok := files[path]
+		if !ok {
+			return nil, fs.ErrNotExist
+		}
+		return []byte(s), nil
+	}
+}
+
+func TestPreprocessor_Includes(t *testing.T) {
+	pp := &Preprocessor{ReadFile: memFiles(map[string]string{
+		"shaders/frag.glsl": "#version 330 core\r\n" +
+			"#include \"include/shadows.glsl\"\r\n" +
+			"#include \"include/lights.glsl\" // again\r\n" +
+			"void main() {}\r\n",
+		"shaders/include/shadows.glsl": "#include \"lights.glsl\"\nfloat shadow;\n",
+		"shaders/include/lights.glsl":  "\ufeffstruct Light { vec3 p; };\n",
+	})}
+
+	got, err := pp.Process("shaders/./frag.glsl", map[string]any{"USE_FOG": 1, "A": "2.0"}, []string{"SKINNED"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"#version 330 core",
+		"#define A 2.0",
+		"#define USE_FOG 1",
+		"#define SKINNED 1",
+		"#line 2 0",
+		"#line 1 1",
+		"#line 1 2",
+		"struct Light { vec3 p; };",
+		"#line 2 1",
+		"float shadow;",
+		"#line 3 0",
+		"",
+		"void main() {}",
+		"",
+	}, "\n")
+	if got != want {
+		t.Errorf("output:\n%s\nwant:\n%s", got, want)
+	}
+	wantFiles := []string{"shaders/frag.glsl", "shaders/include/shadows.glsl", "shaders/include/lights.glsl"}
+	if strings.Join(pp.Files, ",") != strings.Join(wantFiles, ",") {
+		t.Errorf("files %v, want %v", pp.Files, wantFiles)
+	}
+
+	// A second stage includes lights.glsl again and shares the numbering.
+	pp.ReadFile = memFiles(map[string]string{
+		"shaders/vert.glsl":           "#version 330 core\n#include \"include/lights.glsl\"\n",
+		"shaders/include/lights.glsl": "struct Light { vec3 p; };\n",
+	})
+	got, err = pp.Process("shaders/vert.glsl", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "#line 1 2\nstruct Light") {
+		t.Errorf("second stage did not include lights.glsl as source 2:\n%s", got)
+	}
+	if len(pp.Files) != 4 || pp.Files[3] != "shaders/vert.glsl" {
+		t.Errorf("files %v", pp.Files)
+	}
+}
+
+func TestPreprocessor_Errors(t *testing.T) {
+	files := map[string]string{
+		"a.glsl":       "#version 330 core\n#include \"b.glsl\"\n",
+		"b.glsl":       "// b\n#include \"a.glsl\"\n",
+		"missing.glsl": "#version 330 core\n\n#include \"nope.glsl\"\n",
+		"version.glsl": "#version 330 core\n#include \"inner.glsl\"\n",
+		"inner.glsl":   "#version 330 core\n",
+	}
+	cases := []struct {
+		path, want string
+	}{
+		{"a.glsl", "b.glsl:2: include cycle: a.glsl -> b.glsl -> a.glsl"},
+		{"missing.glsl", "missing.glsl:3: "},
+		{"version.glsl", "inner.glsl:1: #version in an included file"},
+	}
+	for _, c := range cases {
+		pp := &Preprocessor{ReadFile: memFiles(files)}
+		_, err := pp.Process(c.path, nil, nil)
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: error %v, want %q", c.path, err, c.want)
+		}
+	}
+
+	pp := &Preprocessor{ReadFile: memFiles(files)}
+	if _, err := pp.Process("missing.glsl", nil, nil); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing include: %v does not wrap fs.ErrNotExist", err)
+	}
+}
+
+func TestPreprocessor_MapLog(t *testing.T) {
+	pp := &Preprocessor{Files: []string{"frag.glsl", "include/lights.glsl"}}
+	log := "shader \"x\": shader compile error: 1:12(3): error: `foo' undeclared\n" +
+		"0:4: warning\n" +
+		"ERROR: 1:7: 'bar' : syntax error\n" +
+		"1(9) : error C0000: oops\n" +
+		"7:3: unknown source\n"
+	want := "shader \"x\": shader compile error: include/lights.glsl:12(3): error: `foo' undeclared\n" +
+		"frag.glsl:4: warning\n" +
+		"ERROR: include/lights.glsl:7: 'bar' : syntax error\n" +
+		"include/lights.glsl(9) : error C0000: oops\n" +
+		"7:3: unknown source\n"
+	if got := pp.MapLog(log); got != want {
+		t.Errorf("MapLog:\n%s\nwant:\n%s", got, want)
+	}
+	if pp.MapError(nil) != nil {
+		t.Error("MapError(nil) != nil")
+	}
+}
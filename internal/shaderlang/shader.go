@@ -1,16 +1,15 @@
 package shaderlang
 
-import (
-	"fmt"
-	"os"
-	"strings"
-)
+import "os"
 
 type ShaderSource struct {
 	Name         string         `json:"name"`
 	VertexPath   string         `json:"vertex"`
 	FragmentPath string         `json:"fragment"`
 	Defines      map[string]any `json:"defines"`
+	// Keywords are the switches variants of the shader may turn on; an
+	// enabled keyword is defined as 1 in both stages (see Build).
+	Keywords []string `json:"keywords"`
 }
 
 // LoadGLSL loads the raw GLSL text from disk.
@@ -22,40 +21,30 @@ func LoadGLSL(path string) (string, error) {
 	return string(data), nil
 }
 
-// ApplyDefines injects #define statements at the top of the shader.
-func ApplyDefines(src string, defs map[string]any) string {
-	if len(defs) == 0 {
-		return src
+// Build preprocesses both stages of s with the given keywords enabled. The
+// returned Preprocessor maps compiler messages back to files.
+func (s ShaderSource) Build(keywords []string) (vert, frag string, pp *Preprocessor, err error) {
+	pp = &Preprocessor{}
+	if vert, err = pp.Process(s.VertexPath, s.Defines, keywords); err != nil {
+		return "", "", nil, err
 	}
-
-	lines := strings.Split(src, "\n")
-
-	// Find #version line
-	versionIndex := -1
-	for i, line := range lines {
-		if strings.HasPrefix(strings.TrimSpace(line), "#version") {
-			versionIndex = i
-			break
-		}
+	if frag, err = pp.Process(s.FragmentPath, s.Defines, keywords); err != nil {
+		return "", "", nil, err
 	}
+	return vert, frag, pp, nil
+}
 
-	// Build define block
-	var defBlock strings.Builder
-	for k, v := range defs {
-		defBlock.WriteString(fmt.Sprintf("#define %s %v\n", k, v))
+// Files lists the GLSL files s is built from: both stages and everything
+// they include. On an error it lists the files read so far, at least the
+// stages.
+func (s ShaderSource) Files() ([]string, error) {
+	pp := &Preprocessor{}
+	_, err := pp.Process(s.VertexPath, nil, nil)
+	if _, ferr := pp.Process(s.FragmentPath, nil, nil); err == nil {
+		err = ferr
 	}
-	defBlock.WriteString("\n")
-
-	// If no #version found, prepend defines normally
-	if versionIndex == -1 {
-		return defBlock.String() + src
+	for _, stage := range []string{s.VertexPath, s.FragmentPath} {
+		pp.fileIndex(stage)
 	}
-
-	// Insert defines *after* #version
-	out := make([]string, 0, len(lines)+len(defs)+2)
-	out = append(out, lines[versionIndex]) // keep #version first
-	out = append(out, defBlock.String())   // then defines
-	out = append(out, lines[versionIndex+1:]...)
-
-	return strings.Join(out, "\n")
+	return pp.Files, err
 }